@@ -2,8 +2,11 @@ package auth
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/alvii147/nymphadora-api/internal/config"
+	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
 )
 
@@ -32,12 +35,64 @@ type APIKey struct {
 	UpdatedAt time.Time  `db:"updated_at"`
 }
 
+// ExecutionUsage represents the database table "execution_usage".
+// Each row records a single code execution by a user, optionally made using an API key.
+// Rows are created when executions are reserved, and hold the most CPU time they can use until they finish.
+type ExecutionUsage struct {
+	ID              int64     `db:"id"`
+	UserUUID        string    `db:"user_uuid"`
+	APIKeyID        *int64    `db:"api_key_id"`
+	CPUMilliseconds int64     `db:"cpu_milliseconds"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+// ExecutionUsageSummary represents the code executions of a user or API key in the current quota windows.
+type ExecutionUsageSummary struct {
+	RunsThisMinute       int64
+	RunsToday            int64
+	CPUMillisecondsToday int64
+}
+
+// ExecutionQuota represents the limits on code executions of a user or API key.
+// Limits of zero are disabled.
+type ExecutionQuota struct {
+	RunsPerMinute    int64
+	RunsPerDay       int64
+	CPUSecondsPerDay int64
+}
+
+// ExecutionQuotaUsage represents the consumption against a single execution quota limit.
+// Max is zero when the limit is disabled.
+type ExecutionQuotaUsage struct {
+	Limit   string
+	Used    int64
+	Max     int64
+	ResetAt time.Time
+}
+
+// APIKeyUsage represents the consumption of an API key against its execution quota.
+type APIKeyUsage struct {
+	APIKey *APIKey
+	Quotas []*ExecutionQuotaUsage
+}
+
+// ExecutionQuotaExceededError represents an exceeded execution quota limit.
+// It wraps errutils.ErrExecutionQuotaExceeded.
+type ExecutionQuotaExceededError struct {
+	Limit   string
+	ResetAt time.Time
+}
+
 // AuthContextKey is a string representing auth-related context keys.
 type AuthContextKey string
 
 // AuthContextKeyUserUUID is the key in context where user UUID is stored after authentication.
 const AuthContextKeyUserUUID AuthContextKey = "userUUID"
 
+// AuthContextKeyAPIKeyID is the key in context where API key ID is stored after API key authentication.
+const AuthContextKeyAPIKeyID AuthContextKey = "apiKeyID"
+
 // GetUserUUIDFromContext extracts the user UUID from a given context.
 func GetUserUUIDFromContext(ctx context.Context) (string, error) {
 	userUUID, ok := ctx.Value(AuthContextKeyUserUUID).(string)
@@ -47,3 +102,111 @@ func GetUserUUIDFromContext(ctx context.Context) (string, error) {
 
 	return userUUID, nil
 }
+
+// GetAPIKeyIDFromContext extracts the ID of the API key used for authentication from a given context.
+// It returns nil if the request was not authenticated using an API key.
+func GetAPIKeyIDFromContext(ctx context.Context) *int64 {
+	apiKeyID, ok := ctx.Value(AuthContextKeyAPIKeyID).(int64)
+	if !ok {
+		return nil
+	}
+
+	return &apiKeyID
+}
+
+// NewUserExecutionQuota returns the configured execution quota for each user.
+func NewUserExecutionQuota(cfg *config.Config) *ExecutionQuota {
+	return &ExecutionQuota{
+		RunsPerMinute:    cfg.QuotaUserRunsPerMinute,
+		RunsPerDay:       cfg.QuotaUserRunsPerDay,
+		CPUSecondsPerDay: cfg.QuotaUserCPUSecondsPerDay,
+	}
+}
+
+// NewAPIKeyExecutionQuota returns the configured execution quota for each API key.
+func NewAPIKeyExecutionQuota(cfg *config.Config) *ExecutionQuota {
+	return &ExecutionQuota{
+		RunsPerMinute:    cfg.QuotaAPIKeyRunsPerMinute,
+		RunsPerDay:       cfg.QuotaAPIKeyRunsPerDay,
+		CPUSecondsPerDay: cfg.QuotaAPIKeyCPUSecondsPerDay,
+	}
+}
+
+// GetExecutionQuotaWindows gets the start of the minute and day quota windows a given time falls in.
+// Windows are aligned to UTC minutes and days.
+func GetExecutionQuotaWindows(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+
+	return now.Truncate(time.Minute), now.Truncate(24 * time.Hour)
+}
+
+// Usage reports the consumption against each limit of the quota in the quota windows a given time falls in.
+func (quota *ExecutionQuota) Usage(summary *ExecutionUsageSummary, now time.Time) []*ExecutionQuotaUsage {
+	minuteStart, dayStart := GetExecutionQuotaWindows(now)
+
+	return []*ExecutionQuotaUsage{
+		{
+			Limit:   api.ExecutionQuotaLimitRunsPerMinute,
+			Used:    summary.RunsThisMinute,
+			Max:     quota.RunsPerMinute,
+			ResetAt: minuteStart.Add(time.Minute),
+		},
+		{
+			Limit:   api.ExecutionQuotaLimitRunsPerDay,
+			Used:    summary.RunsToday,
+			Max:     quota.RunsPerDay,
+			ResetAt: dayStart.Add(24 * time.Hour),
+		},
+		{
+			Limit:   api.ExecutionQuotaLimitCPUMillisecondsPerDay,
+			Used:    summary.CPUMillisecondsToday,
+			Max:     quota.CPUSecondsPerDay * int64(time.Second/time.Millisecond),
+			ResetAt: dayStart.Add(24 * time.Hour),
+		},
+	}
+}
+
+// IsDisabled determines whether all limits of the quota are disabled.
+func (quota *ExecutionQuota) IsDisabled() bool {
+	return quota.RunsPerMinute == 0 && quota.RunsPerDay == 0 && quota.CPUSecondsPerDay == 0
+}
+
+// Check checks whether a given number of additional runs,
+// each using up to a given amount of CPU time in milliseconds, fit within the quota at a given time.
+// It returns an ExecutionQuotaExceededError for the first limit that would be exceeded.
+func (quota *ExecutionQuota) Check(
+	summary *ExecutionUsageSummary,
+	runs int64,
+	cpuMillisecondsPerRun int64,
+	now time.Time,
+) error {
+	for _, usage := range quota.Usage(summary, now) {
+		if usage.Max == 0 {
+			continue
+		}
+
+		exceeded := usage.Used+runs > usage.Max
+		if usage.Limit == api.ExecutionQuotaLimitCPUMillisecondsPerDay {
+			exceeded = usage.Used+runs*cpuMillisecondsPerRun > usage.Max
+		}
+
+		if exceeded {
+			return &ExecutionQuotaExceededError{
+				Limit:   usage.Limit,
+				ResetAt: usage.ResetAt,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Error returns the error message of an exceeded execution quota limit.
+func (e *ExecutionQuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s resets at %s", errutils.ErrExecutionQuotaExceeded, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// Unwrap returns errutils.ErrExecutionQuotaExceeded, so that exceeded quotas can be detected using errors.Is.
+func (e *ExecutionQuotaExceededError) Unwrap() error {
+	return errutils.ErrExecutionQuotaExceeded
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/database"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -62,3 +65,207 @@ func TestGetUserUUIDFromContext(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAPIKeyIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	apiKeyID := int64(42)
+
+	testcases := map[string]struct {
+		ctx          context.Context
+		wantAPIKeyID *int64
+	}{
+		"API key ID in context": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyAPIKeyID, apiKeyID),
+			wantAPIKeyID: &apiKeyID,
+		},
+		"No API key ID in context": {
+			ctx:          context.Background(),
+			wantAPIKeyID: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantAPIKeyID, auth.GetAPIKeyIDFromContext(testcase.ctx))
+		})
+	}
+}
+
+func TestGetExecutionQuotaWindows(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 14, 15, 9, 26, 535, time.FixedZone("EST", -5*60*60))
+
+	minuteStart, dayStart := auth.GetExecutionQuotaWindows(now)
+	require.Equal(t, time.Date(2024, 3, 14, 20, 9, 0, 0, time.UTC), minuteStart)
+	require.Equal(t, time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), dayStart)
+}
+
+func TestExecutionQuotaUsage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+	quota := &auth.ExecutionQuota{
+		RunsPerMinute:    5,
+		RunsPerDay:       100,
+		CPUSecondsPerDay: 60,
+	}
+	summary := &auth.ExecutionUsageSummary{
+		RunsThisMinute:       2,
+		RunsToday:            20,
+		CPUMillisecondsToday: 1500,
+	}
+
+	wantUsage := []*auth.ExecutionQuotaUsage{
+		{
+			Limit:   api.ExecutionQuotaLimitRunsPerMinute,
+			Used:    2,
+			Max:     5,
+			ResetAt: time.Date(2024, 3, 14, 15, 10, 0, 0, time.UTC),
+		},
+		{
+			Limit:   api.ExecutionQuotaLimitRunsPerDay,
+			Used:    20,
+			Max:     100,
+			ResetAt: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Limit:   api.ExecutionQuotaLimitCPUMillisecondsPerDay,
+			Used:    1500,
+			Max:     60000,
+			ResetAt: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	require.Equal(t, wantUsage, quota.Usage(summary, now))
+}
+
+func TestExecutionQuotaIsDisabled(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, (&auth.ExecutionQuota{}).IsDisabled())
+	require.False(t, (&auth.ExecutionQuota{RunsPerDay: 1}).IsDisabled())
+}
+
+func TestExecutionQuotaCheck(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+	quota := &auth.ExecutionQuota{
+		RunsPerMinute:    5,
+		RunsPerDay:       100,
+		CPUSecondsPerDay: 60,
+	}
+
+	testcases := map[string]struct {
+		quota         *auth.ExecutionQuota
+		summary       *auth.ExecutionUsageSummary
+		runs          int64
+		cpuPerRun     int64
+		wantExceeded  bool
+		wantLimit     string
+		wantResetTime time.Time
+	}{
+		"Runs within quota": {
+			quota: quota,
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       4,
+				RunsToday:            99,
+				CPUMillisecondsToday: 57000,
+			},
+			runs:          1,
+			cpuPerRun:     3000,
+			wantExceeded:  false,
+			wantLimit:     "",
+			wantResetTime: time.Time{},
+		},
+		"Runs per minute exceeded": {
+			quota: quota,
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       4,
+				RunsToday:            4,
+				CPUMillisecondsToday: 0,
+			},
+			runs:          2,
+			cpuPerRun:     3000,
+			wantExceeded:  true,
+			wantLimit:     api.ExecutionQuotaLimitRunsPerMinute,
+			wantResetTime: time.Date(2024, 3, 14, 15, 10, 0, 0, time.UTC),
+		},
+		"Runs per day exceeded": {
+			quota: quota,
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       0,
+				RunsToday:            100,
+				CPUMillisecondsToday: 0,
+			},
+			runs:          1,
+			cpuPerRun:     3000,
+			wantExceeded:  true,
+			wantLimit:     api.ExecutionQuotaLimitRunsPerDay,
+			wantResetTime: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"CPU time per day exceeded": {
+			quota: quota,
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       0,
+				RunsToday:            10,
+				CPUMillisecondsToday: 57001,
+			},
+			runs:          1,
+			cpuPerRun:     3000,
+			wantExceeded:  true,
+			wantLimit:     api.ExecutionQuotaLimitCPUMillisecondsPerDay,
+			wantResetTime: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"CPU time per day exceeded by multiple runs": {
+			quota: quota,
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       0,
+				RunsToday:            10,
+				CPUMillisecondsToday: 50000,
+			},
+			runs:          4,
+			cpuPerRun:     3000,
+			wantExceeded:  true,
+			wantLimit:     api.ExecutionQuotaLimitCPUMillisecondsPerDay,
+			wantResetTime: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"Disabled quota": {
+			quota: &auth.ExecutionQuota{},
+			summary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       1000,
+				RunsToday:            1000,
+				CPUMillisecondsToday: 1000000,
+			},
+			runs:          1,
+			cpuPerRun:     3000,
+			wantExceeded:  false,
+			wantLimit:     "",
+			wantResetTime: time.Time{},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testcase.quota.Check(testcase.summary, testcase.runs, testcase.cpuPerRun, now)
+			if !testcase.wantExceeded {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, errutils.ErrExecutionQuotaExceeded)
+
+			var quotaErr *auth.ExecutionQuotaExceededError
+			require.ErrorAs(t, err, &quotaErr)
+			require.Equal(t, testcase.wantLimit, quotaErr.Limit)
+			require.Equal(t, testcase.wantResetTime, quotaErr.ResetAt)
+		})
+	}
+}
@@ -62,7 +62,7 @@ func NewAPIKeyAuthMiddleware(svc Service) httputils.MiddlewareFunc {
 
 // APIKeyAuthMiddleware authenticates a user using provided API Key.
 // If authentication fails, it returns 401.
-// If authentication is successful, it sets the user UUID in context.
+// If authentication is successful, it sets the user UUID and API key ID in context.
 func APIKeyAuthMiddleware(next httputils.HandlerFunc, svc Service) httputils.HandlerFunc {
 	return httputils.HandlerFunc(func(w *httputils.ResponseWriter, r *http.Request) {
 		rawKey, ok := httputils.GetAuthorizationHeader(r.Header, "X-API-Key")
@@ -102,6 +102,9 @@ func APIKeyAuthMiddleware(next httputils.HandlerFunc, svc Service) httputils.Han
 			return
 		}
 
-		next.ServeHTTP(w, r.Clone(context.WithValue(r.Context(), AuthContextKeyUserUUID, apiKey.UserUUID)))
+		ctx := context.WithValue(r.Context(), AuthContextKeyUserUUID, apiKey.UserUUID)
+		ctx = context.WithValue(ctx, AuthContextKeyAPIKeyID, apiKey.ID)
+
+		next.ServeHTTP(w, r.Clone(ctx))
 	})
 }
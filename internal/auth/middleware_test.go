@@ -233,7 +233,7 @@ func TestAPIKeyAuthMiddleware(t *testing.T) {
 	repo := auth.NewRepository(timeProvider)
 	svc := auth.NewService(cfg, timeProvider, TestDBPool, logger, crypto, mailClient, tmplManager, repo)
 
-	_, validAPIKey := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
+	apiKey, validAPIKey := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
 
 	validResponse := map[string]any{
 		"email":      user.Email,
@@ -292,6 +292,7 @@ func TestAPIKeyAuthMiddleware(t *testing.T) {
 			nextCallCount := 0
 			var next httputils.HandlerFunc = func(w *httputils.ResponseWriter, r *http.Request) {
 				require.Equal(t, user.UUID, r.Context().Value(auth.AuthContextKeyUserUUID))
+				require.Equal(t, apiKey.ID, r.Context().Value(auth.AuthContextKeyAPIKeyID))
 				w.WriteJSON(validResponse, validStatusCode)
 				nextCallCount++
 			}
@@ -73,6 +73,21 @@ func (mr *MockRepositoryMockRecorder) CreateAPIKey(ctx, querier, apiKey any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), ctx, querier, apiKey)
 }
 
+// CreateExecutionUsage mocks base method.
+func (m *MockRepository) CreateExecutionUsage(ctx context.Context, querier database.Querier, executionUsage *auth.ExecutionUsage) (*auth.ExecutionUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExecutionUsage", ctx, querier, executionUsage)
+	ret0, _ := ret[0].(*auth.ExecutionUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExecutionUsage indicates an expected call of CreateExecutionUsage.
+func (mr *MockRepositoryMockRecorder) CreateExecutionUsage(ctx, querier, executionUsage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExecutionUsage", reflect.TypeOf((*MockRepository)(nil).CreateExecutionUsage), ctx, querier, executionUsage)
+}
+
 // CreateUser mocks base method.
 func (m *MockRepository) CreateUser(ctx context.Context, querier database.Querier, user *auth.User) (*auth.User, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +117,35 @@ func (mr *MockRepositoryMockRecorder) DeleteAPIKey(ctx, querier, userUUID, apiKe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockRepository)(nil).DeleteAPIKey), ctx, querier, userUUID, apiKeyID)
 }
 
+// DeleteExecutionUsage mocks base method.
+func (m *MockRepository) DeleteExecutionUsage(ctx context.Context, querier database.Querier, executionUsageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExecutionUsage", ctx, querier, executionUsageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExecutionUsage indicates an expected call of DeleteExecutionUsage.
+func (mr *MockRepositoryMockRecorder) DeleteExecutionUsage(ctx, querier, executionUsageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExecutionUsage", reflect.TypeOf((*MockRepository)(nil).DeleteExecutionUsage), ctx, querier, executionUsageID)
+}
+
+// GetExecutionUsageSummary mocks base method.
+func (m *MockRepository) GetExecutionUsageSummary(ctx context.Context, querier database.Querier, userUUID string, apiKeyID *int64, minuteStart, dayStart time.Time) (*auth.ExecutionUsageSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutionUsageSummary", ctx, querier, userUUID, apiKeyID, minuteStart, dayStart)
+	ret0, _ := ret[0].(*auth.ExecutionUsageSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutionUsageSummary indicates an expected call of GetExecutionUsageSummary.
+func (mr *MockRepositoryMockRecorder) GetExecutionUsageSummary(ctx, querier, userUUID, apiKeyID, minuteStart, dayStart any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutionUsageSummary", reflect.TypeOf((*MockRepository)(nil).GetExecutionUsageSummary), ctx, querier, userUUID, apiKeyID, minuteStart, dayStart)
+}
+
 // GetUserByEmail mocks base method.
 func (m *MockRepository) GetUserByEmail(ctx context.Context, querier database.Querier, email string) (*auth.User, error) {
 	m.ctrl.T.Helper()
@@ -162,6 +206,20 @@ func (mr *MockRepositoryMockRecorder) ListActiveAPIKeysByPrefix(ctx, querier, pr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveAPIKeysByPrefix", reflect.TypeOf((*MockRepository)(nil).ListActiveAPIKeysByPrefix), ctx, querier, prefix)
 }
 
+// LockExecutionUsage mocks base method.
+func (m *MockRepository) LockExecutionUsage(ctx context.Context, querier database.Querier, userUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockExecutionUsage", ctx, querier, userUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockExecutionUsage indicates an expected call of LockExecutionUsage.
+func (mr *MockRepositoryMockRecorder) LockExecutionUsage(ctx, querier, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockExecutionUsage", reflect.TypeOf((*MockRepository)(nil).LockExecutionUsage), ctx, querier, userUUID)
+}
+
 // UpdateAPIKey mocks base method.
 func (m *MockRepository) UpdateAPIKey(ctx context.Context, querier database.Querier, userUUID string, apiKeyID int64, name *string, expiresAt jsonutils.Optional[time.Time]) (*auth.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -177,6 +235,20 @@ func (mr *MockRepositoryMockRecorder) UpdateAPIKey(ctx, querier, userUUID, apiKe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKey", reflect.TypeOf((*MockRepository)(nil).UpdateAPIKey), ctx, querier, userUUID, apiKeyID, name, expiresAt)
 }
 
+// UpdateExecutionUsageCPUTime mocks base method.
+func (m *MockRepository) UpdateExecutionUsageCPUTime(ctx context.Context, querier database.Querier, executionUsageID, cpuMilliseconds int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExecutionUsageCPUTime", ctx, querier, executionUsageID, cpuMilliseconds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateExecutionUsageCPUTime indicates an expected call of UpdateExecutionUsageCPUTime.
+func (mr *MockRepositoryMockRecorder) UpdateExecutionUsageCPUTime(ctx, querier, executionUsageID, cpuMilliseconds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExecutionUsageCPUTime", reflect.TypeOf((*MockRepository)(nil).UpdateExecutionUsageCPUTime), ctx, querier, executionUsageID, cpuMilliseconds)
+}
+
 // UpdateUser mocks base method.
 func (m *MockRepository) UpdateUser(ctx context.Context, querier database.Querier, userUUID string, firstName, lastName *string) (*auth.User, error) {
 	m.ctrl.T.Helper()
@@ -150,6 +150,22 @@ func (mr *MockServiceMockRecorder) GetAuthenticatedUser(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthenticatedUser", reflect.TypeOf((*MockService)(nil).GetAuthenticatedUser), ctx)
 }
 
+// GetAuthenticatedUserUsage mocks base method.
+func (m *MockService) GetAuthenticatedUserUsage(ctx context.Context) ([]*auth.ExecutionQuotaUsage, []*auth.APIKeyUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthenticatedUserUsage", ctx)
+	ret0, _ := ret[0].([]*auth.ExecutionQuotaUsage)
+	ret1, _ := ret[1].([]*auth.APIKeyUsage)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAuthenticatedUserUsage indicates an expected call of GetAuthenticatedUserUsage.
+func (mr *MockServiceMockRecorder) GetAuthenticatedUserUsage(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthenticatedUserUsage", reflect.TypeOf((*MockService)(nil).GetAuthenticatedUserUsage), ctx)
+}
+
 // ListAPIKeys mocks base method.
 func (m *MockService) ListAPIKeys(ctx context.Context) ([]*auth.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -73,6 +73,35 @@ type Repository interface {
 		userUUID string,
 		apiKeyID int64,
 	) error
+	LockExecutionUsage(
+		ctx context.Context,
+		querier database.Querier,
+		userUUID string,
+	) error
+	CreateExecutionUsage(
+		ctx context.Context,
+		querier database.Querier,
+		executionUsage *ExecutionUsage,
+	) (*ExecutionUsage, error)
+	UpdateExecutionUsageCPUTime(
+		ctx context.Context,
+		querier database.Querier,
+		executionUsageID int64,
+		cpuMilliseconds int64,
+	) error
+	DeleteExecutionUsage(
+		ctx context.Context,
+		querier database.Querier,
+		executionUsageID int64,
+	) error
+	GetExecutionUsageSummary(
+		ctx context.Context,
+		querier database.Querier,
+		userUUID string,
+		apiKeyID *int64,
+		minuteStart time.Time,
+		dayStart time.Time,
+	) (*ExecutionUsageSummary, error)
 }
 
 // repository implements Repository.
@@ -661,3 +690,188 @@ WHERE
 
 	return nil
 }
+
+// LockExecutionUsage locks the code executions of a given user until the end of the current transaction,
+// so that concurrent code executions of the user are checked against execution quotas one at a time.
+func (repo *repository) LockExecutionUsage(
+	ctx context.Context,
+	querier database.Querier,
+	userUUID string,
+) error {
+	q := `
+SELECT
+	u.uuid
+FROM
+	"user" u
+WHERE
+	u.uuid = $1
+FOR UPDATE;
+	`
+
+	var lockedUUID string
+	err := querier.QueryRow(ctx, q, userUUID).Scan(&lockedUUID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return nil
+}
+
+// CreateExecutionUsage records a new code execution.
+func (repo *repository) CreateExecutionUsage(
+	ctx context.Context,
+	querier database.Querier,
+	executionUsage *ExecutionUsage,
+) (*ExecutionUsage, error) {
+	now := repo.timeProvider.Now()
+	createdExecutionUsage := &ExecutionUsage{}
+
+	q := `
+INSERT INTO execution_usage (
+	user_uuid,
+	api_key_id,
+	cpu_milliseconds,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+RETURNING
+	id,
+	user_uuid,
+	api_key_id,
+	cpu_milliseconds,
+	created_at,
+	updated_at;
+	`
+	err := querier.QueryRow(
+		ctx,
+		q,
+		executionUsage.UserUUID,
+		executionUsage.APIKeyID,
+		executionUsage.CPUMilliseconds,
+		now,
+		now,
+	).Scan(
+		&createdExecutionUsage.ID,
+		&createdExecutionUsage.UserUUID,
+		&createdExecutionUsage.APIKeyID,
+		&createdExecutionUsage.CPUMilliseconds,
+		&createdExecutionUsage.CreatedAt,
+		&createdExecutionUsage.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeForeignKeyViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseForeignKeyConstraintViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdExecutionUsage, nil
+}
+
+// UpdateExecutionUsageCPUTime records the time spent on a given code execution.
+func (repo *repository) UpdateExecutionUsageCPUTime(
+	ctx context.Context,
+	querier database.Querier,
+	executionUsageID int64,
+	cpuMilliseconds int64,
+) error {
+	q := `
+UPDATE
+	execution_usage
+SET
+	cpu_milliseconds = $1,
+	updated_at = $2
+WHERE
+	id = $3;
+	`
+
+	ct, err := querier.Exec(ctx, q, cpuMilliseconds, repo.timeProvider.Now(), executionUsageID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// DeleteExecutionUsage deletes the record of a given code execution.
+func (repo *repository) DeleteExecutionUsage(
+	ctx context.Context,
+	querier database.Querier,
+	executionUsageID int64,
+) error {
+	q := `
+DELETE FROM
+	execution_usage
+WHERE
+	id = $1;
+	`
+
+	ct, err := querier.Exec(ctx, q, executionUsageID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// GetExecutionUsageSummary summarizes the code executions of a given user since the start of given quota windows.
+// When apiKeyID is not nil, only code executions made using the given API key are summarized.
+func (repo *repository) GetExecutionUsageSummary(
+	ctx context.Context,
+	querier database.Querier,
+	userUUID string,
+	apiKeyID *int64,
+	minuteStart time.Time,
+	dayStart time.Time,
+) (*ExecutionUsageSummary, error) {
+	summary := &ExecutionUsageSummary{}
+
+	q := `
+SELECT
+	COUNT(*) FILTER (WHERE e.created_at >= $1),
+	COUNT(*),
+	COALESCE(SUM(e.cpu_milliseconds), 0)
+FROM
+	execution_usage e
+WHERE
+	e.user_uuid = $2
+	AND e.created_at >= $3
+	AND ($4::INT IS NULL OR e.api_key_id = $4);
+	`
+
+	err := querier.QueryRow(ctx, q, minuteStart, userUUID, dayStart, apiKeyID).Scan(
+		&summary.RunsThisMinute,
+		&summary.RunsToday,
+		&summary.CPUMillisecondsToday,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return summary, nil
+}
@@ -898,3 +898,275 @@ func TestRepositoryDeleteAPIKeyError(t *testing.T) {
 		})
 	}
 }
+
+func TestRepositoryCreateExecutionUsageSuccess(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	apiKey, _ := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := auth.NewRepository(timeProvider)
+
+	executionUsage := &auth.ExecutionUsage{
+		UserUUID:        user.UUID,
+		APIKeyID:        &apiKey.ID,
+		CPUMilliseconds: 0,
+	}
+
+	createdExecutionUsage, err := repo.CreateExecutionUsage(context.Background(), dbConn, executionUsage)
+	require.NoError(t, err)
+
+	require.Equal(t, user.UUID, createdExecutionUsage.UserUUID)
+	require.Equal(t, &apiKey.ID, createdExecutionUsage.APIKeyID)
+	require.Equal(t, int64(0), createdExecutionUsage.CPUMilliseconds)
+	require.WithinDuration(t, timeProvider.Now(), createdExecutionUsage.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, timeProvider.Now(), createdExecutionUsage.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateExecutionUsageError(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := auth.NewRepository(timeProvider)
+
+	executionUsage := &auth.ExecutionUsage{
+		UserUUID:        uuid.NewString(),
+		APIKeyID:        nil,
+		CPUMilliseconds: 0,
+	}
+
+	_, err = repo.CreateExecutionUsage(context.Background(), dbConn, executionUsage)
+	require.ErrorIs(t, err, errutils.ErrDatabaseForeignKeyConstraintViolation)
+}
+
+func TestRepositoryUpdateExecutionUsageCPUTime(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := auth.NewRepository(timeProvider)
+
+	executionUsage, err := repo.CreateExecutionUsage(context.Background(), dbConn, &auth.ExecutionUsage{
+		UserUUID: user.UUID,
+	})
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		executionUsageID int64
+		wantErr          error
+	}{
+		"Update existing execution usage": {
+			executionUsageID: executionUsage.ID,
+			wantErr:          nil,
+		},
+		"Update non-existent execution usage": {
+			executionUsageID: -1,
+			wantErr:          errutils.ErrDatabaseNoRowsAffected,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			err = repo.UpdateExecutionUsageCPUTime(context.Background(), dbConn, testcase.executionUsageID, 1500)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRepositoryLockExecutionUsage(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := auth.NewRepository(timeProvider)
+
+	testcases := map[string]struct {
+		userUUID string
+		wantErr  error
+	}{
+		"Lock existing user": {
+			userUUID: user.UUID,
+			wantErr:  nil,
+		},
+		"Lock non-existent user": {
+			userUUID: uuid.NewString(),
+			wantErr:  errutils.ErrDatabaseNoRowsReturned,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			dbTx, err := dbConn.Begin(context.Background())
+			require.NoError(t, err)
+			defer dbTx.Rollback(context.Background())
+
+			err = repo.LockExecutionUsage(context.Background(), dbTx, testcase.userUUID)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRepositoryDeleteExecutionUsage(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := auth.NewRepository(timeProvider)
+
+	executionUsage, err := repo.CreateExecutionUsage(context.Background(), dbConn, &auth.ExecutionUsage{
+		UserUUID:        user.UUID,
+		CPUMilliseconds: 1500,
+	})
+	require.NoError(t, err)
+
+	err = repo.DeleteExecutionUsage(context.Background(), dbConn, executionUsage.ID)
+	require.NoError(t, err)
+
+	minuteStart, dayStart := auth.GetExecutionQuotaWindows(timeProvider.Now())
+	summary, err := repo.GetExecutionUsageSummary(
+		context.Background(),
+		dbConn,
+		user.UUID,
+		nil,
+		minuteStart,
+		dayStart,
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), summary.RunsToday)
+	require.Equal(t, int64(0), summary.CPUMillisecondsToday)
+
+	err = repo.DeleteExecutionUsage(context.Background(), dbConn, executionUsage.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryGetExecutionUsageSummary(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	apiKey, _ := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	timeProvider.SetTime(time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC))
+	repo := auth.NewRepository(timeProvider)
+
+	createExecutionUsage := func(apiKeyID *int64, cpuMilliseconds int64) {
+		executionUsage, err := repo.CreateExecutionUsage(context.Background(), dbConn, &auth.ExecutionUsage{
+			UserUUID: user.UUID,
+			APIKeyID: apiKeyID,
+		})
+		require.NoError(t, err)
+
+		err = repo.UpdateExecutionUsageCPUTime(context.Background(), dbConn, executionUsage.ID, cpuMilliseconds)
+		require.NoError(t, err)
+	}
+
+	createExecutionUsage(nil, 100)
+	timeProvider.Add(-time.Hour)
+	createExecutionUsage(&apiKey.ID, 200)
+	timeProvider.Add(-24 * time.Hour)
+	createExecutionUsage(&apiKey.ID, 400)
+	timeProvider.Add(25 * time.Hour)
+
+	minuteStart, dayStart := auth.GetExecutionQuotaWindows(timeProvider.Now())
+
+	testcases := map[string]struct {
+		apiKeyID    *int64
+		wantSummary *auth.ExecutionUsageSummary
+	}{
+		"Summary of user": {
+			apiKeyID: nil,
+			wantSummary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       1,
+				RunsToday:            2,
+				CPUMillisecondsToday: 300,
+			},
+		},
+		"Summary of API key": {
+			apiKeyID: &apiKey.ID,
+			wantSummary: &auth.ExecutionUsageSummary{
+				RunsThisMinute:       0,
+				RunsToday:            1,
+				CPUMillisecondsToday: 200,
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			summary, err := repo.GetExecutionUsageSummary(
+				context.Background(),
+				dbConn,
+				user.UUID,
+				testcase.apiKeyID,
+				minuteStart,
+				dayStart,
+			)
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantSummary, summary)
+		})
+	}
+}
@@ -85,6 +85,9 @@ type Service interface {
 		ctx context.Context,
 		apiKeyID int64,
 	) error
+	GetAuthenticatedUserUsage(
+		ctx context.Context,
+	) ([]*ExecutionQuotaUsage, []*APIKeyUsage, error)
 }
 
 // service implements Service.
@@ -562,3 +565,56 @@ func (svc *service) DeleteAPIKey(
 
 	return nil
 }
+
+// GetAuthenticatedUserUsage gets the consumption of the currently authenticated user
+// and each of their API keys against their execution quotas.
+func (svc *service) GetAuthenticatedUserUsage(
+	ctx context.Context,
+) ([]*ExecutionQuotaUsage, []*APIKeyUsage, error) {
+	userUUID, err := GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	now := svc.timeProvider.Now()
+	minuteStart, dayStart := GetExecutionQuotaWindows(now)
+
+	userSummary, err := svc.repository.GetExecutionUsageSummary(ctx, dbConn, userUUID, nil, minuteStart, dayStart)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	apiKeys, err := svc.repository.ListAPIKeysByUserUUID(ctx, dbConn, userUUID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	apiKeyQuota := NewAPIKeyExecutionQuota(svc.config)
+	apiKeyUsages := make([]*APIKeyUsage, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		apiKeySummary, err := svc.repository.GetExecutionUsageSummary(
+			ctx,
+			dbConn,
+			userUUID,
+			&apiKey.ID,
+			minuteStart,
+			dayStart,
+		)
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		apiKeyUsages[i] = &APIKeyUsage{
+			APIKey: apiKey,
+			Quotas: apiKeyQuota.Usage(apiKeySummary, now),
+		}
+	}
+
+	return NewUserExecutionQuota(svc.config).Usage(userSummary, now), apiKeyUsages, nil
+}
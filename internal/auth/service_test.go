@@ -19,6 +19,7 @@ import (
 	"github.com/alvii147/nymphadora-api/internal/templatesmanager"
 	templatesmanagermocks "github.com/alvii147/nymphadora-api/internal/templatesmanager/mocks"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
+	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/cryptocore"
 	cryptocoremocks "github.com/alvii147/nymphadora-api/pkg/cryptocore/mocks"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
@@ -2057,3 +2058,125 @@ func TestServiceDeleteAPIKeyError(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceGetAuthenticatedUserUsageSuccess(t *testing.T) {
+	t.Parallel()
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	apiKey, _ := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	repo := auth.NewRepository(timeProvider)
+	svc := auth.NewService(cfg, timeProvider, TestDBPool, logger, crypto, mailClient, tmplManager, repo)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	_, err = repo.CreateExecutionUsage(context.Background(), dbConn, &auth.ExecutionUsage{
+		UserUUID: user.UUID,
+		APIKeyID: &apiKey.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = repo.CreateExecutionUsage(context.Background(), dbConn, &auth.ExecutionUsage{
+		UserUUID: user.UUID,
+		APIKeyID: nil,
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, user.UUID)
+	quotaUsages, apiKeyUsages, err := svc.GetAuthenticatedUserUsage(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, quotaUsages, 3)
+	require.Equal(t, api.ExecutionQuotaLimitRunsPerMinute, quotaUsages[0].Limit)
+	require.Equal(t, int64(2), quotaUsages[0].Used)
+	require.Equal(t, cfg.QuotaUserRunsPerMinute, quotaUsages[0].Max)
+	require.Equal(t, api.ExecutionQuotaLimitRunsPerDay, quotaUsages[1].Limit)
+	require.Equal(t, int64(2), quotaUsages[1].Used)
+	require.Equal(t, cfg.QuotaUserRunsPerDay, quotaUsages[1].Max)
+
+	require.Len(t, apiKeyUsages, 1)
+	require.Equal(t, apiKey.ID, apiKeyUsages[0].APIKey.ID)
+	require.Len(t, apiKeyUsages[0].Quotas, 3)
+	require.Equal(t, int64(1), apiKeyUsages[0].Quotas[0].Used)
+	require.Equal(t, cfg.QuotaAPIKeyRunsPerMinute, apiKeyUsages[0].Quotas[0].Max)
+}
+
+func TestServiceGetAuthenticatedUserUsageError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	userUUID := uuid.NewString()
+	genericRepoErr := errors.New("GetExecutionUsageSummary failed")
+
+	testcases := map[string]struct {
+		ctx     context.Context
+		repoErr error
+		wantErr error
+	}{
+		"No user UUID in context": {
+			ctx:     context.Background(),
+			repoErr: nil,
+			wantErr: nil,
+		},
+		"Generic repo error": {
+			ctx:     context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID),
+			repoErr: genericRepoErr,
+			wantErr: genericRepoErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			repo := authmocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), userUUID, nil, gomock.Any(), gomock.Any()).
+				Return(nil, testcase.repoErr).
+				MaxTimes(1)
+
+			svc := auth.NewService(cfg, timeProvider, dbPool, logger, crypto, mailClient, tmplManager, repo)
+
+			_, _, err := svc.GetAuthenticatedUserUsage(testcase.ctx)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
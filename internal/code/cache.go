@@ -0,0 +1,86 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/internal/database"
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+)
+
+// databaseCache implements piston.Cache and keeps execution results in the database.
+// Entries expire after a fixed time to live,
+// and the least recently updated entries are deleted once the cache holds more than the maximum number of entries.
+type databaseCache struct {
+	timeProvider timekeeper.Provider
+	dbPool       database.Pool
+	repository   Repository
+	ttl          time.Duration
+	maxEntries   int64
+}
+
+// NewDatabaseCache returns a new databaseCache.
+func NewDatabaseCache(
+	timeProvider timekeeper.Provider,
+	dbPool database.Pool,
+	repo Repository,
+	ttl time.Duration,
+	maxEntries int64,
+) *databaseCache {
+	return &databaseCache{
+		timeProvider: timeProvider,
+		dbPool:       dbPool,
+		repository:   repo,
+		ttl:          ttl,
+		maxEntries:   maxEntries,
+	}
+}
+
+// Get gets the execution result for a given key, if it is cached and has not expired.
+func (c *databaseCache) Get(ctx context.Context, key string) (*api.PistonExecuteResponse, bool, error) {
+	dbConn, err := c.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, false, errutils.FormatError(err, "c.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionCacheEntry, err := c.repository.GetExecutionCacheEntry(ctx, dbConn, key)
+	if errors.Is(err, errutils.ErrDatabaseNoRowsReturned) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, errutils.FormatError(err)
+	}
+
+	return executionCacheEntry.Response, true, nil
+}
+
+// Set caches the execution result for a given key,
+// then deletes expired entries and entries beyond the maximum number of entries.
+func (c *databaseCache) Set(ctx context.Context, key string, resp *api.PistonExecuteResponse) error {
+	dbConn, err := c.dbPool.Acquire(ctx)
+	if err != nil {
+		return errutils.FormatError(err, "c.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	_, err = c.repository.CreateOrUpdateExecutionCacheEntry(ctx, dbConn, &ExecutionCacheEntry{
+		Key:       key,
+		Response:  resp,
+		ExpiresAt: c.timeProvider.Now().Add(c.ttl),
+	})
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	err = c.repository.DeleteStaleExecutionCacheEntries(ctx, dbConn, c.maxEntries)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
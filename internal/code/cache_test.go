@@ -0,0 +1,157 @@
+package code_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/internal/code"
+	codemocks "github.com/alvii147/nymphadora-api/internal/code/mocks"
+	"github.com/alvii147/nymphadora-api/internal/database"
+	databasemocks "github.com/alvii147/nymphadora-api/internal/database/mocks"
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDatabaseCacheGet(t *testing.T) {
+	t.Parallel()
+
+	resp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	genericRepoErr := errors.New("GetExecutionCacheEntry failed")
+
+	testcases := map[string]struct {
+		entry    *code.ExecutionCacheEntry
+		repoErr  error
+		wantResp *api.PistonExecuteResponse
+		wantOK   bool
+		wantErr  error
+	}{
+		"Cached entry": {
+			entry: &code.ExecutionCacheEntry{
+				Key:      "key",
+				Response: resp,
+			},
+			repoErr:  nil,
+			wantResp: resp,
+			wantOK:   true,
+			wantErr:  nil,
+		},
+		"No cached entry": {
+			entry:    nil,
+			repoErr:  errutils.ErrDatabaseNoRowsReturned,
+			wantResp: nil,
+			wantOK:   false,
+			wantErr:  nil,
+		},
+		"Generic repo error": {
+			entry:    nil,
+			repoErr:  genericRepoErr,
+			wantResp: nil,
+			wantOK:   false,
+			wantErr:  genericRepoErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetExecutionCacheEntry(gomock.Any(), gomock.Any(), "key").
+				Return(testcase.entry, testcase.repoErr).
+				Times(1)
+
+			cache := code.NewDatabaseCache(timeProvider, dbPool, repo, time.Minute, 8)
+
+			cachedResp, ok, err := cache.Get(context.Background(), "key")
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantOK, ok)
+			require.Equal(t, testcase.wantResp, cachedResp)
+		})
+	}
+}
+
+func TestDatabaseCacheSet(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+
+	resp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	gomock.InOrder(
+		repo.
+			EXPECT().
+			CreateOrUpdateExecutionCacheEntry(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				_ database.Querier,
+				executionCacheEntry *code.ExecutionCacheEntry,
+			) (*code.ExecutionCacheEntry, error) {
+				require.Equal(t, "key", executionCacheEntry.Key)
+				require.Equal(t, resp, executionCacheEntry.Response)
+				require.Equal(t, timeProvider.Now().Add(time.Minute), executionCacheEntry.ExpiresAt)
+
+				return executionCacheEntry, nil
+			}).
+			Times(1),
+		repo.
+			EXPECT().
+			DeleteStaleExecutionCacheEntries(gomock.Any(), gomock.Any(), int64(8)).
+			Return(nil).
+			Times(1),
+	)
+
+	cache := code.NewDatabaseCache(timeProvider, dbPool, repo, time.Minute, 8)
+
+	err := cache.Set(context.Background(), "key", resp)
+	require.NoError(t, err)
+}
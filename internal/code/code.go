@@ -1,11 +1,34 @@
 package code
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+const (
+	// medianPercentile is the percentile of benchmark measurements reported as the median.
+	medianPercentile = 50
+	// p95Percentile is the percentile of benchmark measurements reported as the 95th percentile.
+	p95Percentile = 95
+	// maxPercentile is the percentile of the largest benchmark measurement.
+	maxPercentile = 100
+	// unifiedDiffContextLines is the number of unchanged lines shown around changes in unified diffs.
+	unifiedDiffContextLines = 3
+	// diffMaxComparisons is the maximum number of line comparisons made when diffing two texts.
+	// Changed regions that would need more comparisons are diffed as a full replacement instead.
+	diffMaxComparisons = 1 << 22
+	// unifiedDiffNoNewlineMarker is the marker that follows lines missing a trailing newline in unified diffs.
+	unifiedDiffNoNewlineMarker = "\\ No newline at end of file\n"
 )
 
 // CodeSpaceAccessLevel represents the code space access level type.
@@ -39,61 +62,139 @@ var (
 )
 
 // CodingLanguageConfig includes configuration information for coding languages.
+// Languages without configuration use DefaultCodingLanguageFileName and start with empty contents.
+// Piston does not set environment variables for executions,
+// so they are set by an entry point file that runs the actual entry point once they are set.
+// envEntryPointFormat is formatted with the environment variables and the name of the actual entry point,
+// both encoded as JSON, which are valid literals in the languages that support environment variables.
+// Languages without envEntryPointFormat cannot be run with environment variables.
 var CodingLanguageConfig = map[string]struct {
-	fileName string
-	version  string
+	fileName              string
+	envEntryPointFileName string
+	envEntryPointFormat   string
 }{
 	// C
 	api.PistonLanguageC: {
 		fileName: "main.c",
-		version:  api.PistonVersionC,
 	},
 	// C++
 	api.PistonLanguageCPlusPlus: {
 		fileName: "main.cpp",
-		version:  api.PistonVersionCPlusPlus,
 	},
 	// Go
 	api.PistonLanguageGo: {
 		fileName: "main.go",
-		version:  api.PistonVersionGo,
 	},
 	// Java
 	api.PistonLanguageJava: {
 		fileName: "Main.java",
-		version:  api.PistonVersionJava,
 	},
 	// JavaScript
 	api.PistonLanguageJavaScript: {
-		fileName: "index.js",
-		version:  api.PistonVersionJavaScript,
+		fileName:              "index.js",
+		envEntryPointFileName: "__env__.js",
+		envEntryPointFormat: `const path = require("path");
+
+Object.assign(process.env, %[1]s);
+process.argv[1] = path.resolve(%[2]s);
+require(process.argv[1]);
+`,
 	},
 	// Python
 	api.PistonLanguagePython: {
-		fileName: "main.py",
-		version:  api.PistonVersionPython,
+		fileName:              "main.py",
+		envEntryPointFileName: "__env__.py",
+		envEntryPointFormat: `import os
+import runpy
+import sys
+
+os.environ.update(%[1]s)
+sys.argv[0] = %[2]s
+runpy.run_path(%[2]s, run_name="__main__")
+`,
 	},
 	// Rust
 	api.PistonLanguageRust: {
 		fileName: "main.rs",
-		version:  api.PistonVersionRust,
 	},
 	// TypeScript
 	api.PistonLanguageTypeScript: {
 		fileName: "index.ts",
-		version:  api.PistonVersionTypeScript,
 	},
 }
 
+// DefaultCodingLanguageFileName is the main file name for languages not in CodingLanguageConfig.
+const DefaultCodingLanguageFileName = "main"
+
+// codingLanguageFileName returns the main file name for a given language.
+func codingLanguageFileName(language string) string {
+	languageConfig, ok := CodingLanguageConfig[language]
+	if !ok {
+		return DefaultCodingLanguageFileName
+	}
+
+	return languageConfig.fileName
+}
+
+// codingLanguageEnvEntryPoint builds the entry point file for a given language
+// that sets given environment variables and then runs the file with a given name.
+// It returns errutils.ErrCodeSpaceEnvVarsUnsupported for languages that cannot be run with environment variables.
+func codingLanguageEnvEntryPoint(
+	language string,
+	entryPointName string,
+	env map[string]string,
+) (*api.PistonFile, error) {
+	languageConfig, ok := CodingLanguageConfig[language]
+	if !ok || languageConfig.envEntryPointFormat == "" {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEnvVarsUnsupported,
+			"environment variables not supported for language %s",
+			language,
+		)
+	}
+
+	envJSON, err := json.Marshal(env)
+	if err != nil {
+		return nil, errutils.FormatError(err, "json.Marshal failed")
+	}
+
+	entryPointNameJSON, err := json.Marshal(entryPointName)
+	if err != nil {
+		return nil, errutils.FormatError(err, "json.Marshal failed")
+	}
+
+	name := languageConfig.envEntryPointFileName
+	encoding := api.PistonFileEncoding
+	file := &api.PistonFile{
+		Name:     &name,
+		Content:  fmt.Sprintf(languageConfig.envEntryPointFormat, envJSON, entryPointNameJSON),
+		Encoding: &encoding,
+	}
+
+	return file, nil
+}
+
+// CodingLanguage represents a coding language supported by the runtimes installed on Piston.
+type CodingLanguage struct {
+	Name     string
+	Versions []string
+	Aliases  []string
+}
+
 // CodeSpace represents the database table "code_space".
+// Version starts at 1 and is incremented whenever the code space is updated or renamed.
 type CodeSpace struct {
-	ID         int64     `db:"id"`
-	AuthorUUID *string   `db:"author_uuid"`
-	Name       string    `db:"name"`
-	Language   string    `db:"language"`
-	Contents   string    `db:"contents"`
-	CreatedAt  time.Time `db:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at"`
+	ID              int64      `db:"id"`
+	AuthorUUID      *string    `db:"author_uuid"`
+	Name            string     `db:"name"`
+	Language        string     `db:"language"`
+	LanguageVersion *string    `db:"language_version"`
+	Contents        string     `db:"contents"`
+	ForkedFromID    *int64     `db:"forked_from_id"`
+	Version         int64      `db:"version"`
+	DeletedAt       *time.Time `db:"deleted_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
 }
 
 // CodeSpaceAccess represents the database table "code_space_access".
@@ -106,6 +207,196 @@ type CodeSpaceAccess struct {
 	UpdatedAt   time.Time            `db:"updated_at"`
 }
 
+// CodeSpaceRun represents the database table "code_space_run".
+type CodeSpaceRun struct {
+	ID                         int64      `db:"id"`
+	CodeSpaceID                int64      `db:"code_space_id"`
+	UserUUID                   *string    `db:"user_uuid"`
+	Contents                   string     `db:"contents"`
+	Language                   string     `db:"language"`
+	Version                    string     `db:"version"`
+	Status                     string     `db:"status"`
+	CompileStdout              *string    `db:"compile_stdout"`
+	CompileStderr              *string    `db:"compile_stderr"`
+	CompileCode                *int       `db:"compile_code"`
+	CompileSignal              *string    `db:"compile_signal"`
+	CompileMessage             *string    `db:"compile_message"`
+	CompileStatus              *string    `db:"compile_status"`
+	CompileCPUTime             *int64     `db:"compile_cpu_time"`
+	CompileWallTime            *int64     `db:"compile_wall_time"`
+	CompileMemory              *int64     `db:"compile_memory"`
+	CompileTimedOut            bool       `db:"compile_timed_out"`
+	CompileMemoryLimitExceeded bool       `db:"compile_memory_limit_exceeded"`
+	RunStdout                  *string    `db:"run_stdout"`
+	RunStderr                  *string    `db:"run_stderr"`
+	RunCode                    *int       `db:"run_code"`
+	RunSignal                  *string    `db:"run_signal"`
+	RunMessage                 *string    `db:"run_message"`
+	RunStatus                  *string    `db:"run_status"`
+	RunCPUTime                 *int64     `db:"run_cpu_time"`
+	RunWallTime                *int64     `db:"run_wall_time"`
+	RunMemory                  *int64     `db:"run_memory"`
+	RunTimedOut                bool       `db:"run_timed_out"`
+	RunMemoryLimitExceeded     bool       `db:"run_memory_limit_exceeded"`
+	StartedAt                  *time.Time `db:"started_at"`
+	FinishedAt                 *time.Time `db:"finished_at"`
+	FromCache                  bool       `db:"from_cache"`
+	CreatedAt                  time.Time  `db:"created_at"`
+	UpdatedAt                  time.Time  `db:"updated_at"`
+}
+
+// CodeSpaceFile represents the database table "code_space_file".
+type CodeSpaceFile struct {
+	ID           int64     `db:"id"`
+	CodeSpaceID  int64     `db:"code_space_id"`
+	Name         string    `db:"name"`
+	Contents     string    `db:"contents"`
+	IsEntryPoint bool      `db:"is_entry_point"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// CodeSpaceTestCase represents the database table "code_space_test_case".
+type CodeSpaceTestCase struct {
+	ID             int64     `db:"id"`
+	CodeSpaceID    int64     `db:"code_space_id"`
+	Stdin          *string   `db:"stdin"`
+	Args           []string  `db:"args"`
+	ExpectedStdout string    `db:"expected_stdout"`
+	ComparisonMode string    `db:"comparison_mode"`
+	IsHidden       bool      `db:"is_hidden"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// CodeSpaceRunConfig represents the database table "code_space_run_config".
+type CodeSpaceRunConfig struct {
+	ID                 int64     `db:"id"`
+	CodeSpaceID        int64     `db:"code_space_id"`
+	Name               string    `db:"name"`
+	Stdin              *string   `db:"stdin"`
+	Args               []string  `db:"args"`
+	CompileTimeout     *int64    `db:"compile_timeout"`
+	RunTimeout         *int64    `db:"run_timeout"`
+	CompileMemoryLimit *int64    `db:"compile_memory_limit"`
+	RunMemoryLimit     *int64    `db:"run_memory_limit"`
+	LanguageVersion    *string   `db:"language_version"`
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
+}
+
+// CodeSpaceEnvVar represents the database table "code_space_env_var".
+// Values of secret environment variables are stored encrypted.
+// Value is nil when the value of a secret environment variable is withheld from the user.
+type CodeSpaceEnvVar struct {
+	ID          int64     `db:"id"`
+	CodeSpaceID int64     `db:"code_space_id"`
+	Name        string    `db:"name"`
+	Value       *string   `db:"value"`
+	IsSecret    bool      `db:"is_secret"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// CodeSpaceAlias represents the database table "code_space_alias".
+// Aliases keep the previous names of renamed code spaces resolving to them,
+// and share one namespace with code space names, so that a name never resolves to two code spaces.
+type CodeSpaceAlias struct {
+	ID          int64     `db:"id"`
+	CodeSpaceID int64     `db:"code_space_id"`
+	Name        string    `db:"name"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// CodeSpaceRevision represents the database table "code_space_revision".
+// Revisions are immutable snapshots of the contents of a code space, written whenever the contents are updated.
+// They only cover the main file of the code space, and not the files stored in the table "code_space_file".
+type CodeSpaceRevision struct {
+	ID           int64     `db:"id"`
+	CodeSpaceID  int64     `db:"code_space_id"`
+	AuthorUUID   *string   `db:"author_uuid"`
+	Contents     string    `db:"contents"`
+	ContentsHash string    `db:"contents_hash"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// ExecutionCacheEntry represents the database table "execution_cache".
+type ExecutionCacheEntry struct {
+	Key       string                     `db:"key"`
+	Response  *api.PistonExecuteResponse `db:"response"`
+	ExpiresAt time.Time                  `db:"expires_at"`
+	CreatedAt time.Time                  `db:"created_at"`
+	UpdatedAt time.Time                  `db:"updated_at"`
+}
+
+// CodeSpaceTestResult represents the outcome of running a code space test case.
+type CodeSpaceTestResult struct {
+	TestCase *CodeSpaceTestCase
+	Passed   bool
+	Compile  *api.PistonResults
+	Run      api.PistonResults
+	// Diff is a line diff between expected and actual standard output,
+	// only set for failed test cases that are not compared using regular expressions.
+	Diff      *string
+	FromCache bool
+}
+
+// CodeSpaceBenchmarkStats summarizes a single measurement across the runs of a code space benchmark.
+// Percentiles are computed using the nearest-rank method.
+type CodeSpaceBenchmarkStats struct {
+	Min    int64
+	Median int64
+	P95    int64
+	Max    int64
+}
+
+// CodeSpaceBenchmark represents the outcome of running a code space several times with the same input.
+// Statistics only cover successful runs that reported the measurement,
+// and are nil when there are no such runs.
+type CodeSpaceBenchmark struct {
+	Iterations int64
+	Runs       []*CodeSpaceRun
+	// StoppedEarly is set when a run failed before all iterations were run.
+	StoppedEarly bool
+	WallTime     *CodeSpaceBenchmarkStats
+	CPUTime      *CodeSpaceBenchmarkStats
+	PeakMemory   *int64
+}
+
+// RunCodeSpaceOptions represents user-provided options for code space runs.
+type RunCodeSpaceOptions struct {
+	// Version overrides the language version the code space is pinned to.
+	Version            *string
+	Stdin              *string
+	Args               []string
+	CompileTimeout     *int64
+	RunTimeout         *int64
+	CompileMemoryLimit *int64
+	RunMemoryLimit     *int64
+	// NoCache skips the execution cache, for code spaces whose output is not deterministic.
+	NoCache bool
+	// RunConfig is the name of a saved run configuration of the code space,
+	// whose values are used for the options that are not set.
+	RunConfig *string
+}
+
+// CodeSpaceVersionConflictError represents an update made against an outdated version of a code space.
+// It holds the current state of the code space, and wraps errutils.ErrCodeSpaceVersionConflict.
+type CodeSpaceVersionConflictError struct {
+	CodeSpace       *CodeSpace
+	CodeSpaceAccess *CodeSpaceAccess
+}
+
+// Error returns the error message of an update made against an outdated version of a code space.
+func (e *CodeSpaceVersionConflictError) Error() string {
+	return fmt.Sprintf("%s: current version is %d", errutils.ErrCodeSpaceVersionConflict, e.CodeSpace.Version)
+}
+
+// Unwrap returns errutils.ErrCodeSpaceVersionConflict, so that version conflicts can be detected using errors.Is.
+func (e *CodeSpaceVersionConflictError) Unwrap() error {
+	return errutils.ErrCodeSpaceVersionConflict
+}
+
 //nolint:gochecknoinits
 func init() {
 	Adjectives = strings.Split(strings.TrimSpace(AdjectivesFile), "\n")
@@ -127,6 +418,104 @@ func (l CodeSpaceAccessLevel) String() string {
 	}
 }
 
+// SetResults records the compilation and runtime results of a Piston execution on a code space run.
+func (run *CodeSpaceRun) SetResults(resp *api.PistonExecuteResponse) {
+	if resp.Compile != nil {
+		run.CompileStdout = &resp.Compile.Stdout
+		run.CompileStderr = &resp.Compile.Stderr
+		run.CompileCode = resp.Compile.Code
+		run.CompileSignal = resp.Compile.Signal
+		run.CompileMessage = resp.Compile.Message
+		run.CompileStatus = resp.Compile.Status
+		run.CompileCPUTime = resp.Compile.CPUTime
+		run.CompileWallTime = resp.Compile.WallTime
+		run.CompileMemory = resp.Compile.Memory
+		run.CompileTimedOut = resp.Compile.TimedOut()
+		run.CompileMemoryLimitExceeded = resp.Compile.MemoryLimitExceeded()
+	}
+
+	run.RunStdout = &resp.Run.Stdout
+	run.RunStderr = &resp.Run.Stderr
+	run.RunCode = resp.Run.Code
+	run.RunSignal = resp.Run.Signal
+	run.RunMessage = resp.Run.Message
+	run.RunStatus = resp.Run.Status
+	run.RunCPUTime = resp.Run.CPUTime
+	run.RunWallTime = resp.Run.WallTime
+	run.RunMemory = resp.Run.Memory
+	run.RunTimedOut = resp.Run.TimedOut()
+	run.RunMemoryLimitExceeded = resp.Run.MemoryLimitExceeded()
+}
+
+// Succeeded determines whether a code space run completed, compiled and exited with code zero.
+func (run *CodeSpaceRun) Succeeded() bool {
+	if run.Status != api.CodeSpaceRunStatusCompleted {
+		return false
+	}
+
+	compiled := run.CompileSignal == nil && (run.CompileCode == nil || *run.CompileCode == 0)
+	exited := run.RunCode != nil && *run.RunCode == 0 && run.RunSignal == nil
+
+	return compiled && exited
+}
+
+// NewCodeSpaceBenchmarkStats computes statistics for a given set of measurements.
+// It returns nil when there are no measurements.
+func NewCodeSpaceBenchmarkStats(values []int64) *CodeSpaceBenchmarkStats {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return &CodeSpaceBenchmarkStats{
+		Min:    sorted[0],
+		Median: nearestRankPercentile(sorted, medianPercentile),
+		P95:    nearestRankPercentile(sorted, p95Percentile),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// nearestRankPercentile computes a given percentile of a non-empty sorted set of measurements
+// using the nearest-rank method, that is the smallest measurement
+// that is greater than or equal to the given percentage of all measurements.
+func nearestRankPercentile(sorted []int64, percentile int) int64 {
+	// rank is the ceiling of percentile/100 * n, computed in integers
+	rank := (percentile*len(sorted) + maxPercentile - 1) / maxPercentile
+
+	return sorted[max(rank, 1)-1]
+}
+
+// Summarize computes the wall time, CPU time and peak memory statistics of a code space benchmark
+// from its successful runs.
+func (benchmark *CodeSpaceBenchmark) Summarize() {
+	wallTimes := make([]int64, 0, len(benchmark.Runs))
+	cpuTimes := make([]int64, 0, len(benchmark.Runs))
+	benchmark.PeakMemory = nil
+
+	for _, run := range benchmark.Runs {
+		if !run.Succeeded() {
+			continue
+		}
+
+		if run.RunWallTime != nil {
+			wallTimes = append(wallTimes, *run.RunWallTime)
+		}
+
+		if run.RunCPUTime != nil {
+			cpuTimes = append(cpuTimes, *run.RunCPUTime)
+		}
+
+		if run.RunMemory != nil && (benchmark.PeakMemory == nil || *run.RunMemory > *benchmark.PeakMemory) {
+			benchmark.PeakMemory = run.RunMemory
+		}
+	}
+
+	benchmark.WallTime = NewCodeSpaceBenchmarkStats(wallTimes)
+	benchmark.CPUTime = NewCodeSpaceBenchmarkStats(cpuTimes)
+}
+
 // GetAccessLevelFromString gets the access level from the API string representation.
 func GetAccessLevelFromString(accessLevel string) CodeSpaceAccessLevel {
 	switch accessLevel {
@@ -138,3 +527,313 @@ func GetAccessLevelFromString(accessLevel string) CodeSpaceAccessLevel {
 		return 0
 	}
 }
+
+// MatchesOutput determines whether a given standard output satisfies the expected standard output of a test case.
+// Exact comparisons require identical output, trimmed comparisons ignore leading and trailing whitespace,
+// and regex comparisons require the expected standard output pattern to match the whole trimmed output.
+func (tc *CodeSpaceTestCase) MatchesOutput(stdout string) (bool, error) {
+	switch tc.ComparisonMode {
+	case api.CodeSpaceTestComparisonModeExact:
+		return stdout == tc.ExpectedStdout, nil
+	case api.CodeSpaceTestComparisonModeTrimmed:
+		return strings.TrimSpace(stdout) == strings.TrimSpace(tc.ExpectedStdout), nil
+	case api.CodeSpaceTestComparisonModeRegex:
+		re, err := tc.CompilePattern()
+		if err != nil {
+			return false, errutils.FormatError(err)
+		}
+
+		return re.MatchString(strings.TrimSpace(stdout)), nil
+	default:
+		return false, errutils.FormatErrorf(nil, "unknown comparison mode %s", tc.ComparisonMode)
+	}
+}
+
+// CompilePattern compiles the expected standard output of a test case
+// into a regular expression that must match the whole output.
+func (tc *CodeSpaceTestCase) CompilePattern() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(`^(?:` + tc.ExpectedStdout + `)$`)
+	if err != nil {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceTestCaseInvalidPattern, err)
+	}
+
+	return re, nil
+}
+
+// Apply returns a copy of given run options with the options that are not set taken from the run configuration.
+func (rc *CodeSpaceRunConfig) Apply(opts *RunCodeSpaceOptions) *RunCodeSpaceOptions {
+	appliedOpts := *opts
+
+	if appliedOpts.Version == nil {
+		appliedOpts.Version = rc.LanguageVersion
+	}
+
+	if appliedOpts.Stdin == nil {
+		appliedOpts.Stdin = rc.Stdin
+	}
+
+	if appliedOpts.Args == nil {
+		appliedOpts.Args = rc.Args
+	}
+
+	if appliedOpts.CompileTimeout == nil {
+		appliedOpts.CompileTimeout = rc.CompileTimeout
+	}
+
+	if appliedOpts.RunTimeout == nil {
+		appliedOpts.RunTimeout = rc.RunTimeout
+	}
+
+	if appliedOpts.CompileMemoryLimit == nil {
+		appliedOpts.CompileMemoryLimit = rc.CompileMemoryLimit
+	}
+
+	if appliedOpts.RunMemoryLimit == nil {
+		appliedOpts.RunMemoryLimit = rc.RunMemoryLimit
+	}
+
+	return &appliedOpts
+}
+
+// SetResults records the outcome of a Piston execution of a test case on a test result.
+// A test case passes if compilation succeeds, the program exits with code zero,
+// and its standard output matches the expected standard output.
+func (result *CodeSpaceTestResult) SetResults(resp *api.PistonExecuteResponse) error {
+	result.Compile = resp.Compile
+	result.Run = resp.Run
+
+	matches, err := result.TestCase.MatchesOutput(resp.Run.Stdout)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	compiled := resp.Compile == nil || (resp.Compile.Code != nil && *resp.Compile.Code == 0)
+	exited := resp.Run.Code != nil && *resp.Run.Code == 0 && resp.Run.Signal == nil
+	result.Passed = compiled && exited && matches
+
+	if !matches && result.TestCase.ComparisonMode != api.CodeSpaceTestComparisonModeRegex {
+		expected := result.TestCase.ExpectedStdout
+		actual := resp.Run.Stdout
+		if result.TestCase.ComparisonMode == api.CodeSpaceTestComparisonModeTrimmed {
+			expected = strings.TrimSpace(expected)
+			actual = strings.TrimSpace(actual)
+		}
+
+		diff := DiffLines(expected, actual)
+		result.Diff = &diff
+	}
+
+	return nil
+}
+
+// HashCodeSpaceContents computes the hex-encoded SHA-256 hash of given code space contents.
+func HashCodeSpaceContents(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// DiffLines computes a line diff between expected and actual strings.
+// Lines only in expected are prefixed with "- ", lines only in actual are prefixed with "+ ",
+// and lines in both are prefixed with two spaces.
+// Changed regions too large to compare line by line are shown as a full replacement,
+// so that diffing large outputs takes bounded time and memory.
+func DiffLines(expected string, actual string) string {
+	edits := diffEdits(strings.Split(expected, "\n"), strings.Split(actual, "\n"))
+
+	diffLines := make([]string, 0, len(edits))
+	for _, e := range edits {
+		diffLines = append(diffLines, string(e.kind)+" "+e.line)
+	}
+
+	return strings.Join(diffLines, "\n")
+}
+
+// diffEdit represents a single line in a line diff, along with its position in both texts.
+type diffEdit struct {
+	kind byte
+	line string
+	from int
+	to   int
+}
+
+// UnifiedDiff returns the unified diff between two texts, labelled with the given names.
+// An empty string is returned if the texts are identical.
+func UnifiedDiff(fromName string, toName string, from string, to string) string {
+	edits := diffEdits(splitDiffLines(from), splitDiffLines(to))
+
+	changes := make([]int, 0)
+	for i, e := range edits {
+		if e.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + fromName + "\n")
+	sb.WriteString("+++ " + toName + "\n")
+
+	for i := 0; i < len(changes); {
+		start := max(changes[i]-unifiedDiffContextLines, 0)
+
+		// changes separated by no more than twice the context are merged into the same hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*unifiedDiffContextLines+1 {
+			j++
+		}
+
+		end := min(changes[j]+unifiedDiffContextLines+1, len(edits))
+		writeUnifiedDiffHunk(&sb, edits[start:end])
+		i = j + 1
+	}
+
+	return sb.String()
+}
+
+// splitDiffLines splits a given text into lines, keeping their trailing newlines.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffEdits computes the edits that turn one list of lines into another,
+// using the longest common subsequence of the lines that differ between them.
+func diffEdits(a []string, b []string) []diffEdit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, diffEdit{kind: ' ', line: a[i], from: i, to: i})
+	}
+
+	am := a[prefix : len(a)-suffix]
+	bm := b[prefix : len(b)-suffix]
+
+	if len(am)*len(bm) > diffMaxComparisons {
+		for i, line := range am {
+			edits = append(edits, diffEdit{kind: '-', line: line, from: prefix + i, to: prefix})
+		}
+
+		for j, line := range bm {
+			edits = append(edits, diffEdit{kind: '+', line: line, from: prefix + len(am), to: prefix + j})
+		}
+	} else {
+		edits = append(edits, diffEditsLCS(am, bm, prefix)...)
+	}
+
+	for k := 0; k < suffix; k++ {
+		i := len(a) - suffix + k
+		j := len(b) - suffix + k
+		edits = append(edits, diffEdit{kind: ' ', line: a[i], from: i, to: j})
+	}
+
+	return edits
+}
+
+// diffEditsLCS computes the edits that turn one list of lines into another
+// using a table of longest common subsequence lengths.
+// Line positions in the returned edits are offset by a given number of lines.
+func diffEditsLCS(a []string, b []string, offset int) []diffEdit {
+	n := len(a)
+	m := len(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]diffEdit, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			edits = append(edits, diffEdit{kind: ' ', line: a[i], from: offset + i, to: offset + j})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, diffEdit{kind: '-', line: a[i], from: offset + i, to: offset + j})
+			i++
+		default:
+			edits = append(edits, diffEdit{kind: '+', line: b[j], from: offset + i, to: offset + j})
+			j++
+		}
+	}
+
+	return edits
+}
+
+// writeUnifiedDiffHunk writes a unified diff hunk with a given list of edits.
+func writeUnifiedDiffHunk(sb *strings.Builder, edits []diffEdit) {
+	fromCount := 0
+	toCount := 0
+	for _, e := range edits {
+		if e.kind != '+' {
+			fromCount++
+		}
+
+		if e.kind != '-' {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(
+		sb,
+		"@@ -%s +%s @@\n",
+		formatUnifiedDiffHunkRange(edits[0].from, fromCount),
+		formatUnifiedDiffHunkRange(edits[0].to, toCount),
+	)
+
+	for _, e := range edits {
+		sb.WriteByte(e.kind)
+		sb.WriteString(e.line)
+
+		if !strings.HasSuffix(e.line, "\n") {
+			sb.WriteString("\n" + unifiedDiffNoNewlineMarker)
+		}
+	}
+}
+
+// formatUnifiedDiffHunkRange formats the range of lines in a unified diff hunk,
+// given its zero-based start and line count.
+// Empty ranges refer to the line before them, as the start of a text is line 1.
+func formatUnifiedDiffHunkRange(start int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
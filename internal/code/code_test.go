@@ -2,12 +2,14 @@ package code_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/alvii147/nymphadora-api/internal/code"
 	"github.com/alvii147/nymphadora-api/internal/database"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
 	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/validate"
 	"github.com/stretchr/testify/require"
 )
@@ -122,6 +124,277 @@ func TestCodeSpaceAccessLevelString(t *testing.T) {
 	}
 }
 
+func TestCodeSpaceRunSetResults(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	signal := "SIGKILL"
+	timedOutMessage := "Time limit exceeded"
+	timedOutStatus := api.PistonStatusTimedOut
+	cpuTime := int64(2987)
+	wallTime := int64(3012)
+	memory := int64(8704000)
+
+	testcases := map[string]struct {
+		resp *api.PistonExecuteResponse
+	}{
+		"Runtime results only": {
+			resp: &api.PistonExecuteResponse{
+				Compile: nil,
+				Run: api.PistonResults{
+					Stdout: "Yello!\n",
+					Stderr: "",
+					Code:   &exitCodeZero,
+					Signal: nil,
+				},
+			},
+		},
+		"Compilation and runtime results": {
+			resp: &api.PistonExecuteResponse{
+				Compile: &api.PistonResults{
+					Stdout: "",
+					Stderr: "warning: unused variable",
+					Code:   &exitCodeZero,
+					Signal: nil,
+				},
+				Run: api.PistonResults{
+					Stdout: "",
+					Stderr: "Segmentation fault",
+					Code:   &exitCodeOne,
+					Signal: &signal,
+				},
+			},
+		},
+		"Runtime results with metrics": {
+			resp: &api.PistonExecuteResponse{
+				Compile: nil,
+				Run: api.PistonResults{
+					Stdout:   "",
+					Stderr:   "",
+					Code:     nil,
+					Signal:   &signal,
+					Message:  &timedOutMessage,
+					Status:   &timedOutStatus,
+					CPUTime:  &cpuTime,
+					WallTime: &wallTime,
+					Memory:   &memory,
+				},
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			codeSpaceRun := &code.CodeSpaceRun{}
+			codeSpaceRun.SetResults(testcase.resp)
+
+			if testcase.resp.Compile != nil {
+				require.NotNil(t, codeSpaceRun.CompileStdout)
+				require.Equal(t, testcase.resp.Compile.Stdout, *codeSpaceRun.CompileStdout)
+				require.NotNil(t, codeSpaceRun.CompileStderr)
+				require.Equal(t, testcase.resp.Compile.Stderr, *codeSpaceRun.CompileStderr)
+				require.Equal(t, testcase.resp.Compile.Code, codeSpaceRun.CompileCode)
+				require.Equal(t, testcase.resp.Compile.Signal, codeSpaceRun.CompileSignal)
+			} else {
+				require.Nil(t, codeSpaceRun.CompileStdout)
+				require.Nil(t, codeSpaceRun.CompileStderr)
+				require.Nil(t, codeSpaceRun.CompileCode)
+				require.Nil(t, codeSpaceRun.CompileSignal)
+			}
+
+			require.NotNil(t, codeSpaceRun.RunStdout)
+			require.Equal(t, testcase.resp.Run.Stdout, *codeSpaceRun.RunStdout)
+			require.NotNil(t, codeSpaceRun.RunStderr)
+			require.Equal(t, testcase.resp.Run.Stderr, *codeSpaceRun.RunStderr)
+			require.Equal(t, testcase.resp.Run.Code, codeSpaceRun.RunCode)
+			require.Equal(t, testcase.resp.Run.Signal, codeSpaceRun.RunSignal)
+			require.Equal(t, testcase.resp.Run.Message, codeSpaceRun.RunMessage)
+			require.Equal(t, testcase.resp.Run.Status, codeSpaceRun.RunStatus)
+			require.Equal(t, testcase.resp.Run.CPUTime, codeSpaceRun.RunCPUTime)
+			require.Equal(t, testcase.resp.Run.WallTime, codeSpaceRun.RunWallTime)
+			require.Equal(t, testcase.resp.Run.Memory, codeSpaceRun.RunMemory)
+			require.Equal(t, testcase.resp.Run.TimedOut(), codeSpaceRun.RunTimedOut)
+			require.Equal(t, testcase.resp.Run.MemoryLimitExceeded(), codeSpaceRun.RunMemoryLimitExceeded)
+		})
+	}
+}
+
+func TestCodeSpaceRunSucceeded(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	signal := "SIGKILL"
+
+	testcases := map[string]struct {
+		run           *code.CodeSpaceRun
+		wantSucceeded bool
+	}{
+		"Exited with code zero": {
+			run: &code.CodeSpaceRun{
+				Status:  api.CodeSpaceRunStatusCompleted,
+				RunCode: &exitCodeZero,
+			},
+			wantSucceeded: true,
+		},
+		"Compiled and exited with code zero": {
+			run: &code.CodeSpaceRun{
+				Status:      api.CodeSpaceRunStatusCompleted,
+				CompileCode: &exitCodeZero,
+				RunCode:     &exitCodeZero,
+			},
+			wantSucceeded: true,
+		},
+		"Compilation failed": {
+			run: &code.CodeSpaceRun{
+				Status:      api.CodeSpaceRunStatusCompleted,
+				CompileCode: &exitCodeOne,
+			},
+			wantSucceeded: false,
+		},
+		"Exited with non-zero code": {
+			run: &code.CodeSpaceRun{
+				Status:  api.CodeSpaceRunStatusCompleted,
+				RunCode: &exitCodeOne,
+			},
+			wantSucceeded: false,
+		},
+		"Killed by signal": {
+			run: &code.CodeSpaceRun{
+				Status:    api.CodeSpaceRunStatusCompleted,
+				RunSignal: &signal,
+			},
+			wantSucceeded: false,
+		},
+		"Not completed": {
+			run: &code.CodeSpaceRun{
+				Status: api.CodeSpaceRunStatusFailed,
+			},
+			wantSucceeded: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantSucceeded, testcase.run.Succeeded())
+		})
+	}
+}
+
+func TestNewCodeSpaceBenchmarkStats(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		values    []int64
+		wantStats *code.CodeSpaceBenchmarkStats
+	}{
+		"No values": {
+			values:    []int64{},
+			wantStats: nil,
+		},
+		"Single value": {
+			values: []int64{42},
+			wantStats: &code.CodeSpaceBenchmarkStats{
+				Min:    42,
+				Median: 42,
+				P95:    42,
+				Max:    42,
+			},
+		},
+		"Unsorted values": {
+			values: []int64{30, 10, 40, 20},
+			wantStats: &code.CodeSpaceBenchmarkStats{
+				Min:    10,
+				Median: 20,
+				P95:    40,
+				Max:    40,
+			},
+		},
+		"Twenty values": {
+			values: []int64{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+			wantStats: &code.CodeSpaceBenchmarkStats{
+				Min:    1,
+				Median: 10,
+				P95:    19,
+				Max:    20,
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantStats, code.NewCodeSpaceBenchmarkStats(testcase.values))
+		})
+	}
+}
+
+func TestCodeSpaceBenchmarkSummarize(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	newRun := func(exitCode *int, wallTime int64, cpuTime int64, memory int64) *code.CodeSpaceRun {
+		return &code.CodeSpaceRun{
+			Status:      api.CodeSpaceRunStatusCompleted,
+			RunCode:     exitCode,
+			RunWallTime: &wallTime,
+			RunCPUTime:  &cpuTime,
+			RunMemory:   &memory,
+		}
+	}
+
+	benchmark := &code.CodeSpaceBenchmark{
+		Iterations: 4,
+		Runs: []*code.CodeSpaceRun{
+			newRun(&exitCodeZero, 120, 100, 2048),
+			newRun(&exitCodeZero, 100, 90, 4096),
+			newRun(&exitCodeZero, 140, 110, 1024),
+			newRun(&exitCodeOne, 900, 800, 8192),
+		},
+		StoppedEarly: false,
+	}
+
+	benchmark.Summarize()
+
+	require.Equal(t, &code.CodeSpaceBenchmarkStats{
+		Min:    100,
+		Median: 120,
+		P95:    140,
+		Max:    140,
+	}, benchmark.WallTime)
+	require.Equal(t, &code.CodeSpaceBenchmarkStats{
+		Min:    90,
+		Median: 100,
+		P95:    110,
+		Max:    110,
+	}, benchmark.CPUTime)
+	require.NotNil(t, benchmark.PeakMemory)
+	require.Equal(t, int64(4096), *benchmark.PeakMemory)
+
+	emptyBenchmark := &code.CodeSpaceBenchmark{
+		Iterations: 1,
+		Runs: []*code.CodeSpaceRun{
+			{
+				Status:  api.CodeSpaceRunStatusCompleted,
+				RunCode: &exitCodeZero,
+			},
+		},
+	}
+
+	emptyBenchmark.Summarize()
+
+	require.Nil(t, emptyBenchmark.WallTime)
+	require.Nil(t, emptyBenchmark.CPUTime)
+	require.Nil(t, emptyBenchmark.PeakMemory)
+}
+
 func TestGetAccessLevelFromString(t *testing.T) {
 	t.Parallel()
 
@@ -151,3 +424,406 @@ func TestGetAccessLevelFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestCodeSpaceTestCaseMatchesOutput(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		comparisonMode string
+		expectedStdout string
+		stdout         string
+		wantMatches    bool
+		wantErr        error
+	}{
+		"Exact match": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "Yello!\n",
+			stdout:         "Yello!\n",
+			wantMatches:    true,
+			wantErr:        nil,
+		},
+		"Exact mismatch on whitespace": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "Yello!",
+			stdout:         "Yello!\n",
+			wantMatches:    false,
+			wantErr:        nil,
+		},
+		"Trimmed match ignores surrounding whitespace": {
+			comparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+			expectedStdout: "Yello!",
+			stdout:         "  Yello!\n\n",
+			wantMatches:    true,
+			wantErr:        nil,
+		},
+		"Trimmed mismatch": {
+			comparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+			expectedStdout: "Yello!",
+			stdout:         "Hello!\n",
+			wantMatches:    false,
+			wantErr:        nil,
+		},
+		"Regex matches whole output": {
+			comparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			expectedStdout: `\d+ apples?`,
+			stdout:         "42 apples\n",
+			wantMatches:    true,
+			wantErr:        nil,
+		},
+		"Regex does not match partial output": {
+			comparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			expectedStdout: `\d+`,
+			stdout:         "42 apples\n",
+			wantMatches:    false,
+			wantErr:        nil,
+		},
+		"Invalid regex": {
+			comparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			expectedStdout: `(\d+`,
+			stdout:         "42\n",
+			wantMatches:    false,
+			wantErr:        errutils.ErrCodeSpaceTestCaseInvalidPattern,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			codeSpaceTestCase := &code.CodeSpaceTestCase{
+				ExpectedStdout: testcase.expectedStdout,
+				ComparisonMode: testcase.comparisonMode,
+			}
+
+			matches, err := codeSpaceTestCase.MatchesOutput(testcase.stdout)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantMatches, matches)
+		})
+	}
+}
+
+func TestCodeSpaceRunConfigApply(t *testing.T) {
+	t.Parallel()
+
+	configStdin := "Harry\n"
+	configVersion := "3.9.4"
+	configCompileTimeout := int64(5000)
+	configRunTimeout := int64(3000)
+	configCompileMemoryLimit := int64(128000000)
+	configRunMemoryLimit := int64(64000000)
+	codeSpaceRunConfig := &code.CodeSpaceRunConfig{
+		Name:               "sorting-hat",
+		Stdin:              &configStdin,
+		Args:               []string{"--house", "gryffindor"},
+		CompileTimeout:     &configCompileTimeout,
+		RunTimeout:         &configRunTimeout,
+		CompileMemoryLimit: &configCompileMemoryLimit,
+		RunMemoryLimit:     &configRunMemoryLimit,
+		LanguageVersion:    &configVersion,
+	}
+
+	runConfigName := codeSpaceRunConfig.Name
+	stdin := "Hermione\n"
+	version := "3.10.0"
+	runTimeout := int64(1000)
+
+	testcases := map[string]struct {
+		opts     *code.RunCodeSpaceOptions
+		wantOpts *code.RunCodeSpaceOptions
+	}{
+		"No options set": {
+			opts: &code.RunCodeSpaceOptions{
+				RunConfig: &runConfigName,
+			},
+			wantOpts: &code.RunCodeSpaceOptions{
+				Version:            &configVersion,
+				Stdin:              &configStdin,
+				Args:               []string{"--house", "gryffindor"},
+				CompileTimeout:     &configCompileTimeout,
+				RunTimeout:         &configRunTimeout,
+				CompileMemoryLimit: &configCompileMemoryLimit,
+				RunMemoryLimit:     &configRunMemoryLimit,
+				RunConfig:          &runConfigName,
+			},
+		},
+		"Options set take precedence": {
+			opts: &code.RunCodeSpaceOptions{
+				Version:    &version,
+				Stdin:      &stdin,
+				Args:       []string{},
+				RunTimeout: &runTimeout,
+				NoCache:    true,
+				RunConfig:  &runConfigName,
+			},
+			wantOpts: &code.RunCodeSpaceOptions{
+				Version:            &version,
+				Stdin:              &stdin,
+				Args:               []string{},
+				CompileTimeout:     &configCompileTimeout,
+				RunTimeout:         &runTimeout,
+				CompileMemoryLimit: &configCompileMemoryLimit,
+				RunMemoryLimit:     &configRunMemoryLimit,
+				NoCache:            true,
+				RunConfig:          &runConfigName,
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := *testcase.opts
+			appliedOpts := codeSpaceRunConfig.Apply(testcase.opts)
+			require.Equal(t, testcase.wantOpts, appliedOpts)
+			require.Equal(t, &opts, testcase.opts)
+		})
+	}
+}
+
+func TestCodeSpaceTestResultSetResults(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	signal := "SIGKILL"
+
+	testcases := map[string]struct {
+		comparisonMode string
+		expectedStdout string
+		resp           *api.PistonExecuteResponse
+		wantPassed     bool
+		wantDiff       string
+	}{
+		"Matching output passes": {
+			comparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+			expectedStdout: "4",
+			resp: &api.PistonExecuteResponse{
+				Run: api.PistonResults{
+					Stdout: "4\n",
+					Code:   &exitCodeZero,
+				},
+			},
+			wantPassed: true,
+			wantDiff:   "",
+		},
+		"Mismatching output fails with diff": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "1\n2\n3",
+			resp: &api.PistonExecuteResponse{
+				Run: api.PistonResults{
+					Stdout: "1\n4\n3",
+					Code:   &exitCodeZero,
+				},
+			},
+			wantPassed: false,
+			wantDiff:   "  1\n- 2\n+ 4\n  3",
+		},
+		"Non-zero exit code fails": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "",
+			resp: &api.PistonExecuteResponse{
+				Run: api.PistonResults{
+					Stdout: "",
+					Code:   &exitCodeOne,
+				},
+			},
+			wantPassed: false,
+			wantDiff:   "",
+		},
+		"Killed by signal fails": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "",
+			resp: &api.PistonExecuteResponse{
+				Run: api.PistonResults{
+					Stdout: "",
+					Code:   nil,
+					Signal: &signal,
+				},
+			},
+			wantPassed: false,
+			wantDiff:   "",
+		},
+		"Failed compilation fails": {
+			comparisonMode: api.CodeSpaceTestComparisonModeExact,
+			expectedStdout: "",
+			resp: &api.PistonExecuteResponse{
+				Compile: &api.PistonResults{
+					Stderr: "error: expected ';'",
+					Code:   &exitCodeOne,
+				},
+				Run: api.PistonResults{
+					Stdout: "",
+					Code:   &exitCodeZero,
+				},
+			},
+			wantPassed: false,
+			wantDiff:   "",
+		},
+		"Mismatching regex fails without diff": {
+			comparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			expectedStdout: `\d+`,
+			resp: &api.PistonExecuteResponse{
+				Run: api.PistonResults{
+					Stdout: "four\n",
+					Code:   &exitCodeZero,
+				},
+			},
+			wantPassed: false,
+			wantDiff:   "",
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			codeSpaceTestResult := &code.CodeSpaceTestResult{
+				TestCase: &code.CodeSpaceTestCase{
+					ExpectedStdout: testcase.expectedStdout,
+					ComparisonMode: testcase.comparisonMode,
+				},
+			}
+
+			err := codeSpaceTestResult.SetResults(testcase.resp)
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantPassed, codeSpaceTestResult.Passed)
+			require.Equal(t, testcase.resp.Compile, codeSpaceTestResult.Compile)
+			require.Equal(t, testcase.resp.Run, codeSpaceTestResult.Run)
+
+			if testcase.wantDiff == "" {
+				require.Nil(t, codeSpaceTestResult.Diff)
+
+				return
+			}
+
+			require.NotNil(t, codeSpaceTestResult.Diff)
+			require.Equal(t, testcase.wantDiff, *codeSpaceTestResult.Diff)
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		expected string
+		actual   string
+		wantDiff string
+	}{
+		"Identical": {
+			expected: "a\nb",
+			actual:   "a\nb",
+			wantDiff: "  a\n  b",
+		},
+		"Changed line": {
+			expected: "a\nb\nc",
+			actual:   "a\nx\nc",
+			wantDiff: "  a\n- b\n+ x\n  c",
+		},
+		"Missing lines": {
+			expected: "a\nb\nc",
+			actual:   "a",
+			wantDiff: "  a\n- b\n- c",
+		},
+		"Extra lines": {
+			expected: "a",
+			actual:   "x\na\ny",
+			wantDiff: "+ x\n  a\n+ y",
+		},
+		"Too many lines to compare": {
+			expected: strings.Repeat("a\n", 2999) + "a",
+			actual:   strings.Repeat("b\n", 2999) + "b",
+			wantDiff: strings.Repeat("- a\n", 3000) + strings.TrimSuffix(strings.Repeat("+ b\n", 3000), "\n"),
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantDiff, code.DiffLines(testcase.expected, testcase.actual))
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		from     string
+		to       string
+		wantDiff string
+	}{
+		"Identical": {
+			from:     "print('hello')\n",
+			to:       "print('hello')\n",
+			wantDiff: "",
+		},
+		"Empty": {
+			from:     "",
+			to:       "",
+			wantDiff: "",
+		},
+		"Changed line": {
+			from:     "a\nb\nc\n",
+			to:       "a\nB\nc\n",
+			wantDiff: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"Added to empty": {
+			from:     "",
+			to:       "a\n",
+			wantDiff: "--- from\n+++ to\n@@ -0,0 +1 @@\n+a\n",
+		},
+		"Removed all lines": {
+			from:     "a\n",
+			to:       "",
+			wantDiff: "--- from\n+++ to\n@@ -1 +0,0 @@\n-a\n",
+		},
+		"No newline at end": {
+			from: "a\nb",
+			to:   "a\nc",
+			wantDiff: "--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n" +
+				"-b\n\\ No newline at end of file\n" +
+				"+c\n\\ No newline at end of file\n",
+		},
+		"Replaced lines": {
+			from:     "x\ny\n",
+			to:       "p\nq\nr\n",
+			wantDiff: "--- from\n+++ to\n@@ -1,2 +1,3 @@\n-x\n-y\n+p\n+q\n+r\n",
+		},
+		"Nearby changes in single hunk": {
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:       "1\nX\n3\n4\n5\n6\n7\nY\n9\n",
+			wantDiff: "--- from\n+++ to\n@@ -1,9 +1,9 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n 9\n",
+		},
+		"Distant changes in separate hunks": {
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n",
+			to:   "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\nY\n19\n20\n",
+			wantDiff: "--- from\n+++ to\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+Y\n 19\n 20\n",
+		},
+		"Large replacement": {
+			from: "header\n" + strings.Repeat("from\n", 4096),
+			to:   "header\n" + strings.Repeat("to\n", 4096),
+			wantDiff: "--- from\n+++ to\n@@ -1,4097 +1,4097 @@\n header\n" +
+				strings.Repeat("-from\n", 4096) +
+				strings.Repeat("+to\n", 4096),
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantDiff, code.UnifiedDiff("from", "to", testcase.from, testcase.to))
+		})
+	}
+}
@@ -0,0 +1,874 @@
+package code
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alvii147/nymphadora-api/internal/auth"
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/google/uuid"
+)
+
+const (
+	// codeSpaceCollabHistoryLength is the number of recent edit operations kept for each collaboratively edited
+	// code space, which bounds how far behind the current revision edit operations may be based.
+	codeSpaceCollabHistoryLength = 1000
+	// codeSpaceCollabEventBufferSize is the number of events that may be queued for a collaborator
+	// before it is disconnected for falling behind.
+	codeSpaceCollabEventBufferSize = 256
+)
+
+// EditComponent represents a single step of an edit operation.
+// Exactly one of Retain, Insert and Delete is set, and lengths are counted in Unicode code points.
+type EditComponent struct {
+	Retain int
+	Insert string
+	Delete int
+}
+
+// EditOperation represents an edit of a whole document, as a sequence of edit components
+// that walk over the document from start to end, retaining, inserting or deleting text as they go.
+type EditOperation []EditComponent
+
+// retain appends a component to an edit operation that retains a given number of code points,
+// merging it into the last component if it also retains code points.
+func (op *EditOperation) retain(n int) {
+	if n == 0 {
+		return
+	}
+
+	if last := len(*op) - 1; last >= 0 && (*op)[last].Retain > 0 {
+		(*op)[last].Retain += n
+
+		return
+	}
+
+	*op = append(*op, EditComponent{Retain: n})
+}
+
+// insert appends a component to an edit operation that inserts a given string,
+// merging it into neighbouring insert components.
+// Insertions are kept ahead of deletions at the same position, so that equivalent operations look the same.
+func (op *EditOperation) insert(s string) {
+	if s == "" {
+		return
+	}
+
+	last := len(*op) - 1
+	if last >= 0 && (*op)[last].Insert != "" {
+		(*op)[last].Insert += s
+
+		return
+	}
+
+	if last >= 0 && (*op)[last].Delete > 0 {
+		if last > 0 && (*op)[last-1].Insert != "" {
+			(*op)[last-1].Insert += s
+
+			return
+		}
+
+		*op = append(*op, (*op)[last])
+		(*op)[last] = EditComponent{Insert: s}
+
+		return
+	}
+
+	*op = append(*op, EditComponent{Insert: s})
+}
+
+// delete appends a component to an edit operation that deletes a given number of code points,
+// merging it into the last component if it also deletes code points.
+func (op *EditOperation) delete(n int) {
+	if n == 0 {
+		return
+	}
+
+	if last := len(*op) - 1; last >= 0 && (*op)[last].Delete > 0 {
+		(*op)[last].Delete += n
+
+		return
+	}
+
+	*op = append(*op, EditComponent{Delete: n})
+}
+
+// Validate checks that every component of an edit operation does exactly one thing.
+func (op EditOperation) Validate() error {
+	for i, component := range op {
+		set := 0
+		if component.Retain != 0 {
+			set++
+		}
+
+		if component.Insert != "" {
+			set++
+		}
+
+		if component.Delete != 0 {
+			set++
+		}
+
+		if set != 1 || component.Retain < 0 || component.Delete < 0 {
+			return errutils.FormatErrorf(errutils.ErrCodeSpaceEditInvalid, "invalid component at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// BaseLength returns the length of the documents an edit operation can be applied to.
+func (op EditOperation) BaseLength() int {
+	length := 0
+	for _, component := range op {
+		length += component.Retain + component.Delete
+	}
+
+	return length
+}
+
+// TargetLength returns the length of the documents an edit operation produces.
+func (op EditOperation) TargetLength() int {
+	length := 0
+	for _, component := range op {
+		length += component.Retain + utf8.RuneCountInString(component.Insert)
+	}
+
+	return length
+}
+
+// Apply applies an edit operation to a given document and returns the edited document.
+// The operation must walk over the whole document.
+func (op EditOperation) Apply(doc string) (string, error) {
+	runes := []rune(doc)
+	if op.BaseLength() != len(runes) {
+		return "", errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEditInvalid,
+			"operation base length %d does not match document length %d",
+			op.BaseLength(),
+			len(runes),
+		)
+	}
+
+	edited := make([]rune, 0, op.TargetLength())
+	position := 0
+	for _, component := range op {
+		switch {
+		case component.Retain > 0:
+			edited = append(edited, runes[position:position+component.Retain]...)
+			position += component.Retain
+		case component.Insert != "":
+			edited = append(edited, []rune(component.Insert)...)
+		default:
+			position += component.Delete
+		}
+	}
+
+	return string(edited), nil
+}
+
+// TransformEditOperations transforms two concurrent edit operations a and b made to the same document
+// into a' and b', such that applying a then b' gives the same document as applying b then a'.
+// When both operations insert at the same position, the text inserted by a ends up first.
+func TransformEditOperations(a EditOperation, b EditOperation) (EditOperation, EditOperation, error) {
+	if a.BaseLength() != b.BaseLength() {
+		return nil, nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEditInvalid,
+			"operation base lengths %d and %d do not match",
+			a.BaseLength(),
+			b.BaseLength(),
+		)
+	}
+
+	var aPrime, bPrime EditOperation
+	i, j := 0, 0
+	var componentA, componentB *EditComponent
+	next := func(op EditOperation, idx *int) *EditComponent {
+		if *idx >= len(op) {
+			return nil
+		}
+
+		component := op[*idx]
+		*idx++
+
+		return &component
+	}
+
+	componentA = next(a, &i)
+	componentB = next(b, &j)
+	for componentA != nil || componentB != nil {
+		if componentA != nil && componentA.Insert != "" {
+			aPrime.insert(componentA.Insert)
+			bPrime.retain(utf8.RuneCountInString(componentA.Insert))
+			componentA = next(a, &i)
+
+			continue
+		}
+
+		if componentB != nil && componentB.Insert != "" {
+			aPrime.retain(utf8.RuneCountInString(componentB.Insert))
+			bPrime.insert(componentB.Insert)
+			componentB = next(b, &j)
+
+			continue
+		}
+
+		// base lengths match, so both operations run out of retains and deletes at the same time
+		lengthA := componentA.Retain + componentA.Delete
+		lengthB := componentB.Retain + componentB.Delete
+		length := min(lengthA, lengthB)
+
+		switch {
+		case componentA.Retain > 0 && componentB.Retain > 0:
+			aPrime.retain(length)
+			bPrime.retain(length)
+		case componentA.Delete > 0 && componentB.Retain > 0:
+			aPrime.delete(length)
+		case componentA.Retain > 0 && componentB.Delete > 0:
+			bPrime.delete(length)
+		}
+
+		if componentA.Retain > 0 {
+			componentA.Retain -= length
+		} else {
+			componentA.Delete -= length
+		}
+
+		if componentB.Retain > 0 {
+			componentB.Retain -= length
+		} else {
+			componentB.Delete -= length
+		}
+
+		if lengthA == length {
+			componentA = next(a, &i)
+		}
+
+		if lengthB == length {
+			componentB = next(b, &j)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+// TransformPosition returns where a given position in a document ends up once an edit operation is applied to it.
+// Text inserted at the position pushes it forward, and deleted text around it pulls it back.
+func (op EditOperation) TransformPosition(position int) int {
+	remaining := position
+	transformed := position
+	for _, component := range op {
+		switch {
+		case component.Retain > 0:
+			remaining -= component.Retain
+		case component.Insert != "":
+			transformed += utf8.RuneCountInString(component.Insert)
+		default:
+			transformed -= min(remaining, component.Delete)
+			remaining -= component.Delete
+		}
+
+		if remaining < 0 {
+			break
+		}
+	}
+
+	return transformed
+}
+
+// CodeSpaceCursor represents the cursor of a collaborator in a collaboratively edited code space.
+// Position is where the cursor is, and Anchor is where the selection started,
+// so that the selection spans from Anchor to Position, and there is no selection if they are equal.
+// Both are counted in Unicode code points.
+type CodeSpaceCursor struct {
+	Position int
+	Anchor   int
+}
+
+// transform returns where the cursor ends up once a given edit operation is applied.
+func (cursor *CodeSpaceCursor) transform(operation EditOperation) *CodeSpaceCursor {
+	return &CodeSpaceCursor{
+		Position: operation.TransformPosition(cursor.Position),
+		Anchor:   operation.TransformPosition(cursor.Anchor),
+	}
+}
+
+// CodeSpacePresence represents a collaborator currently connected to a collaboratively edited code space.
+// Each connection is a separate session, so the same user may be present more than once.
+// Cursor is nil until the collaborator shares its cursor.
+type CodeSpacePresence struct {
+	SessionID   string
+	UserUUID    string
+	FirstName   string
+	LastName    string
+	AccessLevel CodeSpaceAccessLevel
+	Cursor      *CodeSpaceCursor
+	JoinedAt    time.Time
+	LastSeenAt  time.Time
+}
+
+// CodeSpaceCollabEvent represents an event sent to a collaborator of a collaboratively edited code space.
+// Type is one of the api.CodeSpaceCollabEventType* values.
+// Snapshot events carry the whole document, the collaborator's own presence and the presence of everyone else,
+// edit events carry edits made by other collaborators, or by users saving the code space outside of collaborative
+// editing, in which case UserUUID is empty if the user is not known,
+// and acknowledgement events confirm that the collaborator's own edit was applied.
+// Join, leave and cursor events carry the presence of other collaborators as it changes.
+type CodeSpaceCollabEvent struct {
+	Type          string
+	Revision      int64
+	Contents      string
+	Operation     EditOperation
+	UserUUID      string
+	Presence      *CodeSpacePresence
+	Collaborators []*CodeSpacePresence
+}
+
+// codeSpaceCollab keeps the documents of code spaces that are being edited collaboratively in memory.
+// Documents are loaded when the first collaborator joins,
+// and unloaded once they have been saved and no collaborators are left.
+type codeSpaceCollab struct {
+	mu           sync.Mutex
+	timeProvider timekeeper.Provider
+	documents    map[int64]*codeSpaceCollabDocument
+}
+
+// newCodeSpaceCollab returns a new codeSpaceCollab.
+func newCodeSpaceCollab(timeProvider timekeeper.Provider) *codeSpaceCollab {
+	return &codeSpaceCollab{
+		timeProvider: timeProvider,
+		documents:    make(map[int64]*codeSpaceCollabDocument),
+	}
+}
+
+// codeSpaceCollabDocument is the in-memory state of a code space that is being edited collaboratively.
+// Revisions count the edit operations applied since the document was loaded.
+// The saved contents are the contents of the code space at the given version as it was last loaded or saved,
+// which the document is only saved over if the code space is still at that version.
+type codeSpaceCollabDocument struct {
+	mu             sync.Mutex
+	timeProvider   timekeeper.Provider
+	codeSpaceID    int64
+	contents       string
+	revision       int64
+	savedRevision  int64
+	savedContents  string
+	version        int64
+	history        []EditOperation
+	lastEditorUUID string
+	collaborators  map[*CodeSpaceCollaborator]struct{}
+	closed         bool
+}
+
+// CodeSpaceCollaborator represents a single session of a user taking part in the collaborative editing of a code space.
+// Events for the collaborator are received from Events,
+// which is closed once the collaborator leaves, times out or is disconnected for falling behind.
+type CodeSpaceCollaborator struct {
+	SessionID   string
+	UserUUID    string
+	FirstName   string
+	LastName    string
+	AccessLevel CodeSpaceAccessLevel
+	JoinedAt    time.Time
+	document    *codeSpaceCollabDocument
+	events      chan *CodeSpaceCollabEvent
+	cursor      *CodeSpaceCursor
+	lastSeenAt  time.Time
+}
+
+// join adds a collaborator for a given user with a given access level to the document of a given code space,
+// loading the document if it is not in memory yet.
+// The collaborator's first event is a snapshot of the document, and everyone else is told that it joined.
+func (collab *codeSpaceCollab) join(
+	codeSpace *CodeSpace,
+	user *auth.User,
+	accessLevel CodeSpaceAccessLevel,
+) *CodeSpaceCollaborator {
+	collab.mu.Lock()
+	defer collab.mu.Unlock()
+
+	document, ok := collab.documents[codeSpace.ID]
+	if !ok {
+		document = &codeSpaceCollabDocument{
+			timeProvider:  collab.timeProvider,
+			codeSpaceID:   codeSpace.ID,
+			contents:      codeSpace.Contents,
+			savedContents: codeSpace.Contents,
+			version:       codeSpace.Version,
+			collaborators: make(map[*CodeSpaceCollaborator]struct{}),
+		}
+		collab.documents[codeSpace.ID] = document
+	}
+
+	now := collab.timeProvider.Now()
+	collaborator := &CodeSpaceCollaborator{
+		SessionID:   uuid.NewString(),
+		UserUUID:    user.UUID,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		AccessLevel: accessLevel,
+		JoinedAt:    now,
+		document:    document,
+		events:      make(chan *CodeSpaceCollabEvent, codeSpaceCollabEventBufferSize),
+		lastSeenAt:  now,
+	}
+
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	presence := collaborator.presence()
+	collaborators := make([]*CodeSpacePresence, 0, len(document.collaborators))
+	for other := range document.collaborators {
+		collaborators = append(collaborators, other.presence())
+		document.send(other, &CodeSpaceCollabEvent{
+			Type:     api.CodeSpaceCollabEventTypeJoin,
+			Presence: presence,
+		})
+	}
+
+	document.collaborators[collaborator] = struct{}{}
+	document.send(collaborator, &CodeSpaceCollabEvent{
+		Type:          api.CodeSpaceCollabEventTypeSnapshot,
+		Revision:      document.revision,
+		Contents:      document.contents,
+		Presence:      presence,
+		Collaborators: collaborators,
+	})
+
+	return collaborator
+}
+
+// snapshot returns the documents currently in memory.
+func (collab *codeSpaceCollab) snapshot() []*codeSpaceCollabDocument {
+	collab.mu.Lock()
+	defer collab.mu.Unlock()
+
+	documents := make([]*codeSpaceCollabDocument, 0, len(collab.documents))
+	for _, document := range collab.documents {
+		documents = append(documents, document)
+	}
+
+	return documents
+}
+
+// presences returns the presence of the collaborators of a given code space,
+// which is empty if the code space is not being edited collaboratively.
+func (collab *codeSpaceCollab) presences(codeSpaceID int64) []*CodeSpacePresence {
+	collab.mu.Lock()
+	defer collab.mu.Unlock()
+
+	document, ok := collab.documents[codeSpaceID]
+	if !ok {
+		return []*CodeSpacePresence{}
+	}
+
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	presences := make([]*CodeSpacePresence, 0, len(document.collaborators))
+	for collaborator := range document.collaborators {
+		presences = append(presences, collaborator.presence())
+	}
+
+	return presences
+}
+
+// rebase rebases the document of a given code space, if it is in memory,
+// onto the code space as it was saved outside of collaborative editing by a given user.
+// Documents that cannot be rebased are closed, so that their collaborators rejoin from the saved code space.
+func (collab *codeSpaceCollab) rebase(codeSpace *CodeSpace, userUUID string) {
+	collab.mu.Lock()
+	document, ok := collab.documents[codeSpace.ID]
+	collab.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	err := document.rebase(codeSpace.Contents, codeSpace.Version, userUUID)
+	if err != nil {
+		collab.close(document)
+	}
+}
+
+// unload removes a given document from memory if it has been saved and no collaborators are left.
+func (collab *codeSpaceCollab) unload(document *codeSpaceCollabDocument) {
+	collab.mu.Lock()
+	defer collab.mu.Unlock()
+
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if len(document.collaborators) > 0 || document.revision != document.savedRevision {
+		return
+	}
+
+	document.closed = true
+	delete(collab.documents, document.codeSpaceID)
+}
+
+// close removes a given document from memory and disconnects all of its collaborators.
+func (collab *codeSpaceCollab) close(document *codeSpaceCollabDocument) {
+	collab.mu.Lock()
+	defer collab.mu.Unlock()
+
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	document.closed = true
+	for collaborator := range document.collaborators {
+		document.remove(collaborator)
+	}
+
+	delete(collab.documents, document.codeSpaceID)
+}
+
+// expire disconnects collaborators that have not been heard from since a given time.
+// It returns the number of disconnected collaborators.
+func (collab *codeSpaceCollab) expire(lastSeenBefore time.Time) int64 {
+	expiredCount := int64(0)
+	for _, document := range collab.snapshot() {
+		document.mu.Lock()
+		for collaborator := range document.collaborators {
+			if collaborator.lastSeenAt.Before(lastSeenBefore) {
+				document.remove(collaborator)
+				expiredCount++
+			}
+		}
+		document.mu.Unlock()
+	}
+
+	return expiredCount
+}
+
+// send queues an event for a given collaborator of the document,
+// disconnecting the collaborator if its queue is full.
+// The document must be locked.
+func (document *codeSpaceCollabDocument) send(collaborator *CodeSpaceCollaborator, event *CodeSpaceCollabEvent) {
+	select {
+	case collaborator.events <- event:
+	default:
+		document.remove(collaborator)
+	}
+}
+
+// remove removes a given collaborator from the document and closes its events,
+// telling everyone else that it left unless the document is being closed.
+// The document must be locked.
+func (document *codeSpaceCollabDocument) remove(collaborator *CodeSpaceCollaborator) {
+	if _, ok := document.collaborators[collaborator]; !ok {
+		return
+	}
+
+	delete(document.collaborators, collaborator)
+	close(collaborator.events)
+
+	if document.closed {
+		return
+	}
+
+	presence := collaborator.presence()
+	for other := range document.collaborators {
+		document.send(other, &CodeSpaceCollabEvent{
+			Type:     api.CodeSpaceCollabEventTypeLeave,
+			Presence: presence,
+		})
+	}
+}
+
+// operationsSince returns the edit operations applied to the document since a given revision.
+// The document must be locked.
+func (document *codeSpaceCollabDocument) operationsSince(revision int64) ([]EditOperation, error) {
+	historyStart := document.revision - int64(len(document.history))
+	if revision > document.revision {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEditInvalid,
+			"revision %d is ahead of current revision %d",
+			revision,
+			document.revision,
+		)
+	}
+
+	if revision < historyStart {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEditOutdated,
+			"revision %d is older than the oldest known revision %d",
+			revision,
+			historyStart,
+		)
+	}
+
+	return document.history[revision-historyStart:], nil
+}
+
+// unsaved returns the contents and revision of the document, the version of the code space it may be saved over,
+// and the user who last edited it, if the document has been edited since it was last saved.
+func (document *codeSpaceCollabDocument) unsaved() (string, int64, int64, string, bool) {
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if document.closed || document.revision == document.savedRevision {
+		return "", 0, 0, "", false
+	}
+
+	return document.contents, document.revision, document.version, document.lastEditorUUID, true
+}
+
+// markSaved records that given contents at a given revision of the document have been saved,
+// bringing the code space to a given version.
+// It is ignored if the document has since been rebased onto a newer version.
+func (document *codeSpaceCollabDocument) markSaved(revision int64, contents string, version int64) {
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if version <= document.version {
+		return
+	}
+
+	document.savedRevision = max(document.savedRevision, revision)
+	document.savedContents = contents
+	document.version = version
+}
+
+// rebase rebases the document onto given contents saved at a given version of the code space by a given user,
+// which is empty if the user is not known.
+// The change from the saved contents of the document to the given contents is transformed against the unsaved edits,
+// then applied and sent to the collaborators like any other edit, so that no edits are lost on either side.
+// It is ignored if the document is already at the given version or a newer one.
+func (document *codeSpaceCollabDocument) rebase(contents string, version int64, userUUID string) error {
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if document.closed || version <= document.version {
+		return nil
+	}
+
+	_, operation, err := TransformEditOperations(
+		diffEditOperation(document.savedContents, document.contents),
+		diffEditOperation(document.savedContents, contents),
+	)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	rebasedContents, err := operation.Apply(document.contents)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	if rebasedContents != document.contents {
+		document.apply(operation, rebasedContents, nil, userUUID)
+	}
+
+	document.savedContents = contents
+	document.version = version
+	if rebasedContents == contents {
+		document.savedRevision = document.revision
+	}
+
+	return nil
+}
+
+// apply records an edit operation that turned the document into given contents,
+// moves cursors along with the edited text, and sends the operation to the collaborators,
+// except for a given collaborator who made the edit, which is sent an acknowledgement instead.
+// The document must be locked.
+func (document *codeSpaceCollabDocument) apply(
+	operation EditOperation,
+	contents string,
+	editor *CodeSpaceCollaborator,
+	editorUUID string,
+) {
+	document.contents = contents
+	document.revision++
+	document.history = append(document.history, operation)
+	if len(document.history) > codeSpaceCollabHistoryLength {
+		document.history = document.history[len(document.history)-codeSpaceCollabHistoryLength:]
+	}
+
+	for other := range document.collaborators {
+		if other.cursor != nil {
+			other.cursor = other.cursor.transform(operation)
+		}
+
+		if other == editor {
+			document.send(other, &CodeSpaceCollabEvent{
+				Type:     api.CodeSpaceCollabEventTypeAck,
+				Revision: document.revision,
+			})
+
+			continue
+		}
+
+		document.send(other, &CodeSpaceCollabEvent{
+			Type:      api.CodeSpaceCollabEventTypeEdit,
+			Revision:  document.revision,
+			Operation: operation,
+			UserUUID:  editorUUID,
+		})
+	}
+}
+
+// diffEditOperation returns an edit operation that turns one document into another,
+// replacing the lines that differ between them.
+func diffEditOperation(from string, to string) EditOperation {
+	var operation EditOperation
+	for _, e := range diffEdits(splitDiffLines(from), splitDiffLines(to)) {
+		switch e.kind {
+		case ' ':
+			operation.retain(utf8.RuneCountInString(e.line))
+		case '+':
+			operation.insert(e.line)
+		default:
+			operation.delete(utf8.RuneCountInString(e.line))
+		}
+	}
+
+	return operation
+}
+
+// presence returns the current presence of the collaborator.
+// The collaborator's document must be locked.
+func (collaborator *CodeSpaceCollaborator) presence() *CodeSpacePresence {
+	return &CodeSpacePresence{
+		SessionID:   collaborator.SessionID,
+		UserUUID:    collaborator.UserUUID,
+		FirstName:   collaborator.FirstName,
+		LastName:    collaborator.LastName,
+		AccessLevel: collaborator.AccessLevel,
+		Cursor:      collaborator.cursor,
+		JoinedAt:    collaborator.JoinedAt,
+		LastSeenAt:  collaborator.lastSeenAt,
+	}
+}
+
+// Events returns the events for the collaborator.
+func (collaborator *CodeSpaceCollaborator) Events() <-chan *CodeSpaceCollabEvent {
+	return collaborator.events
+}
+
+// Edit applies an edit operation based on a given revision of the document to the document.
+// The operation is transformed against the operations applied since the given revision,
+// then sent to the other collaborators, while the collaborator itself is sent an acknowledgement.
+// Cursors are moved along with the edited text.
+// Read-only collaborators cannot edit.
+func (collaborator *CodeSpaceCollaborator) Edit(revision int64, operation EditOperation) error {
+	if collaborator.AccessLevel < CodeSpaceAccessLevelReadWrite {
+		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	err := operation.Validate()
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	document := collaborator.document
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if _, ok := document.collaborators[collaborator]; !ok {
+		return errutils.FormatError(errutils.ErrCodeSpaceCollabClosed)
+	}
+
+	collaborator.lastSeenAt = document.timeProvider.Now()
+
+	concurrentOperations, err := document.operationsSince(revision)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	for _, concurrentOperation := range concurrentOperations {
+		operation, _, err = TransformEditOperations(operation, concurrentOperation)
+		if err != nil {
+			return errutils.FormatError(err)
+		}
+	}
+
+	contents, err := operation.Apply(document.contents)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	document.lastEditorUUID = collaborator.UserUUID
+	document.apply(operation, contents, collaborator, collaborator.UserUUID)
+
+	return nil
+}
+
+// MoveCursor moves the cursor of the collaborator to a given cursor in a given revision of the document,
+// and tells everyone else where it moved.
+// The cursor is transformed against the operations applied since the given revision,
+// and must lie within the document.
+func (collaborator *CodeSpaceCollaborator) MoveCursor(revision int64, cursor *CodeSpaceCursor) error {
+	document := collaborator.document
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	if _, ok := document.collaborators[collaborator]; !ok {
+		return errutils.FormatError(errutils.ErrCodeSpaceCollabClosed)
+	}
+
+	collaborator.lastSeenAt = document.timeProvider.Now()
+
+	concurrentOperations, err := document.operationsSince(revision)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	length := utf8.RuneCountInString(document.contents)
+	if len(concurrentOperations) > 0 {
+		length = concurrentOperations[0].BaseLength()
+	}
+
+	if cursor.Position < 0 || cursor.Position > length || cursor.Anchor < 0 || cursor.Anchor > length {
+		return errutils.FormatErrorf(
+			errutils.ErrCodeSpaceCursorInvalid,
+			"cursor %d-%d is outside of document of length %d",
+			cursor.Anchor,
+			cursor.Position,
+			length,
+		)
+	}
+
+	for _, concurrentOperation := range concurrentOperations {
+		cursor = cursor.transform(concurrentOperation)
+	}
+
+	collaborator.cursor = cursor
+	presence := collaborator.presence()
+	for other := range document.collaborators {
+		if other == collaborator {
+			continue
+		}
+
+		document.send(other, &CodeSpaceCollabEvent{
+			Type:     api.CodeSpaceCollabEventTypeCursor,
+			Revision: document.revision,
+			Presence: presence,
+		})
+	}
+
+	return nil
+}
+
+// Heartbeat records that the collaborator is still connected.
+func (collaborator *CodeSpaceCollaborator) Heartbeat() {
+	document := collaborator.document
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	collaborator.lastSeenAt = document.timeProvider.Now()
+}
+
+// Leave removes the collaborator from the document, closes its events, and tells everyone else that it left.
+// The document stays in memory until its edits have been saved.
+func (collaborator *CodeSpaceCollaborator) Leave() {
+	document := collaborator.document
+	document.mu.Lock()
+	defer document.mu.Unlock()
+
+	document.remove(collaborator)
+}
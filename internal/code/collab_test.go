@@ -0,0 +1,295 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/internal/code"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditOperationValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		operation code.EditOperation
+		wantErr   bool
+	}{
+		"Valid operation": {
+			operation: code.EditOperation{{Retain: 2}, {Insert: "abc"}, {Delete: 3}},
+			wantErr:   false,
+		},
+		"Empty operation": {
+			operation: code.EditOperation{},
+			wantErr:   false,
+		},
+		"Empty component": {
+			operation: code.EditOperation{{Retain: 2}, {}},
+			wantErr:   true,
+		},
+		"Component with retain and insert": {
+			operation: code.EditOperation{{Retain: 2, Insert: "abc"}},
+			wantErr:   true,
+		},
+		"Component with insert and delete": {
+			operation: code.EditOperation{{Insert: "abc", Delete: 3}},
+			wantErr:   true,
+		},
+		"Negative retain": {
+			operation: code.EditOperation{{Retain: -2}},
+			wantErr:   true,
+		},
+		"Negative delete": {
+			operation: code.EditOperation{{Delete: -2}},
+			wantErr:   true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testcase.operation.Validate()
+			if testcase.wantErr {
+				require.ErrorIs(t, err, errutils.ErrCodeSpaceEditInvalid)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEditOperationApply(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		doc              string
+		operation        code.EditOperation
+		wantDoc          string
+		wantBaseLength   int
+		wantTargetLength int
+		wantErr          bool
+	}{
+		"Retain whole document": {
+			doc:              "hello world",
+			operation:        code.EditOperation{{Retain: 11}},
+			wantDoc:          "hello world",
+			wantBaseLength:   11,
+			wantTargetLength: 11,
+			wantErr:          false,
+		},
+		"Insert into empty document": {
+			doc:              "",
+			operation:        code.EditOperation{{Insert: "print('hello')"}},
+			wantDoc:          "print('hello')",
+			wantBaseLength:   0,
+			wantTargetLength: 14,
+			wantErr:          false,
+		},
+		"Insert, delete and retain": {
+			doc:              "hello world",
+			operation:        code.EditOperation{{Retain: 6}, {Insert: "big "}, {Retain: 5}},
+			wantDoc:          "hello big world",
+			wantBaseLength:   11,
+			wantTargetLength: 15,
+			wantErr:          false,
+		},
+		"Replace": {
+			doc:              "hello world",
+			operation:        code.EditOperation{{Insert: "goodbye"}, {Delete: 5}, {Retain: 6}},
+			wantDoc:          "goodbye world",
+			wantBaseLength:   11,
+			wantTargetLength: 13,
+			wantErr:          false,
+		},
+		"Multi-byte characters": {
+			doc:              "héllo wörld",
+			operation:        code.EditOperation{{Retain: 1}, {Delete: 1}, {Insert: "e"}, {Retain: 9}},
+			wantDoc:          "hello wörld",
+			wantBaseLength:   11,
+			wantTargetLength: 11,
+			wantErr:          false,
+		},
+		"Operation shorter than document": {
+			doc:              "hello world",
+			operation:        code.EditOperation{{Retain: 5}},
+			wantDoc:          "",
+			wantBaseLength:   5,
+			wantTargetLength: 5,
+			wantErr:          true,
+		},
+		"Operation longer than document": {
+			doc:              "hello",
+			operation:        code.EditOperation{{Retain: 5}, {Delete: 6}},
+			wantDoc:          "",
+			wantBaseLength:   11,
+			wantTargetLength: 5,
+			wantErr:          true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantBaseLength, testcase.operation.BaseLength())
+			require.Equal(t, testcase.wantTargetLength, testcase.operation.TargetLength())
+
+			doc, err := testcase.operation.Apply(testcase.doc)
+			if testcase.wantErr {
+				require.ErrorIs(t, err, errutils.ErrCodeSpaceEditInvalid)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantDoc, doc)
+		})
+	}
+}
+
+func TestTransformEditOperations(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		doc     string
+		a       code.EditOperation
+		b       code.EditOperation
+		wantDoc string
+	}{
+		"Inserts at different positions": {
+			doc:     "hello world",
+			a:       code.EditOperation{{Insert: ">> "}, {Retain: 11}},
+			b:       code.EditOperation{{Retain: 11}, {Insert: "!"}},
+			wantDoc: ">> hello world!",
+		},
+		"Inserts at same position": {
+			doc:     "hello world",
+			a:       code.EditOperation{{Retain: 5}, {Insert: " there"}, {Retain: 6}},
+			b:       code.EditOperation{{Retain: 5}, {Insert: ","}, {Retain: 6}},
+			wantDoc: "hello there, world",
+		},
+		"Insert inside deleted range": {
+			doc:     "hello world",
+			a:       code.EditOperation{{Retain: 6}, {Insert: "big "}, {Retain: 5}},
+			b:       code.EditOperation{{Retain: 5}, {Delete: 6}},
+			wantDoc: "hellobig ",
+		},
+		"Overlapping deletes": {
+			doc:     "hello world",
+			a:       code.EditOperation{{Retain: 2}, {Delete: 5}, {Retain: 4}},
+			b:       code.EditOperation{{Retain: 4}, {Delete: 4}, {Retain: 3}},
+			wantDoc: "herld",
+		},
+		"Same delete": {
+			doc:     "hello world",
+			a:       code.EditOperation{{Retain: 5}, {Delete: 6}},
+			b:       code.EditOperation{{Retain: 5}, {Delete: 6}},
+			wantDoc: "hello",
+		},
+		"Replace and append": {
+			doc:     "héllo",
+			a:       code.EditOperation{{Retain: 1}, {Delete: 1}, {Insert: "e"}, {Retain: 3}},
+			b:       code.EditOperation{{Retain: 5}, {Insert: "!"}},
+			wantDoc: "hello!",
+		},
+		"Empty document": {
+			doc:     "",
+			a:       code.EditOperation{{Insert: "a"}},
+			b:       code.EditOperation{{Insert: "b"}},
+			wantDoc: "ab",
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			aPrime, bPrime, err := code.TransformEditOperations(testcase.a, testcase.b)
+			require.NoError(t, err)
+
+			docA, err := testcase.a.Apply(testcase.doc)
+			require.NoError(t, err)
+
+			docAB, err := bPrime.Apply(docA)
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantDoc, docAB)
+
+			docB, err := testcase.b.Apply(testcase.doc)
+			require.NoError(t, err)
+
+			docBA, err := aPrime.Apply(docB)
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantDoc, docBA)
+		})
+	}
+}
+
+func TestTransformEditOperationsBaseLengthMismatch(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := code.TransformEditOperations(
+		code.EditOperation{{Retain: 5}},
+		code.EditOperation{{Retain: 6}},
+	)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceEditInvalid)
+}
+
+func TestEditOperationTransformPosition(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		operation    code.EditOperation
+		position     int
+		wantPosition int
+	}{
+		"Retain": {
+			operation:    code.EditOperation{{Retain: 11}},
+			position:     5,
+			wantPosition: 5,
+		},
+		"Insert before position": {
+			operation:    code.EditOperation{{Insert: ">> "}, {Retain: 11}},
+			position:     5,
+			wantPosition: 8,
+		},
+		"Insert at position": {
+			operation:    code.EditOperation{{Retain: 5}, {Insert: ","}, {Retain: 6}},
+			position:     5,
+			wantPosition: 6,
+		},
+		"Insert after position": {
+			operation:    code.EditOperation{{Retain: 11}, {Insert: "!"}},
+			position:     5,
+			wantPosition: 5,
+		},
+		"Delete before position": {
+			operation:    code.EditOperation{{Delete: 2}, {Retain: 9}},
+			position:     5,
+			wantPosition: 3,
+		},
+		"Delete around position": {
+			operation:    code.EditOperation{{Retain: 3}, {Delete: 5}, {Retain: 3}},
+			position:     5,
+			wantPosition: 3,
+		},
+		"Delete after position": {
+			operation:    code.EditOperation{{Retain: 6}, {Delete: 5}},
+			position:     5,
+			wantPosition: 5,
+		},
+		"Multi-byte characters": {
+			operation:    code.EditOperation{{Insert: "héllo "}, {Retain: 5}},
+			position:     5,
+			wantPosition: 11,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantPosition, testcase.operation.TransformPosition(testcase.position))
+		})
+	}
+}
@@ -12,6 +12,7 @@ package codemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	auth "github.com/alvii147/nymphadora-api/internal/auth"
 	code "github.com/alvii147/nymphadora-api/internal/code"
@@ -43,6 +44,20 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClearCodeSpaceEntryPoint mocks base method.
+func (m *MockRepository) ClearCodeSpaceEntryPoint(ctx context.Context, querier database.Querier, codeSpaceID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCodeSpaceEntryPoint", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCodeSpaceEntryPoint indicates an expected call of ClearCodeSpaceEntryPoint.
+func (mr *MockRepositoryMockRecorder) ClearCodeSpaceEntryPoint(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCodeSpaceEntryPoint", reflect.TypeOf((*MockRepository)(nil).ClearCodeSpaceEntryPoint), ctx, querier, codeSpaceID)
+}
+
 // CreateCodeSpace mocks base method.
 func (m *MockRepository) CreateCodeSpace(ctx context.Context, querier database.Querier, codeSpace *code.CodeSpace) (*code.CodeSpace, error) {
 	m.ctrl.T.Helper()
@@ -58,6 +73,111 @@ func (mr *MockRepositoryMockRecorder) CreateCodeSpace(ctx, querier, codeSpace an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpace", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpace), ctx, querier, codeSpace)
 }
 
+// CreateCodeSpaceAlias mocks base method.
+func (m *MockRepository) CreateCodeSpaceAlias(ctx context.Context, querier database.Querier, codeSpaceAlias *code.CodeSpaceAlias) (*code.CodeSpaceAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceAlias", ctx, querier, codeSpaceAlias)
+	ret0, _ := ret[0].(*code.CodeSpaceAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceAlias indicates an expected call of CreateCodeSpaceAlias.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceAlias(ctx, querier, codeSpaceAlias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceAlias", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceAlias), ctx, querier, codeSpaceAlias)
+}
+
+// CreateCodeSpaceEnvVar mocks base method.
+func (m *MockRepository) CreateCodeSpaceEnvVar(ctx context.Context, querier database.Querier, codeSpaceEnvVar *code.CodeSpaceEnvVar) (*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceEnvVar", ctx, querier, codeSpaceEnvVar)
+	ret0, _ := ret[0].(*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceEnvVar indicates an expected call of CreateCodeSpaceEnvVar.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceEnvVar(ctx, querier, codeSpaceEnvVar any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceEnvVar", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceEnvVar), ctx, querier, codeSpaceEnvVar)
+}
+
+// CreateCodeSpaceFile mocks base method.
+func (m *MockRepository) CreateCodeSpaceFile(ctx context.Context, querier database.Querier, codeSpaceFile *code.CodeSpaceFile) (*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceFile", ctx, querier, codeSpaceFile)
+	ret0, _ := ret[0].(*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceFile indicates an expected call of CreateCodeSpaceFile.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceFile(ctx, querier, codeSpaceFile any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceFile", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceFile), ctx, querier, codeSpaceFile)
+}
+
+// CreateCodeSpaceRevision mocks base method.
+func (m *MockRepository) CreateCodeSpaceRevision(ctx context.Context, querier database.Querier, codeSpaceRevision *code.CodeSpaceRevision) (*code.CodeSpaceRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceRevision", ctx, querier, codeSpaceRevision)
+	ret0, _ := ret[0].(*code.CodeSpaceRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceRevision indicates an expected call of CreateCodeSpaceRevision.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceRevision(ctx, querier, codeSpaceRevision any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceRevision", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceRevision), ctx, querier, codeSpaceRevision)
+}
+
+// CreateCodeSpaceRun mocks base method.
+func (m *MockRepository) CreateCodeSpaceRun(ctx context.Context, querier database.Querier, codeSpaceRun *code.CodeSpaceRun) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceRun", ctx, querier, codeSpaceRun)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceRun indicates an expected call of CreateCodeSpaceRun.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceRun(ctx, querier, codeSpaceRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceRun", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceRun), ctx, querier, codeSpaceRun)
+}
+
+// CreateCodeSpaceRunConfig mocks base method.
+func (m *MockRepository) CreateCodeSpaceRunConfig(ctx context.Context, querier database.Querier, codeSpaceRunConfig *code.CodeSpaceRunConfig) (*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceRunConfig", ctx, querier, codeSpaceRunConfig)
+	ret0, _ := ret[0].(*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceRunConfig indicates an expected call of CreateCodeSpaceRunConfig.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceRunConfig(ctx, querier, codeSpaceRunConfig any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceRunConfig", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceRunConfig), ctx, querier, codeSpaceRunConfig)
+}
+
+// CreateCodeSpaceTestCase mocks base method.
+func (m *MockRepository) CreateCodeSpaceTestCase(ctx context.Context, querier database.Querier, codeSpaceTestCase *code.CodeSpaceTestCase) (*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceTestCase", ctx, querier, codeSpaceTestCase)
+	ret0, _ := ret[0].(*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceTestCase indicates an expected call of CreateCodeSpaceTestCase.
+func (mr *MockRepositoryMockRecorder) CreateCodeSpaceTestCase(ctx, querier, codeSpaceTestCase any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceTestCase", reflect.TypeOf((*MockRepository)(nil).CreateCodeSpaceTestCase), ctx, querier, codeSpaceTestCase)
+}
+
 // CreateOrUpdateCodeSpaceAccess mocks base method.
 func (m *MockRepository) CreateOrUpdateCodeSpaceAccess(ctx context.Context, querier database.Querier, codeSpaceAccess *code.CodeSpaceAccess) (*code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -73,6 +193,21 @@ func (mr *MockRepositoryMockRecorder) CreateOrUpdateCodeSpaceAccess(ctx, querier
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateCodeSpaceAccess", reflect.TypeOf((*MockRepository)(nil).CreateOrUpdateCodeSpaceAccess), ctx, querier, codeSpaceAccess)
 }
 
+// CreateOrUpdateExecutionCacheEntry mocks base method.
+func (m *MockRepository) CreateOrUpdateExecutionCacheEntry(ctx context.Context, querier database.Querier, executionCacheEntry *code.ExecutionCacheEntry) (*code.ExecutionCacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateExecutionCacheEntry", ctx, querier, executionCacheEntry)
+	ret0, _ := ret[0].(*code.ExecutionCacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrUpdateExecutionCacheEntry indicates an expected call of CreateOrUpdateExecutionCacheEntry.
+func (mr *MockRepositoryMockRecorder) CreateOrUpdateExecutionCacheEntry(ctx, querier, executionCacheEntry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateExecutionCacheEntry", reflect.TypeOf((*MockRepository)(nil).CreateOrUpdateExecutionCacheEntry), ctx, querier, executionCacheEntry)
+}
+
 // DeleteCodeSpace mocks base method.
 func (m *MockRepository) DeleteCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64) error {
 	m.ctrl.T.Helper()
@@ -101,6 +236,120 @@ func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceAccess(ctx, querier, userUU
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceAccess", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceAccess), ctx, querier, userUUID, codeSpaceID)
 }
 
+// DeleteCodeSpaceAlias mocks base method.
+func (m *MockRepository) DeleteCodeSpaceAlias(ctx context.Context, querier database.Querier, codeSpaceAliasID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceAlias", ctx, querier, codeSpaceAliasID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceAlias indicates an expected call of DeleteCodeSpaceAlias.
+func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceAlias(ctx, querier, codeSpaceAliasID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceAlias", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceAlias), ctx, querier, codeSpaceAliasID)
+}
+
+// DeleteCodeSpaceEnvVar mocks base method.
+func (m *MockRepository) DeleteCodeSpaceEnvVar(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceEnvVarID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceEnvVar", ctx, querier, codeSpaceID, codeSpaceEnvVarID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceEnvVar indicates an expected call of DeleteCodeSpaceEnvVar.
+func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceEnvVar(ctx, querier, codeSpaceID, codeSpaceEnvVarID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceEnvVar", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceEnvVar), ctx, querier, codeSpaceID, codeSpaceEnvVarID)
+}
+
+// DeleteCodeSpaceFile mocks base method.
+func (m *MockRepository) DeleteCodeSpaceFile(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceFileID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceFile", ctx, querier, codeSpaceID, codeSpaceFileID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceFile indicates an expected call of DeleteCodeSpaceFile.
+func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceFile(ctx, querier, codeSpaceID, codeSpaceFileID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceFile", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceFile), ctx, querier, codeSpaceID, codeSpaceFileID)
+}
+
+// DeleteCodeSpaceRunConfig mocks base method.
+func (m *MockRepository) DeleteCodeSpaceRunConfig(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceRunConfigID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceRunConfig", ctx, querier, codeSpaceID, codeSpaceRunConfigID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceRunConfig indicates an expected call of DeleteCodeSpaceRunConfig.
+func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceRunConfig(ctx, querier, codeSpaceID, codeSpaceRunConfigID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceRunConfig", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceRunConfig), ctx, querier, codeSpaceID, codeSpaceRunConfigID)
+}
+
+// DeleteCodeSpaceTestCase mocks base method.
+func (m *MockRepository) DeleteCodeSpaceTestCase(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceTestCaseID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceTestCase", ctx, querier, codeSpaceID, codeSpaceTestCaseID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceTestCase indicates an expected call of DeleteCodeSpaceTestCase.
+func (mr *MockRepositoryMockRecorder) DeleteCodeSpaceTestCase(ctx, querier, codeSpaceID, codeSpaceTestCaseID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceTestCase", reflect.TypeOf((*MockRepository)(nil).DeleteCodeSpaceTestCase), ctx, querier, codeSpaceID, codeSpaceTestCaseID)
+}
+
+// DeleteStaleExecutionCacheEntries mocks base method.
+func (m *MockRepository) DeleteStaleExecutionCacheEntries(ctx context.Context, querier database.Querier, maxEntries int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleExecutionCacheEntries", ctx, querier, maxEntries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStaleExecutionCacheEntries indicates an expected call of DeleteStaleExecutionCacheEntries.
+func (mr *MockRepositoryMockRecorder) DeleteStaleExecutionCacheEntries(ctx, querier, maxEntries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleExecutionCacheEntries", reflect.TypeOf((*MockRepository)(nil).DeleteStaleExecutionCacheEntries), ctx, querier, maxEntries)
+}
+
+// DeleteThinnedCodeSpaceRevisions mocks base method.
+func (m *MockRepository) DeleteThinnedCodeSpaceRevisions(ctx context.Context, querier database.Querier, createdBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteThinnedCodeSpaceRevisions", ctx, querier, createdBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteThinnedCodeSpaceRevisions indicates an expected call of DeleteThinnedCodeSpaceRevisions.
+func (mr *MockRepositoryMockRecorder) DeleteThinnedCodeSpaceRevisions(ctx, querier, createdBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteThinnedCodeSpaceRevisions", reflect.TypeOf((*MockRepository)(nil).DeleteThinnedCodeSpaceRevisions), ctx, querier, createdBefore)
+}
+
+// DeleteTrashedCodeSpaces mocks base method.
+func (m *MockRepository) DeleteTrashedCodeSpaces(ctx context.Context, querier database.Querier, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTrashedCodeSpaces", ctx, querier, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTrashedCodeSpaces indicates an expected call of DeleteTrashedCodeSpaces.
+func (mr *MockRepositoryMockRecorder) DeleteTrashedCodeSpaces(ctx, querier, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTrashedCodeSpaces", reflect.TypeOf((*MockRepository)(nil).DeleteTrashedCodeSpaces), ctx, querier, deletedBefore)
+}
+
 // GetCodeSpace mocks base method.
 func (m *MockRepository) GetCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64) (*code.CodeSpace, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +365,81 @@ func (mr *MockRepositoryMockRecorder) GetCodeSpace(ctx, querier, codeSpaceID any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpace", reflect.TypeOf((*MockRepository)(nil).GetCodeSpace), ctx, querier, codeSpaceID)
 }
 
+// GetCodeSpaceAliasByName mocks base method.
+func (m *MockRepository) GetCodeSpaceAliasByName(ctx context.Context, querier database.Querier, name string) (*code.CodeSpaceAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceAliasByName", ctx, querier, name)
+	ret0, _ := ret[0].(*code.CodeSpaceAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceAliasByName indicates an expected call of GetCodeSpaceAliasByName.
+func (mr *MockRepositoryMockRecorder) GetCodeSpaceAliasByName(ctx, querier, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceAliasByName", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceAliasByName), ctx, querier, name)
+}
+
+// GetCodeSpaceEnvVar mocks base method.
+func (m *MockRepository) GetCodeSpaceEnvVar(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceEnvVarID int64) (*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceEnvVar", ctx, querier, codeSpaceID, codeSpaceEnvVarID)
+	ret0, _ := ret[0].(*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceEnvVar indicates an expected call of GetCodeSpaceEnvVar.
+func (mr *MockRepositoryMockRecorder) GetCodeSpaceEnvVar(ctx, querier, codeSpaceID, codeSpaceEnvVarID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceEnvVar", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceEnvVar), ctx, querier, codeSpaceID, codeSpaceEnvVarID)
+}
+
+// GetCodeSpaceRevision mocks base method.
+func (m *MockRepository) GetCodeSpaceRevision(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceRevisionID int64) (*code.CodeSpaceRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceRevision", ctx, querier, codeSpaceID, codeSpaceRevisionID)
+	ret0, _ := ret[0].(*code.CodeSpaceRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceRevision indicates an expected call of GetCodeSpaceRevision.
+func (mr *MockRepositoryMockRecorder) GetCodeSpaceRevision(ctx, querier, codeSpaceID, codeSpaceRevisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceRevision", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceRevision), ctx, querier, codeSpaceID, codeSpaceRevisionID)
+}
+
+// GetCodeSpaceRun mocks base method.
+func (m *MockRepository) GetCodeSpaceRun(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceRunID int64) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceRun", ctx, querier, codeSpaceID, codeSpaceRunID)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceRun indicates an expected call of GetCodeSpaceRun.
+func (mr *MockRepositoryMockRecorder) GetCodeSpaceRun(ctx, querier, codeSpaceID, codeSpaceRunID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceRun", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceRun), ctx, querier, codeSpaceID, codeSpaceRunID)
+}
+
+// GetCodeSpaceRunConfigByName mocks base method.
+func (m *MockRepository) GetCodeSpaceRunConfigByName(ctx context.Context, querier database.Querier, codeSpaceID int64, name string) (*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceRunConfigByName", ctx, querier, codeSpaceID, name)
+	ret0, _ := ret[0].(*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceRunConfigByName indicates an expected call of GetCodeSpaceRunConfigByName.
+func (mr *MockRepositoryMockRecorder) GetCodeSpaceRunConfigByName(ctx, querier, codeSpaceID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceRunConfigByName", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceRunConfigByName), ctx, querier, codeSpaceID, name)
+}
+
 // GetCodeSpaceWithAccessByName mocks base method.
 func (m *MockRepository) GetCodeSpaceWithAccessByName(ctx context.Context, querier database.Querier, userUUID, name string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -132,6 +456,127 @@ func (mr *MockRepositoryMockRecorder) GetCodeSpaceWithAccessByName(ctx, querier,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceWithAccessByName", reflect.TypeOf((*MockRepository)(nil).GetCodeSpaceWithAccessByName), ctx, querier, userUUID, name)
 }
 
+// GetExecutionCacheEntry mocks base method.
+func (m *MockRepository) GetExecutionCacheEntry(ctx context.Context, querier database.Querier, key string) (*code.ExecutionCacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutionCacheEntry", ctx, querier, key)
+	ret0, _ := ret[0].(*code.ExecutionCacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutionCacheEntry indicates an expected call of GetExecutionCacheEntry.
+func (mr *MockRepositoryMockRecorder) GetExecutionCacheEntry(ctx, querier, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutionCacheEntry", reflect.TypeOf((*MockRepository)(nil).GetExecutionCacheEntry), ctx, querier, key)
+}
+
+// GetTrashedCodeSpaceWithAccessByName mocks base method.
+func (m *MockRepository) GetTrashedCodeSpaceWithAccessByName(ctx context.Context, querier database.Querier, userUUID, name string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedCodeSpaceWithAccessByName", ctx, querier, userUUID, name)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTrashedCodeSpaceWithAccessByName indicates an expected call of GetTrashedCodeSpaceWithAccessByName.
+func (mr *MockRepositoryMockRecorder) GetTrashedCodeSpaceWithAccessByName(ctx, querier, userUUID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedCodeSpaceWithAccessByName", reflect.TypeOf((*MockRepository)(nil).GetTrashedCodeSpaceWithAccessByName), ctx, querier, userUUID, name)
+}
+
+// ListCodeSpaceEnvVars mocks base method.
+func (m *MockRepository) ListCodeSpaceEnvVars(ctx context.Context, querier database.Querier, codeSpaceID int64) ([]*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceEnvVars", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].([]*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceEnvVars indicates an expected call of ListCodeSpaceEnvVars.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceEnvVars(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceEnvVars", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceEnvVars), ctx, querier, codeSpaceID)
+}
+
+// ListCodeSpaceFiles mocks base method.
+func (m *MockRepository) ListCodeSpaceFiles(ctx context.Context, querier database.Querier, codeSpaceID int64) ([]*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceFiles", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].([]*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceFiles indicates an expected call of ListCodeSpaceFiles.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceFiles(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceFiles", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceFiles), ctx, querier, codeSpaceID)
+}
+
+// ListCodeSpaceRevisions mocks base method.
+func (m *MockRepository) ListCodeSpaceRevisions(ctx context.Context, querier database.Querier, codeSpaceID, limit, offset int64) ([]*code.CodeSpaceRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRevisions", ctx, querier, codeSpaceID, limit, offset)
+	ret0, _ := ret[0].([]*code.CodeSpaceRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRevisions indicates an expected call of ListCodeSpaceRevisions.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceRevisions(ctx, querier, codeSpaceID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRevisions", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceRevisions), ctx, querier, codeSpaceID, limit, offset)
+}
+
+// ListCodeSpaceRunConfigs mocks base method.
+func (m *MockRepository) ListCodeSpaceRunConfigs(ctx context.Context, querier database.Querier, codeSpaceID int64) ([]*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRunConfigs", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].([]*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRunConfigs indicates an expected call of ListCodeSpaceRunConfigs.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceRunConfigs(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRunConfigs", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceRunConfigs), ctx, querier, codeSpaceID)
+}
+
+// ListCodeSpaceRuns mocks base method.
+func (m *MockRepository) ListCodeSpaceRuns(ctx context.Context, querier database.Querier, codeSpaceID, limit, offset int64) ([]*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRuns", ctx, querier, codeSpaceID, limit, offset)
+	ret0, _ := ret[0].([]*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRuns indicates an expected call of ListCodeSpaceRuns.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceRuns(ctx, querier, codeSpaceID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRuns", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceRuns), ctx, querier, codeSpaceID, limit, offset)
+}
+
+// ListCodeSpaceTestCases mocks base method.
+func (m *MockRepository) ListCodeSpaceTestCases(ctx context.Context, querier database.Querier, codeSpaceID int64, includeHidden bool) ([]*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceTestCases", ctx, querier, codeSpaceID, includeHidden)
+	ret0, _ := ret[0].([]*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceTestCases indicates an expected call of ListCodeSpaceTestCases.
+func (mr *MockRepositoryMockRecorder) ListCodeSpaceTestCases(ctx, querier, codeSpaceID, includeHidden any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceTestCases", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaceTestCases), ctx, querier, codeSpaceID, includeHidden)
+}
+
 // ListCodeSpaces mocks base method.
 func (m *MockRepository) ListCodeSpaces(ctx context.Context, querier database.Querier, userUUID string) ([]*code.CodeSpace, []*code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -148,6 +593,22 @@ func (mr *MockRepositoryMockRecorder) ListCodeSpaces(ctx, querier, userUUID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaces", reflect.TypeOf((*MockRepository)(nil).ListCodeSpaces), ctx, querier, userUUID)
 }
 
+// ListTrashedCodeSpaces mocks base method.
+func (m *MockRepository) ListTrashedCodeSpaces(ctx context.Context, querier database.Querier, userUUID string) ([]*code.CodeSpace, []*code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashedCodeSpaces", ctx, querier, userUUID)
+	ret0, _ := ret[0].([]*code.CodeSpace)
+	ret1, _ := ret[1].([]*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTrashedCodeSpaces indicates an expected call of ListTrashedCodeSpaces.
+func (mr *MockRepositoryMockRecorder) ListTrashedCodeSpaces(ctx, querier, userUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashedCodeSpaces", reflect.TypeOf((*MockRepository)(nil).ListTrashedCodeSpaces), ctx, querier, userUUID)
+}
+
 // ListUsersWithCodeSpaceAccess mocks base method.
 func (m *MockRepository) ListUsersWithCodeSpaceAccess(ctx context.Context, querier database.Querier, codeSpaceID int64) ([]*auth.User, []*code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -164,17 +625,136 @@ func (mr *MockRepositoryMockRecorder) ListUsersWithCodeSpaceAccess(ctx, querier,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersWithCodeSpaceAccess", reflect.TypeOf((*MockRepository)(nil).ListUsersWithCodeSpaceAccess), ctx, querier, codeSpaceID)
 }
 
+// RenameCodeSpace mocks base method.
+func (m *MockRepository) RenameCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64, name string, version *int64) (*code.CodeSpace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameCodeSpace", ctx, querier, codeSpaceID, name, version)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameCodeSpace indicates an expected call of RenameCodeSpace.
+func (mr *MockRepositoryMockRecorder) RenameCodeSpace(ctx, querier, codeSpaceID, name, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameCodeSpace", reflect.TypeOf((*MockRepository)(nil).RenameCodeSpace), ctx, querier, codeSpaceID, name, version)
+}
+
+// RestoreCodeSpace mocks base method.
+func (m *MockRepository) RestoreCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64) (*code.CodeSpace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCodeSpace", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCodeSpace indicates an expected call of RestoreCodeSpace.
+func (mr *MockRepositoryMockRecorder) RestoreCodeSpace(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCodeSpace", reflect.TypeOf((*MockRepository)(nil).RestoreCodeSpace), ctx, querier, codeSpaceID)
+}
+
+// TrashCodeSpace mocks base method.
+func (m *MockRepository) TrashCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashCodeSpace", ctx, querier, codeSpaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashCodeSpace indicates an expected call of TrashCodeSpace.
+func (mr *MockRepositoryMockRecorder) TrashCodeSpace(ctx, querier, codeSpaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashCodeSpace", reflect.TypeOf((*MockRepository)(nil).TrashCodeSpace), ctx, querier, codeSpaceID)
+}
+
 // UpdateCodeSpace mocks base method.
-func (m *MockRepository) UpdateCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64, contents *string) (*code.CodeSpace, error) {
+func (m *MockRepository) UpdateCodeSpace(ctx context.Context, querier database.Querier, codeSpaceID int64, contents, languageVersion *string, version *int64) (*code.CodeSpace, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCodeSpace", ctx, querier, codeSpaceID, contents)
+	ret := m.ctrl.Call(m, "UpdateCodeSpace", ctx, querier, codeSpaceID, contents, languageVersion, version)
 	ret0, _ := ret[0].(*code.CodeSpace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateCodeSpace indicates an expected call of UpdateCodeSpace.
-func (mr *MockRepositoryMockRecorder) UpdateCodeSpace(ctx, querier, codeSpaceID, contents any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpace(ctx, querier, codeSpaceID, contents, languageVersion, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpace", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpace), ctx, querier, codeSpaceID, contents, languageVersion, version)
+}
+
+// UpdateCodeSpaceEnvVar mocks base method.
+func (m *MockRepository) UpdateCodeSpaceEnvVar(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceEnvVarID int64, name, value *string, isSecret *bool) (*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceEnvVar", ctx, querier, codeSpaceID, codeSpaceEnvVarID, name, value, isSecret)
+	ret0, _ := ret[0].(*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceEnvVar indicates an expected call of UpdateCodeSpaceEnvVar.
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpaceEnvVar(ctx, querier, codeSpaceID, codeSpaceEnvVarID, name, value, isSecret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceEnvVar", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpaceEnvVar), ctx, querier, codeSpaceID, codeSpaceEnvVarID, name, value, isSecret)
+}
+
+// UpdateCodeSpaceFile mocks base method.
+func (m *MockRepository) UpdateCodeSpaceFile(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceFileID int64, name, contents *string, isEntryPoint *bool) (*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceFile", ctx, querier, codeSpaceID, codeSpaceFileID, name, contents, isEntryPoint)
+	ret0, _ := ret[0].(*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceFile indicates an expected call of UpdateCodeSpaceFile.
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpaceFile(ctx, querier, codeSpaceID, codeSpaceFileID, name, contents, isEntryPoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceFile", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpaceFile), ctx, querier, codeSpaceID, codeSpaceFileID, name, contents, isEntryPoint)
+}
+
+// UpdateCodeSpaceRun mocks base method.
+func (m *MockRepository) UpdateCodeSpaceRun(ctx context.Context, querier database.Querier, codeSpaceRun *code.CodeSpaceRun) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceRun", ctx, querier, codeSpaceRun)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceRun indicates an expected call of UpdateCodeSpaceRun.
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpaceRun(ctx, querier, codeSpaceRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceRun", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpaceRun), ctx, querier, codeSpaceRun)
+}
+
+// UpdateCodeSpaceRunConfig mocks base method.
+func (m *MockRepository) UpdateCodeSpaceRunConfig(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceRunConfigID int64, name, stdin *string, args []string, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit *int64, languageVersion *string) (*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceRunConfig", ctx, querier, codeSpaceID, codeSpaceRunConfigID, name, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+	ret0, _ := ret[0].(*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceRunConfig indicates an expected call of UpdateCodeSpaceRunConfig.
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpaceRunConfig(ctx, querier, codeSpaceID, codeSpaceRunConfigID, name, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceRunConfig", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpaceRunConfig), ctx, querier, codeSpaceID, codeSpaceRunConfigID, name, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+}
+
+// UpdateCodeSpaceTestCase mocks base method.
+func (m *MockRepository) UpdateCodeSpaceTestCase(ctx context.Context, querier database.Querier, codeSpaceID, codeSpaceTestCaseID int64, stdin *string, args []string, expectedStdout, comparisonMode *string, isHidden *bool) (*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceTestCase", ctx, querier, codeSpaceID, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden)
+	ret0, _ := ret[0].(*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceTestCase indicates an expected call of UpdateCodeSpaceTestCase.
+func (mr *MockRepositoryMockRecorder) UpdateCodeSpaceTestCase(ctx, querier, codeSpaceID, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpace", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpace), ctx, querier, codeSpaceID, contents)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceTestCase", reflect.TypeOf((*MockRepository)(nil).UpdateCodeSpaceTestCase), ctx, querier, codeSpaceID, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden)
 }
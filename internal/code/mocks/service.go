@@ -12,11 +12,13 @@ package codemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	auth "github.com/alvii147/nymphadora-api/internal/auth"
 	code "github.com/alvii147/nymphadora-api/internal/code"
 	templatesmanager "github.com/alvii147/nymphadora-api/internal/templatesmanager"
 	api "github.com/alvii147/nymphadora-api/pkg/api"
+	piston "github.com/alvii147/nymphadora-api/pkg/piston"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -60,10 +62,37 @@ func (mr *MockServiceMockRecorder) AcceptCodeSpaceUserInvitation(ctx, name, toke
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptCodeSpaceUserInvitation", reflect.TypeOf((*MockService)(nil).AcceptCodeSpaceUserInvitation), ctx, name, token)
 }
 
+// BenchmarkCodeSpace mocks base method.
+func (m *MockService) BenchmarkCodeSpace(ctx context.Context, name string, iterations int64, opts *code.RunCodeSpaceOptions) (*code.CodeSpaceBenchmark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BenchmarkCodeSpace", ctx, name, iterations, opts)
+	ret0, _ := ret[0].(*code.CodeSpaceBenchmark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BenchmarkCodeSpace indicates an expected call of BenchmarkCodeSpace.
+func (mr *MockServiceMockRecorder) BenchmarkCodeSpace(ctx, name, iterations, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BenchmarkCodeSpace", reflect.TypeOf((*MockService)(nil).BenchmarkCodeSpace), ctx, name, iterations, opts)
+}
+
+// Close mocks base method.
+func (m *MockService) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockService)(nil).Close))
+}
+
 // CreateCodeSpace mocks base method.
-func (m *MockService) CreateCodeSpace(ctx context.Context, language string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+func (m *MockService) CreateCodeSpace(ctx context.Context, name *string, language string, languageVersion *string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCodeSpace", ctx, language)
+	ret := m.ctrl.Call(m, "CreateCodeSpace", ctx, name, language, languageVersion)
 	ret0, _ := ret[0].(*code.CodeSpace)
 	ret1, _ := ret[1].(*code.CodeSpaceAccess)
 	ret2, _ := ret[2].(error)
@@ -71,9 +100,69 @@ func (m *MockService) CreateCodeSpace(ctx context.Context, language string) (*co
 }
 
 // CreateCodeSpace indicates an expected call of CreateCodeSpace.
-func (mr *MockServiceMockRecorder) CreateCodeSpace(ctx, language any) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateCodeSpace(ctx, name, language, languageVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpace", reflect.TypeOf((*MockService)(nil).CreateCodeSpace), ctx, name, language, languageVersion)
+}
+
+// CreateCodeSpaceEnvVar mocks base method.
+func (m *MockService) CreateCodeSpaceEnvVar(ctx context.Context, name, envVarName, value string, isSecret bool) (*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceEnvVar", ctx, name, envVarName, value, isSecret)
+	ret0, _ := ret[0].(*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceEnvVar indicates an expected call of CreateCodeSpaceEnvVar.
+func (mr *MockServiceMockRecorder) CreateCodeSpaceEnvVar(ctx, name, envVarName, value, isSecret any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpace", reflect.TypeOf((*MockService)(nil).CreateCodeSpace), ctx, language)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceEnvVar", reflect.TypeOf((*MockService)(nil).CreateCodeSpaceEnvVar), ctx, name, envVarName, value, isSecret)
+}
+
+// CreateCodeSpaceFile mocks base method.
+func (m *MockService) CreateCodeSpaceFile(ctx context.Context, name, fileName, contents string, isEntryPoint bool) (*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceFile", ctx, name, fileName, contents, isEntryPoint)
+	ret0, _ := ret[0].(*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceFile indicates an expected call of CreateCodeSpaceFile.
+func (mr *MockServiceMockRecorder) CreateCodeSpaceFile(ctx, name, fileName, contents, isEntryPoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceFile", reflect.TypeOf((*MockService)(nil).CreateCodeSpaceFile), ctx, name, fileName, contents, isEntryPoint)
+}
+
+// CreateCodeSpaceRunConfig mocks base method.
+func (m *MockService) CreateCodeSpaceRunConfig(ctx context.Context, name, configName string, stdin *string, args []string, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit *int64, languageVersion *string) (*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceRunConfig", ctx, name, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+	ret0, _ := ret[0].(*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceRunConfig indicates an expected call of CreateCodeSpaceRunConfig.
+func (mr *MockServiceMockRecorder) CreateCodeSpaceRunConfig(ctx, name, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceRunConfig", reflect.TypeOf((*MockService)(nil).CreateCodeSpaceRunConfig), ctx, name, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+}
+
+// CreateCodeSpaceTestCase mocks base method.
+func (m *MockService) CreateCodeSpaceTestCase(ctx context.Context, name string, stdin *string, args []string, expectedStdout, comparisonMode string, isHidden bool) (*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCodeSpaceTestCase", ctx, name, stdin, args, expectedStdout, comparisonMode, isHidden)
+	ret0, _ := ret[0].(*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCodeSpaceTestCase indicates an expected call of CreateCodeSpaceTestCase.
+func (mr *MockServiceMockRecorder) CreateCodeSpaceTestCase(ctx, name, stdin, args, expectedStdout, comparisonMode, isHidden any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceTestCase", reflect.TypeOf((*MockService)(nil).CreateCodeSpaceTestCase), ctx, name, stdin, args, expectedStdout, comparisonMode, isHidden)
 }
 
 // DeleteCodeSpace mocks base method.
@@ -90,6 +179,93 @@ func (mr *MockServiceMockRecorder) DeleteCodeSpace(ctx, name any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpace", reflect.TypeOf((*MockService)(nil).DeleteCodeSpace), ctx, name)
 }
 
+// DeleteCodeSpaceEnvVar mocks base method.
+func (m *MockService) DeleteCodeSpaceEnvVar(ctx context.Context, name string, codeSpaceEnvVarID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceEnvVar", ctx, name, codeSpaceEnvVarID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceEnvVar indicates an expected call of DeleteCodeSpaceEnvVar.
+func (mr *MockServiceMockRecorder) DeleteCodeSpaceEnvVar(ctx, name, codeSpaceEnvVarID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceEnvVar", reflect.TypeOf((*MockService)(nil).DeleteCodeSpaceEnvVar), ctx, name, codeSpaceEnvVarID)
+}
+
+// DeleteCodeSpaceFile mocks base method.
+func (m *MockService) DeleteCodeSpaceFile(ctx context.Context, name string, codeSpaceFileID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceFile", ctx, name, codeSpaceFileID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceFile indicates an expected call of DeleteCodeSpaceFile.
+func (mr *MockServiceMockRecorder) DeleteCodeSpaceFile(ctx, name, codeSpaceFileID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceFile", reflect.TypeOf((*MockService)(nil).DeleteCodeSpaceFile), ctx, name, codeSpaceFileID)
+}
+
+// DeleteCodeSpaceRunConfig mocks base method.
+func (m *MockService) DeleteCodeSpaceRunConfig(ctx context.Context, name string, codeSpaceRunConfigID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceRunConfig", ctx, name, codeSpaceRunConfigID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceRunConfig indicates an expected call of DeleteCodeSpaceRunConfig.
+func (mr *MockServiceMockRecorder) DeleteCodeSpaceRunConfig(ctx, name, codeSpaceRunConfigID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceRunConfig", reflect.TypeOf((*MockService)(nil).DeleteCodeSpaceRunConfig), ctx, name, codeSpaceRunConfigID)
+}
+
+// DeleteCodeSpaceTestCase mocks base method.
+func (m *MockService) DeleteCodeSpaceTestCase(ctx context.Context, name string, codeSpaceTestCaseID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCodeSpaceTestCase", ctx, name, codeSpaceTestCaseID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCodeSpaceTestCase indicates an expected call of DeleteCodeSpaceTestCase.
+func (mr *MockServiceMockRecorder) DeleteCodeSpaceTestCase(ctx, name, codeSpaceTestCaseID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCodeSpaceTestCase", reflect.TypeOf((*MockService)(nil).DeleteCodeSpaceTestCase), ctx, name, codeSpaceTestCaseID)
+}
+
+// DiffCodeSpaceRevisions mocks base method.
+func (m *MockService) DiffCodeSpaceRevisions(ctx context.Context, name string, fromCodeSpaceRevisionID, toCodeSpaceRevisionID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffCodeSpaceRevisions", ctx, name, fromCodeSpaceRevisionID, toCodeSpaceRevisionID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffCodeSpaceRevisions indicates an expected call of DiffCodeSpaceRevisions.
+func (mr *MockServiceMockRecorder) DiffCodeSpaceRevisions(ctx, name, fromCodeSpaceRevisionID, toCodeSpaceRevisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffCodeSpaceRevisions", reflect.TypeOf((*MockService)(nil).DiffCodeSpaceRevisions), ctx, name, fromCodeSpaceRevisionID, toCodeSpaceRevisionID)
+}
+
+// ForkCodeSpace mocks base method.
+func (m *MockService) ForkCodeSpace(ctx context.Context, name string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForkCodeSpace", ctx, name)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ForkCodeSpace indicates an expected call of ForkCodeSpace.
+func (mr *MockServiceMockRecorder) ForkCodeSpace(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForkCodeSpace", reflect.TypeOf((*MockService)(nil).ForkCodeSpace), ctx, name)
+}
+
 // GetCodeSpace mocks base method.
 func (m *MockService) GetCodeSpace(ctx context.Context, name string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -106,6 +282,36 @@ func (mr *MockServiceMockRecorder) GetCodeSpace(ctx, name any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpace", reflect.TypeOf((*MockService)(nil).GetCodeSpace), ctx, name)
 }
 
+// GetCodeSpaceRevision mocks base method.
+func (m *MockService) GetCodeSpaceRevision(ctx context.Context, name string, codeSpaceRevisionID int64) (*code.CodeSpaceRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceRevision", ctx, name, codeSpaceRevisionID)
+	ret0, _ := ret[0].(*code.CodeSpaceRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceRevision indicates an expected call of GetCodeSpaceRevision.
+func (mr *MockServiceMockRecorder) GetCodeSpaceRevision(ctx, name, codeSpaceRevisionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceRevision", reflect.TypeOf((*MockService)(nil).GetCodeSpaceRevision), ctx, name, codeSpaceRevisionID)
+}
+
+// GetCodeSpaceRun mocks base method.
+func (m *MockService) GetCodeSpaceRun(ctx context.Context, name string, codeSpaceRunID int64) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeSpaceRun", ctx, name, codeSpaceRunID)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCodeSpaceRun indicates an expected call of GetCodeSpaceRun.
+func (mr *MockServiceMockRecorder) GetCodeSpaceRun(ctx, name, codeSpaceRunID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeSpaceRun", reflect.TypeOf((*MockService)(nil).GetCodeSpaceRun), ctx, name, codeSpaceRunID)
+}
+
 // InviteCodeSpaceUser mocks base method.
 func (m *MockService) InviteCodeSpaceUser(ctx context.Context, name, inviteeEmail string, accessLevel code.CodeSpaceAccessLevel) error {
 	m.ctrl.T.Helper()
@@ -120,6 +326,126 @@ func (mr *MockServiceMockRecorder) InviteCodeSpaceUser(ctx, name, inviteeEmail,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteCodeSpaceUser", reflect.TypeOf((*MockService)(nil).InviteCodeSpaceUser), ctx, name, inviteeEmail, accessLevel)
 }
 
+// JoinCodeSpaceCollaboration mocks base method.
+func (m *MockService) JoinCodeSpaceCollaboration(ctx context.Context, name string) (*code.CodeSpaceCollaborator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinCodeSpaceCollaboration", ctx, name)
+	ret0, _ := ret[0].(*code.CodeSpaceCollaborator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinCodeSpaceCollaboration indicates an expected call of JoinCodeSpaceCollaboration.
+func (mr *MockServiceMockRecorder) JoinCodeSpaceCollaboration(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinCodeSpaceCollaboration", reflect.TypeOf((*MockService)(nil).JoinCodeSpaceCollaboration), ctx, name)
+}
+
+// ListCodeSpaceEnvVars mocks base method.
+func (m *MockService) ListCodeSpaceEnvVars(ctx context.Context, name string) ([]*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceEnvVars", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceEnvVars indicates an expected call of ListCodeSpaceEnvVars.
+func (mr *MockServiceMockRecorder) ListCodeSpaceEnvVars(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceEnvVars", reflect.TypeOf((*MockService)(nil).ListCodeSpaceEnvVars), ctx, name)
+}
+
+// ListCodeSpaceFiles mocks base method.
+func (m *MockService) ListCodeSpaceFiles(ctx context.Context, name string) ([]*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceFiles", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceFiles indicates an expected call of ListCodeSpaceFiles.
+func (mr *MockServiceMockRecorder) ListCodeSpaceFiles(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceFiles", reflect.TypeOf((*MockService)(nil).ListCodeSpaceFiles), ctx, name)
+}
+
+// ListCodeSpacePresence mocks base method.
+func (m *MockService) ListCodeSpacePresence(ctx context.Context, name string) ([]*code.CodeSpacePresence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpacePresence", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpacePresence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpacePresence indicates an expected call of ListCodeSpacePresence.
+func (mr *MockServiceMockRecorder) ListCodeSpacePresence(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpacePresence", reflect.TypeOf((*MockService)(nil).ListCodeSpacePresence), ctx, name)
+}
+
+// ListCodeSpaceRevisions mocks base method.
+func (m *MockService) ListCodeSpaceRevisions(ctx context.Context, name string, limit, offset int64) ([]*code.CodeSpaceRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRevisions", ctx, name, limit, offset)
+	ret0, _ := ret[0].([]*code.CodeSpaceRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRevisions indicates an expected call of ListCodeSpaceRevisions.
+func (mr *MockServiceMockRecorder) ListCodeSpaceRevisions(ctx, name, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRevisions", reflect.TypeOf((*MockService)(nil).ListCodeSpaceRevisions), ctx, name, limit, offset)
+}
+
+// ListCodeSpaceRunConfigs mocks base method.
+func (m *MockService) ListCodeSpaceRunConfigs(ctx context.Context, name string) ([]*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRunConfigs", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRunConfigs indicates an expected call of ListCodeSpaceRunConfigs.
+func (mr *MockServiceMockRecorder) ListCodeSpaceRunConfigs(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRunConfigs", reflect.TypeOf((*MockService)(nil).ListCodeSpaceRunConfigs), ctx, name)
+}
+
+// ListCodeSpaceRuns mocks base method.
+func (m *MockService) ListCodeSpaceRuns(ctx context.Context, name string, limit, offset int64) ([]*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceRuns", ctx, name, limit, offset)
+	ret0, _ := ret[0].([]*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceRuns indicates an expected call of ListCodeSpaceRuns.
+func (mr *MockServiceMockRecorder) ListCodeSpaceRuns(ctx, name, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceRuns", reflect.TypeOf((*MockService)(nil).ListCodeSpaceRuns), ctx, name, limit, offset)
+}
+
+// ListCodeSpaceTestCases mocks base method.
+func (m *MockService) ListCodeSpaceTestCases(ctx context.Context, name string) ([]*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodeSpaceTestCases", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodeSpaceTestCases indicates an expected call of ListCodeSpaceTestCases.
+func (mr *MockServiceMockRecorder) ListCodeSpaceTestCases(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaceTestCases", reflect.TypeOf((*MockService)(nil).ListCodeSpaceTestCases), ctx, name)
+}
+
 // ListCodeSpaceUsers mocks base method.
 func (m *MockService) ListCodeSpaceUsers(ctx context.Context, name string) ([]*auth.User, []*code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
@@ -152,6 +478,66 @@ func (mr *MockServiceMockRecorder) ListCodeSpaces(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodeSpaces", reflect.TypeOf((*MockService)(nil).ListCodeSpaces), ctx)
 }
 
+// ListCodingLanguages mocks base method.
+func (m *MockService) ListCodingLanguages(ctx context.Context) ([]*code.CodingLanguage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCodingLanguages", ctx)
+	ret0, _ := ret[0].([]*code.CodingLanguage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCodingLanguages indicates an expected call of ListCodingLanguages.
+func (mr *MockServiceMockRecorder) ListCodingLanguages(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCodingLanguages", reflect.TypeOf((*MockService)(nil).ListCodingLanguages), ctx)
+}
+
+// ListTrashedCodeSpaces mocks base method.
+func (m *MockService) ListTrashedCodeSpaces(ctx context.Context) ([]*code.CodeSpace, []*code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashedCodeSpaces", ctx)
+	ret0, _ := ret[0].([]*code.CodeSpace)
+	ret1, _ := ret[1].([]*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTrashedCodeSpaces indicates an expected call of ListTrashedCodeSpaces.
+func (mr *MockServiceMockRecorder) ListTrashedCodeSpaces(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashedCodeSpaces", reflect.TypeOf((*MockService)(nil).ListTrashedCodeSpaces), ctx)
+}
+
+// PurgeTrashedCodeSpaces mocks base method.
+func (m *MockService) PurgeTrashedCodeSpaces(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrashedCodeSpaces", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrashedCodeSpaces indicates an expected call of PurgeTrashedCodeSpaces.
+func (mr *MockServiceMockRecorder) PurgeTrashedCodeSpaces(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashedCodeSpaces", reflect.TypeOf((*MockService)(nil).PurgeTrashedCodeSpaces), ctx)
+}
+
+// PurgeTrashedCodeSpacesEvery mocks base method.
+func (m *MockService) PurgeTrashedCodeSpacesEvery(interval time.Duration, onError func(error)) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrashedCodeSpacesEvery", interval, onError)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// PurgeTrashedCodeSpacesEvery indicates an expected call of PurgeTrashedCodeSpacesEvery.
+func (mr *MockServiceMockRecorder) PurgeTrashedCodeSpacesEvery(interval, onError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashedCodeSpacesEvery", reflect.TypeOf((*MockService)(nil).PurgeTrashedCodeSpacesEvery), interval, onError)
+}
+
 // RemoveCodeSpaceUser mocks base method.
 func (m *MockService) RemoveCodeSpaceUser(ctx context.Context, name, codeSpaceUserUUID string) error {
 	m.ctrl.T.Helper()
@@ -166,19 +552,125 @@ func (mr *MockServiceMockRecorder) RemoveCodeSpaceUser(ctx, name, codeSpaceUserU
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCodeSpaceUser", reflect.TypeOf((*MockService)(nil).RemoveCodeSpaceUser), ctx, name, codeSpaceUserUUID)
 }
 
+// RestoreCodeSpace mocks base method.
+func (m *MockService) RestoreCodeSpace(ctx context.Context, name string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCodeSpace", ctx, name)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RestoreCodeSpace indicates an expected call of RestoreCodeSpace.
+func (mr *MockServiceMockRecorder) RestoreCodeSpace(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCodeSpace", reflect.TypeOf((*MockService)(nil).RestoreCodeSpace), ctx, name)
+}
+
+// RestoreCodeSpaceRevision mocks base method.
+func (m *MockService) RestoreCodeSpaceRevision(ctx context.Context, name string, codeSpaceRevisionID int64, version *int64) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCodeSpaceRevision", ctx, name, codeSpaceRevisionID, version)
+	ret0, _ := ret[0].(*code.CodeSpace)
+	ret1, _ := ret[1].(*code.CodeSpaceAccess)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RestoreCodeSpaceRevision indicates an expected call of RestoreCodeSpaceRevision.
+func (mr *MockServiceMockRecorder) RestoreCodeSpaceRevision(ctx, name, codeSpaceRevisionID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCodeSpaceRevision", reflect.TypeOf((*MockService)(nil).RestoreCodeSpaceRevision), ctx, name, codeSpaceRevisionID, version)
+}
+
 // RunCodeSpace mocks base method.
-func (m *MockService) RunCodeSpace(ctx context.Context, name string) (*api.PistonExecuteResponse, error) {
+func (m *MockService) RunCodeSpace(ctx context.Context, name string, opts *code.RunCodeSpaceOptions) (*code.CodeSpaceRun, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunCodeSpace", ctx, name)
-	ret0, _ := ret[0].(*api.PistonExecuteResponse)
+	ret := m.ctrl.Call(m, "RunCodeSpace", ctx, name, opts)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RunCodeSpace indicates an expected call of RunCodeSpace.
-func (mr *MockServiceMockRecorder) RunCodeSpace(ctx, name any) *gomock.Call {
+func (mr *MockServiceMockRecorder) RunCodeSpace(ctx, name, opts any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCodeSpace", reflect.TypeOf((*MockService)(nil).RunCodeSpace), ctx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCodeSpace", reflect.TypeOf((*MockService)(nil).RunCodeSpace), ctx, name, opts)
+}
+
+// RunCodeSpaceInteractively mocks base method.
+func (m *MockService) RunCodeSpaceInteractively(ctx context.Context, name string, opts *code.RunCodeSpaceOptions, interact func(piston.Session) error) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCodeSpaceInteractively", ctx, name, opts, interact)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunCodeSpaceInteractively indicates an expected call of RunCodeSpaceInteractively.
+func (mr *MockServiceMockRecorder) RunCodeSpaceInteractively(ctx, name, opts, interact any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCodeSpaceInteractively", reflect.TypeOf((*MockService)(nil).RunCodeSpaceInteractively), ctx, name, opts, interact)
+}
+
+// RunCodeSpaceMatrix mocks base method.
+func (m *MockService) RunCodeSpaceMatrix(ctx context.Context, name string, versions []string, opts *code.RunCodeSpaceOptions) ([]*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCodeSpaceMatrix", ctx, name, versions, opts)
+	ret0, _ := ret[0].([]*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunCodeSpaceMatrix indicates an expected call of RunCodeSpaceMatrix.
+func (mr *MockServiceMockRecorder) RunCodeSpaceMatrix(ctx, name, versions, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCodeSpaceMatrix", reflect.TypeOf((*MockService)(nil).RunCodeSpaceMatrix), ctx, name, versions, opts)
+}
+
+// RunCodeSpaceTests mocks base method.
+func (m *MockService) RunCodeSpaceTests(ctx context.Context, name string) ([]*code.CodeSpaceTestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunCodeSpaceTests", ctx, name)
+	ret0, _ := ret[0].([]*code.CodeSpaceTestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunCodeSpaceTests indicates an expected call of RunCodeSpaceTests.
+func (mr *MockServiceMockRecorder) RunCodeSpaceTests(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCodeSpaceTests", reflect.TypeOf((*MockService)(nil).RunCodeSpaceTests), ctx, name)
+}
+
+// SaveCodeSpaceCollaborations mocks base method.
+func (m *MockService) SaveCodeSpaceCollaborations(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCodeSpaceCollaborations", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveCodeSpaceCollaborations indicates an expected call of SaveCodeSpaceCollaborations.
+func (mr *MockServiceMockRecorder) SaveCodeSpaceCollaborations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCodeSpaceCollaborations", reflect.TypeOf((*MockService)(nil).SaveCodeSpaceCollaborations), ctx)
+}
+
+// SaveCodeSpaceCollaborationsEvery mocks base method.
+func (m *MockService) SaveCodeSpaceCollaborationsEvery(interval time.Duration, onError func(error)) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCodeSpaceCollaborationsEvery", interval, onError)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// SaveCodeSpaceCollaborationsEvery indicates an expected call of SaveCodeSpaceCollaborationsEvery.
+func (mr *MockServiceMockRecorder) SaveCodeSpaceCollaborationsEvery(interval, onError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCodeSpaceCollaborationsEvery", reflect.TypeOf((*MockService)(nil).SaveCodeSpaceCollaborationsEvery), interval, onError)
 }
 
 // SendCodeSpaceInvitationMail mocks base method.
@@ -195,10 +687,69 @@ func (mr *MockServiceMockRecorder) SendCodeSpaceInvitationMail(ctx, email, data
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCodeSpaceInvitationMail", reflect.TypeOf((*MockService)(nil).SendCodeSpaceInvitationMail), ctx, email, data)
 }
 
+// StartCodeSpaceRun mocks base method.
+func (m *MockService) StartCodeSpaceRun(ctx context.Context, name string, opts *code.RunCodeSpaceOptions) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartCodeSpaceRun", ctx, name, opts)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartCodeSpaceRun indicates an expected call of StartCodeSpaceRun.
+func (mr *MockServiceMockRecorder) StartCodeSpaceRun(ctx, name, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCodeSpaceRun", reflect.TypeOf((*MockService)(nil).StartCodeSpaceRun), ctx, name, opts)
+}
+
+// StreamCodeSpaceRun mocks base method.
+func (m *MockService) StreamCodeSpaceRun(ctx context.Context, name string, opts *code.RunCodeSpaceOptions, onEvent func(*api.PistonEvent)) (*code.CodeSpaceRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCodeSpaceRun", ctx, name, opts, onEvent)
+	ret0, _ := ret[0].(*code.CodeSpaceRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamCodeSpaceRun indicates an expected call of StreamCodeSpaceRun.
+func (mr *MockServiceMockRecorder) StreamCodeSpaceRun(ctx, name, opts, onEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCodeSpaceRun", reflect.TypeOf((*MockService)(nil).StreamCodeSpaceRun), ctx, name, opts, onEvent)
+}
+
+// ThinCodeSpaceRevisions mocks base method.
+func (m *MockService) ThinCodeSpaceRevisions(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ThinCodeSpaceRevisions", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ThinCodeSpaceRevisions indicates an expected call of ThinCodeSpaceRevisions.
+func (mr *MockServiceMockRecorder) ThinCodeSpaceRevisions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ThinCodeSpaceRevisions", reflect.TypeOf((*MockService)(nil).ThinCodeSpaceRevisions), ctx)
+}
+
+// ThinCodeSpaceRevisionsEvery mocks base method.
+func (m *MockService) ThinCodeSpaceRevisionsEvery(interval time.Duration, onError func(error)) func() {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ThinCodeSpaceRevisionsEvery", interval, onError)
+	ret0, _ := ret[0].(func())
+	return ret0
+}
+
+// ThinCodeSpaceRevisionsEvery indicates an expected call of ThinCodeSpaceRevisionsEvery.
+func (mr *MockServiceMockRecorder) ThinCodeSpaceRevisionsEvery(interval, onError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ThinCodeSpaceRevisionsEvery", reflect.TypeOf((*MockService)(nil).ThinCodeSpaceRevisionsEvery), interval, onError)
+}
+
 // UpdateCodeSpace mocks base method.
-func (m *MockService) UpdateCodeSpace(ctx context.Context, name string, contents *string) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
+func (m *MockService) UpdateCodeSpace(ctx context.Context, name string, newName, contents, languageVersion *string, version *int64) (*code.CodeSpace, *code.CodeSpaceAccess, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCodeSpace", ctx, name, contents)
+	ret := m.ctrl.Call(m, "UpdateCodeSpace", ctx, name, newName, contents, languageVersion, version)
 	ret0, _ := ret[0].(*code.CodeSpace)
 	ret1, _ := ret[1].(*code.CodeSpaceAccess)
 	ret2, _ := ret[2].(error)
@@ -206,7 +757,67 @@ func (m *MockService) UpdateCodeSpace(ctx context.Context, name string, contents
 }
 
 // UpdateCodeSpace indicates an expected call of UpdateCodeSpace.
-func (mr *MockServiceMockRecorder) UpdateCodeSpace(ctx, name, contents any) *gomock.Call {
+func (mr *MockServiceMockRecorder) UpdateCodeSpace(ctx, name, newName, contents, languageVersion, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpace", reflect.TypeOf((*MockService)(nil).UpdateCodeSpace), ctx, name, newName, contents, languageVersion, version)
+}
+
+// UpdateCodeSpaceEnvVar mocks base method.
+func (m *MockService) UpdateCodeSpaceEnvVar(ctx context.Context, name string, codeSpaceEnvVarID int64, envVarName, value *string, isSecret *bool) (*code.CodeSpaceEnvVar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceEnvVar", ctx, name, codeSpaceEnvVarID, envVarName, value, isSecret)
+	ret0, _ := ret[0].(*code.CodeSpaceEnvVar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceEnvVar indicates an expected call of UpdateCodeSpaceEnvVar.
+func (mr *MockServiceMockRecorder) UpdateCodeSpaceEnvVar(ctx, name, codeSpaceEnvVarID, envVarName, value, isSecret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceEnvVar", reflect.TypeOf((*MockService)(nil).UpdateCodeSpaceEnvVar), ctx, name, codeSpaceEnvVarID, envVarName, value, isSecret)
+}
+
+// UpdateCodeSpaceFile mocks base method.
+func (m *MockService) UpdateCodeSpaceFile(ctx context.Context, name string, codeSpaceFileID int64, fileName, contents *string, isEntryPoint *bool) (*code.CodeSpaceFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceFile", ctx, name, codeSpaceFileID, fileName, contents, isEntryPoint)
+	ret0, _ := ret[0].(*code.CodeSpaceFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceFile indicates an expected call of UpdateCodeSpaceFile.
+func (mr *MockServiceMockRecorder) UpdateCodeSpaceFile(ctx, name, codeSpaceFileID, fileName, contents, isEntryPoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceFile", reflect.TypeOf((*MockService)(nil).UpdateCodeSpaceFile), ctx, name, codeSpaceFileID, fileName, contents, isEntryPoint)
+}
+
+// UpdateCodeSpaceRunConfig mocks base method.
+func (m *MockService) UpdateCodeSpaceRunConfig(ctx context.Context, name string, codeSpaceRunConfigID int64, configName, stdin *string, args []string, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit *int64, languageVersion *string) (*code.CodeSpaceRunConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceRunConfig", ctx, name, codeSpaceRunConfigID, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+	ret0, _ := ret[0].(*code.CodeSpaceRunConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceRunConfig indicates an expected call of UpdateCodeSpaceRunConfig.
+func (mr *MockServiceMockRecorder) UpdateCodeSpaceRunConfig(ctx, name, codeSpaceRunConfigID, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceRunConfig", reflect.TypeOf((*MockService)(nil).UpdateCodeSpaceRunConfig), ctx, name, codeSpaceRunConfigID, configName, stdin, args, compileTimeout, runTimeout, compileMemoryLimit, runMemoryLimit, languageVersion)
+}
+
+// UpdateCodeSpaceTestCase mocks base method.
+func (m *MockService) UpdateCodeSpaceTestCase(ctx context.Context, name string, codeSpaceTestCaseID int64, stdin *string, args []string, expectedStdout, comparisonMode *string, isHidden *bool) (*code.CodeSpaceTestCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCodeSpaceTestCase", ctx, name, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden)
+	ret0, _ := ret[0].(*code.CodeSpaceTestCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCodeSpaceTestCase indicates an expected call of UpdateCodeSpaceTestCase.
+func (mr *MockServiceMockRecorder) UpdateCodeSpaceTestCase(ctx, name, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpace", reflect.TypeOf((*MockService)(nil).UpdateCodeSpace), ctx, name, contents)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCodeSpaceTestCase", reflect.TypeOf((*MockService)(nil).UpdateCodeSpaceTestCase), ctx, name, codeSpaceTestCaseID, stdin, args, expectedStdout, comparisonMode, isHidden)
 }
@@ -3,12 +3,14 @@ package code
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/database"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // Repository is used to access, modify, and delete code space data.
@@ -41,12 +43,47 @@ type Repository interface {
 		querier database.Querier,
 		codeSpaceID int64,
 		contents *string,
+		languageVersion *string,
+		version *int64,
+	) (*CodeSpace, error)
+	RenameCodeSpace(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		name string,
+		version *int64,
 	) (*CodeSpace, error)
 	DeleteCodeSpace(
 		ctx context.Context,
 		querier database.Querier,
 		codeSpaceID int64,
 	) error
+	TrashCodeSpace(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) error
+	ListTrashedCodeSpaces(
+		ctx context.Context,
+		querier database.Querier,
+		userUUID string,
+	) ([]*CodeSpace, []*CodeSpaceAccess, error)
+	GetTrashedCodeSpaceWithAccessByName(
+		ctx context.Context,
+		querier database.Querier,
+		userUUID string,
+		name string,
+	) (*CodeSpace, *CodeSpaceAccess, error)
+	RestoreCodeSpace(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) (*CodeSpace, error)
+	DeleteTrashedCodeSpaces(
+		ctx context.Context,
+		querier database.Querier,
+		deletedBefore time.Time,
+	) (int64, error)
 	CreateOrUpdateCodeSpaceAccess(
 		ctx context.Context,
 		querier database.Querier,
@@ -63,6 +100,207 @@ type Repository interface {
 		userUUID string,
 		codeSpaceID int64,
 	) error
+	CreateCodeSpaceRun(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceRun *CodeSpaceRun,
+	) (*CodeSpaceRun, error)
+	ListCodeSpaceRuns(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		limit int64,
+		offset int64,
+	) ([]*CodeSpaceRun, error)
+	GetCodeSpaceRun(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceRunID int64,
+	) (*CodeSpaceRun, error)
+	UpdateCodeSpaceRun(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceRun *CodeSpaceRun,
+	) (*CodeSpaceRun, error)
+	CreateCodeSpaceFile(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceFile *CodeSpaceFile,
+	) (*CodeSpaceFile, error)
+	ListCodeSpaceFiles(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) ([]*CodeSpaceFile, error)
+	UpdateCodeSpaceFile(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceFileID int64,
+		name *string,
+		contents *string,
+		isEntryPoint *bool,
+	) (*CodeSpaceFile, error)
+	ClearCodeSpaceEntryPoint(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) error
+	DeleteCodeSpaceFile(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceFileID int64,
+	) error
+	CreateCodeSpaceTestCase(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceTestCase *CodeSpaceTestCase,
+	) (*CodeSpaceTestCase, error)
+	ListCodeSpaceTestCases(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		includeHidden bool,
+	) ([]*CodeSpaceTestCase, error)
+	UpdateCodeSpaceTestCase(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceTestCaseID int64,
+		stdin *string,
+		args []string,
+		expectedStdout *string,
+		comparisonMode *string,
+		isHidden *bool,
+	) (*CodeSpaceTestCase, error)
+	DeleteCodeSpaceTestCase(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceTestCaseID int64,
+	) error
+	CreateCodeSpaceRunConfig(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceRunConfig *CodeSpaceRunConfig,
+	) (*CodeSpaceRunConfig, error)
+	ListCodeSpaceRunConfigs(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) ([]*CodeSpaceRunConfig, error)
+	GetCodeSpaceRunConfigByName(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		name string,
+	) (*CodeSpaceRunConfig, error)
+	UpdateCodeSpaceRunConfig(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceRunConfigID int64,
+		name *string,
+		stdin *string,
+		args []string,
+		compileTimeout *int64,
+		runTimeout *int64,
+		compileMemoryLimit *int64,
+		runMemoryLimit *int64,
+		languageVersion *string,
+	) (*CodeSpaceRunConfig, error)
+	DeleteCodeSpaceRunConfig(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceRunConfigID int64,
+	) error
+	CreateCodeSpaceEnvVar(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceEnvVar *CodeSpaceEnvVar,
+	) (*CodeSpaceEnvVar, error)
+	ListCodeSpaceEnvVars(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+	) ([]*CodeSpaceEnvVar, error)
+	GetCodeSpaceEnvVar(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceEnvVarID int64,
+	) (*CodeSpaceEnvVar, error)
+	UpdateCodeSpaceEnvVar(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceEnvVarID int64,
+		name *string,
+		value *string,
+		isSecret *bool,
+	) (*CodeSpaceEnvVar, error)
+	DeleteCodeSpaceEnvVar(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceEnvVarID int64,
+	) error
+	CreateCodeSpaceAlias(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceAlias *CodeSpaceAlias,
+	) (*CodeSpaceAlias, error)
+	GetCodeSpaceAliasByName(
+		ctx context.Context,
+		querier database.Querier,
+		name string,
+	) (*CodeSpaceAlias, error)
+	DeleteCodeSpaceAlias(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceAliasID int64,
+	) error
+	CreateCodeSpaceRevision(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceRevision *CodeSpaceRevision,
+	) (*CodeSpaceRevision, error)
+	ListCodeSpaceRevisions(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		limit int64,
+		offset int64,
+	) ([]*CodeSpaceRevision, error)
+	GetCodeSpaceRevision(
+		ctx context.Context,
+		querier database.Querier,
+		codeSpaceID int64,
+		codeSpaceRevisionID int64,
+	) (*CodeSpaceRevision, error)
+	DeleteThinnedCodeSpaceRevisions(
+		ctx context.Context,
+		querier database.Querier,
+		createdBefore time.Time,
+	) (int64, error)
+	GetExecutionCacheEntry(
+		ctx context.Context,
+		querier database.Querier,
+		key string,
+	) (*ExecutionCacheEntry, error)
+	CreateOrUpdateExecutionCacheEntry(
+		ctx context.Context,
+		querier database.Querier,
+		executionCacheEntry *ExecutionCacheEntry,
+	) (*ExecutionCacheEntry, error)
+	DeleteStaleExecutionCacheEntries(
+		ctx context.Context,
+		querier database.Querier,
+		maxEntries int64,
+	) error
 }
 
 // repository implements Repository.
@@ -91,7 +329,9 @@ INSERT INTO code_space (
 	author_uuid,
 	name,
 	language,
+	language_version,
 	contents,
+	forked_from_id,
 	created_at,
 	updated_at
 )
@@ -101,14 +341,20 @@ VALUES (
 	$3,
 	$4,
 	$5,
-	$6
+	$6,
+	$7,
+	$8
 )
 RETURNING
 	id,
 	author_uuid,
 	name,
 	language,
+	language_version,
 	contents,
+	forked_from_id,
+	version,
+	deleted_at,
 	created_at,
 	updated_at;
 	`
@@ -119,7 +365,9 @@ RETURNING
 		codeSpace.AuthorUUID,
 		codeSpace.Name,
 		codeSpace.Language,
+		codeSpace.LanguageVersion,
 		codeSpace.Contents,
+		codeSpace.ForkedFromID,
 		now,
 		now,
 	).Scan(
@@ -127,10 +375,22 @@ RETURNING
 		&createdCodeSpace.AuthorUUID,
 		&createdCodeSpace.Name,
 		&createdCodeSpace.Language,
+		&createdCodeSpace.LanguageVersion,
 		&createdCodeSpace.Contents,
+		&createdCodeSpace.ForkedFromID,
+		&createdCodeSpace.Version,
+		&createdCodeSpace.DeletedAt,
 		&createdCodeSpace.CreatedAt,
 		&createdCodeSpace.UpdatedAt,
 	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
 	if err != nil {
 		return nil, errutils.FormatError(err, "querier.Scan failed")
 	}
@@ -153,7 +413,11 @@ SELECT
 	c.author_uuid,
 	c.name,
 	c.language,
+	c.language_version,
 	c.contents,
+	c.forked_from_id,
+	c.version,
+	c.deleted_at,
 	c.created_at,
 	c.updated_at,
 	a.id,
@@ -170,7 +434,8 @@ ON
 	c.id = a.code_space_id
 WHERE
 	a.user_uuid = $1
-	AND a.level >= $2;
+	AND a.level >= $2
+	AND c.deleted_at IS NULL;
 	`
 
 	rows, err := querier.Query(ctx, q, userUUID, CodeSpaceAccessLevelReadOnly)
@@ -188,7 +453,11 @@ WHERE
 			&codeSpace.AuthorUUID,
 			&codeSpace.Name,
 			&codeSpace.Language,
+			&codeSpace.LanguageVersion,
 			&codeSpace.Contents,
+			&codeSpace.ForkedFromID,
+			&codeSpace.Version,
+			&codeSpace.DeletedAt,
 			&codeSpace.CreatedAt,
 			&codeSpace.UpdatedAt,
 			&codeSpaceAccess.ID,
@@ -223,13 +492,18 @@ SELECT
 	c.author_uuid,
 	c.name,
 	c.language,
+	c.language_version,
 	c.contents,
+	c.forked_from_id,
+	c.version,
+	c.deleted_at,
 	c.created_at,
 	c.updated_at
 FROM
 	code_space c
 WHERE
-	c.id = $1;
+	c.id = $1
+	AND c.deleted_at IS NULL;
 	`
 
 	err := querier.QueryRow(ctx, q, codeSpaceID).Scan(
@@ -237,7 +511,11 @@ WHERE
 		&codeSpace.AuthorUUID,
 		&codeSpace.Name,
 		&codeSpace.Language,
+		&codeSpace.LanguageVersion,
 		&codeSpace.Contents,
+		&codeSpace.ForkedFromID,
+		&codeSpace.Version,
+		&codeSpace.DeletedAt,
 		&codeSpace.CreatedAt,
 		&codeSpace.UpdatedAt,
 	)
@@ -254,6 +532,7 @@ WHERE
 }
 
 // GetCodeSpaceWithAccessByName gets a given code space and its corresponding code space access for a given user.
+// The code space is found by its name or by any of its aliases, preferring the code space with the given name.
 func (repo *repository) GetCodeSpaceWithAccessByName(
 	ctx context.Context,
 	querier database.Querier,
@@ -269,7 +548,11 @@ SELECT
 	c.author_uuid,
 	c.name,
 	c.language,
+	c.language_version,
 	c.contents,
+	c.forked_from_id,
+	c.version,
+	c.deleted_at,
 	c.created_at,
 	c.updated_at,
 	a.id,
@@ -285,9 +568,16 @@ INNER JOIN
 ON
 	c.id = a.code_space_id
 WHERE
-	c.name = $1
+	(
+		c.name = $1
+		OR c.id IN (SELECT s.code_space_id FROM code_space_alias s WHERE s.name = $1)
+	)
 	AND a.user_uuid = $2
-	AND a.level >= $3;
+	AND a.level >= $3
+	AND c.deleted_at IS NULL
+ORDER BY
+	c.name = $1 DESC
+LIMIT 1;
 	`
 
 	err := querier.QueryRow(ctx, q, name, userUUID, CodeSpaceAccessLevelReadOnly).Scan(
@@ -295,7 +585,11 @@ WHERE
 		&codeSpace.AuthorUUID,
 		&codeSpace.Name,
 		&codeSpace.Language,
+		&codeSpace.LanguageVersion,
 		&codeSpace.Contents,
+		&codeSpace.ForkedFromID,
+		&codeSpace.Version,
+		&codeSpace.DeletedAt,
 		&codeSpace.CreatedAt,
 		&codeSpace.UpdatedAt,
 		&codeSpaceAccess.ID,
@@ -317,14 +611,18 @@ WHERE
 	return codeSpace, codeSpaceAccess, nil
 }
 
-// UpdateCodeSpace updates a code space.
+// UpdateCodeSpace updates a code space and increments its version.
+// An empty languageVersion unpins the code space from its language version.
+// If version is not nil, the code space is only updated if it is still at the given version.
 func (repo *repository) UpdateCodeSpace(
 	ctx context.Context,
 	querier database.Querier,
 	codeSpaceID int64,
 	contents *string,
+	languageVersion *string,
+	version *int64,
 ) (*CodeSpace, error) {
-	if contents == nil {
+	if contents == nil && languageVersion == nil {
 		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "all attributes are nil")
 	}
 
@@ -335,15 +633,23 @@ UPDATE
 	code_space
 SET
 	contents = COALESCE($1, contents),
-	updated_at = $2
+	language_version = CASE WHEN $2::VARCHAR IS NULL THEN language_version ELSE NULLIF($2, '') END,
+	version = version + 1,
+	updated_at = $3
 WHERE
-	id = $3
+	id = $4
+	AND ($5::BIGINT IS NULL OR version = $5)
+	AND deleted_at IS NULL
 RETURNING
 	id,
 	author_uuid,
 	name,
 	language,
+	language_version,
 	contents,
+	forked_from_id,
+	version,
+	deleted_at,
 	created_at,
 	updated_at;
 	`
@@ -352,14 +658,20 @@ RETURNING
 		ctx,
 		q,
 		contents,
+		languageVersion,
 		repo.timeProvider.Now(),
 		codeSpaceID,
+		version,
 	).Scan(
 		&updatedCodeSpace.ID,
 		&updatedCodeSpace.AuthorUUID,
 		&updatedCodeSpace.Name,
 		&updatedCodeSpace.Language,
+		&updatedCodeSpace.LanguageVersion,
 		&updatedCodeSpace.Contents,
+		&updatedCodeSpace.ForkedFromID,
+		&updatedCodeSpace.Version,
+		&updatedCodeSpace.DeletedAt,
 		&updatedCodeSpace.CreatedAt,
 		&updatedCodeSpace.UpdatedAt,
 	)
@@ -375,6 +687,74 @@ RETURNING
 	return updatedCodeSpace, nil
 }
 
+// RenameCodeSpace changes the name of a code space and increments its version.
+// If version is not nil, the code space is only renamed if it is still at the given version.
+func (repo *repository) RenameCodeSpace(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	name string,
+	version *int64,
+) (*CodeSpace, error) {
+	renamedCodeSpace := &CodeSpace{}
+
+	q := `
+UPDATE
+	code_space
+SET
+	name = $1,
+	version = version + 1,
+	updated_at = $2
+WHERE
+	id = $3
+	AND ($4::BIGINT IS NULL OR version = $4)
+	AND deleted_at IS NULL
+RETURNING
+	id,
+	author_uuid,
+	name,
+	language,
+	language_version,
+	contents,
+	forked_from_id,
+	version,
+	deleted_at,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(ctx, q, name, repo.timeProvider.Now(), codeSpaceID, version).Scan(
+		&renamedCodeSpace.ID,
+		&renamedCodeSpace.AuthorUUID,
+		&renamedCodeSpace.Name,
+		&renamedCodeSpace.Language,
+		&renamedCodeSpace.LanguageVersion,
+		&renamedCodeSpace.Contents,
+		&renamedCodeSpace.ForkedFromID,
+		&renamedCodeSpace.Version,
+		&renamedCodeSpace.DeletedAt,
+		&renamedCodeSpace.CreatedAt,
+		&renamedCodeSpace.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return renamedCodeSpace, nil
+}
+
 // DeleteCodeSpace deletes a code space.
 // If no code space is found, error is returned.
 func (repo *repository) DeleteCodeSpace(
@@ -401,86 +781,57 @@ WHERE
 	return nil
 }
 
-// CreateOrUpdateCodeSpaceAccess creates a new code space access or updates the existing one.
-func (repo *repository) CreateOrUpdateCodeSpaceAccess(
+// TrashCodeSpace moves a code space to the trash.
+// If no code space outside the trash is found, error is returned.
+func (repo *repository) TrashCodeSpace(
 	ctx context.Context,
 	querier database.Querier,
-	codeSpaceAccess *CodeSpaceAccess,
-) (*CodeSpaceAccess, error) {
-	now := repo.timeProvider.Now()
-	createdCodeSpaceAccess := &CodeSpaceAccess{}
-
+	codeSpaceID int64,
+) error {
 	q := `
-INSERT INTO code_space_access (
-	user_uuid,
-	code_space_id,
-	level,
-	created_at,
-	updated_at
-)
-VALUES (
-	$1,
-	$2,
-	$3,
-	$4,
-	$5
-)
-ON CONFLICT (user_uuid, code_space_id)
-DO UPDATE SET
-	level = EXCLUDED.level,
-	updated_at = EXCLUDED.updated_at
-RETURNING
-	id,
-	user_uuid,
-	code_space_id,
-	level,
-	created_at,
-	updated_at;
+UPDATE
+	code_space
+SET
+	deleted_at = $1
+WHERE
+	id = $2
+	AND deleted_at IS NULL;
 	`
 
-	err := querier.QueryRow(
-		ctx,
-		q,
-		codeSpaceAccess.UserUUID,
-		codeSpaceAccess.CodeSpaceID,
-		codeSpaceAccess.Level,
-		now,
-		now,
-	).Scan(
-		&createdCodeSpaceAccess.ID,
-		&createdCodeSpaceAccess.UserUUID,
-		&createdCodeSpaceAccess.CodeSpaceID,
-		&createdCodeSpaceAccess.Level,
-		&createdCodeSpaceAccess.CreatedAt,
-		&createdCodeSpaceAccess.UpdatedAt,
-	)
+	ct, err := querier.Exec(ctx, q, repo.timeProvider.Now(), codeSpaceID)
 	if err != nil {
-		return nil, errutils.FormatError(err, "querier.Scan failed")
+		return errutils.FormatError(err, "querier.Exec failed")
 	}
 
-	return createdCodeSpaceAccess, nil
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
 }
 
-// ListUsersWithCodeSpaceAccess lists all users with access to a given code space.
-func (repo *repository) ListUsersWithCodeSpaceAccess(
+// ListTrashedCodeSpaces lists code spaces in the trash that a given user has read-write access to.
+func (repo *repository) ListTrashedCodeSpaces(
 	ctx context.Context,
 	querier database.Querier,
-	codeSpaceID int64,
-) ([]*auth.User, []*CodeSpaceAccess, error) {
-	users := make([]*auth.User, 0)
-	codeSpacesAccesses := make([]*CodeSpaceAccess, 0)
+	userUUID string,
+) ([]*CodeSpace, []*CodeSpaceAccess, error) {
+	codeSpaces := make([]*CodeSpace, 0)
+	codeSpaceAccesses := make([]*CodeSpaceAccess, 0)
 
 	q := `
 SELECT
-	u.uuid,
-	u.email,
-	u.password,
-	u.first_name,
-	u.last_name,
-	u.is_active,
-	u.is_superuser,
-	u.created_at,
-	u.updated_at,
+	c.id,
+	c.author_uuid,
+	c.name,
+	c.language,
+	c.language_version,
+	c.contents,
+	c.forked_from_id,
+	c.version,
+	c.deleted_at,
+	c.created_at,
+	c.updated_at,
 	a.id,
 	a.user_uuid,
 	a.code_space_id,
@@ -488,7 +839,307 @@ SELECT
 	a.created_at,
 	a.updated_at
 FROM
-	"user" u
+	code_space c
+INNER JOIN
+	code_space_access a
+ON
+	c.id = a.code_space_id
+WHERE
+	a.user_uuid = $1
+	AND a.level >= $2
+	AND c.deleted_at IS NOT NULL
+ORDER BY
+	c.deleted_at DESC;
+	`
+
+	rows, err := querier.Query(ctx, q, userUUID, CodeSpaceAccessLevelReadWrite)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpace := &CodeSpace{}
+		codeSpaceAccess := &CodeSpaceAccess{}
+
+		err := rows.Scan(
+			&codeSpace.ID,
+			&codeSpace.AuthorUUID,
+			&codeSpace.Name,
+			&codeSpace.Language,
+			&codeSpace.LanguageVersion,
+			&codeSpace.Contents,
+			&codeSpace.ForkedFromID,
+			&codeSpace.Version,
+			&codeSpace.DeletedAt,
+			&codeSpace.CreatedAt,
+			&codeSpace.UpdatedAt,
+			&codeSpaceAccess.ID,
+			&codeSpaceAccess.UserUUID,
+			&codeSpaceAccess.CodeSpaceID,
+			&codeSpaceAccess.Level,
+			&codeSpaceAccess.CreatedAt,
+			&codeSpaceAccess.UpdatedAt,
+		)
+		if err != nil {
+			return nil, nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaces = append(codeSpaces, codeSpace)
+		codeSpaceAccesses = append(codeSpaceAccesses, codeSpaceAccess)
+	}
+
+	return codeSpaces, codeSpaceAccesses, nil
+}
+
+// GetTrashedCodeSpaceWithAccessByName gets a given code space in the trash
+// and its corresponding code space access for a given user.
+// The code space is found by its name or by any of its aliases, preferring the code space with the given name.
+func (repo *repository) GetTrashedCodeSpaceWithAccessByName(
+	ctx context.Context,
+	querier database.Querier,
+	userUUID string,
+	name string,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	codeSpace := &CodeSpace{}
+	codeSpaceAccess := &CodeSpaceAccess{}
+
+	q := `
+SELECT
+	c.id,
+	c.author_uuid,
+	c.name,
+	c.language,
+	c.language_version,
+	c.contents,
+	c.forked_from_id,
+	c.version,
+	c.deleted_at,
+	c.created_at,
+	c.updated_at,
+	a.id,
+	a.user_uuid,
+	a.code_space_id,
+	a.level,
+	a.created_at,
+	a.updated_at
+FROM
+	code_space c
+INNER JOIN
+	code_space_access a
+ON
+	c.id = a.code_space_id
+WHERE
+	(
+		c.name = $1
+		OR c.id IN (SELECT s.code_space_id FROM code_space_alias s WHERE s.name = $1)
+	)
+	AND a.user_uuid = $2
+	AND a.level >= $3
+	AND c.deleted_at IS NOT NULL
+ORDER BY
+	c.name = $1 DESC
+LIMIT 1;
+	`
+
+	err := querier.QueryRow(ctx, q, name, userUUID, CodeSpaceAccessLevelReadOnly).Scan(
+		&codeSpace.ID,
+		&codeSpace.AuthorUUID,
+		&codeSpace.Name,
+		&codeSpace.Language,
+		&codeSpace.LanguageVersion,
+		&codeSpace.Contents,
+		&codeSpace.ForkedFromID,
+		&codeSpace.Version,
+		&codeSpace.DeletedAt,
+		&codeSpace.CreatedAt,
+		&codeSpace.UpdatedAt,
+		&codeSpaceAccess.ID,
+		&codeSpaceAccess.UserUUID,
+		&codeSpaceAccess.CodeSpaceID,
+		&codeSpaceAccess.Level,
+		&codeSpaceAccess.CreatedAt,
+		&codeSpaceAccess.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpace, codeSpaceAccess, nil
+}
+
+// RestoreCodeSpace moves a code space out of the trash.
+func (repo *repository) RestoreCodeSpace(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) (*CodeSpace, error) {
+	restoredCodeSpace := &CodeSpace{}
+
+	q := `
+UPDATE
+	code_space
+SET
+	deleted_at = NULL,
+	updated_at = $1
+WHERE
+	id = $2
+	AND deleted_at IS NOT NULL
+RETURNING
+	id,
+	author_uuid,
+	name,
+	language,
+	language_version,
+	contents,
+	forked_from_id,
+	version,
+	deleted_at,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(ctx, q, repo.timeProvider.Now(), codeSpaceID).Scan(
+		&restoredCodeSpace.ID,
+		&restoredCodeSpace.AuthorUUID,
+		&restoredCodeSpace.Name,
+		&restoredCodeSpace.Language,
+		&restoredCodeSpace.LanguageVersion,
+		&restoredCodeSpace.Contents,
+		&restoredCodeSpace.ForkedFromID,
+		&restoredCodeSpace.Version,
+		&restoredCodeSpace.DeletedAt,
+		&restoredCodeSpace.CreatedAt,
+		&restoredCodeSpace.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return restoredCodeSpace, nil
+}
+
+// DeleteTrashedCodeSpaces deletes code spaces that were moved to the trash before a given time,
+// and returns the number of code spaces deleted.
+func (repo *repository) DeleteTrashedCodeSpaces(
+	ctx context.Context,
+	querier database.Querier,
+	deletedBefore time.Time,
+) (int64, error) {
+	q := `
+DELETE FROM
+	code_space c
+WHERE
+	c.deleted_at IS NOT NULL
+	AND c.deleted_at < $1;
+	`
+
+	ct, err := querier.Exec(ctx, q, deletedBefore)
+	if err != nil {
+		return 0, errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+// CreateOrUpdateCodeSpaceAccess creates a new code space access or updates the existing one.
+func (repo *repository) CreateOrUpdateCodeSpaceAccess(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceAccess *CodeSpaceAccess,
+) (*CodeSpaceAccess, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceAccess := &CodeSpaceAccess{}
+
+	q := `
+INSERT INTO code_space_access (
+	user_uuid,
+	code_space_id,
+	level,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+ON CONFLICT (user_uuid, code_space_id)
+DO UPDATE SET
+	level = EXCLUDED.level,
+	updated_at = EXCLUDED.updated_at
+RETURNING
+	id,
+	user_uuid,
+	code_space_id,
+	level,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceAccess.UserUUID,
+		codeSpaceAccess.CodeSpaceID,
+		codeSpaceAccess.Level,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceAccess.ID,
+		&createdCodeSpaceAccess.UserUUID,
+		&createdCodeSpaceAccess.CodeSpaceID,
+		&createdCodeSpaceAccess.Level,
+		&createdCodeSpaceAccess.CreatedAt,
+		&createdCodeSpaceAccess.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceAccess, nil
+}
+
+// ListUsersWithCodeSpaceAccess lists all users with access to a given code space.
+func (repo *repository) ListUsersWithCodeSpaceAccess(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) ([]*auth.User, []*CodeSpaceAccess, error) {
+	users := make([]*auth.User, 0)
+	codeSpacesAccesses := make([]*CodeSpaceAccess, 0)
+
+	q := `
+SELECT
+	u.uuid,
+	u.email,
+	u.password,
+	u.first_name,
+	u.last_name,
+	u.is_active,
+	u.is_superuser,
+	u.created_at,
+	u.updated_at,
+	a.id,
+	a.user_uuid,
+	a.code_space_id,
+	a.level,
+	a.created_at,
+	a.updated_at
+FROM
+	"user" u
 INNER JOIN
 	code_space_access a
 ON
@@ -568,3 +1219,2049 @@ WHERE
 
 	return nil
 }
+
+// CreateCodeSpaceRun creates a new code space run.
+func (repo *repository) CreateCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRun *CodeSpaceRun,
+) (*CodeSpaceRun, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceRun := &CodeSpaceRun{}
+
+	q := `
+INSERT INTO code_space_run (
+	code_space_id,
+	user_uuid,
+	contents,
+	language,
+	version,
+	status,
+	started_at,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9
+)
+RETURNING
+	id,
+	code_space_id,
+	user_uuid,
+	contents,
+	language,
+	version,
+	status,
+	compile_stdout,
+	compile_stderr,
+	compile_code,
+	compile_signal,
+	compile_message,
+	compile_status,
+	compile_cpu_time,
+	compile_wall_time,
+	compile_memory,
+	compile_timed_out,
+	compile_memory_limit_exceeded,
+	run_stdout,
+	run_stderr,
+	run_code,
+	run_signal,
+	run_message,
+	run_status,
+	run_cpu_time,
+	run_wall_time,
+	run_memory,
+	run_timed_out,
+	run_memory_limit_exceeded,
+	started_at,
+	finished_at,
+	from_cache,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceRun.CodeSpaceID,
+		codeSpaceRun.UserUUID,
+		codeSpaceRun.Contents,
+		codeSpaceRun.Language,
+		codeSpaceRun.Version,
+		codeSpaceRun.Status,
+		codeSpaceRun.StartedAt,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceRun.ID,
+		&createdCodeSpaceRun.CodeSpaceID,
+		&createdCodeSpaceRun.UserUUID,
+		&createdCodeSpaceRun.Contents,
+		&createdCodeSpaceRun.Language,
+		&createdCodeSpaceRun.Version,
+		&createdCodeSpaceRun.Status,
+		&createdCodeSpaceRun.CompileStdout,
+		&createdCodeSpaceRun.CompileStderr,
+		&createdCodeSpaceRun.CompileCode,
+		&createdCodeSpaceRun.CompileSignal,
+		&createdCodeSpaceRun.CompileMessage,
+		&createdCodeSpaceRun.CompileStatus,
+		&createdCodeSpaceRun.CompileCPUTime,
+		&createdCodeSpaceRun.CompileWallTime,
+		&createdCodeSpaceRun.CompileMemory,
+		&createdCodeSpaceRun.CompileTimedOut,
+		&createdCodeSpaceRun.CompileMemoryLimitExceeded,
+		&createdCodeSpaceRun.RunStdout,
+		&createdCodeSpaceRun.RunStderr,
+		&createdCodeSpaceRun.RunCode,
+		&createdCodeSpaceRun.RunSignal,
+		&createdCodeSpaceRun.RunMessage,
+		&createdCodeSpaceRun.RunStatus,
+		&createdCodeSpaceRun.RunCPUTime,
+		&createdCodeSpaceRun.RunWallTime,
+		&createdCodeSpaceRun.RunMemory,
+		&createdCodeSpaceRun.RunTimedOut,
+		&createdCodeSpaceRun.RunMemoryLimitExceeded,
+		&createdCodeSpaceRun.StartedAt,
+		&createdCodeSpaceRun.FinishedAt,
+		&createdCodeSpaceRun.FromCache,
+		&createdCodeSpaceRun.CreatedAt,
+		&createdCodeSpaceRun.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceRun, nil
+}
+
+// ListCodeSpaceRuns lists runs of a given code space, most recent first.
+func (repo *repository) ListCodeSpaceRuns(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	limit int64,
+	offset int64,
+) ([]*CodeSpaceRun, error) {
+	codeSpaceRuns := make([]*CodeSpaceRun, 0)
+
+	q := `
+SELECT
+	r.id,
+	r.code_space_id,
+	r.user_uuid,
+	r.contents,
+	r.language,
+	r.version,
+	r.status,
+	r.compile_stdout,
+	r.compile_stderr,
+	r.compile_code,
+	r.compile_signal,
+	r.compile_message,
+	r.compile_status,
+	r.compile_cpu_time,
+	r.compile_wall_time,
+	r.compile_memory,
+	r.compile_timed_out,
+	r.compile_memory_limit_exceeded,
+	r.run_stdout,
+	r.run_stderr,
+	r.run_code,
+	r.run_signal,
+	r.run_message,
+	r.run_status,
+	r.run_cpu_time,
+	r.run_wall_time,
+	r.run_memory,
+	r.run_timed_out,
+	r.run_memory_limit_exceeded,
+	r.started_at,
+	r.finished_at,
+	r.from_cache,
+	r.created_at,
+	r.updated_at
+FROM
+	code_space_run r
+WHERE
+	r.code_space_id = $1
+ORDER BY
+	r.created_at DESC,
+	r.id DESC
+LIMIT
+	$2
+OFFSET
+	$3;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID, limit, offset)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceRun := &CodeSpaceRun{}
+
+		err := rows.Scan(
+			&codeSpaceRun.ID,
+			&codeSpaceRun.CodeSpaceID,
+			&codeSpaceRun.UserUUID,
+			&codeSpaceRun.Contents,
+			&codeSpaceRun.Language,
+			&codeSpaceRun.Version,
+			&codeSpaceRun.Status,
+			&codeSpaceRun.CompileStdout,
+			&codeSpaceRun.CompileStderr,
+			&codeSpaceRun.CompileCode,
+			&codeSpaceRun.CompileSignal,
+			&codeSpaceRun.CompileMessage,
+			&codeSpaceRun.CompileStatus,
+			&codeSpaceRun.CompileCPUTime,
+			&codeSpaceRun.CompileWallTime,
+			&codeSpaceRun.CompileMemory,
+			&codeSpaceRun.CompileTimedOut,
+			&codeSpaceRun.CompileMemoryLimitExceeded,
+			&codeSpaceRun.RunStdout,
+			&codeSpaceRun.RunStderr,
+			&codeSpaceRun.RunCode,
+			&codeSpaceRun.RunSignal,
+			&codeSpaceRun.RunMessage,
+			&codeSpaceRun.RunStatus,
+			&codeSpaceRun.RunCPUTime,
+			&codeSpaceRun.RunWallTime,
+			&codeSpaceRun.RunMemory,
+			&codeSpaceRun.RunTimedOut,
+			&codeSpaceRun.RunMemoryLimitExceeded,
+			&codeSpaceRun.StartedAt,
+			&codeSpaceRun.FinishedAt,
+			&codeSpaceRun.FromCache,
+			&codeSpaceRun.CreatedAt,
+			&codeSpaceRun.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceRuns = append(codeSpaceRuns, codeSpaceRun)
+	}
+
+	return codeSpaceRuns, nil
+}
+
+// GetCodeSpaceRun gets a given run of a given code space.
+func (repo *repository) GetCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceRunID int64,
+) (*CodeSpaceRun, error) {
+	codeSpaceRun := &CodeSpaceRun{}
+
+	q := `
+SELECT
+	r.id,
+	r.code_space_id,
+	r.user_uuid,
+	r.contents,
+	r.language,
+	r.version,
+	r.status,
+	r.compile_stdout,
+	r.compile_stderr,
+	r.compile_code,
+	r.compile_signal,
+	r.compile_message,
+	r.compile_status,
+	r.compile_cpu_time,
+	r.compile_wall_time,
+	r.compile_memory,
+	r.compile_timed_out,
+	r.compile_memory_limit_exceeded,
+	r.run_stdout,
+	r.run_stderr,
+	r.run_code,
+	r.run_signal,
+	r.run_message,
+	r.run_status,
+	r.run_cpu_time,
+	r.run_wall_time,
+	r.run_memory,
+	r.run_timed_out,
+	r.run_memory_limit_exceeded,
+	r.started_at,
+	r.finished_at,
+	r.from_cache,
+	r.created_at,
+	r.updated_at
+FROM
+	code_space_run r
+WHERE
+	r.code_space_id = $1
+	AND r.id = $2;
+	`
+
+	err := querier.QueryRow(ctx, q, codeSpaceID, codeSpaceRunID).Scan(
+		&codeSpaceRun.ID,
+		&codeSpaceRun.CodeSpaceID,
+		&codeSpaceRun.UserUUID,
+		&codeSpaceRun.Contents,
+		&codeSpaceRun.Language,
+		&codeSpaceRun.Version,
+		&codeSpaceRun.Status,
+		&codeSpaceRun.CompileStdout,
+		&codeSpaceRun.CompileStderr,
+		&codeSpaceRun.CompileCode,
+		&codeSpaceRun.CompileSignal,
+		&codeSpaceRun.CompileMessage,
+		&codeSpaceRun.CompileStatus,
+		&codeSpaceRun.CompileCPUTime,
+		&codeSpaceRun.CompileWallTime,
+		&codeSpaceRun.CompileMemory,
+		&codeSpaceRun.CompileTimedOut,
+		&codeSpaceRun.CompileMemoryLimitExceeded,
+		&codeSpaceRun.RunStdout,
+		&codeSpaceRun.RunStderr,
+		&codeSpaceRun.RunCode,
+		&codeSpaceRun.RunSignal,
+		&codeSpaceRun.RunMessage,
+		&codeSpaceRun.RunStatus,
+		&codeSpaceRun.RunCPUTime,
+		&codeSpaceRun.RunWallTime,
+		&codeSpaceRun.RunMemory,
+		&codeSpaceRun.RunTimedOut,
+		&codeSpaceRun.RunMemoryLimitExceeded,
+		&codeSpaceRun.StartedAt,
+		&codeSpaceRun.FinishedAt,
+		&codeSpaceRun.FromCache,
+		&codeSpaceRun.CreatedAt,
+		&codeSpaceRun.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpaceRun, nil
+}
+
+// UpdateCodeSpaceRun updates the status, results, and timestamps of a code space run.
+func (repo *repository) UpdateCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRun *CodeSpaceRun,
+) (*CodeSpaceRun, error) {
+	updatedCodeSpaceRun := &CodeSpaceRun{}
+
+	q := `
+UPDATE
+	code_space_run
+SET
+	status = $1,
+	compile_stdout = $2,
+	compile_stderr = $3,
+	compile_code = $4,
+	compile_signal = $5,
+	compile_message = $6,
+	compile_status = $7,
+	compile_cpu_time = $8,
+	compile_wall_time = $9,
+	compile_memory = $10,
+	compile_timed_out = $11,
+	compile_memory_limit_exceeded = $12,
+	run_stdout = $13,
+	run_stderr = $14,
+	run_code = $15,
+	run_signal = $16,
+	run_message = $17,
+	run_status = $18,
+	run_cpu_time = $19,
+	run_wall_time = $20,
+	run_memory = $21,
+	run_timed_out = $22,
+	run_memory_limit_exceeded = $23,
+	started_at = $24,
+	finished_at = $25,
+	from_cache = $26,
+	updated_at = $27
+WHERE
+	id = $28
+RETURNING
+	id,
+	code_space_id,
+	user_uuid,
+	contents,
+	language,
+	version,
+	status,
+	compile_stdout,
+	compile_stderr,
+	compile_code,
+	compile_signal,
+	compile_message,
+	compile_status,
+	compile_cpu_time,
+	compile_wall_time,
+	compile_memory,
+	compile_timed_out,
+	compile_memory_limit_exceeded,
+	run_stdout,
+	run_stderr,
+	run_code,
+	run_signal,
+	run_message,
+	run_status,
+	run_cpu_time,
+	run_wall_time,
+	run_memory,
+	run_timed_out,
+	run_memory_limit_exceeded,
+	started_at,
+	finished_at,
+	from_cache,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceRun.Status,
+		codeSpaceRun.CompileStdout,
+		codeSpaceRun.CompileStderr,
+		codeSpaceRun.CompileCode,
+		codeSpaceRun.CompileSignal,
+		codeSpaceRun.CompileMessage,
+		codeSpaceRun.CompileStatus,
+		codeSpaceRun.CompileCPUTime,
+		codeSpaceRun.CompileWallTime,
+		codeSpaceRun.CompileMemory,
+		codeSpaceRun.CompileTimedOut,
+		codeSpaceRun.CompileMemoryLimitExceeded,
+		codeSpaceRun.RunStdout,
+		codeSpaceRun.RunStderr,
+		codeSpaceRun.RunCode,
+		codeSpaceRun.RunSignal,
+		codeSpaceRun.RunMessage,
+		codeSpaceRun.RunStatus,
+		codeSpaceRun.RunCPUTime,
+		codeSpaceRun.RunWallTime,
+		codeSpaceRun.RunMemory,
+		codeSpaceRun.RunTimedOut,
+		codeSpaceRun.RunMemoryLimitExceeded,
+		codeSpaceRun.StartedAt,
+		codeSpaceRun.FinishedAt,
+		codeSpaceRun.FromCache,
+		repo.timeProvider.Now(),
+		codeSpaceRun.ID,
+	).Scan(
+		&updatedCodeSpaceRun.ID,
+		&updatedCodeSpaceRun.CodeSpaceID,
+		&updatedCodeSpaceRun.UserUUID,
+		&updatedCodeSpaceRun.Contents,
+		&updatedCodeSpaceRun.Language,
+		&updatedCodeSpaceRun.Version,
+		&updatedCodeSpaceRun.Status,
+		&updatedCodeSpaceRun.CompileStdout,
+		&updatedCodeSpaceRun.CompileStderr,
+		&updatedCodeSpaceRun.CompileCode,
+		&updatedCodeSpaceRun.CompileSignal,
+		&updatedCodeSpaceRun.CompileMessage,
+		&updatedCodeSpaceRun.CompileStatus,
+		&updatedCodeSpaceRun.CompileCPUTime,
+		&updatedCodeSpaceRun.CompileWallTime,
+		&updatedCodeSpaceRun.CompileMemory,
+		&updatedCodeSpaceRun.CompileTimedOut,
+		&updatedCodeSpaceRun.CompileMemoryLimitExceeded,
+		&updatedCodeSpaceRun.RunStdout,
+		&updatedCodeSpaceRun.RunStderr,
+		&updatedCodeSpaceRun.RunCode,
+		&updatedCodeSpaceRun.RunSignal,
+		&updatedCodeSpaceRun.RunMessage,
+		&updatedCodeSpaceRun.RunStatus,
+		&updatedCodeSpaceRun.RunCPUTime,
+		&updatedCodeSpaceRun.RunWallTime,
+		&updatedCodeSpaceRun.RunMemory,
+		&updatedCodeSpaceRun.RunTimedOut,
+		&updatedCodeSpaceRun.RunMemoryLimitExceeded,
+		&updatedCodeSpaceRun.StartedAt,
+		&updatedCodeSpaceRun.FinishedAt,
+		&updatedCodeSpaceRun.FromCache,
+		&updatedCodeSpaceRun.CreatedAt,
+		&updatedCodeSpaceRun.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return updatedCodeSpaceRun, nil
+}
+
+// CreateCodeSpaceFile creates a new file in a code space.
+// If a file with the same name already exists in the code space, error is returned.
+func (repo *repository) CreateCodeSpaceFile(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceFile *CodeSpaceFile,
+) (*CodeSpaceFile, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceFile := &CodeSpaceFile{}
+
+	q := `
+INSERT INTO code_space_file (
+	code_space_id,
+	name,
+	contents,
+	is_entry_point,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+)
+RETURNING
+	id,
+	code_space_id,
+	name,
+	contents,
+	is_entry_point,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceFile.CodeSpaceID,
+		codeSpaceFile.Name,
+		codeSpaceFile.Contents,
+		codeSpaceFile.IsEntryPoint,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceFile.ID,
+		&createdCodeSpaceFile.CodeSpaceID,
+		&createdCodeSpaceFile.Name,
+		&createdCodeSpaceFile.Contents,
+		&createdCodeSpaceFile.IsEntryPoint,
+		&createdCodeSpaceFile.CreatedAt,
+		&createdCodeSpaceFile.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceFile, nil
+}
+
+// ListCodeSpaceFiles lists files of a given code space, ordered by name.
+func (repo *repository) ListCodeSpaceFiles(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) ([]*CodeSpaceFile, error) {
+	codeSpaceFiles := make([]*CodeSpaceFile, 0)
+
+	q := `
+SELECT
+	f.id,
+	f.code_space_id,
+	f.name,
+	f.contents,
+	f.is_entry_point,
+	f.created_at,
+	f.updated_at
+FROM
+	code_space_file f
+WHERE
+	f.code_space_id = $1
+ORDER BY
+	f.name ASC;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceFile := &CodeSpaceFile{}
+
+		err := rows.Scan(
+			&codeSpaceFile.ID,
+			&codeSpaceFile.CodeSpaceID,
+			&codeSpaceFile.Name,
+			&codeSpaceFile.Contents,
+			&codeSpaceFile.IsEntryPoint,
+			&codeSpaceFile.CreatedAt,
+			&codeSpaceFile.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceFiles = append(codeSpaceFiles, codeSpaceFile)
+	}
+
+	return codeSpaceFiles, nil
+}
+
+// UpdateCodeSpaceFile updates the name, contents, and entry point flag of a file in a code space.
+// If no file is affected, error is returned.
+// If another file with the same name already exists in the code space, error is returned.
+func (repo *repository) UpdateCodeSpaceFile(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceFileID int64,
+	name *string,
+	contents *string,
+	isEntryPoint *bool,
+) (*CodeSpaceFile, error) {
+	if name == nil && contents == nil && isEntryPoint == nil {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "all attributes are nil")
+	}
+
+	updatedCodeSpaceFile := &CodeSpaceFile{}
+
+	q := `
+UPDATE
+	code_space_file
+SET
+	name = COALESCE($1, name),
+	contents = COALESCE($2, contents),
+	is_entry_point = COALESCE($3, is_entry_point),
+	updated_at = $4
+WHERE
+	code_space_id = $5
+	AND id = $6
+RETURNING
+	id,
+	code_space_id,
+	name,
+	contents,
+	is_entry_point,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		name,
+		contents,
+		isEntryPoint,
+		repo.timeProvider.Now(),
+		codeSpaceID,
+		codeSpaceFileID,
+	).Scan(
+		&updatedCodeSpaceFile.ID,
+		&updatedCodeSpaceFile.CodeSpaceID,
+		&updatedCodeSpaceFile.Name,
+		&updatedCodeSpaceFile.Contents,
+		&updatedCodeSpaceFile.IsEntryPoint,
+		&updatedCodeSpaceFile.CreatedAt,
+		&updatedCodeSpaceFile.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return updatedCodeSpaceFile, nil
+}
+
+// ClearCodeSpaceEntryPoint unmarks the entry point file of a given code space, if there is one.
+func (repo *repository) ClearCodeSpaceEntryPoint(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) error {
+	q := `
+UPDATE
+	code_space_file
+SET
+	is_entry_point = FALSE,
+	updated_at = $1
+WHERE
+	code_space_id = $2
+	AND is_entry_point = TRUE;
+	`
+
+	_, err := querier.Exec(ctx, q, repo.timeProvider.Now(), codeSpaceID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	return nil
+}
+
+// DeleteCodeSpaceFile deletes a file in a code space.
+// If no file is found, error is returned.
+func (repo *repository) DeleteCodeSpaceFile(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceFileID int64,
+) error {
+	q := `
+DELETE FROM
+	code_space_file f
+WHERE
+	f.code_space_id = $1
+	AND f.id = $2;
+	`
+
+	ct, err := querier.Exec(ctx, q, codeSpaceID, codeSpaceFileID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// CreateCodeSpaceTestCase creates a new test case in a code space.
+func (repo *repository) CreateCodeSpaceTestCase(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceTestCase *CodeSpaceTestCase,
+) (*CodeSpaceTestCase, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceTestCase := &CodeSpaceTestCase{}
+
+	q := `
+INSERT INTO code_space_test_case (
+	code_space_id,
+	stdin,
+	args,
+	expected_stdout,
+	comparison_mode,
+	is_hidden,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8
+)
+RETURNING
+	id,
+	code_space_id,
+	stdin,
+	args,
+	expected_stdout,
+	comparison_mode,
+	is_hidden,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceTestCase.CodeSpaceID,
+		codeSpaceTestCase.Stdin,
+		codeSpaceTestCase.Args,
+		codeSpaceTestCase.ExpectedStdout,
+		codeSpaceTestCase.ComparisonMode,
+		codeSpaceTestCase.IsHidden,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceTestCase.ID,
+		&createdCodeSpaceTestCase.CodeSpaceID,
+		&createdCodeSpaceTestCase.Stdin,
+		&createdCodeSpaceTestCase.Args,
+		&createdCodeSpaceTestCase.ExpectedStdout,
+		&createdCodeSpaceTestCase.ComparisonMode,
+		&createdCodeSpaceTestCase.IsHidden,
+		&createdCodeSpaceTestCase.CreatedAt,
+		&createdCodeSpaceTestCase.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceTestCase, nil
+}
+
+// ListCodeSpaceTestCases lists test cases of a given code space, ordered by creation.
+// Hidden test cases are only listed if includeHidden is true.
+func (repo *repository) ListCodeSpaceTestCases(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	includeHidden bool,
+) ([]*CodeSpaceTestCase, error) {
+	codeSpaceTestCases := make([]*CodeSpaceTestCase, 0)
+
+	q := `
+SELECT
+	t.id,
+	t.code_space_id,
+	t.stdin,
+	t.args,
+	t.expected_stdout,
+	t.comparison_mode,
+	t.is_hidden,
+	t.created_at,
+	t.updated_at
+FROM
+	code_space_test_case t
+WHERE
+	t.code_space_id = $1
+	AND ($2 OR NOT t.is_hidden)
+ORDER BY
+	t.id ASC;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID, includeHidden)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceTestCase := &CodeSpaceTestCase{}
+
+		err := rows.Scan(
+			&codeSpaceTestCase.ID,
+			&codeSpaceTestCase.CodeSpaceID,
+			&codeSpaceTestCase.Stdin,
+			&codeSpaceTestCase.Args,
+			&codeSpaceTestCase.ExpectedStdout,
+			&codeSpaceTestCase.ComparisonMode,
+			&codeSpaceTestCase.IsHidden,
+			&codeSpaceTestCase.CreatedAt,
+			&codeSpaceTestCase.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceTestCases = append(codeSpaceTestCases, codeSpaceTestCase)
+	}
+
+	return codeSpaceTestCases, nil
+}
+
+// UpdateCodeSpaceTestCase updates the inputs, expected output, comparison mode, and visibility of a test case.
+// If no test case is affected, error is returned.
+func (repo *repository) UpdateCodeSpaceTestCase(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceTestCaseID int64,
+	stdin *string,
+	args []string,
+	expectedStdout *string,
+	comparisonMode *string,
+	isHidden *bool,
+) (*CodeSpaceTestCase, error) {
+	if stdin == nil && args == nil && expectedStdout == nil && comparisonMode == nil && isHidden == nil {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "all attributes are nil")
+	}
+
+	updatedCodeSpaceTestCase := &CodeSpaceTestCase{}
+
+	q := `
+UPDATE
+	code_space_test_case
+SET
+	stdin = COALESCE($1, stdin),
+	args = COALESCE($2, args),
+	expected_stdout = COALESCE($3, expected_stdout),
+	comparison_mode = COALESCE($4, comparison_mode),
+	is_hidden = COALESCE($5, is_hidden),
+	updated_at = $6
+WHERE
+	code_space_id = $7
+	AND id = $8
+RETURNING
+	id,
+	code_space_id,
+	stdin,
+	args,
+	expected_stdout,
+	comparison_mode,
+	is_hidden,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		stdin,
+		args,
+		expectedStdout,
+		comparisonMode,
+		isHidden,
+		repo.timeProvider.Now(),
+		codeSpaceID,
+		codeSpaceTestCaseID,
+	).Scan(
+		&updatedCodeSpaceTestCase.ID,
+		&updatedCodeSpaceTestCase.CodeSpaceID,
+		&updatedCodeSpaceTestCase.Stdin,
+		&updatedCodeSpaceTestCase.Args,
+		&updatedCodeSpaceTestCase.ExpectedStdout,
+		&updatedCodeSpaceTestCase.ComparisonMode,
+		&updatedCodeSpaceTestCase.IsHidden,
+		&updatedCodeSpaceTestCase.CreatedAt,
+		&updatedCodeSpaceTestCase.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return updatedCodeSpaceTestCase, nil
+}
+
+// DeleteCodeSpaceTestCase deletes a test case in a code space.
+// If no test case is found, error is returned.
+func (repo *repository) DeleteCodeSpaceTestCase(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceTestCaseID int64,
+) error {
+	q := `
+DELETE FROM
+	code_space_test_case t
+WHERE
+	t.code_space_id = $1
+	AND t.id = $2;
+	`
+
+	ct, err := querier.Exec(ctx, q, codeSpaceID, codeSpaceTestCaseID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// CreateCodeSpaceRunConfig creates a new run configuration in a code space.
+func (repo *repository) CreateCodeSpaceRunConfig(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRunConfig *CodeSpaceRunConfig,
+) (*CodeSpaceRunConfig, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceRunConfig := &CodeSpaceRunConfig{}
+
+	q := `
+INSERT INTO code_space_run_config (
+	code_space_id,
+	name,
+	stdin,
+	args,
+	compile_timeout,
+	run_timeout,
+	compile_memory_limit,
+	run_memory_limit,
+	language_version,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9,
+	$10,
+	$11
+)
+RETURNING
+	id,
+	code_space_id,
+	name,
+	stdin,
+	args,
+	compile_timeout,
+	run_timeout,
+	compile_memory_limit,
+	run_memory_limit,
+	language_version,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceRunConfig.CodeSpaceID,
+		codeSpaceRunConfig.Name,
+		codeSpaceRunConfig.Stdin,
+		codeSpaceRunConfig.Args,
+		codeSpaceRunConfig.CompileTimeout,
+		codeSpaceRunConfig.RunTimeout,
+		codeSpaceRunConfig.CompileMemoryLimit,
+		codeSpaceRunConfig.RunMemoryLimit,
+		codeSpaceRunConfig.LanguageVersion,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceRunConfig.ID,
+		&createdCodeSpaceRunConfig.CodeSpaceID,
+		&createdCodeSpaceRunConfig.Name,
+		&createdCodeSpaceRunConfig.Stdin,
+		&createdCodeSpaceRunConfig.Args,
+		&createdCodeSpaceRunConfig.CompileTimeout,
+		&createdCodeSpaceRunConfig.RunTimeout,
+		&createdCodeSpaceRunConfig.CompileMemoryLimit,
+		&createdCodeSpaceRunConfig.RunMemoryLimit,
+		&createdCodeSpaceRunConfig.LanguageVersion,
+		&createdCodeSpaceRunConfig.CreatedAt,
+		&createdCodeSpaceRunConfig.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceRunConfig, nil
+}
+
+// ListCodeSpaceRunConfigs lists run configurations of a given code space, ordered by name.
+func (repo *repository) ListCodeSpaceRunConfigs(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) ([]*CodeSpaceRunConfig, error) {
+	codeSpaceRunConfigs := make([]*CodeSpaceRunConfig, 0)
+
+	q := `
+SELECT
+	c.id,
+	c.code_space_id,
+	c.name,
+	c.stdin,
+	c.args,
+	c.compile_timeout,
+	c.run_timeout,
+	c.compile_memory_limit,
+	c.run_memory_limit,
+	c.language_version,
+	c.created_at,
+	c.updated_at
+FROM
+	code_space_run_config c
+WHERE
+	c.code_space_id = $1
+ORDER BY
+	c.name ASC;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceRunConfig := &CodeSpaceRunConfig{}
+
+		err := rows.Scan(
+			&codeSpaceRunConfig.ID,
+			&codeSpaceRunConfig.CodeSpaceID,
+			&codeSpaceRunConfig.Name,
+			&codeSpaceRunConfig.Stdin,
+			&codeSpaceRunConfig.Args,
+			&codeSpaceRunConfig.CompileTimeout,
+			&codeSpaceRunConfig.RunTimeout,
+			&codeSpaceRunConfig.CompileMemoryLimit,
+			&codeSpaceRunConfig.RunMemoryLimit,
+			&codeSpaceRunConfig.LanguageVersion,
+			&codeSpaceRunConfig.CreatedAt,
+			&codeSpaceRunConfig.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceRunConfigs = append(codeSpaceRunConfigs, codeSpaceRunConfig)
+	}
+
+	return codeSpaceRunConfigs, nil
+}
+
+// GetCodeSpaceRunConfigByName gets a run configuration of a given code space by its name.
+func (repo *repository) GetCodeSpaceRunConfigByName(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	name string,
+) (*CodeSpaceRunConfig, error) {
+	codeSpaceRunConfig := &CodeSpaceRunConfig{}
+
+	q := `
+SELECT
+	c.id,
+	c.code_space_id,
+	c.name,
+	c.stdin,
+	c.args,
+	c.compile_timeout,
+	c.run_timeout,
+	c.compile_memory_limit,
+	c.run_memory_limit,
+	c.language_version,
+	c.created_at,
+	c.updated_at
+FROM
+	code_space_run_config c
+WHERE
+	c.code_space_id = $1
+	AND c.name = $2;
+	`
+
+	err := querier.QueryRow(ctx, q, codeSpaceID, name).Scan(
+		&codeSpaceRunConfig.ID,
+		&codeSpaceRunConfig.CodeSpaceID,
+		&codeSpaceRunConfig.Name,
+		&codeSpaceRunConfig.Stdin,
+		&codeSpaceRunConfig.Args,
+		&codeSpaceRunConfig.CompileTimeout,
+		&codeSpaceRunConfig.RunTimeout,
+		&codeSpaceRunConfig.CompileMemoryLimit,
+		&codeSpaceRunConfig.RunMemoryLimit,
+		&codeSpaceRunConfig.LanguageVersion,
+		&codeSpaceRunConfig.CreatedAt,
+		&codeSpaceRunConfig.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpaceRunConfig, nil
+}
+
+// UpdateCodeSpaceRunConfig updates the name, inputs, limits, and language version of a run configuration.
+// If no run configuration is affected, error is returned.
+func (repo *repository) UpdateCodeSpaceRunConfig(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceRunConfigID int64,
+	name *string,
+	stdin *string,
+	args []string,
+	compileTimeout *int64,
+	runTimeout *int64,
+	compileMemoryLimit *int64,
+	runMemoryLimit *int64,
+	languageVersion *string,
+) (*CodeSpaceRunConfig, error) {
+	if name == nil &&
+		stdin == nil &&
+		args == nil &&
+		compileTimeout == nil &&
+		runTimeout == nil &&
+		compileMemoryLimit == nil &&
+		runMemoryLimit == nil &&
+		languageVersion == nil {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "all attributes are nil")
+	}
+
+	updatedCodeSpaceRunConfig := &CodeSpaceRunConfig{}
+
+	q := `
+UPDATE
+	code_space_run_config
+SET
+	name = COALESCE($1, name),
+	stdin = COALESCE($2, stdin),
+	args = COALESCE($3, args),
+	compile_timeout = COALESCE($4, compile_timeout),
+	run_timeout = COALESCE($5, run_timeout),
+	compile_memory_limit = COALESCE($6, compile_memory_limit),
+	run_memory_limit = COALESCE($7, run_memory_limit),
+	language_version = COALESCE($8, language_version),
+	updated_at = $9
+WHERE
+	code_space_id = $10
+	AND id = $11
+RETURNING
+	id,
+	code_space_id,
+	name,
+	stdin,
+	args,
+	compile_timeout,
+	run_timeout,
+	compile_memory_limit,
+	run_memory_limit,
+	language_version,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		name,
+		stdin,
+		args,
+		compileTimeout,
+		runTimeout,
+		compileMemoryLimit,
+		runMemoryLimit,
+		languageVersion,
+		repo.timeProvider.Now(),
+		codeSpaceID,
+		codeSpaceRunConfigID,
+	).Scan(
+		&updatedCodeSpaceRunConfig.ID,
+		&updatedCodeSpaceRunConfig.CodeSpaceID,
+		&updatedCodeSpaceRunConfig.Name,
+		&updatedCodeSpaceRunConfig.Stdin,
+		&updatedCodeSpaceRunConfig.Args,
+		&updatedCodeSpaceRunConfig.CompileTimeout,
+		&updatedCodeSpaceRunConfig.RunTimeout,
+		&updatedCodeSpaceRunConfig.CompileMemoryLimit,
+		&updatedCodeSpaceRunConfig.RunMemoryLimit,
+		&updatedCodeSpaceRunConfig.LanguageVersion,
+		&updatedCodeSpaceRunConfig.CreatedAt,
+		&updatedCodeSpaceRunConfig.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return updatedCodeSpaceRunConfig, nil
+}
+
+// DeleteCodeSpaceRunConfig deletes a run configuration in a code space.
+// If no run configuration is found, error is returned.
+func (repo *repository) DeleteCodeSpaceRunConfig(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceRunConfigID int64,
+) error {
+	q := `
+DELETE FROM
+	code_space_run_config c
+WHERE
+	c.code_space_id = $1
+	AND c.id = $2;
+	`
+
+	ct, err := querier.Exec(ctx, q, codeSpaceID, codeSpaceRunConfigID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// CreateCodeSpaceEnvVar creates a new environment variable in a code space.
+func (repo *repository) CreateCodeSpaceEnvVar(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceEnvVar *CodeSpaceEnvVar,
+) (*CodeSpaceEnvVar, error) {
+	now := repo.timeProvider.Now()
+	createdCodeSpaceEnvVar := &CodeSpaceEnvVar{}
+
+	q := `
+INSERT INTO code_space_env_var (
+	code_space_id,
+	name,
+	value,
+	is_secret,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6
+)
+RETURNING
+	id,
+	code_space_id,
+	name,
+	value,
+	is_secret,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceEnvVar.CodeSpaceID,
+		codeSpaceEnvVar.Name,
+		codeSpaceEnvVar.Value,
+		codeSpaceEnvVar.IsSecret,
+		now,
+		now,
+	).Scan(
+		&createdCodeSpaceEnvVar.ID,
+		&createdCodeSpaceEnvVar.CodeSpaceID,
+		&createdCodeSpaceEnvVar.Name,
+		&createdCodeSpaceEnvVar.Value,
+		&createdCodeSpaceEnvVar.IsSecret,
+		&createdCodeSpaceEnvVar.CreatedAt,
+		&createdCodeSpaceEnvVar.UpdatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceEnvVar, nil
+}
+
+// ListCodeSpaceEnvVars lists environment variables of a given code space, ordered by name.
+func (repo *repository) ListCodeSpaceEnvVars(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+) ([]*CodeSpaceEnvVar, error) {
+	codeSpaceEnvVars := make([]*CodeSpaceEnvVar, 0)
+
+	q := `
+SELECT
+	e.id,
+	e.code_space_id,
+	e.name,
+	e.value,
+	e.is_secret,
+	e.created_at,
+	e.updated_at
+FROM
+	code_space_env_var e
+WHERE
+	e.code_space_id = $1
+ORDER BY
+	e.name ASC;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceEnvVar := &CodeSpaceEnvVar{}
+
+		err := rows.Scan(
+			&codeSpaceEnvVar.ID,
+			&codeSpaceEnvVar.CodeSpaceID,
+			&codeSpaceEnvVar.Name,
+			&codeSpaceEnvVar.Value,
+			&codeSpaceEnvVar.IsSecret,
+			&codeSpaceEnvVar.CreatedAt,
+			&codeSpaceEnvVar.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceEnvVars = append(codeSpaceEnvVars, codeSpaceEnvVar)
+	}
+
+	return codeSpaceEnvVars, nil
+}
+
+// GetCodeSpaceEnvVar gets an environment variable of a given code space.
+func (repo *repository) GetCodeSpaceEnvVar(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceEnvVarID int64,
+) (*CodeSpaceEnvVar, error) {
+	codeSpaceEnvVar := &CodeSpaceEnvVar{}
+
+	q := `
+SELECT
+	e.id,
+	e.code_space_id,
+	e.name,
+	e.value,
+	e.is_secret,
+	e.created_at,
+	e.updated_at
+FROM
+	code_space_env_var e
+WHERE
+	e.code_space_id = $1
+	AND e.id = $2;
+	`
+
+	err := querier.QueryRow(ctx, q, codeSpaceID, codeSpaceEnvVarID).Scan(
+		&codeSpaceEnvVar.ID,
+		&codeSpaceEnvVar.CodeSpaceID,
+		&codeSpaceEnvVar.Name,
+		&codeSpaceEnvVar.Value,
+		&codeSpaceEnvVar.IsSecret,
+		&codeSpaceEnvVar.CreatedAt,
+		&codeSpaceEnvVar.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpaceEnvVar, nil
+}
+
+// UpdateCodeSpaceEnvVar updates the name, value, and secrecy of an environment variable.
+// If no environment variable is affected, error is returned.
+func (repo *repository) UpdateCodeSpaceEnvVar(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceEnvVarID int64,
+	name *string,
+	value *string,
+	isSecret *bool,
+) (*CodeSpaceEnvVar, error) {
+	if name == nil && value == nil && isSecret == nil {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "all attributes are nil")
+	}
+
+	updatedCodeSpaceEnvVar := &CodeSpaceEnvVar{}
+
+	q := `
+UPDATE
+	code_space_env_var
+SET
+	name = COALESCE($1, name),
+	value = COALESCE($2, value),
+	is_secret = COALESCE($3, is_secret),
+	updated_at = $4
+WHERE
+	code_space_id = $5
+	AND id = $6
+RETURNING
+	id,
+	code_space_id,
+	name,
+	value,
+	is_secret,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		name,
+		value,
+		isSecret,
+		repo.timeProvider.Now(),
+		codeSpaceID,
+		codeSpaceEnvVarID,
+	).Scan(
+		&updatedCodeSpaceEnvVar.ID,
+		&updatedCodeSpaceEnvVar.CodeSpaceID,
+		&updatedCodeSpaceEnvVar.Name,
+		&updatedCodeSpaceEnvVar.Value,
+		&updatedCodeSpaceEnvVar.IsSecret,
+		&updatedCodeSpaceEnvVar.CreatedAt,
+		&updatedCodeSpaceEnvVar.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsAffected, "querier.Scan failed")
+	}
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return updatedCodeSpaceEnvVar, nil
+}
+
+// DeleteCodeSpaceEnvVar deletes an environment variable in a code space.
+// If no environment variable is found, error is returned.
+func (repo *repository) DeleteCodeSpaceEnvVar(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceEnvVarID int64,
+) error {
+	q := `
+DELETE FROM
+	code_space_env_var e
+WHERE
+	e.code_space_id = $1
+	AND e.id = $2;
+	`
+
+	ct, err := querier.Exec(ctx, q, codeSpaceID, codeSpaceEnvVarID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// CreateCodeSpaceAlias creates a new alias for a code space.
+func (repo *repository) CreateCodeSpaceAlias(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceAlias *CodeSpaceAlias,
+) (*CodeSpaceAlias, error) {
+	createdCodeSpaceAlias := &CodeSpaceAlias{}
+
+	q := `
+INSERT INTO code_space_alias (
+	code_space_id,
+	name,
+	created_at
+)
+VALUES (
+	$1,
+	$2,
+	$3
+)
+RETURNING
+	id,
+	code_space_id,
+	name,
+	created_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceAlias.CodeSpaceID,
+		codeSpaceAlias.Name,
+		repo.timeProvider.Now(),
+	).Scan(
+		&createdCodeSpaceAlias.ID,
+		&createdCodeSpaceAlias.CodeSpaceID,
+		&createdCodeSpaceAlias.Name,
+		&createdCodeSpaceAlias.CreatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	ok := errors.As(err, &pgErr)
+
+	if ok && pgErr != nil && pgErr.Code == errutils.DatabaseErrCodeUniqueViolation {
+		return nil, errutils.FormatError(errutils.ErrDatabaseUniqueViolation, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceAlias, nil
+}
+
+// GetCodeSpaceAliasByName gets the code space alias with a given name.
+func (repo *repository) GetCodeSpaceAliasByName(
+	ctx context.Context,
+	querier database.Querier,
+	name string,
+) (*CodeSpaceAlias, error) {
+	codeSpaceAlias := &CodeSpaceAlias{}
+
+	q := `
+SELECT
+	s.id,
+	s.code_space_id,
+	s.name,
+	s.created_at
+FROM
+	code_space_alias s
+WHERE
+	s.name = $1;
+	`
+
+	err := querier.QueryRow(ctx, q, name).Scan(
+		&codeSpaceAlias.ID,
+		&codeSpaceAlias.CodeSpaceID,
+		&codeSpaceAlias.Name,
+		&codeSpaceAlias.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpaceAlias, nil
+}
+
+// DeleteCodeSpaceAlias deletes a code space alias.
+// If no code space alias is found, error is returned.
+func (repo *repository) DeleteCodeSpaceAlias(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceAliasID int64,
+) error {
+	q := `
+DELETE FROM
+	code_space_alias s
+WHERE
+	s.id = $1;
+	`
+
+	ct, err := querier.Exec(ctx, q, codeSpaceAliasID)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	if ct.RowsAffected() == 0 {
+		return errutils.FormatError(errutils.ErrDatabaseNoRowsAffected)
+	}
+
+	return nil
+}
+
+// CreateCodeSpaceRevision creates a new revision of a code space.
+func (repo *repository) CreateCodeSpaceRevision(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRevision *CodeSpaceRevision,
+) (*CodeSpaceRevision, error) {
+	createdCodeSpaceRevision := &CodeSpaceRevision{}
+
+	q := `
+INSERT INTO code_space_revision (
+	code_space_id,
+	author_uuid,
+	contents,
+	contents_hash,
+	created_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+RETURNING
+	id,
+	code_space_id,
+	author_uuid,
+	contents,
+	contents_hash,
+	created_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		codeSpaceRevision.CodeSpaceID,
+		codeSpaceRevision.AuthorUUID,
+		codeSpaceRevision.Contents,
+		codeSpaceRevision.ContentsHash,
+		repo.timeProvider.Now(),
+	).Scan(
+		&createdCodeSpaceRevision.ID,
+		&createdCodeSpaceRevision.CodeSpaceID,
+		&createdCodeSpaceRevision.AuthorUUID,
+		&createdCodeSpaceRevision.Contents,
+		&createdCodeSpaceRevision.ContentsHash,
+		&createdCodeSpaceRevision.CreatedAt,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdCodeSpaceRevision, nil
+}
+
+// ListCodeSpaceRevisions lists revisions of a given code space, most recent first.
+func (repo *repository) ListCodeSpaceRevisions(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	limit int64,
+	offset int64,
+) ([]*CodeSpaceRevision, error) {
+	codeSpaceRevisions := make([]*CodeSpaceRevision, 0)
+
+	q := `
+SELECT
+	v.id,
+	v.code_space_id,
+	v.author_uuid,
+	v.contents,
+	v.contents_hash,
+	v.created_at
+FROM
+	code_space_revision v
+WHERE
+	v.code_space_id = $1
+ORDER BY
+	v.created_at DESC,
+	v.id DESC
+LIMIT
+	$2
+OFFSET
+	$3;
+	`
+
+	rows, err := querier.Query(ctx, q, codeSpaceID, limit, offset)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Query failed")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		codeSpaceRevision := &CodeSpaceRevision{}
+
+		err := rows.Scan(
+			&codeSpaceRevision.ID,
+			&codeSpaceRevision.CodeSpaceID,
+			&codeSpaceRevision.AuthorUUID,
+			&codeSpaceRevision.Contents,
+			&codeSpaceRevision.ContentsHash,
+			&codeSpaceRevision.CreatedAt,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err, "rows.Scan failed")
+		}
+
+		codeSpaceRevisions = append(codeSpaceRevisions, codeSpaceRevision)
+	}
+
+	return codeSpaceRevisions, nil
+}
+
+// GetCodeSpaceRevision gets a given revision of a given code space.
+func (repo *repository) GetCodeSpaceRevision(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceRevisionID int64,
+) (*CodeSpaceRevision, error) {
+	codeSpaceRevision := &CodeSpaceRevision{}
+
+	q := `
+SELECT
+	v.id,
+	v.code_space_id,
+	v.author_uuid,
+	v.contents,
+	v.contents_hash,
+	v.created_at
+FROM
+	code_space_revision v
+WHERE
+	v.id = $1
+	AND v.code_space_id = $2;
+	`
+
+	err := querier.QueryRow(ctx, q, codeSpaceRevisionID, codeSpaceID).Scan(
+		&codeSpaceRevision.ID,
+		&codeSpaceRevision.CodeSpaceID,
+		&codeSpaceRevision.AuthorUUID,
+		&codeSpaceRevision.Contents,
+		&codeSpaceRevision.ContentsHash,
+		&codeSpaceRevision.CreatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return codeSpaceRevision, nil
+}
+
+// DeleteThinnedCodeSpaceRevisions thins out code space revisions created before a given time,
+// keeping only the last revision of each code space for each day.
+// It returns the number of deleted revisions.
+func (repo *repository) DeleteThinnedCodeSpaceRevisions(
+	ctx context.Context,
+	querier database.Querier,
+	createdBefore time.Time,
+) (int64, error) {
+	q := `
+DELETE FROM
+	code_space_revision v
+WHERE
+	v.created_at < $1
+	AND v.id NOT IN (
+		SELECT DISTINCT ON (k.code_space_id, DATE_TRUNC('day', k.created_at))
+			k.id
+		FROM
+			code_space_revision k
+		WHERE
+			k.created_at < $1
+		ORDER BY
+			k.code_space_id,
+			DATE_TRUNC('day', k.created_at),
+			k.created_at DESC,
+			k.id DESC
+	);
+	`
+
+	ct, err := querier.Exec(ctx, q, createdBefore)
+	if err != nil {
+		return 0, errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+// GetExecutionCacheEntry gets the unexpired execution cache entry with a given key.
+func (repo *repository) GetExecutionCacheEntry(
+	ctx context.Context,
+	querier database.Querier,
+	key string,
+) (*ExecutionCacheEntry, error) {
+	executionCacheEntry := &ExecutionCacheEntry{}
+
+	q := `
+SELECT
+	c.key,
+	c.response,
+	c.expires_at,
+	c.created_at,
+	c.updated_at
+FROM
+	execution_cache c
+WHERE
+	c.key = $1
+	AND c.expires_at > $2;
+	`
+
+	err := querier.QueryRow(ctx, q, key, repo.timeProvider.Now()).Scan(
+		&executionCacheEntry.Key,
+		&executionCacheEntry.Response,
+		&executionCacheEntry.ExpiresAt,
+		&executionCacheEntry.CreatedAt,
+		&executionCacheEntry.UpdatedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errutils.FormatError(errutils.ErrDatabaseNoRowsReturned, "querier.Scan failed")
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return executionCacheEntry, nil
+}
+
+// CreateOrUpdateExecutionCacheEntry creates a new execution cache entry or replaces the existing one with the same key.
+func (repo *repository) CreateOrUpdateExecutionCacheEntry(
+	ctx context.Context,
+	querier database.Querier,
+	executionCacheEntry *ExecutionCacheEntry,
+) (*ExecutionCacheEntry, error) {
+	now := repo.timeProvider.Now()
+	createdExecutionCacheEntry := &ExecutionCacheEntry{}
+
+	q := `
+INSERT INTO execution_cache (
+	key,
+	response,
+	expires_at,
+	created_at,
+	updated_at
+)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5
+)
+ON CONFLICT (key)
+DO UPDATE SET
+	response = EXCLUDED.response,
+	expires_at = EXCLUDED.expires_at,
+	updated_at = EXCLUDED.updated_at
+RETURNING
+	key,
+	response,
+	expires_at,
+	created_at,
+	updated_at;
+	`
+
+	err := querier.QueryRow(
+		ctx,
+		q,
+		executionCacheEntry.Key,
+		executionCacheEntry.Response,
+		executionCacheEntry.ExpiresAt,
+		now,
+		now,
+	).Scan(
+		&createdExecutionCacheEntry.Key,
+		&createdExecutionCacheEntry.Response,
+		&createdExecutionCacheEntry.ExpiresAt,
+		&createdExecutionCacheEntry.CreatedAt,
+		&createdExecutionCacheEntry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err, "querier.Scan failed")
+	}
+
+	return createdExecutionCacheEntry, nil
+}
+
+// DeleteStaleExecutionCacheEntries deletes expired execution cache entries,
+// as well as the least recently updated entries beyond a given maximum number of entries.
+func (repo *repository) DeleteStaleExecutionCacheEntries(
+	ctx context.Context,
+	querier database.Querier,
+	maxEntries int64,
+) error {
+	q := `
+DELETE FROM
+	execution_cache
+WHERE
+	expires_at <= $1
+	OR key NOT IN (
+		SELECT
+			c.key
+		FROM
+			execution_cache c
+		ORDER BY
+			c.updated_at DESC,
+			c.key
+		LIMIT
+			$2
+	);
+	`
+
+	_, err := querier.Exec(ctx, q, repo.timeProvider.Now(), maxEntries)
+	if err != nil {
+		return errutils.FormatError(err, "querier.Exec failed")
+	}
+
+	return nil
+}
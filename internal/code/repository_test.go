@@ -2,11 +2,14 @@ package code_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/code"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
+	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/testkit"
 	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
@@ -28,11 +31,13 @@ func TestRepositoryCreateCodeSpaceSuccess(t *testing.T) {
 	timeProvider := timekeeper.NewFrozenProvider()
 	repo := code.NewRepository(timeProvider)
 
+	languageVersion := "3.10.0"
 	codeSpace := &code.CodeSpace{
-		AuthorUUID: &author.UUID,
-		Name:       "habitable-slaking-volatile-granger-mov",
-		Language:   "python",
-		Contents:   "print('hello')",
+		AuthorUUID:      &author.UUID,
+		Name:            "habitable-slaking-volatile-granger-mov",
+		Language:        "python",
+		LanguageVersion: &languageVersion,
+		Contents:        "print('hello')",
 	}
 
 	createdCodeSpace, err := repo.CreateCodeSpace(context.Background(), dbConn, codeSpace)
@@ -42,6 +47,8 @@ func TestRepositoryCreateCodeSpaceSuccess(t *testing.T) {
 	require.Equal(t, author.UUID, *createdCodeSpace.AuthorUUID)
 	require.Equal(t, codeSpace.Name, createdCodeSpace.Name)
 	require.Equal(t, codeSpace.Language, createdCodeSpace.Language)
+	require.NotNil(t, createdCodeSpace.LanguageVersion)
+	require.Equal(t, languageVersion, *createdCodeSpace.LanguageVersion)
 	require.Equal(t, codeSpace.Contents, createdCodeSpace.Contents)
 	require.WithinDuration(t, timeProvider.Now(), createdCodeSpace.CreatedAt, testkit.TimeToleranceExact)
 	require.WithinDuration(t, timeProvider.Now(), createdCodeSpace.UpdatedAt, testkit.TimeToleranceExact)
@@ -75,7 +82,41 @@ func TestRepositoryCreateCodeSpaceDuplicateName(t *testing.T) {
 	}
 
 	_, err = repo.CreateCodeSpace(context.Background(), dbConn, codeSpace)
-	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseUniqueViolation)
+}
+
+func TestRepositoryCreateCodeSpaceForked(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	sourceCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	forkedCodeSpace, err := repo.CreateCodeSpace(context.Background(), dbConn, &code.CodeSpace{
+		AuthorUUID:   &author.UUID,
+		Name:         "fork-" + uuid.NewString(),
+		Language:     sourceCodeSpace.Language,
+		Contents:     sourceCodeSpace.Contents,
+		ForkedFromID: &sourceCodeSpace.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, forkedCodeSpace.ForkedFromID)
+	require.Equal(t, sourceCodeSpace.ID, *forkedCodeSpace.ForkedFromID)
+
+	err = repo.DeleteCodeSpace(context.Background(), dbConn, sourceCodeSpace.ID)
+	require.NoError(t, err)
+
+	fetchedCodeSpace, err := repo.GetCodeSpace(context.Background(), dbConn, forkedCodeSpace.ID)
+	require.NoError(t, err)
+	require.Nil(t, fetchedCodeSpace.ForkedFromID)
 }
 
 func TestRepositoryListCodeSpaces(t *testing.T) {
@@ -331,7 +372,7 @@ func TestRepositoryUpdateCodeSpaceSuccess(t *testing.T) {
 	repo := code.NewRepository(timeProvider)
 
 	updatedContents := "print('FizzBuzz')"
-	updatedCodeSpace, err := repo.UpdateCodeSpace(context.Background(), dbConn, codeSpace.ID, &updatedContents)
+	updatedCodeSpace, err := repo.UpdateCodeSpace(context.Background(), dbConn, codeSpace.ID, &updatedContents, nil, nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, codeSpace.AuthorUUID)
@@ -339,11 +380,56 @@ func TestRepositoryUpdateCodeSpaceSuccess(t *testing.T) {
 	require.Equal(t, codeSpace.ID, updatedCodeSpace.ID)
 	require.Equal(t, codeSpace.Name, updatedCodeSpace.Name)
 	require.Equal(t, codeSpace.Language, updatedCodeSpace.Language)
+	require.Nil(t, updatedCodeSpace.LanguageVersion)
 	require.Equal(t, updatedContents, updatedCodeSpace.Contents)
+	require.Equal(t, codeSpace.Version+1, updatedCodeSpace.Version)
 	require.WithinDuration(t, now, updatedCodeSpace.CreatedAt, testkit.TimeToleranceTentative)
 	require.WithinDuration(t, tomorrow, updatedCodeSpace.UpdatedAt, testkit.TimeToleranceTentative)
 }
 
+func TestRepositoryUpdateCodeSpaceLanguageVersion(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	languageVersion := "3.9.4"
+	updatedCodeSpace, err := repo.UpdateCodeSpace(context.Background(), dbConn, codeSpace.ID, nil, &languageVersion, nil)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.Contents, updatedCodeSpace.Contents)
+	require.NotNil(t, updatedCodeSpace.LanguageVersion)
+	require.Equal(t, languageVersion, *updatedCodeSpace.LanguageVersion)
+
+	updatedContents := "print('FizzBuzz')"
+	updatedCodeSpace, err = repo.UpdateCodeSpace(context.Background(), dbConn, codeSpace.ID, &updatedContents, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, updatedContents, updatedCodeSpace.Contents)
+	require.NotNil(t, updatedCodeSpace.LanguageVersion)
+	require.Equal(t, languageVersion, *updatedCodeSpace.LanguageVersion)
+
+	unpinnedLanguageVersion := ""
+	updatedCodeSpace, err = repo.UpdateCodeSpace(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		nil,
+		&unpinnedLanguageVersion,
+		nil,
+	)
+	require.NoError(t, err)
+	require.Nil(t, updatedCodeSpace.LanguageVersion)
+}
+
 func TestRepositoryUpdateCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
@@ -356,17 +442,27 @@ func TestRepositoryUpdateCodeSpaceError(t *testing.T) {
 	repo := code.NewRepository(timeProvider)
 	updatedContents := "print('FizzBuzz')"
 
+	outdatedVersion := codeSpace.Version + 1
+
 	testcases := map[string]struct {
 		codeSpaceID     int64
 		updatedContents *string
+		version         *int64
 	}{
 		"Update non-existent code space": {
 			codeSpaceID:     314159265,
 			updatedContents: &updatedContents,
+			version:         nil,
 		},
 		"Update blank update": {
 			codeSpaceID:     codeSpace.ID,
 			updatedContents: nil,
+			version:         nil,
+		},
+		"Update outdated version": {
+			codeSpaceID:     codeSpace.ID,
+			updatedContents: &updatedContents,
+			version:         &outdatedVersion,
 		},
 	}
 
@@ -378,7 +474,14 @@ func TestRepositoryUpdateCodeSpaceError(t *testing.T) {
 			require.NoError(t, err)
 			defer dbConn.Release()
 
-			_, err = repo.UpdateCodeSpace(context.Background(), dbConn, testcase.codeSpaceID, testcase.updatedContents)
+			_, err = repo.UpdateCodeSpace(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.updatedContents,
+				nil,
+				testcase.version,
+			)
 			require.Error(t, err)
 			require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
 		})
@@ -431,7 +534,7 @@ func TestRepositoryDeleteCodeSpaceNoRowsAffected(t *testing.T) {
 	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
 }
 
-func TestRepositoryCreateOrUpdateCodeSpaceAccess(t *testing.T) {
+func TestRepositoryTrashCodeSpace(t *testing.T) {
 	t.Parallel()
 
 	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
@@ -439,114 +542,52 @@ func TestRepositoryCreateOrUpdateCodeSpaceAccess(t *testing.T) {
 	})
 	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
-
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
-
-	invitee, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
 
 	timeProvider := timekeeper.NewFrozenProvider()
 	repo := code.NewRepository(timeProvider)
 
-	testcases := map[string]struct {
-		userUUID    string
-		codeSpaceID int64
-		accessLevel code.CodeSpaceAccessLevel
-		wantErr     bool
-	}{
-		"Create code space access for invitee without access": {
-			userUUID:    invitee.UUID,
-			codeSpaceID: codeSpace.ID,
-			accessLevel: code.CodeSpaceAccessLevelReadOnly,
-			wantErr:     false,
-		},
-		"Update code space access for editor to revoke write access": {
-			userUUID:    editor.UUID,
-			codeSpaceID: codeSpace.ID,
-			accessLevel: code.CodeSpaceAccessLevelReadOnly,
-			wantErr:     false,
-		},
-		"Update code space access for viewer to grant write access": {
-			userUUID:    viewer.UUID,
-			codeSpaceID: codeSpace.ID,
-			accessLevel: code.CodeSpaceAccessLevelReadWrite,
-			wantErr:     false,
-		},
-		"Non-existent code space ID": {
-			userUUID:    author.UUID,
-			codeSpaceID: 314159265,
-			accessLevel: code.CodeSpaceAccessLevelReadOnly,
-			wantErr:     true,
-		},
-	}
-
-	for name, testcase := range testcases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-
-			codeSpaceAccess := &code.CodeSpaceAccess{
-				UserUUID:    testcase.userUUID,
-				CodeSpaceID: testcase.codeSpaceID,
-				Level:       testcase.accessLevel,
-			}
-
-			dbConn, err := TestDBPool.Acquire(context.Background())
-			require.NoError(t, err)
-			defer dbConn.Release()
+	err = repo.TrashCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
 
-			codeSpaceAccess, err = repo.CreateOrUpdateCodeSpaceAccess(context.Background(), dbConn, codeSpaceAccess)
+	_, _, err = repo.GetCodeSpaceWithAccessByName(context.Background(), dbConn, author.UUID, codeSpace.Name)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
 
-			if testcase.wantErr {
-				require.Error(t, err)
+	_, err = repo.GetCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
 
-				return
-			}
+	updatedContents := "print('FizzBuzz')"
+	_, err = repo.UpdateCodeSpace(context.Background(), dbConn, codeSpace.ID, &updatedContents, nil, nil)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
 
-			require.NoError(t, err)
-			require.Equal(t, testcase.userUUID, codeSpaceAccess.UserUUID)
-			require.Equal(t, testcase.codeSpaceID, codeSpaceAccess.CodeSpaceID)
-			require.Equal(t, testcase.accessLevel, codeSpaceAccess.Level)
-			require.WithinDuration(t, timeProvider.Now(), codeSpaceAccess.CreatedAt, testkit.TimeToleranceTentative)
-			require.WithinDuration(t, timeProvider.Now(), codeSpaceAccess.UpdatedAt, testkit.TimeToleranceTentative)
-		})
-	}
+	trashedCodeSpace, trashedCodeSpaceAccess, err := repo.GetTrashedCodeSpaceWithAccessByName(
+		context.Background(),
+		dbConn,
+		author.UUID,
+		codeSpace.Name,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, trashedCodeSpace.ID)
+	require.Equal(t, codeSpace.Contents, trashedCodeSpace.Contents)
+	require.NotNil(t, trashedCodeSpace.DeletedAt)
+	require.WithinDuration(t, timeProvider.Now(), *trashedCodeSpace.DeletedAt, testkit.TimeToleranceExact)
+	require.Equal(t, author.UUID, trashedCodeSpaceAccess.UserUUID)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, trashedCodeSpaceAccess.Level)
+
+	err = repo.TrashCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
 }
 
-func TestRepositoryListUsersWithCodeSpaceAccess(t *testing.T) {
+func TestRepositoryListTrashedCodeSpaces(t *testing.T) {
 	t.Parallel()
 
 	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
 	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
+	trashedCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
 	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
@@ -554,41 +595,40 @@ func TestRepositoryListUsersWithCodeSpaceAccess(t *testing.T) {
 	testkitinternal.MustCreateCodeSpaceAccess(
 		t,
 		viewer.UUID,
-		codeSpace.ID,
+		trashedCodeSpace.ID,
 		code.CodeSpaceAccessLevelReadOnly,
 	)
 
-	timeProvider := timekeeper.NewFrozenProvider()
 	dbConn, err := TestDBPool.Acquire(context.Background())
 	require.NoError(t, err)
 	defer dbConn.Release()
 
+	timeProvider := timekeeper.NewFrozenProvider()
 	repo := code.NewRepository(timeProvider)
 
-	users, codeSpaceAccesses, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	err = repo.TrashCodeSpace(context.Background(), dbConn, trashedCodeSpace.ID)
 	require.NoError(t, err)
-	require.Len(t, users, 3)
-	require.Len(t, codeSpaceAccesses, 3)
-
-	userAccessLevelMap := map[string]code.CodeSpaceAccessLevel{
-		author.UUID: code.CodeSpaceAccessLevelReadWrite,
-		editor.UUID: code.CodeSpaceAccessLevelReadWrite,
-		viewer.UUID: code.CodeSpaceAccessLevelReadOnly,
-	}
 
-	for i, codeSpaceAccess := range codeSpaceAccesses {
-		wantAccessLevel, ok := userAccessLevelMap[codeSpaceAccess.UserUUID]
-		require.True(t, ok)
+	codeSpaces, codeSpaceAccesses, err := repo.ListTrashedCodeSpaces(context.Background(), dbConn, author.UUID)
+	require.NoError(t, err)
+	require.Len(t, codeSpaces, 1)
+	require.Len(t, codeSpaceAccesses, 1)
+	require.Equal(t, trashedCodeSpace.ID, codeSpaces[0].ID)
+	require.NotNil(t, codeSpaces[0].DeletedAt)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, codeSpaceAccesses[0].Level)
 
-		delete(userAccessLevelMap, codeSpaceAccess.UserUUID)
+	codeSpaces, codeSpaceAccesses, err = repo.ListTrashedCodeSpaces(context.Background(), dbConn, viewer.UUID)
+	require.NoError(t, err)
+	require.Empty(t, codeSpaces)
+	require.Empty(t, codeSpaceAccesses)
 
-		require.Equal(t, users[i].UUID, codeSpaceAccess.UserUUID)
-		require.Equal(t, codeSpace.ID, codeSpaceAccess.CodeSpaceID)
-		require.Equal(t, wantAccessLevel, codeSpaceAccess.Level)
-	}
+	codeSpaces, _, err = repo.ListCodeSpaces(context.Background(), dbConn, author.UUID)
+	require.NoError(t, err)
+	require.Len(t, codeSpaces, 1)
+	require.NotEqual(t, trashedCodeSpace.ID, codeSpaces[0].ID)
 }
 
-func TestRepositoryDeleteCodeSpaceAccessSuccess(t *testing.T) {
+func TestRepositoryRestoreCodeSpace(t *testing.T) {
 	t.Parallel()
 
 	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
@@ -596,42 +636,2284 @@ func TestRepositoryDeleteCodeSpaceAccessSuccess(t *testing.T) {
 	})
 	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	invitee, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	_, err = repo.RestoreCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+
+	err = repo.TrashCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	restoredCodeSpace, err := repo.RestoreCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, restoredCodeSpace.ID)
+	require.Equal(t, codeSpace.Name, restoredCodeSpace.Name)
+	require.Equal(t, codeSpace.Contents, restoredCodeSpace.Contents)
+	require.Nil(t, restoredCodeSpace.DeletedAt)
+
+	fetchedCodeSpace, _, err := repo.GetCodeSpaceWithAccessByName(
+		context.Background(),
+		dbConn,
+		author.UUID,
+		codeSpace.Name,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, fetchedCodeSpace.ID)
+
+	_, _, err = repo.GetTrashedCodeSpaceWithAccessByName(context.Background(), dbConn, author.UUID, codeSpace.Name)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+}
+
+func TestRepositoryDeleteTrashedCodeSpaces(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
 	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		invitee.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
+	expiredCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	recentCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	timeProvider := timekeeper.NewFrozenProvider()
 	dbConn, err := TestDBPool.Acquire(context.Background())
 	require.NoError(t, err)
 	defer dbConn.Release()
 
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
 	repo := code.NewRepository(timeProvider)
 
-	err = repo.DeleteCodeSpaceAccess(context.Background(), dbConn, invitee.UUID, codeSpace.ID)
+	timeProvider.SetTime(now.AddDate(0, 0, -2))
+	err = repo.TrashCodeSpace(context.Background(), dbConn, expiredCodeSpace.ID)
 	require.NoError(t, err)
 
-	_, _, err = repo.GetCodeSpaceWithAccessByName(context.Background(), dbConn, invitee.UUID, codeSpace.Name)
-	require.Error(t, err)
-	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+	timeProvider.SetTime(now)
+	err = repo.TrashCodeSpace(context.Background(), dbConn, recentCodeSpace.ID)
+	require.NoError(t, err)
+
+	deletedCount, err := repo.DeleteTrashedCodeSpaces(context.Background(), dbConn, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deletedCount, int64(1))
+
+	codeSpaces, _, err := repo.ListTrashedCodeSpaces(context.Background(), dbConn, author.UUID)
+	require.NoError(t, err)
+	require.Len(t, codeSpaces, 1)
+	require.Equal(t, recentCodeSpace.ID, codeSpaces[0].ID)
 }
 
-func TestRepositoryDeleteCodeSpaceAccessNoRowsAffected(t *testing.T) {
+func TestRepositoryRenameCodeSpace(t *testing.T) {
 	t.Parallel()
 
-	timeProvider := timekeeper.NewFrozenProvider()
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
 	dbConn, err := TestDBPool.Acquire(context.Background())
 	require.NoError(t, err)
 	defer dbConn.Release()
 
+	timeProvider := timekeeper.NewFrozenProvider()
 	repo := code.NewRepository(timeProvider)
 
-	err = repo.DeleteCodeSpaceAccess(context.Background(), dbConn, uuid.NewString(), 314159265)
-	require.Error(t, err)
+	_, err = repo.RenameCodeSpace(context.Background(), dbConn, codeSpace.ID, otherCodeSpace.Name, nil)
+	require.ErrorIs(t, err, errutils.ErrDatabaseUniqueViolation)
+
+	newName := "renamed-" + uuid.NewString()
+	renamedCodeSpace, err := repo.RenameCodeSpace(context.Background(), dbConn, codeSpace.ID, newName, &codeSpace.Version)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, renamedCodeSpace.ID)
+	require.Equal(t, newName, renamedCodeSpace.Name)
+	require.Equal(t, codeSpace.Contents, renamedCodeSpace.Contents)
+	require.Equal(t, codeSpace.Version+1, renamedCodeSpace.Version)
+	require.WithinDuration(t, timeProvider.Now(), renamedCodeSpace.UpdatedAt, testkit.TimeToleranceExact)
+
+	_, err = repo.RenameCodeSpace(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		"renamed-"+uuid.NewString(),
+		&codeSpace.Version,
+	)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+
+	err = repo.TrashCodeSpace(context.Background(), dbConn, otherCodeSpace.ID)
+	require.NoError(t, err)
+
+	_, err = repo.RenameCodeSpace(context.Background(), dbConn, otherCodeSpace.ID, "renamed-"+uuid.NewString(), nil)
 	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
 }
+
+func TestRepositoryCodeSpaceAlias(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	aliasName := "alias-" + uuid.NewString()
+	_, err = repo.GetCodeSpaceAliasByName(context.Background(), dbConn, aliasName)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+
+	codeSpaceAlias, err := repo.CreateCodeSpaceAlias(context.Background(), dbConn, &code.CodeSpaceAlias{
+		CodeSpaceID: codeSpace.ID,
+		Name:        aliasName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, codeSpaceAlias.CodeSpaceID)
+	require.Equal(t, aliasName, codeSpaceAlias.Name)
+	require.WithinDuration(t, timeProvider.Now(), codeSpaceAlias.CreatedAt, testkit.TimeToleranceExact)
+
+	_, err = repo.CreateCodeSpaceAlias(context.Background(), dbConn, &code.CodeSpaceAlias{
+		CodeSpaceID: codeSpace.ID,
+		Name:        aliasName,
+	})
+	require.ErrorIs(t, err, errutils.ErrDatabaseUniqueViolation)
+
+	fetchedCodeSpaceAlias, err := repo.GetCodeSpaceAliasByName(context.Background(), dbConn, aliasName)
+	require.NoError(t, err)
+	require.Equal(t, codeSpaceAlias.ID, fetchedCodeSpaceAlias.ID)
+	require.Equal(t, codeSpace.ID, fetchedCodeSpaceAlias.CodeSpaceID)
+
+	fetchedCodeSpace, codeSpaceAccess, err := repo.GetCodeSpaceWithAccessByName(
+		context.Background(),
+		dbConn,
+		author.UUID,
+		aliasName,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, fetchedCodeSpace.ID)
+	require.Equal(t, codeSpace.Name, fetchedCodeSpace.Name)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, codeSpaceAccess.Level)
+
+	err = repo.DeleteCodeSpaceAlias(context.Background(), dbConn, codeSpaceAlias.ID)
+	require.NoError(t, err)
+
+	err = repo.DeleteCodeSpaceAlias(context.Background(), dbConn, codeSpaceAlias.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+
+	_, _, err = repo.GetCodeSpaceWithAccessByName(context.Background(), dbConn, author.UUID, aliasName)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+}
+
+func TestRepositoryCodeSpaceNameAndAliasShareNamespace(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	_, err = repo.CreateCodeSpaceAlias(context.Background(), dbConn, &code.CodeSpaceAlias{
+		CodeSpaceID: codeSpace.ID,
+		Name:        otherCodeSpace.Name,
+	})
+	require.ErrorIs(t, err, errutils.ErrDatabaseUniqueViolation)
+
+	aliasName := "alias-" + uuid.NewString()
+	codeSpaceAlias, err := repo.CreateCodeSpaceAlias(context.Background(), dbConn, &code.CodeSpaceAlias{
+		CodeSpaceID: codeSpace.ID,
+		Name:        aliasName,
+	})
+	require.NoError(t, err)
+
+	_, err = repo.RenameCodeSpace(context.Background(), dbConn, otherCodeSpace.ID, aliasName, nil)
+	require.ErrorIs(t, err, errutils.ErrDatabaseUniqueViolation)
+
+	err = repo.DeleteCodeSpaceAlias(context.Background(), dbConn, codeSpaceAlias.ID)
+	require.NoError(t, err)
+
+	renamedCodeSpace, err := repo.RenameCodeSpace(context.Background(), dbConn, otherCodeSpace.ID, aliasName, nil)
+	require.NoError(t, err)
+	require.Equal(t, aliasName, renamedCodeSpace.Name)
+
+	_, err = repo.CreateCodeSpaceAlias(context.Background(), dbConn, &code.CodeSpaceAlias{
+		CodeSpaceID: otherCodeSpace.ID,
+		Name:        otherCodeSpace.Name,
+	})
+	require.NoError(t, err)
+}
+
+func TestRepositoryCodeSpaceRevisions(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	contents := "print('revised')"
+	timeProvider.AddDate(0, 0, 1)
+	codeSpaceRevision, err := repo.CreateCodeSpaceRevision(context.Background(), dbConn, &code.CodeSpaceRevision{
+		CodeSpaceID:  codeSpace.ID,
+		AuthorUUID:   &author.UUID,
+		Contents:     contents,
+		ContentsHash: code.HashCodeSpaceContents(contents),
+	})
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, codeSpaceRevision.CodeSpaceID)
+	require.NotNil(t, codeSpaceRevision.AuthorUUID)
+	require.Equal(t, author.UUID, *codeSpaceRevision.AuthorUUID)
+	require.Equal(t, contents, codeSpaceRevision.Contents)
+	require.Equal(t, code.HashCodeSpaceContents(contents), codeSpaceRevision.ContentsHash)
+	require.WithinDuration(t, timeProvider.Now(), codeSpaceRevision.CreatedAt, testkit.TimeToleranceExact)
+
+	codeSpaceRevisions, err := repo.ListCodeSpaceRevisions(context.Background(), dbConn, codeSpace.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceRevisions, 2)
+	require.Equal(t, codeSpaceRevision.ID, codeSpaceRevisions[0].ID)
+	require.Equal(t, codeSpace.Contents, codeSpaceRevisions[1].Contents)
+
+	codeSpaceRevisions, err = repo.ListCodeSpaceRevisions(context.Background(), dbConn, codeSpace.ID, 10, 1)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceRevisions, 1)
+	require.NotEqual(t, codeSpaceRevision.ID, codeSpaceRevisions[0].ID)
+
+	fetchedCodeSpaceRevision, err := repo.GetCodeSpaceRevision(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		codeSpaceRevision.ID,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpaceRevision.ID, fetchedCodeSpaceRevision.ID)
+	require.Equal(t, contents, fetchedCodeSpaceRevision.Contents)
+
+	_, err = repo.GetCodeSpaceRevision(context.Background(), dbConn, otherCodeSpace.ID, codeSpaceRevision.ID)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+}
+
+func TestRepositoryDeleteThinnedCodeSpaceRevisions(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	now := timeProvider.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	createdAts := []time.Time{
+		dayStart.AddDate(0, 0, -3).Add(time.Hour),
+		dayStart.AddDate(0, 0, -3).Add(2 * time.Hour),
+		dayStart.AddDate(0, 0, -3).Add(3 * time.Hour),
+		dayStart.AddDate(0, 0, -2).Add(time.Hour),
+		dayStart.AddDate(0, 0, -1).Add(time.Hour),
+		dayStart.AddDate(0, 0, -1).Add(2 * time.Hour),
+	}
+
+	codeSpaceRevisions := make([]*code.CodeSpaceRevision, len(createdAts))
+	for i, createdAt := range createdAts {
+		timeProvider.SetTime(createdAt)
+		contents := fmt.Sprintf("print(%d)", i)
+		codeSpaceRevisions[i], err = repo.CreateCodeSpaceRevision(context.Background(), dbConn, &code.CodeSpaceRevision{
+			CodeSpaceID:  codeSpace.ID,
+			AuthorUUID:   &author.UUID,
+			Contents:     contents,
+			ContentsHash: code.HashCodeSpaceContents(contents),
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = repo.DeleteThinnedCodeSpaceRevisions(context.Background(), dbConn, dayStart.AddDate(0, 0, -1))
+	require.NoError(t, err)
+
+	remainingCodeSpaceRevisions, err := repo.ListCodeSpaceRevisions(context.Background(), dbConn, codeSpace.ID, 10, 0)
+	require.NoError(t, err)
+
+	remainingIDs := make([]int64, len(remainingCodeSpaceRevisions))
+	for i, codeSpaceRevision := range remainingCodeSpaceRevisions {
+		remainingIDs[i] = codeSpaceRevision.ID
+	}
+
+	require.Len(t, remainingIDs, 5)
+	require.NotContains(t, remainingIDs, codeSpaceRevisions[0].ID)
+	require.NotContains(t, remainingIDs, codeSpaceRevisions[1].ID)
+	require.Contains(t, remainingIDs, codeSpaceRevisions[2].ID)
+	require.Contains(t, remainingIDs, codeSpaceRevisions[3].ID)
+	require.Contains(t, remainingIDs, codeSpaceRevisions[4].ID)
+	require.Contains(t, remainingIDs, codeSpaceRevisions[5].ID)
+}
+
+func TestRepositoryCreateOrUpdateCodeSpaceAccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	invitee, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	testcases := map[string]struct {
+		userUUID    string
+		codeSpaceID int64
+		accessLevel code.CodeSpaceAccessLevel
+		wantErr     bool
+	}{
+		"Create code space access for invitee without access": {
+			userUUID:    invitee.UUID,
+			codeSpaceID: codeSpace.ID,
+			accessLevel: code.CodeSpaceAccessLevelReadOnly,
+			wantErr:     false,
+		},
+		"Update code space access for editor to revoke write access": {
+			userUUID:    editor.UUID,
+			codeSpaceID: codeSpace.ID,
+			accessLevel: code.CodeSpaceAccessLevelReadOnly,
+			wantErr:     false,
+		},
+		"Update code space access for viewer to grant write access": {
+			userUUID:    viewer.UUID,
+			codeSpaceID: codeSpace.ID,
+			accessLevel: code.CodeSpaceAccessLevelReadWrite,
+			wantErr:     false,
+		},
+		"Non-existent code space ID": {
+			userUUID:    author.UUID,
+			codeSpaceID: 314159265,
+			accessLevel: code.CodeSpaceAccessLevelReadOnly,
+			wantErr:     true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				UserUUID:    testcase.userUUID,
+				CodeSpaceID: testcase.codeSpaceID,
+				Level:       testcase.accessLevel,
+			}
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			codeSpaceAccess, err = repo.CreateOrUpdateCodeSpaceAccess(context.Background(), dbConn, codeSpaceAccess)
+
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.userUUID, codeSpaceAccess.UserUUID)
+			require.Equal(t, testcase.codeSpaceID, codeSpaceAccess.CodeSpaceID)
+			require.Equal(t, testcase.accessLevel, codeSpaceAccess.Level)
+			require.WithinDuration(t, timeProvider.Now(), codeSpaceAccess.CreatedAt, testkit.TimeToleranceTentative)
+			require.WithinDuration(t, timeProvider.Now(), codeSpaceAccess.UpdatedAt, testkit.TimeToleranceTentative)
+		})
+	}
+}
+
+func TestRepositoryListUsersWithCodeSpaceAccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	users, codeSpaceAccesses, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	require.Len(t, codeSpaceAccesses, 3)
+
+	userAccessLevelMap := map[string]code.CodeSpaceAccessLevel{
+		author.UUID: code.CodeSpaceAccessLevelReadWrite,
+		editor.UUID: code.CodeSpaceAccessLevelReadWrite,
+		viewer.UUID: code.CodeSpaceAccessLevelReadOnly,
+	}
+
+	for i, codeSpaceAccess := range codeSpaceAccesses {
+		wantAccessLevel, ok := userAccessLevelMap[codeSpaceAccess.UserUUID]
+		require.True(t, ok)
+
+		delete(userAccessLevelMap, codeSpaceAccess.UserUUID)
+
+		require.Equal(t, users[i].UUID, codeSpaceAccess.UserUUID)
+		require.Equal(t, codeSpace.ID, codeSpaceAccess.CodeSpaceID)
+		require.Equal(t, wantAccessLevel, codeSpaceAccess.Level)
+	}
+}
+
+func TestRepositoryDeleteCodeSpaceAccessSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	invitee, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		invitee.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	err = repo.DeleteCodeSpaceAccess(context.Background(), dbConn, invitee.UUID, codeSpace.ID)
+	require.NoError(t, err)
+
+	_, _, err = repo.GetCodeSpaceWithAccessByName(context.Background(), dbConn, invitee.UUID, codeSpace.Name)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+}
+
+func TestRepositoryDeleteCodeSpaceAccessNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	err = repo.DeleteCodeSpaceAccess(context.Background(), dbConn, uuid.NewString(), 314159265)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateCodeSpaceRunSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	codeSpaceRun := &code.CodeSpaceRun{
+		CodeSpaceID: codeSpace.ID,
+		UserUUID:    &author.UUID,
+		Contents:    codeSpace.Contents,
+		Language:    codeSpace.Language,
+		Version:     "3.10.0",
+		Status:      api.CodeSpaceRunStatusQueued,
+	}
+
+	createdCodeSpaceRun, err := repo.CreateCodeSpaceRun(context.Background(), dbConn, codeSpaceRun)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, createdCodeSpaceRun.CodeSpaceID)
+	require.NotNil(t, createdCodeSpaceRun.UserUUID)
+	require.Equal(t, author.UUID, *createdCodeSpaceRun.UserUUID)
+	require.Equal(t, codeSpaceRun.Contents, createdCodeSpaceRun.Contents)
+	require.Equal(t, codeSpaceRun.Language, createdCodeSpaceRun.Language)
+	require.Equal(t, codeSpaceRun.Version, createdCodeSpaceRun.Version)
+	require.Equal(t, api.CodeSpaceRunStatusQueued, createdCodeSpaceRun.Status)
+	require.Nil(t, createdCodeSpaceRun.CompileStdout)
+	require.Nil(t, createdCodeSpaceRun.RunStdout)
+	require.Nil(t, createdCodeSpaceRun.StartedAt)
+	require.Nil(t, createdCodeSpaceRun.FinishedAt)
+	require.WithinDuration(t, timeProvider.Now(), createdCodeSpaceRun.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, timeProvider.Now(), createdCodeSpaceRun.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateCodeSpaceRunNonExistentCodeSpace(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	codeSpaceRun := &code.CodeSpaceRun{
+		CodeSpaceID: 314159265,
+		UserUUID:    &author.UUID,
+		Contents:    "print('hello')",
+		Language:    "python",
+		Version:     "3.10.0",
+		Status:      api.CodeSpaceRunStatusQueued,
+	}
+
+	_, err = repo.CreateCodeSpaceRun(context.Background(), dbConn, codeSpaceRun)
+	require.Error(t, err)
+}
+
+func TestRepositoryListCodeSpaceRuns(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	firstCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+	secondCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+	thirdCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+	testkitinternal.MustCreateCodeSpaceRun(t, otherCodeSpace, author.UUID)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	testcases := map[string]struct {
+		limit   int64
+		offset  int64
+		wantIDs []int64
+	}{
+		"All runs": {
+			limit:   10,
+			offset:  0,
+			wantIDs: []int64{thirdCodeSpaceRun.ID, secondCodeSpaceRun.ID, firstCodeSpaceRun.ID},
+		},
+		"First page": {
+			limit:   2,
+			offset:  0,
+			wantIDs: []int64{thirdCodeSpaceRun.ID, secondCodeSpaceRun.ID},
+		},
+		"Second page": {
+			limit:   2,
+			offset:  2,
+			wantIDs: []int64{firstCodeSpaceRun.ID},
+		},
+		"Offset beyond runs": {
+			limit:   2,
+			offset:  3,
+			wantIDs: []int64{},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			codeSpaceRuns, err := repo.ListCodeSpaceRuns(
+				context.Background(),
+				dbConn,
+				codeSpace.ID,
+				testcase.limit,
+				testcase.offset,
+			)
+			require.NoError(t, err)
+
+			ids := make([]int64, len(codeSpaceRuns))
+			for i, codeSpaceRun := range codeSpaceRuns {
+				require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+				ids[i] = codeSpaceRun.ID
+			}
+
+			require.Equal(t, testcase.wantIDs, ids)
+		})
+	}
+}
+
+func TestRepositoryGetCodeSpaceRun(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	testcases := map[string]struct {
+		codeSpaceID    int64
+		codeSpaceRunID int64
+		wantErr        error
+	}{
+		"Get run": {
+			codeSpaceID:    codeSpace.ID,
+			codeSpaceRunID: codeSpaceRun.ID,
+			wantErr:        nil,
+		},
+		"Get run of another code space": {
+			codeSpaceID:    otherCodeSpace.ID,
+			codeSpaceRunID: codeSpaceRun.ID,
+			wantErr:        errutils.ErrDatabaseNoRowsReturned,
+		},
+		"Get non-existent run": {
+			codeSpaceID:    codeSpace.ID,
+			codeSpaceRunID: 314159265,
+			wantErr:        errutils.ErrDatabaseNoRowsReturned,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			fetchedCodeSpaceRun, err := repo.GetCodeSpaceRun(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceRunID,
+			)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, codeSpaceRun.ID, fetchedCodeSpaceRun.ID)
+			require.Equal(t, codeSpaceRun.CodeSpaceID, fetchedCodeSpaceRun.CodeSpaceID)
+			require.Equal(t, codeSpaceRun.UserUUID, fetchedCodeSpaceRun.UserUUID)
+			require.Equal(t, codeSpaceRun.Contents, fetchedCodeSpaceRun.Contents)
+			require.Equal(t, codeSpaceRun.Status, fetchedCodeSpaceRun.Status)
+			require.Equal(t, codeSpaceRun.RunStdout, fetchedCodeSpaceRun.RunStdout)
+			require.Equal(t, codeSpaceRun.RunCode, fetchedCodeSpaceRun.RunCode)
+		})
+	}
+}
+
+func TestRepositoryUpdateCodeSpaceRunSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "c")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	codeSpaceRun, err := repo.CreateCodeSpaceRun(context.Background(), dbConn, &code.CodeSpaceRun{
+		CodeSpaceID: codeSpace.ID,
+		UserUUID:    &author.UUID,
+		Contents:    codeSpace.Contents,
+		Language:    codeSpace.Language,
+		Version:     "10.2.0",
+		Status:      api.CodeSpaceRunStatusQueued,
+	})
+	require.NoError(t, err)
+
+	later := now.Add(time.Minute)
+	timeProvider.SetTime(later)
+
+	exitCodeZero := 0
+	signal := "SIGKILL"
+	status := api.PistonStatusSignal
+	cpuTime := int64(120)
+	wallTime := int64(134)
+	memory := int64(268435456)
+	codeSpaceRun.Status = api.CodeSpaceRunStatusCompleted
+	codeSpaceRun.StartedAt = &now
+	codeSpaceRun.FinishedAt = &later
+	codeSpaceRun.SetResults(&api.PistonExecuteResponse{
+		Compile: &api.PistonResults{
+			Stdout: "",
+			Stderr: "warning: unused variable",
+			Code:   &exitCodeZero,
+			Signal: nil,
+		},
+		Run: api.PistonResults{
+			Stdout:   "Yello!\n",
+			Stderr:   "",
+			Code:     nil,
+			Signal:   &signal,
+			Status:   &status,
+			CPUTime:  &cpuTime,
+			WallTime: &wallTime,
+			Memory:   &memory,
+		},
+	})
+
+	updatedCodeSpaceRun, err := repo.UpdateCodeSpaceRun(context.Background(), dbConn, codeSpaceRun)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpaceRun.ID, updatedCodeSpaceRun.ID)
+	require.Equal(t, api.CodeSpaceRunStatusCompleted, updatedCodeSpaceRun.Status)
+	require.NotNil(t, updatedCodeSpaceRun.CompileStderr)
+	require.Equal(t, "warning: unused variable", *updatedCodeSpaceRun.CompileStderr)
+	require.Equal(t, &exitCodeZero, updatedCodeSpaceRun.CompileCode)
+	require.Nil(t, updatedCodeSpaceRun.CompileSignal)
+	require.NotNil(t, updatedCodeSpaceRun.RunStdout)
+	require.Equal(t, "Yello!\n", *updatedCodeSpaceRun.RunStdout)
+	require.Nil(t, updatedCodeSpaceRun.RunCode)
+	require.Equal(t, &signal, updatedCodeSpaceRun.RunSignal)
+	require.Equal(t, &status, updatedCodeSpaceRun.RunStatus)
+	require.Equal(t, &cpuTime, updatedCodeSpaceRun.RunCPUTime)
+	require.Equal(t, &wallTime, updatedCodeSpaceRun.RunWallTime)
+	require.Equal(t, &memory, updatedCodeSpaceRun.RunMemory)
+	require.False(t, updatedCodeSpaceRun.RunTimedOut)
+	require.True(t, updatedCodeSpaceRun.RunMemoryLimitExceeded)
+	require.False(t, updatedCodeSpaceRun.CompileTimedOut)
+	require.False(t, updatedCodeSpaceRun.CompileMemoryLimitExceeded)
+	require.NotNil(t, updatedCodeSpaceRun.StartedAt)
+	require.WithinDuration(t, now, *updatedCodeSpaceRun.StartedAt, testkit.TimeToleranceExact)
+	require.NotNil(t, updatedCodeSpaceRun.FinishedAt)
+	require.WithinDuration(t, later, *updatedCodeSpaceRun.FinishedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, now, updatedCodeSpaceRun.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, later, updatedCodeSpaceRun.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryUpdateCodeSpaceRunNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	_, err = repo.UpdateCodeSpaceRun(context.Background(), dbConn, &code.CodeSpaceRun{
+		ID:     314159265,
+		Status: api.CodeSpaceRunStatusFailed,
+	})
+	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "java")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	codeSpaceFile, err := repo.CreateCodeSpaceFile(context.Background(), dbConn, &code.CodeSpaceFile{
+		CodeSpaceID:  codeSpace.ID,
+		Name:         "Helper.java",
+		Contents:     "public class Helper {}",
+		IsEntryPoint: true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceFile.CodeSpaceID)
+	require.Equal(t, "Helper.java", codeSpaceFile.Name)
+	require.Equal(t, "public class Helper {}", codeSpaceFile.Contents)
+	require.True(t, codeSpaceFile.IsEntryPoint)
+	require.WithinDuration(t, now, codeSpaceFile.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, now, codeSpaceFile.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateCodeSpaceFileError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", true)
+
+	testcases := map[string]struct {
+		codeSpaceFile *code.CodeSpaceFile
+		wantErr       error
+	}{
+		"Duplicate name": {
+			codeSpaceFile: &code.CodeSpaceFile{
+				CodeSpaceID:  codeSpace.ID,
+				Name:         "utils.py",
+				IsEntryPoint: false,
+			},
+			wantErr: errutils.ErrDatabaseUniqueViolation,
+		},
+		"Second entry point": {
+			codeSpaceFile: &code.CodeSpaceFile{
+				CodeSpaceID:  codeSpace.ID,
+				Name:         "strings.py",
+				IsEntryPoint: true,
+			},
+			wantErr: errutils.ErrDatabaseUniqueViolation,
+		},
+		"Non-existent code space": {
+			codeSpaceFile: &code.CodeSpaceFile{
+				CodeSpaceID:  314159265,
+				Name:         "utils.py",
+				IsEntryPoint: false,
+			},
+			wantErr: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.CreateCodeSpaceFile(context.Background(), dbConn, testcase.codeSpaceFile)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepositoryListCodeSpaceFiles(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "c")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "c")
+
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.h", false)
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.c", false)
+	testkitinternal.MustCreateCodeSpaceFile(t, otherCodeSpace.ID, "other.c", false)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	codeSpaceFiles, err := repo.ListCodeSpaceFiles(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceFiles, 2)
+	require.Equal(t, "utils.c", codeSpaceFiles[0].Name)
+	require.Equal(t, "utils.h", codeSpaceFiles[1].Name)
+
+	for _, codeSpaceFile := range codeSpaceFiles {
+		require.Equal(t, codeSpace.ID, codeSpaceFile.CodeSpaceID)
+	}
+}
+
+func TestRepositoryUpdateCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	later := timeProvider.Now().Add(time.Minute)
+	timeProvider.SetTime(later)
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	newName := "helpers.py"
+	newContents := "def helper():\n    pass\n"
+	isEntryPoint := true
+
+	updatedCodeSpaceFile, err := repo.UpdateCodeSpaceFile(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		codeSpaceFile.ID,
+		&newName,
+		&newContents,
+		&isEntryPoint,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpaceFile.ID, updatedCodeSpaceFile.ID)
+	require.Equal(t, newName, updatedCodeSpaceFile.Name)
+	require.Equal(t, newContents, updatedCodeSpaceFile.Contents)
+	require.True(t, updatedCodeSpaceFile.IsEntryPoint)
+	require.WithinDuration(t, later, updatedCodeSpaceFile.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryUpdateCodeSpaceFileError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "strings.py", false)
+
+	takenName := "strings.py"
+	newName := "helpers.py"
+
+	testcases := map[string]struct {
+		codeSpaceID     int64
+		codeSpaceFileID int64
+		name            *string
+		wantErr         error
+	}{
+		"No attributes": {
+			codeSpaceID:     codeSpace.ID,
+			codeSpaceFileID: codeSpaceFile.ID,
+			name:            nil,
+			wantErr:         errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Non-existent file": {
+			codeSpaceID:     codeSpace.ID,
+			codeSpaceFileID: 314159265,
+			name:            &newName,
+			wantErr:         errutils.ErrDatabaseNoRowsAffected,
+		},
+		"File in another code space": {
+			codeSpaceID:     314159265,
+			codeSpaceFileID: codeSpaceFile.ID,
+			name:            &newName,
+			wantErr:         errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Name taken": {
+			codeSpaceID:     codeSpace.ID,
+			codeSpaceFileID: codeSpaceFile.ID,
+			name:            &takenName,
+			wantErr:         errutils.ErrDatabaseUniqueViolation,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.UpdateCodeSpaceFile(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceFileID,
+				testcase.name,
+				nil,
+				nil,
+			)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestRepositoryClearCodeSpaceEntryPoint(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "app.py", true)
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.ClearCodeSpaceEntryPoint(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	codeSpaceFiles, err := repo.ListCodeSpaceFiles(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceFiles, 2)
+
+	for _, codeSpaceFile := range codeSpaceFiles {
+		require.False(t, codeSpaceFile.IsEntryPoint)
+	}
+
+	err = repo.ClearCodeSpaceEntryPoint(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+}
+
+func TestRepositoryDeleteCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceFile(context.Background(), dbConn, codeSpace.ID, codeSpaceFile.ID)
+	require.NoError(t, err)
+
+	codeSpaceFiles, err := repo.ListCodeSpaceFiles(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Empty(t, codeSpaceFiles)
+}
+
+func TestRepositoryDeleteCodeSpaceFileNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceFile(context.Background(), dbConn, 314159265, 314159265)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateCodeSpaceTestCaseSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	stdin := "3\n4\n"
+
+	codeSpaceTestCase, err := repo.CreateCodeSpaceTestCase(context.Background(), dbConn, &code.CodeSpaceTestCase{
+		CodeSpaceID:    codeSpace.ID,
+		Stdin:          &stdin,
+		Args:           []string{"--sum"},
+		ExpectedStdout: "7",
+		ComparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+		IsHidden:       true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceTestCase.CodeSpaceID)
+	require.NotNil(t, codeSpaceTestCase.Stdin)
+	require.Equal(t, stdin, *codeSpaceTestCase.Stdin)
+	require.Equal(t, []string{"--sum"}, codeSpaceTestCase.Args)
+	require.Equal(t, "7", codeSpaceTestCase.ExpectedStdout)
+	require.Equal(t, api.CodeSpaceTestComparisonModeTrimmed, codeSpaceTestCase.ComparisonMode)
+	require.True(t, codeSpaceTestCase.IsHidden)
+	require.WithinDuration(t, now, codeSpaceTestCase.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, now, codeSpaceTestCase.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateCodeSpaceTestCaseError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	testcases := map[string]struct {
+		codeSpaceTestCase *code.CodeSpaceTestCase
+	}{
+		"Unknown comparison mode": {
+			codeSpaceTestCase: &code.CodeSpaceTestCase{
+				CodeSpaceID:    codeSpace.ID,
+				Args:           []string{},
+				ExpectedStdout: "7",
+				ComparisonMode: "fuzzy",
+			},
+		},
+		"Non-existent code space": {
+			codeSpaceTestCase: &code.CodeSpaceTestCase{
+				CodeSpaceID:    314159265,
+				Args:           []string{},
+				ExpectedStdout: "7",
+				ComparisonMode: api.CodeSpaceTestComparisonModeExact,
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.CreateCodeSpaceTestCase(context.Background(), dbConn, testcase.codeSpaceTestCase)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRepositoryListCodeSpaceTestCases(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	visibleTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"1",
+		api.CodeSpaceTestComparisonModeExact,
+		false,
+	)
+	hiddenTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"2",
+		api.CodeSpaceTestComparisonModeExact,
+		true,
+	)
+	testkitinternal.MustCreateCodeSpaceTestCase(t, otherCodeSpace.ID, "3", api.CodeSpaceTestComparisonModeExact, false)
+
+	testcases := map[string]struct {
+		includeHidden bool
+		wantIDs       []int64
+	}{
+		"Include hidden": {
+			includeHidden: true,
+			wantIDs:       []int64{visibleTestCase.ID, hiddenTestCase.ID},
+		},
+		"Exclude hidden": {
+			includeHidden: false,
+			wantIDs:       []int64{visibleTestCase.ID},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			codeSpaceTestCases, err := repo.ListCodeSpaceTestCases(
+				context.Background(),
+				dbConn,
+				codeSpace.ID,
+				testcase.includeHidden,
+			)
+			require.NoError(t, err)
+
+			ids := make([]int64, len(codeSpaceTestCases))
+			for i, codeSpaceTestCase := range codeSpaceTestCases {
+				require.Equal(t, codeSpace.ID, codeSpaceTestCase.CodeSpaceID)
+				ids[i] = codeSpaceTestCase.ID
+			}
+
+			require.Equal(t, testcase.wantIDs, ids)
+		})
+	}
+}
+
+func TestRepositoryUpdateCodeSpaceTestCaseSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"7",
+		api.CodeSpaceTestComparisonModeExact,
+		false,
+	)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	later := timeProvider.Now().Add(time.Minute)
+	timeProvider.SetTime(later)
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	newStdin := "5\n"
+	newArgs := []string{"--square"}
+	newExpectedStdout := `25\s*`
+	newComparisonMode := api.CodeSpaceTestComparisonModeRegex
+	isHidden := true
+
+	updatedCodeSpaceTestCase, err := repo.UpdateCodeSpaceTestCase(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		codeSpaceTestCase.ID,
+		&newStdin,
+		newArgs,
+		&newExpectedStdout,
+		&newComparisonMode,
+		&isHidden,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpaceTestCase.ID, updatedCodeSpaceTestCase.ID)
+	require.NotNil(t, updatedCodeSpaceTestCase.Stdin)
+	require.Equal(t, newStdin, *updatedCodeSpaceTestCase.Stdin)
+	require.Equal(t, newArgs, updatedCodeSpaceTestCase.Args)
+	require.Equal(t, newExpectedStdout, updatedCodeSpaceTestCase.ExpectedStdout)
+	require.Equal(t, newComparisonMode, updatedCodeSpaceTestCase.ComparisonMode)
+	require.True(t, updatedCodeSpaceTestCase.IsHidden)
+	require.WithinDuration(t, later, updatedCodeSpaceTestCase.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryUpdateCodeSpaceTestCaseError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"7",
+		api.CodeSpaceTestComparisonModeExact,
+		false,
+	)
+
+	newExpectedStdout := "8"
+
+	testcases := map[string]struct {
+		codeSpaceID         int64
+		codeSpaceTestCaseID int64
+		expectedStdout      *string
+	}{
+		"No attributes": {
+			codeSpaceID:         codeSpace.ID,
+			codeSpaceTestCaseID: codeSpaceTestCase.ID,
+			expectedStdout:      nil,
+		},
+		"Non-existent test case": {
+			codeSpaceID:         codeSpace.ID,
+			codeSpaceTestCaseID: 314159265,
+			expectedStdout:      &newExpectedStdout,
+		},
+		"Test case in another code space": {
+			codeSpaceID:         314159265,
+			codeSpaceTestCaseID: codeSpaceTestCase.ID,
+			expectedStdout:      &newExpectedStdout,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.UpdateCodeSpaceTestCase(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceTestCaseID,
+				nil,
+				nil,
+				testcase.expectedStdout,
+				nil,
+				nil,
+			)
+			require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+		})
+	}
+}
+
+func TestRepositoryDeleteCodeSpaceTestCaseSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"7",
+		api.CodeSpaceTestComparisonModeExact,
+		false,
+	)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceTestCase(context.Background(), dbConn, codeSpace.ID, codeSpaceTestCase.ID)
+	require.NoError(t, err)
+
+	codeSpaceTestCases, err := repo.ListCodeSpaceTestCases(context.Background(), dbConn, codeSpace.ID, true)
+	require.NoError(t, err)
+	require.Empty(t, codeSpaceTestCases)
+}
+
+func TestRepositoryDeleteCodeSpaceTestCaseNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceTestCase(context.Background(), dbConn, 314159265, 314159265)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateCodeSpaceRunConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	stdin := "3\n4\n"
+	runTimeout := int64(1000)
+	languageVersion := "3.9.4"
+
+	codeSpaceRunConfig, err := repo.CreateCodeSpaceRunConfig(context.Background(), dbConn, &code.CodeSpaceRunConfig{
+		CodeSpaceID:     codeSpace.ID,
+		Name:            "sum",
+		Stdin:           &stdin,
+		Args:            []string{"--sum"},
+		RunTimeout:      &runTimeout,
+		LanguageVersion: &languageVersion,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceRunConfig.CodeSpaceID)
+	require.Equal(t, "sum", codeSpaceRunConfig.Name)
+	require.NotNil(t, codeSpaceRunConfig.Stdin)
+	require.Equal(t, stdin, *codeSpaceRunConfig.Stdin)
+	require.Equal(t, []string{"--sum"}, codeSpaceRunConfig.Args)
+	require.Nil(t, codeSpaceRunConfig.CompileTimeout)
+	require.NotNil(t, codeSpaceRunConfig.RunTimeout)
+	require.Equal(t, runTimeout, *codeSpaceRunConfig.RunTimeout)
+	require.Nil(t, codeSpaceRunConfig.CompileMemoryLimit)
+	require.Nil(t, codeSpaceRunConfig.RunMemoryLimit)
+	require.NotNil(t, codeSpaceRunConfig.LanguageVersion)
+	require.Equal(t, languageVersion, *codeSpaceRunConfig.LanguageVersion)
+	require.WithinDuration(t, now, codeSpaceRunConfig.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, now, codeSpaceRunConfig.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateCodeSpaceRunConfigError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "sum", []string{"--sum"})
+
+	testcases := map[string]struct {
+		codeSpaceRunConfig *code.CodeSpaceRunConfig
+		wantErr            error
+	}{
+		"Duplicate name": {
+			codeSpaceRunConfig: &code.CodeSpaceRunConfig{
+				CodeSpaceID: codeSpace.ID,
+				Name:        "sum",
+				Args:        []string{},
+			},
+			wantErr: errutils.ErrDatabaseUniqueViolation,
+		},
+		"Non-existent code space": {
+			codeSpaceRunConfig: &code.CodeSpaceRunConfig{
+				CodeSpaceID: 314159265,
+				Name:        "sum",
+				Args:        []string{},
+			},
+			wantErr: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.CreateCodeSpaceRunConfig(context.Background(), dbConn, testcase.codeSpaceRunConfig)
+			require.Error(t, err)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepositoryListCodeSpaceRunConfigs(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	verboseRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+	quietRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "quiet", []string{"-q"})
+	testkitinternal.MustCreateCodeSpaceRunConfig(t, otherCodeSpace.ID, "debug", []string{"-d"})
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	codeSpaceRunConfigs, err := repo.ListCodeSpaceRunConfigs(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	ids := make([]int64, len(codeSpaceRunConfigs))
+	for i, codeSpaceRunConfig := range codeSpaceRunConfigs {
+		require.Equal(t, codeSpace.ID, codeSpaceRunConfig.CodeSpaceID)
+		ids[i] = codeSpaceRunConfig.ID
+	}
+
+	require.Equal(t, []int64{quietRunConfig.ID, verboseRunConfig.ID}, ids)
+}
+
+func TestRepositoryGetCodeSpaceRunConfigByName(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+
+	testcases := map[string]struct {
+		codeSpaceID int64
+		name        string
+		wantErr     error
+	}{
+		"Existing run config": {
+			codeSpaceID: codeSpace.ID,
+			name:        "verbose",
+			wantErr:     nil,
+		},
+		"Non-existent run config": {
+			codeSpaceID: codeSpace.ID,
+			name:        "quiet",
+			wantErr:     errutils.ErrDatabaseNoRowsReturned,
+		},
+		"Run config in another code space": {
+			codeSpaceID: otherCodeSpace.ID,
+			name:        "verbose",
+			wantErr:     errutils.ErrDatabaseNoRowsReturned,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			fetchedCodeSpaceRunConfig, err := repo.GetCodeSpaceRunConfigByName(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.name,
+			)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, codeSpaceRunConfig.ID, fetchedCodeSpaceRunConfig.ID)
+			require.Equal(t, codeSpaceRunConfig.Args, fetchedCodeSpaceRunConfig.Args)
+		})
+	}
+}
+
+func TestRepositoryUpdateCodeSpaceRunConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	later := timeProvider.Now().Add(time.Minute)
+	timeProvider.SetTime(later)
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	newName := "very-verbose"
+	newStdin := "5\n"
+	newArgs := []string{"-vv"}
+	newCompileTimeout := int64(5000)
+	newRunTimeout := int64(3000)
+	newCompileMemoryLimit := int64(128000000)
+	newRunMemoryLimit := int64(64000000)
+	newLanguageVersion := "3.9.4"
+
+	updatedCodeSpaceRunConfig, err := repo.UpdateCodeSpaceRunConfig(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		codeSpaceRunConfig.ID,
+		&newName,
+		&newStdin,
+		newArgs,
+		&newCompileTimeout,
+		&newRunTimeout,
+		&newCompileMemoryLimit,
+		&newRunMemoryLimit,
+		&newLanguageVersion,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpaceRunConfig.ID, updatedCodeSpaceRunConfig.ID)
+	require.Equal(t, newName, updatedCodeSpaceRunConfig.Name)
+	require.NotNil(t, updatedCodeSpaceRunConfig.Stdin)
+	require.Equal(t, newStdin, *updatedCodeSpaceRunConfig.Stdin)
+	require.Equal(t, newArgs, updatedCodeSpaceRunConfig.Args)
+	require.NotNil(t, updatedCodeSpaceRunConfig.CompileTimeout)
+	require.Equal(t, newCompileTimeout, *updatedCodeSpaceRunConfig.CompileTimeout)
+	require.NotNil(t, updatedCodeSpaceRunConfig.RunTimeout)
+	require.Equal(t, newRunTimeout, *updatedCodeSpaceRunConfig.RunTimeout)
+	require.NotNil(t, updatedCodeSpaceRunConfig.CompileMemoryLimit)
+	require.Equal(t, newCompileMemoryLimit, *updatedCodeSpaceRunConfig.CompileMemoryLimit)
+	require.NotNil(t, updatedCodeSpaceRunConfig.RunMemoryLimit)
+	require.Equal(t, newRunMemoryLimit, *updatedCodeSpaceRunConfig.RunMemoryLimit)
+	require.NotNil(t, updatedCodeSpaceRunConfig.LanguageVersion)
+	require.Equal(t, newLanguageVersion, *updatedCodeSpaceRunConfig.LanguageVersion)
+	require.WithinDuration(t, later, updatedCodeSpaceRunConfig.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryUpdateCodeSpaceRunConfigError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+	testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "quiet", []string{"-q"})
+
+	newName := "debug"
+	existingName := "quiet"
+
+	testcases := map[string]struct {
+		codeSpaceID          int64
+		codeSpaceRunConfigID int64
+		name                 *string
+		wantErr              error
+	}{
+		"No attributes": {
+			codeSpaceID:          codeSpace.ID,
+			codeSpaceRunConfigID: codeSpaceRunConfig.ID,
+			name:                 nil,
+			wantErr:              errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Non-existent run config": {
+			codeSpaceID:          codeSpace.ID,
+			codeSpaceRunConfigID: 314159265,
+			name:                 &newName,
+			wantErr:              errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Run config in another code space": {
+			codeSpaceID:          314159265,
+			codeSpaceRunConfigID: codeSpaceRunConfig.ID,
+			name:                 &newName,
+			wantErr:              errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Duplicate name": {
+			codeSpaceID:          codeSpace.ID,
+			codeSpaceRunConfigID: codeSpaceRunConfig.ID,
+			name:                 &existingName,
+			wantErr:              errutils.ErrDatabaseUniqueViolation,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.UpdateCodeSpaceRunConfig(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceRunConfigID,
+				testcase.name,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+			)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestRepositoryDeleteCodeSpaceRunConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceRunConfig(context.Background(), dbConn, codeSpace.ID, codeSpaceRunConfig.ID)
+	require.NoError(t, err)
+
+	codeSpaceRunConfigs, err := repo.ListCodeSpaceRunConfigs(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Empty(t, codeSpaceRunConfigs)
+}
+
+func TestRepositoryDeleteCodeSpaceRunConfigNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceRunConfig(context.Background(), dbConn, 314159265, 314159265)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateCodeSpaceEnvVarSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	now := timeProvider.Now()
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	value := "Gryffindor"
+
+	codeSpaceEnvVar, err := repo.CreateCodeSpaceEnvVar(context.Background(), dbConn, &code.CodeSpaceEnvVar{
+		CodeSpaceID: codeSpace.ID,
+		Name:        "HOUSE",
+		Value:       &value,
+		IsSecret:    true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceEnvVar.CodeSpaceID)
+	require.Equal(t, "HOUSE", codeSpaceEnvVar.Name)
+	require.NotNil(t, codeSpaceEnvVar.Value)
+	require.Equal(t, value, *codeSpaceEnvVar.Value)
+	require.True(t, codeSpaceEnvVar.IsSecret)
+	require.WithinDuration(t, now, codeSpaceEnvVar.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, now, codeSpaceEnvVar.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryCreateCodeSpaceEnvVarError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+
+	value := "Slytherin"
+
+	testcases := map[string]struct {
+		codeSpaceEnvVar *code.CodeSpaceEnvVar
+		wantErr         error
+	}{
+		"Duplicate name": {
+			codeSpaceEnvVar: &code.CodeSpaceEnvVar{
+				CodeSpaceID: codeSpace.ID,
+				Name:        "HOUSE",
+				Value:       &value,
+			},
+			wantErr: errutils.ErrDatabaseUniqueViolation,
+		},
+		"Non-existent code space": {
+			codeSpaceEnvVar: &code.CodeSpaceEnvVar{
+				CodeSpaceID: 314159265,
+				Name:        "HOUSE",
+				Value:       &value,
+			},
+			wantErr: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.CreateCodeSpaceEnvVar(context.Background(), dbConn, testcase.codeSpaceEnvVar)
+			require.Error(t, err)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepositoryListCodeSpaceEnvVars(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	passwordEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "PASSWORD", "Caput Draconis", true)
+	houseEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+	testkitinternal.MustCreateCodeSpaceEnvVar(t, otherCodeSpace.ID, "HOUSE", "Slytherin", false)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	codeSpaceEnvVars, err := repo.ListCodeSpaceEnvVars(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	ids := make([]int64, len(codeSpaceEnvVars))
+	for i, codeSpaceEnvVar := range codeSpaceEnvVars {
+		require.Equal(t, codeSpace.ID, codeSpaceEnvVar.CodeSpaceID)
+		ids[i] = codeSpaceEnvVar.ID
+	}
+
+	require.Equal(t, []int64{houseEnvVar.ID, passwordEnvVar.ID}, ids)
+	require.Equal(t, passwordEnvVar.Value, codeSpaceEnvVars[1].Value)
+}
+
+func TestRepositoryGetCodeSpaceEnvVar(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+
+	testcases := map[string]struct {
+		codeSpaceID       int64
+		codeSpaceEnvVarID int64
+		wantErr           error
+	}{
+		"Existing env var": {
+			codeSpaceID:       codeSpace.ID,
+			codeSpaceEnvVarID: codeSpaceEnvVar.ID,
+			wantErr:           nil,
+		},
+		"Non-existent env var": {
+			codeSpaceID:       codeSpace.ID,
+			codeSpaceEnvVarID: 314159265,
+			wantErr:           errutils.ErrDatabaseNoRowsReturned,
+		},
+		"Env var in another code space": {
+			codeSpaceID:       otherCodeSpace.ID,
+			codeSpaceEnvVarID: codeSpaceEnvVar.ID,
+			wantErr:           errutils.ErrDatabaseNoRowsReturned,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			fetchedCodeSpaceEnvVar, err := repo.GetCodeSpaceEnvVar(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceEnvVarID,
+			)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, codeSpaceEnvVar.ID, fetchedCodeSpaceEnvVar.ID)
+			require.Equal(t, codeSpaceEnvVar.Name, fetchedCodeSpaceEnvVar.Name)
+			require.Equal(t, codeSpaceEnvVar.Value, fetchedCodeSpaceEnvVar.Value)
+		})
+	}
+}
+
+func TestRepositoryUpdateCodeSpaceEnvVarSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	later := timeProvider.Now().Add(time.Minute)
+	timeProvider.SetTime(later)
+	repo := code.NewRepository(timeProvider)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	newName := "PASSWORD"
+	newValue := "Caput Draconis"
+	newIsSecret := true
+
+	updatedCodeSpaceEnvVar, err := repo.UpdateCodeSpaceEnvVar(
+		context.Background(),
+		dbConn,
+		codeSpace.ID,
+		codeSpaceEnvVar.ID,
+		&newName,
+		&newValue,
+		&newIsSecret,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpaceEnvVar.ID, updatedCodeSpaceEnvVar.ID)
+	require.Equal(t, newName, updatedCodeSpaceEnvVar.Name)
+	require.NotNil(t, updatedCodeSpaceEnvVar.Value)
+	require.Equal(t, newValue, *updatedCodeSpaceEnvVar.Value)
+	require.True(t, updatedCodeSpaceEnvVar.IsSecret)
+	require.WithinDuration(t, later, updatedCodeSpaceEnvVar.UpdatedAt, testkit.TimeToleranceExact)
+}
+
+func TestRepositoryUpdateCodeSpaceEnvVarError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+	testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "PATRONUS", "Stag", false)
+
+	newName := "WAND"
+	existingName := "PATRONUS"
+
+	testcases := map[string]struct {
+		codeSpaceID       int64
+		codeSpaceEnvVarID int64
+		name              *string
+		wantErr           error
+	}{
+		"No attributes": {
+			codeSpaceID:       codeSpace.ID,
+			codeSpaceEnvVarID: codeSpaceEnvVar.ID,
+			name:              nil,
+			wantErr:           errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Non-existent env var": {
+			codeSpaceID:       codeSpace.ID,
+			codeSpaceEnvVarID: 314159265,
+			name:              &newName,
+			wantErr:           errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Env var in another code space": {
+			codeSpaceID:       314159265,
+			codeSpaceEnvVarID: codeSpaceEnvVar.ID,
+			name:              &newName,
+			wantErr:           errutils.ErrDatabaseNoRowsAffected,
+		},
+		"Duplicate name": {
+			codeSpaceID:       codeSpace.ID,
+			codeSpaceEnvVarID: codeSpaceEnvVar.ID,
+			name:              &existingName,
+			wantErr:           errutils.ErrDatabaseUniqueViolation,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+			_, err = repo.UpdateCodeSpaceEnvVar(
+				context.Background(),
+				dbConn,
+				testcase.codeSpaceID,
+				testcase.codeSpaceEnvVarID,
+				testcase.name,
+				nil,
+				nil,
+			)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestRepositoryDeleteCodeSpaceEnvVarSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceEnvVar(context.Background(), dbConn, codeSpace.ID, codeSpaceEnvVar.ID)
+	require.NoError(t, err)
+
+	codeSpaceEnvVars, err := repo.ListCodeSpaceEnvVars(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+	require.Empty(t, codeSpaceEnvVars)
+}
+
+func TestRepositoryDeleteCodeSpaceEnvVarNoRowsAffected(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timekeeper.NewFrozenProvider())
+
+	err = repo.DeleteCodeSpaceEnvVar(context.Background(), dbConn, 314159265, 314159265)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsAffected)
+}
+
+func TestRepositoryCreateOrUpdateExecutionCacheEntry(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	key := testkit.MustGenerateRandomString(64, true, false, true)
+	expiresAt := timeProvider.Now().Add(time.Hour)
+
+	createdEntry, err := repo.CreateOrUpdateExecutionCacheEntry(context.Background(), dbConn, &code.ExecutionCacheEntry{
+		Key: key,
+		Response: &api.PistonExecuteResponse{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Run: api.PistonResults{
+				Stdout: "Yello!\n",
+			},
+		},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, createdEntry.Key)
+	require.Equal(t, "Yello!\n", createdEntry.Response.Run.Stdout)
+	require.WithinDuration(t, expiresAt, createdEntry.ExpiresAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, timeProvider.Now(), createdEntry.CreatedAt, testkit.TimeToleranceExact)
+	require.WithinDuration(t, timeProvider.Now(), createdEntry.UpdatedAt, testkit.TimeToleranceExact)
+
+	updatedEntry, err := repo.CreateOrUpdateExecutionCacheEntry(context.Background(), dbConn, &code.ExecutionCacheEntry{
+		Key: key,
+		Response: &api.PistonExecuteResponse{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Run: api.PistonResults{
+				Stdout: "Yello again!\n",
+			},
+		},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, updatedEntry.Key)
+	require.Equal(t, "Yello again!\n", updatedEntry.Response.Run.Stdout)
+
+	fetchedEntry, err := repo.GetExecutionCacheEntry(context.Background(), dbConn, key)
+	require.NoError(t, err)
+	require.Equal(t, updatedEntry.Response, fetchedEntry.Response)
+}
+
+func TestRepositoryGetExecutionCacheEntryError(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	expiredKey := testkit.MustGenerateRandomString(64, true, false, true)
+	_, err = repo.CreateOrUpdateExecutionCacheEntry(context.Background(), dbConn, &code.ExecutionCacheEntry{
+		Key:       expiredKey,
+		Response:  &api.PistonExecuteResponse{},
+		ExpiresAt: timeProvider.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	testcases := map[string]struct {
+		key string
+	}{
+		"Non-existent entry": {
+			key: testkit.MustGenerateRandomString(64, true, false, true),
+		},
+		"Expired entry": {
+			key: expiredKey,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			_, err = repo.GetExecutionCacheEntry(context.Background(), dbConn, testcase.key)
+			require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+		})
+	}
+}
+
+func TestRepositoryDeleteStaleExecutionCacheEntries(t *testing.T) {
+	t.Parallel()
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	repo := code.NewRepository(timeProvider)
+
+	expiredKey := testkit.MustGenerateRandomString(64, true, false, true)
+	_, err = repo.CreateOrUpdateExecutionCacheEntry(context.Background(), dbConn, &code.ExecutionCacheEntry{
+		Key:       expiredKey,
+		Response:  &api.PistonExecuteResponse{},
+		ExpiresAt: timeProvider.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	freshKey := testkit.MustGenerateRandomString(64, true, false, true)
+	_, err = repo.CreateOrUpdateExecutionCacheEntry(context.Background(), dbConn, &code.ExecutionCacheEntry{
+		Key:       freshKey,
+		Response:  &api.PistonExecuteResponse{},
+		ExpiresAt: timeProvider.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	err = repo.DeleteStaleExecutionCacheEntries(context.Background(), dbConn, 1000000)
+	require.NoError(t, err)
+
+	var count int
+	err = dbConn.QueryRow(
+		context.Background(),
+		"SELECT COUNT(*) FROM execution_cache WHERE key = $1;",
+		expiredKey,
+	).Scan(&count)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	_, err = repo.GetExecutionCacheEntry(context.Background(), dbConn, freshKey)
+	require.NoError(t, err)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/config"
@@ -13,6 +16,7 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/cryptocore"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/logging"
 	"github.com/alvii147/nymphadora-api/pkg/mailclient"
 	"github.com/alvii147/nymphadora-api/pkg/piston"
 	"github.com/alvii147/nymphadora-api/pkg/random"
@@ -22,13 +26,30 @@ import (
 // FrontendCodeSpaceInvitationRoute is the frontend route for code space invitation.
 const FrontendCodeSpaceInvitationRoute = "/code/space/%s/invitation/%s"
 
+// CodeSpaceNameGenerationMaxAttempts is the maximum number of random names tried when creating a code space,
+// before giving up on names that are already taken.
+const CodeSpaceNameGenerationMaxAttempts = 5
+
+// CodeSpaceCollabSaveMaxAttempts is the maximum number of times a collaboratively edited code space is saved
+// in one go, when it keeps being saved outside of collaborative editing in the meantime.
+const CodeSpaceCollabSaveMaxAttempts = 2
+
 // Service performs all code-space-related business logic.
 //
 //go:generate mockgen -package=codemocks -source=$GOFILE -destination=./mocks/service.go
 type Service interface {
+	ListCodingLanguages(
+		ctx context.Context,
+	) ([]*CodingLanguage, error)
 	CreateCodeSpace(
 		ctx context.Context,
+		name *string,
 		language string,
+		languageVersion *string,
+	) (*CodeSpace, *CodeSpaceAccess, error)
+	ForkCodeSpace(
+		ctx context.Context,
+		name string,
 	) (*CodeSpace, *CodeSpaceAccess, error)
 	ListCodeSpaces(
 		ctx context.Context,
@@ -40,16 +61,233 @@ type Service interface {
 	UpdateCodeSpace(
 		ctx context.Context,
 		name string,
+		newName *string,
 		contents *string,
+		languageVersion *string,
+		version *int64,
 	) (*CodeSpace, *CodeSpaceAccess, error)
 	DeleteCodeSpace(
 		ctx context.Context,
 		name string,
 	) error
+	ListTrashedCodeSpaces(
+		ctx context.Context,
+	) ([]*CodeSpace, []*CodeSpaceAccess, error)
+	RestoreCodeSpace(
+		ctx context.Context,
+		name string,
+	) (*CodeSpace, *CodeSpaceAccess, error)
+	PurgeTrashedCodeSpaces(
+		ctx context.Context,
+	) (int64, error)
+	PurgeTrashedCodeSpacesEvery(
+		interval time.Duration,
+		onError func(err error),
+	) func()
+	ListCodeSpaceRevisions(
+		ctx context.Context,
+		name string,
+		limit int64,
+		offset int64,
+	) ([]*CodeSpaceRevision, error)
+	GetCodeSpaceRevision(
+		ctx context.Context,
+		name string,
+		codeSpaceRevisionID int64,
+	) (*CodeSpaceRevision, error)
+	DiffCodeSpaceRevisions(
+		ctx context.Context,
+		name string,
+		fromCodeSpaceRevisionID int64,
+		toCodeSpaceRevisionID int64,
+	) (string, error)
+	RestoreCodeSpaceRevision(
+		ctx context.Context,
+		name string,
+		codeSpaceRevisionID int64,
+		version *int64,
+	) (*CodeSpace, *CodeSpaceAccess, error)
+	ThinCodeSpaceRevisions(
+		ctx context.Context,
+	) (int64, error)
+	ThinCodeSpaceRevisionsEvery(
+		interval time.Duration,
+		onError func(err error),
+	) func()
+	JoinCodeSpaceCollaboration(
+		ctx context.Context,
+		name string,
+	) (*CodeSpaceCollaborator, error)
+	ListCodeSpacePresence(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpacePresence, error)
+	SaveCodeSpaceCollaborations(
+		ctx context.Context,
+	) (int64, error)
+	SaveCodeSpaceCollaborationsEvery(
+		interval time.Duration,
+		onError func(err error),
+	) func()
 	RunCodeSpace(
 		ctx context.Context,
 		name string,
-	) (*api.PistonExecuteResponse, error)
+		opts *RunCodeSpaceOptions,
+	) (*CodeSpaceRun, error)
+	RunCodeSpaceMatrix(
+		ctx context.Context,
+		name string,
+		versions []string,
+		opts *RunCodeSpaceOptions,
+	) ([]*CodeSpaceRun, error)
+	BenchmarkCodeSpace(
+		ctx context.Context,
+		name string,
+		iterations int64,
+		opts *RunCodeSpaceOptions,
+	) (*CodeSpaceBenchmark, error)
+	RunCodeSpaceInteractively(
+		ctx context.Context,
+		name string,
+		opts *RunCodeSpaceOptions,
+		interact func(session piston.Session) error,
+	) (*CodeSpaceRun, error)
+	StartCodeSpaceRun(
+		ctx context.Context,
+		name string,
+		opts *RunCodeSpaceOptions,
+	) (*CodeSpaceRun, error)
+	StreamCodeSpaceRun(
+		ctx context.Context,
+		name string,
+		opts *RunCodeSpaceOptions,
+		onEvent func(event *api.PistonEvent),
+	) (*CodeSpaceRun, error)
+	ListCodeSpaceRuns(
+		ctx context.Context,
+		name string,
+		limit int64,
+		offset int64,
+	) ([]*CodeSpaceRun, error)
+	GetCodeSpaceRun(
+		ctx context.Context,
+		name string,
+		codeSpaceRunID int64,
+	) (*CodeSpaceRun, error)
+	ListCodeSpaceFiles(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpaceFile, error)
+	CreateCodeSpaceFile(
+		ctx context.Context,
+		name string,
+		fileName string,
+		contents string,
+		isEntryPoint bool,
+	) (*CodeSpaceFile, error)
+	UpdateCodeSpaceFile(
+		ctx context.Context,
+		name string,
+		codeSpaceFileID int64,
+		fileName *string,
+		contents *string,
+		isEntryPoint *bool,
+	) (*CodeSpaceFile, error)
+	DeleteCodeSpaceFile(
+		ctx context.Context,
+		name string,
+		codeSpaceFileID int64,
+	) error
+	ListCodeSpaceTestCases(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpaceTestCase, error)
+	CreateCodeSpaceTestCase(
+		ctx context.Context,
+		name string,
+		stdin *string,
+		args []string,
+		expectedStdout string,
+		comparisonMode string,
+		isHidden bool,
+	) (*CodeSpaceTestCase, error)
+	UpdateCodeSpaceTestCase(
+		ctx context.Context,
+		name string,
+		codeSpaceTestCaseID int64,
+		stdin *string,
+		args []string,
+		expectedStdout *string,
+		comparisonMode *string,
+		isHidden *bool,
+	) (*CodeSpaceTestCase, error)
+	DeleteCodeSpaceTestCase(
+		ctx context.Context,
+		name string,
+		codeSpaceTestCaseID int64,
+	) error
+	ListCodeSpaceRunConfigs(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpaceRunConfig, error)
+	CreateCodeSpaceRunConfig(
+		ctx context.Context,
+		name string,
+		configName string,
+		stdin *string,
+		args []string,
+		compileTimeout *int64,
+		runTimeout *int64,
+		compileMemoryLimit *int64,
+		runMemoryLimit *int64,
+		languageVersion *string,
+	) (*CodeSpaceRunConfig, error)
+	UpdateCodeSpaceRunConfig(
+		ctx context.Context,
+		name string,
+		codeSpaceRunConfigID int64,
+		configName *string,
+		stdin *string,
+		args []string,
+		compileTimeout *int64,
+		runTimeout *int64,
+		compileMemoryLimit *int64,
+		runMemoryLimit *int64,
+		languageVersion *string,
+	) (*CodeSpaceRunConfig, error)
+	DeleteCodeSpaceRunConfig(
+		ctx context.Context,
+		name string,
+		codeSpaceRunConfigID int64,
+	) error
+	ListCodeSpaceEnvVars(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpaceEnvVar, error)
+	CreateCodeSpaceEnvVar(
+		ctx context.Context,
+		name string,
+		envVarName string,
+		value string,
+		isSecret bool,
+	) (*CodeSpaceEnvVar, error)
+	UpdateCodeSpaceEnvVar(
+		ctx context.Context,
+		name string,
+		codeSpaceEnvVarID int64,
+		envVarName *string,
+		value *string,
+		isSecret *bool,
+	) (*CodeSpaceEnvVar, error)
+	DeleteCodeSpaceEnvVar(
+		ctx context.Context,
+		name string,
+		codeSpaceEnvVarID int64,
+	) error
+	RunCodeSpaceTests(
+		ctx context.Context,
+		name string,
+	) ([]*CodeSpaceTestResult, error)
 	ListCodeSpaceUsers(
 		ctx context.Context,
 		name string,
@@ -75,19 +313,25 @@ type Service interface {
 		name string,
 		codeSpaceUserUUID string,
 	) error
+	Close()
 }
 
 // service implements Service.
+// Execution results are not cached when executionCache is nil.
 type service struct {
 	config         *config.Config
 	timeProvider   timekeeper.Provider
 	dbPool         database.Pool
+	logger         logging.Logger
 	crypto         cryptocore.Crypto
 	mailClient     mailclient.Client
 	tmplManager    templatesmanager.Manager
 	pistonClient   piston.Client
+	executionCache piston.Cache
 	repository     Repository
 	authRepository auth.Repository
+	collab         *codeSpaceCollab
+	backgroundRuns sync.WaitGroup
 }
 
 // NewService returns a new service.
@@ -95,10 +339,12 @@ func NewService(
 	config *config.Config,
 	timeProvider timekeeper.Provider,
 	dbPool database.Pool,
+	logger logging.Logger,
 	crypto cryptocore.Crypto,
 	mailClient mailclient.Client,
 	tmplManager templatesmanager.Manager,
 	pistonClient piston.Client,
+	executionCache piston.Cache,
 	repo Repository,
 	authRepository auth.Repository,
 ) *service {
@@ -106,202 +352,3472 @@ func NewService(
 		config:         config,
 		timeProvider:   timeProvider,
 		dbPool:         dbPool,
+		logger:         logger,
 		crypto:         crypto,
 		mailClient:     mailClient,
 		tmplManager:    tmplManager,
 		pistonClient:   pistonClient,
+		executionCache: executionCache,
 		repository:     repo,
 		authRepository: authRepository,
+		collab:         newCodeSpaceCollab(timeProvider),
+	}
+}
+
+// GenerateCodeSpaceName generates a random name for a code space.
+func (svc *service) GenerateCodeSpaceName() (string, error) {
+	adjectiveIdx, err := random.GenerateInt64(int64(len(Adjectives)))
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	pokemonIdx, err := random.GenerateInt64(int64(len(Pokemon)))
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	programmingTermIdx, err := random.GenerateInt64(int64(len(ProgrammingTerms)))
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	harryPotterCharacterIdx, err := random.GenerateInt64(int64(len(HarryPotterCharacters)))
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	fileExtensionIdx, err := random.GenerateInt64(int64(len(FileExtensions)))
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	name := strings.Join(
+		[]string{
+			Adjectives[adjectiveIdx],
+			Pokemon[pokemonIdx],
+			ProgrammingTerms[programmingTermIdx],
+			HarryPotterCharacters[harryPotterCharacterIdx],
+			FileExtensions[fileExtensionIdx],
+		},
+		"-",
+	)
+
+	return name, nil
+}
+
+// ListCodingLanguages lists the coding languages supported by the runtimes installed on Piston.
+// Languages are sorted by name and their versions are sorted from newest to oldest.
+func (svc *service) ListCodingLanguages(
+	ctx context.Context,
+) ([]*CodingLanguage, error) {
+	runtimes, err := svc.pistonClient.Runtimes(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	codingLanguages := make([]*CodingLanguage, 0)
+	codingLanguagesByName := make(map[string]*CodingLanguage)
+	for _, runtime := range runtimes {
+		codingLanguage, ok := codingLanguagesByName[runtime.Language]
+		if !ok {
+			codingLanguage = &CodingLanguage{
+				Name:     runtime.Language,
+				Versions: []string{},
+				Aliases:  []string{},
+			}
+			codingLanguagesByName[runtime.Language] = codingLanguage
+			codingLanguages = append(codingLanguages, codingLanguage)
+		}
+
+		codingLanguage.Versions = append(codingLanguage.Versions, runtime.Version)
+		for _, alias := range runtime.Aliases {
+			if !slices.Contains(codingLanguage.Aliases, alias) {
+				codingLanguage.Aliases = append(codingLanguage.Aliases, alias)
+			}
+		}
+	}
+
+	slices.SortFunc(codingLanguages, func(a *CodingLanguage, b *CodingLanguage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, codingLanguage := range codingLanguages {
+		slices.SortFunc(codingLanguage.Versions, func(a string, b string) int {
+			return piston.CompareVersions(b, a)
+		})
+	}
+
+	return codingLanguages, nil
+}
+
+// CreateCodeSpace creates a new code space.
+// The code space runs on the latest installed version of its language unless languageVersion is given.
+// A random name is generated for the code space unless name is given.
+func (svc *service) CreateCodeSpace(
+	ctx context.Context,
+	name *string,
+	language string,
+	languageVersion *string,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	runtimes, err := svc.pistonClient.Runtimes(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	runtime := piston.FindRuntime(runtimes, language)
+	if runtime == nil {
+		return nil, nil, errutils.FormatErrorf(errutils.ErrCodeSpaceUnsupportedLanguage, "unknown language %s", language)
+	}
+
+	// language may be an alias, so code spaces are always created with the runtime's language name
+	language = runtime.Language
+
+	if languageVersion != nil && piston.FindRuntimeVersion(runtimes, language, *languageVersion) == nil {
+		return nil, nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceUnsupportedVersion,
+			"unknown version %s for language %s",
+			*languageVersion,
+			language,
+		)
+	}
+
+	var templateFileBytes []byte
+	languageConfig, ok := CodingLanguageConfig[language]
+	if ok {
+		templateFilePath := fmt.Sprintf("_codetemplates/%s/%s", language, languageConfig.fileName)
+		templateFileBytes, err = CodeTemplatesFS.ReadFile(templateFilePath)
+		if err != nil {
+			return nil, nil, errutils.FormatErrorf(nil, "codeTemplatesFS.ReadFile failed to read %s", templateFilePath)
+		}
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	for attempt := 1; ; attempt++ {
+		codeSpaceName := ""
+		if name != nil {
+			codeSpaceName = *name
+		} else {
+			codeSpaceName, err = svc.GenerateCodeSpaceName()
+			if err != nil {
+				return nil, nil, errutils.FormatError(err)
+			}
+		}
+
+		codeSpace, codeSpaceAccess, err := svc.createCodeSpace(ctx, dbConn, &CodeSpace{
+			Name:            codeSpaceName,
+			AuthorUUID:      &userUUID,
+			Language:        language,
+			LanguageVersion: languageVersion,
+			Contents:        string(templateFileBytes),
+		}, nil)
+
+		// generated names may collide with existing ones, in which case another name is tried
+		retryable := name == nil && attempt < CodeSpaceNameGenerationMaxAttempts
+		if retryable && errors.Is(err, errutils.ErrCodeSpaceAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		return codeSpace, codeSpaceAccess, nil
+	}
+}
+
+// createCodeSpace creates a given code space and its files with read-write access for its author,
+// along with the first revision of its contents.
+// Names that are taken by other code spaces or their aliases are rejected,
+// which the database enforces by keeping code space names and aliases in the table "code_space_name".
+func (svc *service) createCodeSpace(
+	ctx context.Context,
+	dbConn database.Conn,
+	codeSpace *CodeSpace,
+	codeSpaceFiles []*CodeSpaceFile,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	authorUUID := *codeSpace.AuthorUUID
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	_, err = svc.repository.GetCodeSpaceAliasByName(ctx, dbTx, codeSpace.Name)
+	if err == nil {
+		return nil, nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceAlreadyExists,
+			"%s is an alias of another code space",
+			codeSpace.Name,
+		)
+	}
+
+	if !errors.Is(err, errutils.ErrDatabaseNoRowsReturned) {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	codeSpace, err = svc.repository.CreateCodeSpace(ctx, dbTx, codeSpace)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	codeSpaceAccess := &CodeSpaceAccess{
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       CodeSpaceAccessLevelReadWrite,
+	}
+
+	codeSpaceAccess, err = svc.repository.CreateOrUpdateCodeSpaceAccess(ctx, dbTx, codeSpaceAccess)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	err = svc.createCodeSpaceRevision(ctx, dbTx, codeSpace, authorUUID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	for _, codeSpaceFile := range codeSpaceFiles {
+		_, err = svc.repository.CreateCodeSpaceFile(ctx, dbTx, &CodeSpaceFile{
+			CodeSpaceID:  codeSpace.ID,
+			Name:         codeSpaceFile.Name,
+			Contents:     codeSpaceFile.Contents,
+			IsEntryPoint: codeSpaceFile.IsEntryPoint,
+		})
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpace, codeSpaceAccess, nil
+}
+
+// ForkCodeSpace creates a copy of a given code space owned by the currently authenticated user.
+// The fork keeps the contents, language and files of its source, but not its collaborators,
+// test cases, run configurations or environment variables.
+func (svc *service) ForkCodeSpace(
+	ctx context.Context,
+	name string,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	sourceCodeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	sourceCodeSpaceFiles, err := svc.repository.ListCodeSpaceFiles(ctx, dbConn, sourceCodeSpace.ID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		codeSpaceName, err := svc.GenerateCodeSpaceName()
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		codeSpace, codeSpaceAccess, err := svc.createCodeSpace(ctx, dbConn, &CodeSpace{
+			Name:            codeSpaceName,
+			AuthorUUID:      &userUUID,
+			Language:        sourceCodeSpace.Language,
+			LanguageVersion: sourceCodeSpace.LanguageVersion,
+			Contents:        sourceCodeSpace.Contents,
+			ForkedFromID:    &sourceCodeSpace.ID,
+		}, sourceCodeSpaceFiles)
+
+		// generated names may collide with existing ones, in which case another name is tried
+		retryable := attempt < CodeSpaceNameGenerationMaxAttempts
+		if retryable && errors.Is(err, errutils.ErrCodeSpaceAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		return codeSpace, codeSpaceAccess, nil
+	}
+}
+
+// ListCodeSpaces lists all code spaces accessible to the currently authenticated user.
+func (svc *service) ListCodeSpaces(
+	ctx context.Context,
+) ([]*CodeSpace, []*CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpaces, codeSpaceAccesses, err := svc.repository.ListCodeSpaces(ctx, dbConn, userUUID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	return codeSpaces, codeSpaceAccesses, nil
+}
+
+// GetCodeSpace gets a given code space for the currently authenticated user.
+func (svc *service) GetCodeSpace(
+	ctx context.Context,
+	name string,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	return codeSpace, codeSpaceAccess, nil
+}
+
+// UpdateCodeSpace updates a given code space.
+// An empty languageVersion unpins the code space so it runs on the latest installed version of its language.
+// Renamed code spaces keep their previous name as an alias, so links to the previous name still resolve.
+// If version is not nil, the code space is only updated if it is still at the given version,
+// otherwise a CodeSpaceVersionConflictError holding the current state of the code space is returned.
+// Collaborators editing the code space are sent the update as an edit, on top of their unsaved edits.
+func (svc *service) UpdateCodeSpace(
+	ctx context.Context,
+	name string,
+	newName *string,
+	contents *string,
+	languageVersion *string,
+	version *int64,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	if version != nil && *version != codeSpace.Version {
+		return nil, nil, errutils.FormatError(&CodeSpaceVersionConflictError{
+			CodeSpace:       codeSpace,
+			CodeSpaceAccess: codeSpaceAccess,
+		})
+	}
+
+	if languageVersion != nil && *languageVersion != "" {
+		runtimes, err := svc.pistonClient.Runtimes(ctx)
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		if piston.FindRuntimeVersion(runtimes, codeSpace.Language, *languageVersion) == nil {
+			return nil, nil, errutils.FormatErrorf(
+				errutils.ErrCodeSpaceUnsupportedVersion,
+				"unknown version %s for language %s",
+				*languageVersion,
+				codeSpace.Language,
+			)
+		}
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	if newName != nil && *newName != codeSpace.Name {
+		codeSpaceID := codeSpace.ID
+		codeSpace, err = svc.renameCodeSpace(ctx, dbTx, codeSpace, *newName, version)
+		if version != nil && errors.Is(err, errutils.ErrDatabaseNoRowsAffected) {
+			return nil, nil, svc.codeSpaceVersionConflict(ctx, dbTx, codeSpaceID, codeSpaceAccess)
+		}
+
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+
+		// The renamed code space stays locked until the transaction ends,
+		// so it no longer needs to be checked against the given version.
+		version = nil
+	}
+
+	if newName == nil || contents != nil || languageVersion != nil {
+		codeSpaceID := codeSpace.ID
+		codeSpace, err = svc.repository.UpdateCodeSpace(
+			ctx,
+			dbTx,
+			codeSpace.ID,
+			contents,
+			languageVersion,
+			version,
+		)
+		if version != nil && (contents != nil || languageVersion != nil) &&
+			errors.Is(err, errutils.ErrDatabaseNoRowsAffected) {
+			return nil, nil, svc.codeSpaceVersionConflict(ctx, dbTx, codeSpaceID, codeSpaceAccess)
+		}
+
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+	}
+
+	if contents != nil {
+		err = svc.createCodeSpaceRevision(ctx, dbTx, codeSpace, userUUID)
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	svc.collab.rebase(codeSpace, userUUID)
+
+	return codeSpace, codeSpaceAccess, nil
+}
+
+// codeSpaceVersionConflict returns the error for a given code space that could not be updated
+// because it is no longer at the expected version, holding the current state of the code space.
+func (svc *service) codeSpaceVersionConflict(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceAccess *CodeSpaceAccess,
+) error {
+	codeSpace, err := svc.repository.GetCodeSpace(ctx, querier, codeSpaceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	return errutils.FormatError(&CodeSpaceVersionConflictError{
+		CodeSpace:       codeSpace,
+		CodeSpaceAccess: codeSpaceAccess,
+	})
+}
+
+// renameCodeSpace renames a given code space and keeps its previous name as an alias.
+// Names that are taken by other code spaces or their aliases are rejected,
+// while the code space's own aliases may be reclaimed as its name.
+// If version is not nil, the code space is only renamed if it is still at the given version.
+func (svc *service) renameCodeSpace(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpace *CodeSpace,
+	newName string,
+	version *int64,
+) (*CodeSpace, error) {
+	codeSpaceAlias, err := svc.repository.GetCodeSpaceAliasByName(ctx, querier, newName)
+	switch {
+	case err == nil && codeSpaceAlias.CodeSpaceID != codeSpace.ID:
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceAlreadyExists,
+			"%s is an alias of another code space",
+			newName,
+		)
+	case err == nil:
+		err = svc.repository.DeleteCodeSpaceAlias(ctx, querier, codeSpaceAlias.ID)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	case !errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+		return nil, errutils.FormatError(err)
+	}
+
+	renamedCodeSpace, err := svc.repository.RenameCodeSpace(ctx, querier, codeSpace.ID, newName, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	_, err = svc.repository.CreateCodeSpaceAlias(ctx, querier, &CodeSpaceAlias{
+		CodeSpaceID: codeSpace.ID,
+		Name:        codeSpace.Name,
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return renamedCodeSpace, nil
+}
+
+// DeleteCodeSpace moves a given code space to the trash,
+// from which it can be restored until it is purged.
+func (svc *service) DeleteCodeSpace(
+	ctx context.Context,
+	name string,
+) error {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	err = svc.repository.TrashCodeSpace(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// ListTrashedCodeSpaces lists code spaces in the trash that the currently authenticated user can restore.
+func (svc *service) ListTrashedCodeSpaces(
+	ctx context.Context,
+) ([]*CodeSpace, []*CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpaces, codeSpaceAccesses, err := svc.repository.ListTrashedCodeSpaces(ctx, dbConn, userUUID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	return codeSpaces, codeSpaceAccesses, nil
+}
+
+// RestoreCodeSpace moves a given code space out of the trash.
+func (svc *service) RestoreCodeSpace(
+	ctx context.Context,
+	name string,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetTrashedCodeSpaceWithAccessByName(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	restoredCodeSpace, err := svc.repository.RestoreCodeSpace(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	return restoredCodeSpace, codeSpaceAccess, nil
+}
+
+// PurgeTrashedCodeSpaces deletes code spaces that have been in the trash for longer than the configured retention,
+// and returns the number of code spaces deleted.
+func (svc *service) PurgeTrashedCodeSpaces(
+	ctx context.Context,
+) (int64, error) {
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return 0, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	retention := time.Duration(svc.config.CodeSpaceTrashRetentionSeconds) * time.Second
+	purgedCount, err := svc.repository.DeleteTrashedCodeSpaces(ctx, dbConn, svc.timeProvider.Now().Add(-retention))
+	if err != nil {
+		return 0, errutils.FormatError(err)
+	}
+
+	return purgedCount, nil
+}
+
+// runEvery calls fn on a given interval in the background,
+// calling onError whenever fn fails, unless it failed because it was stopped.
+// It returns a function that stops calling fn and waits for any ongoing call to finish.
+func runEvery(interval time.Duration, fn func(ctx context.Context) error, onError func(err error)) func() {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				err := fn(ctx)
+				if err != nil && ctx.Err() == nil {
+					onError(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			cancel()
+		})
+		<-stopped
+	}
+}
+
+// PurgeTrashedCodeSpacesEvery purges the trash on a given interval in the background,
+// calling onError whenever purging fails.
+// It returns a function that stops purging and waits for any ongoing purge to finish.
+func (svc *service) PurgeTrashedCodeSpacesEvery(interval time.Duration, onError func(err error)) func() {
+	return runEvery(interval, func(ctx context.Context) error {
+		_, err := svc.PurgeTrashedCodeSpaces(ctx)
+
+		return err
+	}, onError)
+}
+
+// createCodeSpaceRevision records the current contents of a given code space as a new revision by a given author.
+// Only the main file is recorded, since revisions do not cover the other files of a code space.
+func (svc *service) createCodeSpaceRevision(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpace *CodeSpace,
+	authorUUID string,
+) error {
+	_, err := svc.repository.CreateCodeSpaceRevision(ctx, querier, &CodeSpaceRevision{
+		CodeSpaceID:  codeSpace.ID,
+		AuthorUUID:   &authorUUID,
+		Contents:     codeSpace.Contents,
+		ContentsHash: HashCodeSpaceContents(codeSpace.Contents),
+	})
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// ListCodeSpaceRevisions lists the revisions of a given code space, most recent first.
+func (svc *service) ListCodeSpaceRevisions(
+	ctx context.Context,
+	name string,
+	limit int64,
+	offset int64,
+) ([]*CodeSpaceRevision, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceRevisions, err := svc.repository.ListCodeSpaceRevisions(ctx, dbConn, codeSpace.ID, limit, offset)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRevisions, nil
+}
+
+// GetCodeSpaceRevision gets a given revision of a given code space.
+func (svc *service) GetCodeSpaceRevision(
+	ctx context.Context,
+	name string,
+	codeSpaceRevisionID int64,
+) (*CodeSpaceRevision, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceRevision, err := svc.getCodeSpaceRevision(ctx, dbConn, codeSpace.ID, codeSpaceRevisionID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRevision, nil
+}
+
+// getCodeSpaceRevision gets a given revision of a given code space,
+// returning errutils.ErrCodeSpaceRevisionNotFound if it does not exist.
+func (svc *service) getCodeSpaceRevision(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	codeSpaceRevisionID int64,
+) (*CodeSpaceRevision, error) {
+	codeSpaceRevision, err := svc.repository.GetCodeSpaceRevision(ctx, querier, codeSpaceID, codeSpaceRevisionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRevisionNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	return codeSpaceRevision, nil
+}
+
+// DiffCodeSpaceRevisions computes the unified diff between two revisions of a given code space.
+// The diff is labelled with the main file name of the code space, prefixed with "a/" and "b/".
+func (svc *service) DiffCodeSpaceRevisions(
+	ctx context.Context,
+	name string,
+	fromCodeSpaceRevisionID int64,
+	toCodeSpaceRevisionID int64,
+) (string, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return "", errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return "", err
+	}
+
+	fromCodeSpaceRevision, err := svc.getCodeSpaceRevision(ctx, dbConn, codeSpace.ID, fromCodeSpaceRevisionID)
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	toCodeSpaceRevision, err := svc.getCodeSpaceRevision(ctx, dbConn, codeSpace.ID, toCodeSpaceRevisionID)
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	fileName := codingLanguageFileName(codeSpace.Language)
+	diff := UnifiedDiff(
+		"a/"+fileName,
+		"b/"+fileName,
+		fromCodeSpaceRevision.Contents,
+		toCodeSpaceRevision.Contents,
+	)
+
+	return diff, nil
+}
+
+// RestoreCodeSpaceRevision sets the contents of a given code space to those of one of its revisions,
+// recording the restored contents as a new revision.
+// Only the main file is restored, and the other files of the code space are left as they are.
+// If version is not nil, the code space is only restored if it is still at the given version,
+// otherwise a CodeSpaceVersionConflictError holding the current state of the code space is returned.
+// Collaborators editing the code space are sent the restored contents as an edit, on top of their unsaved edits.
+func (svc *service) RestoreCodeSpaceRevision(
+	ctx context.Context,
+	name string,
+	codeSpaceRevisionID int64,
+	version *int64,
+) (*CodeSpace, *CodeSpaceAccess, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	if version != nil && *version != codeSpace.Version {
+		return nil, nil, errutils.FormatError(&CodeSpaceVersionConflictError{
+			CodeSpace:       codeSpace,
+			CodeSpaceAccess: codeSpaceAccess,
+		})
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceRevision, err := svc.getCodeSpaceRevision(ctx, dbTx, codeSpace.ID, codeSpaceRevisionID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	codeSpaceID := codeSpace.ID
+	codeSpace, err = svc.repository.UpdateCodeSpace(
+		ctx,
+		dbTx,
+		codeSpace.ID,
+		&codeSpaceRevision.Contents,
+		nil,
+		version,
+	)
+	if version != nil && errors.Is(err, errutils.ErrDatabaseNoRowsAffected) {
+		return nil, nil, svc.codeSpaceVersionConflict(ctx, dbTx, codeSpaceID, codeSpaceAccess)
+	}
+
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	err = svc.createCodeSpaceRevision(ctx, dbTx, codeSpace, userUUID)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	svc.collab.rebase(codeSpace, userUUID)
+
+	return codeSpace, codeSpaceAccess, nil
+}
+
+// ThinCodeSpaceRevisions thins out code space revisions older than the configured retention,
+// keeping only the last revision of each code space for each day.
+// It returns the number of deleted revisions.
+func (svc *service) ThinCodeSpaceRevisions(
+	ctx context.Context,
+) (int64, error) {
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return 0, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	retention := time.Duration(svc.config.CodeSpaceHistoryRetentionSeconds) * time.Second
+	thinnedCount, err := svc.repository.DeleteThinnedCodeSpaceRevisions(
+		ctx,
+		dbConn,
+		svc.timeProvider.Now().Add(-retention),
+	)
+	if err != nil {
+		return 0, errutils.FormatError(err)
+	}
+
+	return thinnedCount, nil
+}
+
+// ThinCodeSpaceRevisionsEvery thins out code space revisions on a given interval in the background,
+// calling onError whenever thinning fails.
+// It returns a function that stops thinning and waits for any ongoing thinning to finish.
+func (svc *service) ThinCodeSpaceRevisionsEvery(interval time.Duration, onError func(err error)) func() {
+	return runEvery(interval, func(ctx context.Context) error {
+		_, err := svc.ThinCodeSpaceRevisions(ctx)
+
+		return err
+	}, onError)
+}
+
+// JoinCodeSpaceCollaboration joins the collaborative editing of a given code space.
+// Users with read-only access may join to follow edits and share their cursor,
+// but only users with read-write access may edit.
+// The returned collaborator's first event is a snapshot of the code space,
+// which includes edits that have not been saved yet and the other collaborators already connected.
+func (svc *service) JoinCodeSpaceCollaboration(
+	ctx context.Context,
+	name string,
+) (*CodeSpaceCollaborator, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	users, codeSpaceAccesses, err := svc.repository.ListUsersWithCodeSpaceAccess(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	for i, user := range users {
+		if user.UUID == userUUID {
+			return svc.collab.join(codeSpace, user, codeSpaceAccesses[i].Level), nil
+		}
+	}
+
+	return nil, errutils.FormatErrorf(errutils.ErrCodeSpaceAccessNotFound, "user %s not found", userUUID)
+}
+
+// ListCodeSpacePresence lists the collaborators currently connected to a given code space,
+// with their names and access levels as they are now, rather than as they were when they joined.
+// Collaborators whose access has since been removed are left out.
+func (svc *service) ListCodeSpacePresence(
+	ctx context.Context,
+	name string,
+) ([]*CodeSpacePresence, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	presences := svc.collab.presences(codeSpace.ID)
+	if len(presences) == 0 {
+		return presences, nil
+	}
+
+	users, codeSpaceAccesses, err := svc.repository.ListUsersWithCodeSpaceAccess(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	usersByUUID := make(map[string]int, len(users))
+	for i, user := range users {
+		usersByUUID[user.UUID] = i
+	}
+
+	currentPresences := make([]*CodeSpacePresence, 0, len(presences))
+	for _, presence := range presences {
+		i, ok := usersByUUID[presence.UserUUID]
+		if !ok {
+			continue
+		}
+
+		presence.FirstName = users[i].FirstName
+		presence.LastName = users[i].LastName
+		presence.AccessLevel = codeSpaceAccesses[i].Level
+		currentPresences = append(currentPresences, presence)
+	}
+
+	slices.SortFunc(currentPresences, func(a *CodeSpacePresence, b *CodeSpacePresence) int {
+		return a.JoinedAt.Compare(b.JoinedAt)
+	})
+
+	return currentPresences, nil
+}
+
+// SaveCodeSpaceCollaborations saves collaboratively edited code spaces that have unsaved edits,
+// recording a revision authored by the last collaborator to edit each of them.
+// Code spaces are only saved over the version their documents were loaded or last saved at,
+// and documents of code spaces that have been saved outside of collaborative editing in the meantime
+// are rebased onto the saved code spaces first, so that neither side's edits are lost.
+// Collaborators that have not been heard from within the configured presence timeout are disconnected first.
+// Saved code spaces without collaborators are unloaded,
+// and code spaces that have been trashed in the meantime are closed, disconnecting their collaborators.
+// It returns the number of saved code spaces.
+func (svc *service) SaveCodeSpaceCollaborations(
+	ctx context.Context,
+) (int64, error) {
+	presenceTimeout := time.Duration(svc.config.CodeSpacePresenceTimeoutSeconds) * time.Second
+	svc.collab.expire(svc.timeProvider.Now().Add(-presenceTimeout))
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return 0, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	savedCount := int64(0)
+	for _, document := range svc.collab.snapshot() {
+		saved, err := svc.saveCodeSpaceCollabDocument(ctx, dbConn, document)
+		if errors.Is(err, errutils.ErrCodeSpaceNotFound) {
+			svc.collab.close(document)
+
+			continue
+		}
+
+		if err != nil {
+			return savedCount, errutils.FormatError(err)
+		}
+
+		if !saved {
+			svc.collab.unload(document)
+
+			continue
+		}
+
+		savedCount++
+	}
+
+	return savedCount, nil
+}
+
+// saveCodeSpaceCollabDocument saves a given collaboratively edited code space if it has unsaved edits.
+// If the code space is no longer at the version the document may be saved over,
+// the document is rebased onto the current code space and saving is tried again,
+// up to CodeSpaceCollabSaveMaxAttempts times.
+// It returns whether the code space was saved, or ErrCodeSpaceNotFound if it has been trashed.
+func (svc *service) saveCodeSpaceCollabDocument(
+	ctx context.Context,
+	dbConn database.Conn,
+	document *codeSpaceCollabDocument,
+) (bool, error) {
+	for range CodeSpaceCollabSaveMaxAttempts {
+		contents, revision, version, editorUUID, ok := document.unsaved()
+		if !ok {
+			return false, nil
+		}
+
+		codeSpace, err := svc.saveCodeSpaceCollabContents(
+			ctx,
+			dbConn,
+			document.codeSpaceID,
+			contents,
+			version,
+			editorUUID,
+		)
+		if err == nil {
+			document.markSaved(revision, contents, codeSpace.Version)
+
+			return true, nil
+		}
+
+		if !errors.Is(err, errutils.ErrDatabaseNoRowsAffected) {
+			return false, errutils.FormatError(err)
+		}
+
+		codeSpace, err = svc.repository.GetCodeSpace(ctx, dbConn, document.codeSpaceID)
+		if err != nil {
+			switch {
+			case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+				err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+			default:
+				err = errutils.FormatError(err)
+			}
+
+			return false, err
+		}
+
+		err = document.rebase(codeSpace.Contents, codeSpace.Version, "")
+		if err != nil {
+			return false, errutils.FormatError(err)
+		}
+	}
+
+	return false, nil
+}
+
+// saveCodeSpaceCollabContents saves given contents of a collaboratively edited code space
+// if the code space is still at a given version, and records a revision authored by a given user.
+func (svc *service) saveCodeSpaceCollabContents(
+	ctx context.Context,
+	dbConn database.Conn,
+	codeSpaceID int64,
+	contents string,
+	version int64,
+	editorUUID string,
+) (*CodeSpace, error) {
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpace, err := svc.repository.UpdateCodeSpace(ctx, dbTx, codeSpaceID, &contents, nil, &version)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.createCodeSpaceRevision(ctx, dbTx, codeSpace, editorUUID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpace, nil
+}
+
+// SaveCodeSpaceCollaborationsEvery saves collaboratively edited code spaces on a given interval in the background,
+// calling onError whenever saving fails.
+// It returns a function that stops saving, waits for any ongoing saving to finish and then saves one last time,
+// so that no edits are lost on shutdown.
+func (svc *service) SaveCodeSpaceCollaborationsEvery(interval time.Duration, onError func(err error)) func() {
+	stop := runEvery(interval, func(ctx context.Context) error {
+		_, err := svc.SaveCodeSpaceCollaborations(ctx)
+
+		return err
+	}, onError)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			stop()
+
+			_, err := svc.SaveCodeSpaceCollaborations(context.Background())
+			if err != nil {
+				onError(err)
+			}
+		})
+	}
+}
+
+// checkRunCodeSpaceLimits checks that the limits requested for a code space run
+// do not exceed the configured maximums.
+func (svc *service) checkRunCodeSpaceLimits(opts *RunCodeSpaceOptions) error {
+	limits := []struct {
+		name     string
+		value    *int64
+		maxValue int64
+	}{
+		{
+			name:     "compile timeout",
+			value:    opts.CompileTimeout,
+			maxValue: svc.config.PistonMaxCompileTimeout,
+		},
+		{
+			name:     "run timeout",
+			value:    opts.RunTimeout,
+			maxValue: svc.config.PistonMaxRunTimeout,
+		},
+		{
+			name:     "compile memory limit",
+			value:    opts.CompileMemoryLimit,
+			maxValue: svc.config.PistonMaxCompileMemoryLimit,
+		},
+		{
+			name:     "run memory limit",
+			value:    opts.RunMemoryLimit,
+			maxValue: svc.config.PistonMaxRunMemoryLimit,
+		},
+	}
+
+	for _, limit := range limits {
+		if limit.value != nil && *limit.value > limit.maxValue {
+			return errutils.FormatErrorf(
+				errutils.ErrCodeSpaceRunLimitExceeded,
+				"%s %d exceeds maximum %d",
+				limit.name,
+				*limit.value,
+				limit.maxValue,
+			)
+		}
+	}
+
+	return nil
+}
+
+// executionCPUMillisecondsPerRun gets the CPU time in milliseconds reserved for each run with given options
+// until the run finishes.
+// Since the CPU time used by a run is not known in advance, runs reserve the most time they can be given.
+func (svc *service) executionCPUMillisecondsPerRun(opts *RunCodeSpaceOptions) int64 {
+	compileTimeout := svc.config.PistonMaxCompileTimeout
+	if opts.CompileTimeout != nil {
+		compileTimeout = *opts.CompileTimeout
+	}
+
+	runTimeout := svc.config.PistonMaxRunTimeout
+	if opts.RunTimeout != nil {
+		runTimeout = *opts.RunTimeout
+	}
+
+	return compileTimeout + runTimeout
+}
+
+// reserveExecutionUsages checks that a given number of additional runs with given options
+// fit within the execution quotas of a given user and of the API key used for authentication, if any,
+// and records them, so that they count against the execution quotas before they are executed.
+// The check and the records are made in one transaction while the code executions of the user are locked,
+// so that concurrent requests cannot exceed the execution quotas together.
+// Each record reserves CPU time using executionCPUMillisecondsPerRun,
+// and must be passed to either finishExecutionUsage or releaseExecutionUsages.
+func (svc *service) reserveExecutionUsages(
+	ctx context.Context,
+	dbConn database.Conn,
+	userUUID string,
+	runs int64,
+	opts *RunCodeSpaceOptions,
+) ([]*auth.ExecutionUsage, error) {
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	err = svc.authRepository.LockExecutionUsage(ctx, dbTx, userUUID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	cpuMillisecondsPerRun := svc.executionCPUMillisecondsPerRun(opts)
+	apiKeyID := auth.GetAPIKeyIDFromContext(ctx)
+
+	err = svc.checkExecutionQuota(
+		ctx,
+		dbTx,
+		auth.NewUserExecutionQuota(svc.config),
+		userUUID,
+		nil,
+		runs,
+		cpuMillisecondsPerRun,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if apiKeyID != nil {
+		err = svc.checkExecutionQuota(
+			ctx,
+			dbTx,
+			auth.NewAPIKeyExecutionQuota(svc.config),
+			userUUID,
+			apiKeyID,
+			runs,
+			cpuMillisecondsPerRun,
+		)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	executionUsages := make([]*auth.ExecutionUsage, runs)
+	for i := range executionUsages {
+		executionUsages[i], err = svc.authRepository.CreateExecutionUsage(ctx, dbTx, &auth.ExecutionUsage{
+			UserUUID:        userUUID,
+			APIKeyID:        apiKeyID,
+			CPUMilliseconds: cpuMillisecondsPerRun,
+		})
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return executionUsages, nil
+}
+
+// checkExecutionQuota checks that a given number of additional runs,
+// each using up to a given amount of CPU time in milliseconds, fit within a given execution quota.
+// When apiKeyID is nil, the quota is checked against all code executions of the user,
+// otherwise it is only checked against code executions made using the API key.
+func (svc *service) checkExecutionQuota(
+	ctx context.Context,
+	querier database.Querier,
+	quota *auth.ExecutionQuota,
+	userUUID string,
+	apiKeyID *int64,
+	runs int64,
+	cpuMillisecondsPerRun int64,
+) error {
+	if quota.IsDisabled() {
+		return nil
+	}
+
+	now := svc.timeProvider.Now()
+	minuteStart, dayStart := auth.GetExecutionQuotaWindows(now)
+
+	summary, err := svc.authRepository.GetExecutionUsageSummary(ctx, querier, userUUID, apiKeyID, minuteStart, dayStart)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	err = quota.Check(summary, runs, cpuMillisecondsPerRun, now)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// finishExecutionUsage replaces the CPU time reserved for a code execution
+// with the CPU time reported by Piston for its compile and run stages.
+// Executions served from the execution cache, and executions without results, use no CPU time.
+// Failures are logged rather than returned, since the execution itself has already finished.
+func (svc *service) finishExecutionUsage(
+	ctx context.Context,
+	querier database.Querier,
+	executionUsage *auth.ExecutionUsage,
+	resp *api.PistonExecuteResponse,
+	fromCache bool,
+) {
+	var cpuMilliseconds int64
+	if resp != nil && !fromCache {
+		if resp.Compile != nil && resp.Compile.CPUTime != nil {
+			cpuMilliseconds += *resp.Compile.CPUTime
+		}
+
+		if resp.Run.CPUTime != nil {
+			cpuMilliseconds += *resp.Run.CPUTime
+		}
+	}
+
+	err := svc.authRepository.UpdateExecutionUsageCPUTime(ctx, querier, executionUsage.ID, cpuMilliseconds)
+	if err != nil {
+		svc.logger.LogError(errutils.FormatError(err))
+	}
+}
+
+// releaseExecutionUsages deletes the records of reserved code executions that were never executed,
+// so that they no longer count against the execution quotas.
+// The records are deleted even if the context is done, since the executions are often dropped because of it.
+// Failures are logged rather than returned, since they must not hide the reason the executions were dropped.
+func (svc *service) releaseExecutionUsages(
+	ctx context.Context,
+	querier database.Querier,
+	executionUsages []*auth.ExecutionUsage,
+) {
+	ctx = context.WithoutCancel(ctx)
+	for _, executionUsage := range executionUsages {
+		err := svc.authRepository.DeleteExecutionUsage(ctx, querier, executionUsage.ID)
+		if err != nil {
+			svc.logger.LogError(errutils.FormatError(err))
+		}
+	}
+}
+
+// buildPistonExecuteRequest resolves the runtime for a given code space
+// and builds the Piston execution request for its files and environment variables.
+// Runs of code spaces with environment variables fail with errutils.ErrCodeSpaceEnvVarsUnsupported
+// in languages that cannot set them.
+// Secret environment variables are only set for users with read-write access,
+// since users can see the output of the code they run.
+func (svc *service) buildPistonExecuteRequest(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpace *CodeSpace,
+	codeSpaceAccess *CodeSpaceAccess,
+	opts *RunCodeSpaceOptions,
+) (*api.PistonExecuteRequest, error) {
+	runtimes, err := svc.pistonClient.Runtimes(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	version := codeSpace.LanguageVersion
+	if opts.Version != nil {
+		version = opts.Version
+	}
+
+	var runtime *api.PistonRuntime
+	if version != nil {
+		runtime = piston.FindRuntimeVersion(runtimes, codeSpace.Language, *version)
+		if runtime == nil {
+			return nil, errutils.FormatErrorf(
+				errutils.ErrCodeSpaceUnsupportedVersion,
+				"unknown version %s for language %s",
+				*version,
+				codeSpace.Language,
+			)
+		}
+	} else {
+		runtime = piston.FindRuntime(runtimes, codeSpace.Language)
+		if runtime == nil {
+			return nil, errutils.FormatErrorf(
+				errutils.ErrCodeSpaceUnsupportedLanguage,
+				"unknown language %s",
+				codeSpace.Language,
+			)
+		}
+	}
+
+	codeSpaceFiles, err := svc.repository.ListCodeSpaceFiles(ctx, querier, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	// Piston treats the first file as the entry point,
+	// which is the main file unless another file is marked as the entry point
+	encoding := api.PistonFileEncoding
+	mainFileName := codingLanguageFileName(codeSpace.Language)
+	mainFile := api.PistonFile{
+		Name:     &mainFileName,
+		Content:  codeSpace.Contents,
+		Encoding: &encoding,
+	}
+	files := []api.PistonFile{mainFile}
+	for _, codeSpaceFile := range codeSpaceFiles {
+		file := api.PistonFile{
+			Name:     &codeSpaceFile.Name,
+			Content:  codeSpaceFile.Contents,
+			Encoding: &encoding,
+		}
+
+		if codeSpaceFile.IsEntryPoint {
+			files[0] = file
+			files = append(files, mainFile)
+		} else {
+			files = append(files, file)
+		}
+	}
+
+	includeSecrets := codeSpaceAccess.Level >= CodeSpaceAccessLevelReadWrite
+	env, err := svc.buildCodeSpaceEnv(ctx, querier, codeSpace.ID, includeSecrets)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	// Piston does not set environment variables, so an entry point that sets them runs the actual entry point
+	if len(env) > 0 {
+		envEntryPoint, err := codingLanguageEnvEntryPoint(codeSpace.Language, *files[0].Name, env)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		files = append([]api.PistonFile{*envEntryPoint}, files...)
+	}
+
+	req := &api.PistonExecuteRequest{
+		Language:           codeSpace.Language,
+		Version:            runtime.Version,
+		Files:              files,
+		Stdin:              opts.Stdin,
+		Args:               opts.Args,
+		CompileTimeout:     opts.CompileTimeout,
+		RunTimeout:         opts.RunTimeout,
+		CompileMemoryLimit: opts.CompileMemoryLimit,
+		RunMemoryLimit:     opts.RunMemoryLimit,
+	}
+
+	return req, nil
+}
+
+// applyCodeSpaceRunConfig fills in the run options that are not set
+// from the run configuration of a given code space named in the options.
+// Options that don't name a run configuration are returned as they are.
+func (svc *service) applyCodeSpaceRunConfig(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceID int64,
+	opts *RunCodeSpaceOptions,
+) (*RunCodeSpaceOptions, error) {
+	if opts.RunConfig == nil {
+		return opts, nil
+	}
+
+	codeSpaceRunConfig, err := svc.repository.GetCodeSpaceRunConfigByName(ctx, querier, codeSpaceID, *opts.RunConfig)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunConfigNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	// the maximum limits may have been lowered since the run configuration was saved
+	opts = codeSpaceRunConfig.Apply(opts)
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return opts, nil
+}
+
+// createCodeSpaceRun creates a code space run with a given status for a given code space
+// and builds the corresponding Piston execution request.
+// Options naming a run configuration are filled in from it before the request is built.
+func (svc *service) createCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	userUUID string,
+	name string,
+	opts *RunCodeSpaceOptions,
+	status string,
+) (*CodeSpaceRun, *api.PistonExecuteRequest, error) {
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, querier, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, nil, err
+	}
+
+	opts, err = svc.applyCodeSpaceRunConfig(ctx, querier, codeSpace.ID, opts)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	req, err := svc.buildPistonExecuteRequest(ctx, querier, codeSpace, codeSpaceAccess, opts)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun := &CodeSpaceRun{
+		CodeSpaceID: codeSpace.ID,
+		UserUUID:    &userUUID,
+		Contents:    codeSpace.Contents,
+		Language:    codeSpace.Language,
+		Version:     req.Version,
+		Status:      status,
+	}
+
+	if status == api.CodeSpaceRunStatusRunning {
+		startedAt := svc.timeProvider.Now()
+		codeSpaceRun.StartedAt = &startedAt
+	}
+
+	codeSpaceRun, err = svc.repository.CreateCodeSpaceRun(ctx, querier, codeSpaceRun)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRun, req, nil
+}
+
+// executePistonRequest executes a given Piston request, reporting progress events to onEvent when it is not nil.
+// When useCache is true and an execution cache is configured, the result of an identical earlier request
+// is returned from the cache instead, in which case no progress events are reported.
+// Cache failures are logged rather than returned, so that the request is still executed.
+// It returns whether the result came from the cache.
+func (svc *service) executePistonRequest(
+	ctx context.Context,
+	req *api.PistonExecuteRequest,
+	useCache bool,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, bool, error) {
+	var key string
+	if useCache && svc.executionCache != nil {
+		var err error
+		key, err = piston.CacheKey(req)
+		if err != nil {
+			svc.logger.LogWarn(errutils.FormatError(err))
+		}
+	}
+
+	if key != "" {
+		resp, ok, err := svc.executionCache.Get(ctx, key)
+		if err != nil {
+			svc.logger.LogWarn(errutils.FormatError(err))
+		} else if ok {
+			return resp, true, nil
+		}
+	}
+
+	var resp *api.PistonExecuteResponse
+	var err error
+	if onEvent != nil {
+		resp, err = piston.ExecuteStream(ctx, svc.pistonClient, req, onEvent)
+	} else {
+		resp, err = svc.pistonClient.Execute(ctx, req)
+	}
+
+	if err != nil {
+		return nil, false, errutils.FormatError(err)
+	}
+
+	// executions killed by a signal, such as those that ran out of time, are not cached,
+	// since they may finish when retried under less load
+	killed := resp.Run.Signal != nil || (resp.Compile != nil && resp.Compile.Signal != nil)
+	if key != "" && !killed {
+		err = svc.executionCache.Set(ctx, key, resp)
+		if err != nil {
+			svc.logger.LogWarn(errutils.FormatError(err))
+		}
+	}
+
+	return resp, false, nil
+}
+
+// executeCodeSpaceRun executes a given Piston request and records the outcome on a given code space run
+// using trackCodeSpaceRun.
+// Progress events are reported to onEvent when it is not nil.
+// The execution cache is used when useCache is true.
+func (svc *service) executeCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRun *CodeSpaceRun,
+	executionUsage *auth.ExecutionUsage,
+	req *api.PistonExecuteRequest,
+	useCache bool,
+	onEvent func(event *api.PistonEvent),
+) (*CodeSpaceRun, error) {
+	codeSpaceRun, err := svc.trackCodeSpaceRun(
+		ctx,
+		querier,
+		codeSpaceRun,
+		executionUsage,
+		func(ctx context.Context) (*api.PistonExecuteResponse, bool, error) {
+			return svc.executePistonRequest(ctx, req, useCache, onEvent)
+		},
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRun, nil
+}
+
+// trackCodeSpaceRun marks a given code space run as running, calls execute
+// and records the outcome of the execution on the code space run,
+// along with its usage on a given execution usage reserved using reserveExecutionUsages.
+// If the context is done before the execution finishes, the run is recorded as cancelled
+// and errutils.ErrCodeSpaceRunCancelled is returned.
+func (svc *service) trackCodeSpaceRun(
+	ctx context.Context,
+	querier database.Querier,
+	codeSpaceRun *CodeSpaceRun,
+	executionUsage *auth.ExecutionUsage,
+	execute func(ctx context.Context) (*api.PistonExecuteResponse, bool, error),
+) (*CodeSpaceRun, error) {
+	var err error
+	if codeSpaceRun.Status != api.CodeSpaceRunStatusRunning {
+		startedAt := svc.timeProvider.Now()
+		codeSpaceRun.Status = api.CodeSpaceRunStatusRunning
+		codeSpaceRun.StartedAt = &startedAt
+
+		codeSpaceRun, err = svc.repository.UpdateCodeSpaceRun(ctx, querier, codeSpaceRun)
+		if err != nil {
+			svc.releaseExecutionUsages(ctx, querier, []*auth.ExecutionUsage{executionUsage})
+
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	resp, fromCache, execErr := execute(ctx)
+	cancelled := execErr != nil && ctx.Err() != nil
+
+	// the outcome is recorded even if the context is done, so that cancelled runs don't stay running
+	recordCtx := context.WithoutCancel(ctx)
+
+	finishedAt := svc.timeProvider.Now()
+	codeSpaceRun.FinishedAt = &finishedAt
+	codeSpaceRun.FromCache = fromCache
+	svc.finishExecutionUsage(recordCtx, querier, executionUsage, resp, fromCache)
+
+	switch {
+	case cancelled:
+		codeSpaceRun.Status = api.CodeSpaceRunStatusCancelled
+	case execErr != nil:
+		codeSpaceRun.Status = api.CodeSpaceRunStatusFailed
+	default:
+		codeSpaceRun.Status = api.CodeSpaceRunStatusCompleted
+		codeSpaceRun.SetResults(resp)
+	}
+
+	codeSpaceRun, err = svc.repository.UpdateCodeSpaceRun(recordCtx, querier, codeSpaceRun)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if cancelled {
+		return nil, errutils.FormatErrorf(errutils.ErrCodeSpaceRunCancelled, "%v", execErr)
+	}
+
+	if execErr != nil {
+		return nil, errutils.FormatError(execErr)
+	}
+
+	return codeSpaceRun, nil
+}
+
+// RunCodeSpace runs the code in a code space and waits for the results.
+func (svc *service) RunCodeSpace(
+	ctx context.Context,
+	name string,
+	opts *RunCodeSpaceOptions,
+) (*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, 1, opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, req, err := svc.createCodeSpaceRun(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+		opts,
+		api.CodeSpaceRunStatusRunning,
+	)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, err = svc.executeCodeSpaceRun(ctx, dbConn, codeSpaceRun, executionUsages[0], req, !opts.NoCache, nil)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRun, nil
+}
+
+// RunCodeSpaceMatrix runs the code in a code space once on each of the given language versions
+// and waits for all the results.
+// Runs are recorded before any of them are executed, so an unsupported version fails the whole matrix.
+// Runs are executed concurrently and returned in the same order as versions.
+func (svc *service) RunCodeSpaceMatrix(
+	ctx context.Context,
+	name string,
+	versions []string,
+	opts *RunCodeSpaceOptions,
+) ([]*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, int64(len(versions)), opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRuns, reqs, err := svc.createCodeSpaceRunMatrix(ctx, dbConn, userUUID, name, versions, opts)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+
+		return nil, errutils.FormatError(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(versions))
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// each run records its progress concurrently, so each needs its own connection
+			runDBConn, err := svc.dbPool.Acquire(ctx)
+			if err != nil {
+				errs[i] = errutils.FormatError(err, "svc.dbPool.Acquire failed")
+
+				return
+			}
+			defer runDBConn.Release()
+
+			codeSpaceRuns[i], errs[i] = svc.executeCodeSpaceRun(
+				ctx,
+				runDBConn,
+				codeSpaceRuns[i],
+				executionUsages[i],
+				reqs[i],
+				!opts.NoCache,
+				nil,
+			)
+		}()
+	}
+
+	wg.Wait()
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRuns, nil
+}
+
+// createCodeSpaceRunMatrix records a queued run of the code in a code space on each of the given language versions
+// in one transaction, and builds the Piston execution requests for them.
+func (svc *service) createCodeSpaceRunMatrix(
+	ctx context.Context,
+	dbConn database.Conn,
+	userUUID string,
+	name string,
+	versions []string,
+	opts *RunCodeSpaceOptions,
+) ([]*CodeSpaceRun, []*api.PistonExecuteRequest, error) {
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceRuns := make([]*CodeSpaceRun, len(versions))
+	reqs := make([]*api.PistonExecuteRequest, len(versions))
+	for i := range versions {
+		versionOpts := *opts
+		versionOpts.Version = &versions[i]
+
+		codeSpaceRuns[i], reqs[i], err = svc.createCodeSpaceRun(
+			ctx,
+			dbTx,
+			userUUID,
+			name,
+			&versionOpts,
+			api.CodeSpaceRunStatusQueued,
+		)
+		if err != nil {
+			return nil, nil, errutils.FormatError(err)
+		}
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceRuns, reqs, nil
+}
+
+// BenchmarkCodeSpace runs the code in a code space a given number of times with the same input
+// and summarizes the resources used by the runs.
+// The whole benchmark is refused unless all of its runs fit within the execution quotas.
+// Runs are executed one at a time, so that the benchmark holds at most one slot of the execution queue,
+// and the execution cache is skipped so that every run is measured.
+// The benchmark stops at the first run that does not succeed, which is included in the returned runs.
+func (svc *service) BenchmarkCodeSpace(
+	ctx context.Context,
+	name string,
+	iterations int64,
+	opts *RunCodeSpaceOptions,
+) (*CodeSpaceBenchmark, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, iterations, opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	// runs that are never executed, because the benchmark stopped early or failed, are released
+	executed := 0
+	defer func() {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages[executed:])
+	}()
+
+	// the request is built once, so that every run executes the same code
+	codeSpaceRun, req, err := svc.createCodeSpaceRun(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+		opts,
+		api.CodeSpaceRunStatusRunning,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	benchmark := &CodeSpaceBenchmark{
+		Iterations: iterations,
+		Runs:       make([]*CodeSpaceRun, 0, iterations),
+	}
+
+	for i := range iterations {
+		if i > 0 {
+			startedAt := svc.timeProvider.Now()
+			codeSpaceRun, err = svc.repository.CreateCodeSpaceRun(ctx, dbConn, &CodeSpaceRun{
+				CodeSpaceID: codeSpaceRun.CodeSpaceID,
+				UserUUID:    &userUUID,
+				Contents:    codeSpaceRun.Contents,
+				Language:    codeSpaceRun.Language,
+				Version:     codeSpaceRun.Version,
+				Status:      api.CodeSpaceRunStatusRunning,
+				StartedAt:   &startedAt,
+			})
+			if err != nil {
+				return nil, errutils.FormatError(err)
+			}
+		}
+
+		executed++
+		codeSpaceRun, err = svc.executeCodeSpaceRun(ctx, dbConn, codeSpaceRun, executionUsages[i], req, false, nil)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		benchmark.Runs = append(benchmark.Runs, codeSpaceRun)
+		if !codeSpaceRun.Succeeded() {
+			benchmark.StoppedEarly = i < iterations-1
+
+			break
+		}
+	}
+
+	benchmark.Summarize()
+
+	return benchmark, nil
+}
+
+// StartCodeSpaceRun queues a run of the code in a code space and returns without waiting for the results.
+// The run is executed in the background and its progress can be retrieved using GetCodeSpaceRun.
+// The database connection used to queue the run is handed over to the background run,
+// so that queued runs never wait on the connection pool. Close waits for background runs to finish.
+func (svc *service) StartCodeSpaceRun(
+	ctx context.Context,
+	name string,
+	opts *RunCodeSpaceOptions,
+) (*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, 1, opts)
+	if err != nil {
+		dbConn.Release()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, req, err := svc.createCodeSpaceRun(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+		opts,
+		api.CodeSpaceRunStatusQueued,
+	)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+		dbConn.Release()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	// the run outlives the request, so it must not be cancelled along with it
+	bgCtx := context.WithoutCancel(ctx)
+	queuedCodeSpaceRun := *codeSpaceRun
+
+	svc.backgroundRuns.Add(1)
+	go func() {
+		defer svc.backgroundRuns.Done()
+		defer dbConn.Release()
+
+		_, err := svc.executeCodeSpaceRun(
+			bgCtx,
+			dbConn,
+			&queuedCodeSpaceRun,
+			executionUsages[0],
+			req,
+			!opts.NoCache,
+			nil,
+		)
+		if err != nil {
+			svc.logger.LogError(errutils.FormatError(err))
+		}
+	}()
+
+	return codeSpaceRun, nil
+}
+
+// Close waits for code space runs started in the background to finish.
+func (svc *service) Close() {
+	svc.backgroundRuns.Wait()
+}
+
+// StreamCodeSpaceRun runs the code in a code space and waits for the results,
+// reporting progress events to onEvent as the run goes through its phases.
+func (svc *service) StreamCodeSpaceRun(
+	ctx context.Context,
+	name string,
+	opts *RunCodeSpaceOptions,
+	onEvent func(event *api.PistonEvent),
+) (*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, 1, opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, req, err := svc.createCodeSpaceRun(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+		opts,
+		api.CodeSpaceRunStatusQueued,
+	)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, err = svc.executeCodeSpaceRun(
+		ctx,
+		dbConn,
+		codeSpaceRun,
+		executionUsages[0],
+		req,
+		!opts.NoCache,
+		onEvent,
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRun, nil
+}
+
+// RunCodeSpaceInteractively runs the code in a code space in an interactive session
+// and waits for the session to finish.
+// Once the session has started, interact is called to drive it,
+// for instance by forwarding standard input and output between the session and the user.
+// The run is recorded as completed with the results reported by the session if interact returns nil.
+// The execution cache is never used, since the output depends on the input given during the session.
+func (svc *service) RunCodeSpaceInteractively(
+	ctx context.Context,
+	name string,
+	opts *RunCodeSpaceOptions,
+	interact func(session piston.Session) error,
+) (*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, 1, opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, req, err := svc.createCodeSpaceRun(
+		ctx,
+		dbConn,
+		userUUID,
+		name,
+		opts,
+		api.CodeSpaceRunStatusRunning,
+	)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+
+		return nil, errutils.FormatError(err)
+	}
+
+	codeSpaceRun, err = svc.trackCodeSpaceRun(
+		ctx,
+		dbConn,
+		codeSpaceRun,
+		executionUsages[0],
+		func(ctx context.Context) (*api.PistonExecuteResponse, bool, error) {
+			session, err := piston.Connect(ctx, svc.pistonClient, req)
+			if err != nil {
+				return nil, false, errutils.FormatError(err)
+			}
+			defer session.Close()
+
+			err = interact(session)
+			if err != nil {
+				return nil, false, errutils.FormatError(err)
+			}
+
+			return session.Results(), false, nil
+		},
+	)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRun, nil
+}
+
+// ListCodeSpaceRuns lists the run history of a given code space.
+func (svc *service) ListCodeSpaceRuns(
+	ctx context.Context,
+	name string,
+	limit int64,
+	offset int64,
+) ([]*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceRuns, err := svc.repository.ListCodeSpaceRuns(ctx, dbConn, codeSpace.ID, limit, offset)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRuns, nil
+}
+
+// GetCodeSpaceRun gets a given run of a given code space.
+func (svc *service) GetCodeSpaceRun(
+	ctx context.Context,
+	name string,
+	codeSpaceRunID int64,
+) (*CodeSpaceRun, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceRun, err := svc.repository.GetCodeSpaceRun(ctx, dbConn, codeSpace.ID, codeSpaceRunID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	return codeSpaceRun, nil
+}
+
+// ListCodeSpaceFiles lists the files in a given code space, excluding its main file.
+func (svc *service) ListCodeSpaceFiles(
+	ctx context.Context,
+	name string,
+) ([]*CodeSpaceFile, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceFiles, err := svc.repository.ListCodeSpaceFiles(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceFiles, nil
+}
+
+// CreateCodeSpaceFile creates a new file in a given code space.
+// If the new file is marked as the entry point, the previous entry point is unmarked.
+func (svc *service) CreateCodeSpaceFile(
+	ctx context.Context,
+	name string,
+	fileName string,
+	contents string,
+	isEntryPoint bool,
+) (*CodeSpaceFile, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	if fileName == codingLanguageFileName(codeSpace.Language) {
+		return nil, errutils.FormatErrorf(errutils.ErrCodeSpaceFileAlreadyExists, "%s is the main file", fileName)
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceFiles, err := svc.repository.ListCodeSpaceFiles(ctx, dbTx, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if len(codeSpaceFiles) >= api.CodeSpaceFilesMaxCount {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceFileLimitExceeded,
+			"code space already has %d files",
+			len(codeSpaceFiles),
+		)
+	}
+
+	if isEntryPoint {
+		err = svc.repository.ClearCodeSpaceEntryPoint(ctx, dbTx, codeSpace.ID)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	codeSpaceFile := &CodeSpaceFile{
+		CodeSpaceID:  codeSpace.ID,
+		Name:         fileName,
+		Contents:     contents,
+		IsEntryPoint: isEntryPoint,
+	}
+
+	codeSpaceFile, err = svc.repository.CreateCodeSpaceFile(ctx, dbTx, codeSpaceFile)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceFileAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceFile, nil
+}
+
+// UpdateCodeSpaceFile renames, updates the contents of, or marks as entry point a file in a given code space.
+// If the file is marked as the entry point, the previous entry point is unmarked.
+func (svc *service) UpdateCodeSpaceFile(
+	ctx context.Context,
+	name string,
+	codeSpaceFileID int64,
+	fileName *string,
+	contents *string,
+	isEntryPoint *bool,
+) (*CodeSpaceFile, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	if fileName != nil && *fileName == codingLanguageFileName(codeSpace.Language) {
+		return nil, errutils.FormatErrorf(errutils.ErrCodeSpaceFileAlreadyExists, "%s is the main file", *fileName)
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	if isEntryPoint != nil && *isEntryPoint {
+		err = svc.repository.ClearCodeSpaceEntryPoint(ctx, dbTx, codeSpace.ID)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	codeSpaceFile, err := svc.repository.UpdateCodeSpaceFile(
+		ctx,
+		dbTx,
+		codeSpace.ID,
+		codeSpaceFileID,
+		fileName,
+		contents,
+		isEntryPoint,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceFileNotFound)
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceFileAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceFile, nil
+}
+
+// DeleteCodeSpaceFile deletes a file in a given code space.
+func (svc *service) DeleteCodeSpaceFile(
+	ctx context.Context,
+	name string,
+	codeSpaceFileID int64,
+) error {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	err = svc.repository.DeleteCodeSpaceFile(ctx, dbConn, codeSpace.ID, codeSpaceFileID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceFileNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ListCodeSpaceTestCases lists the test cases in a given code space.
+// Users with read-only access can only list visible test cases.
+func (svc *service) ListCodeSpaceTestCases(
+	ctx context.Context,
+	name string,
+) ([]*CodeSpaceTestCase, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	includeHidden := codeSpaceAccess.Level >= CodeSpaceAccessLevelReadWrite
+	codeSpaceTestCases, err := svc.repository.ListCodeSpaceTestCases(ctx, dbConn, codeSpace.ID, includeHidden)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceTestCases, nil
+}
+
+// CreateCodeSpaceTestCase creates a new test case in a given code space.
+func (svc *service) CreateCodeSpaceTestCase(
+	ctx context.Context,
+	name string,
+	stdin *string,
+	args []string,
+	expectedStdout string,
+	comparisonMode string,
+	isHidden bool,
+) (*CodeSpaceTestCase, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceTestCases, err := svc.repository.ListCodeSpaceTestCases(ctx, dbTx, codeSpace.ID, true)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if len(codeSpaceTestCases) >= api.CodeSpaceTestCasesMaxCount {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceTestCaseLimitExceeded,
+			"code space already has %d test cases",
+			len(codeSpaceTestCases),
+		)
+	}
+
+	if args == nil {
+		args = []string{}
+	}
+
+	codeSpaceTestCase := &CodeSpaceTestCase{
+		CodeSpaceID:    codeSpace.ID,
+		Stdin:          stdin,
+		Args:           args,
+		ExpectedStdout: expectedStdout,
+		ComparisonMode: comparisonMode,
+		IsHidden:       isHidden,
+	}
+
+	if comparisonMode == api.CodeSpaceTestComparisonModeRegex {
+		_, err = codeSpaceTestCase.CompilePattern()
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	codeSpaceTestCase, err = svc.repository.CreateCodeSpaceTestCase(ctx, dbTx, codeSpaceTestCase)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceTestCase, nil
+}
+
+// UpdateCodeSpaceTestCase updates the inputs, expected output, comparison mode, or visibility
+// of a test case in a given code space.
+// The update is rolled back if it leaves a test case compared using regular expressions with an invalid pattern.
+func (svc *service) UpdateCodeSpaceTestCase(
+	ctx context.Context,
+	name string,
+	codeSpaceTestCaseID int64,
+	stdin *string,
+	args []string,
+	expectedStdout *string,
+	comparisonMode *string,
+	isHidden *bool,
+) (*CodeSpaceTestCase, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceTestCase, err := svc.repository.UpdateCodeSpaceTestCase(
+		ctx,
+		dbTx,
+		codeSpace.ID,
+		codeSpaceTestCaseID,
+		stdin,
+		args,
+		expectedStdout,
+		comparisonMode,
+		isHidden,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceTestCaseNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceTestCase.ComparisonMode == api.CodeSpaceTestComparisonModeRegex {
+		_, err = codeSpaceTestCase.CompilePattern()
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceTestCase, nil
+}
+
+// DeleteCodeSpaceTestCase deletes a test case in a given code space.
+func (svc *service) DeleteCodeSpaceTestCase(
+	ctx context.Context,
+	name string,
+	codeSpaceTestCaseID int64,
+) error {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	err = svc.repository.DeleteCodeSpaceTestCase(ctx, dbConn, codeSpace.ID, codeSpaceTestCaseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceTestCaseNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ListCodeSpaceRunConfigs lists the run configurations in a given code space.
+func (svc *service) ListCodeSpaceRunConfigs(
+	ctx context.Context,
+	name string,
+) ([]*CodeSpaceRunConfig, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	codeSpaceRunConfigs, err := svc.repository.ListCodeSpaceRunConfigs(ctx, dbConn, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceRunConfigs, nil
+}
+
+// CreateCodeSpaceRunConfig creates a new run configuration in a given code space.
+// Limits are checked against the allowed maximum when the run configuration is saved and again when it is used.
+func (svc *service) CreateCodeSpaceRunConfig(
+	ctx context.Context,
+	name string,
+	configName string,
+	stdin *string,
+	args []string,
+	compileTimeout *int64,
+	runTimeout *int64,
+	compileMemoryLimit *int64,
+	runMemoryLimit *int64,
+	languageVersion *string,
+) (*CodeSpaceRunConfig, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(&RunCodeSpaceOptions{
+		CompileTimeout:     compileTimeout,
+		RunTimeout:         runTimeout,
+		CompileMemoryLimit: compileMemoryLimit,
+		RunMemoryLimit:     runMemoryLimit,
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	codeSpaceRunConfigs, err := svc.repository.ListCodeSpaceRunConfigs(ctx, dbTx, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if len(codeSpaceRunConfigs) >= api.CodeSpaceRunConfigsMaxCount {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceRunConfigLimitExceeded,
+			"code space already has %d run configs",
+			len(codeSpaceRunConfigs),
+		)
+	}
+
+	if args == nil {
+		args = []string{}
+	}
+
+	codeSpaceRunConfig, err := svc.repository.CreateCodeSpaceRunConfig(ctx, dbTx, &CodeSpaceRunConfig{
+		CodeSpaceID:        codeSpace.ID,
+		Name:               configName,
+		Stdin:              stdin,
+		Args:               args,
+		CompileTimeout:     compileTimeout,
+		RunTimeout:         runTimeout,
+		CompileMemoryLimit: compileMemoryLimit,
+		RunMemoryLimit:     runMemoryLimit,
+		LanguageVersion:    languageVersion,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunConfigAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	return codeSpaceRunConfig, nil
+}
+
+// UpdateCodeSpaceRunConfig updates the name, inputs, limits, or language version
+// of a run configuration in a given code space.
+func (svc *service) UpdateCodeSpaceRunConfig(
+	ctx context.Context,
+	name string,
+	codeSpaceRunConfigID int64,
+	configName *string,
+	stdin *string,
+	args []string,
+	compileTimeout *int64,
+	runTimeout *int64,
+	compileMemoryLimit *int64,
+	runMemoryLimit *int64,
+	languageVersion *string,
+) (*CodeSpaceRunConfig, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	err = svc.checkRunCodeSpaceLimits(&RunCodeSpaceOptions{
+		CompileTimeout:     compileTimeout,
+		RunTimeout:         runTimeout,
+		CompileMemoryLimit: compileMemoryLimit,
+		RunMemoryLimit:     runMemoryLimit,
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
+
+	codeSpaceRunConfig, err := svc.repository.UpdateCodeSpaceRunConfig(
+		ctx,
+		dbConn,
+		codeSpace.ID,
+		codeSpaceRunConfigID,
+		configName,
+		stdin,
+		args,
+		compileTimeout,
+		runTimeout,
+		compileMemoryLimit,
+		runMemoryLimit,
+		languageVersion,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunConfigNotFound)
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunConfigAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
+	}
+
+	return codeSpaceRunConfig, nil
+}
+
+// DeleteCodeSpaceRunConfig deletes a run configuration in a given code space.
+func (svc *service) DeleteCodeSpaceRunConfig(
+	ctx context.Context,
+	name string,
+	codeSpaceRunConfigID int64,
+) error {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	dbConn, err := svc.dbPool.Acquire(ctx)
+	if err != nil {
+		return errutils.FormatError(err, "svc.dbPool.Acquire failed")
+	}
+	defer dbConn.Release()
+
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
 	}
-}
 
-// GenerateCodeSpaceName generates a random name for a code space.
-func (svc *service) GenerateCodeSpaceName() (string, error) {
-	adjectiveIdx, err := random.GenerateInt64(int64(len(Adjectives)))
-	if err != nil {
-		return "", errutils.FormatError(err)
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
 	}
 
-	pokemonIdx, err := random.GenerateInt64(int64(len(Pokemon)))
+	err = svc.repository.DeleteCodeSpaceRunConfig(ctx, dbConn, codeSpace.ID, codeSpaceRunConfigID)
 	if err != nil {
-		return "", errutils.FormatError(err)
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceRunConfigNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
 	}
 
-	programmingTermIdx, err := random.GenerateInt64(int64(len(ProgrammingTerms)))
-	if err != nil {
-		return "", errutils.FormatError(err)
+	return nil
+}
+
+// revealCodeSpaceEnvVar decrypts the value of a given environment variable in place if it is secret.
+func (svc *service) revealCodeSpaceEnvVar(codeSpaceEnvVar *CodeSpaceEnvVar) error {
+	if !codeSpaceEnvVar.IsSecret || codeSpaceEnvVar.Value == nil {
+		return nil
 	}
 
-	harryPotterCharacterIdx, err := random.GenerateInt64(int64(len(HarryPotterCharacters)))
+	value, err := svc.crypto.DecryptSecret(*codeSpaceEnvVar.Value)
 	if err != nil {
-		return "", errutils.FormatError(err)
+		return errutils.FormatErrorf(err, "failed to decrypt code space env var %d", codeSpaceEnvVar.ID)
 	}
 
-	fileExtensionIdx, err := random.GenerateInt64(int64(len(FileExtensions)))
+	codeSpaceEnvVar.Value = &value
+
+	return nil
+}
+
+// encodeCodeSpaceEnvVarValue returns the value of an environment variable as it is stored,
+// which is encrypted if the environment variable is secret.
+func (svc *service) encodeCodeSpaceEnvVarValue(value string, isSecret bool) (string, error) {
+	if !isSecret {
+		return value, nil
+	}
+
+	encryptedValue, err := svc.crypto.EncryptSecret(value)
 	if err != nil {
 		return "", errutils.FormatError(err)
 	}
 
-	name := strings.Join(
-		[]string{
-			Adjectives[adjectiveIdx],
-			Pokemon[pokemonIdx],
-			ProgrammingTerms[programmingTermIdx],
-			HarryPotterCharacters[harryPotterCharacterIdx],
-			FileExtensions[fileExtensionIdx],
-		},
-		"-",
-	)
-
-	return name, nil
+	return encryptedValue, nil
 }
 
-// CreateCodeSpace creates a new code space.
-func (svc *service) CreateCodeSpace(
+// buildCodeSpaceEnv builds the environment variables set for runs of a given code space.
+// Secret environment variables are only included when includeSecrets is true.
+// Returns nil when no environment variables are set.
+func (svc *service) buildCodeSpaceEnv(
 	ctx context.Context,
-	language string,
-) (*CodeSpace, *CodeSpaceAccess, error) {
-	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	querier database.Querier,
+	codeSpaceID int64,
+	includeSecrets bool,
+) (map[string]string, error) {
+	codeSpaceEnvVars, err := svc.repository.ListCodeSpaceEnvVars(ctx, querier, codeSpaceID)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
-	languageConfig, ok := CodingLanguageConfig[language]
-	if !ok {
-		return nil, nil, errutils.FormatErrorf(errutils.ErrCodeSpaceUnsupportedLanguage, "unknown language %s", language)
-	}
+	var env map[string]string
+	for _, codeSpaceEnvVar := range codeSpaceEnvVars {
+		if codeSpaceEnvVar.IsSecret && !includeSecrets {
+			continue
+		}
 
-	templateFilePath := fmt.Sprintf("_codetemplates/%s/%s", language, languageConfig.fileName)
-	templateFileBytes, err := CodeTemplatesFS.ReadFile(templateFilePath)
-	if err != nil {
-		return nil, nil, errutils.FormatErrorf(nil, "codeTemplatesFS.ReadFile failed to read %s", templateFilePath)
+		err = svc.revealCodeSpaceEnvVar(codeSpaceEnvVar)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		if env == nil {
+			env = make(map[string]string, len(codeSpaceEnvVars))
+		}
+
+		env[codeSpaceEnvVar.Name] = *codeSpaceEnvVar.Value
 	}
 
-	name, err := svc.GenerateCodeSpaceName()
+	return env, nil
+}
+
+// ListCodeSpaceEnvVars lists the environment variables in a given code space.
+// Values of secret environment variables are withheld from users with read-only access.
+func (svc *service) ListCodeSpaceEnvVars(
+	ctx context.Context,
+	name string,
+) ([]*CodeSpaceEnvVar, error) {
+	userUUID, err := auth.GetUserUUIDFromContext(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
 	dbConn, err := svc.dbPool.Acquire(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
 	}
 	defer dbConn.Release()
 
-	codeSpace := &CodeSpace{
-		Name:       name,
-		AuthorUUID: &userUUID,
-		Language:   language,
-		Contents:   string(templateFileBytes),
-	}
-
-	dbTx, err := dbConn.Begin(ctx)
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err, "dbConn.Begin failed")
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
 	}
-	defer dbTx.Rollback(ctx)
 
-	codeSpace, err = svc.repository.CreateCodeSpace(ctx, dbTx, codeSpace)
+	codeSpaceEnvVars, err := svc.repository.ListCodeSpaceEnvVars(ctx, dbConn, codeSpace.ID)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
-	codeSpaceAccess := &CodeSpaceAccess{
-		UserUUID:    userUUID,
-		CodeSpaceID: codeSpace.ID,
-		Level:       CodeSpaceAccessLevelReadWrite,
-	}
+	revealSecrets := codeSpaceAccess.Level >= CodeSpaceAccessLevelReadWrite
+	for _, codeSpaceEnvVar := range codeSpaceEnvVars {
+		if codeSpaceEnvVar.IsSecret && !revealSecrets {
+			codeSpaceEnvVar.Value = nil
 
-	codeSpaceAccess, err = svc.repository.CreateOrUpdateCodeSpaceAccess(ctx, dbTx, codeSpaceAccess)
-	if err != nil {
-		return nil, nil, errutils.FormatError(err)
-	}
+			continue
+		}
 
-	err = dbTx.Commit(ctx)
-	if err != nil {
-		return nil, nil, errutils.FormatError(err, "dbTx.Commit failed")
+		err = svc.revealCodeSpaceEnvVar(codeSpaceEnvVar)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
 	}
 
-	return codeSpace, codeSpaceAccess, nil
+	return codeSpaceEnvVars, nil
 }
 
-// ListCodeSpaces lists all code spaces accessible to the currently authenticated user.
-func (svc *service) ListCodeSpaces(
+// CreateCodeSpaceEnvVar creates a new environment variable in a given code space.
+// Values of secret environment variables are encrypted before they are stored.
+func (svc *service) CreateCodeSpaceEnvVar(
 	ctx context.Context,
-) ([]*CodeSpace, []*CodeSpaceAccess, error) {
+	name string,
+	envVarName string,
+	value string,
+	isSecret bool,
+) (*CodeSpaceEnvVar, error) {
 	userUUID, err := auth.GetUserUUIDFromContext(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
 	dbConn, err := svc.dbPool.Acquire(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
 	}
 	defer dbConn.Release()
 
-	codeSpaces, codeSpaceAccesses, err := svc.repository.ListCodeSpaces(ctx, dbConn, userUUID)
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
 	}
 
-	return codeSpaces, codeSpaceAccesses, nil
-}
+	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+	}
 
-// GetCodeSpace gets a given code space for the currently authenticated user.
-func (svc *service) GetCodeSpace(
-	ctx context.Context,
-	name string,
-) (*CodeSpace, *CodeSpaceAccess, error) {
-	userUUID, err := auth.GetUserUUIDFromContext(ctx)
+	storedValue, err := svc.encodeCodeSpaceEnvVarValue(value, isSecret)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
-	dbConn, err := svc.dbPool.Acquire(ctx)
+	dbTx, err := dbConn.Begin(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
 	}
-	defer dbConn.Release()
+	defer dbTx.Rollback(ctx)
 
-	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	codeSpaceEnvVars, err := svc.repository.ListCodeSpaceEnvVars(ctx, dbTx, codeSpace.ID)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	if len(codeSpaceEnvVars) >= api.CodeSpaceEnvVarsMaxCount {
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeSpaceEnvVarLimitExceeded,
+			"code space already has %d env vars",
+			len(codeSpaceEnvVars),
+		)
+	}
+
+	codeSpaceEnvVar, err := svc.repository.CreateCodeSpaceEnvVar(ctx, dbTx, &CodeSpaceEnvVar{
+		CodeSpaceID: codeSpace.ID,
+		Name:        envVarName,
+		Value:       &storedValue,
+		IsSecret:    isSecret,
+	})
 	if err != nil {
 		switch {
-		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
-			err = errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceEnvVarAlreadyExists)
 		default:
 			err = errutils.FormatError(err)
 		}
 
-		return nil, nil, err
+		return nil, err
 	}
 
-	return codeSpace, codeSpaceAccess, nil
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	codeSpaceEnvVar.Value = &value
+
+	return codeSpaceEnvVar, nil
 }
 
-// UpdateCodeSpace updates a given code space.
-func (svc *service) UpdateCodeSpace(
+// UpdateCodeSpaceEnvVar updates the name, value, or secrecy of an environment variable in a given code space.
+// The stored value is encrypted or decrypted when the environment variable is made secret or not secret.
+func (svc *service) UpdateCodeSpaceEnvVar(
 	ctx context.Context,
 	name string,
-	contents *string,
-) (*CodeSpace, *CodeSpaceAccess, error) {
+	codeSpaceEnvVarID int64,
+	envVarName *string,
+	value *string,
+	isSecret *bool,
+) (*CodeSpaceEnvVar, error) {
 	userUUID, err := auth.GetUserUUIDFromContext(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		return nil, errutils.FormatError(err)
 	}
 
 	dbConn, err := svc.dbPool.Acquire(ctx)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
+		return nil, errutils.FormatError(err, "svc.dbPool.Acquire failed")
 	}
 	defer dbConn.Release()
 
-	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(
-		ctx,
-		dbConn,
-		userUUID,
-		name,
-	)
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
 	if err != nil {
 		switch {
 		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
@@ -310,30 +3826,96 @@ func (svc *service) UpdateCodeSpace(
 			err = errutils.FormatError(err)
 		}
 
-		return nil, nil, err
+		return nil, err
 	}
 
 	if codeSpaceAccess.Level < CodeSpaceAccessLevelReadWrite {
-		return nil, nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
+		return nil, errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
 	}
 
-	codeSpace, err = svc.repository.UpdateCodeSpace(
+	dbTx, err := dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbConn.Begin failed")
+	}
+	defer dbTx.Rollback(ctx)
+
+	// the stored value depends on both the value and the secrecy,
+	// so the current environment variable fills in whichever of the two is not being updated
+	var storedValue *string
+	if value != nil || isSecret != nil {
+		currentCodeSpaceEnvVar, err := svc.repository.GetCodeSpaceEnvVar(ctx, dbTx, codeSpace.ID, codeSpaceEnvVarID)
+		if err != nil {
+			switch {
+			case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
+				err = errutils.FormatError(errutils.ErrCodeSpaceEnvVarNotFound)
+			default:
+				err = errutils.FormatError(err)
+			}
+
+			return nil, err
+		}
+
+		err = svc.revealCodeSpaceEnvVar(currentCodeSpaceEnvVar)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		if value == nil {
+			value = currentCodeSpaceEnvVar.Value
+		}
+
+		if isSecret == nil {
+			isSecret = &currentCodeSpaceEnvVar.IsSecret
+		}
+
+		encodedValue, err := svc.encodeCodeSpaceEnvVarValue(*value, *isSecret)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		storedValue = &encodedValue
+	}
+
+	codeSpaceEnvVar, err := svc.repository.UpdateCodeSpaceEnvVar(
 		ctx,
-		dbConn,
+		dbTx,
 		codeSpace.ID,
-		contents,
+		codeSpaceEnvVarID,
+		envVarName,
+		storedValue,
+		isSecret,
 	)
 	if err != nil {
-		return nil, nil, errutils.FormatError(err)
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceEnvVarNotFound)
+		case errors.Is(err, errutils.ErrDatabaseUniqueViolation):
+			err = errutils.FormatError(errutils.ErrCodeSpaceEnvVarAlreadyExists)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return nil, err
 	}
 
-	return codeSpace, codeSpaceAccess, nil
+	err = dbTx.Commit(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err, "dbTx.Commit failed")
+	}
+
+	err = svc.revealCodeSpaceEnvVar(codeSpaceEnvVar)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return codeSpaceEnvVar, nil
 }
 
-// DeleteCodeSpace deletes a code space.
-func (svc *service) DeleteCodeSpace(
+// DeleteCodeSpaceEnvVar deletes an environment variable in a given code space.
+func (svc *service) DeleteCodeSpaceEnvVar(
 	ctx context.Context,
 	name string,
+	codeSpaceEnvVarID int64,
 ) error {
 	userUUID, err := auth.GetUserUUIDFromContext(ctx)
 	if err != nil {
@@ -346,12 +3928,7 @@ func (svc *service) DeleteCodeSpace(
 	}
 	defer dbConn.Release()
 
-	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(
-		ctx,
-		dbConn,
-		userUUID,
-		name,
-	)
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
 	if err != nil {
 		switch {
 		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
@@ -367,19 +3944,29 @@ func (svc *service) DeleteCodeSpace(
 		return errutils.FormatError(errutils.ErrCodeSpaceAccessDenied)
 	}
 
-	err = svc.repository.DeleteCodeSpace(ctx, dbConn, codeSpace.ID)
+	err = svc.repository.DeleteCodeSpaceEnvVar(ctx, dbConn, codeSpace.ID, codeSpaceEnvVarID)
 	if err != nil {
-		return errutils.FormatError(err)
+		switch {
+		case errors.Is(err, errutils.ErrDatabaseNoRowsAffected):
+			err = errutils.FormatError(errutils.ErrCodeSpaceEnvVarNotFound)
+		default:
+			err = errutils.FormatError(err)
+		}
+
+		return err
 	}
 
 	return nil
 }
 
-// RunCodeSpace runs the code in a code space.
-func (svc *service) RunCodeSpace(
+// RunCodeSpaceTests runs the code in a code space against each of its test cases and grades the results.
+// Users with read-only access only run visible test cases.
+// Test runs are executed concurrently, are not recorded in the run history but count against execution quotas,
+// and are returned in the same order as the test cases.
+func (svc *service) RunCodeSpaceTests(
 	ctx context.Context,
 	name string,
-) (*api.PistonExecuteResponse, error) {
+) ([]*CodeSpaceTestResult, error) {
 	userUUID, err := auth.GetUserUUIDFromContext(ctx)
 	if err != nil {
 		return nil, errutils.FormatError(err)
@@ -391,7 +3978,7 @@ func (svc *service) RunCodeSpace(
 	}
 	defer dbConn.Release()
 
-	codeSpace, _, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
+	codeSpace, codeSpaceAccess, err := svc.repository.GetCodeSpaceWithAccessByName(ctx, dbConn, userUUID, name)
 	if err != nil {
 		switch {
 		case errors.Is(err, errutils.ErrDatabaseNoRowsReturned):
@@ -403,30 +3990,76 @@ func (svc *service) RunCodeSpace(
 		return nil, err
 	}
 
-	languageConfig, ok := CodingLanguageConfig[codeSpace.Language]
-	if !ok {
-		return nil, errutils.FormatErrorf(nil, "unknown language %s", codeSpace.Language)
+	includeHidden := codeSpaceAccess.Level >= CodeSpaceAccessLevelReadWrite
+	codeSpaceTestCases, err := svc.repository.ListCodeSpaceTestCases(ctx, dbConn, codeSpace.ID, includeHidden)
+	if err != nil {
+		return nil, errutils.FormatError(err)
 	}
 
-	encoding := api.PistonFileEncoding
-	req := &api.PistonExecuteRequest{
-		Language: codeSpace.Language,
-		Version:  languageConfig.version,
-		Files: []api.PistonFile{
-			{
-				Name:     &languageConfig.fileName,
-				Content:  codeSpace.Contents,
-				Encoding: &encoding,
-			},
-		},
+	opts := &RunCodeSpaceOptions{}
+
+	// usage is recorded on dbConn before and after the concurrent executions,
+	// since a connection cannot be used concurrently
+	executionUsages, err := svc.reserveExecutionUsages(ctx, dbConn, userUUID, int64(len(codeSpaceTestCases)), opts)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	req, err := svc.buildPistonExecuteRequest(ctx, dbConn, codeSpace, codeSpaceAccess, opts)
+	if err != nil {
+		svc.releaseExecutionUsages(ctx, dbConn, executionUsages)
+
+		return nil, errutils.FormatError(err)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*CodeSpaceTestResult, len(codeSpaceTestCases))
+	resps := make([]*api.PistonExecuteResponse, len(codeSpaceTestCases))
+	fromCaches := make([]bool, len(codeSpaceTestCases))
+	errs := make([]error, len(codeSpaceTestCases))
+	for i, codeSpaceTestCase := range codeSpaceTestCases {
+		testReq := *req
+		testReq.Stdin = codeSpaceTestCase.Stdin
+		testReq.Args = codeSpaceTestCase.Args
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			resps[i], fromCaches[i], err = svc.executePistonRequest(ctx, &testReq, true, nil)
+			if err != nil {
+				errs[i] = errutils.FormatError(err)
+
+				return
+			}
+
+			results[i] = &CodeSpaceTestResult{
+				TestCase:  codeSpaceTestCase,
+				FromCache: fromCaches[i],
+			}
+			errs[i] = results[i].SetResults(resps[i])
+		}()
+	}
+
+	wg.Wait()
+
+	// usage is recorded even if the context is done, since the executions may have already used resources
+	recordCtx := context.WithoutCancel(ctx)
+	for i, executionUsage := range executionUsages {
+		svc.finishExecutionUsage(recordCtx, dbConn, executionUsage, resps[i], fromCaches[i])
+	}
+
+	err = errors.Join(errs...)
+	if err != nil && ctx.Err() != nil {
+		return nil, errutils.FormatErrorf(errutils.ErrCodeSpaceRunCancelled, "%v", err)
 	}
 
-	resp, err := svc.pistonClient.Execute(req)
 	if err != nil {
 		return nil, errutils.FormatError(err)
 	}
 
-	return resp, nil
+	return results, nil
 }
 
 // ListCodeSpaceUsers lists users with access to a code space.
@@ -592,8 +4225,12 @@ func (svc *service) AcceptCodeSpaceUserInvitation(
 		return nil, nil, err
 	}
 
+	// invitation links embed the code space name, which may have changed since the invitation was sent
 	if name != codeSpace.Name {
-		return nil, nil, errutils.FormatError(errutils.ErrCodeSpaceNotFound)
+		codeSpaceAlias, err := svc.repository.GetCodeSpaceAliasByName(ctx, dbConn, name)
+		if err != nil || codeSpaceAlias.CodeSpaceID != codeSpace.ID {
+			return nil, nil, errutils.FormatErrorf(errutils.ErrCodeSpaceNotFound, "%s is not a name of the code space", name)
+		}
 	}
 
 	user, err := svc.authRepository.GetUserByEmail(ctx, dbConn, claims.InviteeEmail)
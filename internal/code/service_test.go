@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	htmltemplate "html/template"
+	"io"
 	"regexp"
 	"testing"
 	texttemplate "text/template"
@@ -14,6 +15,7 @@ import (
 	authmocks "github.com/alvii147/nymphadora-api/internal/auth/mocks"
 	"github.com/alvii147/nymphadora-api/internal/code"
 	codemocks "github.com/alvii147/nymphadora-api/internal/code/mocks"
+	"github.com/alvii147/nymphadora-api/internal/database"
 	databasemocks "github.com/alvii147/nymphadora-api/internal/database/mocks"
 	"github.com/alvii147/nymphadora-api/internal/templatesmanager"
 	templatesmanagermocks "github.com/alvii147/nymphadora-api/internal/templatesmanager/mocks"
@@ -22,7 +24,6 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/cryptocore"
 	cryptocoremocks "github.com/alvii147/nymphadora-api/pkg/cryptocore/mocks"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
-	"github.com/alvii147/nymphadora-api/pkg/httputils"
 	mailclientmocks "github.com/alvii147/nymphadora-api/pkg/mailclient/mocks"
 	"github.com/alvii147/nymphadora-api/pkg/piston"
 	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
@@ -31,6 +32,7 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/validate"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -46,18 +48,21 @@ func TestServiceGenerateCodeSpaceName(t *testing.T) {
 	crypto := cryptocoremocks.NewMockCrypto(ctrl)
 	mailClient := mailclientmocks.NewMockClient(ctrl)
 	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-	pistonClient := piston.NewClient(nil, httputils.NewHTTPClient(nil))
+	pistonClient := testkitinternal.GetPistonClient()
 	repo := codemocks.NewMockRepository(ctrl)
 	authRepo := authmocks.NewMockRepository(ctrl)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		dbPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
@@ -71,6 +76,120 @@ func TestServiceGenerateCodeSpaceName(t *testing.T) {
 	require.True(t, v.Passed())
 }
 
+func TestServiceListCodingLanguages(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.9.4",
+				Aliases:  []string{"py", "python3"},
+			},
+			{
+				Language: api.PistonLanguageJavaScript,
+				Version:  "18.15.0",
+				Aliases:  []string{"js"},
+			},
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+				Aliases:  []string{"py", "py3", "python3"},
+			},
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "2.7.18",
+				Aliases:  []string{"py2"},
+			},
+		}, nil).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	codingLanguages, err := svc.ListCodingLanguages(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []*code.CodingLanguage{
+		{
+			Name:     api.PistonLanguageJavaScript,
+			Versions: []string{"18.15.0"},
+			Aliases:  []string{"js"},
+		},
+		{
+			Name:     api.PistonLanguagePython,
+			Versions: []string{"3.10.0", "3.9.4", "2.7.18"},
+			Aliases:  []string{"py", "python3", "py3", "py2"},
+		},
+	}, codingLanguages)
+}
+
+func TestServiceListCodingLanguagesError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	runtimesErr := errors.New("Runtimes failed")
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(nil, runtimesErr).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	_, err := svc.ListCodingLanguages(context.Background())
+	require.ErrorIs(t, err, runtimesErr)
+}
+
 func TestServiceCreateCodeSpaceSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -85,25 +204,28 @@ func TestServiceCreateCodeSpaceSuccess(t *testing.T) {
 	crypto := cryptocoremocks.NewMockCrypto(ctrl)
 	mailClient := mailclientmocks.NewMockClient(ctrl)
 	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-	pistonClient := piston.NewClient(nil, httputils.NewHTTPClient(nil))
+	pistonClient := testkitinternal.GetPistonClient()
 	repo := code.NewRepository(timeProvider)
 	authRepo := auth.NewRepository(timeProvider)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		TestDBPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
 	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
 
-	codeSpace, codeSpaceAccess, err := svc.CreateCodeSpace(ctx, "python")
+	codeSpace, codeSpaceAccess, err := svc.CreateCodeSpace(ctx, nil, "python", nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, codeSpace.AuthorUUID)
@@ -115,6 +237,115 @@ func TestServiceCreateCodeSpaceSuccess(t *testing.T) {
 	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, codeSpaceAccess.Level)
 }
 
+func TestServiceCreateCodeSpaceLanguageAlias(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py", "py3"},
+		},
+		{
+			Language: "brainfuck",
+			Version:  "2.7.3",
+			Aliases:  []string{"bf"},
+		},
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	codeSpace, _, err := svc.CreateCodeSpace(ctx, nil, "py3", nil)
+	require.NoError(t, err)
+	require.Equal(t, api.PistonLanguagePython, codeSpace.Language)
+	require.NotEmpty(t, codeSpace.Contents)
+
+	codeSpace, _, err = svc.CreateCodeSpace(ctx, nil, "bf", nil)
+	require.NoError(t, err)
+	require.Equal(t, "brainfuck", codeSpace.Language)
+	require.Empty(t, codeSpace.Contents)
+}
+
+func TestServiceCreateCodeSpaceLanguageVersion(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
+			Aliases:  []string{"py"},
+		},
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	languageVersion := "3.9.4"
+	codeSpace, _, err := svc.CreateCodeSpace(ctx, nil, "py", &languageVersion)
+	require.NoError(t, err)
+	require.Equal(t, api.PistonLanguagePython, codeSpace.Language)
+	require.NotNil(t, codeSpace.LanguageVersion)
+	require.Equal(t, languageVersion, *codeSpace.LanguageVersion)
+}
+
 func TestServiceCreateCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
@@ -124,6 +355,16 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 		u.IsActive = true
 	})
 
+	runtimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+	}
+
+	unsupportedLanguageVersion := "2.7.18"
+	genericRuntimesErr := errors.New("Runtimes failed")
 	dbBeginErr := errors.New("Begin failed")
 	dbCommitErr := errors.New("Commit failed")
 	genericCreateCodeSpaceRepoErr := errors.New("CreateCodeSpace failed")
@@ -132,6 +373,8 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 	testcases := map[string]struct {
 		ctx                          context.Context
 		language                     string
+		languageVersion              *string
+		runtimesErr                  error
 		dbBeginErr                   error
 		dbCommitErr                  error
 		createCodeSpaceRepoErr       error
@@ -141,24 +384,52 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 		"No user in context": {
 			ctx:                          context.Background(),
 			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   nil,
 			dbCommitErr:                  nil,
 			createCodeSpaceRepoErr:       nil,
 			createCodeSpaceAccessRepoErr: nil,
 			wantErr:                      nil,
 		},
+		"Runtimes fails": {
+			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
+			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  genericRuntimesErr,
+			dbBeginErr:                   nil,
+			dbCommitErr:                  nil,
+			createCodeSpaceRepoErr:       nil,
+			createCodeSpaceAccessRepoErr: nil,
+			wantErr:                      genericRuntimesErr,
+		},
 		"Unsupported language": {
 			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
 			language:                     "parseltongue",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   nil,
 			dbCommitErr:                  nil,
 			createCodeSpaceRepoErr:       nil,
 			createCodeSpaceAccessRepoErr: nil,
 			wantErr:                      errutils.ErrCodeSpaceUnsupportedLanguage,
 		},
+		"Unsupported language version": {
+			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
+			language:                     "python",
+			languageVersion:              &unsupportedLanguageVersion,
+			runtimesErr:                  nil,
+			dbBeginErr:                   nil,
+			dbCommitErr:                  nil,
+			createCodeSpaceRepoErr:       nil,
+			createCodeSpaceAccessRepoErr: nil,
+			wantErr:                      errutils.ErrCodeSpaceUnsupportedVersion,
+		},
 		"Begin transaction fails": {
 			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
 			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   dbBeginErr,
 			dbCommitErr:                  nil,
 			createCodeSpaceRepoErr:       nil,
@@ -168,6 +439,8 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 		"CreateCodeSpace fails": {
 			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
 			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   nil,
 			dbCommitErr:                  nil,
 			createCodeSpaceRepoErr:       genericCreateCodeSpaceRepoErr,
@@ -177,6 +450,8 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 		"CreateOrUpdateCodeSpaceAccess fails": {
 			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
 			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   nil,
 			dbCommitErr:                  nil,
 			createCodeSpaceRepoErr:       nil,
@@ -186,6 +461,8 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 		"Commit transaction fails": {
 			ctx:                          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID),
 			language:                     "python",
+			languageVersion:              nil,
+			runtimesErr:                  nil,
 			dbBeginErr:                   nil,
 			dbCommitErr:                  dbCommitErr,
 			createCodeSpaceRepoErr:       nil,
@@ -210,6 +487,12 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return(runtimes, testcase.runtimesErr).
+				MaxTimes(1)
+
 			dbTx.
 				EXPECT().
 				Commit(gomock.Any()).
@@ -239,6 +522,12 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 				Return(dbConn, nil).
 				MaxTimes(1)
 
+			repo.
+				EXPECT().
+				GetCodeSpaceAliasByName(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, errutils.ErrDatabaseNoRowsReturned).
+				MaxTimes(1)
+
 			repo.
 				EXPECT().
 				CreateCodeSpace(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -272,19 +561,22 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 				).
 				MaxTimes(1)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			_, _, err := svc.CreateCodeSpace(testcase.ctx, testcase.language)
+			_, _, err := svc.CreateCodeSpace(testcase.ctx, nil, testcase.language, testcase.languageVersion)
 			require.Error(t, err)
 
 			if testcase.wantErr != nil {
@@ -294,67 +586,71 @@ func TestServiceCreateCodeSpaceError(t *testing.T) {
 	}
 }
 
-func TestServiceListCodeSpacesSuccess(t *testing.T) {
+func TestServiceCreateCodeSpaceNameTaken(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	sharedCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-	privateCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		sharedCodeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
-
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		sharedCodeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
+	authorUUID := uuid.NewString()
+	customName := "fizz-buzz"
+	otherCodeSpaceAlias := &code.CodeSpaceAlias{
+		ID:          7,
+		CodeSpaceID: 99,
+		Name:        customName,
+	}
 
-	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
+	runtimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+	}
 
 	testcases := map[string]struct {
-		userUUID               string
-		wantCodeSpaceAccessMap map[int64]code.CodeSpaceAccessLevel
+		name                 *string
+		aliasTaken           bool
+		createErrs           []error
+		wantCreateCalls      int
+		wantErr              error
+		wantCreatedCodeSpace bool
 	}{
-		"Author can access both shared and private code spaces": {
-			userUUID: author.UUID,
-			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
-				sharedCodeSpace.ID:  code.CodeSpaceAccessLevelReadWrite,
-				privateCodeSpace.ID: code.CodeSpaceAccessLevelReadWrite,
-			},
+		"Generated name collision is retried": {
+			name:                 nil,
+			aliasTaken:           false,
+			createErrs:           []error{errutils.ErrDatabaseUniqueViolation, nil},
+			wantCreateCalls:      2,
+			wantErr:              nil,
+			wantCreatedCodeSpace: true,
 		},
-		"Editor can access shared code space": {
-			userUUID: editor.UUID,
-			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
-				sharedCodeSpace.ID: code.CodeSpaceAccessLevelReadWrite,
+		"Generated names keep colliding": {
+			name:       nil,
+			aliasTaken: false,
+			createErrs: []error{
+				errutils.ErrDatabaseUniqueViolation,
+				errutils.ErrDatabaseUniqueViolation,
+				errutils.ErrDatabaseUniqueViolation,
+				errutils.ErrDatabaseUniqueViolation,
+				errutils.ErrDatabaseUniqueViolation,
 			},
+			wantCreateCalls:      code.CodeSpaceNameGenerationMaxAttempts,
+			wantErr:              errutils.ErrCodeSpaceAlreadyExists,
+			wantCreatedCodeSpace: false,
 		},
-		"Viewer can access shared code space": {
-			userUUID: viewer.UUID,
-			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
-				sharedCodeSpace.ID: code.CodeSpaceAccessLevelReadOnly,
-			},
+		"Custom name taken by code space": {
+			name:                 &customName,
+			aliasTaken:           false,
+			createErrs:           []error{errutils.ErrDatabaseUniqueViolation},
+			wantCreateCalls:      1,
+			wantErr:              errutils.ErrCodeSpaceAlreadyExists,
+			wantCreatedCodeSpace: false,
 		},
-		"Third party user cannot access any code space": {
-			userUUID:               thirdPartyUser.UUID,
-			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{},
+		"Custom name taken by alias": {
+			name:                 &customName,
+			aliasTaken:           true,
+			createErrs:           nil,
+			wantCreateCalls:      0,
+			wantErr:              errutils.ErrCodeSpaceAlreadyExists,
+			wantCreatedCodeSpace: false,
 		},
 	}
 
@@ -364,77 +660,265 @@ func TestServiceListCodeSpacesSuccess(t *testing.T) {
 
 			ctrl := gomock.NewController(t)
 			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
 			pistonClient := pistonmocks.NewMockClient(ctrl)
-			repo := code.NewRepository(timeProvider)
-			authRepo := auth.NewRepository(timeProvider)
-
-			svc := code.NewService(
-				cfg,
-				timeProvider,
-				TestDBPool,
-				crypto,
-				mailClient,
-				tmplManager,
-				pistonClient,
-				repo,
-				authRepo,
-			)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
 
-			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
-			codeSpaces, codeSpaceAccesses, err := svc.ListCodeSpaces(ctx)
-			require.NoError(t, err)
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return(runtimes, nil).
+				Times(1)
 
-			require.Len(t, codeSpaces, len(testcase.wantCodeSpaceAccessMap))
-			require.Len(t, codeSpaceAccesses, len(testcase.wantCodeSpaceAccessMap))
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				AnyTimes()
 
-			wantCodeSpaceIDs := make([]int64, len(testcase.wantCodeSpaceAccessMap))
-			i := 0
-			for codeSpaceID := range testcase.wantCodeSpaceAccessMap {
-				wantCodeSpaceIDs[i] = codeSpaceID
-				i++
-			}
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				AnyTimes()
 
-			codeSpaceIDs := make([]int64, len(codeSpaces))
-			for i, codeSpace := range codeSpaces {
-				codeSpaceIDs[i] = codeSpace.ID
-				require.Contains(t, testcase.wantCodeSpaceAccessMap, codeSpace.ID)
-				require.Equal(
-					t,
-					testcase.wantCodeSpaceAccessMap[codeSpace.ID],
-					codeSpaceAccesses[i].Level,
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				AnyTimes()
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			if testcase.aliasTaken {
+				repo.
+					EXPECT().
+					GetCodeSpaceAliasByName(gomock.Any(), dbTx, customName).
+					Return(otherCodeSpaceAlias, nil).
+					Times(1)
+			} else {
+				repo.
+					EXPECT().
+					GetCodeSpaceAliasByName(gomock.Any(), dbTx, gomock.Any()).
+					Return(nil, errutils.ErrDatabaseNoRowsReturned).
+					Times(testcase.wantCreateCalls)
+			}
+
+			triedNames := make([]string, 0, len(testcase.createErrs))
+			calls := make([]any, 0, len(testcase.createErrs))
+			for _, createErr := range testcase.createErrs {
+				calls = append(calls, repo.
+					EXPECT().
+					CreateCodeSpace(gomock.Any(), dbTx, gomock.Any()).
+					DoAndReturn(func(
+						_ context.Context,
+						_ database.Querier,
+						codeSpace *code.CodeSpace,
+					) (*code.CodeSpace, error) {
+						triedNames = append(triedNames, codeSpace.Name)
+						if createErr != nil {
+							return nil, createErr
+						}
+
+						codeSpace.ID = 42
+
+						return codeSpace, nil
+					}).
+					Times(1),
 				)
 			}
+			gomock.InOrder(calls...)
 
-			require.ElementsMatch(t, wantCodeSpaceIDs, codeSpaceIDs)
+			repo.
+				EXPECT().
+				CreateOrUpdateCodeSpaceAccess(gomock.Any(), dbTx, gomock.Any()).
+				Return(&code.CodeSpaceAccess{
+					ID:          314,
+					UserUUID:    authorUUID,
+					CodeSpaceID: 42,
+					Level:       code.CodeSpaceAccessLevelReadWrite,
+				}, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+				Return(&code.CodeSpaceRevision{
+					ID:          7,
+					CodeSpaceID: 42,
+					AuthorUUID:  &authorUUID,
+				}, nil).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+			codeSpace, _, err := svc.CreateCodeSpace(ctx, testcase.name, api.PistonLanguagePython, nil)
+			require.Len(t, triedNames, testcase.wantCreateCalls)
+
+			if testcase.name != nil {
+				for _, triedName := range triedNames {
+					require.Equal(t, *testcase.name, triedName)
+				}
+			}
+
+			if !testcase.wantCreatedCodeSpace {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, triedNames[len(triedNames)-1], codeSpace.Name)
 		})
 	}
 }
 
-func TestServiceListCodeSpacesError(t *testing.T) {
+func TestServiceForkCodeSpaceSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	student, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceAccess(t, student.UUID, codeSpace.ID, code.CodeSpaceAccessLevelReadOnly)
+	codeSpaceFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "helpers.py", false)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, student.UUID)
+	forkedCodeSpace, forkedCodeSpaceAccess, err := svc.ForkCodeSpace(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.NotEqual(t, codeSpace.ID, forkedCodeSpace.ID)
+	require.NotEqual(t, codeSpace.Name, forkedCodeSpace.Name)
+	require.Equal(t, student.UUID, *forkedCodeSpace.AuthorUUID)
+	require.Equal(t, codeSpace.Language, forkedCodeSpace.Language)
+	require.Equal(t, codeSpace.Contents, forkedCodeSpace.Contents)
+	require.NotNil(t, forkedCodeSpace.ForkedFromID)
+	require.Equal(t, codeSpace.ID, *forkedCodeSpace.ForkedFromID)
+	require.Equal(t, student.UUID, forkedCodeSpaceAccess.UserUUID)
+	require.Equal(t, forkedCodeSpace.ID, forkedCodeSpaceAccess.CodeSpaceID)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, forkedCodeSpaceAccess.Level)
+
+	forkedCodeSpaceFiles, err := svc.ListCodeSpaceFiles(ctx, forkedCodeSpace.Name)
+	require.NoError(t, err)
+	require.Len(t, forkedCodeSpaceFiles, 1)
+	require.Equal(t, forkedCodeSpace.ID, forkedCodeSpaceFiles[0].CodeSpaceID)
+	require.Equal(t, codeSpaceFile.Name, forkedCodeSpaceFiles[0].Name)
+	require.Equal(t, codeSpaceFile.Contents, forkedCodeSpaceFiles[0].Contents)
+
+	codeSpaces, _, err := svc.ListCodeSpaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, codeSpaces, 2)
+
+	authorCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	_, _, err = svc.GetCodeSpace(authorCtx, forkedCodeSpace.Name)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceNotFound)
+}
+
+func TestServiceForkCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
 	userUUID := uuid.NewString()
-	genericRepoErr := errors.New("ListCodeSpaces failed")
+	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    userUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadOnly,
+	}
 
 	testcases := map[string]struct {
-		ctx     context.Context
-		repoErr error
-		wantErr error
+		codeSpace       *code.CodeSpace
+		codeSpaceAccess *code.CodeSpaceAccess
+		getErr          error
+		createErr       error
+		wantErr         error
 	}{
-		"No user UUID in context": {
-			ctx:     context.Background(),
-			repoErr: nil,
-			wantErr: nil,
+		"Code space not found": {
+			codeSpace:       nil,
+			codeSpaceAccess: nil,
+			getErr:          errutils.ErrDatabaseNoRowsReturned,
+			createErr:       nil,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
 		},
-		"ListCodeSpaces fails": {
-			ctx:     context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID),
-			repoErr: genericRepoErr,
-			wantErr: genericRepoErr,
+		"Generated names keep colliding": {
+			codeSpace:       codeSpace,
+			codeSpaceAccess: codeSpaceAccess,
+			getErr:          nil,
+			createErr:       errutils.ErrDatabaseUniqueViolation,
+			wantErr:         errutils.ErrCodeSpaceAlreadyExists,
+		},
+		"Code space creation fails": {
+			codeSpace:       codeSpace,
+			codeSpaceAccess: codeSpaceAccess,
+			getErr:          nil,
+			createErr:       errors.New("create code space failed"),
+			wantErr:         nil,
 		},
 	}
 
@@ -446,6 +930,7 @@ func TestServiceListCodeSpacesError(t *testing.T) {
 			timeProvider := timekeeper.NewFrozenProvider()
 			dbPool := databasemocks.NewMockPool(ctrl)
 			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
@@ -453,36 +938,75 @@ func TestServiceListCodeSpacesError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Times(0)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				AnyTimes()
+
 			dbConn.
 				EXPECT().
 				Release().
-				MaxTimes(1)
+				Times(1)
 
 			dbPool.
 				EXPECT().
 				Acquire(gomock.Any()).
 				Return(dbConn, nil).
-				MaxTimes(1)
+				Times(1)
 
 			repo.
 				EXPECT().
-				ListCodeSpaces(gomock.Any(), gomock.Any(), userUUID).
-				Return(nil, nil, testcase.repoErr).
+				GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+				Return(testcase.codeSpace, testcase.codeSpaceAccess, testcase.getErr).
+				Times(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), dbConn, codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
 				MaxTimes(1)
 
+			repo.
+				EXPECT().
+				GetCodeSpaceAliasByName(gomock.Any(), dbTx, gomock.Any()).
+				Return(nil, errutils.ErrDatabaseNoRowsReturned).
+				AnyTimes()
+
+			repo.
+				EXPECT().
+				CreateCodeSpace(gomock.Any(), dbTx, gomock.Any()).
+				Return(nil, testcase.createErr).
+				MaxTimes(code.CodeSpaceNameGenerationMaxAttempts)
+
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			_, _, err := svc.ListCodeSpaces(testcase.ctx)
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			_, _, err := svc.ForkCodeSpace(ctx, codeSpace.Name)
 			require.Error(t, err)
 
 			if testcase.wantErr != nil {
@@ -492,7 +1016,7 @@ func TestServiceListCodeSpacesError(t *testing.T) {
 	}
 }
 
-func TestServiceGetCodeSpace(t *testing.T) {
+func TestServiceListCodeSpacesSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -528,58 +1052,31 @@ func TestServiceGetCodeSpace(t *testing.T) {
 	})
 
 	testcases := map[string]struct {
-		userUUID        string
-		codeSpace       *code.CodeSpace
-		wantErr         error
-		wantAccessLevel code.CodeSpaceAccessLevel
+		userUUID               string
+		wantCodeSpaceAccessMap map[int64]code.CodeSpaceAccessLevel
 	}{
-		"Author can access shared code space": {
-			userUUID:        author.UUID,
-			codeSpace:       sharedCodeSpace,
-			wantErr:         nil,
-			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-		},
-		"Author can access private code space": {
-			userUUID:        author.UUID,
-			codeSpace:       privateCodeSpace,
-			wantErr:         nil,
-			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+		"Author can access both shared and private code spaces": {
+			userUUID: author.UUID,
+			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
+				sharedCodeSpace.ID:  code.CodeSpaceAccessLevelReadWrite,
+				privateCodeSpace.ID: code.CodeSpaceAccessLevelReadWrite,
+			},
 		},
 		"Editor can access shared code space": {
-			userUUID:        editor.UUID,
-			codeSpace:       sharedCodeSpace,
-			wantErr:         nil,
-			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-		},
-		"Editor cannot access private code space": {
-			userUUID:        editor.UUID,
-			codeSpace:       privateCodeSpace,
-			wantErr:         errutils.ErrCodeSpaceNotFound,
-			wantAccessLevel: 0,
+			userUUID: editor.UUID,
+			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
+				sharedCodeSpace.ID: code.CodeSpaceAccessLevelReadWrite,
+			},
 		},
 		"Viewer can access shared code space": {
-			userUUID:        viewer.UUID,
-			codeSpace:       sharedCodeSpace,
-			wantErr:         nil,
-			wantAccessLevel: code.CodeSpaceAccessLevelReadOnly,
-		},
-		"Viewer cannot access private code space": {
-			userUUID:        viewer.UUID,
-			codeSpace:       privateCodeSpace,
-			wantErr:         errutils.ErrCodeSpaceNotFound,
-			wantAccessLevel: 0,
-		},
-		"Third party user cannot access shared code space": {
-			userUUID:        thirdPartyUser.UUID,
-			codeSpace:       sharedCodeSpace,
-			wantErr:         errutils.ErrCodeSpaceNotFound,
-			wantAccessLevel: 0,
+			userUUID: viewer.UUID,
+			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{
+				sharedCodeSpace.ID: code.CodeSpaceAccessLevelReadOnly,
+			},
 		},
-		"Third party user cannot access private code space": {
-			userUUID:        thirdPartyUser.UUID,
-			codeSpace:       privateCodeSpace,
-			wantErr:         errutils.ErrCodeSpaceNotFound,
-			wantAccessLevel: 0,
+		"Third party user cannot access any code space": {
+			userUUID:               thirdPartyUser.UUID,
+			wantCodeSpaceAccessMap: map[int64]code.CodeSpaceAccessLevel{},
 		},
 	}
 
@@ -596,52 +1093,286 @@ func TestServiceGetCodeSpace(t *testing.T) {
 			repo := code.NewRepository(timeProvider)
 			authRepo := auth.NewRepository(timeProvider)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				TestDBPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
 			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
-			codeSpace, codeSpaceAccess, err := svc.GetCodeSpace(ctx, testcase.codeSpace.Name)
+			codeSpaces, codeSpaceAccesses, err := svc.ListCodeSpaces(ctx)
+			require.NoError(t, err)
 
-			if testcase.wantErr != nil {
-				require.Error(t, err)
-				require.ErrorIs(t, err, testcase.wantErr)
+			require.Len(t, codeSpaces, len(testcase.wantCodeSpaceAccessMap))
+			require.Len(t, codeSpaceAccesses, len(testcase.wantCodeSpaceAccessMap))
 
-				return
+			wantCodeSpaceIDs := make([]int64, len(testcase.wantCodeSpaceAccessMap))
+			i := 0
+			for codeSpaceID := range testcase.wantCodeSpaceAccessMap {
+				wantCodeSpaceIDs[i] = codeSpaceID
+				i++
 			}
 
-			require.NoError(t, err)
-			require.Equal(t, testcase.codeSpace.ID, codeSpace.ID)
-			require.NotNil(t, codeSpace.AuthorUUID)
-			require.Equal(t, author.UUID, *codeSpace.AuthorUUID)
-			require.Equal(t, testcase.codeSpace.Name, codeSpace.Name)
-			require.Equal(t, testcase.codeSpace.Language, codeSpace.Language)
-			require.Equal(t, testcase.codeSpace.Contents, codeSpace.Contents)
-			require.WithinDuration(t, testcase.codeSpace.CreatedAt, codeSpace.CreatedAt, testkit.TimeToleranceExact)
-			require.WithinDuration(t, testcase.codeSpace.UpdatedAt, codeSpace.UpdatedAt, testkit.TimeToleranceExact)
+			codeSpaceIDs := make([]int64, len(codeSpaces))
+			for i, codeSpace := range codeSpaces {
+				codeSpaceIDs[i] = codeSpace.ID
+				require.Contains(t, testcase.wantCodeSpaceAccessMap, codeSpace.ID)
+				require.Equal(
+					t,
+					testcase.wantCodeSpaceAccessMap[codeSpace.ID],
+					codeSpaceAccesses[i].Level,
+				)
+			}
 
-			require.Equal(t, testcase.userUUID, codeSpaceAccess.UserUUID)
-			require.Equal(t, testcase.codeSpace.ID, codeSpaceAccess.CodeSpaceID)
-			require.Equal(t, testcase.wantAccessLevel, codeSpaceAccess.Level)
+			require.ElementsMatch(t, wantCodeSpaceIDs, codeSpaceIDs)
 		})
 	}
 }
 
-func TestServiceGetCodeSpaceError(t *testing.T) {
+func TestServiceListCodeSpacesError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
 	userUUID := uuid.NewString()
-	codeSpaceName := "habitable-slaking-volatile-granger-mov"
+	genericRepoErr := errors.New("ListCodeSpaces failed")
+
+	testcases := map[string]struct {
+		ctx     context.Context
+		repoErr error
+		wantErr error
+	}{
+		"No user UUID in context": {
+			ctx:     context.Background(),
+			repoErr: nil,
+			wantErr: nil,
+		},
+		"ListCodeSpaces fails": {
+			ctx:     context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID),
+			repoErr: genericRepoErr,
+			wantErr: genericRepoErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaces(gomock.Any(), gomock.Any(), userUUID).
+				Return(nil, nil, testcase.repoErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, _, err := svc.ListCodeSpaces(testcase.ctx)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceGetCodeSpace(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	sharedCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	privateCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		sharedCodeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		sharedCodeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID        string
+		codeSpace       *code.CodeSpace
+		wantErr         error
+		wantAccessLevel code.CodeSpaceAccessLevel
+	}{
+		"Author can access shared code space": {
+			userUUID:        author.UUID,
+			codeSpace:       sharedCodeSpace,
+			wantErr:         nil,
+			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+		},
+		"Author can access private code space": {
+			userUUID:        author.UUID,
+			codeSpace:       privateCodeSpace,
+			wantErr:         nil,
+			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+		},
+		"Editor can access shared code space": {
+			userUUID:        editor.UUID,
+			codeSpace:       sharedCodeSpace,
+			wantErr:         nil,
+			wantAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+		},
+		"Editor cannot access private code space": {
+			userUUID:        editor.UUID,
+			codeSpace:       privateCodeSpace,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+			wantAccessLevel: 0,
+		},
+		"Viewer can access shared code space": {
+			userUUID:        viewer.UUID,
+			codeSpace:       sharedCodeSpace,
+			wantErr:         nil,
+			wantAccessLevel: code.CodeSpaceAccessLevelReadOnly,
+		},
+		"Viewer cannot access private code space": {
+			userUUID:        viewer.UUID,
+			codeSpace:       privateCodeSpace,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+			wantAccessLevel: 0,
+		},
+		"Third party user cannot access shared code space": {
+			userUUID:        thirdPartyUser.UUID,
+			codeSpace:       sharedCodeSpace,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+			wantAccessLevel: 0,
+		},
+		"Third party user cannot access private code space": {
+			userUUID:        thirdPartyUser.UUID,
+			codeSpace:       privateCodeSpace,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+			wantAccessLevel: 0,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			codeSpace, codeSpaceAccess, err := svc.GetCodeSpace(ctx, testcase.codeSpace.Name)
+
+			if testcase.wantErr != nil {
+				require.Error(t, err)
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.codeSpace.ID, codeSpace.ID)
+			require.NotNil(t, codeSpace.AuthorUUID)
+			require.Equal(t, author.UUID, *codeSpace.AuthorUUID)
+			require.Equal(t, testcase.codeSpace.Name, codeSpace.Name)
+			require.Equal(t, testcase.codeSpace.Language, codeSpace.Language)
+			require.Equal(t, testcase.codeSpace.Contents, codeSpace.Contents)
+			require.WithinDuration(t, testcase.codeSpace.CreatedAt, codeSpace.CreatedAt, testkit.TimeToleranceExact)
+			require.WithinDuration(t, testcase.codeSpace.UpdatedAt, codeSpace.UpdatedAt, testkit.TimeToleranceExact)
+
+			require.Equal(t, testcase.userUUID, codeSpaceAccess.UserUUID)
+			require.Equal(t, testcase.codeSpace.ID, codeSpaceAccess.CodeSpaceID)
+			require.Equal(t, testcase.wantAccessLevel, codeSpaceAccess.Level)
+		})
+	}
+}
+
+func TestServiceGetCodeSpaceError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	userUUID := uuid.NewString()
+	codeSpaceName := "habitable-slaking-volatile-granger-mov"
 	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
 
 	testcases := map[string]struct {
@@ -693,14 +1424,17 @@ func TestServiceGetCodeSpaceError(t *testing.T) {
 				Return(nil, nil, testcase.repoErr).
 				MaxTimes(1)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
@@ -738,21 +1472,24 @@ func TestServiceUpdateCodeSpaceAuthorSuccess(t *testing.T) {
 	tomorrow := now.AddDate(0, 0, 1)
 	timeProvider.SetTime(tomorrow)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		TestDBPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
 	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
 	updatedContents := "print('FizzBuzz')"
-	updatedCodeSpace, codeSpaceAccess, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, &updatedContents)
+	updatedCodeSpace, codeSpaceAccess, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &updatedContents, nil, nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, codeSpace.AuthorUUID)
@@ -799,21 +1536,24 @@ func TestServiceUpdateCodeSpaceEditorSuccess(t *testing.T) {
 	tomorrow := now.AddDate(0, 0, 1)
 	timeProvider.SetTime(tomorrow)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		TestDBPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
 	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editor.UUID)
 	updatedContents := "print('FizzBuzz')"
-	updatedCodeSpace, codeSpaceAccess, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, &updatedContents)
+	updatedCodeSpace, codeSpaceAccess, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &updatedContents, nil, nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, codeSpace.AuthorUUID)
@@ -827,7 +1567,7 @@ func TestServiceUpdateCodeSpaceEditorSuccess(t *testing.T) {
 	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, codeSpaceAccess.Level)
 }
 
-func TestServiceUpdateCodeSpaceFails(t *testing.T) {
+func TestServiceUpdateCodeSpaceLanguageVersion(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -837,33 +1577,95 @@ func TestServiceUpdateCodeSpaceFails(t *testing.T) {
 	})
 	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
-
-	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-
-	testcases := map[string]struct {
-		userUUID string
-		wantErr  error
-	}{
-		"Viewer cannot update code space": {
-			userUUID: viewer.UUID,
-			wantErr:  errutils.ErrCodeSpaceAccessDenied,
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
 		},
-		"Third party user cannot update code space": {
-			userUUID: thirdPartyUser.UUID,
-			wantErr:  errutils.ErrCodeSpaceNotFound,
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
 		},
-	}
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+
+	languageVersion := "3.9.4"
+	updatedCodeSpace, _, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, nil, &languageVersion, nil)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.Contents, updatedCodeSpace.Contents)
+	require.NotNil(t, updatedCodeSpace.LanguageVersion)
+	require.Equal(t, languageVersion, *updatedCodeSpace.LanguageVersion)
+
+	unsupportedLanguageVersion := "2.7.18"
+	_, _, err = svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, nil, &unsupportedLanguageVersion, nil)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceUnsupportedVersion)
+
+	unpinnedLanguageVersion := ""
+	updatedCodeSpace, _, err = svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, nil, &unpinnedLanguageVersion, nil)
+	require.NoError(t, err)
+	require.Nil(t, updatedCodeSpace.LanguageVersion)
+}
+
+func TestServiceUpdateCodeSpaceFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID string
+		wantErr  error
+	}{
+		"Viewer cannot update code space": {
+			userUUID: viewer.UUID,
+			wantErr:  errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Third party user cannot update code space": {
+			userUUID: thirdPartyUser.UUID,
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+	}
 
 	for name, testcase := range testcases {
 		t.Run(name, func(t *testing.T) {
@@ -882,80 +1684,92 @@ func TestServiceUpdateCodeSpaceFails(t *testing.T) {
 			tomorrow := now.AddDate(0, 0, 1)
 			timeProvider.SetTime(tomorrow)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				TestDBPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
 			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
 			updatedContents := "print('FizzBuzz')"
-			_, _, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, &updatedContents)
+			_, _, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &updatedContents, nil, nil)
 			require.Error(t, err)
 			require.ErrorIs(t, err, testcase.wantErr)
 		})
 	}
 }
 
-func TestServiceUpdateCodeSpaceError(t *testing.T) {
+func TestServiceUpdateCodeSpaceRename(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	authorUUID := uuid.NewString()
+	userUUID := uuid.NewString()
+	oldName := "old-name"
+	newName := "new-name"
 	codeSpace := &code.CodeSpace{
-		ID:         42,
-		AuthorUUID: &authorUUID,
-		Name:       "habitable-slaking-volatile-granger-mov",
-		Language:   "python",
-		Contents:   "print('hello')",
+		ID:       42,
+		Name:     oldName,
+		Language: api.PistonLanguagePython,
 	}
 	codeSpaceAccess := &code.CodeSpaceAccess{
 		ID:          314,
-		UserUUID:    authorUUID,
+		UserUUID:    userUUID,
 		CodeSpaceID: codeSpace.ID,
 		Level:       code.CodeSpaceAccessLevelReadWrite,
 	}
 
-	updatedContents := "print('FizzBuzz')"
-	genericRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed")
-	genericRepoUpdateErr := errors.New("UpdateCodeSpace failed")
-
 	testcases := map[string]struct {
-		ctx           context.Context
-		repoGetErr    error
-		repoUpdateErr error
-		wantErr       error
+		alias            *code.CodeSpaceAlias
+		renameErr        error
+		wantAliasDeleted bool
+		wantAliasCreated bool
+		wantErr          error
 	}{
-		"No user UUID in context": {
-			ctx:           context.Background(),
-			repoGetErr:    nil,
-			repoUpdateErr: nil,
-			wantErr:       nil,
+		"Rename to unused name": {
+			alias:            nil,
+			renameErr:        nil,
+			wantAliasDeleted: false,
+			wantAliasCreated: true,
+			wantErr:          nil,
 		},
-		"GetCodeSpaceWithAccessByName fails, no rows returned": {
-			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:    errutils.ErrDatabaseNoRowsReturned,
-			repoUpdateErr: nil,
-			wantErr:       errutils.ErrCodeSpaceNotFound,
+		"Rename to own alias": {
+			alias: &code.CodeSpaceAlias{
+				ID:          7,
+				CodeSpaceID: codeSpace.ID,
+				Name:        newName,
+			},
+			renameErr:        nil,
+			wantAliasDeleted: true,
+			wantAliasCreated: true,
+			wantErr:          nil,
 		},
-		"GetCodeSpaceWithAccessByName fails, generic error": {
-			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:    genericRepoGetErr,
-			repoUpdateErr: nil,
-			wantErr:       genericRepoGetErr,
+		"Rename to alias of other code space": {
+			alias: &code.CodeSpaceAlias{
+				ID:          8,
+				CodeSpaceID: 99,
+				Name:        newName,
+			},
+			renameErr:        nil,
+			wantAliasDeleted: false,
+			wantAliasCreated: false,
+			wantErr:          errutils.ErrCodeSpaceAlreadyExists,
 		},
-		"UpdateCodeSpace fails": {
-			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:    nil,
-			repoUpdateErr: genericRepoUpdateErr,
-			wantErr:       genericRepoUpdateErr,
+		"Rename to name of other code space": {
+			alias:            nil,
+			renameErr:        errutils.ErrDatabaseUniqueViolation,
+			wantAliasDeleted: false,
+			wantAliasCreated: false,
+			wantErr:          errutils.ErrCodeSpaceAlreadyExists,
 		},
 	}
 
@@ -967,6 +1781,7 @@ func TestServiceUpdateCodeSpaceError(t *testing.T) {
 			timeProvider := timekeeper.NewFrozenProvider()
 			dbPool := databasemocks.NewMockPool(ctrl)
 			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
@@ -974,52 +1789,126 @@ func TestServiceUpdateCodeSpaceError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				Times(1)
+
 			dbConn.
 				EXPECT().
 				Release().
-				MaxTimes(1)
+				Times(1)
 
 			dbPool.
 				EXPECT().
 				Acquire(gomock.Any()).
 				Return(dbConn, nil).
-				MaxTimes(1)
+				Times(1)
 
 			repo.
 				EXPECT().
-				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
-				Return(codeSpace, codeSpaceAccess, testcase.repoGetErr).
-				MaxTimes(1)
+				GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, oldName).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			aliasErr := error(nil)
+			if testcase.alias == nil {
+				aliasErr = errutils.ErrDatabaseNoRowsReturned
+			}
 
 			repo.
 				EXPECT().
-				UpdateCodeSpace(gomock.Any(), gomock.Any(), codeSpace.ID, &updatedContents).
-				Return(codeSpace, testcase.repoUpdateErr).
+				GetCodeSpaceAliasByName(gomock.Any(), dbTx, newName).
+				Return(testcase.alias, aliasErr).
+				Times(1)
+
+			deleteAliasCalls := 0
+			if testcase.wantAliasDeleted {
+				deleteAliasCalls = 1
+			}
+
+			repo.
+				EXPECT().
+				DeleteCodeSpaceAlias(gomock.Any(), dbTx, gomock.Any()).
+				Return(nil).
+				Times(deleteAliasCalls)
+
+			repo.
+				EXPECT().
+				RenameCodeSpace(gomock.Any(), dbTx, codeSpace.ID, newName, nil).
+				Return(&code.CodeSpace{
+					ID:       codeSpace.ID,
+					Name:     newName,
+					Language: codeSpace.Language,
+				}, testcase.renameErr).
 				MaxTimes(1)
 
+			createAliasCalls := 0
+			if testcase.wantAliasCreated {
+				createAliasCalls = 1
+			}
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceAlias(gomock.Any(), dbTx, &code.CodeSpaceAlias{
+					CodeSpaceID: codeSpace.ID,
+					Name:        oldName,
+				}).
+				Return(&code.CodeSpaceAlias{
+					ID:          9,
+					CodeSpaceID: codeSpace.ID,
+					Name:        oldName,
+				}, nil).
+				Times(createAliasCalls)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpace(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			_, _, err := svc.UpdateCodeSpace(testcase.ctx, codeSpace.Name, &updatedContents)
-			require.Error(t, err)
-
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			renamedCodeSpace, _, err := svc.UpdateCodeSpace(ctx, oldName, &newName, nil, nil, nil)
 			if testcase.wantErr != nil {
 				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
 			}
+
+			require.NoError(t, err)
+			require.Equal(t, newName, renamedCodeSpace.Name)
 		})
 	}
 }
 
-func TestServiceDeleteCodeSpaceAuthorSuccess(t *testing.T) {
+func TestServiceUpdateCodeSpaceVersion(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -1038,120 +1927,148 @@ func TestServiceDeleteCodeSpaceAuthorSuccess(t *testing.T) {
 	repo := code.NewRepository(timeProvider)
 	authRepo := auth.NewRepository(timeProvider)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		TestDBPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
 	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
-	err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
-	require.NoError(t, err)
-
-	dbConn, err := TestDBPool.Acquire(context.Background())
+	staleVersion := codeSpace.Version
+	updatedContents := "print('FizzBuzz')"
+	updatedCodeSpace, _, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &updatedContents, nil, &staleVersion)
 	require.NoError(t, err)
-	defer dbConn.Release()
-
-	_, err = repo.GetCodeSpace(context.Background(), dbConn, codeSpace.ID)
-	require.Error(t, err)
-	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
-}
-
-func TestServiceDeleteCodeSpaceEditorSuccess(t *testing.T) {
-	t.Parallel()
-
-	cfg := testkitinternal.MustCreateConfig()
-
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
-
-	ctrl := gomock.NewController(t)
-	timeProvider := timekeeper.NewFrozenProvider()
-	crypto := cryptocoremocks.NewMockCrypto(ctrl)
-	mailClient := mailclientmocks.NewMockClient(ctrl)
-	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-	pistonClient := pistonmocks.NewMockClient(ctrl)
-	repo := code.NewRepository(timeProvider)
-	authRepo := auth.NewRepository(timeProvider)
-
-	svc := code.NewService(
-		cfg,
-		timeProvider,
-		TestDBPool,
-		crypto,
-		mailClient,
-		tmplManager,
-		pistonClient,
-		repo,
-		authRepo,
+	require.Equal(t, updatedContents, updatedCodeSpace.Contents)
+	require.Equal(t, staleVersion+1, updatedCodeSpace.Version)
+
+	var conflictErr *code.CodeSpaceVersionConflictError
+	conflictingContents := "print('Fizz')"
+	_, _, err = svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &conflictingContents, nil, &staleVersion)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceVersionConflict)
+	require.ErrorAs(t, err, &conflictErr)
+	require.Equal(t, updatedCodeSpace.Version, conflictErr.CodeSpace.Version)
+	require.Equal(t, updatedContents, conflictErr.CodeSpace.Contents)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, conflictErr.CodeSpaceAccess.Level)
+
+	newName := "renamed-" + uuid.NewString()
+	_, _, err = svc.UpdateCodeSpace(ctx, codeSpace.Name, &newName, nil, nil, &staleVersion)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceVersionConflict)
+
+	renamedCodeSpace, _, err := svc.UpdateCodeSpace(
+		ctx,
+		codeSpace.Name,
+		&newName,
+		&conflictingContents,
+		nil,
+		&updatedCodeSpace.Version,
 	)
-
-	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editor.UUID)
-	err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
 	require.NoError(t, err)
+	require.Equal(t, newName, renamedCodeSpace.Name)
+	require.Equal(t, conflictingContents, renamedCodeSpace.Contents)
+	require.Greater(t, renamedCodeSpace.Version, updatedCodeSpace.Version)
 
-	dbConn, err := TestDBPool.Acquire(context.Background())
+	fetchedCodeSpace, _, err := svc.GetCodeSpace(ctx, newName)
 	require.NoError(t, err)
-	defer dbConn.Release()
-
-	_, err = repo.GetCodeSpace(context.Background(), dbConn, codeSpace.ID)
-	require.Error(t, err)
-	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
+	require.Equal(t, renamedCodeSpace.Version, fetchedCodeSpace.Version)
 }
 
-func TestServiceDeleteCodeSpaceFails(t *testing.T) {
+func TestServiceUpdateCodeSpaceVersionConflict(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
-
-	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
+	userUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "print('hello')",
+		Version:  3,
+	}
+	currentCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: "print('hello, world')",
+		Version:  4,
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    userUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+	contents := "print('goodbye')"
+	newName := "renamed-code-space"
 
 	testcases := map[string]struct {
-		userUUID string
-		wantErr  error
+		newName              *string
+		contents             *string
+		version              int64
+		renameErr            error
+		updateErr            error
+		getCurrentErr        error
+		wantGetCurrent       bool
+		wantErr              error
+		wantConflictVersion  int64
+		wantConflictContents string
 	}{
-		"Viewer cannot delete code space": {
-			userUUID: viewer.UUID,
-			wantErr:  errutils.ErrCodeSpaceAccessDenied,
+		"Outdated version": {
+			newName:              nil,
+			contents:             &contents,
+			version:              2,
+			renameErr:            nil,
+			updateErr:            nil,
+			getCurrentErr:        nil,
+			wantGetCurrent:       false,
+			wantErr:              errutils.ErrCodeSpaceVersionConflict,
+			wantConflictVersion:  codeSpace.Version,
+			wantConflictContents: codeSpace.Contents,
 		},
-		"Third party user cannot delete code space": {
-			userUUID: thirdPartyUser.UUID,
-			wantErr:  errutils.ErrCodeSpaceNotFound,
+		"Concurrent update": {
+			newName:              nil,
+			contents:             &contents,
+			version:              codeSpace.Version,
+			renameErr:            nil,
+			updateErr:            errutils.ErrDatabaseNoRowsAffected,
+			getCurrentErr:        nil,
+			wantGetCurrent:       true,
+			wantErr:              errutils.ErrCodeSpaceVersionConflict,
+			wantConflictVersion:  currentCodeSpace.Version,
+			wantConflictContents: currentCodeSpace.Contents,
+		},
+		"Concurrent rename": {
+			newName:              &newName,
+			contents:             nil,
+			version:              codeSpace.Version,
+			renameErr:            errutils.ErrDatabaseNoRowsAffected,
+			updateErr:            nil,
+			getCurrentErr:        nil,
+			wantGetCurrent:       true,
+			wantErr:              errutils.ErrCodeSpaceVersionConflict,
+			wantConflictVersion:  currentCodeSpace.Version,
+			wantConflictContents: currentCodeSpace.Contents,
+		},
+		"Concurrent deletion": {
+			newName:              nil,
+			contents:             &contents,
+			version:              codeSpace.Version,
+			renameErr:            nil,
+			updateErr:            errutils.ErrDatabaseNoRowsAffected,
+			getCurrentErr:        errutils.ErrDatabaseNoRowsReturned,
+			wantGetCurrent:       true,
+			wantErr:              errutils.ErrCodeSpaceNotFound,
+			wantConflictVersion:  0,
+			wantConflictContents: "",
 		},
 	}
 
@@ -1161,34 +2078,125 @@ func TestServiceDeleteCodeSpaceFails(t *testing.T) {
 
 			ctrl := gomock.NewController(t)
 			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
 			pistonClient := pistonmocks.NewMockClient(ctrl)
-			repo := code.NewRepository(timeProvider)
-			authRepo := auth.NewRepository(timeProvider)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
 
-			svc := code.NewService(
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Times(0)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceAliasByName(gomock.Any(), dbTx, newName).
+				Return(nil, errutils.ErrDatabaseNoRowsReturned).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				RenameCodeSpace(gomock.Any(), dbTx, codeSpace.ID, newName, &testcase.version).
+				Return(nil, testcase.renameErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, testcase.contents, nil, &testcase.version).
+				Return(nil, testcase.updateErr).
+				MaxTimes(1)
+
+			getCurrentCalls := 0
+			if testcase.wantGetCurrent {
+				getCurrentCalls = 1
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpace(gomock.Any(), dbTx, codeSpace.ID).
+				Return(currentCodeSpace, testcase.getCurrentErr).
+				Times(getCurrentCalls)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRevision(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
 				cfg,
 				timeProvider,
-				TestDBPool,
+				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
-			err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
-			require.Error(t, err)
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			_, _, err := svc.UpdateCodeSpace(
+				ctx,
+				codeSpace.Name,
+				testcase.newName,
+				testcase.contents,
+				nil,
+				&testcase.version,
+			)
 			require.ErrorIs(t, err, testcase.wantErr)
+
+			var conflictErr *code.CodeSpaceVersionConflictError
+			if !errors.As(err, &conflictErr) {
+				require.NotErrorIs(t, err, errutils.ErrCodeSpaceVersionConflict)
+
+				return
+			}
+
+			require.Equal(t, testcase.wantConflictVersion, conflictErr.CodeSpace.Version)
+			require.Equal(t, testcase.wantConflictContents, conflictErr.CodeSpace.Contents)
+			require.Equal(t, codeSpaceAccess, conflictErr.CodeSpaceAccess)
 		})
 	}
 }
 
-func TestServiceDeleteCodeSpaceError(t *testing.T) {
+func TestServiceUpdateCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -1208,38 +2216,39 @@ func TestServiceDeleteCodeSpaceError(t *testing.T) {
 		Level:       code.CodeSpaceAccessLevelReadWrite,
 	}
 
+	updatedContents := "print('FizzBuzz')"
 	genericRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed")
-	genericRepoDeleteErr := errors.New("DeleteCodeSpace failed")
+	genericRepoUpdateErr := errors.New("UpdateCodeSpace failed")
 
 	testcases := map[string]struct {
 		ctx           context.Context
 		repoGetErr    error
-		repoDeleteErr error
+		repoUpdateErr error
 		wantErr       error
 	}{
 		"No user UUID in context": {
 			ctx:           context.Background(),
 			repoGetErr:    nil,
-			repoDeleteErr: nil,
+			repoUpdateErr: nil,
 			wantErr:       nil,
 		},
 		"GetCodeSpaceWithAccessByName fails, no rows returned": {
 			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
 			repoGetErr:    errutils.ErrDatabaseNoRowsReturned,
-			repoDeleteErr: nil,
+			repoUpdateErr: nil,
 			wantErr:       errutils.ErrCodeSpaceNotFound,
 		},
 		"GetCodeSpaceWithAccessByName fails, generic error": {
 			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
 			repoGetErr:    genericRepoGetErr,
-			repoDeleteErr: nil,
+			repoUpdateErr: nil,
 			wantErr:       genericRepoGetErr,
 		},
-		"DeleteCodeSpace fails": {
+		"UpdateCodeSpace fails": {
 			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
 			repoGetErr:    nil,
-			repoDeleteErr: genericRepoDeleteErr,
-			wantErr:       genericRepoDeleteErr,
+			repoUpdateErr: genericRepoUpdateErr,
+			wantErr:       genericRepoUpdateErr,
 		},
 	}
 
@@ -1251,6 +2260,7 @@ func TestServiceDeleteCodeSpaceError(t *testing.T) {
 			timeProvider := timekeeper.NewFrozenProvider()
 			dbPool := databasemocks.NewMockPool(ctrl)
 			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
@@ -1258,6 +2268,24 @@ func TestServiceDeleteCodeSpaceError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
 			dbConn.
 				EXPECT().
 				Release().
@@ -1277,23 +2305,26 @@ func TestServiceDeleteCodeSpaceError(t *testing.T) {
 
 			repo.
 				EXPECT().
-				DeleteCodeSpace(gomock.Any(), gomock.Any(), codeSpace.ID).
-				Return(testcase.repoDeleteErr).
+				UpdateCodeSpace(gomock.Any(), gomock.Any(), codeSpace.ID, &updatedContents, nil, nil).
+				Return(codeSpace, testcase.repoUpdateErr).
 				MaxTimes(1)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			err := svc.DeleteCodeSpace(testcase.ctx, codeSpace.Name)
+			_, _, err := svc.UpdateCodeSpace(testcase.ctx, codeSpace.Name, nil, &updatedContents, nil, nil)
 			require.Error(t, err)
 
 			if testcase.wantErr != nil {
@@ -1303,7 +2334,7 @@ func TestServiceDeleteCodeSpaceError(t *testing.T) {
 	}
 }
 
-func TestServiceRunCodeSpaceSuccess(t *testing.T) {
+func TestServiceDeleteCodeSpaceAuthorSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -1311,297 +2342,125 @@ func TestServiceRunCodeSpaceSuccess(t *testing.T) {
 	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
 	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	exitCodeZero := 0
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
 
-	testcases := map[string]struct {
-		language            string
-		wantVersion         string
-		wantCompileResponse *api.PistonResults
-		wantRunResponse     api.PistonResults
-	}{
-		"Run C code space": {
-			language:    api.PistonLanguageC,
-			wantVersion: api.PistonVersionC,
-			wantCompileResponse: &api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "",
-				Stderr: "",
-				Output: "",
-			},
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run C++ code space": {
-			language:    api.PistonLanguageCPlusPlus,
-			wantVersion: api.PistonVersionCPlusPlus,
-			wantCompileResponse: &api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "",
-				Stderr: "",
-				Output: "",
-			},
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run Go code space": {
-			language:            api.PistonLanguageGo,
-			wantVersion:         api.PistonVersionGo,
-			wantCompileResponse: nil,
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run Java code space": {
-			language:            api.PistonLanguageJava,
-			wantVersion:         api.PistonVersionJava,
-			wantCompileResponse: nil,
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run JavaScript code space": {
-			language:            api.PistonLanguageJavaScript,
-			wantVersion:         api.PistonVersionJavaScript,
-			wantCompileResponse: nil,
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run Python code space": {
-			language:            api.PistonLanguagePython,
-			wantVersion:         api.PistonVersionPython,
-			wantCompileResponse: nil,
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run Rust code space": {
-			language:    api.PistonLanguageRust,
-			wantVersion: api.PistonVersionRust,
-			wantCompileResponse: &api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "",
-				Stderr: "",
-				Output: "",
-			},
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-		"Run TypeScript code space": {
-			language:            api.PistonLanguageTypeScript,
-			wantVersion:         api.PistonVersionTypeScript,
-			wantCompileResponse: nil,
-			wantRunResponse: api.PistonResults{
-				Code:   &exitCodeZero,
-				Signal: nil,
-				Stdout: "Yello!\n",
-				Stderr: "",
-				Output: "Yello!\n",
-			},
-		},
-	}
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
 
-	for name, testcase := range testcases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-			codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, testcase.language)
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
+	require.NoError(t, err)
 
-			ctrl := gomock.NewController(t)
-			timeProvider := timekeeper.NewFrozenProvider()
-			crypto := cryptocoremocks.NewMockCrypto(ctrl)
-			mailClient := mailclientmocks.NewMockClient(ctrl)
-			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-			pistonClient := piston.NewClient(nil, httputils.NewHTTPClient(nil))
-			repo := code.NewRepository(timeProvider)
-			authRepo := auth.NewRepository(timeProvider)
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
 
-			svc := code.NewService(
-				cfg,
-				timeProvider,
-				TestDBPool,
-				crypto,
-				mailClient,
-				tmplManager,
-				pistonClient,
-				repo,
-				authRepo,
-			)
+	_, err = repo.GetCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
 
-			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
-			pistonResponse, err := svc.RunCodeSpace(ctx, codeSpace.Name)
-			require.NoError(t, err)
-			require.Equal(t, testcase.language, pistonResponse.Language)
-			require.Equal(t, testcase.wantVersion, pistonResponse.Version)
-			require.Equal(t, testcase.wantCompileResponse, pistonResponse.Compile)
-			require.Equal(t, testcase.wantRunResponse, pistonResponse.Run)
-		})
-	}
+	trashedCodeSpace, _, err := repo.GetTrashedCodeSpaceWithAccessByName(
+		context.Background(),
+		dbConn,
+		author.UUID,
+		codeSpace.Name,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, trashedCodeSpace.ID)
+	require.NotNil(t, trashedCodeSpace.DeletedAt)
+	require.WithinDuration(t, timeProvider.Now(), *trashedCodeSpace.DeletedAt, testkit.TimeToleranceExact)
 }
 
-func TestServiceRunCodeSpaceError(t *testing.T) {
+func TestServiceDeleteCodeSpaceEditorSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	authorUUID := uuid.NewString()
-
-	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
-	genericPistonErr := errors.New("Execute failed")
-
-	testcases := map[string]struct {
-		ctx       context.Context
-		language  string
-		repoErr   error
-		pistonErr error
-		wantErr   error
-	}{
-		"No user UUID in context": {
-			ctx:       context.Background(),
-			language:  "python",
-			repoErr:   nil,
-			pistonErr: nil,
-			wantErr:   nil,
-		},
-		"GetCodeSpaceWithAccessByName fails, no rows returned": {
-			ctx:       context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			language:  "python",
-			repoErr:   errutils.ErrDatabaseNoRowsReturned,
-			pistonErr: nil,
-			wantErr:   errutils.ErrCodeSpaceNotFound,
-		},
-		"GetCodeSpaceWithAccessByName fails, generic error": {
-			ctx:       context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			language:  "python",
-			repoErr:   genericRepoErr,
-			pistonErr: nil,
-			wantErr:   genericRepoErr,
-		},
-		"Unknown language": {
-			ctx:       context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			language:  "unknown",
-			repoErr:   nil,
-			pistonErr: nil,
-			wantErr:   nil,
-		},
-		"Execute fails": {
-			ctx:       context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			language:  "python",
-			repoErr:   nil,
-			pistonErr: genericPistonErr,
-			wantErr:   genericPistonErr,
-		},
-	}
-
-	for name, testcase := range testcases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-
-			ctrl := gomock.NewController(t)
-			timeProvider := timekeeper.NewFrozenProvider()
-			dbPool := databasemocks.NewMockPool(ctrl)
-			dbConn := databasemocks.NewMockConn(ctrl)
-			crypto := cryptocoremocks.NewMockCrypto(ctrl)
-			mailClient := mailclientmocks.NewMockClient(ctrl)
-			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-			pistonClient := pistonmocks.NewMockClient(ctrl)
-			repo := codemocks.NewMockRepository(ctrl)
-			authRepo := authmocks.NewMockRepository(ctrl)
-
-			dbConn.
-				EXPECT().
-				Release().
-				MaxTimes(1)
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-			dbPool.
-				EXPECT().
-				Acquire(gomock.Any()).
-				Return(dbConn, nil).
-				MaxTimes(1)
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
 
-			pistonClient.
-				EXPECT().
-				Execute(gomock.Any()).
-				Return(nil, testcase.pistonErr).
-				MaxTimes(1)
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
 
-			codeSpace := &code.CodeSpace{
-				ID:         42,
-				AuthorUUID: &authorUUID,
-				Name:       "habitable-slaking-volatile-granger-mov",
-				Language:   testcase.language,
-				Contents:   "print('hello')",
-			}
-			codeSpaceAccess := &code.CodeSpaceAccess{
-				ID:          314,
-				UserUUID:    authorUUID,
-				CodeSpaceID: codeSpace.ID,
-				Level:       code.CodeSpaceAccessLevelReadWrite,
-			}
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
 
-			repo.
-				EXPECT().
-				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
-				Return(codeSpace, codeSpaceAccess, testcase.repoErr).
-				MaxTimes(1)
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editor.UUID)
+	err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
+	require.NoError(t, err)
 
-			svc := code.NewService(
-				cfg,
-				timeProvider,
-				dbPool,
-				crypto,
-				mailClient,
-				tmplManager,
-				pistonClient,
-				repo,
-				authRepo,
-			)
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
 
-			_, err := svc.RunCodeSpace(testcase.ctx, codeSpace.Name)
-			require.Error(t, err)
+	_, err = repo.GetCodeSpace(context.Background(), dbConn, codeSpace.ID)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errutils.ErrDatabaseNoRowsReturned)
 
-			if testcase.wantErr != nil {
-				require.ErrorIs(t, err, testcase.wantErr)
-			}
-		})
-	}
+	trashedCodeSpace, _, err := repo.GetTrashedCodeSpaceWithAccessByName(
+		context.Background(),
+		dbConn,
+		author.UUID,
+		codeSpace.Name,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, trashedCodeSpace.ID)
+	require.NotNil(t, trashedCodeSpace.DeletedAt)
+	require.WithinDuration(t, timeProvider.Now(), *trashedCodeSpace.DeletedAt, testkit.TimeToleranceExact)
 }
 
-func TestServiceListCodeSpaceUsers(t *testing.T) {
+func TestServiceDeleteCodeSpaceFails(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -1611,16 +2470,6 @@ func TestServiceListCodeSpaceUsers(t *testing.T) {
 	})
 	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
-
 	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
 	})
@@ -1639,19 +2488,11 @@ func TestServiceListCodeSpaceUsers(t *testing.T) {
 		userUUID string
 		wantErr  error
 	}{
-		"Author can list codespace users": {
-			userUUID: author.UUID,
-			wantErr:  nil,
-		},
-		"Editor can list codespace users": {
-			userUUID: editor.UUID,
-			wantErr:  nil,
-		},
-		"Viewer gets access denied": {
+		"Viewer cannot delete code space": {
 			userUUID: viewer.UUID,
 			wantErr:  errutils.ErrCodeSpaceAccessDenied,
 		},
-		"Third party user gets code space not found": {
+		"Third party user cannot delete code space": {
 			userUUID: thirdPartyUser.UUID,
 			wantErr:  errutils.ErrCodeSpaceNotFound,
 		},
@@ -1670,91 +2511,81 @@ func TestServiceListCodeSpaceUsers(t *testing.T) {
 			repo := code.NewRepository(timeProvider)
 			authRepo := auth.NewRepository(timeProvider)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				TestDBPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
 			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
-			users, codeSpaceAccesses, err := svc.ListCodeSpaceUsers(ctx, codeSpace.Name)
-
-			if testcase.wantErr != nil {
-				require.Error(t, err)
-				require.ErrorIs(t, err, testcase.wantErr)
-
-				return
-			}
-
-			require.NoError(t, err)
-			require.Len(t, users, 3)
-			require.Len(t, codeSpaceAccesses, 3)
-
-			userAccessLevelMap := map[string]code.CodeSpaceAccessLevel{
-				author.UUID: code.CodeSpaceAccessLevelReadWrite,
-				editor.UUID: code.CodeSpaceAccessLevelReadWrite,
-				viewer.UUID: code.CodeSpaceAccessLevelReadOnly,
-			}
-
-			for i, codeSpaceAccess := range codeSpaceAccesses {
-				wantAccessLevel, ok := userAccessLevelMap[codeSpaceAccess.UserUUID]
-				require.True(t, ok)
-
-				delete(userAccessLevelMap, codeSpaceAccess.UserUUID)
-
-				require.Equal(t, users[i].UUID, codeSpaceAccess.UserUUID)
-				require.Equal(t, codeSpace.ID, codeSpaceAccess.CodeSpaceID)
-				require.Equal(t, wantAccessLevel, codeSpaceAccess.Level)
-			}
+			err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
+			require.Error(t, err)
+			require.ErrorIs(t, err, testcase.wantErr)
 		})
 	}
 }
 
-func TestServiceListCodeSpaceUsersError(t *testing.T) {
+func TestServiceDeleteCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
 	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
 
 	genericRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed")
-	genericRepoListErr := errors.New("ListUsersWithCodeSpaceAccess failed")
+	genericRepoDeleteErr := errors.New("TrashCodeSpace failed")
 
 	testcases := map[string]struct {
-		ctx         context.Context
-		repoGetErr  error
-		repoListErr error
-		wantErr     error
+		ctx           context.Context
+		repoGetErr    error
+		repoDeleteErr error
+		wantErr       error
 	}{
 		"No user UUID in context": {
-			ctx:         context.Background(),
-			repoGetErr:  nil,
-			repoListErr: nil,
-			wantErr:     nil,
+			ctx:           context.Background(),
+			repoGetErr:    nil,
+			repoDeleteErr: nil,
+			wantErr:       nil,
 		},
 		"GetCodeSpaceWithAccessByName fails, no rows returned": {
-			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:  errutils.ErrDatabaseNoRowsReturned,
-			repoListErr: nil,
-			wantErr:     errutils.ErrCodeSpaceNotFound,
+			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:    errutils.ErrDatabaseNoRowsReturned,
+			repoDeleteErr: nil,
+			wantErr:       errutils.ErrCodeSpaceNotFound,
 		},
 		"GetCodeSpaceWithAccessByName fails, generic error": {
-			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:  genericRepoGetErr,
-			repoListErr: nil,
-			wantErr:     genericRepoGetErr,
+			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:    genericRepoGetErr,
+			repoDeleteErr: nil,
+			wantErr:       genericRepoGetErr,
 		},
-		"ListUsersWithCodeSpaceAccess fails": {
-			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			repoGetErr:  nil,
-			repoListErr: genericRepoListErr,
-			wantErr:     genericRepoListErr,
+		"TrashCodeSpace fails": {
+			ctx:           context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:    nil,
+			repoDeleteErr: genericRepoDeleteErr,
+			wantErr:       genericRepoDeleteErr,
 		},
 	}
 
@@ -1773,20 +2604,6 @@ func TestServiceListCodeSpaceUsersError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
-			codeSpace := &code.CodeSpace{
-				ID:         42,
-				AuthorUUID: &authorUUID,
-				Name:       "habitable-slaking-volatile-granger-mov",
-				Language:   "python",
-				Contents:   "print('hello')",
-			}
-			codeSpaceAccess := &code.CodeSpaceAccess{
-				ID:          314,
-				UserUUID:    authorUUID,
-				CodeSpaceID: codeSpace.ID,
-				Level:       code.CodeSpaceAccessLevelReadWrite,
-			}
-
 			dbConn.
 				EXPECT().
 				Release().
@@ -1806,23 +2623,26 @@ func TestServiceListCodeSpaceUsersError(t *testing.T) {
 
 			repo.
 				EXPECT().
-				ListUsersWithCodeSpaceAccess(gomock.Any(), gomock.Any(), codeSpace.ID).
-				Return(nil, nil, testcase.repoListErr).
+				TrashCodeSpace(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(testcase.repoDeleteErr).
 				MaxTimes(1)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			_, _, err := svc.ListCodeSpaceUsers(testcase.ctx, codeSpace.Name)
+			err := svc.DeleteCodeSpace(testcase.ctx, codeSpace.Name)
 			require.Error(t, err)
 
 			if testcase.wantErr != nil {
@@ -1832,97 +2652,141 @@ func TestServiceListCodeSpaceUsersError(t *testing.T) {
 	}
 }
 
-func TestServiceSendCodeSpaceInvitationMailSuccess(t *testing.T) {
+func TestServiceRestoreCodeSpaceSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	email := testkit.GenerateFakeEmail()
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
 	ctrl := gomock.NewController(t)
 	timeProvider := timekeeper.NewFrozenProvider()
-	dbPool := databasemocks.NewMockPool(ctrl)
-	dbConn := databasemocks.NewMockConn(ctrl)
 	crypto := cryptocoremocks.NewMockCrypto(ctrl)
-	mailClient := testkit.NewInMemMailClient("support@nymphadora.com", timeProvider)
-	tmplManager := templatesmanager.NewManager()
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
 	pistonClient := pistonmocks.NewMockClient(ctrl)
-	repo := codemocks.NewMockRepository(ctrl)
-	authRepo := authmocks.NewMockRepository(ctrl)
-
-	dbConn.
-		EXPECT().
-		Release().
-		MaxTimes(1)
-
-	dbPool.
-		EXPECT().
-		Acquire(gomock.Any()).
-		Return(dbConn, nil).
-		MaxTimes(1)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
-		dbPool,
+		TestDBPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
-	invitationURL := "http://localhost:3000/code/space/" +
-		"habitable-slaking-volatile-granger-mov/invitation/" +
-		"1nv1t4t10njwt"
-	err := svc.SendCodeSpaceInvitationMail(
-		context.Background(),
-		email,
-		templatesmanager.CodeSpaceInvitationEmailTemplateData{
-			InvitationURL: invitationURL,
-		},
-	)
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	err := svc.DeleteCodeSpace(ctx, codeSpace.Name)
 	require.NoError(t, err)
-	require.Len(t, mailClient.Logs, 1)
 
-	lastMail := mailClient.Logs[len(mailClient.Logs)-1]
-	require.Equal(t, []string{email}, lastMail.To)
-	require.Equal(t, "You've been invited to collaborate on a code space!", lastMail.Subject)
-	require.WithinDuration(t, timeProvider.Now(), lastMail.SentAt, testkit.TimeToleranceExact)
+	trashedCodeSpaces, _, err := svc.ListTrashedCodeSpaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashedCodeSpaces, 1)
+	require.Equal(t, codeSpace.ID, trashedCodeSpaces[0].ID)
 
-	mailMessage := string(lastMail.Message)
-	require.Contains(t, mailMessage, "Nymphadora - You've been invited to collaborate on a code space!")
-	require.Contains(t, mailMessage, "Accept Invitation")
-	require.Contains(t, mailMessage, invitationURL)
+	restoredCodeSpace, restoredCodeSpaceAccess, err := svc.RestoreCodeSpace(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, restoredCodeSpace.ID)
+	require.Nil(t, restoredCodeSpace.DeletedAt)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, restoredCodeSpaceAccess.Level)
+
+	fetchedCodeSpace, _, err := svc.GetCodeSpace(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, fetchedCodeSpace.ID)
+
+	trashedCodeSpaces, _, err = svc.ListTrashedCodeSpaces(ctx)
+	require.NoError(t, err)
+	require.Empty(t, trashedCodeSpaces)
 }
 
-func TestServiceSendCodeSpaceInvitationMailError(t *testing.T) {
+func TestServiceRestoreCodeSpaceError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	invitationURL := "http://localhost:3000/code/space/" +
-		"habitable-slaking-volatile-granger-mov/invitation/" +
-		"1nv1t4t10njwt"
-	email := testkit.GenerateFakeEmail()
-	tmplLoadErr := errors.New("Load failed")
-	mailSendErr := errors.New("Send failed")
+	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	authorAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+	viewerAccess := &code.CodeSpaceAccess{
+		ID:          315,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadOnly,
+	}
+
+	genericRepoGetErr := errors.New("GetTrashedCodeSpaceWithAccessByName failed")
+	genericRepoRestoreErr := errors.New("RestoreCodeSpace failed")
 
 	testcases := map[string]struct {
-		tmplLoadErr error
-		mailSendErr error
-		wantErr     error
+		ctx             context.Context
+		codeSpaceAccess *code.CodeSpaceAccess
+		repoGetErr      error
+		repoRestoreErr  error
+		wantErr         error
 	}{
-		"template Load fails": {
-			tmplLoadErr: tmplLoadErr,
-			mailSendErr: nil,
-			wantErr:     tmplLoadErr,
+		"No user UUID in context": {
+			ctx:             context.Background(),
+			codeSpaceAccess: authorAccess,
+			repoGetErr:      nil,
+			repoRestoreErr:  nil,
+			wantErr:         nil,
 		},
-		"mail Send fails": {
-			tmplLoadErr: nil,
-			mailSendErr: mailSendErr,
-			wantErr:     mailSendErr,
+		"GetTrashedCodeSpaceWithAccessByName fails, no rows returned": {
+			ctx:             context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			codeSpaceAccess: authorAccess,
+			repoGetErr:      errutils.ErrDatabaseNoRowsReturned,
+			repoRestoreErr:  nil,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+		},
+		"GetTrashedCodeSpaceWithAccessByName fails, generic error": {
+			ctx:             context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			codeSpaceAccess: authorAccess,
+			repoGetErr:      genericRepoGetErr,
+			repoRestoreErr:  nil,
+			wantErr:         genericRepoGetErr,
+		},
+		"Viewer cannot restore code space": {
+			ctx:             context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			codeSpaceAccess: viewerAccess,
+			repoGetErr:      nil,
+			repoRestoreErr:  nil,
+			wantErr:         errutils.ErrCodeSpaceAccessDenied,
+		},
+		"RestoreCodeSpace fails, no rows affected": {
+			ctx:             context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			codeSpaceAccess: authorAccess,
+			repoGetErr:      nil,
+			repoRestoreErr:  errutils.ErrDatabaseNoRowsAffected,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
+		},
+		"RestoreCodeSpace fails, generic error": {
+			ctx:             context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			codeSpaceAccess: authorAccess,
+			repoGetErr:      nil,
+			repoRestoreErr:  genericRepoRestoreErr,
+			wantErr:         genericRepoRestoreErr,
 		},
 	}
 
@@ -1952,223 +2816,297 @@ func TestServiceSendCodeSpaceInvitationMailError(t *testing.T) {
 				Return(dbConn, nil).
 				MaxTimes(1)
 
+			repo.
+				EXPECT().
+				GetTrashedCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, testcase.codeSpaceAccess, testcase.repoGetErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				RestoreCodeSpace(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(codeSpace, testcase.repoRestoreErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			tmplManager.
-				EXPECT().
-				Load("codespaceinvitation").
-				Return(texttemplate.New("text"), htmltemplate.New("html"), testcase.tmplLoadErr).
-				MaxTimes(1)
-
-			mailClient.
-				EXPECT().
-				Send(
-					[]string{email},
-					"You've been invited to collaborate on a code space!",
-					gomock.Any(),
-					gomock.Any(),
-					gomock.Any(),
-				).
-				Return(testcase.mailSendErr).
-				MaxTimes(1)
+			_, _, err := svc.RestoreCodeSpace(testcase.ctx, codeSpace.Name)
+			require.Error(t, err)
 
-			err := svc.SendCodeSpaceInvitationMail(
-				context.Background(),
-				email,
-				templatesmanager.CodeSpaceInvitationEmailTemplateData{
-					InvitationURL: invitationURL,
-				},
-			)
-			require.ErrorIs(t, err, testcase.wantErr)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
 		})
 	}
 }
 
-func TestServiceInviteCodeSpaceUserSuccess(t *testing.T) {
+func TestServicePurgeTrashedCodeSpaces(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
+	retention := time.Duration(cfg.CodeSpaceTrashRetentionSeconds) * time.Second
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
 
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
 
-	testcases := map[string]struct {
-		inviteeEmail string
-		accessLevel  code.CodeSpaceAccessLevel
-	}{
-		"Author invites editor": {
-			inviteeEmail: editor.Email,
-			accessLevel:  code.CodeSpaceAccessLevelReadWrite,
-		},
-		"Author invites viewer": {
-			inviteeEmail: viewer.Email,
-			accessLevel:  code.CodeSpaceAccessLevelReadOnly,
-		},
-	}
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
 
-	for name, testcase := range testcases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
+	repo.
+		EXPECT().
+		DeleteTrashedCodeSpaces(gomock.Any(), dbConn, timeProvider.Now().Add(-retention)).
+		Return(int64(3), nil).
+		Times(1)
 
-			ctrl := gomock.NewController(t)
-			timeProvider := timekeeper.NewFrozenProvider()
-			crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
-			mailClient := testkit.NewInMemMailClient("support@nymphadora.com", timeProvider)
-			tmplManager := templatesmanager.NewManager()
-			pistonClient := pistonmocks.NewMockClient(ctrl)
-			repo := code.NewRepository(timeProvider)
-			authRepo := auth.NewRepository(timeProvider)
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
 
-			svc := code.NewService(
-				cfg,
-				timeProvider,
-				TestDBPool,
-				crypto,
-				mailClient,
-				tmplManager,
-				pistonClient,
-				repo,
-				authRepo,
-			)
+	purgedCount, err := svc.PurgeTrashedCodeSpaces(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), purgedCount)
+}
 
-			mailCount := len(mailClient.Logs)
+func TestServicePurgeTrashedCodeSpacesEvery(t *testing.T) {
+	t.Parallel()
 
-			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
-			err := svc.InviteCodeSpaceUser(ctx, codeSpace.Name, testcase.inviteeEmail, testcase.accessLevel)
-			require.NoError(t, err)
+	cfg := testkitinternal.MustCreateConfig()
 
-			require.Len(t, mailClient.Logs, mailCount+1)
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
 
-			lastMail := mailClient.Logs[len(mailClient.Logs)-1]
-			require.Equal(t, []string{testcase.inviteeEmail}, lastMail.To)
-			require.Equal(t, "You've been invited to collaborate on a code space!", lastMail.Subject)
-			require.WithinDuration(t, timeProvider.Now(), lastMail.SentAt, testkit.TimeToleranceExact)
+	dbConn.
+		EXPECT().
+		Release().
+		MinTimes(1)
 
-			mailMessage := string(lastMail.Message)
-			require.Contains(t, mailMessage, "Nymphadora - You've been invited to collaborate on a code space!")
-			require.Contains(t, mailMessage, "Accept Invitation")
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		MinTimes(1)
 
-			pattern := fmt.Sprintf(
-				cfg.FrontendBaseURL+code.FrontendCodeSpaceInvitationRoute,
-				codeSpace.Name,
-				`(\S+)`,
-			)
-			r, err := regexp.Compile(pattern)
-			require.NoError(t, err)
+	purgeErr := errors.New("DeleteTrashedCodeSpaces failed")
+	purged := make(chan struct{})
+	repo.
+		EXPECT().
+		DeleteTrashedCodeSpaces(gomock.Any(), dbConn, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ database.Querier, _ time.Time) (int64, error) {
+			select {
+			case purged <- struct{}{}:
+			default:
+			}
 
-			matches := r.FindStringSubmatch(mailMessage)
-			require.Len(t, matches, 2)
+			return 0, purgeErr
+		}).
+		MinTimes(1)
 
-			invitationToken := matches[1]
-			claims := &cryptocore.CodeSpaceInvitationJWTClaims{}
-			parsedToken, err := jwt.ParseWithClaims(invitationToken, claims, func(t *jwt.Token) (any, error) {
-				return []byte(cfg.SecretKey), nil
-			})
-			require.NoError(t, err)
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
 
-			require.NotNil(t, parsedToken)
-			require.True(t, parsedToken.Valid)
-			require.Equal(t, author.UUID, claims.Subject)
-			require.Equal(t, testcase.inviteeEmail, claims.InviteeEmail)
-			require.Equal(t, string(cryptocore.JWTTypeCodeSpaceInvitation), claims.TokenType)
-			require.WithinDuration(t, timeProvider.Now(), time.Time(claims.IssuedAt), testkit.TimeToleranceExact)
-			require.WithinDuration(
-				t,
-				timeProvider.Now().Add(cryptocore.JWTLifetimeCodeSpaceInvitation),
-				time.Time(claims.ExpiresAt),
-				testkit.TimeToleranceExact,
-			)
-		})
+	stop := svc.PurgeTrashedCodeSpacesEvery(time.Millisecond, func(err error) {
+		assert.ErrorIs(t, err, purgeErr)
+	})
+
+	select {
+	case <-purged:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "trash was not purged")
 	}
+
+	stop()
+	stop()
 }
 
-func TestServiceInviteCodeSpaceUserError(t *testing.T) {
+func TestServiceCodeSpaceRevisionsSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	authorUUID := uuid.NewString()
-	inviteeEmail := testkit.GenerateFakeEmail()
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
 
-	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
-	createJWTErr := errors.New("CreateCodeSpaceInvitationJWT failed")
-	mailClientErr := errors.New("Send failed")
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	contents := "print('first')\nprint('second')\n"
+	timeProvider.Add(time.Minute)
+	updatedCodeSpace, _, err := svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, &contents, nil, nil)
+	require.NoError(t, err)
+
+	codeSpaceRevisions, err := svc.ListCodeSpaceRevisions(ctx, codeSpace.Name, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceRevisions, 2)
+
+	latestCodeSpaceRevision := codeSpaceRevisions[0]
+	require.Equal(t, contents, latestCodeSpaceRevision.Contents)
+	require.Equal(t, code.HashCodeSpaceContents(contents), latestCodeSpaceRevision.ContentsHash)
+	require.NotNil(t, latestCodeSpaceRevision.AuthorUUID)
+	require.Equal(t, author.UUID, *latestCodeSpaceRevision.AuthorUUID)
+
+	firstCodeSpaceRevision := codeSpaceRevisions[1]
+	require.Equal(t, codeSpace.Contents, firstCodeSpaceRevision.Contents)
+
+	fetchedCodeSpaceRevision, err := svc.GetCodeSpaceRevision(ctx, codeSpace.Name, firstCodeSpaceRevision.ID)
+	require.NoError(t, err)
+	require.Equal(t, firstCodeSpaceRevision.ID, fetchedCodeSpaceRevision.ID)
+
+	diff, err := svc.DiffCodeSpaceRevisions(
+		ctx,
+		codeSpace.Name,
+		firstCodeSpaceRevision.ID,
+		latestCodeSpaceRevision.ID,
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		code.UnifiedDiff("a/main.py", "b/main.py", codeSpace.Contents, contents),
+		diff,
+	)
+	require.Contains(t, diff, "+print('second')\n")
+
+	_, _, err = svc.RestoreCodeSpaceRevision(ctx, codeSpace.Name, firstCodeSpaceRevision.ID, &codeSpace.Version)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceVersionConflict)
+
+	timeProvider.Add(time.Minute)
+	restoredCodeSpace, _, err := svc.RestoreCodeSpaceRevision(
+		ctx,
+		codeSpace.Name,
+		firstCodeSpaceRevision.ID,
+		&updatedCodeSpace.Version,
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.Contents, restoredCodeSpace.Contents)
+	require.Equal(t, updatedCodeSpace.Version+1, restoredCodeSpace.Version)
+
+	codeSpaceRevisions, err = svc.ListCodeSpaceRevisions(ctx, codeSpace.Name, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceRevisions, 3)
+	require.NotEqual(t, firstCodeSpaceRevision.ID, codeSpaceRevisions[0].ID)
+	require.Equal(t, codeSpace.Contents, codeSpaceRevisions[0].Contents)
+	require.Equal(t, firstCodeSpaceRevision.ContentsHash, codeSpaceRevisions[0].ContentsHash)
+}
+
+func TestServiceRestoreCodeSpaceRevisionError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	userUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "print('hello')",
+	}
 
 	testcases := map[string]struct {
-		ctx               context.Context
-		authorAccessLevel code.CodeSpaceAccessLevel
-		repoErr           error
-		createJWTErr      error
-		mailClientErr     error
-		wantErr           error
+		accessLevel     code.CodeSpaceAccessLevel
+		getCodeSpaceErr error
+		getRevisionErr  error
+		wantErr         error
 	}{
-		"No user UUID in context": {
-			ctx:               context.Background(),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-			repoErr:           nil,
-			createJWTErr:      nil,
-			mailClientErr:     nil,
-			wantErr:           nil,
-		},
-		"Access denied without write access": {
-			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadOnly,
-			repoErr:           nil,
-			createJWTErr:      nil,
-			mailClientErr:     nil,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"GetCodeSpaceWithAccessByName fails, no rows returned": {
-			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-			repoErr:           errutils.ErrDatabaseNoRowsReturned,
-			createJWTErr:      nil,
-			mailClientErr:     nil,
-			wantErr:           errutils.ErrCodeSpaceNotFound,
-		},
-		"GetCodeSpaceWithAccessByName fails, generic error": {
-			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-			repoErr:           genericRepoErr,
-			createJWTErr:      nil,
-			mailClientErr:     nil,
-			wantErr:           genericRepoErr,
+		"Code space not found": {
+			accessLevel:     code.CodeSpaceAccessLevelReadWrite,
+			getCodeSpaceErr: errutils.ErrDatabaseNoRowsReturned,
+			getRevisionErr:  nil,
+			wantErr:         errutils.ErrCodeSpaceNotFound,
 		},
-		"CreateCodeSpaceInvitationJWT fails": {
-			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-			repoErr:           nil,
-			createJWTErr:      createJWTErr,
-			mailClientErr:     nil,
-			wantErr:           createJWTErr,
+		"Read-only access": {
+			accessLevel:     code.CodeSpaceAccessLevelReadOnly,
+			getCodeSpaceErr: nil,
+			getRevisionErr:  nil,
+			wantErr:         errutils.ErrCodeSpaceAccessDenied,
 		},
-		"mailClient.Send fails": {
-			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
-			repoErr:           nil,
-			createJWTErr:      nil,
-			mailClientErr:     mailClientErr,
-			wantErr:           mailClientErr,
+		"Revision not found": {
+			accessLevel:     code.CodeSpaceAccessLevelReadWrite,
+			getCodeSpaceErr: nil,
+			getRevisionErr:  errutils.ErrDatabaseNoRowsReturned,
+			wantErr:         errutils.ErrCodeSpaceRevisionNotFound,
 		},
 	}
 
@@ -2180,6 +3118,7 @@ func TestServiceInviteCodeSpaceUserError(t *testing.T) {
 			timeProvider := timekeeper.NewFrozenProvider()
 			dbPool := databasemocks.NewMockPool(ctrl)
 			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
@@ -2187,314 +3126,7893 @@ func TestServiceInviteCodeSpaceUserError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
-			codeSpace := &code.CodeSpace{
-				ID:         42,
-				AuthorUUID: &authorUUID,
-				Name:       "habitable-slaking-volatile-granger-mov",
-				Language:   "python",
-				Contents:   "print('hello')",
-			}
-			codeSpaceAccess := &code.CodeSpaceAccess{
-				ID:          314,
-				UserUUID:    authorUUID,
-				CodeSpaceID: codeSpace.ID,
-				Level:       testcase.authorAccessLevel,
-			}
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Times(0)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
 
 			dbConn.
 				EXPECT().
-				Release().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
 				MaxTimes(1)
 
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
 			dbPool.
 				EXPECT().
 				Acquire(gomock.Any()).
 				Return(dbConn, nil).
-				MaxTimes(1)
+				Times(1)
 
 			repo.
 				EXPECT().
-				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
-				Return(codeSpace, codeSpaceAccess, testcase.repoErr).
-				MaxTimes(1)
+				GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+				Return(codeSpace, &code.CodeSpaceAccess{
+					ID:          314,
+					UserUUID:    userUUID,
+					CodeSpaceID: codeSpace.ID,
+					Level:       testcase.accessLevel,
+				}, testcase.getCodeSpaceErr).
+				Times(1)
 
-			crypto.
+			repo.
 				EXPECT().
-				CreateCodeSpaceInvitationJWT(
-					authorUUID,
-					inviteeEmail,
-					codeSpace.ID,
-					int(code.CodeSpaceAccessLevelReadWrite),
-				).
-				Return("1nv1t4t10nt0k3n", testcase.createJWTErr).
+				GetCodeSpaceRevision(gomock.Any(), dbTx, codeSpace.ID, int64(7)).
+				Return(nil, testcase.getRevisionErr).
 				MaxTimes(1)
 
-			tmplManager.
+			repo.
 				EXPECT().
-				Load("codespaceinvitation").
-				Return(texttemplate.New("text"), htmltemplate.New("html"), nil).
-				MaxTimes(1)
+				UpdateCodeSpace(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
 
-			mailClient.
+			repo.
 				EXPECT().
-				Send(
-					[]string{inviteeEmail},
-					"You've been invited to collaborate on a code space!",
-					gomock.Any(),
-					gomock.Any(),
-					gomock.Any(),
-				).
-				Return(testcase.mailClientErr).
-				MaxTimes(1)
+				CreateCodeSpaceRevision(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			err := svc.InviteCodeSpaceUser(
-				testcase.ctx,
-				codeSpace.Name,
-				inviteeEmail,
-				code.CodeSpaceAccessLevelReadWrite,
-			)
-			require.Error(t, err)
-
-			if testcase.wantErr != nil {
-				require.ErrorIs(t, err, testcase.wantErr)
-			}
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			_, _, err := svc.RestoreCodeSpaceRevision(ctx, codeSpace.Name, 7, nil)
+			require.ErrorIs(t, err, testcase.wantErr)
 		})
 	}
 }
 
-func TestServiceRemoveCodeSpaceUserAuthorCanRemoveViewer(t *testing.T) {
+func TestServiceRestoreCodeSpaceRevisionVersionConflict(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	userUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "print('hello')",
+		Version:  3,
+	}
+	currentCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: "print('hello, world')",
+		Version:  4,
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    userUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+	codeSpaceRevision := &code.CodeSpaceRevision{
+		ID:          7,
+		CodeSpaceID: codeSpace.ID,
+		Contents:    "print('goodbye')",
+	}
 
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
+	testcases := map[string]struct {
+		version              int64
+		updateErr            error
+		getCurrentErr        error
+		wantGetCurrent       bool
+		wantErr              error
+		wantConflictVersion  int64
+		wantConflictContents string
+	}{
+		"Outdated version": {
+			version:              2,
+			updateErr:            nil,
+			getCurrentErr:        nil,
+			wantGetCurrent:       false,
+			wantErr:              errutils.ErrCodeSpaceVersionConflict,
+			wantConflictVersion:  codeSpace.Version,
+			wantConflictContents: codeSpace.Contents,
+		},
+		"Concurrent update": {
+			version:              codeSpace.Version,
+			updateErr:            errutils.ErrDatabaseNoRowsAffected,
+			getCurrentErr:        nil,
+			wantGetCurrent:       true,
+			wantErr:              errutils.ErrCodeSpaceVersionConflict,
+			wantConflictVersion:  currentCodeSpace.Version,
+			wantConflictContents: currentCodeSpace.Contents,
+		},
+		"Concurrent deletion": {
+			version:              codeSpace.Version,
+			updateErr:            errutils.ErrDatabaseNoRowsAffected,
+			getCurrentErr:        errutils.ErrDatabaseNoRowsReturned,
+			wantGetCurrent:       true,
+			wantErr:              errutils.ErrCodeSpaceNotFound,
+			wantConflictVersion:  0,
+			wantConflictContents: "",
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Times(0)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceRevision(gomock.Any(), dbTx, codeSpace.ID, codeSpaceRevision.ID).
+				Return(codeSpaceRevision, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpace(
+					gomock.Any(),
+					dbTx,
+					codeSpace.ID,
+					&codeSpaceRevision.Contents,
+					nil,
+					&testcase.version,
+				).
+				Return(nil, testcase.updateErr).
+				MaxTimes(1)
+
+			getCurrentCalls := 0
+			if testcase.wantGetCurrent {
+				getCurrentCalls = 1
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpace(gomock.Any(), dbTx, codeSpace.ID).
+				Return(currentCodeSpace, testcase.getCurrentErr).
+				Times(getCurrentCalls)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRevision(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			_, _, err := svc.RestoreCodeSpaceRevision(ctx, codeSpace.Name, codeSpaceRevision.ID, &testcase.version)
+			require.ErrorIs(t, err, testcase.wantErr)
+
+			var conflictErr *code.CodeSpaceVersionConflictError
+			if !errors.As(err, &conflictErr) {
+				require.NotErrorIs(t, err, errutils.ErrCodeSpaceVersionConflict)
+
+				return
+			}
+
+			require.Equal(t, testcase.wantConflictVersion, conflictErr.CodeSpace.Version)
+			require.Equal(t, testcase.wantConflictContents, conflictErr.CodeSpace.Contents)
+			require.Equal(t, codeSpaceAccess, conflictErr.CodeSpaceAccess)
+		})
+	}
+}
+
+func TestServiceThinCodeSpaceRevisions(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+	retention := time.Duration(cfg.CodeSpaceHistoryRetentionSeconds) * time.Second
 
 	ctrl := gomock.NewController(t)
 	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
 	crypto := cryptocoremocks.NewMockCrypto(ctrl)
 	mailClient := mailclientmocks.NewMockClient(ctrl)
 	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
 	pistonClient := pistonmocks.NewMockClient(ctrl)
-	repo := code.NewRepository(timeProvider)
-	authRepo := auth.NewRepository(timeProvider)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		DeleteThinnedCodeSpaceRevisions(gomock.Any(), dbConn, timeProvider.Now().Add(-retention)).
+		Return(int64(5), nil).
+		Times(1)
 
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
-		TestDBPool,
+		dbPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
-	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
-	err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, viewer.UUID)
-	require.NoError(t, err)
-
-	dbConn, err := TestDBPool.Acquire(context.Background())
-	require.NoError(t, err)
-	defer dbConn.Release()
-
-	users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	thinnedCount, err := svc.ThinCodeSpaceRevisions(context.Background())
 	require.NoError(t, err)
-
-	userUUIDs := make([]string, len(users))
-	for i, user := range users {
-		userUUIDs[i] = user.UUID
-	}
-
-	require.NotContains(t, userUUIDs, viewer.UUID)
+	require.Equal(t, int64(5), thinnedCount)
 }
 
-func TestServiceRemoveCodeSpaceUserEditorCanRemoveViewer(t *testing.T) {
+func TestServiceCodeSpaceCollaboration(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
-
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
-	)
-
 	ctrl := gomock.NewController(t)
 	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
 	crypto := cryptocoremocks.NewMockCrypto(ctrl)
 	mailClient := mailclientmocks.NewMockClient(ctrl)
 	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
 	pistonClient := pistonmocks.NewMockClient(ctrl)
-	repo := code.NewRepository(timeProvider)
-	authRepo := auth.NewRepository(timeProvider)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	writerUser := &auth.User{
+		UUID:      uuid.NewString(),
+		FirstName: "Harry",
+		LastName:  "Potter",
+	}
+	readerUser := &auth.User{
+		UUID:      uuid.NewString(),
+		FirstName: "Ronald",
+		LastName:  "Weasley",
+	}
+	writerUUID := writerUser.UUID
+	readerUUID := readerUser.UUID
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "hello world",
+		Version:  1,
+	}
+	savedContents := ">> hello world!"
+	savedCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: savedContents,
+		Version:  2,
+	}
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(5)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(5)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, writerUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadWrite}, nil).
+		Times(2)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, readerUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadOnly}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+		Return(
+			[]*auth.User{writerUser, readerUser},
+			[]*code.CodeSpaceAccess{
+				{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadWrite},
+				{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadOnly},
+			},
+			nil,
+		).
+		Times(3)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &savedContents, nil, &codeSpace.Version).
+		Return(savedCodeSpace, nil).
+		Times(1)
 
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			querier database.Querier,
+			codeSpaceRevision *code.CodeSpaceRevision,
+		) (*code.CodeSpaceRevision, error) {
+			require.Equal(t, codeSpace.ID, codeSpaceRevision.CodeSpaceID)
+			require.Equal(t, &writerUUID, codeSpaceRevision.AuthorUUID)
+			require.Equal(t, savedContents, codeSpaceRevision.Contents)
+
+			return codeSpaceRevision, nil
+		}).
+		Times(1)
+
+	_, _, logger := testkit.CreateInMemLogger()
 	svc := code.NewService(
 		cfg,
 		timeProvider,
-		TestDBPool,
+		dbPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
 
-	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editor.UUID)
-	err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, viewer.UUID)
+	writerCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, writerUUID)
+	readerCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, readerUUID)
+
+	writer, err := svc.JoinCodeSpaceCollaboration(writerCtx, codeSpace.Name)
 	require.NoError(t, err)
+	require.Equal(t, writerUUID, writer.UserUUID)
+	require.Equal(t, code.CodeSpaceAccessLevelReadWrite, writer.AccessLevel)
 
-	dbConn, err := TestDBPool.Acquire(context.Background())
+	event := <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, event.Type)
+	require.Equal(t, int64(0), event.Revision)
+	require.Equal(t, codeSpace.Contents, event.Contents)
+	require.Equal(t, writer.SessionID, event.Presence.SessionID)
+	require.Empty(t, event.Collaborators)
+
+	reader, err := svc.JoinCodeSpaceCollaboration(readerCtx, codeSpace.Name)
 	require.NoError(t, err)
-	defer dbConn.Release()
+	require.Equal(t, code.CodeSpaceAccessLevelReadOnly, reader.AccessLevel)
 
-	users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	event = <-reader.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, event.Type)
+	require.Equal(t, codeSpace.Contents, event.Contents)
+	require.Len(t, event.Collaborators, 1)
+	require.Equal(t, writer.SessionID, event.Collaborators[0].SessionID)
+
+	event = <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeJoin, event.Type)
+	require.Equal(t, reader.SessionID, event.Presence.SessionID)
+	require.Equal(t, readerUser.FirstName, event.Presence.FirstName)
+	require.Equal(t, readerUser.LastName, event.Presence.LastName)
+
+	err = writer.Edit(0, code.EditOperation{{Retain: 11}, {Insert: "!"}})
+	require.NoError(t, err)
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:     api.CodeSpaceCollabEventTypeAck,
+		Revision: 1,
+	}, <-writer.Events())
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:      api.CodeSpaceCollabEventTypeEdit,
+		Revision:  1,
+		Operation: code.EditOperation{{Retain: 11}, {Insert: "!"}},
+		UserUUID:  writerUUID,
+	}, <-reader.Events())
+
+	err = reader.Edit(1, code.EditOperation{{Delete: 12}})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceAccessDenied)
+
+	// edits based on older revisions are transformed against the edits made since
+	err = writer.Edit(0, code.EditOperation{{Insert: ">> "}, {Retain: 11}})
 	require.NoError(t, err)
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:     api.CodeSpaceCollabEventTypeAck,
+		Revision: 2,
+	}, <-writer.Events())
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:      api.CodeSpaceCollabEventTypeEdit,
+		Revision:  2,
+		Operation: code.EditOperation{{Insert: ">> "}, {Retain: 12}},
+		UserUUID:  writerUUID,
+	}, <-reader.Events())
+
+	err = writer.Edit(3, code.EditOperation{{Retain: 15}})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceEditInvalid)
+
+	err = writer.Edit(2, code.EditOperation{{Retain: 11}})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceEditInvalid)
+
+	savedCount, err := svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), savedCount)
 
-	userUUIDs := make([]string, len(users))
-	for i, user := range users {
-		userUUIDs[i] = user.UUID
-	}
+	writer.Leave()
 
-	require.NotContains(t, userUUIDs, viewer.UUID)
+	_, ok := <-writer.Events()
+	require.False(t, ok)
+
+	event = <-reader.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeLeave, event.Type)
+	require.Equal(t, writer.SessionID, event.Presence.SessionID)
+
+	reader.Leave()
+
+	_, ok = <-reader.Events()
+	require.False(t, ok)
+
+	err = writer.Edit(2, code.EditOperation{{Retain: 15}})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceCollabClosed)
+
+	// saved code spaces without collaborators are unloaded, so joining again loads them from the repository
+	savedCount, err = svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), savedCount)
+
+	writer, err = svc.JoinCodeSpaceCollaboration(writerCtx, codeSpace.Name)
+	require.NoError(t, err)
+
+	event = <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, event.Type)
+	require.Equal(t, int64(0), event.Revision)
+	require.Equal(t, codeSpace.Contents, event.Contents)
 }
 
-func TestServiceRemoveCodeSpaceUserSelfRemoval(t *testing.T) {
+func TestServiceCodeSpacePresence(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
-	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
 
-	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		editor.UUID,
-		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
-	)
+	writerUser := &auth.User{
+		UUID:      uuid.NewString(),
+		FirstName: "Harry",
+		LastName:  "Potter",
+	}
+	readerUser := &auth.User{
+		UUID:      uuid.NewString(),
+		FirstName: "Ronald",
+		LastName:  "Weasley",
+	}
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "hello world",
+		Version:  1,
+	}
+	writerAccess := &code.CodeSpaceAccess{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadWrite}
+	readerAccess := &code.CodeSpaceAccess{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadOnly}
+	renamedWriterUser := &auth.User{
+		UUID:      writerUser.UUID,
+		FirstName: "Harry James",
+		LastName:  "Potter",
+	}
 
-	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
-		t,
-		viewer.UUID,
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(5)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(5)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, writerUser.UUID, codeSpace.Name).
+		Return(codeSpace, writerAccess, nil).
+		Times(3)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, readerUser.UUID, codeSpace.Name).
+		Return(codeSpace, readerAccess, nil).
+		Times(1)
+
+	gomock.InOrder(
+		repo.
+			EXPECT().
+			ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+			Return([]*auth.User{writerUser, readerUser}, []*code.CodeSpaceAccess{writerAccess, readerAccess}, nil).
+			Times(3),
+		repo.
+			EXPECT().
+			ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+			Return([]*auth.User{renamedWriterUser}, []*code.CodeSpaceAccess{writerAccess}, nil).
+			Times(1),
+	)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, gomock.Any(), nil, &codeSpace.Version).
+		Return(codeSpace, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+		Return(&code.CodeSpaceRevision{}, nil).
+		Times(1)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	writerCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, writerUser.UUID)
+	readerCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, readerUser.UUID)
+
+	writer, err := svc.JoinCodeSpaceCollaboration(writerCtx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, (<-writer.Events()).Type)
+
+	timeProvider.Add(time.Second)
+
+	reader, err := svc.JoinCodeSpaceCollaboration(readerCtx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, (<-reader.Events()).Type)
+	require.Equal(t, api.CodeSpaceCollabEventTypeJoin, (<-writer.Events()).Type)
+
+	err = reader.MoveCursor(0, &code.CodeSpaceCursor{Position: 5, Anchor: 0})
+	require.NoError(t, err)
+
+	event := <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeCursor, event.Type)
+	require.Equal(t, reader.SessionID, event.Presence.SessionID)
+	require.Equal(t, &code.CodeSpaceCursor{Position: 5, Anchor: 0}, event.Presence.Cursor)
+
+	err = reader.MoveCursor(0, &code.CodeSpaceCursor{Position: 12, Anchor: 0})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceCursorInvalid)
+
+	// cursors move along with the edited text
+	err = writer.Edit(0, code.EditOperation{{Insert: ">> "}, {Retain: 11}})
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeAck, (<-writer.Events()).Type)
+	require.Equal(t, api.CodeSpaceCollabEventTypeEdit, (<-reader.Events()).Type)
+
+	presences, err := svc.ListCodeSpacePresence(writerCtx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Len(t, presences, 2)
+	require.Equal(t, writer.SessionID, presences[0].SessionID)
+	require.Nil(t, presences[0].Cursor)
+	require.Equal(t, reader.SessionID, presences[1].SessionID)
+	require.Equal(t, readerUser.FirstName, presences[1].FirstName)
+	require.Equal(t, code.CodeSpaceAccessLevelReadOnly, presences[1].AccessLevel)
+	require.Equal(t, &code.CodeSpaceCursor{Position: 8, Anchor: 3}, presences[1].Cursor)
+
+	// cursors based on older revisions are transformed against the edits made since
+	err = reader.MoveCursor(0, &code.CodeSpaceCursor{Position: 11, Anchor: 11})
+	require.NoError(t, err)
+
+	event = <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeCursor, event.Type)
+	require.Equal(t, &code.CodeSpaceCursor{Position: 14, Anchor: 14}, event.Presence.Cursor)
+
+	// collaborators whose access has been removed are left out, and names are as they are now
+	presences, err = svc.ListCodeSpacePresence(writerCtx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Len(t, presences, 1)
+	require.Equal(t, writer.SessionID, presences[0].SessionID)
+	require.Equal(t, renamedWriterUser.FirstName, presences[0].FirstName)
+
+	// collaborators that are not heard from within the timeout are disconnected
+	presenceTimeout := time.Duration(cfg.CodeSpacePresenceTimeoutSeconds) * time.Second
+	timeProvider.Add(presenceTimeout / 2)
+	writer.Heartbeat()
+	timeProvider.Add(presenceTimeout/2 + time.Second)
+
+	savedCount, err := svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), savedCount)
+
+	_, ok := <-reader.Events()
+	require.False(t, ok)
+
+	event = <-writer.Events()
+	require.Equal(t, api.CodeSpaceCollabEventTypeLeave, event.Type)
+	require.Equal(t, reader.SessionID, event.Presence.SessionID)
+
+	err = reader.MoveCursor(1, &code.CodeSpaceCursor{Position: 0, Anchor: 0})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceCollabClosed)
+}
+
+func TestServiceSaveCodeSpaceCollaborationsTrashed(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	userUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "hello world",
+		Version:  1,
+	}
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(3)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(3)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadWrite}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+		Return(
+			[]*auth.User{{UUID: userUUID}},
+			[]*code.CodeSpaceAccess{{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadWrite}},
+			nil,
+		).
+		Times(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Times(0)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, gomock.Any(), nil, &codeSpace.Version).
+		Return(nil, errutils.ErrDatabaseNoRowsAffected).
+		Times(1)
+
+	repo.
+		EXPECT().
+		GetCodeSpace(gomock.Any(), dbConn, codeSpace.ID).
+		Return(nil, errutils.ErrDatabaseNoRowsReturned).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+	collaborator, err := svc.JoinCodeSpaceCollaboration(ctx, codeSpace.Name)
+	require.NoError(t, err)
+
+	err = collaborator.Edit(0, code.EditOperation{{Retain: 11}, {Insert: "!"}})
+	require.NoError(t, err)
+
+	savedCount, err := svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), savedCount)
+
+	events := make([]string, 0)
+	for event := range collaborator.Events() {
+		events = append(events, event.Type)
+	}
+
+	require.Equal(t, []string{api.CodeSpaceCollabEventTypeSnapshot, api.CodeSpaceCollabEventTypeAck}, events)
+
+	err = collaborator.Edit(1, code.EditOperation{{Retain: 12}})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceCollabClosed)
+
+	// closed code spaces are no longer saved
+	savedCount, err = svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), savedCount)
+}
+
+func TestServiceSaveCodeSpaceCollaborationsVersionConflict(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	userUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "print('a')\nprint('b')\n",
+		Version:  1,
+	}
+	collabContents := "print('a')\nprint('b')\nprint('c')\n"
+	currentCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: "print('A')\nprint('b')\n",
+		Version:  2,
+	}
+	rebasedContents := "print('A')\nprint('b')\nprint('c')\n"
+	savedCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: rebasedContents,
+		Version:  3,
+	}
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(3)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(3)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, userUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadWrite}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+		Return(
+			[]*auth.User{{UUID: userUUID}},
+			[]*code.CodeSpaceAccess{{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadWrite}},
+			nil,
+		).
+		Times(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(2)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(2)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	gomock.InOrder(
+		repo.
+			EXPECT().
+			UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &collabContents, nil, &codeSpace.Version).
+			Return(nil, errutils.ErrDatabaseNoRowsAffected).
+			Times(1),
+		repo.
+			EXPECT().
+			GetCodeSpace(gomock.Any(), dbConn, codeSpace.ID).
+			Return(currentCodeSpace, nil).
+			Times(1),
+		repo.
+			EXPECT().
+			UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &rebasedContents, nil, &currentCodeSpace.Version).
+			Return(savedCodeSpace, nil).
+			Times(1),
+	)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			querier database.Querier,
+			codeSpaceRevision *code.CodeSpaceRevision,
+		) (*code.CodeSpaceRevision, error) {
+			require.Equal(t, codeSpace.ID, codeSpaceRevision.CodeSpaceID)
+			require.Equal(t, &userUUID, codeSpaceRevision.AuthorUUID)
+			require.Equal(t, rebasedContents, codeSpaceRevision.Contents)
+
+			return codeSpaceRevision, nil
+		}).
+		Times(1)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+	collaborator, err := svc.JoinCodeSpaceCollaboration(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, (<-collaborator.Events()).Type)
+
+	err = collaborator.Edit(0, code.EditOperation{{Retain: 22}, {Insert: "print('c')\n"}})
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeAck, (<-collaborator.Events()).Type)
+
+	// the code space was saved outside of collaborative editing since it was loaded,
+	// so the document is rebased onto it before being saved, keeping the edits of both
+	savedCount, err := svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), savedCount)
+
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:      api.CodeSpaceCollabEventTypeEdit,
+		Revision:  2,
+		Operation: code.EditOperation{{Insert: "print('A')\n"}, {Delete: 11}, {Retain: 22}},
+		UserUUID:  "",
+	}, <-collaborator.Events())
+
+	// saved code spaces are saved over the version they were saved at
+	err = collaborator.Edit(2, code.EditOperation{{Retain: 33}})
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeAck, (<-collaborator.Events()).Type)
+
+	savedContents := rebasedContents
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &savedContents, nil, &savedCodeSpace.Version).
+		Return(&code.CodeSpace{ID: codeSpace.ID, Contents: savedContents, Version: 4}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+		Return(&code.CodeSpaceRevision{}, nil).
+		Times(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	savedCount, err = svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), savedCount)
+}
+
+func TestServiceUpdateCodeSpaceCollaboration(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	collaboratorUUID := uuid.NewString()
+	editorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:       42,
+		Name:     "habitable-slaking-volatile-granger-mov",
+		Language: "python",
+		Contents: "print('a')\n",
+		Version:  1,
+	}
+	updatedContents := "print('b')\n"
+	updatedCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: updatedContents,
+		Version:  2,
+	}
+	codeSpaceRevision := &code.CodeSpaceRevision{
+		ID:          7,
+		CodeSpaceID: codeSpace.ID,
+		Contents:    codeSpace.Contents,
+	}
+	restoredCodeSpace := &code.CodeSpace{
+		ID:       codeSpace.ID,
+		Name:     codeSpace.Name,
+		Language: codeSpace.Language,
+		Contents: codeSpaceRevision.Contents,
+		Version:  3,
+	}
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(4)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(4)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, collaboratorUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadOnly}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, editorUUID, codeSpace.Name).
+		Return(codeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadWrite}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), dbConn, editorUUID, codeSpace.Name).
+		Return(updatedCodeSpace, &code.CodeSpaceAccess{Level: code.CodeSpaceAccessLevelReadWrite}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListUsersWithCodeSpaceAccess(gomock.Any(), dbConn, codeSpace.ID).
+		Return(
+			[]*auth.User{{UUID: collaboratorUUID}},
+			[]*code.CodeSpaceAccess{{CodeSpaceID: codeSpace.ID, Level: code.CodeSpaceAccessLevelReadOnly}},
+			nil,
+		).
+		Times(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		Times(2)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		Times(2)
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		Times(2)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &updatedContents, nil, nil).
+		Return(updatedCodeSpace, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		GetCodeSpaceRevision(gomock.Any(), dbTx, codeSpace.ID, codeSpaceRevision.ID).
+		Return(codeSpaceRevision, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpace(gomock.Any(), dbTx, codeSpace.ID, &codeSpaceRevision.Contents, nil, &updatedCodeSpace.Version).
+		Return(restoredCodeSpace, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRevision(gomock.Any(), dbTx, gomock.Any()).
+		Return(&code.CodeSpaceRevision{}, nil).
+		Times(2)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	collaboratorCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, collaboratorUUID)
+	collaborator, err := svc.JoinCodeSpaceCollaboration(collaboratorCtx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceCollabEventTypeSnapshot, (<-collaborator.Events()).Type)
+
+	editorCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editorUUID)
+	_, _, err = svc.UpdateCodeSpace(editorCtx, codeSpace.Name, nil, &updatedContents, nil, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:      api.CodeSpaceCollabEventTypeEdit,
+		Revision:  1,
+		Operation: code.EditOperation{{Insert: "print('b')\n"}, {Delete: 11}},
+		UserUUID:  editorUUID,
+	}, <-collaborator.Events())
+
+	_, _, err = svc.RestoreCodeSpaceRevision(editorCtx, codeSpace.Name, codeSpaceRevision.ID, &updatedCodeSpace.Version)
+	require.NoError(t, err)
+
+	require.Equal(t, &code.CodeSpaceCollabEvent{
+		Type:      api.CodeSpaceCollabEventTypeEdit,
+		Revision:  2,
+		Operation: code.EditOperation{{Insert: "print('a')\n"}, {Delete: 11}},
+		UserUUID:  editorUUID,
+	}, <-collaborator.Events())
+
+	// the updates are already saved, so there is nothing left to save
+	savedCount, err := svc.SaveCodeSpaceCollaborations(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), savedCount)
+}
+
+func TestServiceRunCodeSpaceSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	exitCodeZero := 0
+
+	testcases := map[string]struct {
+		language            string
+		wantCompileResponse *api.PistonResults
+		wantRunResponse     api.PistonResults
+	}{
+		"Run C code space": {
+			language: api.PistonLanguageC,
+			wantCompileResponse: &api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "",
+				Stderr: "",
+				Output: "",
+			},
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run C++ code space": {
+			language: api.PistonLanguageCPlusPlus,
+			wantCompileResponse: &api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "",
+				Stderr: "",
+				Output: "",
+			},
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run Go code space": {
+			language:            api.PistonLanguageGo,
+			wantCompileResponse: nil,
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run Java code space": {
+			language:            api.PistonLanguageJava,
+			wantCompileResponse: nil,
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run JavaScript code space": {
+			language:            api.PistonLanguageJavaScript,
+			wantCompileResponse: nil,
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run Python code space": {
+			language:            api.PistonLanguagePython,
+			wantCompileResponse: nil,
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run Rust code space": {
+			language: api.PistonLanguageRust,
+			wantCompileResponse: &api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "",
+				Stderr: "",
+				Output: "",
+			},
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+		"Run TypeScript code space": {
+			language:            api.PistonLanguageTypeScript,
+			wantCompileResponse: nil,
+			wantRunResponse: api.PistonResults{
+				Code:   &exitCodeZero,
+				Signal: nil,
+				Stdout: "Yello!\n",
+				Stderr: "",
+				Output: "Yello!\n",
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, testcase.language)
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := testkitinternal.GetPistonClient()
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+			codeSpaceRun, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+			require.NoError(t, err)
+			require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+			require.NotNil(t, codeSpaceRun.UserUUID)
+			require.Equal(t, author.UUID, *codeSpaceRun.UserUUID)
+			require.Equal(t, codeSpace.Contents, codeSpaceRun.Contents)
+			require.Equal(t, testcase.language, codeSpaceRun.Language)
+			runtimes, err := pistonClient.Runtimes(context.Background())
+			require.NoError(t, err)
+			runtime := piston.FindRuntime(runtimes, testcase.language)
+			require.NotNil(t, runtime)
+			require.Equal(t, runtime.Version, codeSpaceRun.Version)
+			require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+			require.NotNil(t, codeSpaceRun.StartedAt)
+			require.NotNil(t, codeSpaceRun.FinishedAt)
+
+			if testcase.wantCompileResponse != nil {
+				require.NotNil(t, codeSpaceRun.CompileStdout)
+				require.Equal(t, testcase.wantCompileResponse.Stdout, *codeSpaceRun.CompileStdout)
+				require.NotNil(t, codeSpaceRun.CompileStderr)
+				require.Equal(t, testcase.wantCompileResponse.Stderr, *codeSpaceRun.CompileStderr)
+				require.Equal(t, testcase.wantCompileResponse.Code, codeSpaceRun.CompileCode)
+				require.Equal(t, testcase.wantCompileResponse.Signal, codeSpaceRun.CompileSignal)
+			} else {
+				require.Nil(t, codeSpaceRun.CompileStdout)
+				require.Nil(t, codeSpaceRun.CompileStderr)
+				require.Nil(t, codeSpaceRun.CompileCode)
+				require.Nil(t, codeSpaceRun.CompileSignal)
+			}
+
+			require.NotNil(t, codeSpaceRun.RunStdout)
+			require.Equal(t, testcase.wantRunResponse.Stdout, *codeSpaceRun.RunStdout)
+			require.NotNil(t, codeSpaceRun.RunStderr)
+			require.Equal(t, testcase.wantRunResponse.Stderr, *codeSpaceRun.RunStderr)
+			require.Equal(t, testcase.wantRunResponse.Code, codeSpaceRun.RunCode)
+			require.Equal(t, testcase.wantRunResponse.Signal, codeSpaceRun.RunSignal)
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
+	genericRuntimesErr := errors.New("Runtimes failed")
+	genericListFilesErr := errors.New("ListCodeSpaceFiles failed")
+	genericCreateRunErr := errors.New("CreateCodeSpaceRun failed")
+	genericUpdateRunErr := errors.New("UpdateCodeSpaceRun failed")
+	genericPistonErr := errors.New("Execute failed")
+	excessiveRunTimeout := cfg.PistonMaxRunTimeout + 1
+	unknownVersion := "2.7.18"
+	runtimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+	}
+
+	testcases := map[string]struct {
+		ctx          context.Context
+		language     string
+		opts         *code.RunCodeSpaceOptions
+		repoErr      error
+		runtimesErr  error
+		listFilesErr error
+		createRunErr error
+		updateRunErr error
+		pistonErr    error
+		wantErr      error
+	}{
+		"No user UUID in context": {
+			ctx:          context.Background(),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      nil,
+		},
+		"Run timeout exceeds maximum": {
+			ctx:      context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language: "python",
+			opts: &code.RunCodeSpaceOptions{
+				RunTimeout: &excessiveRunTimeout,
+			},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceRunLimitExceeded,
+		},
+		"GetCodeSpaceWithAccessByName fails, no rows returned": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      errutils.ErrDatabaseNoRowsReturned,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceNotFound,
+		},
+		"GetCodeSpaceWithAccessByName fails, generic error": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      genericRepoErr,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      genericRepoErr,
+		},
+		"Runtimes fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  genericRuntimesErr,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      genericRuntimesErr,
+		},
+		"Unknown language": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "unknown",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceUnsupportedLanguage,
+		},
+		"Unknown version": {
+			ctx:      context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language: "python",
+			opts: &code.RunCodeSpaceOptions{
+				Version: &unknownVersion,
+			},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceUnsupportedVersion,
+		},
+		"ListCodeSpaceFiles fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: genericListFilesErr,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      genericListFilesErr,
+		},
+		"CreateCodeSpaceRun fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: genericCreateRunErr,
+			updateRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      genericCreateRunErr,
+		},
+		"UpdateCodeSpaceRun fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: genericUpdateRunErr,
+			pistonErr:    nil,
+			wantErr:      genericUpdateRunErr,
+		},
+		"Execute fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			language:     "python",
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			runtimesErr:  nil,
+			listFilesErr: nil,
+			createRunErr: nil,
+			updateRunErr: nil,
+			pistonErr:    genericPistonErr,
+			wantErr:      genericPistonErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return(runtimes, testcase.runtimesErr).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				Return(&api.PistonExecuteResponse{}, testcase.pistonErr).
+				MaxTimes(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   testcase.language,
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, testcase.repoErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, testcase.listFilesErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{}, nil).
+				MaxTimes(1)
+
+			codeSpaceRun := &code.CodeSpaceRun{
+				ID:          271,
+				CodeSpaceID: codeSpace.ID,
+				UserUUID:    &authorUUID,
+				Contents:    codeSpace.Contents,
+				Language:    codeSpace.Language,
+				Status:      api.CodeSpaceRunStatusRunning,
+			}
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(codeSpaceRun, testcase.createRunErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(codeSpaceRun, testcase.updateRunErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.RunCodeSpace(testcase.ctx, codeSpace.Name, testcase.opts)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceQuotaExceeded(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+	userUUID := uuid.NewString()
+	apiKeyID := int64(42)
+	userCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+
+	testcases := map[string]struct {
+		ctx           context.Context
+		userSummary   *auth.ExecutionUsageSummary
+		apiKeySummary *auth.ExecutionUsageSummary
+		wantLimit     string
+	}{
+		"User runs per minute exceeded": {
+			ctx: userCtx,
+			userSummary: &auth.ExecutionUsageSummary{
+				RunsThisMinute: cfg.QuotaUserRunsPerMinute,
+			},
+			apiKeySummary: &auth.ExecutionUsageSummary{},
+			wantLimit:     api.ExecutionQuotaLimitRunsPerMinute,
+		},
+		"User CPU time per day exceeded": {
+			ctx: userCtx,
+			userSummary: &auth.ExecutionUsageSummary{
+				CPUMillisecondsToday: cfg.QuotaUserCPUSecondsPerDay * 1000,
+			},
+			apiKeySummary: &auth.ExecutionUsageSummary{},
+			wantLimit:     api.ExecutionQuotaLimitCPUMillisecondsPerDay,
+		},
+		"API key runs per day exceeded": {
+			ctx:         context.WithValue(userCtx, auth.AuthContextKeyAPIKeyID, apiKeyID),
+			userSummary: &auth.ExecutionUsageSummary{},
+			apiKeySummary: &auth.ExecutionUsageSummary{
+				RunsToday: cfg.QuotaAPIKeyRunsPerDay,
+			},
+			wantLimit: api.ExecutionQuotaLimitRunsPerDay,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), userUUID, nil, gomock.Any(), gomock.Any()).
+				Return(testcase.userSummary, nil).
+				MaxTimes(1)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), userUUID, &apiKeyID, gomock.Any(), gomock.Any()).
+				Return(testcase.apiKeySummary, nil).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.RunCodeSpace(testcase.ctx, "habitable-slaking-volatile-granger-mov", &code.RunCodeSpaceOptions{})
+			require.ErrorIs(t, err, errutils.ErrExecutionQuotaExceeded)
+
+			var quotaErr *auth.ExecutionQuotaExceededError
+			require.ErrorAs(t, err, &quotaErr)
+			require.Equal(t, testcase.wantLimit, quotaErr.Limit)
+			require.True(t, quotaErr.ResetAt.After(timeProvider.Now()))
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceLanguageVersion(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
+		},
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+
+	codeSpaceRun, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "3.10.0", codeSpaceRun.Version)
+
+	pinnedVersion := "3.9.4"
+	_, _, err = svc.UpdateCodeSpace(ctx, codeSpace.Name, nil, nil, &pinnedVersion, nil)
+	require.NoError(t, err)
+
+	codeSpaceRun, err = svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, pinnedVersion, codeSpaceRun.Version)
+
+	overriddenVersion := "3.10.0"
+	codeSpaceRun, err = svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{
+		Version: &overriddenVersion,
+	})
+	require.NoError(t, err)
+	require.Equal(t, overriddenVersion, codeSpaceRun.Version)
+}
+
+func TestServiceRunCodeSpaceMatrixSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	exitCode := 0
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(
+		piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+			},
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.9.4",
+			},
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "2.7.18",
+			},
+		}),
+		piston.WithFakeClientRunResults(api.PistonResults{
+			Stdout: "Yello!\n",
+			Output: "Yello!\n",
+			Code:   &exitCode,
+		}),
+	)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	versions := []string{"2.7.18", "3.10.0", "3.9.4"}
+	codeSpaceRuns, err := svc.RunCodeSpaceMatrix(ctx, codeSpace.Name, versions, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Len(t, codeSpaceRuns, len(versions))
+
+	runIDs := make(map[int64]struct{}, len(codeSpaceRuns))
+	for i, codeSpaceRun := range codeSpaceRuns {
+		require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+		require.Equal(t, api.PistonLanguagePython, codeSpaceRun.Language)
+		require.Equal(t, versions[i], codeSpaceRun.Version)
+		require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+		require.NotNil(t, codeSpaceRun.RunStdout)
+		require.Equal(t, "Yello!\n", *codeSpaceRun.RunStdout)
+
+		runIDs[codeSpaceRun.ID] = struct{}{}
+	}
+
+	require.Len(t, runIDs, len(versions))
+}
+
+func TestServiceRunCodeSpaceMatrixError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	dbBeginErr := errors.New("Begin failed")
+	dbCommitErr := errors.New("Commit failed")
+	genericCreateRunErr := errors.New("CreateCodeSpaceRun failed")
+	genericPistonErr := errors.New("Execute failed")
+	excessiveRunTimeout := cfg.PistonMaxRunTimeout + 1
+
+	testcases := map[string]struct {
+		ctx          context.Context
+		versions     []string
+		opts         *code.RunCodeSpaceOptions
+		dbBeginErr   error
+		dbCommitErr  error
+		createRunErr error
+		pistonErr    error
+		wantErr      error
+	}{
+		"No user UUID in context": {
+			ctx:          context.Background(),
+			versions:     []string{"3.10.0"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   nil,
+			dbCommitErr:  nil,
+			createRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      nil,
+		},
+		"Run timeout exceeds maximum": {
+			ctx:      context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions: []string{"3.10.0"},
+			opts: &code.RunCodeSpaceOptions{
+				RunTimeout: &excessiveRunTimeout,
+			},
+			dbBeginErr:   nil,
+			dbCommitErr:  nil,
+			createRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceRunLimitExceeded,
+		},
+		"Begin transaction fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions:     []string{"3.10.0"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   dbBeginErr,
+			dbCommitErr:  nil,
+			createRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      dbBeginErr,
+		},
+		"Unknown version": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions:     []string{"2.7.18"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   nil,
+			dbCommitErr:  nil,
+			createRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      errutils.ErrCodeSpaceUnsupportedVersion,
+		},
+		"CreateCodeSpaceRun fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions:     []string{"3.10.0"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   nil,
+			dbCommitErr:  nil,
+			createRunErr: genericCreateRunErr,
+			pistonErr:    nil,
+			wantErr:      genericCreateRunErr,
+		},
+		"Commit transaction fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions:     []string{"3.10.0"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   nil,
+			dbCommitErr:  dbCommitErr,
+			createRunErr: nil,
+			pistonErr:    nil,
+			wantErr:      dbCommitErr,
+		},
+		"Execute fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			versions:     []string{"3.10.0"},
+			opts:         &code.RunCodeSpaceOptions{},
+			dbBeginErr:   nil,
+			dbCommitErr:  nil,
+			createRunErr: nil,
+			pistonErr:    genericPistonErr,
+			wantErr:      genericPistonErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			// one transaction reserves the runs and another records them
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(testcase.dbCommitErr).
+				MaxTimes(2)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(2)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, testcase.dbBeginErr).
+				MaxTimes(2)
+
+			// one connection records the runs and another executes the single version
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(2)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(2)
+
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				Return(&api.PistonExecuteResponse{}, testcase.pistonErr).
+				MaxTimes(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{}, nil).
+				MaxTimes(1)
+
+			codeSpaceRun := &code.CodeSpaceRun{
+				ID:          271,
+				CodeSpaceID: codeSpace.ID,
+				UserUUID:    &authorUUID,
+				Contents:    codeSpace.Contents,
+				Language:    codeSpace.Language,
+				Version:     "3.10.0",
+				Status:      api.CodeSpaceRunStatusQueued,
+			}
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(codeSpaceRun, testcase.createRunErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(codeSpaceRun, nil).
+				MaxTimes(2)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.RunCodeSpaceMatrix(testcase.ctx, codeSpace.Name, testcase.versions, testcase.opts)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceBenchmarkCodeSpaceSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	exitCode := 0
+	wallTime := int64(120)
+	cpuTime := int64(100)
+	memory := int64(8704000)
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(
+		piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+			},
+		}),
+		piston.WithFakeClientRunResults(api.PistonResults{
+			Stdout:   "Yello!\n",
+			Output:   "Yello!\n",
+			Code:     &exitCode,
+			WallTime: &wallTime,
+			CPUTime:  &cpuTime,
+			Memory:   &memory,
+		}),
+	)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		piston.NewMemoryCache(timeProvider, time.Minute, 8),
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	benchmark, err := svc.BenchmarkCodeSpace(ctx, codeSpace.Name, 3, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), benchmark.Iterations)
+	require.False(t, benchmark.StoppedEarly)
+	require.Len(t, benchmark.Runs, 3)
+
+	runIDs := make(map[int64]struct{}, len(benchmark.Runs))
+	for _, codeSpaceRun := range benchmark.Runs {
+		require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+		require.Equal(t, "3.10.0", codeSpaceRun.Version)
+		require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+		require.False(t, codeSpaceRun.FromCache)
+
+		runIDs[codeSpaceRun.ID] = struct{}{}
+	}
+
+	require.Len(t, runIDs, 3)
+	require.Equal(t, &code.CodeSpaceBenchmarkStats{
+		Min:    wallTime,
+		Median: wallTime,
+		P95:    wallTime,
+		Max:    wallTime,
+	}, benchmark.WallTime)
+	require.Equal(t, &code.CodeSpaceBenchmarkStats{
+		Min:    cpuTime,
+		Median: cpuTime,
+		P95:    cpuTime,
+		Max:    cpuTime,
+	}, benchmark.CPUTime)
+	require.Equal(t, &memory, benchmark.PeakMemory)
+}
+
+func TestServiceBenchmarkCodeSpaceStopsEarly(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	wallTime := int64(120)
+	failedWallTime := int64(900)
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{
+			{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+			},
+		}, nil).
+		Times(1)
+
+	gomock.InOrder(
+		pistonClient.
+			EXPECT().
+			Execute(gomock.Any(), gomock.Any()).
+			Return(&api.PistonExecuteResponse{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+				Run: api.PistonResults{
+					Code:     &exitCodeZero,
+					WallTime: &wallTime,
+				},
+			}, nil).
+			Times(1),
+		pistonClient.
+			EXPECT().
+			Execute(gomock.Any(), gomock.Any()).
+			Return(&api.PistonExecuteResponse{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+				Run: api.PistonResults{
+					Stderr:   "ZeroDivisionError: division by zero",
+					Code:     &exitCodeOne,
+					WallTime: &failedWallTime,
+				},
+			}, nil).
+			Times(1),
+	)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	benchmark, err := svc.BenchmarkCodeSpace(ctx, codeSpace.Name, 5, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), benchmark.Iterations)
+	require.True(t, benchmark.StoppedEarly)
+	require.Len(t, benchmark.Runs, 2)
+	require.True(t, benchmark.Runs[0].Succeeded())
+	require.False(t, benchmark.Runs[1].Succeeded())
+	require.Equal(t, &code.CodeSpaceBenchmarkStats{
+		Min:    wallTime,
+		Median: wallTime,
+		P95:    wallTime,
+		Max:    wallTime,
+	}, benchmark.WallTime)
+	require.Nil(t, benchmark.CPUTime)
+	require.Nil(t, benchmark.PeakMemory)
+}
+
+func TestServiceBenchmarkCodeSpaceQuotaExceeded(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+	userUUID := uuid.NewString()
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	// a single run would still fit within the quota, but the whole benchmark does not
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), userUUID, nil, gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{
+			RunsThisMinute: cfg.QuotaUserRunsPerMinute - 1,
+		}, nil).
+		Times(1)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	_, err := svc.BenchmarkCodeSpace(ctx, "habitable-slaking-volatile-granger-mov", 10, &code.RunCodeSpaceOptions{})
+	require.ErrorIs(t, err, errutils.ErrExecutionQuotaExceeded)
+
+	var quotaErr *auth.ExecutionQuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, api.ExecutionQuotaLimitRunsPerMinute, quotaErr.Limit)
+}
+
+func TestServiceStartCodeSpaceRunSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRunResults(api.PistonResults{
+		Stdout: "Yello!\n",
+		Stderr: "",
+		Output: "Yello!\n",
+		Code:   nil,
+		Signal: nil,
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	codeSpaceRun, err := svc.StartCodeSpaceRun(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+	require.Equal(t, api.CodeSpaceRunStatusQueued, codeSpaceRun.Status)
+	require.Nil(t, codeSpaceRun.StartedAt)
+	require.Nil(t, codeSpaceRun.RunStdout)
+
+	svc.Close()
+
+	finishedCodeSpaceRun, err := svc.GetCodeSpaceRun(ctx, codeSpace.Name, codeSpaceRun.ID)
+	require.NoError(t, err)
+	require.Equal(t, api.CodeSpaceRunStatusCompleted, finishedCodeSpaceRun.Status)
+	require.NotNil(t, finishedCodeSpaceRun.StartedAt)
+	require.NotNil(t, finishedCodeSpaceRun.FinishedAt)
+	require.NotNil(t, finishedCodeSpaceRun.RunStdout)
+	require.Equal(t, "Yello!\n", *finishedCodeSpaceRun.RunStdout)
+}
+
+func TestServiceStartCodeSpaceRunExecuteFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	_, bufErr, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsage{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+		Return(codeSpace, codeSpaceAccess, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceFile{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceEnvVar{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			require.Equal(t, api.CodeSpaceRunStatusQueued, codeSpaceRun.Status)
+			createdCodeSpaceRun := *codeSpaceRun
+			createdCodeSpaceRun.ID = 271
+
+			return &createdCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	statuses := make([]string, 0)
+	repo.
+		EXPECT().
+		UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			statuses = append(statuses, codeSpaceRun.Status)
+			updatedCodeSpaceRun := *codeSpaceRun
+
+			return &updatedCodeSpaceRun, nil
+		}).
+		Times(2)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+		Times(1)
+
+	pistonClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(nil, errutils.ErrCodeExecutionQueueFull).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+	codeSpaceRun, err := svc.StartCodeSpaceRun(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(271), codeSpaceRun.ID)
+	require.Equal(t, api.CodeSpaceRunStatusQueued, codeSpaceRun.Status)
+
+	svc.Close()
+
+	require.Equal(t, []string{api.CodeSpaceRunStatusRunning, api.CodeSpaceRunStatusFailed}, statuses)
+	require.Contains(t, bufErr.String(), errutils.ErrCodeExecutionQueueFull.Error())
+}
+
+func TestServiceStartCodeSpaceRunError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
+	genericCreateRunErr := errors.New("CreateCodeSpaceRun failed")
+	excessiveCompileTimeout := cfg.PistonMaxCompileTimeout + 1
+
+	testcases := map[string]struct {
+		ctx          context.Context
+		opts         *code.RunCodeSpaceOptions
+		repoErr      error
+		createRunErr error
+		wantErr      error
+	}{
+		"No user UUID in context": {
+			ctx:          context.Background(),
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			createRunErr: nil,
+			wantErr:      nil,
+		},
+		"Compile timeout exceeds maximum": {
+			ctx: context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			opts: &code.RunCodeSpaceOptions{
+				CompileTimeout: &excessiveCompileTimeout,
+			},
+			repoErr:      nil,
+			createRunErr: nil,
+			wantErr:      errutils.ErrCodeSpaceRunLimitExceeded,
+		},
+		"GetCodeSpaceWithAccessByName fails, no rows returned": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      errutils.ErrDatabaseNoRowsReturned,
+			createRunErr: nil,
+			wantErr:      errutils.ErrCodeSpaceNotFound,
+		},
+		"GetCodeSpaceWithAccessByName fails, generic error": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      genericRepoErr,
+			createRunErr: nil,
+			wantErr:      genericRepoErr,
+		},
+		"CreateCodeSpaceRun fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			opts:         &code.RunCodeSpaceOptions{},
+			repoErr:      nil,
+			createRunErr: genericCreateRunErr,
+			wantErr:      genericCreateRunErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+				MaxTimes(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, testcase.repoErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{}, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, testcase.createRunErr).
+				MaxTimes(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.StartCodeSpaceRun(testcase.ctx, codeSpace.Name, testcase.opts)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+
+			svc.Close()
+		})
+	}
+}
+
+func TestServiceStreamCodeSpaceRunSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "c")
+
+	exitCodeZero := 0
+	compileResults := &api.PistonResults{
+		Stdout: "",
+		Stderr: "",
+		Output: "",
+		Code:   &exitCodeZero,
+		Signal: nil,
+	}
+	runResults := api.PistonResults{
+		Stdout: "Yello!\n",
+		Stderr: "",
+		Output: "Yello!\n",
+		Code:   &exitCodeZero,
+		Signal: nil,
+	}
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(
+		piston.WithFakeClientCompileResults(compileResults),
+		piston.WithFakeClientRunResults(runResults),
+	)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	eventTypes := make([]string, 0)
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	codeSpaceRun, err := svc.StreamCodeSpaceRun(
+		ctx,
+		codeSpace.Name,
+		&code.RunCodeSpaceOptions{},
+		func(event *api.PistonEvent) {
+			eventTypes = append(eventTypes, event.Type)
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+	require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+	require.NotNil(t, codeSpaceRun.StartedAt)
+	require.NotNil(t, codeSpaceRun.FinishedAt)
+	require.NotNil(t, codeSpaceRun.CompileCode)
+	require.Equal(t, exitCodeZero, *codeSpaceRun.CompileCode)
+	require.NotNil(t, codeSpaceRun.RunStdout)
+	require.Equal(t, "Yello!\n", *codeSpaceRun.RunStdout)
+
+	require.Equal(
+		t,
+		[]string{
+			api.PistonEventTypeCompiling,
+			api.PistonEventTypeCompileFinished,
+			api.PistonEventTypeRunning,
+			api.PistonEventTypeStdout,
+		},
+		eventTypes,
+	)
+}
+
+func TestServiceStreamCodeSpaceRunStreamingClient(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockStreamingClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsage{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+		Return(codeSpace, codeSpaceAccess, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceFile{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceEnvVar{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			require.Equal(t, api.CodeSpaceRunStatusQueued, codeSpaceRun.Status)
+			createdCodeSpaceRun := *codeSpaceRun
+			createdCodeSpaceRun.ID = 271
+
+			return &createdCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	statuses := make([]string, 0)
+	repo.
+		EXPECT().
+		UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			statuses = append(statuses, codeSpaceRun.Status)
+			updatedCodeSpaceRun := *codeSpaceRun
+
+			return &updatedCodeSpaceRun, nil
+		}).
+		Times(2)
+
+	stage := api.PistonStageRun
+	chunk := "Yello!\n"
+	wantEvents := []*api.PistonEvent{
+		{
+			Type: api.PistonEventTypeRunning,
+		},
+		{
+			Type:  api.PistonEventTypeStdout,
+			Stage: &stage,
+			Data:  &chunk,
+		},
+	}
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+		Times(1)
+
+	pistonClient.
+		EXPECT().
+		ExecuteStream(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			req *api.PistonExecuteRequest,
+			onEvent func(event *api.PistonEvent),
+		) (*api.PistonExecuteResponse, error) {
+			for _, event := range wantEvents {
+				onEvent(event)
+			}
+
+			return &api.PistonExecuteResponse{
+				Language: req.Language,
+				Version:  req.Version,
+				Run: api.PistonResults{
+					Stdout: chunk,
+					Output: chunk,
+				},
+			}, nil
+		}).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	events := make([]*api.PistonEvent, 0)
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+	codeSpaceRun, err := svc.StreamCodeSpaceRun(
+		ctx,
+		codeSpace.Name,
+		&code.RunCodeSpaceOptions{},
+		func(event *api.PistonEvent) {
+			events = append(events, event)
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(271), codeSpaceRun.ID)
+	require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+	require.NotNil(t, codeSpaceRun.RunStdout)
+	require.Equal(t, chunk, *codeSpaceRun.RunStdout)
+	require.Equal(t, wantEvents, events)
+	require.Equal(t, []string{api.CodeSpaceRunStatusRunning, api.CodeSpaceRunStatusCompleted}, statuses)
+}
+
+// nonInteractiveClient hides the interactive sessions of the Client it wraps.
+type nonInteractiveClient struct {
+	piston.Client
+}
+
+func TestServiceRunCodeSpaceInteractively(t *testing.T) {
+	t.Parallel()
+
+	signal := "SIGINT"
+
+	testcases := map[string]struct {
+		pistonClient piston.Client
+		interact     func(t *testing.T, session piston.Session, cancel context.CancelFunc) error
+		wantStatus   string
+		wantErr      error
+	}{
+		"Session finishes": {
+			pistonClient: piston.NewFakeClient(),
+			interact: func(t *testing.T, session piston.Session, _ context.CancelFunc) error {
+				event, err := session.Receive()
+				require.NoError(t, err)
+				require.Equal(t, api.PistonEventTypeRunning, event.Type)
+
+				err = session.WriteStdin("Harry\n")
+				require.NoError(t, err)
+
+				event, err = session.Receive()
+				require.NoError(t, err)
+				require.Equal(t, api.PistonEventTypeStdout, event.Type)
+
+				err = session.Signal(signal)
+				require.NoError(t, err)
+
+				_, err = session.Receive()
+				require.ErrorIs(t, err, io.EOF)
+
+				return nil
+			},
+			wantStatus: api.CodeSpaceRunStatusCompleted,
+			wantErr:    nil,
+		},
+		"Session cancelled": {
+			pistonClient: piston.NewFakeClient(),
+			interact: func(t *testing.T, session piston.Session, cancel context.CancelFunc) error {
+				_, err := session.Receive()
+				require.NoError(t, err)
+
+				cancel()
+
+				_, err = session.Receive()
+
+				return err
+			},
+			wantStatus: api.CodeSpaceRunStatusCancelled,
+			wantErr:    errutils.ErrCodeSpaceRunCancelled,
+		},
+		"Client not interactive": {
+			pistonClient: &nonInteractiveClient{
+				Client: piston.NewFakeClient(),
+			},
+			interact: func(t *testing.T, _ piston.Session, _ context.CancelFunc) error {
+				require.Fail(t, "interact called without a session")
+
+				return nil
+			},
+			wantStatus: api.CodeSpaceRunStatusFailed,
+			wantErr:    errutils.ErrCodeExecutionNotInteractive,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := testkitinternal.MustCreateConfig()
+
+			authorUUID := uuid.NewString()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				Times(1)
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print(input())",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{}, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					codeSpaceRun *code.CodeSpaceRun,
+				) (*code.CodeSpaceRun, error) {
+					require.Equal(t, api.CodeSpaceRunStatusRunning, codeSpaceRun.Status)
+					createdCodeSpaceRun := *codeSpaceRun
+					createdCodeSpaceRun.ID = 271
+
+					return &createdCodeSpaceRun, nil
+				}).
+				Times(1)
+
+			var recordedCodeSpaceRun *code.CodeSpaceRun
+			repo.
+				EXPECT().
+				UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					codeSpaceRun *code.CodeSpaceRun,
+				) (*code.CodeSpaceRun, error) {
+					updatedCodeSpaceRun := *codeSpaceRun
+					recordedCodeSpaceRun = &updatedCodeSpaceRun
+
+					return &updatedCodeSpaceRun, nil
+				}).
+				Times(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				testcase.pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx, cancel := context.WithCancel(
+				context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			)
+			t.Cleanup(cancel)
+
+			codeSpaceRun, err := svc.RunCodeSpaceInteractively(
+				ctx,
+				codeSpace.Name,
+				&code.RunCodeSpaceOptions{},
+				func(session piston.Session) error {
+					return testcase.interact(t, session, cancel)
+				},
+			)
+
+			require.NotNil(t, recordedCodeSpaceRun)
+			require.Equal(t, testcase.wantStatus, recordedCodeSpaceRun.Status)
+			require.NotNil(t, recordedCodeSpaceRun.FinishedAt)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, int64(271), codeSpaceRun.ID)
+			require.NotNil(t, codeSpaceRun.RunStdout)
+			require.Equal(t, "Harry\n", *codeSpaceRun.RunStdout)
+			require.NotNil(t, codeSpaceRun.RunSignal)
+			require.Equal(t, signal, *codeSpaceRun.RunSignal)
+		})
+	}
+}
+
+func TestServiceListCodeSpaceRuns(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	stranger, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceAccess(t, viewer.UUID, codeSpace.ID, code.CodeSpaceAccessLevelReadOnly)
+
+	firstCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+	secondCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, viewer.UUID)
+
+	testcases := map[string]struct {
+		userUUID string
+		limit    int64
+		offset   int64
+		wantIDs  []int64
+		wantErr  error
+	}{
+		"Author lists runs": {
+			userUUID: author.UUID,
+			limit:    10,
+			offset:   0,
+			wantIDs:  []int64{secondCodeSpaceRun.ID, firstCodeSpaceRun.ID},
+			wantErr:  nil,
+		},
+		"Viewer lists runs with offset": {
+			userUUID: viewer.UUID,
+			limit:    10,
+			offset:   1,
+			wantIDs:  []int64{firstCodeSpaceRun.ID},
+			wantErr:  nil,
+		},
+		"User without access cannot list runs": {
+			userUUID: stranger.UUID,
+			limit:    10,
+			offset:   0,
+			wantIDs:  nil,
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			codeSpaceRuns, err := svc.ListCodeSpaceRuns(ctx, codeSpace.Name, testcase.limit, testcase.offset)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			ids := make([]int64, len(codeSpaceRuns))
+			for i, codeSpaceRun := range codeSpaceRuns {
+				ids[i] = codeSpaceRun.ID
+			}
+
+			require.Equal(t, testcase.wantIDs, ids)
+		})
+	}
+}
+
+func TestServiceGetCodeSpaceRun(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	stranger, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	otherCodeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	codeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+	otherCodeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, otherCodeSpace, author.UUID)
+
+	testcases := map[string]struct {
+		userUUID       string
+		codeSpaceRunID int64
+		wantErr        error
+	}{
+		"Get run": {
+			userUUID:       author.UUID,
+			codeSpaceRunID: codeSpaceRun.ID,
+			wantErr:        nil,
+		},
+		"Get run of another code space": {
+			userUUID:       author.UUID,
+			codeSpaceRunID: otherCodeSpaceRun.ID,
+			wantErr:        errutils.ErrCodeSpaceRunNotFound,
+		},
+		"Get non-existent run": {
+			userUUID:       author.UUID,
+			codeSpaceRunID: 314159265,
+			wantErr:        errutils.ErrCodeSpaceRunNotFound,
+		},
+		"User without access cannot get run": {
+			userUUID:       stranger.UUID,
+			codeSpaceRunID: codeSpaceRun.ID,
+			wantErr:        errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			fetchedCodeSpaceRun, err := svc.GetCodeSpaceRun(ctx, codeSpace.Name, testcase.codeSpaceRunID)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, codeSpaceRun.ID, fetchedCodeSpaceRun.ID)
+			require.Equal(t, codeSpaceRun.Status, fetchedCodeSpaceRun.Status)
+			require.Equal(t, codeSpaceRun.RunStdout, fetchedCodeSpaceRun.RunStdout)
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceSendsAllFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsage{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "import app",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+		Return(codeSpace, codeSpaceAccess, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceFile{
+			{
+				ID:           1,
+				CodeSpaceID:  codeSpace.ID,
+				Name:         "utils.py",
+				Contents:     "def greet():\n    print('Yello!')\n",
+				IsEntryPoint: false,
+			},
+			{
+				ID:           2,
+				CodeSpaceID:  codeSpace.ID,
+				Name:         "app.py",
+				Contents:     "import utils\nutils.greet()\n",
+				IsEntryPoint: true,
+			},
+		}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceEnvVar{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			createdCodeSpaceRun := *codeSpaceRun
+
+			return &createdCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			updatedCodeSpaceRun := *codeSpaceRun
+
+			return &updatedCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+		Times(1)
+
+	pistonClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+			fileNames := make([]string, len(req.Files))
+			for i, file := range req.Files {
+				require.NotNil(t, file.Name)
+				fileNames[i] = *file.Name
+			}
+
+			require.Equal(t, []string{"app.py", "utils.py", "main.py"}, fileNames)
+			require.Equal(t, codeSpace.Contents, req.Files[2].Content)
+
+			return &api.PistonExecuteResponse{}, nil
+		}).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+	_, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+}
+
+func TestServiceRunCodeSpaceExecutionCache(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	runCPUTime := int64(120)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	executionCache := piston.NewMemoryCache(timeProvider, time.Minute, 8)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{}, nil).
+		AnyTimes()
+
+	// runs reserve the most CPU time they can be given until they finish
+	authRepo.
+		EXPECT().
+		CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			executionUsage *auth.ExecutionUsage,
+		) (*auth.ExecutionUsage, error) {
+			require.Equal(t, cfg.PistonMaxCompileTimeout+cfg.PistonMaxRunTimeout, executionUsage.CPUMilliseconds)
+
+			return executionUsage, nil
+		}).
+		Times(3)
+
+	// executions are charged the CPU time reported by Piston, except when served from the cache
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), int64(120)).
+		Return(nil).
+		Times(2)
+
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), int64(0)).
+		Return(nil).
+		Times(1)
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		AnyTimes()
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(3)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(3)
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('Yello!')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+		Return(codeSpace, codeSpaceAccess, nil).
+		Times(3)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceFile{}, nil).
+		Times(3)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceEnvVar{}, nil).
+		Times(3)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			createdCodeSpaceRun := *codeSpaceRun
+
+			return &createdCodeSpaceRun, nil
+		}).
+		Times(3)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			updatedCodeSpaceRun := *codeSpaceRun
+
+			return &updatedCodeSpaceRun, nil
+		}).
+		Times(3)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+		Times(3)
+
+	pistonClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(&api.PistonExecuteResponse{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Run: api.PistonResults{
+				Stdout:  "Yello!\n",
+				CPUTime: &runCPUTime,
+			},
+		}, nil).
+		Times(2)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		executionCache,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+
+	codeSpaceRun, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.False(t, codeSpaceRun.FromCache)
+	require.Equal(t, "Yello!\n", *codeSpaceRun.RunStdout)
+
+	codeSpaceRun, err = svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.NoError(t, err)
+	require.True(t, codeSpaceRun.FromCache)
+	require.Equal(t, "Yello!\n", *codeSpaceRun.RunStdout)
+
+	codeSpaceRun, err = svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{
+		NoCache: true,
+	})
+	require.NoError(t, err)
+	require.False(t, codeSpaceRun.FromCache)
+	require.Equal(t, "Yello!\n", *codeSpaceRun.RunStdout)
+}
+
+func TestServiceRunCodeSpaceCancelled(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	dbTx := databasemocks.NewMockTx(ctrl)
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	authRepo.
+		EXPECT().
+		GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsageSummary{}, nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&auth.ExecutionUsage{}, nil).
+		Times(1)
+
+	authRepo.
+		EXPECT().
+		UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ database.Querier, _ int64, _ int64) error {
+			require.NoError(t, ctx.Err())
+
+			return nil
+		}).
+		Times(1)
+
+	authRepo.
+		EXPECT().
+		LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	authRepo.
+		EXPECT().
+		DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	dbTx.
+		EXPECT().
+		Commit(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbTx.
+		EXPECT().
+		Rollback(gomock.Any()).
+		Return(nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Begin(gomock.Any()).
+		Return(dbTx, nil).
+		MaxTimes(1)
+
+	dbConn.
+		EXPECT().
+		Release().
+		Times(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		Times(1)
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('Yello!')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	repo.
+		EXPECT().
+		GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+		Return(codeSpace, codeSpaceAccess, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceFile{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+		Return([]*code.CodeSpaceEnvVar{}, nil).
+		Times(1)
+
+	repo.
+		EXPECT().
+		CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			createdCodeSpaceRun := *codeSpaceRun
+
+			return &createdCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	repo.
+		EXPECT().
+		UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context,
+			_ database.Querier,
+			codeSpaceRun *code.CodeSpaceRun,
+		) (*code.CodeSpaceRun, error) {
+			require.NoError(t, ctx.Err())
+			require.Equal(t, api.CodeSpaceRunStatusCancelled, codeSpaceRun.Status)
+			require.NotNil(t, codeSpaceRun.FinishedAt)
+
+			updatedCodeSpaceRun := *codeSpaceRun
+
+			return &updatedCodeSpaceRun, nil
+		}).
+		Times(1)
+
+	pistonClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return([]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}}, nil).
+		Times(1)
+
+	ctx, cancel := context.WithCancel(
+		context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+	)
+	defer cancel()
+
+	pistonClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+			// the client disconnects while the code is running
+			cancel()
+
+			return nil, ctx.Err()
+		}).
+		Times(1)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	_, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceRunCancelled)
+}
+
+func TestServiceListCodeSpaceFiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "c")
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.h", false)
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.c", false)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID      string
+		wantFileNames []string
+		wantErr       error
+	}{
+		"Author can list files": {
+			userUUID:      author.UUID,
+			wantFileNames: []string{"utils.c", "utils.h"},
+			wantErr:       nil,
+		},
+		"Viewer can list files": {
+			userUUID:      viewer.UUID,
+			wantFileNames: []string{"utils.c", "utils.h"},
+			wantErr:       nil,
+		},
+		"Third party user cannot list files": {
+			userUUID:      thirdPartyUser.UUID,
+			wantFileNames: nil,
+			wantErr:       errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			codeSpaceFiles, err := svc.ListCodeSpaceFiles(ctx, codeSpace.Name)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			fileNames := make([]string, len(codeSpaceFiles))
+			for i, codeSpaceFile := range codeSpaceFiles {
+				fileNames[i] = codeSpaceFile.Name
+			}
+
+			require.Equal(t, testcase.wantFileNames, fileNames)
+		})
+	}
+}
+
+func TestServiceCreateCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	utilsFile, err := svc.CreateCodeSpaceFile(ctx, codeSpace.Name, "utils.py", "def greet(): pass", true)
+	require.NoError(t, err)
+	require.Equal(t, codeSpace.ID, utilsFile.CodeSpaceID)
+	require.Equal(t, "utils.py", utilsFile.Name)
+	require.Equal(t, "def greet(): pass", utilsFile.Contents)
+	require.True(t, utilsFile.IsEntryPoint)
+
+	appFile, err := svc.CreateCodeSpaceFile(ctx, codeSpace.Name, "app.py", "import utils", true)
+	require.NoError(t, err)
+	require.True(t, appFile.IsEntryPoint)
+
+	codeSpaceFiles, err := svc.ListCodeSpaceFiles(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceFiles, 2)
+
+	for _, codeSpaceFile := range codeSpaceFiles {
+		require.Equal(t, codeSpaceFile.ID == appFile.ID, codeSpaceFile.IsEntryPoint)
+	}
+}
+
+func TestServiceCreateCodeSpaceFileFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID string
+		fileName string
+		wantErr  error
+	}{
+		"Viewer cannot create file": {
+			userUUID: viewer.UUID,
+			fileName: "app.py",
+			wantErr:  errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Third party user cannot create file": {
+			userUUID: thirdPartyUser.UUID,
+			fileName: "app.py",
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+		"File cannot be named after main file": {
+			userUUID: author.UUID,
+			fileName: "main.py",
+			wantErr:  errutils.ErrCodeSpaceFileAlreadyExists,
+		},
+		"File cannot be named after existing file": {
+			userUUID: author.UUID,
+			fileName: "utils.py",
+			wantErr:  errutils.ErrCodeSpaceFileAlreadyExists,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			_, err := svc.CreateCodeSpaceFile(ctx, codeSpace.Name, testcase.fileName, "", false)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestServiceCreateCodeSpaceFileError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	fullCodeSpaceFiles := make([]*code.CodeSpaceFile, api.CodeSpaceFilesMaxCount)
+	genericDBBeginErr := errors.New("Begin failed")
+	genericListFilesErr := errors.New("ListCodeSpaceFiles failed")
+	genericClearErr := errors.New("ClearCodeSpaceEntryPoint failed")
+	genericCreateErr := errors.New("CreateCodeSpaceFile failed")
+	genericDBCommitErr := errors.New("Commit failed")
+
+	testcases := map[string]struct {
+		ctx          context.Context
+		dbBeginErr   error
+		files        []*code.CodeSpaceFile
+		listFilesErr error
+		clearErr     error
+		createErr    error
+		dbCommitErr  error
+		wantErr      error
+	}{
+		"No user UUID in context": {
+			ctx:          context.Background(),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    nil,
+			dbCommitErr:  nil,
+			wantErr:      nil,
+		},
+		"Begin fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   genericDBBeginErr,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    nil,
+			dbCommitErr:  nil,
+			wantErr:      genericDBBeginErr,
+		},
+		"ListCodeSpaceFiles fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: genericListFilesErr,
+			clearErr:     nil,
+			createErr:    nil,
+			dbCommitErr:  nil,
+			wantErr:      genericListFilesErr,
+		},
+		"Too many files": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        fullCodeSpaceFiles,
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    nil,
+			dbCommitErr:  nil,
+			wantErr:      errutils.ErrCodeSpaceFileLimitExceeded,
+		},
+		"ClearCodeSpaceEntryPoint fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     genericClearErr,
+			createErr:    nil,
+			dbCommitErr:  nil,
+			wantErr:      genericClearErr,
+		},
+		"CreateCodeSpaceFile fails, unique violation": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    errutils.ErrDatabaseUniqueViolation,
+			dbCommitErr:  nil,
+			wantErr:      errutils.ErrCodeSpaceFileAlreadyExists,
+		},
+		"CreateCodeSpaceFile fails, generic error": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    genericCreateErr,
+			dbCommitErr:  nil,
+			wantErr:      genericCreateErr,
+		},
+		"Commit fails": {
+			ctx:          context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			dbBeginErr:   nil,
+			files:        []*code.CodeSpaceFile{},
+			listFilesErr: nil,
+			clearErr:     nil,
+			createErr:    nil,
+			dbCommitErr:  genericDBCommitErr,
+			wantErr:      genericDBCommitErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(testcase.dbCommitErr).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, testcase.dbBeginErr).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(testcase.files, testcase.listFilesErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ClearCodeSpaceEntryPoint(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(testcase.clearErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceFile(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&code.CodeSpaceFile{}, testcase.createErr).
+				MaxTimes(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.CreateCodeSpaceFile(testcase.ctx, codeSpace.Name, "utils.py", "", true)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceUpdateCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	utilsFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", true)
+	appFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "app.py", false)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	newName := "server.py"
+	newContents := "import utils"
+	isEntryPoint := true
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	updatedFile, err := svc.UpdateCodeSpaceFile(
+		ctx,
+		codeSpace.Name,
+		appFile.ID,
+		&newName,
+		&newContents,
+		&isEntryPoint,
+	)
+	require.NoError(t, err)
+	require.Equal(t, appFile.ID, updatedFile.ID)
+	require.Equal(t, newName, updatedFile.Name)
+	require.Equal(t, newContents, updatedFile.Contents)
+	require.True(t, updatedFile.IsEntryPoint)
+
+	codeSpaceFiles, err := svc.ListCodeSpaceFiles(ctx, codeSpace.Name)
+	require.NoError(t, err)
+	require.Len(t, codeSpaceFiles, 2)
+
+	for _, codeSpaceFile := range codeSpaceFiles {
+		if codeSpaceFile.ID == utilsFile.ID {
+			require.False(t, codeSpaceFile.IsEntryPoint)
+		}
+	}
+}
+
+func TestServiceUpdateCodeSpaceFileFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	utilsFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+	testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "app.py", false)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	newName := "helpers.py"
+	mainFileName := "main.py"
+	takenName := "app.py"
+
+	testcases := map[string]struct {
+		userUUID        string
+		codeSpaceFileID int64
+		fileName        *string
+		wantErr         error
+	}{
+		"Viewer cannot update file": {
+			userUUID:        viewer.UUID,
+			codeSpaceFileID: utilsFile.ID,
+			fileName:        &newName,
+			wantErr:         errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Non-existent file": {
+			userUUID:        author.UUID,
+			codeSpaceFileID: 314159265,
+			fileName:        &newName,
+			wantErr:         errutils.ErrCodeSpaceFileNotFound,
+		},
+		"File cannot be renamed to main file": {
+			userUUID:        author.UUID,
+			codeSpaceFileID: utilsFile.ID,
+			fileName:        &mainFileName,
+			wantErr:         errutils.ErrCodeSpaceFileAlreadyExists,
+		},
+		"File cannot be renamed to existing file": {
+			userUUID:        author.UUID,
+			codeSpaceFileID: utilsFile.ID,
+			fileName:        &takenName,
+			wantErr:         errutils.ErrCodeSpaceFileAlreadyExists,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			_, err := svc.UpdateCodeSpaceFile(
+				ctx,
+				codeSpace.Name,
+				testcase.codeSpaceFileID,
+				testcase.fileName,
+				nil,
+				nil,
+			)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestServiceDeleteCodeSpaceFile(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	utilsFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", false)
+	appFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "app.py", false)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	testcases := map[string]struct {
+		userUUID        string
+		codeSpaceFileID int64
+		wantErr         error
+	}{
+		"Author can delete file": {
+			userUUID:        author.UUID,
+			codeSpaceFileID: utilsFile.ID,
+			wantErr:         nil,
+		},
+		"Viewer cannot delete file": {
+			userUUID:        viewer.UUID,
+			codeSpaceFileID: appFile.ID,
+			wantErr:         errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Non-existent file": {
+			userUUID:        author.UUID,
+			codeSpaceFileID: 314159265,
+			wantErr:         errutils.ErrCodeSpaceFileNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			err := svc.DeleteCodeSpaceFile(ctx, codeSpace.Name, testcase.codeSpaceFileID)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServiceListCodeSpaceUsers(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID string
+		wantErr  error
+	}{
+		"Author can list codespace users": {
+			userUUID: author.UUID,
+			wantErr:  nil,
+		},
+		"Editor can list codespace users": {
+			userUUID: editor.UUID,
+			wantErr:  nil,
+		},
+		"Viewer gets access denied": {
+			userUUID: viewer.UUID,
+			wantErr:  errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Third party user gets code space not found": {
+			userUUID: thirdPartyUser.UUID,
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			users, codeSpaceAccesses, err := svc.ListCodeSpaceUsers(ctx, codeSpace.Name)
+
+			if testcase.wantErr != nil {
+				require.Error(t, err)
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, users, 3)
+			require.Len(t, codeSpaceAccesses, 3)
+
+			userAccessLevelMap := map[string]code.CodeSpaceAccessLevel{
+				author.UUID: code.CodeSpaceAccessLevelReadWrite,
+				editor.UUID: code.CodeSpaceAccessLevelReadWrite,
+				viewer.UUID: code.CodeSpaceAccessLevelReadOnly,
+			}
+
+			for i, codeSpaceAccess := range codeSpaceAccesses {
+				wantAccessLevel, ok := userAccessLevelMap[codeSpaceAccess.UserUUID]
+				require.True(t, ok)
+
+				delete(userAccessLevelMap, codeSpaceAccess.UserUUID)
+
+				require.Equal(t, users[i].UUID, codeSpaceAccess.UserUUID)
+				require.Equal(t, codeSpace.ID, codeSpaceAccess.CodeSpaceID)
+				require.Equal(t, wantAccessLevel, codeSpaceAccess.Level)
+			}
+		})
+	}
+}
+
+func TestServiceListCodeSpaceUsersError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	genericRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed")
+	genericRepoListErr := errors.New("ListUsersWithCodeSpaceAccess failed")
+
+	testcases := map[string]struct {
+		ctx         context.Context
+		repoGetErr  error
+		repoListErr error
+		wantErr     error
+	}{
+		"No user UUID in context": {
+			ctx:         context.Background(),
+			repoGetErr:  nil,
+			repoListErr: nil,
+			wantErr:     nil,
+		},
+		"GetCodeSpaceWithAccessByName fails, no rows returned": {
+			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:  errutils.ErrDatabaseNoRowsReturned,
+			repoListErr: nil,
+			wantErr:     errutils.ErrCodeSpaceNotFound,
+		},
+		"GetCodeSpaceWithAccessByName fails, generic error": {
+			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:  genericRepoGetErr,
+			repoListErr: nil,
+			wantErr:     genericRepoGetErr,
+		},
+		"ListUsersWithCodeSpaceAccess fails": {
+			ctx:         context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			repoGetErr:  nil,
+			repoListErr: genericRepoListErr,
+			wantErr:     genericRepoListErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, testcase.repoGetErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListUsersWithCodeSpaceAccess(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(nil, nil, testcase.repoListErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, _, err := svc.ListCodeSpaceUsers(testcase.ctx, codeSpace.Name)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceSendCodeSpaceInvitationMailSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	email := testkit.GenerateFakeEmail()
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := databasemocks.NewMockPool(ctrl)
+	dbConn := databasemocks.NewMockConn(ctrl)
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := testkit.NewInMemMailClient("support@nymphadora.com", timeProvider)
+	tmplManager := templatesmanager.NewManager()
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := codemocks.NewMockRepository(ctrl)
+	authRepo := authmocks.NewMockRepository(ctrl)
+
+	dbConn.
+		EXPECT().
+		Release().
+		MaxTimes(1)
+
+	dbPool.
+		EXPECT().
+		Acquire(gomock.Any()).
+		Return(dbConn, nil).
+		MaxTimes(1)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		dbPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	invitationURL := "http://localhost:3000/code/space/" +
+		"habitable-slaking-volatile-granger-mov/invitation/" +
+		"1nv1t4t10njwt"
+	err := svc.SendCodeSpaceInvitationMail(
+		context.Background(),
+		email,
+		templatesmanager.CodeSpaceInvitationEmailTemplateData{
+			InvitationURL: invitationURL,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, mailClient.Logs, 1)
+
+	lastMail := mailClient.Logs[len(mailClient.Logs)-1]
+	require.Equal(t, []string{email}, lastMail.To)
+	require.Equal(t, "You've been invited to collaborate on a code space!", lastMail.Subject)
+	require.WithinDuration(t, timeProvider.Now(), lastMail.SentAt, testkit.TimeToleranceExact)
+
+	mailMessage := string(lastMail.Message)
+	require.Contains(t, mailMessage, "Nymphadora - You've been invited to collaborate on a code space!")
+	require.Contains(t, mailMessage, "Accept Invitation")
+	require.Contains(t, mailMessage, invitationURL)
+}
+
+func TestServiceSendCodeSpaceInvitationMailError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	invitationURL := "http://localhost:3000/code/space/" +
+		"habitable-slaking-volatile-granger-mov/invitation/" +
+		"1nv1t4t10njwt"
+	email := testkit.GenerateFakeEmail()
+	tmplLoadErr := errors.New("Load failed")
+	mailSendErr := errors.New("Send failed")
+
+	testcases := map[string]struct {
+		tmplLoadErr error
+		mailSendErr error
+		wantErr     error
+	}{
+		"template Load fails": {
+			tmplLoadErr: tmplLoadErr,
+			mailSendErr: nil,
+			wantErr:     tmplLoadErr,
+		},
+		"mail Send fails": {
+			tmplLoadErr: nil,
+			mailSendErr: mailSendErr,
+			wantErr:     mailSendErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			tmplManager.
+				EXPECT().
+				Load("codespaceinvitation").
+				Return(texttemplate.New("text"), htmltemplate.New("html"), testcase.tmplLoadErr).
+				MaxTimes(1)
+
+			mailClient.
+				EXPECT().
+				Send(
+					[]string{email},
+					"You've been invited to collaborate on a code space!",
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+				).
+				Return(testcase.mailSendErr).
+				MaxTimes(1)
+
+			err := svc.SendCodeSpaceInvitationMail(
+				context.Background(),
+				email,
+				templatesmanager.CodeSpaceInvitationEmailTemplateData{
+					InvitationURL: invitationURL,
+				},
+			)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestServiceInviteCodeSpaceUserSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		inviteeEmail string
+		accessLevel  code.CodeSpaceAccessLevel
+	}{
+		"Author invites editor": {
+			inviteeEmail: editor.Email,
+			accessLevel:  code.CodeSpaceAccessLevelReadWrite,
+		},
+		"Author invites viewer": {
+			inviteeEmail: viewer.Email,
+			accessLevel:  code.CodeSpaceAccessLevelReadOnly,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
+			mailClient := testkit.NewInMemMailClient("support@nymphadora.com", timeProvider)
+			tmplManager := templatesmanager.NewManager()
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			mailCount := len(mailClient.Logs)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+			err := svc.InviteCodeSpaceUser(ctx, codeSpace.Name, testcase.inviteeEmail, testcase.accessLevel)
+			require.NoError(t, err)
+
+			require.Len(t, mailClient.Logs, mailCount+1)
+
+			lastMail := mailClient.Logs[len(mailClient.Logs)-1]
+			require.Equal(t, []string{testcase.inviteeEmail}, lastMail.To)
+			require.Equal(t, "You've been invited to collaborate on a code space!", lastMail.Subject)
+			require.WithinDuration(t, timeProvider.Now(), lastMail.SentAt, testkit.TimeToleranceExact)
+
+			mailMessage := string(lastMail.Message)
+			require.Contains(t, mailMessage, "Nymphadora - You've been invited to collaborate on a code space!")
+			require.Contains(t, mailMessage, "Accept Invitation")
+
+			pattern := fmt.Sprintf(
+				cfg.FrontendBaseURL+code.FrontendCodeSpaceInvitationRoute,
+				codeSpace.Name,
+				`(\S+)`,
+			)
+			r, err := regexp.Compile(pattern)
+			require.NoError(t, err)
+
+			matches := r.FindStringSubmatch(mailMessage)
+			require.Len(t, matches, 2)
+
+			invitationToken := matches[1]
+			claims := &cryptocore.CodeSpaceInvitationJWTClaims{}
+			parsedToken, err := jwt.ParseWithClaims(invitationToken, claims, func(t *jwt.Token) (any, error) {
+				return []byte(cfg.SecretKey), nil
+			})
+			require.NoError(t, err)
+
+			require.NotNil(t, parsedToken)
+			require.True(t, parsedToken.Valid)
+			require.Equal(t, author.UUID, claims.Subject)
+			require.Equal(t, testcase.inviteeEmail, claims.InviteeEmail)
+			require.Equal(t, string(cryptocore.JWTTypeCodeSpaceInvitation), claims.TokenType)
+			require.WithinDuration(t, timeProvider.Now(), time.Time(claims.IssuedAt), testkit.TimeToleranceExact)
+			require.WithinDuration(
+				t,
+				timeProvider.Now().Add(cryptocore.JWTLifetimeCodeSpaceInvitation),
+				time.Time(claims.ExpiresAt),
+				testkit.TimeToleranceExact,
+			)
+		})
+	}
+}
+
+func TestServiceInviteCodeSpaceUserError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	inviteeEmail := testkit.GenerateFakeEmail()
+
+	genericRepoErr := errors.New("GetCodeSpaceWithAccessByName failed")
+	createJWTErr := errors.New("CreateCodeSpaceInvitationJWT failed")
+	mailClientErr := errors.New("Send failed")
+
+	testcases := map[string]struct {
+		ctx               context.Context
+		authorAccessLevel code.CodeSpaceAccessLevel
+		repoErr           error
+		createJWTErr      error
+		mailClientErr     error
+		wantErr           error
+	}{
+		"No user UUID in context": {
+			ctx:               context.Background(),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+			repoErr:           nil,
+			createJWTErr:      nil,
+			mailClientErr:     nil,
+			wantErr:           nil,
+		},
+		"Access denied without write access": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadOnly,
+			repoErr:           nil,
+			createJWTErr:      nil,
+			mailClientErr:     nil,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"GetCodeSpaceWithAccessByName fails, no rows returned": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+			repoErr:           errutils.ErrDatabaseNoRowsReturned,
+			createJWTErr:      nil,
+			mailClientErr:     nil,
+			wantErr:           errutils.ErrCodeSpaceNotFound,
+		},
+		"GetCodeSpaceWithAccessByName fails, generic error": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+			repoErr:           genericRepoErr,
+			createJWTErr:      nil,
+			mailClientErr:     nil,
+			wantErr:           genericRepoErr,
+		},
+		"CreateCodeSpaceInvitationJWT fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+			repoErr:           nil,
+			createJWTErr:      createJWTErr,
+			mailClientErr:     nil,
+			wantErr:           createJWTErr,
+		},
+		"mailClient.Send fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorAccessLevel: code.CodeSpaceAccessLevelReadWrite,
+			repoErr:           nil,
+			createJWTErr:      nil,
+			mailClientErr:     mailClientErr,
+			wantErr:           mailClientErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       testcase.authorAccessLevel,
+			}
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, testcase.repoErr).
+				MaxTimes(1)
+
+			crypto.
+				EXPECT().
+				CreateCodeSpaceInvitationJWT(
+					authorUUID,
+					inviteeEmail,
+					codeSpace.ID,
+					int(code.CodeSpaceAccessLevelReadWrite),
+				).
+				Return("1nv1t4t10nt0k3n", testcase.createJWTErr).
+				MaxTimes(1)
+
+			tmplManager.
+				EXPECT().
+				Load("codespaceinvitation").
+				Return(texttemplate.New("text"), htmltemplate.New("html"), nil).
+				MaxTimes(1)
+
+			mailClient.
+				EXPECT().
+				Send(
+					[]string{inviteeEmail},
+					"You've been invited to collaborate on a code space!",
+					gomock.Any(),
+					gomock.Any(),
+					gomock.Any(),
+				).
+				Return(testcase.mailClientErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			err := svc.InviteCodeSpaceUser(
+				testcase.ctx,
+				codeSpace.Name,
+				inviteeEmail,
+				code.CodeSpaceAccessLevelReadWrite,
+			)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceRemoveCodeSpaceUserAuthorCanRemoveViewer(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, viewer.UUID)
+	require.NoError(t, err)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	userUUIDs := make([]string, len(users))
+	for i, user := range users {
+		userUUIDs[i] = user.UUID
+	}
+
+	require.NotContains(t, userUUIDs, viewer.UUID)
+}
+
+func TestServiceRemoveCodeSpaceUserEditorCanRemoveViewer(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, editor.UUID)
+	err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, viewer.UUID)
+	require.NoError(t, err)
+
+	dbConn, err := TestDBPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+	require.NoError(t, err)
+
+	userUUIDs := make([]string, len(users))
+	for i, user := range users {
+		userUUIDs[i] = user.UUID
+	}
+
+	require.NotContains(t, userUUIDs, viewer.UUID)
+}
+
+func TestServiceRemoveCodeSpaceUserSelfRemoval(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	testcases := map[string]struct {
+		codeSpaceUserUUID string
+	}{
+		"Author can remove self access": {
+			codeSpaceUserUUID: author.UUID,
+		},
+		"Editor can remove self access": {
+			codeSpaceUserUUID: editor.UUID,
+		},
+		"Viewer can remove self access": {
+			codeSpaceUserUUID: viewer.UUID,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.codeSpaceUserUUID)
+			err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, testcase.codeSpaceUserUUID)
+			require.NoError(t, err)
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
+			require.NoError(t, err)
+
+			userUUIDs := make([]string, len(users))
+			for i, user := range users {
+				userUUIDs[i] = user.UUID
+			}
+
+			require.NotContains(t, userUUIDs, testcase.codeSpaceUserUUID)
+		})
+	}
+}
+
+func TestServiceRemoveCodeSpaceUserFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	editor1, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor1.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	editor2, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		editor2.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadWrite,
+	)
+
+	viewer1, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer1.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	viewer2, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer2.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID          string
+		codeSpaceUserUUID string
+		wantErr           error
+	}{
+		"Author cannot remove editor access": {
+			userUUID:          author.UUID,
+			codeSpaceUserUUID: editor1.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Editor cannot remove author access": {
+			userUUID:          editor1.UUID,
+			codeSpaceUserUUID: author.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Editor cannot remove editor access": {
+			userUUID:          editor1.UUID,
+			codeSpaceUserUUID: editor2.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Viewer cannot remove author access": {
+			userUUID:          viewer1.UUID,
+			codeSpaceUserUUID: author.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Viewer cannot remove editor access": {
+			userUUID:          viewer1.UUID,
+			codeSpaceUserUUID: editor1.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Viewer cannot remove viewer access": {
+			userUUID:          viewer1.UUID,
+			codeSpaceUserUUID: viewer2.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Third party user cannot remove author access": {
+			userUUID:          thirdPartyUser.UUID,
+			codeSpaceUserUUID: author.UUID,
+			wantErr:           errutils.ErrCodeSpaceNotFound,
+		},
+		"Third party user cannot remove editor access": {
+			userUUID:          thirdPartyUser.UUID,
+			codeSpaceUserUUID: editor1.UUID,
+			wantErr:           errutils.ErrCodeSpaceNotFound,
+		},
+		"Third party user cannot remove viewer access": {
+			userUUID:          thirdPartyUser.UUID,
+			codeSpaceUserUUID: viewer1.UUID,
+			wantErr:           errutils.ErrCodeSpaceNotFound,
+		},
+		"Third party user access not found": {
+			userUUID:          author.UUID,
+			codeSpaceUserUUID: thirdPartyUser.UUID,
+			wantErr:           errutils.ErrCodeSpaceAccessNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, testcase.codeSpaceUserUUID)
+			require.Error(t, err)
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestServiceRemoveCodeSpaceUserError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	viewerUUID := uuid.NewString()
+
+	authorRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed for author")
+	viewerRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed for viewer")
+	repoDeleteErr := errors.New("DeleteCodeSpaceAccess failed")
+
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	authorCodeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+	viewerCodeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          159,
+		UserUUID:    viewerUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadOnly,
+	}
+
+	testcases := map[string]struct {
+		ctx              context.Context
+		authorRepoGetErr error
+		viewerRepoGetErr error
+		repoDeleteErr    error
+		wantErr          error
+	}{
+		"No user UUID in context": {
+			ctx:              context.Background(),
+			authorRepoGetErr: nil,
+			viewerRepoGetErr: nil,
+			repoDeleteErr:    nil,
+			wantErr:          nil,
+		},
+		"GetCodeSpaceWithAccessByName fails for author": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorRepoGetErr: authorRepoGetErr,
+			viewerRepoGetErr: nil,
+			repoDeleteErr:    nil,
+			wantErr:          authorRepoGetErr,
+		},
+		"GetCodeSpaceWithAccessByName fails for viwer": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorRepoGetErr: nil,
+			viewerRepoGetErr: viewerRepoGetErr,
+			repoDeleteErr:    nil,
+			wantErr:          viewerRepoGetErr,
+		},
+		"DeleteCodeSpaceAccess fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			authorRepoGetErr: nil,
+			viewerRepoGetErr: nil,
+			repoDeleteErr:    repoDeleteErr,
+			wantErr:          repoDeleteErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, authorCodeSpaceAccess, testcase.authorRepoGetErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), viewerUUID, codeSpace.Name).
+				Return(codeSpace, viewerCodeSpaceAccess, testcase.viewerRepoGetErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				DeleteCodeSpaceAccess(gomock.Any(), gomock.Any(), viewerUUID, codeSpace.ID).
+				Return(testcase.repoDeleteErr).
+				MaxTimes(1)
+
+			_, _, logger := testkit.CreateInMemLogger()
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			err := svc.RemoveCodeSpaceUser(testcase.ctx, codeSpace.Name, viewerUUID)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceListCodeSpaceTestCases(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	visibleTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"Yello!",
+		api.CodeSpaceTestComparisonModeTrimmed,
+		false,
+	)
+	hiddenTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"Yello!",
+		api.CodeSpaceTestComparisonModeExact,
+		true,
+	)
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID string
+		wantIDs  []int64
+		wantErr  error
+	}{
+		"Author can list all test cases": {
+			userUUID: author.UUID,
+			wantIDs:  []int64{visibleTestCase.ID, hiddenTestCase.ID},
+			wantErr:  nil,
+		},
+		"Viewer can only list visible test cases": {
+			userUUID: viewer.UUID,
+			wantIDs:  []int64{visibleTestCase.ID},
+			wantErr:  nil,
+		},
+		"Third party user cannot list test cases": {
+			userUUID: thirdPartyUser.UUID,
+			wantIDs:  nil,
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			codeSpaceTestCases, err := svc.ListCodeSpaceTestCases(ctx, codeSpace.Name)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			ids := make([]int64, len(codeSpaceTestCases))
+			for i, codeSpaceTestCase := range codeSpaceTestCases {
+				ids[i] = codeSpaceTestCase.ID
+			}
+
+			require.Equal(t, testcase.wantIDs, ids)
+		})
+	}
+}
+
+func TestServiceCreateCodeSpaceTestCaseSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	codeSpaceTestCase, err := svc.CreateCodeSpaceTestCase(
+		ctx,
+		codeSpace.Name,
+		nil,
+		nil,
+		`Yello!?`,
+		api.CodeSpaceTestComparisonModeRegex,
+		true,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceTestCase.CodeSpaceID)
+	require.Nil(t, codeSpaceTestCase.Stdin)
+	require.Empty(t, codeSpaceTestCase.Args)
+	require.Equal(t, `Yello!?`, codeSpaceTestCase.ExpectedStdout)
+	require.Equal(t, api.CodeSpaceTestComparisonModeRegex, codeSpaceTestCase.ComparisonMode)
+	require.True(t, codeSpaceTestCase.IsHidden)
+}
+
+func TestServiceCreateCodeSpaceTestCaseError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	viewerUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+
+	fullCodeSpaceTestCases := make([]*code.CodeSpaceTestCase, api.CodeSpaceTestCasesMaxCount)
+	genericDBBeginErr := errors.New("Begin failed")
+	genericListTestCasesErr := errors.New("ListCodeSpaceTestCases failed")
+	genericCreateErr := errors.New("CreateCodeSpaceTestCase failed")
+	genericDBCommitErr := errors.New("Commit failed")
+
+	testcases := map[string]struct {
+		ctx              context.Context
+		accessLevel      code.CodeSpaceAccessLevel
+		expectedStdout   string
+		comparisonMode   string
+		dbBeginErr       error
+		testCases        []*code.CodeSpaceTestCase
+		listTestCasesErr error
+		createErr        error
+		dbCommitErr      error
+		wantErr          error
+	}{
+		"No user UUID in context": {
+			ctx:              context.Background(),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          nil,
+		},
+		"Viewer cannot create test cases": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, viewerUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadOnly,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Begin fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       genericDBBeginErr,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          genericDBBeginErr,
+		},
+		"ListCodeSpaceTestCases fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: genericListTestCasesErr,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          genericListTestCasesErr,
+		},
+		"Too many test cases": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        fullCodeSpaceTestCases,
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          errutils.ErrCodeSpaceTestCaseLimitExceeded,
+		},
+		"Invalid pattern": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   `(Yello!`,
+			comparisonMode:   api.CodeSpaceTestComparisonModeRegex,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      nil,
+			wantErr:          errutils.ErrCodeSpaceTestCaseInvalidPattern,
+		},
+		"CreateCodeSpaceTestCase fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        genericCreateErr,
+			dbCommitErr:      nil,
+			wantErr:          genericCreateErr,
+		},
+		"Commit fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:      code.CodeSpaceAccessLevelReadWrite,
+			expectedStdout:   "Yello!",
+			comparisonMode:   api.CodeSpaceTestComparisonModeExact,
+			dbBeginErr:       nil,
+			testCases:        []*code.CodeSpaceTestCase{},
+			listTestCasesErr: nil,
+			createErr:        nil,
+			dbCommitErr:      genericDBCommitErr,
+			wantErr:          genericDBCommitErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(testcase.dbCommitErr).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, testcase.dbBeginErr).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			userUUID, _ := testcase.ctx.Value(auth.AuthContextKeyUserUUID).(string)
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    userUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       testcase.accessLevel,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), gomock.Any(), codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceTestCases(gomock.Any(), gomock.Any(), codeSpace.ID, true).
+				Return(testcase.testCases, testcase.listTestCasesErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceTestCase(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&code.CodeSpaceTestCase{}, testcase.createErr).
+				MaxTimes(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.CreateCodeSpaceTestCase(
+				testcase.ctx,
+				codeSpace.Name,
+				nil,
+				nil,
+				testcase.expectedStdout,
+				testcase.comparisonMode,
+				false,
+			)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceListCodeSpaceRunConfigs(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+	verboseRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+	quietRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "quiet", []string{"-q"})
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
+		codeSpace.ID,
+		code.CodeSpaceAccessLevelReadOnly,
+	)
+
+	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	testcases := map[string]struct {
+		userUUID string
+		wantIDs  []int64
+		wantErr  error
+	}{
+		"Author can list run configs": {
+			userUUID: author.UUID,
+			wantIDs:  []int64{quietRunConfig.ID, verboseRunConfig.ID},
+			wantErr:  nil,
+		},
+		"Viewer can list run configs": {
+			userUUID: viewer.UUID,
+			wantIDs:  []int64{quietRunConfig.ID, verboseRunConfig.ID},
+			wantErr:  nil,
+		},
+		"Third party user cannot list run configs": {
+			userUUID: thirdPartyUser.UUID,
+			wantIDs:  nil,
+			wantErr:  errutils.ErrCodeSpaceNotFound,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := code.NewRepository(timeProvider)
+			authRepo := auth.NewRepository(timeProvider)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				TestDBPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
+			codeSpaceRunConfigs, err := svc.ListCodeSpaceRunConfigs(ctx, codeSpace.Name)
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			ids := make([]int64, len(codeSpaceRunConfigs))
+			for i, codeSpaceRunConfig := range codeSpaceRunConfigs {
+				ids[i] = codeSpaceRunConfig.ID
+			}
+
+			require.Equal(t, testcase.wantIDs, ids)
+		})
+	}
+}
+
+func TestServiceCreateCodeSpaceRunConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	_, _, logger := testkit.CreateInMemLogger()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := pistonmocks.NewMockClient(ctrl)
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	stdin := "Harry\n"
+	runTimeout := int64(1000)
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	codeSpaceRunConfig, err := svc.CreateCodeSpaceRunConfig(
+		ctx,
+		codeSpace.Name,
+		"sorting-hat",
+		&stdin,
+		nil,
+		nil,
+		&runTimeout,
+		nil,
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, codeSpace.ID, codeSpaceRunConfig.CodeSpaceID)
+	require.Equal(t, "sorting-hat", codeSpaceRunConfig.Name)
+	require.NotNil(t, codeSpaceRunConfig.Stdin)
+	require.Equal(t, stdin, *codeSpaceRunConfig.Stdin)
+	require.Empty(t, codeSpaceRunConfig.Args)
+	require.NotNil(t, codeSpaceRunConfig.RunTimeout)
+	require.Equal(t, runTimeout, *codeSpaceRunConfig.RunTimeout)
+	require.Nil(t, codeSpaceRunConfig.LanguageVersion)
+
+	_, err = svc.CreateCodeSpaceRunConfig(
+		ctx,
+		codeSpace.Name,
+		"sorting-hat",
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceRunConfigAlreadyExists)
+}
+
+func TestServiceCreateCodeSpaceRunConfigError(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	viewerUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+
+	runTimeout := int64(1000)
+	excessiveRunTimeout := cfg.PistonMaxRunTimeout + 1
+	fullCodeSpaceRunConfigs := make([]*code.CodeSpaceRunConfig, api.CodeSpaceRunConfigsMaxCount)
+	genericDBBeginErr := errors.New("Begin failed")
+	genericListRunConfigsErr := errors.New("ListCodeSpaceRunConfigs failed")
+	genericCreateErr := errors.New("CreateCodeSpaceRunConfig failed")
+	genericDBCommitErr := errors.New("Commit failed")
+
+	testcases := map[string]struct {
+		ctx               context.Context
+		accessLevel       code.CodeSpaceAccessLevel
+		runTimeout        *int64
+		dbBeginErr        error
+		runConfigs        []*code.CodeSpaceRunConfig
+		listRunConfigsErr error
+		createErr         error
+		dbCommitErr       error
+		wantErr           error
+	}{
+		"No user UUID in context": {
+			ctx:               context.Background(),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           nil,
+		},
+		"Run timeout exceeds maximum": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &excessiveRunTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           errutils.ErrCodeSpaceRunLimitExceeded,
+		},
+		"Viewer cannot create run configs": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, viewerUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadOnly,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           errutils.ErrCodeSpaceAccessDenied,
+		},
+		"Begin fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        genericDBBeginErr,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           genericDBBeginErr,
+		},
+		"ListCodeSpaceRunConfigs fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: genericListRunConfigsErr,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           genericListRunConfigsErr,
+		},
+		"Too many run configs": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        fullCodeSpaceRunConfigs,
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       nil,
+			wantErr:           errutils.ErrCodeSpaceRunConfigLimitExceeded,
+		},
+		"Run config already exists": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         errutils.ErrDatabaseUniqueViolation,
+			dbCommitErr:       nil,
+			wantErr:           errutils.ErrCodeSpaceRunConfigAlreadyExists,
+		},
+		"CreateCodeSpaceRunConfig fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         genericCreateErr,
+			dbCommitErr:       nil,
+			wantErr:           genericCreateErr,
+		},
+		"Commit fails": {
+			ctx:               context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			accessLevel:       code.CodeSpaceAccessLevelReadWrite,
+			runTimeout:        &runTimeout,
+			dbBeginErr:        nil,
+			runConfigs:        []*code.CodeSpaceRunConfig{},
+			listRunConfigsErr: nil,
+			createErr:         nil,
+			dbCommitErr:       genericDBCommitErr,
+			wantErr:           genericDBCommitErr,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocoremocks.NewMockCrypto(ctrl)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(testcase.dbCommitErr).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, testcase.dbBeginErr).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				MaxTimes(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				MaxTimes(1)
+
+			userUUID, _ := testcase.ctx.Value(auth.AuthContextKeyUserUUID).(string)
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    userUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       testcase.accessLevel,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), gomock.Any(), codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceRunConfigs(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(testcase.runConfigs, testcase.listRunConfigsErr).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRunConfig(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&code.CodeSpaceRunConfig{}, testcase.createErr).
+				MaxTimes(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			_, err := svc.CreateCodeSpaceRunConfig(
+				testcase.ctx,
+				codeSpace.Name,
+				"sorting-hat",
+				nil,
+				nil,
+				nil,
+				testcase.runTimeout,
+				nil,
+				nil,
+				nil,
+			)
+			require.Error(t, err)
+
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceRunConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	testkitinternal.MustCreateCodeSpaceAccess(
+		t,
+		viewer.UUID,
 		codeSpace.ID,
 		code.CodeSpaceAccessLevelReadOnly,
 	)
 
+	ctrl := gomock.NewController(t)
+	timeProvider := timekeeper.NewFrozenProvider()
+	crypto := cryptocoremocks.NewMockCrypto(ctrl)
+	mailClient := mailclientmocks.NewMockClient(ctrl)
+	tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+	pistonClient := piston.NewFakeClient(piston.WithFakeClientRuntimes([]*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
+		},
+	}))
+	repo := code.NewRepository(timeProvider)
+	authRepo := auth.NewRepository(timeProvider)
+
+	_, _, logger := testkit.CreateInMemLogger()
+	svc := code.NewService(
+		cfg,
+		timeProvider,
+		TestDBPool,
+		logger,
+		crypto,
+		mailClient,
+		tmplManager,
+		pistonClient,
+		nil,
+		repo,
+		authRepo,
+	)
+
+	authorCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, author.UUID)
+	viewerCtx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, viewer.UUID)
+
+	configVersion := "3.9.4"
+	_, err := svc.CreateCodeSpaceRunConfig(
+		viewerCtx,
+		codeSpace.Name,
+		"legacy",
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		&configVersion,
+	)
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceAccessDenied)
+
+	_, err = svc.CreateCodeSpaceRunConfig(
+		authorCtx,
+		codeSpace.Name,
+		"legacy",
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		&configVersion,
+	)
+	require.NoError(t, err)
+
+	runConfigName := "legacy"
+	codeSpaceRun, err := svc.RunCodeSpace(viewerCtx, codeSpace.Name, &code.RunCodeSpaceOptions{
+		RunConfig: &runConfigName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, configVersion, codeSpaceRun.Version)
+
+	unknownRunConfigName := "modern"
+	_, err = svc.RunCodeSpace(viewerCtx, codeSpace.Name, &code.RunCodeSpaceOptions{
+		RunConfig: &unknownRunConfigName,
+	})
+	require.ErrorIs(t, err, errutils.ErrCodeSpaceRunConfigNotFound)
+}
+
+func TestServiceListCodeSpaceEnvVars(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+
+	password := "Caput Draconis"
+	encryptedPassword, err := cryptocore.NewCrypto(timekeeper.NewFrozenProvider(), cfg.SecretKey).EncryptSecret(password)
+	require.NoError(t, err)
+
+	house := "Gryffindor"
+
+	testcases := map[string]struct {
+		accessLevel  code.CodeSpaceAccessLevel
+		wantPassword *string
+	}{
+		"Read-write access reveals secrets": {
+			accessLevel:  code.CodeSpaceAccessLevelReadWrite,
+			wantPassword: &password,
+		},
+		"Read-only access withholds secrets": {
+			accessLevel:  code.CodeSpaceAccessLevelReadOnly,
+			wantPassword: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			userUUID := uuid.NewString()
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    userUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       testcase.accessLevel,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), userUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{
+					{
+						ID:          1,
+						CodeSpaceID: codeSpace.ID,
+						Name:        "HOUSE",
+						Value:       &house,
+						IsSecret:    false,
+					},
+					{
+						ID:          2,
+						CodeSpaceID: codeSpace.ID,
+						Name:        "PASSWORD",
+						Value:       &encryptedPassword,
+						IsSecret:    true,
+					},
+				}, nil).
+				Times(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			codeSpaceEnvVars, err := svc.ListCodeSpaceEnvVars(ctx, codeSpace.Name)
+			require.NoError(t, err)
+			require.Len(t, codeSpaceEnvVars, 2)
+
+			require.Equal(t, "HOUSE", codeSpaceEnvVars[0].Name)
+			require.Equal(t, &house, codeSpaceEnvVars[0].Value)
+			require.Equal(t, "PASSWORD", codeSpaceEnvVars[1].Name)
+			require.Equal(t, testcase.wantPassword, codeSpaceEnvVars[1].Value)
+		})
+	}
+}
+
+func TestServiceUpdateCodeSpaceEnvVar(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+	codeSpace := &code.CodeSpace{
+		ID:         42,
+		AuthorUUID: &authorUUID,
+		Name:       "habitable-slaking-volatile-granger-mov",
+		Language:   "python",
+		Contents:   "print('hello')",
+	}
+	codeSpaceAccess := &code.CodeSpaceAccess{
+		ID:          314,
+		UserUUID:    authorUUID,
+		CodeSpaceID: codeSpace.ID,
+		Level:       code.CodeSpaceAccessLevelReadWrite,
+	}
+
+	password := "Caput Draconis"
+	encryptedPassword, err := cryptocore.NewCrypto(timekeeper.NewFrozenProvider(), cfg.SecretKey).EncryptSecret(password)
+	require.NoError(t, err)
+
+	newName := "PASSWORD_2"
+	newPassword := "Fortuna Major"
+	secret := true
+	notSecret := false
+
 	testcases := map[string]struct {
-		codeSpaceUserUUID string
+		envVarName   *string
+		value        *string
+		isSecret     *bool
+		wantGet      bool
+		wantValue    string
+		wantIsSecret bool
 	}{
-		"Author can remove self access": {
-			codeSpaceUserUUID: author.UUID,
+		"Rename keeps stored value": {
+			envVarName:   &newName,
+			value:        nil,
+			isSecret:     nil,
+			wantGet:      false,
+			wantValue:    password,
+			wantIsSecret: true,
 		},
-		"Editor can remove self access": {
-			codeSpaceUserUUID: editor.UUID,
+		"New value is encrypted": {
+			envVarName:   nil,
+			value:        &newPassword,
+			isSecret:     nil,
+			wantGet:      true,
+			wantValue:    newPassword,
+			wantIsSecret: true,
 		},
-		"Viewer can remove self access": {
-			codeSpaceUserUUID: viewer.UUID,
+		"Making not secret decrypts value": {
+			envVarName:   nil,
+			value:        nil,
+			isSecret:     &notSecret,
+			wantGet:      true,
+			wantValue:    password,
+			wantIsSecret: false,
+		},
+		"Keeping secret with new value": {
+			envVarName:   nil,
+			value:        &newPassword,
+			isSecret:     &secret,
+			wantGet:      true,
+			wantValue:    newPassword,
+			wantIsSecret: true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				Times(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				Times(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			getTimes := 0
+			if testcase.wantGet {
+				getTimes = 1
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceEnvVar(gomock.Any(), gomock.Any(), codeSpace.ID, int64(7)).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					_ int64,
+					_ int64,
+				) (*code.CodeSpaceEnvVar, error) {
+					storedPassword := encryptedPassword
+
+					return &code.CodeSpaceEnvVar{
+						ID:          7,
+						CodeSpaceID: codeSpace.ID,
+						Name:        "PASSWORD",
+						Value:       &storedPassword,
+						IsSecret:    true,
+					}, nil
+				}).
+				Times(getTimes)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpaceEnvVar(gomock.Any(), gomock.Any(), codeSpace.ID, int64(7), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					_ int64,
+					_ int64,
+					envVarName *string,
+					value *string,
+					isSecret *bool,
+				) (*code.CodeSpaceEnvVar, error) {
+					require.Equal(t, testcase.envVarName, envVarName)
+
+					if !testcase.wantGet {
+						require.Nil(t, value)
+						require.Nil(t, isSecret)
+
+						storedPassword := encryptedPassword
+						value = &storedPassword
+						isSecret = &secret
+					}
+
+					require.NotNil(t, value)
+					require.NotNil(t, isSecret)
+					require.Equal(t, testcase.wantIsSecret, *isSecret)
+
+					if testcase.wantIsSecret {
+						require.NotEqual(t, testcase.wantValue, *value)
+
+						decryptedValue, err := crypto.DecryptSecret(*value)
+						require.NoError(t, err)
+						require.Equal(t, testcase.wantValue, decryptedValue)
+					} else {
+						require.Equal(t, testcase.wantValue, *value)
+					}
+
+					updatedName := "PASSWORD"
+					if envVarName != nil {
+						updatedName = *envVarName
+					}
+
+					return &code.CodeSpaceEnvVar{
+						ID:          7,
+						CodeSpaceID: codeSpace.ID,
+						Name:        updatedName,
+						Value:       value,
+						IsSecret:    *isSecret,
+					}, nil
+				}).
+				Times(1)
+
+			svc := code.NewService(
+				cfg,
+				timeProvider,
+				dbPool,
+				logger,
+				crypto,
+				mailClient,
+				tmplManager,
+				pistonClient,
+				nil,
+				repo,
+				authRepo,
+			)
+
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
+			codeSpaceEnvVar, err := svc.UpdateCodeSpaceEnvVar(
+				ctx,
+				codeSpace.Name,
+				7,
+				testcase.envVarName,
+				testcase.value,
+				testcase.isSecret,
+			)
+			require.NoError(t, err)
+			require.NotNil(t, codeSpaceEnvVar.Value)
+			require.Equal(t, testcase.wantValue, *codeSpaceEnvVar.Value)
+			require.Equal(t, testcase.wantIsSecret, codeSpaceEnvVar.IsSecret)
+		})
+	}
+}
+
+func TestServiceRunCodeSpaceEnvVars(t *testing.T) {
+	t.Parallel()
+
+	cfg := testkitinternal.MustCreateConfig()
+
+	authorUUID := uuid.NewString()
+
+	password := "Caput Draconis"
+	encryptedPassword, err := cryptocore.NewCrypto(timekeeper.NewFrozenProvider(), cfg.SecretKey).EncryptSecret(password)
+	require.NoError(t, err)
+
+	motto := `Draco dormiens "nunquam" titillandus`
+	envVars := []*code.CodeSpaceEnvVar{
+		{
+			ID:       1,
+			Name:     "MOTTO",
+			Value:    &motto,
+			IsSecret: false,
+		},
+		{
+			ID:       2,
+			Name:     "PASSWORD",
+			Value:    &encryptedPassword,
+			IsSecret: true,
+		},
+	}
+
+	encoding := api.PistonFileEncoding
+	envEntryPointPython := "__env__.py"
+	envEntryPointJavaScript := "__env__.js"
+	mainPython := "main.py"
+	mainJavaScript := "index.js"
+	mainGo := "main.go"
+
+	testcases := map[string]struct {
+		language    string
+		contents    string
+		accessLevel code.CodeSpaceAccessLevel
+		envVars     []*code.CodeSpaceEnvVar
+		wantFiles   []api.PistonFile
+		wantErr     error
+	}{
+		"Python with read-write access sets secrets": {
+			language:    "python",
+			contents:    "import os\nprint(os.environ['MOTTO'])",
+			accessLevel: code.CodeSpaceAccessLevelReadWrite,
+			envVars:     envVars,
+			wantFiles: []api.PistonFile{
+				{
+					Name: &envEntryPointPython,
+					Content: `import os
+import runpy
+import sys
+
+os.environ.update({"MOTTO":"Draco dormiens \"nunquam\" titillandus","PASSWORD":"Caput Draconis"})
+sys.argv[0] = "main.py"
+runpy.run_path("main.py", run_name="__main__")
+`,
+					Encoding: &encoding,
+				},
+				{
+					Name:     &mainPython,
+					Content:  "import os\nprint(os.environ['MOTTO'])",
+					Encoding: &encoding,
+				},
+			},
+			wantErr: nil,
+		},
+		"Python with read-only access leaves out secrets": {
+			language:    "python",
+			contents:    "import os\nprint(os.environ['MOTTO'])",
+			accessLevel: code.CodeSpaceAccessLevelReadOnly,
+			envVars:     envVars,
+			wantFiles: []api.PistonFile{
+				{
+					Name: &envEntryPointPython,
+					Content: `import os
+import runpy
+import sys
+
+os.environ.update({"MOTTO":"Draco dormiens \"nunquam\" titillandus"})
+sys.argv[0] = "main.py"
+runpy.run_path("main.py", run_name="__main__")
+`,
+					Encoding: &encoding,
+				},
+				{
+					Name:     &mainPython,
+					Content:  "import os\nprint(os.environ['MOTTO'])",
+					Encoding: &encoding,
+				},
+			},
+			wantErr: nil,
+		},
+		"JavaScript": {
+			language:    "javascript",
+			contents:    "console.log(process.env.MOTTO);",
+			accessLevel: code.CodeSpaceAccessLevelReadWrite,
+			envVars:     envVars,
+			wantFiles: []api.PistonFile{
+				{
+					Name: &envEntryPointJavaScript,
+					Content: `const path = require("path");
+
+Object.assign(process.env, {"MOTTO":"Draco dormiens \"nunquam\" titillandus","PASSWORD":"Caput Draconis"});
+process.argv[1] = path.resolve("index.js");
+require(process.argv[1]);
+`,
+					Encoding: &encoding,
+				},
+				{
+					Name:     &mainJavaScript,
+					Content:  "console.log(process.env.MOTTO);",
+					Encoding: &encoding,
+				},
+			},
+			wantErr: nil,
+		},
+		"Language without environment variable support": {
+			language:    "go",
+			contents:    "package main",
+			accessLevel: code.CodeSpaceAccessLevelReadWrite,
+			envVars:     envVars,
+			wantFiles:   nil,
+			wantErr:     errutils.ErrCodeSpaceEnvVarsUnsupported,
+		},
+		"Language without environment variable support and no environment variables": {
+			language:    "go",
+			contents:    "package main",
+			accessLevel: code.CodeSpaceAccessLevelReadWrite,
+			envVars:     []*code.CodeSpaceEnvVar{},
+			wantFiles: []api.PistonFile{
+				{
+					Name:     &mainGo,
+					Content:  "package main",
+					Encoding: &encoding,
+				},
+			},
+			wantErr: nil,
 		},
 	}
 
-	for name, testcase := range testcases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			timeProvider := timekeeper.NewFrozenProvider()
+			_, _, logger := testkit.CreateInMemLogger()
+			dbPool := databasemocks.NewMockPool(ctrl)
+			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
+			crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
+			mailClient := mailclientmocks.NewMockClient(ctrl)
+			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
+			pistonClient := pistonmocks.NewMockClient(ctrl)
+			repo := codemocks.NewMockRepository(ctrl)
+			authRepo := authmocks.NewMockRepository(ctrl)
+
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Release().
+				Times(1)
+
+			dbPool.
+				EXPECT().
+				Acquire(gomock.Any()).
+				Return(dbConn, nil).
+				Times(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   testcase.language,
+				Contents:   testcase.contents,
+			}
+			userUUID := uuid.NewString()
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    userUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       testcase.accessLevel,
+			}
+
+			repo.
+				EXPECT().
+				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), userUUID, codeSpace.Name).
+				Return(codeSpace, codeSpaceAccess, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
+				Times(1)
+
+			// values are revealed in place, so each run gets its own copies
+			codeSpaceEnvVars := make([]*code.CodeSpaceEnvVar, len(testcase.envVars))
+			for i, envVar := range testcase.envVars {
+				codeSpaceEnvVar := *envVar
+				codeSpaceEnvVar.CodeSpaceID = codeSpace.ID
+				codeSpaceEnvVars[i] = &codeSpaceEnvVar
+			}
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return(codeSpaceEnvVars, nil).
+				Times(1)
+
+			repo.
+				EXPECT().
+				CreateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					codeSpaceRun *code.CodeSpaceRun,
+				) (*code.CodeSpaceRun, error) {
+					createdCodeSpaceRun := *codeSpaceRun
+
+					return &createdCodeSpaceRun, nil
+				}).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				UpdateCodeSpaceRun(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ database.Querier,
+					codeSpaceRun *code.CodeSpaceRun,
+				) (*code.CodeSpaceRun, error) {
+					updatedCodeSpaceRun := *codeSpaceRun
+
+					return &updatedCodeSpaceRun, nil
+				}).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return([]*api.PistonRuntime{
+					{Language: api.PistonLanguageGo, Version: "1.16.2"},
+					{Language: api.PistonLanguageJavaScript, Version: "18.15.0"},
+					{Language: api.PistonLanguagePython, Version: "3.10.0"},
+				}, nil).
+				Times(1)
 
-			ctrl := gomock.NewController(t)
-			timeProvider := timekeeper.NewFrozenProvider()
-			crypto := cryptocoremocks.NewMockCrypto(ctrl)
-			mailClient := mailclientmocks.NewMockClient(ctrl)
-			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-			pistonClient := pistonmocks.NewMockClient(ctrl)
-			repo := code.NewRepository(timeProvider)
-			authRepo := auth.NewRepository(timeProvider)
+			pistonClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+					require.Equal(t, testcase.wantFiles, req.Files)
+
+					return &api.PistonExecuteResponse{}, nil
+				}).
+				MaxTimes(1)
 
 			svc := code.NewService(
 				cfg,
 				timeProvider,
-				TestDBPool,
+				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.codeSpaceUserUUID)
-			err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, testcase.codeSpaceUserUUID)
-			require.NoError(t, err)
-
-			dbConn, err := TestDBPool.Acquire(context.Background())
-			require.NoError(t, err)
-			defer dbConn.Release()
-
-			users, _, err := repo.ListUsersWithCodeSpaceAccess(context.Background(), dbConn, codeSpace.ID)
-			require.NoError(t, err)
+			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, userUUID)
+			_, err := svc.RunCodeSpace(ctx, codeSpace.Name, &code.RunCodeSpaceOptions{})
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
 
-			userUUIDs := make([]string, len(users))
-			for i, user := range users {
-				userUUIDs[i] = user.UUID
+				return
 			}
 
-			require.NotContains(t, userUUIDs, testcase.codeSpaceUserUUID)
+			require.NoError(t, err)
 		})
 	}
 }
-
-func TestServiceRemoveCodeSpaceUserFails(t *testing.T) {
+func TestServiceRunCodeSpaceTestsSuccess(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
@@ -2503,105 +11021,55 @@ func TestServiceRemoveCodeSpaceUserFails(t *testing.T) {
 		u.IsActive = true
 	})
 	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
-
-	editor1, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
+	passingTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
 		t,
-		editor1.UUID,
 		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
+		"Yello!",
+		api.CodeSpaceTestComparisonModeTrimmed,
+		false,
 	)
-
-	editor2, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
+	failingTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
 		t,
-		editor2.UUID,
 		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadWrite,
+		"Yello!",
+		api.CodeSpaceTestComparisonModeExact,
+		false,
 	)
-
-	viewer1, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-	testkitinternal.MustCreateCodeSpaceAccess(
+	hiddenTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
 		t,
-		viewer1.UUID,
 		codeSpace.ID,
-		code.CodeSpaceAccessLevelReadOnly,
+		`Y\w+!`,
+		api.CodeSpaceTestComparisonModeRegex,
+		true,
 	)
 
-	viewer2, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+	viewer, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
 		u.IsActive = true
 	})
 	testkitinternal.MustCreateCodeSpaceAccess(
 		t,
-		viewer2.UUID,
+		viewer.UUID,
 		codeSpace.ID,
 		code.CodeSpaceAccessLevelReadOnly,
 	)
 
-	thirdPartyUser, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
-		u.IsActive = true
-	})
-
 	testcases := map[string]struct {
-		userUUID          string
-		codeSpaceUserUUID string
-		wantErr           error
+		userUUID       string
+		wantIDs        []int64
+		wantPassed     []bool
+		wantDiffIsNils []bool
 	}{
-		"Author cannot remove editor access": {
-			userUUID:          author.UUID,
-			codeSpaceUserUUID: editor1.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Editor cannot remove author access": {
-			userUUID:          editor1.UUID,
-			codeSpaceUserUUID: author.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Editor cannot remove editor access": {
-			userUUID:          editor1.UUID,
-			codeSpaceUserUUID: editor2.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Viewer cannot remove author access": {
-			userUUID:          viewer1.UUID,
-			codeSpaceUserUUID: author.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Viewer cannot remove editor access": {
-			userUUID:          viewer1.UUID,
-			codeSpaceUserUUID: editor1.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Viewer cannot remove viewer access": {
-			userUUID:          viewer1.UUID,
-			codeSpaceUserUUID: viewer2.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessDenied,
-		},
-		"Third party user cannot remove author access": {
-			userUUID:          thirdPartyUser.UUID,
-			codeSpaceUserUUID: author.UUID,
-			wantErr:           errutils.ErrCodeSpaceNotFound,
-		},
-		"Third party user cannot remove editor access": {
-			userUUID:          thirdPartyUser.UUID,
-			codeSpaceUserUUID: editor1.UUID,
-			wantErr:           errutils.ErrCodeSpaceNotFound,
+		"Author runs all test cases": {
+			userUUID:       author.UUID,
+			wantIDs:        []int64{passingTestCase.ID, failingTestCase.ID, hiddenTestCase.ID},
+			wantPassed:     []bool{true, false, true},
+			wantDiffIsNils: []bool{true, false, true},
 		},
-		"Third party user cannot remove viewer access": {
-			userUUID:          thirdPartyUser.UUID,
-			codeSpaceUserUUID: viewer1.UUID,
-			wantErr:           errutils.ErrCodeSpaceNotFound,
-		},
-		"Third party user access not found": {
-			userUUID:          author.UUID,
-			codeSpaceUserUUID: thirdPartyUser.UUID,
-			wantErr:           errutils.ErrCodeSpaceAccessNotFound,
+		"Viewer only runs visible test cases": {
+			userUUID:       viewer.UUID,
+			wantIDs:        []int64{passingTestCase.ID, failingTestCase.ID},
+			wantPassed:     []bool{true, false},
+			wantDiffIsNils: []bool{true, false},
 		},
 	}
 
@@ -2609,101 +11077,127 @@ func TestServiceRemoveCodeSpaceUserFails(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			exitCode := 0
 			ctrl := gomock.NewController(t)
 			timeProvider := timekeeper.NewFrozenProvider()
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
-			pistonClient := pistonmocks.NewMockClient(ctrl)
+			pistonClient := piston.NewFakeClient(
+				piston.WithFakeClientRunResults(api.PistonResults{
+					Stdout: "Yello!\n",
+					Output: "Yello!\n",
+					Code:   &exitCode,
+				}),
+			)
 			repo := code.NewRepository(timeProvider)
 			authRepo := auth.NewRepository(timeProvider)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				TestDBPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
 			ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, testcase.userUUID)
-			err := svc.RemoveCodeSpaceUser(ctx, codeSpace.Name, testcase.codeSpaceUserUUID)
-			require.Error(t, err)
-			require.ErrorIs(t, err, testcase.wantErr)
+			codeSpaceTestResults, err := svc.RunCodeSpaceTests(ctx, codeSpace.Name)
+			require.NoError(t, err)
+			require.Len(t, codeSpaceTestResults, len(testcase.wantIDs))
+
+			for i, codeSpaceTestResult := range codeSpaceTestResults {
+				require.Equal(t, testcase.wantIDs[i], codeSpaceTestResult.TestCase.ID)
+				require.Equal(t, testcase.wantPassed[i], codeSpaceTestResult.Passed)
+				require.Equal(t, "Yello!\n", codeSpaceTestResult.Run.Stdout)
+				require.Equal(t, testcase.wantDiffIsNils[i], codeSpaceTestResult.Diff == nil)
+			}
+
+			dbConn, err := TestDBPool.Acquire(context.Background())
+			require.NoError(t, err)
+			defer dbConn.Release()
+
+			codeSpaceRuns, err := repo.ListCodeSpaceRuns(context.Background(), dbConn, codeSpace.ID, 10, 0)
+			require.NoError(t, err)
+			require.Empty(t, codeSpaceRuns)
 		})
 	}
 }
 
-func TestServiceRemoveCodeSpaceUserError(t *testing.T) {
+func TestServiceRunCodeSpaceTestsError(t *testing.T) {
 	t.Parallel()
 
 	cfg := testkitinternal.MustCreateConfig()
 
 	authorUUID := uuid.NewString()
-	viewerUUID := uuid.NewString()
-
-	authorRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed for author")
-	viewerRepoGetErr := errors.New("GetCodeSpaceWithAccessByName failed for viewer")
-	repoDeleteErr := errors.New("DeleteCodeSpaceAccess failed")
 
-	codeSpace := &code.CodeSpace{
-		ID:         42,
-		AuthorUUID: &authorUUID,
-		Name:       "habitable-slaking-volatile-granger-mov",
-		Language:   "python",
-		Contents:   "print('hello')",
-	}
-	authorCodeSpaceAccess := &code.CodeSpaceAccess{
-		ID:          314,
-		UserUUID:    authorUUID,
-		CodeSpaceID: codeSpace.ID,
-		Level:       code.CodeSpaceAccessLevelReadWrite,
-	}
-	viewerCodeSpaceAccess := &code.CodeSpaceAccess{
-		ID:          159,
-		UserUUID:    viewerUUID,
-		CodeSpaceID: codeSpace.ID,
-		Level:       code.CodeSpaceAccessLevelReadOnly,
-	}
+	genericGetCodeSpaceErr := errors.New("GetCodeSpaceWithAccessByName failed")
+	genericListTestCasesErr := errors.New("ListCodeSpaceTestCases failed")
+	genericRuntimesErr := errors.New("Runtimes failed")
+	genericPistonErr := errors.New("Execute failed")
 
 	testcases := map[string]struct {
 		ctx              context.Context
-		authorRepoGetErr error
-		viewerRepoGetErr error
-		repoDeleteErr    error
+		getCodeSpaceErr  error
+		listTestCasesErr error
+		runtimesErr      error
+		pistonErr        error
 		wantErr          error
 	}{
 		"No user UUID in context": {
 			ctx:              context.Background(),
-			authorRepoGetErr: nil,
-			viewerRepoGetErr: nil,
-			repoDeleteErr:    nil,
+			getCodeSpaceErr:  nil,
+			listTestCasesErr: nil,
+			runtimesErr:      nil,
+			pistonErr:        nil,
 			wantErr:          nil,
 		},
-		"GetCodeSpaceWithAccessByName fails for author": {
+		"Code space not found": {
 			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorRepoGetErr: authorRepoGetErr,
-			viewerRepoGetErr: nil,
-			repoDeleteErr:    nil,
-			wantErr:          authorRepoGetErr,
+			getCodeSpaceErr:  errutils.ErrDatabaseNoRowsReturned,
+			listTestCasesErr: nil,
+			runtimesErr:      nil,
+			pistonErr:        nil,
+			wantErr:          errutils.ErrCodeSpaceNotFound,
 		},
-		"GetCodeSpaceWithAccessByName fails for viwer": {
+		"GetCodeSpaceWithAccessByName fails": {
 			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorRepoGetErr: nil,
-			viewerRepoGetErr: viewerRepoGetErr,
-			repoDeleteErr:    nil,
-			wantErr:          viewerRepoGetErr,
+			getCodeSpaceErr:  genericGetCodeSpaceErr,
+			listTestCasesErr: nil,
+			runtimesErr:      nil,
+			pistonErr:        nil,
+			wantErr:          genericGetCodeSpaceErr,
 		},
-		"DeleteCodeSpaceAccess fails": {
+		"ListCodeSpaceTestCases fails": {
 			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
-			authorRepoGetErr: nil,
-			viewerRepoGetErr: nil,
-			repoDeleteErr:    repoDeleteErr,
-			wantErr:          repoDeleteErr,
+			getCodeSpaceErr:  nil,
+			listTestCasesErr: genericListTestCasesErr,
+			runtimesErr:      nil,
+			pistonErr:        nil,
+			wantErr:          genericListTestCasesErr,
+		},
+		"Runtimes fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			getCodeSpaceErr:  nil,
+			listTestCasesErr: nil,
+			runtimesErr:      genericRuntimesErr,
+			pistonErr:        nil,
+			wantErr:          genericRuntimesErr,
+		},
+		"Execute fails": {
+			ctx:              context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID),
+			getCodeSpaceErr:  nil,
+			listTestCasesErr: nil,
+			runtimesErr:      nil,
+			pistonErr:        genericPistonErr,
+			wantErr:          genericPistonErr,
 		},
 	}
 
@@ -2715,6 +11209,7 @@ func TestServiceRemoveCodeSpaceUserError(t *testing.T) {
 			timeProvider := timekeeper.NewFrozenProvider()
 			dbPool := databasemocks.NewMockPool(ctrl)
 			dbConn := databasemocks.NewMockConn(ctrl)
+			dbTx := databasemocks.NewMockTx(ctrl)
 			crypto := cryptocoremocks.NewMockCrypto(ctrl)
 			mailClient := mailclientmocks.NewMockClient(ctrl)
 			tmplManager := templatesmanagermocks.NewMockManager(ctrl)
@@ -2722,6 +11217,54 @@ func TestServiceRemoveCodeSpaceUserError(t *testing.T) {
 			repo := codemocks.NewMockRepository(ctrl)
 			authRepo := authmocks.NewMockRepository(ctrl)
 
+			authRepo.
+				EXPECT().
+				GetExecutionUsageSummary(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsageSummary{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				CreateExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&auth.ExecutionUsage{}, nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				UpdateExecutionUsageCPUTime(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				LockExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			authRepo.
+				EXPECT().
+				DeleteExecutionUsage(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			dbTx.
+				EXPECT().
+				Commit(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbTx.
+				EXPECT().
+				Rollback(gomock.Any()).
+				Return(nil).
+				MaxTimes(1)
+
+			dbConn.
+				EXPECT().
+				Begin(gomock.Any()).
+				Return(dbTx, nil).
+				MaxTimes(1)
+
 			dbConn.
 				EXPECT().
 				Release().
@@ -2733,37 +11276,86 @@ func TestServiceRemoveCodeSpaceUserError(t *testing.T) {
 				Return(dbConn, nil).
 				MaxTimes(1)
 
+			pistonClient.
+				EXPECT().
+				Runtimes(gomock.Any()).
+				Return(
+					[]*api.PistonRuntime{{Language: api.PistonLanguagePython, Version: "3.10.0"}},
+					testcase.runtimesErr,
+				).
+				MaxTimes(1)
+
+			pistonClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				Return(&api.PistonExecuteResponse{}, testcase.pistonErr).
+				MaxTimes(1)
+
+			codeSpace := &code.CodeSpace{
+				ID:         42,
+				AuthorUUID: &authorUUID,
+				Name:       "habitable-slaking-volatile-granger-mov",
+				Language:   "python",
+				Contents:   "print('hello')",
+			}
+			codeSpaceAccess := &code.CodeSpaceAccess{
+				ID:          314,
+				UserUUID:    authorUUID,
+				CodeSpaceID: codeSpace.ID,
+				Level:       code.CodeSpaceAccessLevelReadWrite,
+			}
+
 			repo.
 				EXPECT().
 				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), authorUUID, codeSpace.Name).
-				Return(codeSpace, authorCodeSpaceAccess, testcase.authorRepoGetErr).
+				Return(codeSpace, codeSpaceAccess, testcase.getCodeSpaceErr).
 				MaxTimes(1)
 
 			repo.
 				EXPECT().
-				GetCodeSpaceWithAccessByName(gomock.Any(), gomock.Any(), viewerUUID, codeSpace.Name).
-				Return(codeSpace, viewerCodeSpaceAccess, testcase.viewerRepoGetErr).
+				ListCodeSpaceTestCases(gomock.Any(), gomock.Any(), codeSpace.ID, true).
+				Return(
+					[]*code.CodeSpaceTestCase{
+						{
+							ID:             7,
+							CodeSpaceID:    codeSpace.ID,
+							Args:           []string{},
+							ExpectedStdout: "hello",
+							ComparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+						},
+					},
+					testcase.listTestCasesErr,
+				).
 				MaxTimes(1)
 
 			repo.
 				EXPECT().
-				DeleteCodeSpaceAccess(gomock.Any(), gomock.Any(), viewerUUID, codeSpace.ID).
-				Return(testcase.repoDeleteErr).
+				ListCodeSpaceFiles(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceFile{}, nil).
+				MaxTimes(1)
+
+			repo.
+				EXPECT().
+				ListCodeSpaceEnvVars(gomock.Any(), gomock.Any(), codeSpace.ID).
+				Return([]*code.CodeSpaceEnvVar{}, nil).
 				MaxTimes(1)
 
+			_, _, logger := testkit.CreateInMemLogger()
 			svc := code.NewService(
 				cfg,
 				timeProvider,
 				dbPool,
+				logger,
 				crypto,
 				mailClient,
 				tmplManager,
 				pistonClient,
+				nil,
 				repo,
 				authRepo,
 			)
 
-			err := svc.RemoveCodeSpaceUser(testcase.ctx, codeSpace.Name, viewerUUID)
+			_, err := svc.RunCodeSpaceTests(testcase.ctx, codeSpace.Name)
 			require.Error(t, err)
 
 			if testcase.wantErr != nil {
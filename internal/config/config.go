@@ -2,19 +2,51 @@ package config
 
 // Config represents config variables for the server.
 type Config struct {
-	Hostname             string `env:"NYMPHADORAAPI_HOSTNAME"`
-	Port                 int    `env:"NYMPHADORAAPI_PORT"`
-	SecretKey            string `env:"NYMPHADORAAPI_SECRET_KEY"`
-	FrontendBaseURL      string `env:"NYMPHADORAAPI_FRONTEND_BASE_URL"`
-	PostgresHostname     string `env:"NYMPHADORAAPI_POSTGRES_HOSTNAME"`
-	PostgresPort         int    `env:"NYMPHADORAAPI_POSTGRES_PORT"`
-	PostgresUsername     string `env:"NYMPHADORAAPI_POSTGRES_USERNAME"`
-	PostgresPassword     string `env:"NYMPHADORAAPI_POSTGRES_PASSWORD"`
-	PostgresDatabaseName string `env:"NYMPHADORAAPI_POSTGRES_DATABASE_NAME"`
-	SMTPHostname         string `env:"NYMPHADORAAPI_SMTP_HOSTNAME"`
-	SMTPPort             int    `env:"NYMPHADORAAPI_SMTP_PORT"`
-	SMTPUsername         string `env:"NYMPHADORAAPI_SMTP_USERNAME"`
-	SMTPPassword         string `env:"NYMPHADORAAPI_SMTP_PASSWORD"`
-	MailClientType       string `env:"NYMPHADORAAPI_MAIL_CLIENT_TYPE"`
-	PistonAPIKey         string `env:"NYMPHADORAAPI_PISTON_API_KEY"`
+	Hostname                            string  `env:"NYMPHADORAAPI_HOSTNAME"`
+	Port                                int     `env:"NYMPHADORAAPI_PORT"`
+	SecretKey                           string  `env:"NYMPHADORAAPI_SECRET_KEY"`
+	FrontendBaseURL                     string  `env:"NYMPHADORAAPI_FRONTEND_BASE_URL"`
+	PostgresHostname                    string  `env:"NYMPHADORAAPI_POSTGRES_HOSTNAME"`
+	PostgresPort                        int     `env:"NYMPHADORAAPI_POSTGRES_PORT"`
+	PostgresUsername                    string  `env:"NYMPHADORAAPI_POSTGRES_USERNAME"`
+	PostgresPassword                    string  `env:"NYMPHADORAAPI_POSTGRES_PASSWORD"`
+	PostgresDatabaseName                string  `env:"NYMPHADORAAPI_POSTGRES_DATABASE_NAME"`
+	SMTPHostname                        string  `env:"NYMPHADORAAPI_SMTP_HOSTNAME"`
+	SMTPPort                            int     `env:"NYMPHADORAAPI_SMTP_PORT"`
+	SMTPUsername                        string  `env:"NYMPHADORAAPI_SMTP_USERNAME"`
+	SMTPPassword                        string  `env:"NYMPHADORAAPI_SMTP_PASSWORD"`
+	MailClientType                      string  `env:"NYMPHADORAAPI_MAIL_CLIENT_TYPE"`
+	PistonClientType                    string  `env:"NYMPHADORAAPI_PISTON_CLIENT_TYPE"`
+	PistonBaseURL                       string  `env:"NYMPHADORAAPI_PISTON_BASE_URL"`
+	PistonAPIKey                        string  `env:"NYMPHADORAAPI_PISTON_API_KEY"`
+	PistonHTTPTimeoutSeconds            int     `env:"NYMPHADORAAPI_PISTON_HTTP_TIMEOUT_SECONDS"`
+	PistonRateLimit                     float64 `env:"NYMPHADORAAPI_PISTON_RATE_LIMIT"`
+	PistonRateLimitBurst                int     `env:"NYMPHADORAAPI_PISTON_RATE_LIMIT_BURST"`
+	PistonMaxConcurrency                int     `env:"NYMPHADORAAPI_PISTON_MAX_CONCURRENCY"`
+	PistonMaxQueueDepth                 int     `env:"NYMPHADORAAPI_PISTON_MAX_QUEUE_DEPTH"`
+	PistonMaxCompileTimeout             int64   `env:"NYMPHADORAAPI_PISTON_MAX_COMPILE_TIMEOUT"`
+	PistonMaxRunTimeout                 int64   `env:"NYMPHADORAAPI_PISTON_MAX_RUN_TIMEOUT"`
+	PistonMaxCompileMemoryLimit         int64   `env:"NYMPHADORAAPI_PISTON_MAX_COMPILE_MEMORY_LIMIT"`
+	PistonMaxRunMemoryLimit             int64   `env:"NYMPHADORAAPI_PISTON_MAX_RUN_MEMORY_LIMIT"`
+	PistonRuntimesRefreshSeconds        int     `env:"NYMPHADORAAPI_PISTON_RUNTIMES_REFRESH_SECONDS"`
+	PistonMaxRetries                    int     `env:"NYMPHADORAAPI_PISTON_MAX_RETRIES"`
+	PistonRetryBaseDelayMilliseconds    int     `env:"NYMPHADORAAPI_PISTON_RETRY_BASE_DELAY_MILLISECONDS"`
+	PistonRetryMaxDelayMilliseconds     int     `env:"NYMPHADORAAPI_PISTON_RETRY_MAX_DELAY_MILLISECONDS"`
+	PistonCircuitBreakerThreshold       int     `env:"NYMPHADORAAPI_PISTON_CIRCUIT_BREAKER_THRESHOLD"`
+	PistonCircuitBreakerCooldownSeconds int     `env:"NYMPHADORAAPI_PISTON_CIRCUIT_BREAKER_COOLDOWN_SECONDS"`
+	QuotaUserRunsPerMinute              int64   `env:"NYMPHADORAAPI_QUOTA_USER_RUNS_PER_MINUTE"`
+	QuotaUserRunsPerDay                 int64   `env:"NYMPHADORAAPI_QUOTA_USER_RUNS_PER_DAY"`
+	QuotaUserCPUSecondsPerDay           int64   `env:"NYMPHADORAAPI_QUOTA_USER_CPU_SECONDS_PER_DAY"`
+	QuotaAPIKeyRunsPerMinute            int64   `env:"NYMPHADORAAPI_QUOTA_API_KEY_RUNS_PER_MINUTE"`
+	QuotaAPIKeyRunsPerDay               int64   `env:"NYMPHADORAAPI_QUOTA_API_KEY_RUNS_PER_DAY"`
+	QuotaAPIKeyCPUSecondsPerDay         int64   `env:"NYMPHADORAAPI_QUOTA_API_KEY_CPU_SECONDS_PER_DAY"`
+	ExecutionCacheType                  string  `env:"NYMPHADORAAPI_EXECUTION_CACHE_TYPE"`
+	ExecutionCacheTTLSeconds            int     `env:"NYMPHADORAAPI_EXECUTION_CACHE_TTL_SECONDS"`
+	ExecutionCacheMaxEntries            int     `env:"NYMPHADORAAPI_EXECUTION_CACHE_MAX_ENTRIES"`
+	CodeSpaceTrashRetentionSeconds      int     `env:"NYMPHADORAAPI_CODE_SPACE_TRASH_RETENTION_SECONDS"`
+	CodeSpaceTrashPurgeIntervalSeconds  int     `env:"NYMPHADORAAPI_CODE_SPACE_TRASH_PURGE_INTERVAL_SECONDS"`
+	CodeSpaceHistoryRetentionSeconds    int     `env:"NYMPHADORAAPI_CODE_SPACE_HISTORY_RETENTION_SECONDS"`
+	CodeSpaceHistoryThinIntervalSeconds int     `env:"NYMPHADORAAPI_CODE_SPACE_HISTORY_THIN_INTERVAL_SECONDS"`
+	CodeSpaceCollabSaveIntervalSeconds  int     `env:"NYMPHADORAAPI_CODE_SPACE_COLLAB_SAVE_INTERVAL_SECONDS"`
+	CodeSpacePresenceTimeoutSeconds     int     `env:"NYMPHADORAAPI_CODE_SPACE_PRESENCE_TIMEOUT_SECONDS"`
 }
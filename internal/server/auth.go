@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/httputils"
@@ -218,6 +219,63 @@ func (ctrl *Controller) HandleGetUserMe(w *httputils.ResponseWriter, r *http.Req
 	)
 }
 
+// newExecutionQuotaUsageResponses converts execution quota usages into their response representation.
+func newExecutionQuotaUsageResponses(quotaUsages []*auth.ExecutionQuotaUsage) []*api.ExecutionQuotaUsageResponse {
+	resp := make([]*api.ExecutionQuotaUsageResponse, len(quotaUsages))
+	for i, quotaUsage := range quotaUsages {
+		var quotaMax *int64
+		if quotaUsage.Max > 0 {
+			quotaMax = &quotaUsage.Max
+		}
+
+		resp[i] = &api.ExecutionQuotaUsageResponse{
+			Limit:   quotaUsage.Limit,
+			Used:    quotaUsage.Used,
+			Max:     quotaMax,
+			ResetAt: quotaUsage.ResetAt,
+		}
+	}
+
+	return resp
+}
+
+// HandleGetUserUsage handles retrieval of execution quota consumption of currently authenticated user.
+// Methods: GET
+// URL: /auth/users/me/usage, /api/v1/auth/users/me/usage.
+func (ctrl *Controller) HandleGetUserUsage(w *httputils.ResponseWriter, r *http.Request) {
+	quotaUsages, apiKeyUsages, err := ctrl.authService.GetAuthenticatedUserUsage(r.Context())
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	apiKeys := make([]*api.APIKeyUsageResponse, len(apiKeyUsages))
+	for i, apiKeyUsage := range apiKeyUsages {
+		apiKeys[i] = &api.APIKeyUsageResponse{
+			ID:     apiKeyUsage.APIKey.ID,
+			Prefix: apiKeyUsage.APIKey.Prefix,
+			Name:   apiKeyUsage.APIKey.Name,
+			Quotas: newExecutionQuotaUsageResponses(apiKeyUsage.Quotas),
+		}
+	}
+
+	w.WriteJSON(
+		api.GetUserUsageResponse{
+			Quotas:  newExecutionQuotaUsageResponses(quotaUsages),
+			APIKeys: apiKeys,
+		},
+		http.StatusOK,
+	)
+}
+
 // HandleUpdateUser handles updating of currently authenticated user.
 // Methods: PATCH
 // URL: /auth/users/me.
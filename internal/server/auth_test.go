@@ -528,6 +528,100 @@ func TestHandleGetUserMe(t *testing.T) {
 	}
 }
 
+func TestHandleGetUserUsage(t *testing.T) {
+	t.Parallel()
+
+	httpClient := httputils.NewHTTPClient(nil)
+
+	user, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	userAccessJWT, _ := testkitinternal.MustCreateUserAuthJWTs(user.UUID)
+	apiKey, rawAPIKey := testkitinternal.MustCreateUserAPIKey(t, user.UUID, nil)
+
+	testcases := map[string]struct {
+		path           string
+		headers        map[string]string
+		wantStatusCode int
+		wantErrCode    string
+		wantErrDetail  string
+	}{
+		"Get usage using JWT": {
+			path: "/auth/users/me/usage",
+			headers: map[string]string{
+				"Authorization": fmt.Sprintf("Bearer %s", userAccessJWT),
+			},
+			wantStatusCode: http.StatusOK,
+			wantErrCode:    "",
+			wantErrDetail:  "",
+		},
+		"Get usage using API key": {
+			path: "/api/v1/auth/users/me/usage",
+			headers: map[string]string{
+				"Authorization": fmt.Sprintf("X-API-Key %s", rawAPIKey),
+			},
+			wantStatusCode: http.StatusOK,
+			wantErrCode:    "",
+			wantErrDetail:  "",
+		},
+		"Get usage without authentication": {
+			path:           "/auth/users/me/usage",
+			headers:        map[string]string{},
+			wantStatusCode: http.StatusUnauthorized,
+			wantErrCode:    api.ErrCodeMissingCredentials,
+			wantErrDetail:  api.ErrDetailMissingToken,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, TestServerURL+testcase.path, http.NoBody)
+			require.NoError(t, err)
+
+			for key, value := range testcase.headers {
+				req.Header.Add(key, value)
+			}
+
+			res, err := httpClient.Do(req)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				err := res.Body.Close()
+				require.NoError(t, err)
+			})
+
+			require.Equal(t, testcase.wantStatusCode, res.StatusCode)
+
+			if !httputils.IsHTTPSuccess(testcase.wantStatusCode) {
+				var errResp api.ErrorResponse
+				err = json.NewDecoder(res.Body).Decode(&errResp)
+				require.NoError(t, err)
+
+				require.Equal(t, testcase.wantErrCode, errResp.Code)
+				require.Equal(t, testcase.wantErrDetail, errResp.Detail)
+
+				return
+			}
+
+			var getUserUsageResp api.GetUserUsageResponse
+			err = json.NewDecoder(res.Body).Decode(&getUserUsageResp)
+			require.NoError(t, err)
+
+			require.Len(t, getUserUsageResp.Quotas, 3)
+			for _, quota := range getUserUsageResp.Quotas {
+				require.Zero(t, quota.Used)
+			}
+
+			require.Len(t, getUserUsageResp.APIKeys, 1)
+			require.Equal(t, apiKey.ID, getUserUsageResp.APIKeys[0].ID)
+			require.Equal(t, apiKey.Prefix, getUserUsageResp.APIKeys[0].Prefix)
+			require.Equal(t, apiKey.Name, getUserUsageResp.APIKeys[0].Name)
+			require.Len(t, getUserUsageResp.APIKeys[0].Quotas, 3)
+		})
+	}
+}
+
 func TestHandleUpdateUser(t *testing.T) {
 	t.Parallel()
 
@@ -1,34 +1,3088 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/code"
 	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/httputils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	"github.com/alvii147/nymphadora-api/pkg/websocket"
 )
 
-// CodeSpaceNameParamKey is the URL parameter used for code space name.
-const CodeSpaceNameParamKey = "name"
+const (
+	// CodeSpaceNameParamKey is the URL parameter used for code space name.
+	CodeSpaceNameParamKey = "name"
+	// CodeSpaceRunIDParamKey is the URL parameter used for code space run ID.
+	CodeSpaceRunIDParamKey = "id"
+	// CodeSpaceFileIDParamKey is the URL parameter used for code space file ID.
+	CodeSpaceFileIDParamKey = "id"
+	// CodeSpaceTestCaseIDParamKey is the URL parameter used for code space test case ID.
+	CodeSpaceTestCaseIDParamKey = "id"
+	// CodeSpaceRunConfigIDParamKey is the URL parameter used for code space run configuration ID.
+	CodeSpaceRunConfigIDParamKey = "id"
+	// CodeSpaceEnvVarIDParamKey is the URL parameter used for code space environment variable ID.
+	CodeSpaceEnvVarIDParamKey = "id"
+	// CodeSpaceRevisionIDParamKey is the URL parameter used for code space revision ID.
+	CodeSpaceRevisionIDParamKey = "id"
+	// ArgsQueryParamKey is the URL query parameter used for command-line arguments, repeated once per argument.
+	ArgsQueryParamKey = "args"
+	// CompileTimeoutQueryParamKey is the URL query parameter used for compilation timeouts.
+	CompileTimeoutQueryParamKey = "compile_timeout"
+	// RunTimeoutQueryParamKey is the URL query parameter used for runtime timeouts.
+	RunTimeoutQueryParamKey = "run_timeout"
+	// CompileMemoryLimitQueryParamKey is the URL query parameter used for compilation memory limits.
+	CompileMemoryLimitQueryParamKey = "compile_memory_limit"
+	// RunMemoryLimitQueryParamKey is the URL query parameter used for runtime memory limits.
+	RunMemoryLimitQueryParamKey = "run_memory_limit"
+	// LimitQueryParamKey is the URL query parameter used for the maximum number of results in a page.
+	LimitQueryParamKey = "limit"
+	// OffsetQueryParamKey is the URL query parameter used for the number of results to skip.
+	OffsetQueryParamKey = "offset"
+	// FromQueryParamKey is the URL query parameter used for the code space revision a diff starts from.
+	FromQueryParamKey = "from"
+	// ToQueryParamKey is the URL query parameter used for the code space revision a diff ends at.
+	ToQueryParamKey = "to"
+)
 
 // GetCodeSpaceNameParam extracts the code space name from the parameters of a request.
 func GetCodeSpaceNameParam(r *http.Request) string {
 	param := r.PathValue(CodeSpaceNameParamKey)
 
-	return param
+	return param
+}
+
+// GetCodeSpaceRunIDParam extracts the code space run ID from the parameters of a request.
+func GetCodeSpaceRunIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceRunIDParamKey)
+	codeSpaceRunID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceRunID, nil
+}
+
+// GetCodeSpaceFileIDParam extracts the code space file ID from the parameters of a request.
+func GetCodeSpaceFileIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceFileIDParamKey)
+	codeSpaceFileID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceFileID, nil
+}
+
+// GetCodeSpaceTestCaseIDParam extracts the code space test case ID from the parameters of a request.
+func GetCodeSpaceTestCaseIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceTestCaseIDParamKey)
+	codeSpaceTestCaseID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceTestCaseID, nil
+}
+
+// GetCodeSpaceRunConfigIDParam extracts the code space run configuration ID from the parameters of a request.
+func GetCodeSpaceRunConfigIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceRunConfigIDParamKey)
+	codeSpaceRunConfigID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceRunConfigID, nil
+}
+
+// GetCodeSpaceEnvVarIDParam extracts the code space environment variable ID from the parameters of a request.
+func GetCodeSpaceEnvVarIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceEnvVarIDParamKey)
+	codeSpaceEnvVarID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceEnvVarID, nil
+}
+
+// GetCodeSpaceRevisionIDParam extracts the code space revision ID from the parameters of a request.
+func GetCodeSpaceRevisionIDParam(r *http.Request) (int64, error) {
+	param := r.PathValue(CodeSpaceRevisionIDParamKey)
+	codeSpaceRevisionID, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for param %s", param)
+	}
+
+	return codeSpaceRevisionID, nil
+}
+
+// GetDiffCodeSpaceRevisionsQueryParams extracts the code space revisions to diff
+// from the query parameters of a request.
+func GetDiffCodeSpaceRevisionsQueryParams(r *http.Request) (*api.DiffCodeSpaceRevisionsRequest, error) {
+	query := r.URL.Query()
+	req := &api.DiffCodeSpaceRevisionsRequest{}
+
+	revisions := []struct {
+		key   string
+		value *int64
+	}{
+		{key: FromQueryParamKey, value: &req.From},
+		{key: ToQueryParamKey, value: &req.To},
+	}
+
+	for _, revision := range revisions {
+		param := query.Get(revision.key)
+		value, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, errutils.FormatErrorf(err, "strconv.ParseInt failed for query param %s", param)
+		}
+
+		*revision.value = value
+	}
+
+	return req, nil
+}
+
+// FormatCodeSpaceETag formats a given code space version as a strong entity tag.
+func FormatCodeSpaceETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// GetIfMatchHeader extracts the expected code space version from the If-Match header of a request.
+// It returns nil if the header is missing or matches any version.
+func GetIfMatchHeader(r *http.Request) (*int64, error) {
+	header := strings.TrimSpace(r.Header.Get(httputils.HTTPHeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	unquotedHeader, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		unquotedHeader, ok = strings.CutSuffix(unquotedHeader, `"`)
+	}
+
+	if !ok {
+		return nil, errutils.FormatErrorf(nil, "invalid entity tag %s", header)
+	}
+
+	version, err := strconv.ParseInt(unquotedHeader, 10, 64)
+	if err != nil {
+		return nil, errutils.FormatErrorf(err, "strconv.ParseInt failed for entity tag %s", header)
+	}
+
+	return &version, nil
+}
+
+// GetPaginationQueryParams extracts the limit and offset from the query parameters of a request.
+// The given default limit is used when no limit is provided.
+func GetPaginationQueryParams(r *http.Request, defaultLimit int64) (int64, int64, error) {
+	query := r.URL.Query()
+	limit := defaultLimit
+	offset := int64(0)
+
+	var err error
+	if query.Has(LimitQueryParamKey) {
+		param := query.Get(LimitQueryParamKey)
+		limit, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for query param %s", param)
+		}
+	}
+
+	if query.Has(OffsetQueryParamKey) {
+		param := query.Get(OffsetQueryParamKey)
+		offset, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, 0, errutils.FormatErrorf(err, "strconv.ParseInt failed for query param %s", param)
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// GetInteractiveCodeSpaceRunQueryParams extracts the execution options of interactive code space runs
+// from the query parameters of a request.
+func GetInteractiveCodeSpaceRunQueryParams(r *http.Request) (*api.InteractiveCodeSpaceRunRequest, error) {
+	query := r.URL.Query()
+	req := &api.InteractiveCodeSpaceRunRequest{
+		Args: query[ArgsQueryParamKey],
+	}
+
+	limits := []struct {
+		key   string
+		value **int64
+	}{
+		{key: CompileTimeoutQueryParamKey, value: &req.CompileTimeout},
+		{key: RunTimeoutQueryParamKey, value: &req.RunTimeout},
+		{key: CompileMemoryLimitQueryParamKey, value: &req.CompileMemoryLimit},
+		{key: RunMemoryLimitQueryParamKey, value: &req.RunMemoryLimit},
+	}
+
+	for _, limit := range limits {
+		if !query.Has(limit.key) {
+			continue
+		}
+
+		param := query.Get(limit.key)
+		value, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, errutils.FormatErrorf(err, "strconv.ParseInt failed for query param %s", param)
+		}
+
+		*limit.value = &value
+	}
+
+	return req, nil
+}
+
+// newCodeSpaceRunResultsResponses builds the compilation and runtime results responses of a code space run.
+// Results that have not been recorded are returned as nil.
+func newCodeSpaceRunResultsResponses(
+	codeSpaceRun *code.CodeSpaceRun,
+) (*api.RunCodeSpaceResultsResponse, *api.RunCodeSpaceResultsResponse) {
+	var compileResults *api.RunCodeSpaceResultsResponse
+	if codeSpaceRun.CompileStdout != nil && codeSpaceRun.CompileStderr != nil {
+		compileResults = &api.RunCodeSpaceResultsResponse{
+			Stdout:              *codeSpaceRun.CompileStdout,
+			Stderr:              *codeSpaceRun.CompileStderr,
+			Code:                codeSpaceRun.CompileCode,
+			Signal:              codeSpaceRun.CompileSignal,
+			Message:             codeSpaceRun.CompileMessage,
+			Status:              codeSpaceRun.CompileStatus,
+			CPUTime:             codeSpaceRun.CompileCPUTime,
+			WallTime:            codeSpaceRun.CompileWallTime,
+			Memory:              codeSpaceRun.CompileMemory,
+			TimedOut:            codeSpaceRun.CompileTimedOut,
+			MemoryLimitExceeded: codeSpaceRun.CompileMemoryLimitExceeded,
+		}
+	}
+
+	var runResults *api.RunCodeSpaceResultsResponse
+	if codeSpaceRun.RunStdout != nil && codeSpaceRun.RunStderr != nil {
+		runResults = &api.RunCodeSpaceResultsResponse{
+			Stdout:              *codeSpaceRun.RunStdout,
+			Stderr:              *codeSpaceRun.RunStderr,
+			Code:                codeSpaceRun.RunCode,
+			Signal:              codeSpaceRun.RunSignal,
+			Message:             codeSpaceRun.RunMessage,
+			Status:              codeSpaceRun.RunStatus,
+			CPUTime:             codeSpaceRun.RunCPUTime,
+			WallTime:            codeSpaceRun.RunWallTime,
+			Memory:              codeSpaceRun.RunMemory,
+			TimedOut:            codeSpaceRun.RunTimedOut,
+			MemoryLimitExceeded: codeSpaceRun.RunMemoryLimitExceeded,
+		}
+	}
+
+	return compileResults, runResults
+}
+
+// newGetCodeSpaceResponse builds the response for a given code space
+// as seen by a user with the given access to it.
+func newGetCodeSpaceResponse(
+	codeSpace *code.CodeSpace,
+	codeSpaceAccess *code.CodeSpaceAccess,
+) *api.GetCodeSpaceResponse {
+	return &api.GetCodeSpaceResponse{
+		ID:              codeSpace.ID,
+		AuthorUUID:      codeSpace.AuthorUUID,
+		Name:            codeSpace.Name,
+		Language:        codeSpace.Language,
+		LanguageVersion: codeSpace.LanguageVersion,
+		Contents:        codeSpace.Contents,
+		ForkedFromID:    codeSpace.ForkedFromID,
+		Version:         codeSpace.Version,
+		AccessLevel:     codeSpaceAccess.Level.String(),
+		CreatedAt:       codeSpace.CreatedAt,
+		UpdatedAt:       codeSpace.UpdatedAt,
+	}
+}
+
+// newGetCodeSpaceRunResponse builds the response body for a given code space run.
+func newGetCodeSpaceRunResponse(codeSpaceRun *code.CodeSpaceRun) *api.GetCodeSpaceRunResponse {
+	compileResults, runResults := newCodeSpaceRunResultsResponses(codeSpaceRun)
+
+	return &api.GetCodeSpaceRunResponse{
+		ID:          codeSpaceRun.ID,
+		CodeSpaceID: codeSpaceRun.CodeSpaceID,
+		UserUUID:    codeSpaceRun.UserUUID,
+		Contents:    codeSpaceRun.Contents,
+		Language:    codeSpaceRun.Language,
+		Version:     codeSpaceRun.Version,
+		Status:      codeSpaceRun.Status,
+		Compile:     compileResults,
+		Run:         runResults,
+		StartedAt:   codeSpaceRun.StartedAt,
+		FinishedAt:  codeSpaceRun.FinishedAt,
+		FromCache:   codeSpaceRun.FromCache,
+		CreatedAt:   codeSpaceRun.CreatedAt,
+		UpdatedAt:   codeSpaceRun.UpdatedAt,
+	}
+}
+
+// newRunCodeSpaceResponse builds the response body for a given finished code space run.
+func newRunCodeSpaceResponse(codeSpaceRun *code.CodeSpaceRun) *api.RunCodeSpaceResponse {
+	compileResults, runResults := newCodeSpaceRunResultsResponses(codeSpaceRun)
+	resp := &api.RunCodeSpaceResponse{
+		ID:        codeSpaceRun.ID,
+		Compile:   compileResults,
+		FromCache: codeSpaceRun.FromCache,
+	}
+
+	if runResults != nil {
+		resp.Run = *runResults
+	}
+
+	return resp
+}
+
+// newBenchmarkCodeSpaceStatsResponse builds the response body for given code space benchmark statistics.
+func newBenchmarkCodeSpaceStatsResponse(stats *code.CodeSpaceBenchmarkStats) *api.BenchmarkCodeSpaceStatsResponse {
+	if stats == nil {
+		return nil
+	}
+
+	return &api.BenchmarkCodeSpaceStatsResponse{
+		Min:    stats.Min,
+		Median: stats.Median,
+		P95:    stats.P95,
+		Max:    stats.Max,
+	}
+}
+
+// newBenchmarkCodeSpaceResponse builds the response body for a given code space benchmark.
+func newBenchmarkCodeSpaceResponse(benchmark *code.CodeSpaceBenchmark) *api.BenchmarkCodeSpaceResponse {
+	resp := &api.BenchmarkCodeSpaceResponse{
+		Iterations:   benchmark.Iterations,
+		StoppedEarly: benchmark.StoppedEarly,
+		WallTime:     newBenchmarkCodeSpaceStatsResponse(benchmark.WallTime),
+		CPUTime:      newBenchmarkCodeSpaceStatsResponse(benchmark.CPUTime),
+		PeakMemory:   benchmark.PeakMemory,
+		Runs:         make([]*api.RunCodeSpaceResponse, len(benchmark.Runs)),
+	}
+
+	for i, codeSpaceRun := range benchmark.Runs {
+		resp.Runs[i] = newRunCodeSpaceResponse(codeSpaceRun)
+	}
+
+	return resp
+}
+
+// newGetCodeSpaceTestCaseResponse builds the response body for a given code space test case.
+func newGetCodeSpaceTestCaseResponse(codeSpaceTestCase *code.CodeSpaceTestCase) *api.GetCodeSpaceTestCaseResponse {
+	return &api.GetCodeSpaceTestCaseResponse{
+		ID:             codeSpaceTestCase.ID,
+		CodeSpaceID:    codeSpaceTestCase.CodeSpaceID,
+		Stdin:          codeSpaceTestCase.Stdin,
+		Args:           codeSpaceTestCase.Args,
+		ExpectedStdout: codeSpaceTestCase.ExpectedStdout,
+		ComparisonMode: codeSpaceTestCase.ComparisonMode,
+		IsHidden:       codeSpaceTestCase.IsHidden,
+		CreatedAt:      codeSpaceTestCase.CreatedAt,
+		UpdatedAt:      codeSpaceTestCase.UpdatedAt,
+	}
+}
+
+// newGetCodeSpaceRunConfigResponse builds the response body for a given code space run configuration.
+func newGetCodeSpaceRunConfigResponse(
+	codeSpaceRunConfig *code.CodeSpaceRunConfig,
+) *api.GetCodeSpaceRunConfigResponse {
+	return &api.GetCodeSpaceRunConfigResponse{
+		ID:                 codeSpaceRunConfig.ID,
+		CodeSpaceID:        codeSpaceRunConfig.CodeSpaceID,
+		Name:               codeSpaceRunConfig.Name,
+		Stdin:              codeSpaceRunConfig.Stdin,
+		Args:               codeSpaceRunConfig.Args,
+		CompileTimeout:     codeSpaceRunConfig.CompileTimeout,
+		RunTimeout:         codeSpaceRunConfig.RunTimeout,
+		CompileMemoryLimit: codeSpaceRunConfig.CompileMemoryLimit,
+		RunMemoryLimit:     codeSpaceRunConfig.RunMemoryLimit,
+		LanguageVersion:    codeSpaceRunConfig.LanguageVersion,
+		CreatedAt:          codeSpaceRunConfig.CreatedAt,
+		UpdatedAt:          codeSpaceRunConfig.UpdatedAt,
+	}
+}
+
+// newGetCodeSpaceRevisionResponse builds the response body for a given code space revision.
+func newGetCodeSpaceRevisionResponse(codeSpaceRevision *code.CodeSpaceRevision) *api.GetCodeSpaceRevisionResponse {
+	return &api.GetCodeSpaceRevisionResponse{
+		ID:           codeSpaceRevision.ID,
+		CodeSpaceID:  codeSpaceRevision.CodeSpaceID,
+		AuthorUUID:   codeSpaceRevision.AuthorUUID,
+		Contents:     codeSpaceRevision.Contents,
+		ContentsHash: codeSpaceRevision.ContentsHash,
+		CreatedAt:    codeSpaceRevision.CreatedAt,
+	}
+}
+
+// newCodeSpaceEditOperation builds a code space edit operation from the components of a collaborative edit message.
+func newCodeSpaceEditOperation(components []*api.CodeSpaceEditComponent) code.EditOperation {
+	operation := make(code.EditOperation, len(components))
+	for i, component := range components {
+		switch {
+		case component.Retain != nil:
+			operation[i].Retain = int(*component.Retain)
+		case component.Insert != nil:
+			operation[i].Insert = *component.Insert
+		case component.Delete != nil:
+			operation[i].Delete = int(*component.Delete)
+		}
+	}
+
+	return operation
+}
+
+// newCodeSpaceEditComponentsResponse builds the components of a collaborative edit event
+// from a given code space edit operation.
+func newCodeSpaceEditComponentsResponse(operation code.EditOperation) []*api.CodeSpaceEditComponent {
+	components := make([]*api.CodeSpaceEditComponent, len(operation))
+	for i, component := range operation {
+		switch {
+		case component.Retain > 0:
+			retain := int64(component.Retain)
+			components[i] = &api.CodeSpaceEditComponent{Retain: &retain}
+		case component.Insert != "":
+			insert := component.Insert
+			components[i] = &api.CodeSpaceEditComponent{Insert: &insert}
+		default:
+			deleteLength := int64(component.Delete)
+			components[i] = &api.CodeSpaceEditComponent{Delete: &deleteLength}
+		}
+	}
+
+	return components
+}
+
+// newGetCodeSpaceCollaboratorResponse builds the response body for the presence of a given collaborator.
+func newGetCodeSpaceCollaboratorResponse(presence *code.CodeSpacePresence) *api.GetCodeSpaceCollaboratorResponse {
+	var cursor *api.CodeSpaceCursor
+	if presence.Cursor != nil {
+		cursor = &api.CodeSpaceCursor{
+			Position: int64(presence.Cursor.Position),
+			Anchor:   int64(presence.Cursor.Anchor),
+		}
+	}
+
+	return &api.GetCodeSpaceCollaboratorResponse{
+		SessionID:   presence.SessionID,
+		UserUUID:    presence.UserUUID,
+		FirstName:   presence.FirstName,
+		LastName:    presence.LastName,
+		AccessLevel: presence.AccessLevel.String(),
+		Cursor:      cursor,
+		JoinedAt:    presence.JoinedAt,
+		LastSeenAt:  presence.LastSeenAt,
+	}
+}
+
+// newCodeSpaceCollabEvent builds the message sent to clients for a given collaborative editing event.
+func newCodeSpaceCollabEvent(event *code.CodeSpaceCollabEvent) *api.CodeSpaceCollabEvent {
+	var data any
+	switch event.Type {
+	case api.CodeSpaceCollabEventTypeSnapshot:
+		collaborators := make([]*api.GetCodeSpaceCollaboratorResponse, len(event.Collaborators))
+		for i, presence := range event.Collaborators {
+			collaborators[i] = newGetCodeSpaceCollaboratorResponse(presence)
+		}
+
+		data = &api.CodeSpaceCollabSnapshotResponse{
+			Revision:      event.Revision,
+			Contents:      event.Contents,
+			Session:       newGetCodeSpaceCollaboratorResponse(event.Presence),
+			Collaborators: collaborators,
+		}
+	case api.CodeSpaceCollabEventTypeJoin, api.CodeSpaceCollabEventTypeLeave, api.CodeSpaceCollabEventTypeCursor:
+		data = newGetCodeSpaceCollaboratorResponse(event.Presence)
+	case api.CodeSpaceCollabEventTypeEdit:
+		data = &api.CodeSpaceCollabEditResponse{
+			Revision:  event.Revision,
+			Operation: newCodeSpaceEditComponentsResponse(event.Operation),
+			UserUUID:  event.UserUUID,
+		}
+	default:
+		data = &api.CodeSpaceCollabAckResponse{
+			Revision: event.Revision,
+		}
+	}
+
+	return &api.CodeSpaceCollabEvent{
+		Event: event.Type,
+		Data:  data,
+	}
+}
+
+// newRunCodeSpaceResultsResponse builds the results response of a single stage of a Piston execution.
+func newRunCodeSpaceResultsResponse(results *api.PistonResults) *api.RunCodeSpaceResultsResponse {
+	return &api.RunCodeSpaceResultsResponse{
+		Stdout:              results.Stdout,
+		Stderr:              results.Stderr,
+		Code:                results.Code,
+		Signal:              results.Signal,
+		Message:             results.Message,
+		Status:              results.Status,
+		CPUTime:             results.CPUTime,
+		WallTime:            results.WallTime,
+		Memory:              results.Memory,
+		TimedOut:            results.TimedOut(),
+		MemoryLimitExceeded: results.MemoryLimitExceeded(),
+	}
+}
+
+// newRunCodeSpaceTestsResponse builds the response body for given code space test results.
+func newRunCodeSpaceTestsResponse(codeSpaceTestResults []*code.CodeSpaceTestResult) *api.RunCodeSpaceTestsResponse {
+	resp := &api.RunCodeSpaceTestsResponse{
+		Results: make([]*api.RunCodeSpaceTestResultResponse, len(codeSpaceTestResults)),
+		Passed:  0,
+		Total:   len(codeSpaceTestResults),
+		Score:   0,
+	}
+
+	for i, codeSpaceTestResult := range codeSpaceTestResults {
+		var compileResults *api.RunCodeSpaceResultsResponse
+		if codeSpaceTestResult.Compile != nil {
+			compileResults = newRunCodeSpaceResultsResponse(codeSpaceTestResult.Compile)
+		}
+
+		resp.Results[i] = &api.RunCodeSpaceTestResultResponse{
+			TestCaseID: codeSpaceTestResult.TestCase.ID,
+			IsHidden:   codeSpaceTestResult.TestCase.IsHidden,
+			Passed:     codeSpaceTestResult.Passed,
+			Compile:    compileResults,
+			Run:        *newRunCodeSpaceResultsResponse(&codeSpaceTestResult.Run),
+			Diff:       codeSpaceTestResult.Diff,
+			FromCache:  codeSpaceTestResult.FromCache,
+		}
+
+		if codeSpaceTestResult.Passed {
+			resp.Passed++
+		}
+	}
+
+	if resp.Total > 0 {
+		resp.Score = float64(resp.Passed) / float64(resp.Total)
+	}
+
+	return resp
+}
+
+// writeRunCodeSpaceError writes the error response for a given code space run error.
+// The current time is used to compute how long to wait before retrying when an execution quota is exceeded.
+func writeRunCodeSpaceError(w *httputils.ResponseWriter, err error, now time.Time) {
+	var quotaErr *auth.ExecutionQuotaExceededError
+	var circuitErr *piston.CircuitOpenError
+
+	switch {
+	case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunConfigNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceRunConfigNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceRunLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceUnsupportedLanguage):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceLanguageUnsupported,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceUnsupportedVersion):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceVersionUnsupported,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceEnvVarsUnsupported):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceEnvVarsUnsupported,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionQueueFull):
+		w.Header().Set(
+			httputils.HTTPHeaderRetryAfter,
+			strconv.Itoa(int(piston.QueueFullRetryAfter.Seconds())),
+		)
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeTooManyRequests,
+				Detail: api.ErrDetailCodeExecutionBusy,
+			},
+			http.StatusTooManyRequests,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionRateLimited):
+		w.Header().Set(
+			httputils.HTTPHeaderRetryAfter,
+			strconv.Itoa(int(piston.RateLimitedRetryAfter.Seconds())),
+		)
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeUpstreamRateLimited,
+				Detail: api.ErrDetailCodeExecutionRateLimited,
+			},
+			http.StatusTooManyRequests,
+		)
+	case errors.As(err, &circuitErr):
+		w.Header().Set(
+			httputils.HTTPHeaderRetryAfter,
+			strconv.Itoa(max(1, int(math.Ceil(circuitErr.RetryAfter.Seconds())))),
+		)
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeUpstreamUnavailable,
+				Detail: api.ErrDetailCodeExecutionUnavailable,
+			},
+			http.StatusServiceUnavailable,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionUnavailable):
+		w.Header().Set(
+			httputils.HTTPHeaderRetryAfter,
+			strconv.Itoa(int(piston.UnavailableRetryAfter.Seconds())),
+		)
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeUpstreamUnavailable,
+				Detail: api.ErrDetailCodeExecutionUnavailable,
+			},
+			http.StatusServiceUnavailable,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionRejected):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeUpstreamRejected,
+				Detail: api.ErrDetailCodeExecutionRejected,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionRuntimeNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeRuntimeNotFound,
+				Detail: api.ErrDetailCodeExecutionRuntimeNotFound,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeExecutionNotInteractive):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeNotImplemented,
+				Detail: api.ErrDetailCodeExecutionNotInteractive,
+			},
+			http.StatusNotImplemented,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunCancelled):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeRequestCancelled,
+				Detail: api.ErrDetailCodeSpaceRunCancelled,
+			},
+			http.StatusRequestTimeout,
+		)
+	case errors.As(err, &quotaErr):
+		w.Header().Set(
+			httputils.HTTPHeaderRetryAfter,
+			strconv.Itoa(max(1, int(math.Ceil(quotaErr.ResetAt.Sub(now).Seconds())))),
+		)
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:    api.ErrCodeQuotaExceeded,
+				Detail:  api.ErrDetailExecutionQuotaExceeded,
+				ResetAt: &quotaErr.ResetAt,
+			},
+			http.StatusTooManyRequests,
+		)
+	default:
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// writeCodeSpaceFileError writes the error response for a given code space file error.
+func writeCodeSpaceFileError(w *httputils.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceFileNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceFileNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeAccessDenied,
+				Detail: api.ErrDetailCodeSpaceAccessDenied,
+			},
+			http.StatusForbidden,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceFileAlreadyExists):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceExists,
+				Detail: api.ErrDetailCodeSpaceFileExists,
+			},
+			http.StatusConflict,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceFileLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceFileLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	default:
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// writeCodeSpaceTestCaseError writes the error response for a given code space test case error.
+func writeCodeSpaceTestCaseError(w *httputils.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceTestCaseNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceTestCaseNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeAccessDenied,
+				Detail: api.ErrDetailCodeSpaceAccessDenied,
+			},
+			http.StatusForbidden,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceTestCaseLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceTestCaseLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceTestCaseInvalidPattern):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceTestCaseInvalidPattern,
+			},
+			http.StatusBadRequest,
+		)
+	default:
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// writeCodeSpaceRunConfigError writes the error response for a given code space run configuration error.
+func writeCodeSpaceRunConfigError(w *httputils.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunConfigNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceRunConfigNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeAccessDenied,
+				Detail: api.ErrDetailCodeSpaceAccessDenied,
+			},
+			http.StatusForbidden,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunConfigAlreadyExists):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceExists,
+				Detail: api.ErrDetailCodeSpaceRunConfigExists,
+			},
+			http.StatusConflict,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunConfigLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceRunConfigLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceRunLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceRunLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	default:
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// writeCodeSpaceEnvVarError writes the error response for a given code space environment variable error.
+func writeCodeSpaceEnvVarError(w *httputils.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceEnvVarNotFound):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceNotFound,
+				Detail: api.ErrDetailCodeSpaceEnvVarNotFound,
+			},
+			http.StatusNotFound,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeAccessDenied,
+				Detail: api.ErrDetailCodeSpaceAccessDenied,
+			},
+			http.StatusForbidden,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceEnvVarAlreadyExists):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeResourceExists,
+				Detail: api.ErrDetailCodeSpaceEnvVarExists,
+			},
+			http.StatusConflict,
+		)
+	case errors.Is(err, errutils.ErrCodeSpaceEnvVarLimitExceeded):
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailCodeSpaceEnvVarLimitExceeded,
+			},
+			http.StatusBadRequest,
+		)
+	default:
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// HandleListCodingLanguages handles retrieval of coding languages supported by the installed runtimes.
+// Methods: GET
+// URL: /code/languages.
+func (ctrl *Controller) HandleListCodingLanguages(w *httputils.ResponseWriter, r *http.Request) {
+	codingLanguages, err := ctrl.codeService.ListCodingLanguages(r.Context())
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	resp := &api.ListCodingLanguagesResponse{
+		Languages: make([]*api.GetCodingLanguageResponse, len(codingLanguages)),
+	}
+	for i, codingLanguage := range codingLanguages {
+		resp.Languages[i] = &api.GetCodingLanguageResponse{
+			Language: codingLanguage.Name,
+			Versions: codingLanguage.Versions,
+			Aliases:  codingLanguage.Aliases,
+		}
+	}
+
+	w.WriteJSON(resp, http.StatusOK)
+}
+
+// HandleCreateCodeSpace handles creation of new code spaces.
+// Methods: POST
+// URL: /code/space.
+func (ctrl *Controller) HandleCreateCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	var req api.CreateCodeSpaceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codingLanguages, err := ctrl.codeService.ListCodingLanguages(r.Context())
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	supportedLanguages := make([]string, 0, len(codingLanguages))
+	for _, codingLanguage := range codingLanguages {
+		supportedLanguages = append(supportedLanguages, codingLanguage.Name)
+		supportedLanguages = append(supportedLanguages, codingLanguage.Aliases...)
+	}
+
+	validationPassed, validationFailures := req.Validate(supportedLanguages)
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.CreateCodeSpace(
+		r.Context(),
+		req.Name,
+		req.Language,
+		req.LanguageVersion,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceAlreadyExists):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceExists,
+					Detail: api.ErrDetailCodeSpaceExists,
+				},
+				http.StatusConflict,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceUnsupportedLanguage):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailCodeSpaceLanguageUnsupported,
+				},
+				http.StatusBadRequest,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceUnsupportedVersion):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailCodeSpaceVersionUnsupported,
+				},
+				http.StatusBadRequest,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.WriteJSON(
+		api.CreateCodeSpaceResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			Version:         codeSpace.Version,
+			AccessLevel:     codeSpaceAccess.Level.String(),
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleListCodeSpaces handles retrieval of code spaces for currently authenticated user.
+// Methods: GET
+// URL: /code/space.
+func (ctrl *Controller) HandleListCodeSpaces(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaces, codeSpaceAccesses, err := ctrl.codeService.ListCodeSpaces(r.Context())
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	responseBody := api.ListCodeSpacesResponse{
+		CodeSpaces: make([]*api.GetCodeSpaceResponse, len(codeSpaces)),
+	}
+
+	for i, codeSpace := range codeSpaces {
+		responseBody.CodeSpaces[i] = newGetCodeSpaceResponse(codeSpace, codeSpaceAccesses[i])
+	}
+
+	w.WriteJSON(responseBody, http.StatusOK)
+}
+
+// HandleGetCodeSpace handles retrieval of a code space.
+// Methods: GET
+// URL: /code/space/{name}.
+func (ctrl *Controller) HandleGetCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.GetCodeSpace(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.Header().Set(httputils.HTTPHeaderETag, FormatCodeSpaceETag(codeSpace.Version))
+	w.WriteJSON(newGetCodeSpaceResponse(codeSpace, codeSpaceAccess), http.StatusOK)
+}
+
+// HandleUpdateCodeSpace handles updating of code spaces.
+// Methods: PATCH
+// URL: /code/space/{name}.
+func (ctrl *Controller) HandleUpdateCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.UpdateCodeSpaceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	version := req.Version
+	if version == nil {
+		version, err = GetIfMatchHeader(r)
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailInvalidRequestData,
+				},
+				http.StatusBadRequest,
+			)
+
+			return
+		}
+	}
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.UpdateCodeSpace(
+		r.Context(),
+		codeSpaceName,
+		req.Name,
+		req.Contents,
+		req.LanguageVersion,
+		version,
+	)
+	if err != nil {
+		var conflictErr *code.CodeSpaceVersionConflictError
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.As(err, &conflictErr):
+			w.Header().Set(httputils.HTTPHeaderETag, FormatCodeSpaceETag(conflictErr.CodeSpace.Version))
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:      api.ErrCodeVersionConflict,
+					Detail:    api.ErrDetailCodeSpaceVersionConflict,
+					CodeSpace: newGetCodeSpaceResponse(conflictErr.CodeSpace, conflictErr.CodeSpaceAccess),
+				},
+				http.StatusConflict,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceAlreadyExists):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceExists,
+					Detail: api.ErrDetailCodeSpaceExists,
+				},
+				http.StatusConflict,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeAccessDenied,
+					Detail: api.ErrDetailCodeSpaceAccessDenied,
+				},
+				http.StatusForbidden,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceUnsupportedVersion):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailCodeSpaceVersionUnsupported,
+				},
+				http.StatusBadRequest,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.Header().Set(httputils.HTTPHeaderETag, FormatCodeSpaceETag(codeSpace.Version))
+	w.WriteJSON(
+		api.UpdateCodeSpaceResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			Version:         codeSpace.Version,
+			AccessLevel:     codeSpaceAccess.Level.String(),
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleDeleteCodeSpace handles moving of code spaces to the trash.
+// Methods: DELETE
+// URL: /code/space/{name}.
+func (ctrl *Controller) HandleDeleteCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	err := ctrl.codeService.DeleteCodeSpace(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeAccessDenied,
+					Detail: api.ErrDetailCodeSpaceAccessDenied,
+				},
+				http.StatusForbidden,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.WriteJSON(nil, http.StatusNoContent)
+}
+
+// HandleListTrashedCodeSpaces handles retrieval of trashed code spaces the currently authenticated user can restore.
+// Methods: GET
+// URL: /code/trash.
+func (ctrl *Controller) HandleListTrashedCodeSpaces(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaces, codeSpaceAccesses, err := ctrl.codeService.ListTrashedCodeSpaces(r.Context())
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	responseBody := api.ListTrashedCodeSpacesResponse{
+		CodeSpaces: make([]*api.GetTrashedCodeSpaceResponse, len(codeSpaces)),
+	}
+
+	for i, codeSpace := range codeSpaces {
+		var deletedAt time.Time
+		if codeSpace.DeletedAt != nil {
+			deletedAt = *codeSpace.DeletedAt
+		}
+
+		responseBody.CodeSpaces[i] = &api.GetTrashedCodeSpaceResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			AccessLevel:     codeSpaceAccesses[i].Level.String(),
+			DeletedAt:       deletedAt,
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
+		}
+	}
+
+	w.WriteJSON(responseBody, http.StatusOK)
+}
+
+// HandleRestoreCodeSpace handles restoring of trashed code spaces.
+// Methods: POST
+// URL: /code/trash/{name}/restore.
+func (ctrl *Controller) HandleRestoreCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.RestoreCodeSpace(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeAccessDenied,
+					Detail: api.ErrDetailCodeSpaceAccessDenied,
+				},
+				http.StatusForbidden,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.WriteJSON(
+		api.RestoreCodeSpaceResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			Version:         codeSpace.Version,
+			AccessLevel:     codeSpaceAccess.Level.String(),
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleForkCodeSpace handles forking of code spaces.
+// Methods: POST
+// URL: /code/space/{name}/fork.
+func (ctrl *Controller) HandleForkCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.ForkCodeSpace(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.WriteJSON(
+		api.ForkCodeSpaceResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			ForkedFromID:    codeSpace.ForkedFromID,
+			Version:         codeSpace.Version,
+			AccessLevel:     codeSpaceAccess.Level.String(),
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleListCodeSpaceFiles handles retrieval of the files in a code space.
+// Methods: GET
+// URL: /code/space/{name}/files.
+func (ctrl *Controller) HandleListCodeSpaceFiles(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceFiles, err := ctrl.codeService.ListCodeSpaceFiles(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceFileError(w, err)
+
+		return
+	}
+
+	resp := &api.ListCodeSpaceFilesResponse{
+		Files: make([]*api.GetCodeSpaceFileResponse, len(codeSpaceFiles)),
+	}
+
+	for i, codeSpaceFile := range codeSpaceFiles {
+		resp.Files[i] = &api.GetCodeSpaceFileResponse{
+			ID:           codeSpaceFile.ID,
+			CodeSpaceID:  codeSpaceFile.CodeSpaceID,
+			Name:         codeSpaceFile.Name,
+			Contents:     codeSpaceFile.Contents,
+			IsEntryPoint: codeSpaceFile.IsEntryPoint,
+			CreatedAt:    codeSpaceFile.CreatedAt,
+			UpdatedAt:    codeSpaceFile.UpdatedAt,
+		}
+	}
+
+	w.WriteJSON(resp, http.StatusOK)
+}
+
+// HandleCreateCodeSpaceFile handles creation of files in code spaces.
+// Methods: POST
+// URL: /code/space/{name}/files.
+func (ctrl *Controller) HandleCreateCodeSpaceFile(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.CreateCodeSpaceFileRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceFile, err := ctrl.codeService.CreateCodeSpaceFile(
+		r.Context(),
+		codeSpaceName,
+		req.Name,
+		req.Contents,
+		req.IsEntryPoint,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceFileError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.CreateCodeSpaceFileResponse{
+			ID:           codeSpaceFile.ID,
+			CodeSpaceID:  codeSpaceFile.CodeSpaceID,
+			Name:         codeSpaceFile.Name,
+			Contents:     codeSpaceFile.Contents,
+			IsEntryPoint: codeSpaceFile.IsEntryPoint,
+			CreatedAt:    codeSpaceFile.CreatedAt,
+			UpdatedAt:    codeSpaceFile.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleUpdateCodeSpaceFile handles renaming, content updates, and entry point changes of files in code spaces.
+// Methods: PATCH
+// URL: /code/space/{name}/files/{id}.
+func (ctrl *Controller) HandleUpdateCodeSpaceFile(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceFileID, err := GetCodeSpaceFileIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	var req api.UpdateCodeSpaceFileRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceFile, err := ctrl.codeService.UpdateCodeSpaceFile(
+		r.Context(),
+		codeSpaceName,
+		codeSpaceFileID,
+		req.Name,
+		req.Contents,
+		req.IsEntryPoint,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceFileError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.UpdateCodeSpaceFileResponse{
+			ID:           codeSpaceFile.ID,
+			CodeSpaceID:  codeSpaceFile.CodeSpaceID,
+			Name:         codeSpaceFile.Name,
+			Contents:     codeSpaceFile.Contents,
+			IsEntryPoint: codeSpaceFile.IsEntryPoint,
+			CreatedAt:    codeSpaceFile.CreatedAt,
+			UpdatedAt:    codeSpaceFile.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleDeleteCodeSpaceFile handles deletion of files in code spaces.
+// Methods: DELETE
+// URL: /code/space/{name}/files/{id}.
+func (ctrl *Controller) HandleDeleteCodeSpaceFile(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceFileID, err := GetCodeSpaceFileIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	err = ctrl.codeService.DeleteCodeSpaceFile(r.Context(), codeSpaceName, codeSpaceFileID)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceFileError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(nil, http.StatusNoContent)
+}
+
+// HandleListCodeSpaceTestCases handles retrieval of the test cases in a code space.
+// Hidden test cases are only listed for users with write access.
+// Methods: GET
+// URL: /code/space/{name}/tests.
+func (ctrl *Controller) HandleListCodeSpaceTestCases(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceTestCases, err := ctrl.codeService.ListCodeSpaceTestCases(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceTestCaseError(w, err)
+
+		return
+	}
+
+	resp := &api.ListCodeSpaceTestCasesResponse{
+		TestCases: make([]*api.GetCodeSpaceTestCaseResponse, len(codeSpaceTestCases)),
+	}
+
+	for i, codeSpaceTestCase := range codeSpaceTestCases {
+		resp.TestCases[i] = newGetCodeSpaceTestCaseResponse(codeSpaceTestCase)
+	}
+
+	w.WriteJSON(resp, http.StatusOK)
+}
+
+// HandleCreateCodeSpaceTestCase handles creation of test cases in code spaces.
+// Methods: POST
+// URL: /code/space/{name}/tests.
+func (ctrl *Controller) HandleCreateCodeSpaceTestCase(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.CreateCodeSpaceTestCaseRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceTestCase, err := ctrl.codeService.CreateCodeSpaceTestCase(
+		r.Context(),
+		codeSpaceName,
+		req.Stdin,
+		req.Args,
+		req.ExpectedStdout,
+		req.ComparisonMode,
+		req.IsHidden,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceTestCaseError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.CreateCodeSpaceTestCaseResponse{
+			ID:             codeSpaceTestCase.ID,
+			CodeSpaceID:    codeSpaceTestCase.CodeSpaceID,
+			Stdin:          codeSpaceTestCase.Stdin,
+			Args:           codeSpaceTestCase.Args,
+			ExpectedStdout: codeSpaceTestCase.ExpectedStdout,
+			ComparisonMode: codeSpaceTestCase.ComparisonMode,
+			IsHidden:       codeSpaceTestCase.IsHidden,
+			CreatedAt:      codeSpaceTestCase.CreatedAt,
+			UpdatedAt:      codeSpaceTestCase.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleUpdateCodeSpaceTestCase handles updates to the inputs, expected output, comparison mode,
+// and visibility of test cases in code spaces.
+// Methods: PATCH
+// URL: /code/space/{name}/tests/{id}.
+func (ctrl *Controller) HandleUpdateCodeSpaceTestCase(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceTestCaseID, err := GetCodeSpaceTestCaseIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	var req api.UpdateCodeSpaceTestCaseRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceTestCase, err := ctrl.codeService.UpdateCodeSpaceTestCase(
+		r.Context(),
+		codeSpaceName,
+		codeSpaceTestCaseID,
+		req.Stdin,
+		req.Args,
+		req.ExpectedStdout,
+		req.ComparisonMode,
+		req.IsHidden,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceTestCaseError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.UpdateCodeSpaceTestCaseResponse{
+			ID:             codeSpaceTestCase.ID,
+			CodeSpaceID:    codeSpaceTestCase.CodeSpaceID,
+			Stdin:          codeSpaceTestCase.Stdin,
+			Args:           codeSpaceTestCase.Args,
+			ExpectedStdout: codeSpaceTestCase.ExpectedStdout,
+			ComparisonMode: codeSpaceTestCase.ComparisonMode,
+			IsHidden:       codeSpaceTestCase.IsHidden,
+			CreatedAt:      codeSpaceTestCase.CreatedAt,
+			UpdatedAt:      codeSpaceTestCase.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleDeleteCodeSpaceTestCase handles deletion of test cases in code spaces.
+// Methods: DELETE
+// URL: /code/space/{name}/tests/{id}.
+func (ctrl *Controller) HandleDeleteCodeSpaceTestCase(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceTestCaseID, err := GetCodeSpaceTestCaseIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	err = ctrl.codeService.DeleteCodeSpaceTestCase(r.Context(), codeSpaceName, codeSpaceTestCaseID)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceTestCaseError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(nil, http.StatusNoContent)
+}
+
+// HandleListCodeSpaceRunConfigs handles retrieval of run configurations in code spaces.
+// Methods: GET
+// URL: /code/space/{name}/run-configs, /api/v1/code/space/{name}/run-configs.
+func (ctrl *Controller) HandleListCodeSpaceRunConfigs(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceRunConfigs, err := ctrl.codeService.ListCodeSpaceRunConfigs(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceRunConfigError(w, err)
+
+		return
+	}
+
+	resp := &api.ListCodeSpaceRunConfigsResponse{
+		RunConfigs: make([]*api.GetCodeSpaceRunConfigResponse, len(codeSpaceRunConfigs)),
+	}
+
+	for i, codeSpaceRunConfig := range codeSpaceRunConfigs {
+		resp.RunConfigs[i] = newGetCodeSpaceRunConfigResponse(codeSpaceRunConfig)
+	}
+
+	w.WriteJSON(resp, http.StatusOK)
+}
+
+// HandleCreateCodeSpaceRunConfig handles creation of run configurations in code spaces.
+// Methods: POST
+// URL: /code/space/{name}/run-configs.
+func (ctrl *Controller) HandleCreateCodeSpaceRunConfig(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.CreateCodeSpaceRunConfigRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceRunConfig, err := ctrl.codeService.CreateCodeSpaceRunConfig(
+		r.Context(),
+		codeSpaceName,
+		req.Name,
+		req.Stdin,
+		req.Args,
+		req.CompileTimeout,
+		req.RunTimeout,
+		req.CompileMemoryLimit,
+		req.RunMemoryLimit,
+		req.LanguageVersion,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceRunConfigError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.CreateCodeSpaceRunConfigResponse{
+			ID:                 codeSpaceRunConfig.ID,
+			CodeSpaceID:        codeSpaceRunConfig.CodeSpaceID,
+			Name:               codeSpaceRunConfig.Name,
+			Stdin:              codeSpaceRunConfig.Stdin,
+			Args:               codeSpaceRunConfig.Args,
+			CompileTimeout:     codeSpaceRunConfig.CompileTimeout,
+			RunTimeout:         codeSpaceRunConfig.RunTimeout,
+			CompileMemoryLimit: codeSpaceRunConfig.CompileMemoryLimit,
+			RunMemoryLimit:     codeSpaceRunConfig.RunMemoryLimit,
+			LanguageVersion:    codeSpaceRunConfig.LanguageVersion,
+			CreatedAt:          codeSpaceRunConfig.CreatedAt,
+			UpdatedAt:          codeSpaceRunConfig.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleUpdateCodeSpaceRunConfig handles updates to the name, inputs, limits, and language version
+// of run configurations in code spaces.
+// Methods: PATCH
+// URL: /code/space/{name}/run-configs/{id}.
+func (ctrl *Controller) HandleUpdateCodeSpaceRunConfig(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceRunConfigID, err := GetCodeSpaceRunConfigIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	var req api.UpdateCodeSpaceRunConfigRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceRunConfig, err := ctrl.codeService.UpdateCodeSpaceRunConfig(
+		r.Context(),
+		codeSpaceName,
+		codeSpaceRunConfigID,
+		req.Name,
+		req.Stdin,
+		req.Args,
+		req.CompileTimeout,
+		req.RunTimeout,
+		req.CompileMemoryLimit,
+		req.RunMemoryLimit,
+		req.LanguageVersion,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceRunConfigError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.UpdateCodeSpaceRunConfigResponse{
+			ID:                 codeSpaceRunConfig.ID,
+			CodeSpaceID:        codeSpaceRunConfig.CodeSpaceID,
+			Name:               codeSpaceRunConfig.Name,
+			Stdin:              codeSpaceRunConfig.Stdin,
+			Args:               codeSpaceRunConfig.Args,
+			CompileTimeout:     codeSpaceRunConfig.CompileTimeout,
+			RunTimeout:         codeSpaceRunConfig.RunTimeout,
+			CompileMemoryLimit: codeSpaceRunConfig.CompileMemoryLimit,
+			RunMemoryLimit:     codeSpaceRunConfig.RunMemoryLimit,
+			LanguageVersion:    codeSpaceRunConfig.LanguageVersion,
+			CreatedAt:          codeSpaceRunConfig.CreatedAt,
+			UpdatedAt:          codeSpaceRunConfig.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleDeleteCodeSpaceRunConfig handles deletion of run configurations in code spaces.
+// Methods: DELETE
+// URL: /code/space/{name}/run-configs/{id}.
+func (ctrl *Controller) HandleDeleteCodeSpaceRunConfig(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceRunConfigID, err := GetCodeSpaceRunConfigIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	err = ctrl.codeService.DeleteCodeSpaceRunConfig(r.Context(), codeSpaceName, codeSpaceRunConfigID)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceRunConfigError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(nil, http.StatusNoContent)
+}
+
+// HandleListCodeSpaceEnvVars handles retrieval of environment variables in code spaces.
+// Values of secret environment variables are only returned to users with write access.
+// Methods: GET
+// URL: /code/space/{name}/env, /api/v1/code/space/{name}/env.
+func (ctrl *Controller) HandleListCodeSpaceEnvVars(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceEnvVars, err := ctrl.codeService.ListCodeSpaceEnvVars(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceEnvVarError(w, err)
+
+		return
+	}
+
+	resp := &api.ListCodeSpaceEnvVarsResponse{
+		EnvVars: make([]*api.GetCodeSpaceEnvVarResponse, len(codeSpaceEnvVars)),
+	}
+
+	for i, codeSpaceEnvVar := range codeSpaceEnvVars {
+		resp.EnvVars[i] = &api.GetCodeSpaceEnvVarResponse{
+			ID:          codeSpaceEnvVar.ID,
+			CodeSpaceID: codeSpaceEnvVar.CodeSpaceID,
+			Name:        codeSpaceEnvVar.Name,
+			Value:       codeSpaceEnvVar.Value,
+			IsSecret:    codeSpaceEnvVar.IsSecret,
+			CreatedAt:   codeSpaceEnvVar.CreatedAt,
+			UpdatedAt:   codeSpaceEnvVar.UpdatedAt,
+		}
+	}
+
+	w.WriteJSON(resp, http.StatusOK)
+}
+
+// HandleCreateCodeSpaceEnvVar handles creation of environment variables in code spaces.
+// Methods: POST
+// URL: /code/space/{name}/env.
+func (ctrl *Controller) HandleCreateCodeSpaceEnvVar(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.CreateCodeSpaceEnvVarRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceEnvVar, err := ctrl.codeService.CreateCodeSpaceEnvVar(
+		r.Context(),
+		codeSpaceName,
+		req.Name,
+		req.Value,
+		req.IsSecret,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceEnvVarError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.CreateCodeSpaceEnvVarResponse{
+			ID:          codeSpaceEnvVar.ID,
+			CodeSpaceID: codeSpaceEnvVar.CodeSpaceID,
+			Name:        codeSpaceEnvVar.Name,
+			Value:       codeSpaceEnvVar.Value,
+			IsSecret:    codeSpaceEnvVar.IsSecret,
+			CreatedAt:   codeSpaceEnvVar.CreatedAt,
+			UpdatedAt:   codeSpaceEnvVar.UpdatedAt,
+		},
+		http.StatusCreated,
+	)
+}
+
+// HandleUpdateCodeSpaceEnvVar handles updates to the name, value, and secrecy of environment variables
+// in code spaces.
+// Methods: PATCH
+// URL: /code/space/{name}/env/{id}.
+func (ctrl *Controller) HandleUpdateCodeSpaceEnvVar(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceEnvVarID, err := GetCodeSpaceEnvVarIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	var req api.UpdateCodeSpaceEnvVarRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceEnvVar, err := ctrl.codeService.UpdateCodeSpaceEnvVar(
+		r.Context(),
+		codeSpaceName,
+		codeSpaceEnvVarID,
+		req.Name,
+		req.Value,
+		req.IsSecret,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceEnvVarError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(
+		api.UpdateCodeSpaceEnvVarResponse{
+			ID:          codeSpaceEnvVar.ID,
+			CodeSpaceID: codeSpaceEnvVar.CodeSpaceID,
+			Name:        codeSpaceEnvVar.Name,
+			Value:       codeSpaceEnvVar.Value,
+			IsSecret:    codeSpaceEnvVar.IsSecret,
+			CreatedAt:   codeSpaceEnvVar.CreatedAt,
+			UpdatedAt:   codeSpaceEnvVar.UpdatedAt,
+		},
+		http.StatusOK,
+	)
+}
+
+// HandleDeleteCodeSpaceEnvVar handles deletion of environment variables in code spaces.
+// Methods: DELETE
+// URL: /code/space/{name}/env/{id}.
+func (ctrl *Controller) HandleDeleteCodeSpaceEnvVar(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+	codeSpaceEnvVarID, err := GetCodeSpaceEnvVarIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	err = ctrl.codeService.DeleteCodeSpaceEnvVar(r.Context(), codeSpaceName, codeSpaceEnvVarID)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeCodeSpaceEnvVarError(w, err)
+
+		return
+	}
+
+	w.WriteJSON(nil, http.StatusNoContent)
+}
+
+// HandleRunCodeSpaceTests handles running and grading of code spaces against their test cases.
+// Hidden test cases are only run for users with write access.
+// Methods: POST
+// URL: /code/space/{name}/test, /api/v1/code/space/{name}/test.
+func (ctrl *Controller) HandleRunCodeSpaceTests(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceTestResults, err := ctrl.codeService.RunCodeSpaceTests(r.Context(), codeSpaceName)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+		return
+	}
+
+	w.WriteJSON(newRunCodeSpaceTestsResponse(codeSpaceTestResults), http.StatusOK)
+}
+
+// HandleRunCodeSpace handles running of code spaces.
+// Methods: POST
+// URL: /code/space/{name}/run, /api/v1/code/space/{name}/run.
+func (ctrl *Controller) HandleRunCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.RunCodeSpaceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	opts := &code.RunCodeSpaceOptions{
+		Stdin:              req.Stdin,
+		Args:               req.Args,
+		CompileTimeout:     req.CompileTimeout,
+		RunTimeout:         req.RunTimeout,
+		CompileMemoryLimit: req.CompileMemoryLimit,
+		RunMemoryLimit:     req.RunMemoryLimit,
+		NoCache:            req.NoCache,
+		RunConfig:          req.RunConfig,
+	}
+
+	var codeSpaceRun *code.CodeSpaceRun
+	if req.Async {
+		codeSpaceRun, err = ctrl.codeService.StartCodeSpaceRun(r.Context(), codeSpaceName, opts)
+	} else {
+		codeSpaceRun, err = ctrl.codeService.RunCodeSpace(r.Context(), codeSpaceName, opts)
+	}
+
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+		return
+	}
+
+	if req.Async {
+		w.WriteJSON(
+			api.StartCodeSpaceRunResponse{
+				ID:     codeSpaceRun.ID,
+				Status: codeSpaceRun.Status,
+			},
+			http.StatusAccepted,
+		)
+
+		return
+	}
+
+	w.WriteJSON(newRunCodeSpaceResponse(codeSpaceRun), http.StatusOK)
+}
+
+// HandleRunCodeSpaceMatrix handles running of code spaces on several language versions.
+// Methods: POST
+// URL: /code/space/{name}/run/matrix, /api/v1/code/space/{name}/run/matrix.
+func (ctrl *Controller) HandleRunCodeSpaceMatrix(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.RunCodeSpaceMatrixRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	opts := &code.RunCodeSpaceOptions{
+		Stdin:              req.Stdin,
+		Args:               req.Args,
+		CompileTimeout:     req.CompileTimeout,
+		RunTimeout:         req.RunTimeout,
+		CompileMemoryLimit: req.CompileMemoryLimit,
+		RunMemoryLimit:     req.RunMemoryLimit,
+		NoCache:            req.NoCache,
+	}
+
+	codeSpaceRuns, err := ctrl.codeService.RunCodeSpaceMatrix(r.Context(), codeSpaceName, req.Versions, opts)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+		return
+	}
+
+	responseBody := api.RunCodeSpaceMatrixResponse{
+		Results: make(map[string]*api.RunCodeSpaceResponse, len(codeSpaceRuns)),
+	}
+
+	for i, codeSpaceRun := range codeSpaceRuns {
+		responseBody.Results[req.Versions[i]] = newRunCodeSpaceResponse(codeSpaceRun)
+	}
+
+	w.WriteJSON(responseBody, http.StatusOK)
+}
+
+// HandleBenchmarkCodeSpace handles running of code spaces several times to measure their resource usage.
+// Methods: POST
+// URL: /code/space/{name}/benchmark, /api/v1/code/space/{name}/benchmark.
+func (ctrl *Controller) HandleBenchmarkCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.BenchmarkCodeSpaceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	opts := &code.RunCodeSpaceOptions{
+		Stdin:              req.Stdin,
+		Args:               req.Args,
+		CompileTimeout:     req.CompileTimeout,
+		RunTimeout:         req.RunTimeout,
+		CompileMemoryLimit: req.CompileMemoryLimit,
+		RunMemoryLimit:     req.RunMemoryLimit,
+	}
+
+	benchmark, err := ctrl.codeService.BenchmarkCodeSpace(r.Context(), codeSpaceName, req.Iterations, opts)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+		return
+	}
+
+	w.WriteJSON(newBenchmarkCodeSpaceResponse(benchmark), http.StatusOK)
+}
+
+// HandleStreamCodeSpaceRun handles running of code spaces with progress streamed as Server-Sent Events.
+// Methods: POST
+// URL: /code/space/{name}/run/stream, /api/v1/code/space/{name}/run/stream.
+func (ctrl *Controller) HandleStreamCodeSpaceRun(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	var req api.RunCodeSpaceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	opts := &code.RunCodeSpaceOptions{
+		Stdin:              req.Stdin,
+		Args:               req.Args,
+		CompileTimeout:     req.CompileTimeout,
+		RunTimeout:         req.RunTimeout,
+		CompileMemoryLimit: req.CompileMemoryLimit,
+		RunMemoryLimit:     req.RunMemoryLimit,
+		NoCache:            req.NoCache,
+		RunConfig:          req.RunConfig,
+	}
+
+	// the event stream is only opened once the first event is emitted,
+	// so that errors before that can still be reported with the appropriate status code
+	streaming := false
+	writeEvent := func(event string, data any) {
+		if !streaming {
+			w.WriteEventHeaders()
+			streaming = true
+		}
+
+		err := w.WriteEvent(event, data)
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+		}
+	}
+
+	codeSpaceRun, err := ctrl.codeService.StreamCodeSpaceRun(
+		r.Context(),
+		codeSpaceName,
+		opts,
+		func(event *api.PistonEvent) {
+			writeEvent(event.Type, event)
+		},
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		if !streaming {
+			writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+			return
+		}
+
+		writeEvent(
+			api.CodeSpaceRunEventTypeError,
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+		)
+
+		return
+	}
+
+	writeEvent(api.CodeSpaceRunEventTypeExit, newRunCodeSpaceResponse(codeSpaceRun))
+}
+
+// HandleInteractiveCodeSpaceRun handles running of code spaces interactively over a WebSocket connection.
+// Clients send standard input and signals as InteractiveCodeSpaceRunMessage,
+// and receive progress events and output as InteractiveCodeSpaceRunEvent.
+// Methods: GET
+// URL: /code/space/{name}/run/interactive, /api/v1/code/space/{name}/run/interactive.
+func (ctrl *Controller) HandleInteractiveCodeSpaceRun(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	req, err := GetInteractiveCodeSpaceRunQueryParams(r)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	if !websocket.IsUpgradeRequest(r) {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "not a websocket upgrade request"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailWebSocketUpgradeRequired,
+			},
+			http.StatusUpgradeRequired,
+		)
+
+		return
+	}
+
+	opts := &code.RunCodeSpaceOptions{
+		Args:               req.Args,
+		CompileTimeout:     req.CompileTimeout,
+		RunTimeout:         req.RunTimeout,
+		CompileMemoryLimit: req.CompileMemoryLimit,
+		RunMemoryLimit:     req.RunMemoryLimit,
+	}
+
+	// the session is cancelled if the client goes away, so that the run is recorded as cancelled
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// the connection is only upgraded once the session has started,
+	// so that errors before that can still be reported with the appropriate status code
+	var conn *websocket.Conn
+	readerDone := make(chan struct{})
+	codeSpaceRun, err := ctrl.codeService.RunCodeSpaceInteractively(
+		ctx,
+		codeSpaceName,
+		opts,
+		func(session piston.Session) error {
+			var err error
+			conn, err = websocket.Upgrade(w, r)
+			if err != nil {
+				return errutils.FormatError(err)
+			}
+
+			go func() {
+				defer close(readerDone)
+				ctrl.forwardInteractiveCodeSpaceRunMessages(conn, session, cancel)
+			}()
+
+			return ctrl.forwardInteractiveCodeSpaceRunEvents(conn, session, cancel)
+		},
+	)
+	if conn == nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		writeRunCodeSpaceError(w, err, ctrl.timeProvider.Now())
+
+		return
+	}
+
+	defer func() {
+		conn.Close()
+		<-readerDone
+	}()
+
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		ctrl.writeInteractiveCodeSpaceRunEvent(
+			conn,
+			api.CodeSpaceRunEventTypeError,
+			api.ErrorResponse{
+				Code:   api.ErrCodeInternalServerError,
+				Detail: api.ErrDetailInternalServerError,
+			},
+		)
+		_ = conn.WriteClose(websocket.CloseInternalServerError, "")
+
+		return
+	}
+
+	ctrl.writeInteractiveCodeSpaceRunEvent(conn, api.CodeSpaceRunEventTypeExit, newRunCodeSpaceResponse(codeSpaceRun))
+	_ = conn.WriteClose(websocket.CloseNormalClosure, "")
+}
+
+// forwardInteractiveCodeSpaceRunEvents forwards progress events from an interactive session to a client connection
+// until the session finishes.
+// The session is cancelled using cancel if the client cannot be written to.
+func (ctrl *Controller) forwardInteractiveCodeSpaceRunEvents(
+	conn *websocket.Conn,
+	session piston.Session,
+	cancel context.CancelFunc,
+) error {
+	for {
+		event, err := session.Receive()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errutils.FormatError(err)
+		}
+
+		err = conn.WriteJSON(&api.InteractiveCodeSpaceRunEvent{
+			Event: event.Type,
+			Data:  event,
+		})
+		if err != nil {
+			cancel()
+
+			return errutils.FormatError(err)
+		}
+	}
+}
+
+// forwardInteractiveCodeSpaceRunMessages forwards standard input and signals from a client connection
+// to an interactive session until the connection is closed.
+// Invalid messages are answered with an error event and otherwise ignored.
+// The session is cancelled using cancel once the connection is closed.
+func (ctrl *Controller) forwardInteractiveCodeSpaceRunMessages(
+	conn *websocket.Conn,
+	session piston.Session,
+	cancel context.CancelFunc,
+) {
+	defer cancel()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg api.InteractiveCodeSpaceRunMessage
+		err = json.Unmarshal(message, &msg)
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err, "json.Unmarshal failed"))
+			ctrl.writeInteractiveCodeSpaceRunEvent(
+				conn,
+				api.CodeSpaceRunEventTypeError,
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailInvalidRequestData,
+				},
+			)
+
+			continue
+		}
+
+		validationPassed, validationFailures := msg.Validate()
+		if !validationPassed {
+			ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+			ctrl.writeInteractiveCodeSpaceRunEvent(
+				conn,
+				api.CodeSpaceRunEventTypeError,
+				api.ErrorResponse{
+					Code:               api.ErrCodeInvalidRequest,
+					Detail:             api.ErrDetailInvalidRequestData,
+					ValidationFailures: validationFailures,
+				},
+			)
+
+			continue
+		}
+
+		if msg.Type == api.InteractiveCodeSpaceRunMessageTypeSignal {
+			err = session.Signal(*msg.Signal)
+		} else {
+			err = session.WriteStdin(*msg.Data)
+		}
+
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+		}
+	}
+}
+
+// writeInteractiveCodeSpaceRunEvent writes an event with given data to a client connection.
+// Failures are logged rather than returned, since the client may already have gone away.
+func (ctrl *Controller) writeInteractiveCodeSpaceRunEvent(conn *websocket.Conn, event string, data any) {
+	err := conn.WriteJSON(&api.InteractiveCodeSpaceRunEvent{
+		Event: event,
+		Data:  data,
+	})
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err))
+	}
+}
+
+// HandleListCodeSpaceRuns handles retrieval of the run history of a code space.
+// Methods: GET
+// URL: /code/space/{name}/runs, /api/v1/code/space/{name}/runs.
+func (ctrl *Controller) HandleListCodeSpaceRuns(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	limit, offset, err := GetPaginationQueryParams(r, api.ListCodeSpaceRunsDefaultLimit)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	req := api.ListCodeSpaceRunsRequest{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceRuns, err := ctrl.codeService.ListCodeSpaceRuns(r.Context(), codeSpaceName, req.Limit, req.Offset)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	responseBody := api.ListCodeSpaceRunsResponse{
+		Runs:   make([]*api.GetCodeSpaceRunResponse, len(codeSpaceRuns)),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	for i, codeSpaceRun := range codeSpaceRuns {
+		responseBody.Runs[i] = newGetCodeSpaceRunResponse(codeSpaceRun)
+	}
+
+	w.WriteJSON(responseBody, http.StatusOK)
+}
+
+// HandleGetCodeSpaceRun handles retrieval of a code space run.
+// Methods: GET
+// URL: /code/space/{name}/runs/{id}, /api/v1/code/space/{name}/runs/{id}.
+func (ctrl *Controller) HandleGetCodeSpaceRun(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceRunID, err := GetCodeSpaceRunIDParam(r)
+	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceRun, err := ctrl.codeService.GetCodeSpaceRun(r.Context(), codeSpaceName, codeSpaceRunID)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceRunNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceRunNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	w.WriteJSON(newGetCodeSpaceRunResponse(codeSpaceRun), http.StatusOK)
+}
+
+// HandleListCodeSpaceRevisions handles retrieval of the revision history of a code space.
+// Revisions only cover the main file of a code space, so changes to its other files are not listed.
+// Methods: GET
+// URL: /code/space/{name}/revisions.
+func (ctrl *Controller) HandleListCodeSpaceRevisions(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	limit, offset, err := GetPaginationQueryParams(r, api.ListCodeSpaceRevisionsDefaultLimit)
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	req := api.ListCodeSpaceRevisionsRequest{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	codeSpaceRevisions, err := ctrl.codeService.ListCodeSpaceRevisions(
+		r.Context(),
+		codeSpaceName,
+		req.Limit,
+		req.Offset,
+	)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
+
+		return
+	}
+
+	responseBody := api.ListCodeSpaceRevisionsResponse{
+		Revisions: make([]*api.GetCodeSpaceRevisionResponse, len(codeSpaceRevisions)),
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	}
+
+	for i, codeSpaceRevision := range codeSpaceRevisions {
+		responseBody.Revisions[i] = newGetCodeSpaceRevisionResponse(codeSpaceRevision)
+	}
+
+	w.WriteJSON(responseBody, http.StatusOK)
 }
 
-// HandleCreateCodeSpace handles creation of new code spaces.
-// Methods: POST
-// URL: /code/space.
-func (ctrl *Controller) HandleCreateCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
-	var req api.CreateCodeSpaceRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
+// HandleGetCodeSpaceRevision handles retrieval of a code space revision.
+// Methods: GET
+// URL: /code/space/{name}/revisions/{id}.
+func (ctrl *Controller) HandleGetCodeSpaceRevision(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	codeSpaceRevisionID, err := GetCodeSpaceRevisionIDParam(r)
 	if err != nil {
-		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
 		w.WriteJSON(
 			api.ErrorResponse{
 				Code:   api.ErrCodeInvalidRequest,
@@ -40,95 +3094,78 @@ func (ctrl *Controller) HandleCreateCodeSpace(w *httputils.ResponseWriter, r *ht
 		return
 	}
 
-	validationPassed, validationFailures := req.Validate()
-	if !validationPassed {
-		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
-		w.WriteJSON(
-			api.ErrorResponse{
-				Code:               api.ErrCodeInvalidRequest,
-				Detail:             api.ErrDetailInvalidRequestData,
-				ValidationFailures: validationFailures,
-			},
-			http.StatusBadRequest,
-		)
-
-		return
-	}
-
-	codeSpace, codeSpaceAccess, err := ctrl.codeService.CreateCodeSpace(r.Context(), req.Language)
+	codeSpaceRevision, err := ctrl.codeService.GetCodeSpaceRevision(r.Context(), codeSpaceName, codeSpaceRevisionID)
 	if err != nil {
 		ctrl.logger.LogError(errutils.FormatError(err))
-		w.WriteJSON(
-			api.ErrorResponse{
-				Code:   api.ErrCodeInternalServerError,
-				Detail: api.ErrDetailInternalServerError,
-			},
-			http.StatusInternalServerError,
-		)
+		switch {
+		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceNotFound,
+				},
+				http.StatusNotFound,
+			)
+		case errors.Is(err, errutils.ErrCodeSpaceRevisionNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceRevisionNotFound,
+				},
+				http.StatusNotFound,
+			)
+		default:
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInternalServerError,
+					Detail: api.ErrDetailInternalServerError,
+				},
+				http.StatusInternalServerError,
+			)
+		}
 
 		return
 	}
 
-	w.WriteJSON(
-		api.CreateCodeSpaceResponse{
-			ID:          codeSpace.ID,
-			AuthorUUID:  codeSpace.AuthorUUID,
-			Name:        codeSpace.Name,
-			Language:    codeSpace.Language,
-			Contents:    codeSpace.Contents,
-			AccessLevel: codeSpaceAccess.Level.String(),
-			CreatedAt:   codeSpace.CreatedAt,
-			UpdatedAt:   codeSpace.UpdatedAt,
-		},
-		http.StatusCreated,
-	)
+	w.WriteJSON(newGetCodeSpaceRevisionResponse(codeSpaceRevision), http.StatusOK)
 }
 
-// HandleListCodeSpaces handles retrieval of code spaces for currently authenticated user.
+// HandleDiffCodeSpaceRevisions handles computing the unified diff between two code space revisions.
 // Methods: GET
-// URL: /code/space.
-func (ctrl *Controller) HandleListCodeSpaces(w *httputils.ResponseWriter, r *http.Request) {
-	codeSpaces, codeSpaceAccesses, err := ctrl.codeService.ListCodeSpaces(r.Context())
+// URL: /code/space/{name}/revisions/diff.
+func (ctrl *Controller) HandleDiffCodeSpaceRevisions(w *httputils.ResponseWriter, r *http.Request) {
+	codeSpaceName := GetCodeSpaceNameParam(r)
+
+	req, err := GetDiffCodeSpaceRevisionsQueryParams(r)
 	if err != nil {
-		ctrl.logger.LogError(errutils.FormatError(err))
+		ctrl.logger.LogWarn(errutils.FormatError(err))
 		w.WriteJSON(
 			api.ErrorResponse{
-				Code:   api.ErrCodeInternalServerError,
-				Detail: api.ErrDetailInternalServerError,
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
 			},
-			http.StatusInternalServerError,
+			http.StatusBadRequest,
 		)
 
 		return
 	}
 
-	responseBody := api.ListCodeSpacesResponse{
-		CodeSpaces: make([]*api.GetCodeSpaceResponse, len(codeSpaces)),
-	}
+	validationPassed, validationFailures := req.Validate()
+	if !validationPassed {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:               api.ErrCodeInvalidRequest,
+				Detail:             api.ErrDetailInvalidRequestData,
+				ValidationFailures: validationFailures,
+			},
+			http.StatusBadRequest,
+		)
 
-	for i, codeSpace := range codeSpaces {
-		responseBody.CodeSpaces[i] = &api.GetCodeSpaceResponse{
-			ID:          codeSpace.ID,
-			AuthorUUID:  codeSpace.AuthorUUID,
-			Name:        codeSpace.Name,
-			Language:    codeSpace.Language,
-			Contents:    codeSpace.Contents,
-			AccessLevel: codeSpaceAccesses[i].Level.String(),
-			CreatedAt:   codeSpace.CreatedAt,
-			UpdatedAt:   codeSpace.UpdatedAt,
-		}
+		return
 	}
 
-	w.WriteJSON(responseBody, http.StatusOK)
-}
-
-// HandleGetCodeSpace handles retrieval of a code space.
-// Methods: GET
-// URL: /code/space/{name}.
-func (ctrl *Controller) HandleGetCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
-	codeSpaceName := GetCodeSpaceNameParam(r)
-
-	codeSpace, codeSpaceAccess, err := ctrl.codeService.GetCodeSpace(r.Context(), codeSpaceName)
+	diff, err := ctrl.codeService.DiffCodeSpaceRevisions(r.Context(), codeSpaceName, req.From, req.To)
 	if err != nil {
 		ctrl.logger.LogError(errutils.FormatError(err))
 		switch {
@@ -140,6 +3177,14 @@ func (ctrl *Controller) HandleGetCodeSpace(w *httputils.ResponseWriter, r *http.
 				},
 				http.StatusNotFound,
 			)
+		case errors.Is(err, errutils.ErrCodeSpaceRevisionNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceRevisionNotFound,
+				},
+				http.StatusNotFound,
+			)
 		default:
 			w.WriteJSON(
 				api.ErrorResponse{
@@ -154,29 +3199,40 @@ func (ctrl *Controller) HandleGetCodeSpace(w *httputils.ResponseWriter, r *http.
 	}
 
 	w.WriteJSON(
-		api.GetCodeSpaceResponse{
-			ID:          codeSpace.ID,
-			AuthorUUID:  codeSpace.AuthorUUID,
-			Name:        codeSpace.Name,
-			Language:    codeSpace.Language,
-			Contents:    codeSpace.Contents,
-			AccessLevel: codeSpaceAccess.Level.String(),
-			CreatedAt:   codeSpace.CreatedAt,
-			UpdatedAt:   codeSpace.UpdatedAt,
+		api.DiffCodeSpaceRevisionsResponse{
+			FromRevisionID: req.From,
+			ToRevisionID:   req.To,
+			Diff:           diff,
 		},
 		http.StatusOK,
 	)
 }
 
-// HandleUpdateCodeSpace handles updating of code spaces.
-// Methods: PATCH
-// URL: /code/space/{name}.
-func (ctrl *Controller) HandleUpdateCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+// HandleRestoreCodeSpaceRevision handles restoring of code space revisions.
+// Only the main file of the code space is restored, and its other files are left as they are.
+// The expected version of the code space may be given in the request body or the If-Match header,
+// in which case the code space is only restored if it is still at that version.
+// Methods: POST
+// URL: /code/space/{name}/revisions/{id}/restore.
+func (ctrl *Controller) HandleRestoreCodeSpaceRevision(w *httputils.ResponseWriter, r *http.Request) {
 	codeSpaceName := GetCodeSpaceNameParam(r)
 
-	var req api.UpdateCodeSpaceRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
+	codeSpaceRevisionID, err := GetCodeSpaceRevisionIDParam(r)
 	if err != nil {
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailInvalidRequestData,
+			},
+			http.StatusBadRequest,
+		)
+
+		return
+	}
+
+	var req api.RestoreCodeSpaceRevisionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
 		ctrl.logger.LogWarn(errutils.FormatError(err, "json.Decoder.Decode failed"))
 		w.WriteJSON(
 			api.ErrorResponse{
@@ -204,10 +3260,43 @@ func (ctrl *Controller) HandleUpdateCodeSpace(w *httputils.ResponseWriter, r *ht
 		return
 	}
 
-	codeSpace, codeSpaceAccess, err := ctrl.codeService.UpdateCodeSpace(r.Context(), codeSpaceName, req.Contents)
+	version := req.Version
+	if version == nil {
+		version, err = GetIfMatchHeader(r)
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailInvalidRequestData,
+				},
+				http.StatusBadRequest,
+			)
+
+			return
+		}
+	}
+
+	codeSpace, codeSpaceAccess, err := ctrl.codeService.RestoreCodeSpaceRevision(
+		r.Context(),
+		codeSpaceName,
+		codeSpaceRevisionID,
+		version,
+	)
 	if err != nil {
+		var conflictErr *code.CodeSpaceVersionConflictError
 		ctrl.logger.LogError(errutils.FormatError(err))
 		switch {
+		case errors.As(err, &conflictErr):
+			w.Header().Set(httputils.HTTPHeaderETag, FormatCodeSpaceETag(conflictErr.CodeSpace.Version))
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:      api.ErrCodeVersionConflict,
+					Detail:    api.ErrDetailCodeSpaceVersionConflict,
+					CodeSpace: newGetCodeSpaceResponse(conflictErr.CodeSpace, conflictErr.CodeSpaceAccess),
+				},
+				http.StatusConflict,
+			)
 		case errors.Is(err, errutils.ErrCodeSpaceNotFound):
 			w.WriteJSON(
 				api.ErrorResponse{
@@ -216,6 +3305,14 @@ func (ctrl *Controller) HandleUpdateCodeSpace(w *httputils.ResponseWriter, r *ht
 				},
 				http.StatusNotFound,
 			)
+		case errors.Is(err, errutils.ErrCodeSpaceRevisionNotFound):
+			w.WriteJSON(
+				api.ErrorResponse{
+					Code:   api.ErrCodeResourceNotFound,
+					Detail: api.ErrDetailCodeSpaceRevisionNotFound,
+				},
+				http.StatusNotFound,
+			)
 		case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
 			w.WriteJSON(
 				api.ErrorResponse{
@@ -237,28 +3334,51 @@ func (ctrl *Controller) HandleUpdateCodeSpace(w *httputils.ResponseWriter, r *ht
 		return
 	}
 
+	w.Header().Set(httputils.HTTPHeaderETag, FormatCodeSpaceETag(codeSpace.Version))
 	w.WriteJSON(
-		api.UpdateCodeSpaceResponse{
-			ID:          codeSpace.ID,
-			AuthorUUID:  codeSpace.AuthorUUID,
-			Name:        codeSpace.Name,
-			Language:    codeSpace.Language,
-			Contents:    codeSpace.Contents,
-			AccessLevel: codeSpaceAccess.Level.String(),
-			CreatedAt:   codeSpace.CreatedAt,
-			UpdatedAt:   codeSpace.UpdatedAt,
+		api.RestoreCodeSpaceRevisionResponse{
+			ID:              codeSpace.ID,
+			AuthorUUID:      codeSpace.AuthorUUID,
+			Name:            codeSpace.Name,
+			Language:        codeSpace.Language,
+			LanguageVersion: codeSpace.LanguageVersion,
+			Contents:        codeSpace.Contents,
+			Version:         codeSpace.Version,
+			AccessLevel:     codeSpaceAccess.Level.String(),
+			CreatedAt:       codeSpace.CreatedAt,
+			UpdatedAt:       codeSpace.UpdatedAt,
 		},
 		http.StatusOK,
 	)
 }
 
-// HandleDeleteCodeSpace handles deletion of code spaces.
-// Methods: DELETE
-// URL: /code/space/{name}.
-func (ctrl *Controller) HandleDeleteCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+// HandleCollaborateOnCodeSpace handles collaborative editing of code spaces over a WebSocket connection.
+// Clients start with a snapshot of the code space, then send their edits, cursors and heartbeats
+// as CodeSpaceCollabMessage, and receive edits made by other collaborators, acknowledgements of their own edits,
+// and other collaborators joining, leaving and moving their cursors as CodeSpaceCollabEvent.
+// Users with read-only access receive edits and share their cursor but cannot make edits.
+// Clients that send nothing within the presence timeout are disconnected.
+// Methods: GET
+// URL: /code/space/{name}/collab.
+func (ctrl *Controller) HandleCollaborateOnCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
 	codeSpaceName := GetCodeSpaceNameParam(r)
 
-	err := ctrl.codeService.DeleteCodeSpace(r.Context(), codeSpaceName)
+	if !websocket.IsUpgradeRequest(r) {
+		ctrl.logger.LogWarn(errutils.FormatError(nil, "not a websocket upgrade request"))
+		w.WriteJSON(
+			api.ErrorResponse{
+				Code:   api.ErrCodeInvalidRequest,
+				Detail: api.ErrDetailWebSocketUpgradeRequired,
+			},
+			http.StatusUpgradeRequired,
+		)
+
+		return
+	}
+
+	// the connection is only upgraded once the collaboration has been joined,
+	// so that errors before that can still be reported with the appropriate status code
+	collaborator, err := ctrl.codeService.JoinCodeSpaceCollaboration(r.Context(), codeSpaceName)
 	if err != nil {
 		ctrl.logger.LogError(errutils.FormatError(err))
 		switch {
@@ -270,14 +3390,6 @@ func (ctrl *Controller) HandleDeleteCodeSpace(w *httputils.ResponseWriter, r *ht
 				},
 				http.StatusNotFound,
 			)
-		case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
-			w.WriteJSON(
-				api.ErrorResponse{
-					Code:   api.ErrCodeAccessDenied,
-					Detail: api.ErrDetailCodeSpaceAccessDenied,
-				},
-				http.StatusForbidden,
-			)
 		default:
 			w.WriteJSON(
 				api.ErrorResponse{
@@ -290,17 +3402,162 @@ func (ctrl *Controller) HandleDeleteCodeSpace(w *httputils.ResponseWriter, r *ht
 
 		return
 	}
+	defer collaborator.Leave()
 
-	w.WriteJSON(nil, http.StatusNoContent)
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		ctrl.logger.LogError(errutils.FormatError(err))
+
+		return
+	}
+
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		ctrl.forwardCodeSpaceCollabMessages(conn, collaborator)
+	}()
+
+	defer func() {
+		conn.Close()
+		<-readerDone
+	}()
+
+	// events stop once the client leaves, falls behind, or the code space is closed
+	for event := range collaborator.Events() {
+		err = conn.WriteJSON(newCodeSpaceCollabEvent(event))
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+
+			return
+		}
+	}
+
+	_ = conn.WriteClose(websocket.CloseGoingAway, "")
 }
 
-// HandleRunCodeSpace handles running of code spaces.
-// Methods: POST
-// URL: /code/space/{name}/run, /api/v1/code/space/{name}/run.
-func (ctrl *Controller) HandleRunCodeSpace(w *httputils.ResponseWriter, r *http.Request) {
+// forwardCodeSpaceCollabMessages forwards edits, cursors and heartbeats from a client connection to a collaborator
+// until the connection is closed, at which point the collaborator leaves.
+// Messages that are invalid or cannot be applied are answered with an error event and otherwise ignored.
+func (ctrl *Controller) forwardCodeSpaceCollabMessages(conn *websocket.Conn, collaborator *code.CodeSpaceCollaborator) {
+	defer collaborator.Leave()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg api.CodeSpaceCollabMessage
+		err = json.Unmarshal(message, &msg)
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err, "json.Unmarshal failed"))
+			ctrl.writeCodeSpaceCollabError(
+				conn,
+				api.ErrorResponse{
+					Code:   api.ErrCodeInvalidRequest,
+					Detail: api.ErrDetailInvalidRequestData,
+				},
+			)
+
+			continue
+		}
+
+		validationPassed, validationFailures := msg.Validate()
+		if !validationPassed {
+			ctrl.logger.LogWarn(errutils.FormatError(nil, "validation failed: %v", validationFailures))
+			ctrl.writeCodeSpaceCollabError(
+				conn,
+				api.ErrorResponse{
+					Code:               api.ErrCodeInvalidRequest,
+					Detail:             api.ErrDetailInvalidRequestData,
+					ValidationFailures: validationFailures,
+				},
+			)
+
+			continue
+		}
+
+		switch msg.Type {
+		case api.CodeSpaceCollabMessageTypeEdit:
+			err = collaborator.Edit(msg.Revision, newCodeSpaceEditOperation(msg.Operation))
+		case api.CodeSpaceCollabMessageTypeCursor:
+			err = collaborator.MoveCursor(msg.Revision, &code.CodeSpaceCursor{
+				Position: int(msg.Cursor.Position),
+				Anchor:   int(msg.Cursor.Anchor),
+			})
+		default:
+			collaborator.Heartbeat()
+		}
+
+		if err != nil {
+			ctrl.logger.LogWarn(errutils.FormatError(err))
+			switch {
+			case errors.Is(err, errutils.ErrCodeSpaceCollabClosed):
+				return
+			case errors.Is(err, errutils.ErrCodeSpaceAccessDenied):
+				ctrl.writeCodeSpaceCollabError(
+					conn,
+					api.ErrorResponse{
+						Code:   api.ErrCodeAccessDenied,
+						Detail: api.ErrDetailCodeSpaceAccessDenied,
+					},
+				)
+			case errors.Is(err, errutils.ErrCodeSpaceEditOutdated):
+				ctrl.writeCodeSpaceCollabError(
+					conn,
+					api.ErrorResponse{
+						Code:   api.ErrCodeVersionConflict,
+						Detail: api.ErrDetailCodeSpaceEditOutdated,
+					},
+				)
+			case errors.Is(err, errutils.ErrCodeSpaceEditInvalid):
+				ctrl.writeCodeSpaceCollabError(
+					conn,
+					api.ErrorResponse{
+						Code:   api.ErrCodeInvalidRequest,
+						Detail: api.ErrDetailCodeSpaceEditInvalid,
+					},
+				)
+			case errors.Is(err, errutils.ErrCodeSpaceCursorInvalid):
+				ctrl.writeCodeSpaceCollabError(
+					conn,
+					api.ErrorResponse{
+						Code:   api.ErrCodeInvalidRequest,
+						Detail: api.ErrDetailCodeSpaceCursorInvalid,
+					},
+				)
+			default:
+				ctrl.writeCodeSpaceCollabError(
+					conn,
+					api.ErrorResponse{
+						Code:   api.ErrCodeInternalServerError,
+						Detail: api.ErrDetailInternalServerError,
+					},
+				)
+			}
+		}
+	}
+}
+
+// writeCodeSpaceCollabError writes an error event to a client connection.
+// Failures are logged rather than returned, since the client may already have gone away.
+func (ctrl *Controller) writeCodeSpaceCollabError(conn *websocket.Conn, errResp api.ErrorResponse) {
+	err := conn.WriteJSON(&api.CodeSpaceCollabEvent{
+		Event: api.CodeSpaceCollabEventTypeError,
+		Data:  errResp,
+	})
+	if err != nil {
+		ctrl.logger.LogWarn(errutils.FormatError(err))
+	}
+}
+
+// HandleListCodeSpacePresence handles retrieval of the collaborators currently connected to a code space.
+// Methods: GET
+// URL: /code/space/{name}/presence.
+func (ctrl *Controller) HandleListCodeSpacePresence(w *httputils.ResponseWriter, r *http.Request) {
 	codeSpaceName := GetCodeSpaceNameParam(r)
 
-	pistonResponse, err := ctrl.codeService.RunCodeSpace(r.Context(), codeSpaceName)
+	presences, err := ctrl.codeService.ListCodeSpacePresence(r.Context(), codeSpaceName)
 	if err != nil {
 		ctrl.logger.LogError(errutils.FormatError(err))
 		switch {
@@ -325,25 +3582,15 @@ func (ctrl *Controller) HandleRunCodeSpace(w *httputils.ResponseWriter, r *http.
 		return
 	}
 
-	resp := api.RunCodeSpaceResponse{
-		Run: api.RunCodeSpaceResultsResponse{
-			Stdout: pistonResponse.Compile.Stdout,
-			Stderr: pistonResponse.Compile.Stderr,
-			Code:   pistonResponse.Compile.Code,
-			Signal: pistonResponse.Compile.Signal,
-		},
+	responseBody := api.ListCodeSpaceCollaboratorsResponse{
+		Collaborators: make([]*api.GetCodeSpaceCollaboratorResponse, len(presences)),
 	}
 
-	if pistonResponse.Compile != nil {
-		resp.Compile = &api.RunCodeSpaceResultsResponse{
-			Stdout: pistonResponse.Compile.Stdout,
-			Stderr: pistonResponse.Compile.Stderr,
-			Code:   pistonResponse.Compile.Code,
-			Signal: pistonResponse.Compile.Signal,
-		}
+	for i, presence := range presences {
+		responseBody.Collaborators[i] = newGetCodeSpaceCollaboratorResponse(presence)
 	}
 
-	w.WriteJSON(resp, http.StatusOK)
+	w.WriteJSON(responseBody, http.StatusOK)
 }
 
 // HandleListCodespaceUsers handles retrieval of users with access to a code space.
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/alvii147/nymphadora-api/internal/auth"
+	"github.com/alvii147/nymphadora-api/internal/server"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
 	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/httputils"
@@ -16,6 +18,601 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestGetCodeSpaceRunIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues         map[string]string
+		wantCodeSpaceRunID int64
+		wantErr            bool
+	}{
+		"Valid code space run ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceRunID: 42,
+			wantErr:            false,
+		},
+		"No code space run ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceRunID: 0,
+			wantErr:            true,
+		},
+		"Invalid code space run ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceRunID: 0,
+			wantErr:            true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceRunID, err := server.GetCodeSpaceRunIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceRunID, codeSpaceRunID)
+		})
+	}
+}
+
+func TestGetCodeSpaceFileIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues          map[string]string
+		wantCodeSpaceFileID int64
+		wantErr             bool
+	}{
+		"Valid code space file ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceFileID: 42,
+			wantErr:             false,
+		},
+		"No code space file ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceFileID: 0,
+			wantErr:             true,
+		},
+		"Invalid code space file ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceFileID: 0,
+			wantErr:             true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceFileID, err := server.GetCodeSpaceFileIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceFileID, codeSpaceFileID)
+		})
+	}
+}
+
+func TestGetCodeSpaceTestCaseIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues              map[string]string
+		wantCodeSpaceTestCaseID int64
+		wantErr                 bool
+	}{
+		"Valid code space test case ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceTestCaseID: 42,
+			wantErr:                 false,
+		},
+		"No code space test case ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceTestCaseID: 0,
+			wantErr:                 true,
+		},
+		"Invalid code space test case ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceTestCaseID: 0,
+			wantErr:                 true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceTestCaseID, err := server.GetCodeSpaceTestCaseIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceTestCaseID, codeSpaceTestCaseID)
+		})
+	}
+}
+
+func TestGetCodeSpaceRunConfigIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues               map[string]string
+		wantCodeSpaceRunConfigID int64
+		wantErr                  bool
+	}{
+		"Valid code space run config ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceRunConfigID: 42,
+			wantErr:                  false,
+		},
+		"No code space run config ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceRunConfigID: 0,
+			wantErr:                  true,
+		},
+		"Invalid code space run config ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceRunConfigID: 0,
+			wantErr:                  true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceRunConfigID, err := server.GetCodeSpaceRunConfigIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceRunConfigID, codeSpaceRunConfigID)
+		})
+	}
+}
+
+func TestGetCodeSpaceEnvVarIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues            map[string]string
+		wantCodeSpaceEnvVarID int64
+		wantErr               bool
+	}{
+		"Valid code space env var ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceEnvVarID: 42,
+			wantErr:               false,
+		},
+		"No code space env var ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceEnvVarID: 0,
+			wantErr:               true,
+		},
+		"Invalid code space env var ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceEnvVarID: 0,
+			wantErr:               true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceEnvVarID, err := server.GetCodeSpaceEnvVarIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceEnvVarID, codeSpaceEnvVarID)
+		})
+	}
+}
+
+func TestGetCodeSpaceRevisionIDParam(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		pathValues              map[string]string
+		wantCodeSpaceRevisionID int64
+		wantErr                 bool
+	}{
+		"Valid code space revision ID": {
+			pathValues: map[string]string{
+				"id": "42",
+			},
+			wantCodeSpaceRevisionID: 42,
+			wantErr:                 false,
+		},
+		"No code space revision ID": {
+			pathValues: map[string]string{
+				"dead": "beef",
+			},
+			wantCodeSpaceRevisionID: 0,
+			wantErr:                 true,
+		},
+		"Invalid code space revision ID": {
+			pathValues: map[string]string{
+				"id": "deadbeef",
+			},
+			wantCodeSpaceRevisionID: 0,
+			wantErr:                 true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{}
+			for name, value := range testcase.pathValues {
+				req.SetPathValue(name, value)
+			}
+
+			codeSpaceRevisionID, err := server.GetCodeSpaceRevisionIDParam(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantCodeSpaceRevisionID, codeSpaceRevisionID)
+		})
+	}
+}
+
+func TestGetDiffCodeSpaceRevisionsQueryParams(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		rawQuery string
+		wantReq  *api.DiffCodeSpaceRevisionsRequest
+		wantErr  bool
+	}{
+		"From and to": {
+			rawQuery: "from=3&to=7",
+			wantReq: &api.DiffCodeSpaceRevisionsRequest{
+				From: 3,
+				To:   7,
+			},
+			wantErr: false,
+		},
+		"No query params": {
+			rawQuery: "",
+			wantReq:  nil,
+			wantErr:  true,
+		},
+		"Missing to": {
+			rawQuery: "from=3",
+			wantReq:  nil,
+			wantErr:  true,
+		},
+		"Invalid from": {
+			rawQuery: "from=deadbeef&to=7",
+			wantReq:  nil,
+			wantErr:  true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{
+				URL: &url.URL{
+					RawQuery: testcase.rawQuery,
+				},
+			}
+
+			diffReq, err := server.GetDiffCodeSpaceRevisionsQueryParams(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantReq, diffReq)
+		})
+	}
+}
+
+func TestGetIfMatchHeader(t *testing.T) {
+	t.Parallel()
+
+	version := int64(42)
+
+	testcases := map[string]struct {
+		headers     map[string]string
+		wantVersion *int64
+		wantErr     bool
+	}{
+		"Valid entity tag": {
+			headers: map[string]string{
+				"If-Match": server.FormatCodeSpaceETag(42),
+			},
+			wantVersion: &version,
+			wantErr:     false,
+		},
+		"No If-Match header": {
+			headers:     map[string]string{},
+			wantVersion: nil,
+			wantErr:     false,
+		},
+		"Any entity tag": {
+			headers: map[string]string{
+				"If-Match": "*",
+			},
+			wantVersion: nil,
+			wantErr:     false,
+		},
+		"Unquoted entity tag": {
+			headers: map[string]string{
+				"If-Match": "42",
+			},
+			wantVersion: nil,
+			wantErr:     true,
+		},
+		"Weak entity tag": {
+			headers: map[string]string{
+				"If-Match": `W/"42"`,
+			},
+			wantVersion: nil,
+			wantErr:     true,
+		},
+		"Invalid entity tag": {
+			headers: map[string]string{
+				"If-Match": `"deadbeef"`,
+			},
+			wantVersion: nil,
+			wantErr:     true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{
+				Header: http.Header{},
+			}
+			for key, value := range testcase.headers {
+				req.Header.Add(key, value)
+			}
+
+			version, err := server.GetIfMatchHeader(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantVersion, version)
+		})
+	}
+}
+
+func TestGetPaginationQueryParams(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		rawQuery   string
+		wantLimit  int64
+		wantOffset int64
+		wantErr    bool
+	}{
+		"No query params": {
+			rawQuery:   "",
+			wantLimit:  20,
+			wantOffset: 0,
+			wantErr:    false,
+		},
+		"Limit and offset": {
+			rawQuery:   "limit=5&offset=10",
+			wantLimit:  5,
+			wantOffset: 10,
+			wantErr:    false,
+		},
+		"Invalid limit": {
+			rawQuery:   "limit=deadbeef",
+			wantLimit:  0,
+			wantOffset: 0,
+			wantErr:    true,
+		},
+		"Invalid offset": {
+			rawQuery:   "offset=deadbeef",
+			wantLimit:  0,
+			wantOffset: 0,
+			wantErr:    true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{
+				URL: &url.URL{
+					RawQuery: testcase.rawQuery,
+				},
+			}
+
+			limit, offset, err := server.GetPaginationQueryParams(req, 20)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantLimit, limit)
+			require.Equal(t, testcase.wantOffset, offset)
+		})
+	}
+}
+
+func TestGetInteractiveCodeSpaceRunQueryParams(t *testing.T) {
+	t.Parallel()
+
+	runTimeout := int64(3000)
+	runMemoryLimit := int64(1048576)
+
+	testcases := map[string]struct {
+		rawQuery string
+		wantReq  *api.InteractiveCodeSpaceRunRequest
+		wantErr  bool
+	}{
+		"No query params": {
+			rawQuery: "",
+			wantReq:  &api.InteractiveCodeSpaceRunRequest{},
+			wantErr:  false,
+		},
+		"Args and limits": {
+			rawQuery: "args=--verbose&args=42&run_timeout=3000&run_memory_limit=1048576",
+			wantReq: &api.InteractiveCodeSpaceRunRequest{
+				Args:           []string{"--verbose", "42"},
+				RunTimeout:     &runTimeout,
+				RunMemoryLimit: &runMemoryLimit,
+			},
+			wantErr: false,
+		},
+		"Invalid compile timeout": {
+			rawQuery: "compile_timeout=deadbeef",
+			wantReq:  nil,
+			wantErr:  true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &http.Request{
+				URL: &url.URL{
+					RawQuery: testcase.rawQuery,
+				},
+			}
+
+			interactiveReq, err := server.GetInteractiveCodeSpaceRunQueryParams(req)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, testcase.wantReq, interactiveReq)
+		})
+	}
+}
+
+func TestHandleListCodingLanguages(t *testing.T) {
+	t.Parallel()
+
+	httpClient := httputils.NewHTTPClient(nil)
+
+	req, err := http.NewRequest(http.MethodGet, TestServerURL+"/code/languages", http.NoBody)
+	require.NoError(t, err)
+
+	res, err := httpClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := res.Body.Close()
+		require.NoError(t, err)
+	})
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var listCodingLanguagesResp api.ListCodingLanguagesResponse
+	err = json.NewDecoder(res.Body).Decode(&listCodingLanguagesResp)
+	require.NoError(t, err)
+
+	languages := make([]string, len(listCodingLanguagesResp.Languages))
+	for i, codingLanguage := range listCodingLanguagesResp.Languages {
+		languages[i] = codingLanguage.Language
+		require.NotEmpty(t, codingLanguage.Versions)
+	}
+
+	require.Contains(t, languages, api.PistonLanguagePython)
+	require.IsIncreasing(t, languages)
+}
+
 func TestHandleCreateCodeSpace(t *testing.T) {
 	t.Parallel()
 
@@ -62,6 +659,20 @@ func TestHandleCreateCodeSpace(t *testing.T) {
 			wantErrCode:    "",
 			wantErrDetail:  "",
 		},
+		"Valid request for Python code space using alias": {
+			headers: map[string]string{
+				"Authorization": fmt.Sprintf("Bearer %s", userAccessJWT),
+			},
+			requestBody: `
+				{
+					"language": "py"
+				}
+			`,
+			wantStatusCode: http.StatusCreated,
+			wantLanguage:   "python",
+			wantErrCode:    "",
+			wantErrDetail:  "",
+		},
 		"Unsupported language": {
 			headers: map[string]string{
 				"Authorization": fmt.Sprintf("Bearer %s", userAccessJWT),
@@ -76,6 +687,21 @@ func TestHandleCreateCodeSpace(t *testing.T) {
 			wantErrCode:    api.ErrCodeInvalidRequest,
 			wantErrDetail:  api.ErrDetailInvalidRequestData,
 		},
+		"Unsupported language version": {
+			headers: map[string]string{
+				"Authorization": fmt.Sprintf("Bearer %s", userAccessJWT),
+			},
+			requestBody: `
+				{
+					"language": "python",
+					"language_version": "0.0.0"
+				}
+			`,
+			wantStatusCode: http.StatusBadRequest,
+			wantLanguage:   "",
+			wantErrCode:    api.ErrCodeInvalidRequest,
+			wantErrDetail:  api.ErrDetailCodeSpaceVersionUnsupported,
+		},
 		"No language": {
 			headers: map[string]string{
 				"Authorization": fmt.Sprintf("Bearer %s", userAccessJWT),
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"github.com/alvii147/nymphadora-api/internal/config"
 	"github.com/alvii147/nymphadora-api/internal/database"
 	"github.com/alvii147/nymphadora-api/internal/templatesmanager"
+	"github.com/alvii147/nymphadora-api/pkg/circuitbreaker"
 	"github.com/alvii147/nymphadora-api/pkg/cryptocore"
 	"github.com/alvii147/nymphadora-api/pkg/env"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
@@ -19,6 +21,7 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/logging"
 	"github.com/alvii147/nymphadora-api/pkg/mailclient"
 	"github.com/alvii147/nymphadora-api/pkg/piston"
+	"github.com/alvii147/nymphadora-api/pkg/ratelimit"
 	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
 )
 
@@ -27,16 +30,20 @@ const HTTPServerTimeout = 30 * time.Second
 
 // Controller handles server API operations.
 type Controller struct {
-	config       *config.Config
-	timeProvider timekeeper.Provider
-	router       httputils.Router
-	dbPool       database.Pool
-	logger       logging.Logger
-	crypto       cryptocore.Crypto
-	mailClient   mailclient.Client
-	tmplManager  templatesmanager.Manager
-	authService  auth.Service
-	codeService  code.Service
+	config              *config.Config
+	timeProvider        timekeeper.Provider
+	router              httputils.Router
+	dbPool              database.Pool
+	logger              logging.Logger
+	crypto              cryptocore.Crypto
+	mailClient          mailclient.Client
+	tmplManager         templatesmanager.Manager
+	authService         auth.Service
+	codeService         code.Service
+	stopRuntimesRefresh func()
+	stopTrashPurge      func()
+	stopHistoryThin     func()
+	stopCollabSave      func()
 }
 
 // NewController sets up the server and returns a new controller.
@@ -99,31 +106,144 @@ func NewController() (*Controller, error) {
 		authRepository,
 	)
 
-	pistonClient := piston.NewClient(nil, httputils.NewHTTPClient(nil))
+	var pistonClient piston.Client
+	switch cfg.PistonClientType {
+	case piston.ClientTypePiston:
+		var pistonAPIKey *string
+		if cfg.PistonAPIKey != "" {
+			pistonAPIKey = &cfg.PistonAPIKey
+		}
+
+		pistonClient = piston.NewClient(
+			cfg.PistonBaseURL,
+			pistonAPIKey,
+			httputils.NewHTTPClient(func(c *http.Client) {
+				c.Timeout = time.Duration(cfg.PistonHTTPTimeoutSeconds) * time.Second
+			}),
+		)
+	case piston.ClientTypeFake:
+		pistonClient = piston.NewFakeClient()
+	default:
+		return nil, errutils.FormatErrorf(nil, "unknown piston client type %s", cfg.PistonClientType)
+	}
+
+	// retries go through the circuit breaker and the queue again, so that they are rate limited
+	// like any other request, and the circuit breaker sits in front of the queue,
+	// so that requests fail fast while Piston is down instead of waiting for their turn in the queue
+	pistonClient = piston.NewQueuedClient(
+		pistonClient,
+		ratelimit.NewTokenBucket(timeProvider, cfg.PistonRateLimit, cfg.PistonRateLimitBurst),
+		cfg.PistonMaxConcurrency,
+		cfg.PistonMaxQueueDepth,
+	)
+	pistonClient = piston.NewCircuitBreakingClient(
+		pistonClient,
+		circuitbreaker.NewBreaker(
+			timeProvider,
+			cfg.PistonCircuitBreakerThreshold,
+			time.Duration(cfg.PistonCircuitBreakerCooldownSeconds)*time.Second,
+		),
+	)
+	pistonClient = piston.NewRetryingClient(
+		pistonClient,
+		cfg.PistonMaxRetries,
+		time.Duration(cfg.PistonRetryBaseDelayMilliseconds)*time.Millisecond,
+		time.Duration(cfg.PistonRetryMaxDelayMilliseconds)*time.Millisecond,
+	)
+
 	codeRepository := code.NewRepository(timeProvider)
+
+	var executionCache piston.Cache
+	switch cfg.ExecutionCacheType {
+	case piston.CacheTypeMemory:
+		executionCache = piston.NewMemoryCache(
+			timeProvider,
+			time.Duration(cfg.ExecutionCacheTTLSeconds)*time.Second,
+			cfg.ExecutionCacheMaxEntries,
+		)
+	case piston.CacheTypeDatabase:
+		executionCache = code.NewDatabaseCache(
+			timeProvider,
+			dbPool,
+			codeRepository,
+			time.Duration(cfg.ExecutionCacheTTLSeconds)*time.Second,
+			int64(cfg.ExecutionCacheMaxEntries),
+		)
+	case piston.CacheTypeNone:
+		executionCache = nil
+	default:
+		return nil, errutils.FormatErrorf(nil, "unknown execution cache type %s", cfg.ExecutionCacheType)
+	}
+
+	// runtimes are loaded at startup and refreshed on a schedule,
+	// so runtimes added to Piston become available without a redeploy
+	runtimesClient := piston.NewRuntimesCachingClient(pistonClient)
+	err = runtimesClient.Refresh(context.Background())
+	if err != nil {
+		logger.LogWarn(errutils.FormatError(err, "runtimesClient.Refresh failed"))
+	}
+
+	stopRuntimesRefresh := runtimesClient.RefreshEvery(
+		time.Duration(cfg.PistonRuntimesRefreshSeconds)*time.Second,
+		func(err error) {
+			logger.LogWarn(errutils.FormatError(err, "runtimesClient.Refresh failed"))
+		},
+	)
+	pistonClient = runtimesClient
+
 	codeService := code.NewService(
 		cfg,
 		timeProvider,
 		dbPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		executionCache,
 		codeRepository,
 		authRepository,
 	)
 
+	// trashed code spaces are purged on a schedule once they outlive the retention
+	stopTrashPurge := codeService.PurgeTrashedCodeSpacesEvery(
+		time.Duration(cfg.CodeSpaceTrashPurgeIntervalSeconds)*time.Second,
+		func(err error) {
+			logger.LogWarn(errutils.FormatError(err, "codeService.PurgeTrashedCodeSpaces failed"))
+		},
+	)
+
+	// code space revisions are thinned on a schedule once they outlive the full history retention
+	stopHistoryThin := codeService.ThinCodeSpaceRevisionsEvery(
+		time.Duration(cfg.CodeSpaceHistoryThinIntervalSeconds)*time.Second,
+		func(err error) {
+			logger.LogWarn(errutils.FormatError(err, "codeService.ThinCodeSpaceRevisions failed"))
+		},
+	)
+
+	// collaboratively edited code spaces are saved on a schedule rather than on every edit
+	stopCollabSave := codeService.SaveCodeSpaceCollaborationsEvery(
+		time.Duration(cfg.CodeSpaceCollabSaveIntervalSeconds)*time.Second,
+		func(err error) {
+			logger.LogWarn(errutils.FormatError(err, "codeService.SaveCodeSpaceCollaborations failed"))
+		},
+	)
+
 	ctrl := &Controller{
-		config:       cfg,
-		timeProvider: timeProvider,
-		router:       router,
-		dbPool:       dbPool,
-		logger:       logger,
-		crypto:       crypto,
-		mailClient:   mailClient,
-		tmplManager:  tmplManager,
-		authService:  authService,
-		codeService:  codeService,
+		config:              cfg,
+		timeProvider:        timeProvider,
+		router:              router,
+		dbPool:              dbPool,
+		logger:              logger,
+		crypto:              crypto,
+		mailClient:          mailClient,
+		tmplManager:         tmplManager,
+		authService:         authService,
+		codeService:         codeService,
+		stopRuntimesRefresh: stopRuntimesRefresh,
+		stopTrashPurge:      stopTrashPurge,
+		stopHistoryThin:     stopHistoryThin,
+		stopCollabSave:      stopCollabSave,
 	}
 
 	ctrl.route()
@@ -156,6 +276,12 @@ func (ctrl *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Close closes the Controller and its connections.
 func (ctrl *Controller) Close() {
+	ctrl.stopRuntimesRefresh()
+	ctrl.stopTrashPurge()
+	ctrl.stopHistoryThin()
+	ctrl.stopCollabSave()
+	ctrl.codeService.Close()
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -17,6 +17,8 @@ func (ctrl *Controller) route() {
 	ctrl.router.GET("/auth/users/me", ctrl.HandleGetUserMe, jwtMiddleware, loggerMiddleware)
 	ctrl.router.PATCH("/auth/users/me", ctrl.HandleUpdateUser, jwtMiddleware, loggerMiddleware)
 	ctrl.router.GET("/api/v1/auth/users/me", ctrl.HandleGetUserMe, apiKeyMiddleware, loggerMiddleware)
+	ctrl.router.GET("/auth/users/me/usage", ctrl.HandleGetUserUsage, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/api/v1/auth/users/me/usage", ctrl.HandleGetUserUsage, apiKeyMiddleware, loggerMiddleware)
 	ctrl.router.POST("/auth/users/activate", ctrl.HandleActivateUser, loggerMiddleware)
 
 	ctrl.router.POST("/auth/tokens", ctrl.HandleCreateJWT, loggerMiddleware)
@@ -28,12 +30,135 @@ func (ctrl *Controller) route() {
 	ctrl.router.PATCH("/auth/api-keys/{id}", ctrl.HandleUpdateAPIKey, jwtMiddleware, loggerMiddleware)
 	ctrl.router.DELETE("/auth/api-keys/{id}", ctrl.HandleDeleteAPIKey, jwtMiddleware, loggerMiddleware)
 
+	ctrl.router.GET("/code/languages", ctrl.HandleListCodingLanguages, loggerMiddleware)
 	ctrl.router.POST("/code/space", ctrl.HandleCreateCodeSpace, jwtMiddleware, loggerMiddleware)
 	ctrl.router.GET("/code/space", ctrl.HandleListCodeSpaces, jwtMiddleware, loggerMiddleware)
 	ctrl.router.GET("/code/space/{name}", ctrl.HandleGetCodeSpace, jwtMiddleware, loggerMiddleware)
 	ctrl.router.PATCH("/code/space/{name}", ctrl.HandleUpdateCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.DELETE("/code/space/{name}", ctrl.HandleDeleteCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/space/{name}/fork", ctrl.HandleForkCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/code/trash", ctrl.HandleListTrashedCodeSpaces, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/trash/{name}/restore", ctrl.HandleRestoreCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/code/space/{name}/files", ctrl.HandleListCodeSpaceFiles, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/space/{name}/files", ctrl.HandleCreateCodeSpaceFile, jwtMiddleware, loggerMiddleware)
+	ctrl.router.PATCH("/code/space/{name}/files/{id}", ctrl.HandleUpdateCodeSpaceFile, jwtMiddleware, loggerMiddleware)
+	ctrl.router.DELETE("/code/space/{name}/files/{id}", ctrl.HandleDeleteCodeSpaceFile, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/code/space/{name}/tests", ctrl.HandleListCodeSpaceTestCases, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/space/{name}/tests", ctrl.HandleCreateCodeSpaceTestCase, jwtMiddleware, loggerMiddleware)
+	ctrl.router.PATCH(
+		"/code/space/{name}/tests/{id}",
+		ctrl.HandleUpdateCodeSpaceTestCase,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.DELETE(
+		"/code/space/{name}/tests/{id}",
+		ctrl.HandleDeleteCodeSpaceTestCase,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET("/code/space/{name}/run-configs", ctrl.HandleListCodeSpaceRunConfigs, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET(
+		"/api/v1/code/space/{name}/run-configs",
+		ctrl.HandleListCodeSpaceRunConfigs,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.POST(
+		"/code/space/{name}/run-configs",
+		ctrl.HandleCreateCodeSpaceRunConfig,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.PATCH(
+		"/code/space/{name}/run-configs/{id}",
+		ctrl.HandleUpdateCodeSpaceRunConfig,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.DELETE(
+		"/code/space/{name}/run-configs/{id}",
+		ctrl.HandleDeleteCodeSpaceRunConfig,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET("/code/space/{name}/env", ctrl.HandleListCodeSpaceEnvVars, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET(
+		"/api/v1/code/space/{name}/env",
+		ctrl.HandleListCodeSpaceEnvVars,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.POST("/code/space/{name}/env", ctrl.HandleCreateCodeSpaceEnvVar, jwtMiddleware, loggerMiddleware)
+	ctrl.router.PATCH("/code/space/{name}/env/{id}", ctrl.HandleUpdateCodeSpaceEnvVar, jwtMiddleware, loggerMiddleware)
+	ctrl.router.DELETE("/code/space/{name}/env/{id}", ctrl.HandleDeleteCodeSpaceEnvVar, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/space/{name}/test", ctrl.HandleRunCodeSpaceTests, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST("/api/v1/code/space/{name}/test", ctrl.HandleRunCodeSpaceTests, apiKeyMiddleware, loggerMiddleware)
 	ctrl.router.POST("/code/space/{name}/run", ctrl.HandleRunCodeSpace, jwtMiddleware, loggerMiddleware)
 	ctrl.router.POST("/api/v1/code/space/{name}/run", ctrl.HandleRunCodeSpace, apiKeyMiddleware, loggerMiddleware)
+	ctrl.router.POST("/code/space/{name}/run/matrix", ctrl.HandleRunCodeSpaceMatrix, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST(
+		"/api/v1/code/space/{name}/run/matrix",
+		ctrl.HandleRunCodeSpaceMatrix,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.POST("/code/space/{name}/benchmark", ctrl.HandleBenchmarkCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST(
+		"/api/v1/code/space/{name}/benchmark",
+		ctrl.HandleBenchmarkCodeSpace,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.POST("/code/space/{name}/run/stream", ctrl.HandleStreamCodeSpaceRun, jwtMiddleware, loggerMiddleware)
+	ctrl.router.POST(
+		"/api/v1/code/space/{name}/run/stream",
+		ctrl.HandleStreamCodeSpaceRun,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET(
+		"/code/space/{name}/run/interactive",
+		ctrl.HandleInteractiveCodeSpaceRun,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET(
+		"/api/v1/code/space/{name}/run/interactive",
+		ctrl.HandleInteractiveCodeSpaceRun,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET("/code/space/{name}/runs", ctrl.HandleListCodeSpaceRuns, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/code/space/{name}/runs/{id}", ctrl.HandleGetCodeSpaceRun, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/api/v1/code/space/{name}/runs", ctrl.HandleListCodeSpaceRuns, apiKeyMiddleware, loggerMiddleware)
+	ctrl.router.GET(
+		"/api/v1/code/space/{name}/runs/{id}",
+		ctrl.HandleGetCodeSpaceRun,
+		apiKeyMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET("/code/space/{name}/revisions", ctrl.HandleListCodeSpaceRevisions, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET(
+		"/code/space/{name}/revisions/diff",
+		ctrl.HandleDiffCodeSpaceRevisions,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET(
+		"/code/space/{name}/revisions/{id}",
+		ctrl.HandleGetCodeSpaceRevision,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.POST(
+		"/code/space/{name}/revisions/{id}/restore",
+		ctrl.HandleRestoreCodeSpaceRevision,
+		jwtMiddleware,
+		loggerMiddleware,
+	)
+	ctrl.router.GET("/code/space/{name}/collab", ctrl.HandleCollaborateOnCodeSpace, jwtMiddleware, loggerMiddleware)
+	ctrl.router.GET("/code/space/{name}/presence", ctrl.HandleListCodeSpacePresence, jwtMiddleware, loggerMiddleware)
 	ctrl.router.GET("/code/space/{name}/access", ctrl.HandleListCodespaceUsers, jwtMiddleware, loggerMiddleware)
 	ctrl.router.POST("/code/space/{name}/access", ctrl.HandleInviteCodeSpaceUser, jwtMiddleware, loggerMiddleware)
 	ctrl.router.DELETE("/code/space/{name}/access", ctrl.HandleRemoveCodeSpaceUser, jwtMiddleware, loggerMiddleware)
@@ -7,11 +7,10 @@ import (
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/code"
 	"github.com/alvii147/nymphadora-api/internal/templatesmanager"
+	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/alvii147/nymphadora-api/pkg/cryptocore"
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
-	"github.com/alvii147/nymphadora-api/pkg/httputils"
 	"github.com/alvii147/nymphadora-api/pkg/mailclient"
-	"github.com/alvii147/nymphadora-api/pkg/piston"
 	"github.com/alvii147/nymphadora-api/pkg/testkit"
 	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
 	"github.com/stretchr/testify/require"
@@ -29,20 +28,23 @@ func MustCreateCodeSpace(
 	dbPool := MustNewDatabasePool()
 	defer dbPool.Close()
 
+	_, _, logger := testkit.CreateInMemLogger()
 	crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
 	mailClient := mailclient.NewConsoleClient("support@nymphadora.com", timeProvider, os.Stdout)
 	tmplManager := templatesmanager.NewManager()
-	pistonClient := piston.NewClient(nil, httputils.NewHTTPClient(nil))
+	pistonClient := GetPistonClient()
 	repo := code.NewRepository(timeProvider)
 	authRepo := auth.NewRepository(timeProvider)
 	svc := code.NewService(
 		cfg,
 		timeProvider,
 		dbPool,
+		logger,
 		crypto,
 		mailClient,
 		tmplManager,
 		pistonClient,
+		nil,
 		repo,
 		authRepo,
 	)
@@ -50,7 +52,9 @@ func MustCreateCodeSpace(
 	ctx := context.WithValue(context.Background(), auth.AuthContextKeyUserUUID, authorUUID)
 	codeSpace, codeSpaceAccess, err := svc.CreateCodeSpace(
 		ctx,
+		nil,
 		language,
+		nil,
 	)
 	if err != nil {
 		panic(errutils.FormatError(err))
@@ -89,3 +93,201 @@ func MustCreateCodeSpaceAccess(
 
 	return codeSpaceAccess
 }
+
+// MustCreateCodeSpaceRun creates and returns a new completed code space run
+// for a given code space and user UUID and panics on error.
+func MustCreateCodeSpaceRun(
+	t testkit.TestingT,
+	codeSpace *code.CodeSpace,
+	userUUID string,
+) *code.CodeSpaceRun {
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := MustNewDatabasePool()
+	defer dbPool.Close()
+
+	dbConn, err := dbPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	startedAt := timeProvider.Now()
+	codeSpaceRun := &code.CodeSpaceRun{
+		CodeSpaceID: codeSpace.ID,
+		UserUUID:    &userUUID,
+		Contents:    codeSpace.Contents,
+		Language:    codeSpace.Language,
+		Version:     "0.0.0",
+		Status:      api.CodeSpaceRunStatusRunning,
+		StartedAt:   &startedAt,
+	}
+
+	codeSpaceRun, err = repo.CreateCodeSpaceRun(context.Background(), dbConn, codeSpaceRun)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	exitCode := 0
+	finishedAt := timeProvider.Now()
+	codeSpaceRun.Status = api.CodeSpaceRunStatusCompleted
+	codeSpaceRun.FinishedAt = &finishedAt
+	codeSpaceRun.SetResults(&api.PistonExecuteResponse{
+		Language: codeSpace.Language,
+		Version:  codeSpaceRun.Version,
+		Run: api.PistonResults{
+			Stdout: "Yello!\n",
+			Stderr: "",
+			Output: "Yello!\n",
+			Code:   &exitCode,
+			Signal: nil,
+		},
+	})
+
+	codeSpaceRun, err = repo.UpdateCodeSpaceRun(context.Background(), dbConn, codeSpaceRun)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	return codeSpaceRun
+}
+
+// MustCreateCodeSpaceFile creates and returns a new file in a given code space and panics on error.
+func MustCreateCodeSpaceFile(
+	t testkit.TestingT,
+	codeSpaceID int64,
+	name string,
+	isEntryPoint bool,
+) *code.CodeSpaceFile {
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := MustNewDatabasePool()
+	defer dbPool.Close()
+
+	dbConn, err := dbPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	codeSpaceFile := &code.CodeSpaceFile{
+		CodeSpaceID:  codeSpaceID,
+		Name:         name,
+		Contents:     "Yello!\n",
+		IsEntryPoint: isEntryPoint,
+	}
+
+	codeSpaceFile, err = repo.CreateCodeSpaceFile(context.Background(), dbConn, codeSpaceFile)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	return codeSpaceFile
+}
+
+// MustCreateCodeSpaceTestCase creates and returns a new test case in a given code space and panics on error.
+func MustCreateCodeSpaceTestCase(
+	t testkit.TestingT,
+	codeSpaceID int64,
+	expectedStdout string,
+	comparisonMode string,
+	isHidden bool,
+) *code.CodeSpaceTestCase {
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := MustNewDatabasePool()
+	defer dbPool.Close()
+
+	dbConn, err := dbPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	codeSpaceTestCase := &code.CodeSpaceTestCase{
+		CodeSpaceID:    codeSpaceID,
+		Stdin:          nil,
+		Args:           []string{},
+		ExpectedStdout: expectedStdout,
+		ComparisonMode: comparisonMode,
+		IsHidden:       isHidden,
+	}
+
+	codeSpaceTestCase, err = repo.CreateCodeSpaceTestCase(context.Background(), dbConn, codeSpaceTestCase)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	return codeSpaceTestCase
+}
+
+// MustCreateCodeSpaceRunConfig creates and returns a new run configuration in a given code space and panics on error.
+func MustCreateCodeSpaceRunConfig(
+	t testkit.TestingT,
+	codeSpaceID int64,
+	name string,
+	args []string,
+) *code.CodeSpaceRunConfig {
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := MustNewDatabasePool()
+	defer dbPool.Close()
+
+	dbConn, err := dbPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	codeSpaceRunConfig := &code.CodeSpaceRunConfig{
+		CodeSpaceID: codeSpaceID,
+		Name:        name,
+		Args:        args,
+	}
+
+	codeSpaceRunConfig, err = repo.CreateCodeSpaceRunConfig(context.Background(), dbConn, codeSpaceRunConfig)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	return codeSpaceRunConfig
+}
+
+// MustCreateCodeSpaceEnvVar creates and returns a new environment variable for a given code space
+// and panics on error.
+// Values of secret environment variables are encrypted before they are stored,
+// and the returned environment variable holds the stored value.
+func MustCreateCodeSpaceEnvVar(
+	t testkit.TestingT,
+	codeSpaceID int64,
+	name string,
+	value string,
+	isSecret bool,
+) *code.CodeSpaceEnvVar {
+	cfg := MustCreateConfig()
+	timeProvider := timekeeper.NewFrozenProvider()
+	dbPool := MustNewDatabasePool()
+	defer dbPool.Close()
+
+	dbConn, err := dbPool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer dbConn.Release()
+
+	repo := code.NewRepository(timeProvider)
+
+	if isSecret {
+		crypto := cryptocore.NewCrypto(timeProvider, cfg.SecretKey)
+		value, err = crypto.EncryptSecret(value)
+		require.NoError(t, err)
+	}
+
+	codeSpaceEnvVar := &code.CodeSpaceEnvVar{
+		CodeSpaceID: codeSpaceID,
+		Name:        name,
+		Value:       &value,
+		IsSecret:    isSecret,
+	}
+
+	codeSpaceEnvVar, err = repo.CreateCodeSpaceEnvVar(context.Background(), dbConn, codeSpaceEnvVar)
+	if err != nil {
+		panic(errutils.FormatError(err))
+	}
+
+	return codeSpaceEnvVar
+}
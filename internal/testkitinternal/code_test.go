@@ -6,6 +6,7 @@ import (
 	"github.com/alvii147/nymphadora-api/internal/auth"
 	"github.com/alvii147/nymphadora-api/internal/code"
 	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
+	"github.com/alvii147/nymphadora-api/pkg/api"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -72,3 +73,149 @@ func TestMustCreateCodeSpaceAccessError(t *testing.T) {
 		)
 	})
 }
+
+func TestMustCreateCodeSpaceRunSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	codeSpaceRun := testkitinternal.MustCreateCodeSpaceRun(t, codeSpace, author.UUID)
+
+	require.Equal(t, codeSpace.ID, codeSpaceRun.CodeSpaceID)
+	require.NotNil(t, codeSpaceRun.UserUUID)
+	require.Equal(t, author.UUID, *codeSpaceRun.UserUUID)
+	require.Equal(t, codeSpace.Contents, codeSpaceRun.Contents)
+	require.Equal(t, codeSpace.Language, codeSpaceRun.Language)
+	require.Equal(t, api.CodeSpaceRunStatusCompleted, codeSpaceRun.Status)
+	require.NotNil(t, codeSpaceRun.RunStdout)
+	require.NotNil(t, codeSpaceRun.FinishedAt)
+}
+
+func TestMustCreateCodeSpaceRunError(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+
+	require.Panics(t, func() {
+		testkitinternal.MustCreateCodeSpaceRun(
+			t,
+			&code.CodeSpace{
+				ID:       314159265,
+				Language: "python",
+				Contents: "print('hello')",
+			},
+			author.UUID,
+		)
+	})
+}
+
+func TestMustCreateCodeSpaceFileSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	codeSpaceFile := testkitinternal.MustCreateCodeSpaceFile(t, codeSpace.ID, "utils.py", true)
+
+	require.Equal(t, codeSpace.ID, codeSpaceFile.CodeSpaceID)
+	require.Equal(t, "utils.py", codeSpaceFile.Name)
+	require.True(t, codeSpaceFile.IsEntryPoint)
+}
+
+func TestMustCreateCodeSpaceFileError(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		testkitinternal.MustCreateCodeSpaceFile(t, 314159265, "utils.py", false)
+	})
+}
+
+func TestMustCreateCodeSpaceTestCaseSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	codeSpaceTestCase := testkitinternal.MustCreateCodeSpaceTestCase(
+		t,
+		codeSpace.ID,
+		"Yello!",
+		api.CodeSpaceTestComparisonModeTrimmed,
+		true,
+	)
+
+	require.Equal(t, codeSpace.ID, codeSpaceTestCase.CodeSpaceID)
+	require.Equal(t, "Yello!", codeSpaceTestCase.ExpectedStdout)
+	require.Equal(t, api.CodeSpaceTestComparisonModeTrimmed, codeSpaceTestCase.ComparisonMode)
+	require.True(t, codeSpaceTestCase.IsHidden)
+}
+
+func TestMustCreateCodeSpaceTestCaseError(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		testkitinternal.MustCreateCodeSpaceTestCase(t, 314159265, "Yello!", api.CodeSpaceTestComparisonModeExact, false)
+	})
+}
+
+func TestMustCreateCodeSpaceRunConfigSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	codeSpaceRunConfig := testkitinternal.MustCreateCodeSpaceRunConfig(t, codeSpace.ID, "verbose", []string{"-v"})
+
+	require.Equal(t, codeSpace.ID, codeSpaceRunConfig.CodeSpaceID)
+	require.Equal(t, "verbose", codeSpaceRunConfig.Name)
+	require.Equal(t, []string{"-v"}, codeSpaceRunConfig.Args)
+}
+
+func TestMustCreateCodeSpaceRunConfigError(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		testkitinternal.MustCreateCodeSpaceRunConfig(t, 314159265, "verbose", []string{})
+	})
+}
+
+func TestMustCreateCodeSpaceEnvVarSuccess(t *testing.T) {
+	t.Parallel()
+
+	author, _ := testkitinternal.MustCreateUser(t, func(u *auth.User) {
+		u.IsActive = true
+	})
+	codeSpace, _ := testkitinternal.MustCreateCodeSpace(t, author.UUID, "python")
+
+	codeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "HOUSE", "Gryffindor", false)
+
+	require.Equal(t, codeSpace.ID, codeSpaceEnvVar.CodeSpaceID)
+	require.Equal(t, "HOUSE", codeSpaceEnvVar.Name)
+	require.Equal(t, "Gryffindor", *codeSpaceEnvVar.Value)
+	require.False(t, codeSpaceEnvVar.IsSecret)
+
+	secretCodeSpaceEnvVar := testkitinternal.MustCreateCodeSpaceEnvVar(t, codeSpace.ID, "PASSWORD", "Caput Draconis", true)
+
+	require.Equal(t, "PASSWORD", secretCodeSpaceEnvVar.Name)
+	require.NotEqual(t, "Caput Draconis", *secretCodeSpaceEnvVar.Value)
+	require.True(t, secretCodeSpaceEnvVar.IsSecret)
+}
+
+func TestMustCreateCodeSpaceEnvVarError(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		testkitinternal.MustCreateCodeSpaceEnvVar(t, 314159265, "HOUSE", "Gryffindor", false)
+	})
+}
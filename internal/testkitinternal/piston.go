@@ -0,0 +1,36 @@
+package testkitinternal
+
+import (
+	"sync"
+
+	"github.com/alvii147/nymphadora-api/pkg/httputils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	"github.com/alvii147/nymphadora-api/pkg/ratelimit"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+)
+
+var (
+	// testPistonClient is the Piston client shared across tests.
+	testPistonClient piston.Client
+	// testPistonClientOnce ensures testPistonClient is created only once.
+	testPistonClientOnce sync.Once
+)
+
+// GetPistonClient returns a Piston client that is shared across tests.
+// Sharing a single client ensures tests running in parallel respect the Piston rate limit
+// and fetch Piston runtimes only once.
+func GetPistonClient() piston.Client {
+	testPistonClientOnce.Do(func() {
+		cfg := MustCreateConfig()
+		testPistonClient = piston.NewRuntimesCachingClient(
+			piston.NewQueuedClient(
+				piston.NewClient(piston.PistonDefaultBaseURL, nil, httputils.NewHTTPClient(nil)),
+				ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), cfg.PistonRateLimit, cfg.PistonRateLimitBurst),
+				cfg.PistonMaxConcurrency,
+				cfg.PistonMaxQueueDepth,
+			),
+		)
+	})
+
+	return testPistonClient
+}
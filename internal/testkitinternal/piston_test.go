@@ -0,0 +1,16 @@
+package testkitinternal_test
+
+import (
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/internal/testkitinternal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPistonClient(t *testing.T) {
+	t.Parallel()
+
+	client := testkitinternal.GetPistonClient()
+	require.NotNil(t, client)
+	require.Same(t, client, testkitinternal.GetPistonClient())
+}
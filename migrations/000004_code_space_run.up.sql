@@ -0,0 +1,23 @@
+Create TABLE code_space_run (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    user_uuid UUID NULL REFERENCES "user"(uuid) ON DELETE SET NULL,
+    contents TEXT NOT NULL,
+    language VARCHAR(150) NOT NULL,
+    version VARCHAR(150) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    compile_stdout TEXT NULL,
+    compile_stderr TEXT NULL,
+    compile_code INT NULL,
+    compile_signal VARCHAR(20) NULL,
+    run_stdout TEXT NULL,
+    run_stderr TEXT NULL,
+    run_code INT NULL,
+    run_signal VARCHAR(20) NULL,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX code_space_run_code_space_id_created_at_idx ON code_space_run (code_space_id, created_at DESC);
@@ -0,0 +1,12 @@
+Create TABLE code_space_file (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    contents TEXT NOT NULL,
+    is_entry_point BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    UNIQUE (code_space_id, name)
+);
+
+CREATE UNIQUE INDEX code_space_file_code_space_id_entry_point_idx ON code_space_file (code_space_id) WHERE is_entry_point;
@@ -0,0 +1 @@
+ALTER TABLE code_space DROP COLUMN IF EXISTS language_version;
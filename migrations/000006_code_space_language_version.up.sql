@@ -0,0 +1 @@
+ALTER TABLE code_space ADD COLUMN language_version VARCHAR(32) NULL;
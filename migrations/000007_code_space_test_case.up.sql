@@ -0,0 +1,13 @@
+CREATE TABLE code_space_test_case (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    stdin TEXT NULL,
+    args TEXT[] NOT NULL DEFAULT '{}',
+    expected_stdout TEXT NOT NULL,
+    comparison_mode VARCHAR(16) NOT NULL CHECK (comparison_mode IN ('exact', 'trimmed', 'regex')),
+    is_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX code_space_test_case_code_space_id_idx ON code_space_test_case (code_space_id);
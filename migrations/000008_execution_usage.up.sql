@@ -0,0 +1,11 @@
+CREATE TABLE execution_usage (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_uuid UUID NOT NULL REFERENCES "user"(uuid) ON DELETE CASCADE,
+    api_key_id INT NULL REFERENCES api_key(id) ON DELETE SET NULL,
+    cpu_milliseconds BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX execution_usage_user_uuid_created_at_idx ON execution_usage (user_uuid, created_at);
+CREATE INDEX execution_usage_api_key_id_created_at_idx ON execution_usage (api_key_id, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS from_cache;
+DROP TABLE IF EXISTS execution_cache;
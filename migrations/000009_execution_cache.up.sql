@@ -0,0 +1,11 @@
+CREATE TABLE execution_cache (
+    key CHAR(64) PRIMARY KEY,
+    response JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX execution_cache_updated_at_idx ON execution_cache (updated_at DESC);
+
+ALTER TABLE code_space_run ADD COLUMN from_cache BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,14 @@
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_memory_limit_exceeded;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_timed_out;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_memory;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_wall_time;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_cpu_time;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_status;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS run_message;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_memory_limit_exceeded;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_timed_out;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_memory;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_wall_time;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_cpu_time;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_status;
+ALTER TABLE code_space_run DROP COLUMN IF EXISTS compile_message;
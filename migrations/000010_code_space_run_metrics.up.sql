@@ -0,0 +1,14 @@
+ALTER TABLE code_space_run ADD COLUMN compile_message TEXT NULL;
+ALTER TABLE code_space_run ADD COLUMN compile_status VARCHAR(20) NULL;
+ALTER TABLE code_space_run ADD COLUMN compile_cpu_time BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN compile_wall_time BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN compile_memory BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN compile_timed_out BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE code_space_run ADD COLUMN compile_memory_limit_exceeded BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE code_space_run ADD COLUMN run_message TEXT NULL;
+ALTER TABLE code_space_run ADD COLUMN run_status VARCHAR(20) NULL;
+ALTER TABLE code_space_run ADD COLUMN run_cpu_time BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN run_wall_time BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN run_memory BIGINT NULL;
+ALTER TABLE code_space_run ADD COLUMN run_timed_out BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE code_space_run ADD COLUMN run_memory_limit_exceeded BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS code_space_run_config;
@@ -0,0 +1,15 @@
+CREATE TABLE code_space_run_config (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    stdin TEXT NULL,
+    args TEXT[] NOT NULL DEFAULT '{}',
+    compile_timeout BIGINT NULL,
+    run_timeout BIGINT NULL,
+    compile_memory_limit BIGINT NULL,
+    run_memory_limit BIGINT NULL,
+    language_version VARCHAR(32) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    UNIQUE (code_space_id, name)
+);
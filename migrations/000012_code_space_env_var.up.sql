@@ -0,0 +1,10 @@
+CREATE TABLE code_space_env_var (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    value TEXT NOT NULL,
+    is_secret BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    UNIQUE (code_space_id, name)
+);
@@ -0,0 +1 @@
+ALTER TABLE code_space ADD COLUMN deleted_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE code_space ADD COLUMN forked_from_id INT NULL REFERENCES code_space(id) ON DELETE SET NULL;
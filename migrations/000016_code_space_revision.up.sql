@@ -0,0 +1,10 @@
+CREATE TABLE code_space_revision (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    code_space_id INT NOT NULL REFERENCES code_space(id) ON DELETE CASCADE,
+    author_uuid UUID NULL REFERENCES "user"(uuid) ON DELETE SET NULL,
+    contents TEXT NOT NULL,
+    contents_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX code_space_revision_code_space_id_created_at_idx ON code_space_revision (code_space_id, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE code_space ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS code_space_alias_claim_name ON code_space_alias;
+DROP TRIGGER IF EXISTS code_space_claim_name ON code_space;
+DROP FUNCTION IF EXISTS claim_code_space_name;
+DROP TABLE IF EXISTS code_space_name;
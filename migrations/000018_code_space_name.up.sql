@@ -0,0 +1,30 @@
+CREATE TABLE code_space_name (
+    name VARCHAR(150) PRIMARY KEY
+);
+
+DELETE FROM code_space_alias s USING code_space c WHERE s.name = c.name;
+
+INSERT INTO code_space_name (name) SELECT c.name FROM code_space c;
+INSERT INTO code_space_name (name) SELECT s.name FROM code_space_alias s;
+
+CREATE FUNCTION claim_code_space_name() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM code_space_name WHERE name = OLD.name;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO code_space_name (name) VALUES (NEW.name);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER code_space_claim_name
+AFTER INSERT OR DELETE OR UPDATE OF name ON code_space
+FOR EACH ROW EXECUTE FUNCTION claim_code_space_name();
+
+CREATE TRIGGER code_space_alias_claim_name
+AFTER INSERT OR DELETE OR UPDATE OF name ON code_space_alias
+FOR EACH ROW EXECUTE FUNCTION claim_code_space_name();
@@ -7,6 +7,15 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/validate"
 )
 
+const (
+	// ExecutionQuotaLimitRunsPerMinute limits the number of code executions in each minute.
+	ExecutionQuotaLimitRunsPerMinute = "runs_per_minute"
+	// ExecutionQuotaLimitRunsPerDay limits the number of code executions in each day.
+	ExecutionQuotaLimitRunsPerDay = "runs_per_day"
+	// ExecutionQuotaLimitCPUMillisecondsPerDay limits the CPU time used executing code in each day.
+	ExecutionQuotaLimitCPUMillisecondsPerDay = "cpu_milliseconds_per_day"
+)
+
 // CreateUserRequest represents the request body for user creation requests.
 type CreateUserRequest struct {
 	Email     string `json:"email"`
@@ -89,6 +98,29 @@ type UpdateUserResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ExecutionQuotaUsageResponse represents the consumption against a single execution quota limit.
+// Max is nil when the limit is disabled.
+type ExecutionQuotaUsageResponse struct {
+	Limit   string    `json:"limit"`
+	Used    int64     `json:"used"`
+	Max     *int64    `json:"max"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// APIKeyUsageResponse represents the execution quota consumption of an API key.
+type APIKeyUsageResponse struct {
+	ID     int64                          `json:"id"`
+	Prefix string                         `json:"prefix"`
+	Name   string                         `json:"name"`
+	Quotas []*ExecutionQuotaUsageResponse `json:"quotas"`
+}
+
+// GetUserUsageResponse represents the response body for get current user usage requests.
+type GetUserUsageResponse struct {
+	Quotas  []*ExecutionQuotaUsageResponse `json:"quotas"`
+	APIKeys []*APIKeyUsageResponse         `json:"api_keys"`
+}
+
 // CreateTokenRequest represents the request body for create token requests.
 type CreateTokenRequest struct {
 	Email    string `json:"email"`
@@ -6,17 +6,121 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/validate"
 )
 
-// SupportedCodingLanguages is the list of supported coding languages.
-var SupportedCodingLanguages = []string{
-	PistonLanguageC,
-	PistonLanguageCPlusPlus,
-	PistonLanguageGo,
-	PistonLanguageJava,
-	PistonLanguageJavaScript,
-	PistonLanguagePython,
-	PistonLanguageRust,
-	PistonLanguageTypeScript,
-}
+const (
+	// RunCodeSpaceStdinMaxLength is the maximum length of standard input for code space runs.
+	RunCodeSpaceStdinMaxLength = 65536
+	// RunCodeSpaceArgsMaxCount is the maximum number of command-line arguments for code space runs.
+	RunCodeSpaceArgsMaxCount = 64
+	// RunCodeSpaceArgMaxLength is the maximum length of a single command-line argument for code space runs.
+	RunCodeSpaceArgMaxLength = 1024
+	// ListCodeSpaceRunsDefaultLimit is the default number of code space runs returned per page.
+	ListCodeSpaceRunsDefaultLimit = 20
+	// ListCodeSpaceRunsMaxLimit is the maximum number of code space runs returned per page.
+	ListCodeSpaceRunsMaxLimit = 100
+	// ListCodeSpaceRevisionsDefaultLimit is the default number of code space revisions returned per page.
+	ListCodeSpaceRevisionsDefaultLimit = 20
+	// ListCodeSpaceRevisionsMaxLimit is the maximum number of code space revisions returned per page.
+	ListCodeSpaceRevisionsMaxLimit = 100
+	// CodeSpaceFileNameMaxLength is the maximum length of code space file names.
+	CodeSpaceFileNameMaxLength = 255
+	// CodeSpaceFilesMaxCount is the maximum number of files in a code space, excluding its main file.
+	CodeSpaceFilesMaxCount = 32
+	// CodeSpaceLanguageVersionMaxLength is the maximum length of code space language versions.
+	CodeSpaceLanguageVersionMaxLength = 32
+	// RunCodeSpaceMatrixMaxVersions is the maximum number of language versions in a code space matrix run.
+	RunCodeSpaceMatrixMaxVersions = 8
+	// BenchmarkCodeSpaceMaxIterations is the maximum number of runs in a code space benchmark.
+	BenchmarkCodeSpaceMaxIterations = 50
+	// CodeSpaceTestCasesMaxCount is the maximum number of test cases in a code space.
+	CodeSpaceTestCasesMaxCount = 32
+	// CodeSpaceTestCaseExpectedStdoutMaxLength is the maximum length of expected standard output for test cases.
+	CodeSpaceTestCaseExpectedStdoutMaxLength = 65536
+	// CodeSpaceRunConfigsMaxCount is the maximum number of run configurations in a code space.
+	CodeSpaceRunConfigsMaxCount = 32
+	// CodeSpaceRunConfigNameMaxLength is the maximum length of code space run configuration names.
+	CodeSpaceRunConfigNameMaxLength = 64
+	// CodeSpaceEnvVarsMaxCount is the maximum number of environment variables in a code space.
+	CodeSpaceEnvVarsMaxCount = 32
+	// CodeSpaceEnvVarNameMaxLength is the maximum length of code space environment variable names.
+	CodeSpaceEnvVarNameMaxLength = 64
+	// CodeSpaceEnvVarValueMaxLength is the maximum length of code space environment variable values.
+	CodeSpaceEnvVarValueMaxLength = 4096
+	// CodeSpaceNameMinLength is the minimum length of custom code space names.
+	CodeSpaceNameMinLength = 3
+	// CodeSpaceNameMaxLength is the maximum length of custom code space names.
+	CodeSpaceNameMaxLength = 64
+	// CodeSpaceEditComponentsMaxCount is the maximum number of components in a collaborative code space edit.
+	CodeSpaceEditComponentsMaxCount = 1024
+	// CodeSpaceEditInsertMaxLength is the maximum length of text inserted by a collaborative code space edit component.
+	CodeSpaceEditInsertMaxLength = 65536
+)
+
+const (
+	// CodeSpaceTestComparisonModeExact represents test cases whose output must match exactly.
+	CodeSpaceTestComparisonModeExact = "exact"
+	// CodeSpaceTestComparisonModeTrimmed represents test cases whose output must match,
+	// ignoring leading and trailing whitespace.
+	CodeSpaceTestComparisonModeTrimmed = "trimmed"
+	// CodeSpaceTestComparisonModeRegex represents test cases whose output must match a regular expression.
+	CodeSpaceTestComparisonModeRegex = "regex"
+)
+
+const (
+	// CodeSpaceRunStatusQueued represents code space runs waiting to be executed.
+	CodeSpaceRunStatusQueued = "queued"
+	// CodeSpaceRunStatusRunning represents code space runs being executed.
+	CodeSpaceRunStatusRunning = "running"
+	// CodeSpaceRunStatusCompleted represents code space runs that finished executing.
+	CodeSpaceRunStatusCompleted = "completed"
+	// CodeSpaceRunStatusFailed represents code space runs that could not be executed.
+	CodeSpaceRunStatusFailed = "failed"
+	// CodeSpaceRunStatusCancelled represents code space runs that were cancelled before they finished executing.
+	CodeSpaceRunStatusCancelled = "cancelled"
+)
+
+const (
+	// CodeSpaceRunEventTypeExit represents the final event of a streamed code space run that finished executing.
+	CodeSpaceRunEventTypeExit = "exit"
+	// CodeSpaceRunEventTypeError represents the final event of a streamed code space run that could not be executed.
+	CodeSpaceRunEventTypeError = "error"
+)
+
+const (
+	// InteractiveCodeSpaceRunMessageTypeStdin represents messages carrying standard input for interactive runs.
+	InteractiveCodeSpaceRunMessageTypeStdin = "stdin"
+	// InteractiveCodeSpaceRunMessageTypeSignal represents messages sending a signal to interactive runs.
+	InteractiveCodeSpaceRunMessageTypeSignal = "signal"
+)
+
+// InteractiveCodeSpaceRunSignals are the signals that can be sent to interactive code space runs.
+var InteractiveCodeSpaceRunSignals = []string{"SIGINT", "SIGTERM", "SIGKILL"}
+
+const (
+	// CodeSpaceCollabMessageTypeEdit represents messages carrying edits to collaboratively edited code spaces.
+	CodeSpaceCollabMessageTypeEdit = "edit"
+	// CodeSpaceCollabMessageTypeCursor represents messages carrying the cursor and selection of a collaborator.
+	CodeSpaceCollabMessageTypeCursor = "cursor"
+	// CodeSpaceCollabMessageTypeHeartbeat represents messages telling that a collaborator is still connected.
+	CodeSpaceCollabMessageTypeHeartbeat = "heartbeat"
+)
+
+const (
+	// CodeSpaceCollabEventTypeSnapshot represents the first event of a collaborative editing session,
+	// carrying the whole code space.
+	CodeSpaceCollabEventTypeSnapshot = "snapshot"
+	// CodeSpaceCollabEventTypeEdit represents events carrying edits made by other collaborators.
+	CodeSpaceCollabEventTypeEdit = "edit"
+	// CodeSpaceCollabEventTypeAck represents events confirming that an edit was applied.
+	CodeSpaceCollabEventTypeAck = "ack"
+	// CodeSpaceCollabEventTypeError represents events reporting that a message could not be handled.
+	CodeSpaceCollabEventTypeError = "error"
+	// CodeSpaceCollabEventTypeJoin represents events carrying the presence of a collaborator that joined.
+	CodeSpaceCollabEventTypeJoin = "join"
+	// CodeSpaceCollabEventTypeLeave represents events carrying the presence of a collaborator that left or timed out.
+	CodeSpaceCollabEventTypeLeave = "leave"
+	// CodeSpaceCollabEventTypeCursor represents events carrying the presence of a collaborator that moved its cursor.
+	CodeSpaceCollabEventTypeCursor = "cursor"
+)
 
 const (
 	// CodeSpaceAccessLevelReadOnly represents read-only access on code spaces.
@@ -25,41 +129,79 @@ const (
 	CodeSpaceAccessLevelReadWrite = "W"
 )
 
+// GetCodingLanguageResponse represents the response body for a single coding language
+// in coding language retrieval requests.
+type GetCodingLanguageResponse struct {
+	Language string   `json:"language"`
+	Versions []string `json:"versions"`
+	Aliases  []string `json:"aliases"`
+}
+
+// ListCodingLanguagesResponse represents the response body for coding language retrieval requests.
+type ListCodingLanguagesResponse struct {
+	Languages []*GetCodingLanguageResponse `json:"languages"`
+}
+
 // CreateCodeSpaceRequest represents the request body for code space creation requests.
+// A name is generated for the code space if none is given.
 type CreateCodeSpaceRequest struct {
-	Language string `json:"language"`
+	Name            *string `json:"name"`
+	Language        string  `json:"language"`
+	LanguageVersion *string `json:"language_version"`
+}
+
+// validateCodeSpaceName validates a custom code space name.
+func validateCodeSpaceName(v *validate.Validator, name string) {
+	v.ValidateStringSlug("name", name)
+	v.ValidateStringMinLength("name", name, CodeSpaceNameMinLength)
+	v.ValidateStringMaxLength("name", name, CodeSpaceNameMaxLength)
 }
 
 // Validate validates fields in CreateCodeSpaceRequest.
-func (r *CreateCodeSpaceRequest) Validate() (bool, map[string][]string) {
+// Supported languages include the names and aliases of the runtimes installed on Piston.
+func (r *CreateCodeSpaceRequest) Validate(supportedLanguages []string) (bool, map[string][]string) {
 	v := validate.NewValidator()
-	v.ValidateStringOptions("language", r.Language, SupportedCodingLanguages, false)
+	if r.Name != nil {
+		validateCodeSpaceName(v, *r.Name)
+	}
+	v.ValidateStringOptions("language", r.Language, supportedLanguages, false)
+	if r.LanguageVersion != nil {
+		v.ValidateStringNotBlank("language_version", *r.LanguageVersion)
+		v.ValidateStringMaxLength("language_version", *r.LanguageVersion, CodeSpaceLanguageVersionMaxLength)
+	}
 
 	return v.Passed(), v.Failures()
 }
 
 // CreateCodeSpaceResponse represents the response body for code space creation requests.
 type CreateCodeSpaceResponse struct {
-	ID          int64     `json:"id"`
-	AuthorUUID  *string   `json:"author_uuid"`
-	Name        string    `json:"name"`
-	Language    string    `json:"language"`
-	Contents    string    `json:"contents"`
-	AccessLevel string    `json:"access_level"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // GetCodeSpaceResponse represents the response body for a single code space in code space retrieval requests.
+// Forked code spaces include the ID of the code space they were forked from.
+// The version is incremented whenever the code space is updated or renamed.
 type GetCodeSpaceResponse struct {
-	ID          int64     `json:"id"`
-	AuthorUUID  *string   `json:"author_uuid"`
-	Name        string    `json:"name"`
-	Language    string    `json:"language"`
-	Contents    string    `json:"contents"`
-	AccessLevel string    `json:"access_level"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	ForkedFromID    *int64    `json:"forked_from_id"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // ListCodeSpacesResponse represents the response body for code space retrieval requests.
@@ -68,41 +210,1052 @@ type ListCodeSpacesResponse struct {
 }
 
 // UpdateCodeSpaceRequest represents the request body for code space update requests.
+// An empty language version unpins the code space so it runs on the latest installed version of its language.
+// Renamed code spaces can still be found by their previous names.
+// If a version is given, the code space is only updated if it is still at that version.
 type UpdateCodeSpaceRequest struct {
-	Contents *string `json:"contents"`
+	Name            *string `json:"name"`
+	Contents        *string `json:"contents"`
+	LanguageVersion *string `json:"language_version"`
+	Version         *int64  `json:"version"`
 }
 
 // Validate validates fields in UpdateCodeSpaceRequest.
 func (r *UpdateCodeSpaceRequest) Validate() (bool, map[string][]string) {
 	v := validate.NewValidator()
+	if r.Name != nil {
+		validateCodeSpaceName(v, *r.Name)
+	}
+	if r.LanguageVersion != nil {
+		v.ValidateStringMaxLength("language_version", *r.LanguageVersion, CodeSpaceLanguageVersionMaxLength)
+	}
+	if r.Version != nil {
+		v.ValidateInt64MinValue("version", *r.Version, 1)
+	}
 
 	return v.Passed(), v.Failures()
 }
 
 // UpdateCodeSpaceResponse represents the response body for code space update requests.
 type UpdateCodeSpaceResponse struct {
-	ID          int64     `json:"id"`
-	AuthorUUID  *string   `json:"author_uuid"`
-	Name        string    `json:"name"`
-	Language    string    `json:"language"`
-	Contents    string    `json:"contents"`
-	AccessLevel string    `json:"access_level"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GetTrashedCodeSpaceResponse represents the response body for trashed code space retrieval requests.
+type GetTrashedCodeSpaceResponse struct {
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	AccessLevel     string    `json:"access_level"`
+	DeletedAt       time.Time `json:"deleted_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ListTrashedCodeSpacesResponse represents the response body for trashed code space retrieval requests.
+type ListTrashedCodeSpacesResponse struct {
+	CodeSpaces []*GetTrashedCodeSpaceResponse `json:"code_spaces"`
+}
+
+// RestoreCodeSpaceResponse represents the response body for code space restore requests.
+type RestoreCodeSpaceResponse struct {
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ListCodeSpaceRevisionsRequest represents the query parameters for code space revision history requests.
+type ListCodeSpaceRevisionsRequest struct {
+	Limit  int64
+	Offset int64
+}
+
+// Validate validates fields in ListCodeSpaceRevisionsRequest.
+func (r *ListCodeSpaceRevisionsRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateInt64MinValue("limit", r.Limit, 1)
+	v.ValidateInt64MaxValue("limit", r.Limit, ListCodeSpaceRevisionsMaxLimit)
+	v.ValidateInt64MinValue("offset", r.Offset, 0)
+
+	return v.Passed(), v.Failures()
+}
+
+// GetCodeSpaceRevisionResponse represents the response body for code space revision retrieval requests.
+// Contents are those of the main file of the code space, since revisions do not cover its other files.
+type GetCodeSpaceRevisionResponse struct {
+	ID           int64     `json:"id"`
+	CodeSpaceID  int64     `json:"code_space_id"`
+	AuthorUUID   *string   `json:"author_uuid"`
+	Contents     string    `json:"contents"`
+	ContentsHash string    `json:"contents_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ListCodeSpaceRevisionsResponse represents the response body for code space revision history requests.
+type ListCodeSpaceRevisionsResponse struct {
+	Revisions []*GetCodeSpaceRevisionResponse `json:"revisions"`
+	Limit     int64                           `json:"limit"`
+	Offset    int64                           `json:"offset"`
+}
+
+// DiffCodeSpaceRevisionsRequest represents the query parameters for code space revision diff requests.
+type DiffCodeSpaceRevisionsRequest struct {
+	From int64
+	To   int64
+}
+
+// Validate validates fields in DiffCodeSpaceRevisionsRequest.
+func (r *DiffCodeSpaceRevisionsRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateInt64MinValue("from", r.From, 1)
+	v.ValidateInt64MinValue("to", r.To, 1)
+
+	return v.Passed(), v.Failures()
+}
+
+// DiffCodeSpaceRevisionsResponse represents the response body for code space revision diff requests.
+// Diff is a unified diff, and is empty when both revisions have the same contents.
+type DiffCodeSpaceRevisionsResponse struct {
+	FromRevisionID int64  `json:"from_revision_id"`
+	ToRevisionID   int64  `json:"to_revision_id"`
+	Diff           string `json:"diff"`
+}
+
+// RestoreCodeSpaceRevisionRequest represents the optional request body for code space revision restore requests.
+// If a version is given, the code space is only restored if it is still at that version.
+type RestoreCodeSpaceRevisionRequest struct {
+	Version *int64 `json:"version"`
+}
+
+// Validate validates fields in RestoreCodeSpaceRevisionRequest.
+func (r *RestoreCodeSpaceRevisionRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	if r.Version != nil {
+		v.ValidateInt64MinValue("version", *r.Version, 1)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// RestoreCodeSpaceRevisionResponse represents the response body for code space revision restore requests.
+// Only the contents of the main file are restored, and the other files of the code space are left as they are.
+type RestoreCodeSpaceRevisionResponse struct {
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ForkCodeSpaceResponse represents the response body for code space fork requests.
+type ForkCodeSpaceResponse struct {
+	ID              int64     `json:"id"`
+	AuthorUUID      *string   `json:"author_uuid"`
+	Name            string    `json:"name"`
+	Language        string    `json:"language"`
+	LanguageVersion *string   `json:"language_version"`
+	Contents        string    `json:"contents"`
+	ForkedFromID    *int64    `json:"forked_from_id"`
+	Version         int64     `json:"version"`
+	AccessLevel     string    `json:"access_level"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// RunCodeSpaceRequest represents the request body for code space run requests.
+type RunCodeSpaceRequest struct {
+	Stdin              *string  `json:"stdin"`
+	Args               []string `json:"args"`
+	CompileTimeout     *int64   `json:"compile_timeout"`
+	RunTimeout         *int64   `json:"run_timeout"`
+	CompileMemoryLimit *int64   `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64   `json:"run_memory_limit"`
+	Async              bool     `json:"async"`
+	NoCache            bool     `json:"no_cache"`
+	// RunConfig is the name of a saved run configuration whose values are used for the fields that are not set.
+	RunConfig *string `json:"run_config"`
+}
+
+// Validate validates fields in RunCodeSpaceRequest.
+func (r *RunCodeSpaceRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateRunCodeSpaceOptions(
+		v,
+		r.Stdin,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	if r.RunConfig != nil {
+		v.ValidateStringMaxLength("run_config", *r.RunConfig, CodeSpaceRunConfigNameMaxLength)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// validateRunCodeSpaceOptions validates the execution options shared by code space run requests.
+func validateRunCodeSpaceOptions(
+	v *validate.Validator,
+	stdin *string,
+	args []string,
+	compileTimeout *int64,
+	runTimeout *int64,
+	compileMemoryLimit *int64,
+	runMemoryLimit *int64,
+) {
+	if stdin != nil {
+		v.ValidateStringMaxLength("stdin", *stdin, RunCodeSpaceStdinMaxLength)
+	}
+
+	v.ValidateInt64MaxValue("args", int64(len(args)), RunCodeSpaceArgsMaxCount)
+	for _, arg := range args {
+		v.ValidateStringMaxLength("args", arg, RunCodeSpaceArgMaxLength)
+	}
+
+	if compileTimeout != nil {
+		v.ValidateInt64MinValue("compile_timeout", *compileTimeout, 1)
+	}
+
+	if runTimeout != nil {
+		v.ValidateInt64MinValue("run_timeout", *runTimeout, 1)
+	}
+
+	if compileMemoryLimit != nil {
+		v.ValidateInt64MinValue("compile_memory_limit", *compileMemoryLimit, 1)
+	}
+
+	if runMemoryLimit != nil {
+		v.ValidateInt64MinValue("run_memory_limit", *runMemoryLimit, 1)
+	}
+}
+
+// RunCodeSpaceMatrixRequest represents the request body for code space matrix run requests.
+type RunCodeSpaceMatrixRequest struct {
+	Versions           []string `json:"versions"`
+	Stdin              *string  `json:"stdin"`
+	Args               []string `json:"args"`
+	CompileTimeout     *int64   `json:"compile_timeout"`
+	RunTimeout         *int64   `json:"run_timeout"`
+	CompileMemoryLimit *int64   `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64   `json:"run_memory_limit"`
+	NoCache            bool     `json:"no_cache"`
+}
+
+// Validate validates fields in RunCodeSpaceMatrixRequest.
+func (r *RunCodeSpaceMatrixRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateInt64MinValue("versions", int64(len(r.Versions)), 1)
+	v.ValidateInt64MaxValue("versions", int64(len(r.Versions)), RunCodeSpaceMatrixMaxVersions)
+	for _, version := range r.Versions {
+		v.ValidateStringNotBlank("versions", version)
+		v.ValidateStringMaxLength("versions", version, CodeSpaceLanguageVersionMaxLength)
+	}
+
+	v.ValidateStringsUnique("versions", r.Versions)
+
+	validateRunCodeSpaceOptions(
+		v,
+		r.Stdin,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	return v.Passed(), v.Failures()
+}
+
+// BenchmarkCodeSpaceRequest represents the request body for code space benchmark requests.
+type BenchmarkCodeSpaceRequest struct {
+	Iterations         int64    `json:"iterations"`
+	Stdin              *string  `json:"stdin"`
+	Args               []string `json:"args"`
+	CompileTimeout     *int64   `json:"compile_timeout"`
+	RunTimeout         *int64   `json:"run_timeout"`
+	CompileMemoryLimit *int64   `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64   `json:"run_memory_limit"`
+}
+
+// Validate validates fields in BenchmarkCodeSpaceRequest.
+func (r *BenchmarkCodeSpaceRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateInt64MinValue("iterations", r.Iterations, 1)
+	v.ValidateInt64MaxValue("iterations", r.Iterations, BenchmarkCodeSpaceMaxIterations)
+
+	validateRunCodeSpaceOptions(
+		v,
+		r.Stdin,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	return v.Passed(), v.Failures()
+}
+
+// InteractiveCodeSpaceRunRequest represents the query parameters for interactive code space run requests.
+type InteractiveCodeSpaceRunRequest struct {
+	Args               []string
+	CompileTimeout     *int64
+	RunTimeout         *int64
+	CompileMemoryLimit *int64
+	RunMemoryLimit     *int64
+}
+
+// Validate validates fields in InteractiveCodeSpaceRunRequest.
+func (r *InteractiveCodeSpaceRunRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateRunCodeSpaceOptions(
+		v,
+		nil,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	return v.Passed(), v.Failures()
+}
+
+// InteractiveCodeSpaceRunMessage represents messages sent by clients during interactive code space runs.
+// Data is only set for standard input messages, and Signal is only set for signal messages.
+type InteractiveCodeSpaceRunMessage struct {
+	Type   string  `json:"type"`
+	Data   *string `json:"data"`
+	Signal *string `json:"signal"`
+}
+
+// Validate validates fields in InteractiveCodeSpaceRunMessage.
+func (m *InteractiveCodeSpaceRunMessage) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateStringOptions(
+		"type",
+		m.Type,
+		[]string{InteractiveCodeSpaceRunMessageTypeStdin, InteractiveCodeSpaceRunMessageTypeSignal},
+		true,
+	)
+
+	switch m.Type {
+	case InteractiveCodeSpaceRunMessageTypeStdin:
+		data := ""
+		if m.Data != nil {
+			data = *m.Data
+		}
+
+		v.ValidateStringMinLength("data", data, 1)
+		v.ValidateStringMaxLength("data", data, RunCodeSpaceStdinMaxLength)
+	case InteractiveCodeSpaceRunMessageTypeSignal:
+		signal := ""
+		if m.Signal != nil {
+			signal = *m.Signal
+		}
+
+		v.ValidateStringOptions("signal", signal, InteractiveCodeSpaceRunSignals, true)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// InteractiveCodeSpaceRunEvent represents messages sent to clients during interactive code space runs.
+// Event is one of the Piston event types, CodeSpaceRunEventTypeExit or CodeSpaceRunEventTypeError,
+// and Data is the corresponding PistonEvent, RunCodeSpaceResponse or ErrorResponse.
+type InteractiveCodeSpaceRunEvent struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+}
+
+// CodeSpaceEditComponent represents a single step of a collaborative code space edit.
+// Exactly one of Retain, Insert and Delete is set, and lengths are counted in Unicode code points.
+type CodeSpaceEditComponent struct {
+	Retain *int64  `json:"retain,omitempty"`
+	Insert *string `json:"insert,omitempty"`
+	Delete *int64  `json:"delete,omitempty"`
+}
+
+// CodeSpaceCursor represents the cursor of a collaborator in a collaboratively edited code space.
+// The selection spans from Anchor to Position, and there is no selection if they are equal.
+// Both are counted in Unicode code points.
+type CodeSpaceCursor struct {
+	Position int64 `json:"position"`
+	Anchor   int64 `json:"anchor"`
+}
+
+// CodeSpaceCollabMessage represents messages sent by clients during collaborative editing of code spaces.
+// Operation is only set for edit messages, and walks over the whole code space as of Revision,
+// retaining, inserting or deleting text as it goes.
+// Cursor is only set for cursor messages, and points into the code space as of Revision.
+// Heartbeat messages carry nothing, and only keep the collaborator from timing out.
+type CodeSpaceCollabMessage struct {
+	Type      string                    `json:"type"`
+	Revision  int64                     `json:"revision"`
+	Operation []*CodeSpaceEditComponent `json:"operation"`
+	Cursor    *CodeSpaceCursor          `json:"cursor"`
+}
+
+// Validate validates fields in CodeSpaceCollabMessage.
+func (m *CodeSpaceCollabMessage) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateStringOptions(
+		"type",
+		m.Type,
+		[]string{
+			CodeSpaceCollabMessageTypeEdit,
+			CodeSpaceCollabMessageTypeCursor,
+			CodeSpaceCollabMessageTypeHeartbeat,
+		},
+		true,
+	)
+	v.ValidateInt64MinValue("revision", m.Revision, 0)
+
+	switch m.Type {
+	case CodeSpaceCollabMessageTypeEdit:
+		validateCodeSpaceEditComponents(v, m.Operation)
+	case CodeSpaceCollabMessageTypeCursor:
+		v.ValidateExactlyOneSet("cursor", []string{"cursor"}, m.Cursor != nil)
+		if m.Cursor != nil {
+			v.ValidateInt64MinValue("cursor", m.Cursor.Position, 0)
+			v.ValidateInt64MinValue("cursor", m.Cursor.Anchor, 0)
+		}
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// validateCodeSpaceEditComponents validates the components of a collaborative code space edit.
+func validateCodeSpaceEditComponents(v *validate.Validator, operation []*CodeSpaceEditComponent) {
+	v.ValidateInt64MaxValue("operation", int64(len(operation)), CodeSpaceEditComponentsMaxCount)
+	for _, component := range operation {
+		if component == nil {
+			component = &CodeSpaceEditComponent{}
+		}
+
+		v.ValidateExactlyOneSet(
+			"operation",
+			[]string{"retain", "insert", "delete"},
+			component.Retain != nil,
+			component.Insert != nil,
+			component.Delete != nil,
+		)
+
+		if component.Retain != nil {
+			v.ValidateInt64MinValue("operation", *component.Retain, 1)
+		}
+
+		if component.Insert != nil {
+			v.ValidateStringMinLength("operation", *component.Insert, 1)
+			v.ValidateStringMaxLength("operation", *component.Insert, CodeSpaceEditInsertMaxLength)
+		}
+
+		if component.Delete != nil {
+			v.ValidateInt64MinValue("operation", *component.Delete, 1)
+		}
+	}
+}
+
+// CodeSpaceCollabEvent represents messages sent to clients during collaborative editing of code spaces.
+// Event is one of the CodeSpaceCollabEventType* values,
+// and Data is the corresponding CodeSpaceCollabSnapshotResponse, CodeSpaceCollabEditResponse,
+// CodeSpaceCollabAckResponse, ErrorResponse, or GetCodeSpaceCollaboratorResponse for join, leave and cursor events.
+type CodeSpaceCollabEvent struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+}
+
+// GetCodeSpaceCollaboratorResponse represents the presence of a single session
+// of a collaborator connected to a collaboratively edited code space.
+type GetCodeSpaceCollaboratorResponse struct {
+	SessionID   string           `json:"session_id"`
+	UserUUID    string           `json:"user_uuid"`
+	FirstName   string           `json:"first_name"`
+	LastName    string           `json:"last_name"`
+	AccessLevel string           `json:"access_level"`
+	Cursor      *CodeSpaceCursor `json:"cursor"`
+	JoinedAt    time.Time        `json:"joined_at"`
+	LastSeenAt  time.Time        `json:"last_seen_at"`
+}
+
+// ListCodeSpaceCollaboratorsResponse represents the response body for code space presence requests.
+type ListCodeSpaceCollaboratorsResponse struct {
+	Collaborators []*GetCodeSpaceCollaboratorResponse `json:"collaborators"`
+}
+
+// CodeSpaceCollabSnapshotResponse represents the whole code space as of a given revision
+// at the start of a collaborative editing session,
+// along with the client's own session and the other collaborators already connected.
+type CodeSpaceCollabSnapshotResponse struct {
+	Revision      int64                               `json:"revision"`
+	Contents      string                              `json:"contents"`
+	Session       *GetCodeSpaceCollaboratorResponse   `json:"session"`
+	Collaborators []*GetCodeSpaceCollaboratorResponse `json:"collaborators"`
+}
+
+// CodeSpaceCollabEditResponse represents an edit made by another collaborator,
+// or by a user saving the code space outside of collaborative editing,
+// which brings the code space to a given revision.
+// UserUUID is empty if the user who made the edit is not known.
+type CodeSpaceCollabEditResponse struct {
+	Revision  int64                     `json:"revision"`
+	Operation []*CodeSpaceEditComponent `json:"operation"`
+	UserUUID  string                    `json:"user_uuid"`
+}
+
+// CodeSpaceCollabAckResponse represents the confirmation that the client's edit was applied,
+// bringing the code space to a given revision.
+type CodeSpaceCollabAckResponse struct {
+	Revision int64 `json:"revision"`
 }
 
 // RunCodeSpaceResultsResponse represents code execution results for code space run requests.
+// CPUTime and WallTime are in milliseconds, and Memory is in bytes.
+// TimedOut and MemoryLimitExceeded tell stages killed for exceeding their limits apart from stages that crashed.
 type RunCodeSpaceResultsResponse struct {
-	Stdout string  `json:"stdout"`
-	Stderr string  `json:"stderr"`
-	Code   *int    `json:"code"`
-	Signal *string `json:"signal"`
+	Stdout              string  `json:"stdout"`
+	Stderr              string  `json:"stderr"`
+	Code                *int    `json:"code"`
+	Signal              *string `json:"signal"`
+	Message             *string `json:"message"`
+	Status              *string `json:"status"`
+	CPUTime             *int64  `json:"cpu_time"`
+	WallTime            *int64  `json:"wall_time"`
+	Memory              *int64  `json:"memory"`
+	TimedOut            bool    `json:"timed_out"`
+	MemoryLimitExceeded bool    `json:"memory_limit_exceeded"`
 }
 
 // RunCodeSpaceResponse represents the response body for code space run requests.
 type RunCodeSpaceResponse struct {
-	Compile *RunCodeSpaceResultsResponse `json:"compile"`
-	Run     RunCodeSpaceResultsResponse  `json:"run"`
+	ID        int64                        `json:"id"`
+	Compile   *RunCodeSpaceResultsResponse `json:"compile"`
+	Run       RunCodeSpaceResultsResponse  `json:"run"`
+	FromCache bool                         `json:"from_cache"`
+}
+
+// RunCodeSpaceMatrixResponse represents the response body for code space matrix run requests.
+// Results are keyed by language version.
+type RunCodeSpaceMatrixResponse struct {
+	Results map[string]*RunCodeSpaceResponse `json:"results"`
+}
+
+// BenchmarkCodeSpaceStatsResponse represents statistics of a single measurement across code space benchmark runs.
+type BenchmarkCodeSpaceStatsResponse struct {
+	Min    int64 `json:"min"`
+	Median int64 `json:"median"`
+	P95    int64 `json:"p95"`
+	Max    int64 `json:"max"`
+}
+
+// BenchmarkCodeSpaceResponse represents the response body for code space benchmark requests.
+// WallTime and CPUTime are in milliseconds, and PeakMemory is in bytes.
+// Statistics only cover successful runs, and are null when there are none.
+type BenchmarkCodeSpaceResponse struct {
+	Iterations   int64                            `json:"iterations"`
+	StoppedEarly bool                             `json:"stopped_early"`
+	WallTime     *BenchmarkCodeSpaceStatsResponse `json:"wall_time"`
+	CPUTime      *BenchmarkCodeSpaceStatsResponse `json:"cpu_time"`
+	PeakMemory   *int64                           `json:"peak_memory"`
+	Runs         []*RunCodeSpaceResponse          `json:"runs"`
+}
+
+// StartCodeSpaceRunResponse represents the response body for asynchronous code space run requests.
+type StartCodeSpaceRunResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// ListCodeSpaceRunsRequest represents the query parameters for code space run history requests.
+type ListCodeSpaceRunsRequest struct {
+	Limit  int64
+	Offset int64
+}
+
+// Validate validates fields in ListCodeSpaceRunsRequest.
+func (r *ListCodeSpaceRunsRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateInt64MinValue("limit", r.Limit, 1)
+	v.ValidateInt64MaxValue("limit", r.Limit, ListCodeSpaceRunsMaxLimit)
+	v.ValidateInt64MinValue("offset", r.Offset, 0)
+
+	return v.Passed(), v.Failures()
+}
+
+// GetCodeSpaceRunResponse represents the response body for code space run retrieval requests.
+type GetCodeSpaceRunResponse struct {
+	ID          int64                        `json:"id"`
+	CodeSpaceID int64                        `json:"code_space_id"`
+	UserUUID    *string                      `json:"user_uuid"`
+	Contents    string                       `json:"contents"`
+	Language    string                       `json:"language"`
+	Version     string                       `json:"version"`
+	Status      string                       `json:"status"`
+	Compile     *RunCodeSpaceResultsResponse `json:"compile"`
+	Run         *RunCodeSpaceResultsResponse `json:"run"`
+	StartedAt   *time.Time                   `json:"started_at"`
+	FinishedAt  *time.Time                   `json:"finished_at"`
+	FromCache   bool                         `json:"from_cache"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	UpdatedAt   time.Time                    `json:"updated_at"`
+}
+
+// ListCodeSpaceRunsResponse represents the response body for code space run history requests.
+type ListCodeSpaceRunsResponse struct {
+	Runs   []*GetCodeSpaceRunResponse `json:"runs"`
+	Limit  int64                      `json:"limit"`
+	Offset int64                      `json:"offset"`
+}
+
+// CreateCodeSpaceFileRequest represents the request body for code space file creation requests.
+type CreateCodeSpaceFileRequest struct {
+	Name         string `json:"name"`
+	Contents     string `json:"contents"`
+	IsEntryPoint bool   `json:"is_entry_point"`
+}
+
+// Validate validates fields in CreateCodeSpaceFileRequest.
+func (r *CreateCodeSpaceFileRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	v.ValidateStringMaxLength("name", r.Name, CodeSpaceFileNameMaxLength)
+	v.ValidateStringFilePath("name", r.Name)
+
+	return v.Passed(), v.Failures()
+}
+
+// CreateCodeSpaceFileResponse represents the response body for code space file creation requests.
+type CreateCodeSpaceFileResponse struct {
+	ID           int64     `json:"id"`
+	CodeSpaceID  int64     `json:"code_space_id"`
+	Name         string    `json:"name"`
+	Contents     string    `json:"contents"`
+	IsEntryPoint bool      `json:"is_entry_point"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// GetCodeSpaceFileResponse represents the response body for a single file in code space file retrieval requests.
+type GetCodeSpaceFileResponse struct {
+	ID           int64     `json:"id"`
+	CodeSpaceID  int64     `json:"code_space_id"`
+	Name         string    `json:"name"`
+	Contents     string    `json:"contents"`
+	IsEntryPoint bool      `json:"is_entry_point"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ListCodeSpaceFilesResponse represents the response body for code space file retrieval requests.
+type ListCodeSpaceFilesResponse struct {
+	Files []*GetCodeSpaceFileResponse `json:"files"`
+}
+
+// UpdateCodeSpaceFileRequest represents the request body for code space file update requests.
+type UpdateCodeSpaceFileRequest struct {
+	Name         *string `json:"name"`
+	Contents     *string `json:"contents"`
+	IsEntryPoint *bool   `json:"is_entry_point"`
+}
+
+// Validate validates fields in UpdateCodeSpaceFileRequest.
+func (r *UpdateCodeSpaceFileRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	if r.Name != nil {
+		v.ValidateStringMaxLength("name", *r.Name, CodeSpaceFileNameMaxLength)
+		v.ValidateStringFilePath("name", *r.Name)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// UpdateCodeSpaceFileResponse represents the response body for code space file update requests.
+type UpdateCodeSpaceFileResponse struct {
+	ID           int64     `json:"id"`
+	CodeSpaceID  int64     `json:"code_space_id"`
+	Name         string    `json:"name"`
+	Contents     string    `json:"contents"`
+	IsEntryPoint bool      `json:"is_entry_point"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreateCodeSpaceTestCaseRequest represents the request body for code space test case creation requests.
+type CreateCodeSpaceTestCaseRequest struct {
+	Stdin          *string  `json:"stdin"`
+	Args           []string `json:"args"`
+	ExpectedStdout string   `json:"expected_stdout"`
+	ComparisonMode string   `json:"comparison_mode"`
+	IsHidden       bool     `json:"is_hidden"`
+}
+
+// Validate validates fields in CreateCodeSpaceTestCaseRequest.
+func (r *CreateCodeSpaceTestCaseRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateCodeSpaceTestCase(v, r.Stdin, r.Args, &r.ExpectedStdout, &r.ComparisonMode)
+
+	return v.Passed(), v.Failures()
+}
+
+// validateCodeSpaceTestCase validates the fields shared by code space test case requests.
+// Patterns are only validated when both the expected standard output and the comparison mode are given.
+func validateCodeSpaceTestCase(
+	v *validate.Validator,
+	stdin *string,
+	args []string,
+	expectedStdout *string,
+	comparisonMode *string,
+) {
+	if stdin != nil {
+		v.ValidateStringMaxLength("stdin", *stdin, RunCodeSpaceStdinMaxLength)
+	}
+
+	v.ValidateInt64MaxValue("args", int64(len(args)), RunCodeSpaceArgsMaxCount)
+	for _, arg := range args {
+		v.ValidateStringMaxLength("args", arg, RunCodeSpaceArgMaxLength)
+	}
+
+	if expectedStdout != nil {
+		v.ValidateStringMaxLength("expected_stdout", *expectedStdout, CodeSpaceTestCaseExpectedStdoutMaxLength)
+	}
+
+	if comparisonMode != nil {
+		v.ValidateStringOptions(
+			"comparison_mode",
+			*comparisonMode,
+			[]string{
+				CodeSpaceTestComparisonModeExact,
+				CodeSpaceTestComparisonModeTrimmed,
+				CodeSpaceTestComparisonModeRegex,
+			},
+			true,
+		)
+	}
+
+	if expectedStdout != nil && comparisonMode != nil && *comparisonMode == CodeSpaceTestComparisonModeRegex {
+		v.ValidateStringRegex("expected_stdout", *expectedStdout)
+	}
+}
+
+// CreateCodeSpaceTestCaseResponse represents the response body for code space test case creation requests.
+type CreateCodeSpaceTestCaseResponse struct {
+	ID             int64     `json:"id"`
+	CodeSpaceID    int64     `json:"code_space_id"`
+	Stdin          *string   `json:"stdin"`
+	Args           []string  `json:"args"`
+	ExpectedStdout string    `json:"expected_stdout"`
+	ComparisonMode string    `json:"comparison_mode"`
+	IsHidden       bool      `json:"is_hidden"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// GetCodeSpaceTestCaseResponse represents the response body for a single test case
+// in code space test case retrieval requests.
+type GetCodeSpaceTestCaseResponse struct {
+	ID             int64     `json:"id"`
+	CodeSpaceID    int64     `json:"code_space_id"`
+	Stdin          *string   `json:"stdin"`
+	Args           []string  `json:"args"`
+	ExpectedStdout string    `json:"expected_stdout"`
+	ComparisonMode string    `json:"comparison_mode"`
+	IsHidden       bool      `json:"is_hidden"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ListCodeSpaceTestCasesResponse represents the response body for code space test case retrieval requests.
+type ListCodeSpaceTestCasesResponse struct {
+	TestCases []*GetCodeSpaceTestCaseResponse `json:"test_cases"`
+}
+
+// UpdateCodeSpaceTestCaseRequest represents the request body for code space test case update requests.
+type UpdateCodeSpaceTestCaseRequest struct {
+	Stdin          *string  `json:"stdin"`
+	Args           []string `json:"args"`
+	ExpectedStdout *string  `json:"expected_stdout"`
+	ComparisonMode *string  `json:"comparison_mode"`
+	IsHidden       *bool    `json:"is_hidden"`
+}
+
+// Validate validates fields in UpdateCodeSpaceTestCaseRequest.
+func (r *UpdateCodeSpaceTestCaseRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateCodeSpaceTestCase(v, r.Stdin, r.Args, r.ExpectedStdout, r.ComparisonMode)
+
+	return v.Passed(), v.Failures()
+}
+
+// UpdateCodeSpaceTestCaseResponse represents the response body for code space test case update requests.
+type UpdateCodeSpaceTestCaseResponse struct {
+	ID             int64     `json:"id"`
+	CodeSpaceID    int64     `json:"code_space_id"`
+	Stdin          *string   `json:"stdin"`
+	Args           []string  `json:"args"`
+	ExpectedStdout string    `json:"expected_stdout"`
+	ComparisonMode string    `json:"comparison_mode"`
+	IsHidden       bool      `json:"is_hidden"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateCodeSpaceRunConfigRequest represents the request body for code space run configuration creation requests.
+type CreateCodeSpaceRunConfigRequest struct {
+	Name               string   `json:"name"`
+	Stdin              *string  `json:"stdin"`
+	Args               []string `json:"args"`
+	CompileTimeout     *int64   `json:"compile_timeout"`
+	RunTimeout         *int64   `json:"run_timeout"`
+	CompileMemoryLimit *int64   `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64   `json:"run_memory_limit"`
+	LanguageVersion    *string  `json:"language_version"`
+}
+
+// Validate validates fields in CreateCodeSpaceRunConfigRequest.
+func (r *CreateCodeSpaceRunConfigRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateCodeSpaceRunConfigName(v, r.Name)
+	validateRunCodeSpaceOptions(
+		v,
+		r.Stdin,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	if r.LanguageVersion != nil {
+		v.ValidateStringMaxLength("language_version", *r.LanguageVersion, CodeSpaceLanguageVersionMaxLength)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// validateCodeSpaceRunConfigName validates the name of a code space run configuration.
+func validateCodeSpaceRunConfigName(v *validate.Validator, name string) {
+	v.ValidateStringMaxLength("name", name, CodeSpaceRunConfigNameMaxLength)
+	v.ValidateStringSlug("name", name)
+}
+
+// CreateCodeSpaceRunConfigResponse represents the response body for code space run configuration creation requests.
+type CreateCodeSpaceRunConfigResponse struct {
+	ID                 int64     `json:"id"`
+	CodeSpaceID        int64     `json:"code_space_id"`
+	Name               string    `json:"name"`
+	Stdin              *string   `json:"stdin"`
+	Args               []string  `json:"args"`
+	CompileTimeout     *int64    `json:"compile_timeout"`
+	RunTimeout         *int64    `json:"run_timeout"`
+	CompileMemoryLimit *int64    `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64    `json:"run_memory_limit"`
+	LanguageVersion    *string   `json:"language_version"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// GetCodeSpaceRunConfigResponse represents the response body for a single run configuration
+// in code space run configuration retrieval requests.
+type GetCodeSpaceRunConfigResponse struct {
+	ID                 int64     `json:"id"`
+	CodeSpaceID        int64     `json:"code_space_id"`
+	Name               string    `json:"name"`
+	Stdin              *string   `json:"stdin"`
+	Args               []string  `json:"args"`
+	CompileTimeout     *int64    `json:"compile_timeout"`
+	RunTimeout         *int64    `json:"run_timeout"`
+	CompileMemoryLimit *int64    `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64    `json:"run_memory_limit"`
+	LanguageVersion    *string   `json:"language_version"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ListCodeSpaceRunConfigsResponse represents the response body for code space run configuration retrieval requests.
+type ListCodeSpaceRunConfigsResponse struct {
+	RunConfigs []*GetCodeSpaceRunConfigResponse `json:"run_configs"`
+}
+
+// UpdateCodeSpaceRunConfigRequest represents the request body for code space run configuration update requests.
+type UpdateCodeSpaceRunConfigRequest struct {
+	Name               *string  `json:"name"`
+	Stdin              *string  `json:"stdin"`
+	Args               []string `json:"args"`
+	CompileTimeout     *int64   `json:"compile_timeout"`
+	RunTimeout         *int64   `json:"run_timeout"`
+	CompileMemoryLimit *int64   `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64   `json:"run_memory_limit"`
+	LanguageVersion    *string  `json:"language_version"`
+}
+
+// Validate validates fields in UpdateCodeSpaceRunConfigRequest.
+func (r *UpdateCodeSpaceRunConfigRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	if r.Name != nil {
+		validateCodeSpaceRunConfigName(v, *r.Name)
+	}
+
+	validateRunCodeSpaceOptions(
+		v,
+		r.Stdin,
+		r.Args,
+		r.CompileTimeout,
+		r.RunTimeout,
+		r.CompileMemoryLimit,
+		r.RunMemoryLimit,
+	)
+
+	if r.LanguageVersion != nil {
+		v.ValidateStringMaxLength("language_version", *r.LanguageVersion, CodeSpaceLanguageVersionMaxLength)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// UpdateCodeSpaceRunConfigResponse represents the response body for code space run configuration update requests.
+type UpdateCodeSpaceRunConfigResponse struct {
+	ID                 int64     `json:"id"`
+	CodeSpaceID        int64     `json:"code_space_id"`
+	Name               string    `json:"name"`
+	Stdin              *string   `json:"stdin"`
+	Args               []string  `json:"args"`
+	CompileTimeout     *int64    `json:"compile_timeout"`
+	RunTimeout         *int64    `json:"run_timeout"`
+	CompileMemoryLimit *int64    `json:"compile_memory_limit"`
+	RunMemoryLimit     *int64    `json:"run_memory_limit"`
+	LanguageVersion    *string   `json:"language_version"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// CreateCodeSpaceEnvVarRequest represents the request body for code space environment variable creation requests.
+type CreateCodeSpaceEnvVarRequest struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	IsSecret bool   `json:"is_secret"`
+}
+
+// Validate validates fields in CreateCodeSpaceEnvVarRequest.
+func (r *CreateCodeSpaceEnvVarRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	validateCodeSpaceEnvVarName(v, r.Name)
+	v.ValidateStringMaxLength("value", r.Value, CodeSpaceEnvVarValueMaxLength)
+
+	return v.Passed(), v.Failures()
+}
+
+// validateCodeSpaceEnvVarName validates the name of a code space environment variable.
+func validateCodeSpaceEnvVarName(v *validate.Validator, name string) {
+	v.ValidateStringMaxLength("name", name, CodeSpaceEnvVarNameMaxLength)
+	v.ValidateStringEnvVarName("name", name)
+}
+
+// CreateCodeSpaceEnvVarResponse represents the response body for code space environment variable creation requests.
+type CreateCodeSpaceEnvVarResponse struct {
+	ID          int64     `json:"id"`
+	CodeSpaceID int64     `json:"code_space_id"`
+	Name        string    `json:"name"`
+	Value       *string   `json:"value"`
+	IsSecret    bool      `json:"is_secret"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// GetCodeSpaceEnvVarResponse represents the response body for a single environment variable
+// in code space environment variable retrieval requests.
+// Value is null for secret environment variables when the user only has read-only access.
+type GetCodeSpaceEnvVarResponse struct {
+	ID          int64     `json:"id"`
+	CodeSpaceID int64     `json:"code_space_id"`
+	Name        string    `json:"name"`
+	Value       *string   `json:"value"`
+	IsSecret    bool      `json:"is_secret"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListCodeSpaceEnvVarsResponse represents the response body for code space environment variable retrieval requests.
+type ListCodeSpaceEnvVarsResponse struct {
+	EnvVars []*GetCodeSpaceEnvVarResponse `json:"env_vars"`
+}
+
+// UpdateCodeSpaceEnvVarRequest represents the request body for code space environment variable update requests.
+type UpdateCodeSpaceEnvVarRequest struct {
+	Name     *string `json:"name"`
+	Value    *string `json:"value"`
+	IsSecret *bool   `json:"is_secret"`
+}
+
+// Validate validates fields in UpdateCodeSpaceEnvVarRequest.
+func (r *UpdateCodeSpaceEnvVarRequest) Validate() (bool, map[string][]string) {
+	v := validate.NewValidator()
+	if r.Name != nil {
+		validateCodeSpaceEnvVarName(v, *r.Name)
+	}
+
+	if r.Value != nil {
+		v.ValidateStringMaxLength("value", *r.Value, CodeSpaceEnvVarValueMaxLength)
+	}
+
+	return v.Passed(), v.Failures()
+}
+
+// UpdateCodeSpaceEnvVarResponse represents the response body for code space environment variable update requests.
+type UpdateCodeSpaceEnvVarResponse struct {
+	ID          int64     `json:"id"`
+	CodeSpaceID int64     `json:"code_space_id"`
+	Name        string    `json:"name"`
+	Value       *string   `json:"value"`
+	IsSecret    bool      `json:"is_secret"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RunCodeSpaceTestResultResponse represents the grading result of a single test case
+// for code space test run requests.
+type RunCodeSpaceTestResultResponse struct {
+	TestCaseID int64                        `json:"test_case_id"`
+	IsHidden   bool                         `json:"is_hidden"`
+	Passed     bool                         `json:"passed"`
+	Compile    *RunCodeSpaceResultsResponse `json:"compile"`
+	Run        RunCodeSpaceResultsResponse  `json:"run"`
+	Diff       *string                      `json:"diff"`
+	FromCache  bool                         `json:"from_cache"`
+}
+
+// RunCodeSpaceTestsResponse represents the response body for code space test run requests.
+// Score is the fraction of test cases that passed.
+type RunCodeSpaceTestsResponse struct {
+	Results []*RunCodeSpaceTestResultResponse `json:"results"`
+	Passed  int                               `json:"passed"`
+	Total   int                               `json:"total"`
+	Score   float64                           `json:"score"`
 }
 
 // GetCodespaceUserResponse represents the response body for a single user's code space access
@@ -1,6 +1,8 @@
 package api_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/alvii147/nymphadora-api/pkg/api"
@@ -12,6 +14,22 @@ import (
 func TestCreateCodeSpaceRequestValidate(t *testing.T) {
 	t.Parallel()
 
+	supportedLanguages := []string{
+		api.PistonLanguageC,
+		"gcc",
+		api.PistonLanguagePython,
+		"py",
+		"py3",
+	}
+
+	languageVersion := "3.10.0"
+	blankLanguageVersion := " "
+	longLanguageVersion := strings.Repeat("9", api.CodeSpaceLanguageVersionMaxLength+1)
+	codeSpaceName := "fizz-buzz"
+	invalidCodeSpaceName := "Fizz Buzz"
+	shortCodeSpaceName := "fb"
+	longCodeSpaceName := strings.Repeat("a", api.CodeSpaceNameMaxLength+1)
+
 	testcases := map[string]struct {
 		req               *api.CreateCodeSpaceRequest
 		wantValid         bool
@@ -24,61 +42,89 @@ func TestCreateCodeSpaceRequestValidate(t *testing.T) {
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
-		"Valid request, language C++": {
+		"Valid request, language Python": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguageCPlusPlus,
+				Language: api.PistonLanguagePython,
 			},
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
-		"Valid request, language Go": {
+		"Valid request, language alias": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguageGo,
+				Language: "py3",
 			},
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
-		"Valid request, language Java": {
+		"Valid request, with language version": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguageJava,
+				Language:        api.PistonLanguagePython,
+				LanguageVersion: &languageVersion,
 			},
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
-		"Valid request, language JavaScript": {
+		"Blank language version": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguageJavaScript,
+				Language:        api.PistonLanguagePython,
+				LanguageVersion: &blankLanguageVersion,
 			},
-			wantValid:         true,
-			wantInvalidFields: nil,
+			wantValid:         false,
+			wantInvalidFields: []string{"language_version"},
 		},
-		"Valid request, language Python": {
+		"Language version too long": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguagePython,
+				Language:        api.PistonLanguagePython,
+				LanguageVersion: &longLanguageVersion,
 			},
-			wantValid:         true,
-			wantInvalidFields: nil,
+			wantValid:         false,
+			wantInvalidFields: []string{"language_version"},
 		},
-		"Valid request, language Rust": {
+		"Language with code template but no runtime": {
 			req: &api.CreateCodeSpaceRequest{
 				Language: api.PistonLanguageRust,
 			},
-			wantValid:         true,
-			wantInvalidFields: nil,
+			wantValid:         false,
+			wantInvalidFields: []string{"language"},
+		},
+		"Unsupported language": {
+			req: &api.CreateCodeSpaceRequest{
+				Language: "un5upp0rt3dl4ngu4g3",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"language"},
 		},
-		"Valid request, language TypeScript": {
+		"Valid request, with name": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: api.PistonLanguageTypeScript,
+				Name:     &codeSpaceName,
+				Language: api.PistonLanguagePython,
 			},
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
-		"Unsupported language": {
+		"Name not a slug": {
 			req: &api.CreateCodeSpaceRequest{
-				Language: "un5upp0rt3dl4ngu4g3",
+				Name:     &invalidCodeSpaceName,
+				Language: api.PistonLanguagePython,
 			},
 			wantValid:         false,
-			wantInvalidFields: []string{"language"},
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too short": {
+			req: &api.CreateCodeSpaceRequest{
+				Name:     &shortCodeSpaceName,
+				Language: api.PistonLanguagePython,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too long": {
+			req: &api.CreateCodeSpaceRequest{
+				Name:     &longCodeSpaceName,
+				Language: api.PistonLanguagePython,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
 		},
 	}
 
@@ -86,7 +132,7 @@ func TestCreateCodeSpaceRequestValidate(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			passed, failures := testcase.req.Validate()
+			passed, failures := testcase.req.Validate(supportedLanguages)
 			require.Equal(t, testcase.wantValid, passed)
 			require.Len(t, failures, len(testcase.wantInvalidFields))
 
@@ -103,6 +149,14 @@ func TestUpdateCodeSpaceRequestValidate(t *testing.T) {
 	t.Parallel()
 
 	contents := "print('Hello')"
+	languageVersion := "3.10.0"
+	unpinnedLanguageVersion := ""
+	longLanguageVersion := strings.Repeat("9", api.CodeSpaceLanguageVersionMaxLength+1)
+	codeSpaceName := "fizz-buzz"
+	invalidCodeSpaceName := "fizz--buzz"
+	longCodeSpaceName := strings.Repeat("a", api.CodeSpaceNameMaxLength+1)
+	version := int64(3)
+	zeroVersion := int64(0)
 
 	testcases := map[string]struct {
 		req               *api.UpdateCodeSpaceRequest
@@ -123,6 +177,1430 @@ func TestUpdateCodeSpaceRequestValidate(t *testing.T) {
 			wantValid:         true,
 			wantInvalidFields: nil,
 		},
+		"Valid request, with language version": {
+			req: &api.UpdateCodeSpaceRequest{
+				LanguageVersion: &languageVersion,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid request, unpinned language version": {
+			req: &api.UpdateCodeSpaceRequest{
+				LanguageVersion: &unpinnedLanguageVersion,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Language version too long": {
+			req: &api.UpdateCodeSpaceRequest{
+				LanguageVersion: &longLanguageVersion,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"language_version"},
+		},
+		"Valid request, with name": {
+			req: &api.UpdateCodeSpaceRequest{
+				Name: &codeSpaceName,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Name not a slug": {
+			req: &api.UpdateCodeSpaceRequest{
+				Name: &invalidCodeSpaceName,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too long": {
+			req: &api.UpdateCodeSpaceRequest{
+				Name: &longCodeSpaceName,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Valid request, with version": {
+			req: &api.UpdateCodeSpaceRequest{
+				Contents: &contents,
+				Version:  &version,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Version not positive": {
+			req: &api.UpdateCodeSpaceRequest{
+				Contents: &contents,
+				Version:  &zeroVersion,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"version"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestRunCodeSpaceRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "42\n"
+	longStdin := strings.Repeat("x", api.RunCodeSpaceStdinMaxLength+1)
+	timeout := int64(3000)
+	zero := int64(0)
+	memoryLimit := int64(134217728)
+	negative := int64(-1)
+	runConfig := "verbose"
+	longRunConfig := strings.Repeat("x", api.CodeSpaceRunConfigNameMaxLength+1)
+
+	tooManyArgs := make([]string, api.RunCodeSpaceArgsMaxCount+1)
+	for i := range tooManyArgs {
+		tooManyArgs[i] = "arg"
+	}
+
+	testcases := map[string]struct {
+		req               *api.RunCodeSpaceRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request, empty": {
+			req:               &api.RunCodeSpaceRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid request, all fields": {
+			req: &api.RunCodeSpaceRequest{
+				Stdin:              &stdin,
+				Args:               []string{"--verbose", "input.txt"},
+				CompileTimeout:     &timeout,
+				RunTimeout:         &timeout,
+				CompileMemoryLimit: &memoryLimit,
+				RunMemoryLimit:     &memoryLimit,
+				RunConfig:          &runConfig,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Run config name too long": {
+			req: &api.RunCodeSpaceRequest{
+				RunConfig: &longRunConfig,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"run_config"},
+		},
+		"Stdin too long": {
+			req: &api.RunCodeSpaceRequest{
+				Stdin: &longStdin,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"stdin"},
+		},
+		"Too many args": {
+			req: &api.RunCodeSpaceRequest{
+				Args: tooManyArgs,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"args"},
+		},
+		"Arg too long": {
+			req: &api.RunCodeSpaceRequest{
+				Args: []string{strings.Repeat("x", api.RunCodeSpaceArgMaxLength+1)},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"args"},
+		},
+		"Non-positive timeouts and memory limits": {
+			req: &api.RunCodeSpaceRequest{
+				CompileTimeout:     &zero,
+				RunTimeout:         &negative,
+				CompileMemoryLimit: &zero,
+				RunMemoryLimit:     &negative,
+			},
+			wantValid: false,
+			wantInvalidFields: []string{
+				"compile_timeout",
+				"run_timeout",
+				"compile_memory_limit",
+				"run_memory_limit",
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestRunCodeSpaceMatrixRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "42\n"
+	timeout := int64(3000)
+	zero := int64(0)
+
+	tooManyVersions := make([]string, api.RunCodeSpaceMatrixMaxVersions+1)
+	for i := range tooManyVersions {
+		tooManyVersions[i] = fmt.Sprintf("3.%d.0", i)
+	}
+
+	testcases := map[string]struct {
+		req               *api.RunCodeSpaceMatrixRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request, versions only": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: []string{"3.9.4", "3.10.0"},
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid request, all fields": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions:   []string{"3.9.4", "3.10.0"},
+				Stdin:      &stdin,
+				Args:       []string{"--verbose"},
+				RunTimeout: &timeout,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"No versions": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: []string{},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"versions"},
+		},
+		"Too many versions": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: tooManyVersions,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"versions"},
+		},
+		"Blank version": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: []string{"3.10.0", " "},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"versions"},
+		},
+		"Version too long": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: []string{strings.Repeat("9", api.CodeSpaceLanguageVersionMaxLength+1)},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"versions"},
+		},
+		"Duplicate versions": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions: []string{"3.10.0", "3.9.4", "3.10.0"},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"versions"},
+		},
+		"Non-positive timeout": {
+			req: &api.RunCodeSpaceMatrixRequest{
+				Versions:       []string{"3.10.0"},
+				CompileTimeout: &zero,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"compile_timeout"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestBenchmarkCodeSpaceRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "42\n"
+	timeout := int64(3000)
+	zero := int64(0)
+
+	testcases := map[string]struct {
+		req               *api.BenchmarkCodeSpaceRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request, iterations only": {
+			req: &api.BenchmarkCodeSpaceRequest{
+				Iterations: 10,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid request, all fields": {
+			req: &api.BenchmarkCodeSpaceRequest{
+				Iterations: api.BenchmarkCodeSpaceMaxIterations,
+				Stdin:      &stdin,
+				Args:       []string{"--verbose"},
+				RunTimeout: &timeout,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"No iterations": {
+			req: &api.BenchmarkCodeSpaceRequest{
+				Iterations: 0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"iterations"},
+		},
+		"Too many iterations": {
+			req: &api.BenchmarkCodeSpaceRequest{
+				Iterations: api.BenchmarkCodeSpaceMaxIterations + 1,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"iterations"},
+		},
+		"Non-positive timeout": {
+			req: &api.BenchmarkCodeSpaceRequest{
+				Iterations: 10,
+				RunTimeout: &zero,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"run_timeout"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestInteractiveCodeSpaceRunRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	timeout := int64(3000)
+	zero := int64(0)
+
+	testcases := map[string]struct {
+		req               *api.InteractiveCodeSpaceRunRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid empty request": {
+			req:               &api.InteractiveCodeSpaceRunRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid request, all fields": {
+			req: &api.InteractiveCodeSpaceRunRequest{
+				Args:               []string{"--verbose"},
+				CompileTimeout:     &timeout,
+				RunTimeout:         &timeout,
+				CompileMemoryLimit: &timeout,
+				RunMemoryLimit:     &timeout,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Too many args": {
+			req: &api.InteractiveCodeSpaceRunRequest{
+				Args: make([]string, api.RunCodeSpaceArgsMaxCount+1),
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"args"},
+		},
+		"Non-positive limits": {
+			req: &api.InteractiveCodeSpaceRunRequest{
+				RunTimeout:     &zero,
+				RunMemoryLimit: &zero,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"run_timeout", "run_memory_limit"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestInteractiveCodeSpaceRunMessageValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "Harry\n"
+	emptyStdin := ""
+	longStdin := strings.Repeat("a", api.RunCodeSpaceStdinMaxLength+1)
+	sigint := "SIGINT"
+	sighup := "SIGHUP"
+
+	testcases := map[string]struct {
+		msg               *api.InteractiveCodeSpaceRunMessage
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid stdin message": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: api.InteractiveCodeSpaceRunMessageTypeStdin,
+				Data: &stdin,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid signal message": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type:   api.InteractiveCodeSpaceRunMessageTypeSignal,
+				Signal: &sigint,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Unknown type": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: "resize",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"type"},
+		},
+		"Stdin message without data": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: api.InteractiveCodeSpaceRunMessageTypeStdin,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"data"},
+		},
+		"Stdin message with empty data": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: api.InteractiveCodeSpaceRunMessageTypeStdin,
+				Data: &emptyStdin,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"data"},
+		},
+		"Stdin message with too much data": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: api.InteractiveCodeSpaceRunMessageTypeStdin,
+				Data: &longStdin,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"data"},
+		},
+		"Signal message without signal": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type: api.InteractiveCodeSpaceRunMessageTypeSignal,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"signal"},
+		},
+		"Signal message with unsupported signal": {
+			msg: &api.InteractiveCodeSpaceRunMessage{
+				Type:   api.InteractiveCodeSpaceRunMessageTypeSignal,
+				Signal: &sighup,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"signal"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.msg.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestCodeSpaceCollabMessageValidate(t *testing.T) {
+	t.Parallel()
+
+	retain := int64(11)
+	zeroRetain := int64(0)
+	insert := "!"
+	emptyInsert := ""
+	longInsert := strings.Repeat("a", api.CodeSpaceEditInsertMaxLength+1)
+	deleteLength := int64(5)
+	negativeDeleteLength := int64(-5)
+	tooManyComponents := make([]*api.CodeSpaceEditComponent, api.CodeSpaceEditComponentsMaxCount+1)
+	for i := range tooManyComponents {
+		tooManyComponents[i] = &api.CodeSpaceEditComponent{Retain: &retain}
+	}
+
+	testcases := map[string]struct {
+		msg               *api.CodeSpaceCollabMessage
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid edit message": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:     api.CodeSpaceCollabMessageTypeEdit,
+				Revision: 3,
+				Operation: []*api.CodeSpaceEditComponent{
+					{Retain: &retain},
+					{Insert: &insert},
+					{Delete: &deleteLength},
+				},
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid cursor message": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:     api.CodeSpaceCollabMessageTypeCursor,
+				Revision: 3,
+				Cursor: &api.CodeSpaceCursor{
+					Position: 5,
+					Anchor:   0,
+				},
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid heartbeat message": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type: api.CodeSpaceCollabMessageTypeHeartbeat,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Cursor message without cursor": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:     api.CodeSpaceCollabMessageTypeCursor,
+				Revision: 3,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"cursor"},
+		},
+		"Cursor message with negative position": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:     api.CodeSpaceCollabMessageTypeCursor,
+				Revision: 3,
+				Cursor: &api.CodeSpaceCursor{
+					Position: -1,
+					Anchor:   0,
+				},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"cursor"},
+		},
+		"Unknown type": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      "resize",
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Retain: &retain}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"type"},
+		},
+		"Negative revision": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  -1,
+				Operation: []*api.CodeSpaceEditComponent{{Retain: &retain}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"revision"},
+		},
+		"Empty component": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Null component": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{nil},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Component with retain and insert": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Retain: &retain, Insert: &insert}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Zero retain": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Retain: &zeroRetain}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Empty insert": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Insert: &emptyInsert}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Insert too long": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Insert: &longInsert}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Negative delete": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: []*api.CodeSpaceEditComponent{{Delete: &negativeDeleteLength}},
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+		"Too many components": {
+			msg: &api.CodeSpaceCollabMessage{
+				Type:      api.CodeSpaceCollabMessageTypeEdit,
+				Revision:  3,
+				Operation: tooManyComponents,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"operation"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.msg.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestListCodeSpaceRunsRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		req               *api.ListCodeSpaceRunsRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.ListCodeSpaceRunsRequest{
+				Limit:  api.ListCodeSpaceRunsDefaultLimit,
+				Offset: 40,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Limit too small": {
+			req: &api.ListCodeSpaceRunsRequest{
+				Limit:  0,
+				Offset: 0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"limit"},
+		},
+		"Limit too large": {
+			req: &api.ListCodeSpaceRunsRequest{
+				Limit:  api.ListCodeSpaceRunsMaxLimit + 1,
+				Offset: 0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"limit"},
+		},
+		"Negative offset": {
+			req: &api.ListCodeSpaceRunsRequest{
+				Limit:  api.ListCodeSpaceRunsDefaultLimit,
+				Offset: -1,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"offset"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestListCodeSpaceRevisionsRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		req               *api.ListCodeSpaceRevisionsRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.ListCodeSpaceRevisionsRequest{
+				Limit:  api.ListCodeSpaceRevisionsDefaultLimit,
+				Offset: 40,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Limit too small": {
+			req: &api.ListCodeSpaceRevisionsRequest{
+				Limit:  0,
+				Offset: 0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"limit"},
+		},
+		"Limit too large": {
+			req: &api.ListCodeSpaceRevisionsRequest{
+				Limit:  api.ListCodeSpaceRevisionsMaxLimit + 1,
+				Offset: 0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"limit"},
+		},
+		"Negative offset": {
+			req: &api.ListCodeSpaceRevisionsRequest{
+				Limit:  api.ListCodeSpaceRevisionsDefaultLimit,
+				Offset: -1,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"offset"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestDiffCodeSpaceRevisionsRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		req               *api.DiffCodeSpaceRevisionsRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.DiffCodeSpaceRevisionsRequest{
+				From: 3,
+				To:   5,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Missing revisions": {
+			req: &api.DiffCodeSpaceRevisionsRequest{
+				From: 0,
+				To:   0,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"from", "to"},
+		},
+		"Negative to revision": {
+			req: &api.DiffCodeSpaceRevisionsRequest{
+				From: 3,
+				To:   -1,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"to"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestRestoreCodeSpaceRevisionRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	version := int64(3)
+	zeroVersion := int64(0)
+
+	testcases := map[string]struct {
+		req               *api.RestoreCodeSpaceRevisionRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.RestoreCodeSpaceRevisionRequest{
+				Version: &version,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty request": {
+			req:               &api.RestoreCodeSpaceRevisionRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Zero version": {
+			req: &api.RestoreCodeSpaceRevisionRequest{
+				Version: &zeroVersion,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"version"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestCreateCodeSpaceFileRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		req               *api.CreateCodeSpaceFileRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.CreateCodeSpaceFileRequest{
+				Name:         "utils/strings.py",
+				Contents:     "def reverse(s):\n    return s[::-1]\n",
+				IsEntryPoint: false,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty name": {
+			req: &api.CreateCodeSpaceFileRequest{
+				Name:         "",
+				Contents:     "",
+				IsEntryPoint: false,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name escapes code space": {
+			req: &api.CreateCodeSpaceFileRequest{
+				Name:         "../secrets.txt",
+				Contents:     "",
+				IsEntryPoint: false,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too long": {
+			req: &api.CreateCodeSpaceFileRequest{
+				Name:         strings.Repeat("a", api.CodeSpaceFileNameMaxLength+1),
+				Contents:     "",
+				IsEntryPoint: false,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestUpdateCodeSpaceFileRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	validName := "Helper.java"
+	invalidName := "/Helper.java"
+	contents := "public class Helper {}"
+	isEntryPoint := true
+
+	testcases := map[string]struct {
+		req               *api.UpdateCodeSpaceFileRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.UpdateCodeSpaceFileRequest{
+				Name:         &validName,
+				Contents:     &contents,
+				IsEntryPoint: &isEntryPoint,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty request": {
+			req:               &api.UpdateCodeSpaceFileRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Invalid name": {
+			req: &api.UpdateCodeSpaceFileRequest{
+				Name: &invalidName,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestCreateCodeSpaceTestCaseRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "3\n4\n"
+	longStdin := strings.Repeat("a", api.RunCodeSpaceStdinMaxLength+1)
+
+	testcases := map[string]struct {
+		req               *api.CreateCodeSpaceTestCaseRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				Stdin:          &stdin,
+				Args:           []string{"--sum"},
+				ExpectedStdout: "7",
+				ComparisonMode: api.CodeSpaceTestComparisonModeTrimmed,
+				IsHidden:       true,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Valid regex request": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				ExpectedStdout: `\d+`,
+				ComparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Missing comparison mode": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				ExpectedStdout: "7",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"comparison_mode"},
+		},
+		"Unknown comparison mode": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				ExpectedStdout: "7",
+				ComparisonMode: "fuzzy",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"comparison_mode"},
+		},
+		"Invalid regex": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				ExpectedStdout: `(\d+`,
+				ComparisonMode: api.CodeSpaceTestComparisonModeRegex,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"expected_stdout"},
+		},
+		"Expected output too long": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				ExpectedStdout: strings.Repeat("a", api.CodeSpaceTestCaseExpectedStdoutMaxLength+1),
+				ComparisonMode: api.CodeSpaceTestComparisonModeExact,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"expected_stdout"},
+		},
+		"Inputs too long": {
+			req: &api.CreateCodeSpaceTestCaseRequest{
+				Stdin:          &longStdin,
+				Args:           make([]string, api.RunCodeSpaceArgsMaxCount+1),
+				ExpectedStdout: "7",
+				ComparisonMode: api.CodeSpaceTestComparisonModeExact,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"stdin", "args"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestUpdateCodeSpaceTestCaseRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	validPattern := `\d+`
+	invalidPattern := `(\d+`
+	regexMode := api.CodeSpaceTestComparisonModeRegex
+	unknownMode := "fuzzy"
+	isHidden := true
+
+	testcases := map[string]struct {
+		req               *api.UpdateCodeSpaceTestCaseRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.UpdateCodeSpaceTestCaseRequest{
+				ExpectedStdout: &validPattern,
+				ComparisonMode: &regexMode,
+				IsHidden:       &isHidden,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty request": {
+			req:               &api.UpdateCodeSpaceTestCaseRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Expected output without comparison mode": {
+			req: &api.UpdateCodeSpaceTestCaseRequest{
+				ExpectedStdout: &invalidPattern,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Unknown comparison mode": {
+			req: &api.UpdateCodeSpaceTestCaseRequest{
+				ComparisonMode: &unknownMode,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"comparison_mode"},
+		},
+		"Invalid regex": {
+			req: &api.UpdateCodeSpaceTestCaseRequest{
+				ExpectedStdout: &invalidPattern,
+				ComparisonMode: &regexMode,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"expected_stdout"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestCreateCodeSpaceRunConfigRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	stdin := "3\n4\n"
+	timeout := int64(3000)
+	zero := int64(0)
+	languageVersion := "3.10.0"
+	longLanguageVersion := strings.Repeat("1", api.CodeSpaceLanguageVersionMaxLength+1)
+
+	testcases := map[string]struct {
+		req               *api.CreateCodeSpaceRunConfigRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.CreateCodeSpaceRunConfigRequest{
+				Name:            "sum-inputs",
+				Stdin:           &stdin,
+				Args:            []string{"--sum"},
+				RunTimeout:      &timeout,
+				LanguageVersion: &languageVersion,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Missing name": {
+			req:               &api.CreateCodeSpaceRunConfigRequest{},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name not a slug": {
+			req: &api.CreateCodeSpaceRunConfigRequest{
+				Name: "Sum Inputs",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too long": {
+			req: &api.CreateCodeSpaceRunConfigRequest{
+				Name: strings.Repeat("x", api.CodeSpaceRunConfigNameMaxLength+1),
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Invalid options": {
+			req: &api.CreateCodeSpaceRunConfigRequest{
+				Name:            "sum-inputs",
+				Args:            make([]string, api.RunCodeSpaceArgsMaxCount+1),
+				CompileTimeout:  &zero,
+				LanguageVersion: &longLanguageVersion,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"args", "compile_timeout", "language_version"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestUpdateCodeSpaceRunConfigRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	name := "sum-inputs"
+	invalidName := "Sum Inputs"
+	negative := int64(-1)
+
+	testcases := map[string]struct {
+		req               *api.UpdateCodeSpaceRunConfigRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.UpdateCodeSpaceRunConfigRequest{
+				Name: &name,
+				Args: []string{"--sum"},
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty request": {
+			req:               &api.UpdateCodeSpaceRunConfigRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Name not a slug": {
+			req: &api.UpdateCodeSpaceRunConfigRequest{
+				Name: &invalidName,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Negative memory limit": {
+			req: &api.UpdateCodeSpaceRunConfigRequest{
+				RunMemoryLimit: &negative,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"run_memory_limit"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestCreateCodeSpaceEnvVarRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		req               *api.CreateCodeSpaceEnvVarRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:     "API_TOKEN",
+				Value:    "0xdeadbeef",
+				IsSecret: true,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty value": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:     "DEBUG",
+				Value:    "",
+				IsSecret: false,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty name": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:  "",
+				Value: "0xdeadbeef",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Invalid name": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:  "API-TOKEN",
+				Value: "0xdeadbeef",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Name too long": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:  strings.Repeat("A", api.CodeSpaceEnvVarNameMaxLength+1),
+				Value: "0xdeadbeef",
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Value too long": {
+			req: &api.CreateCodeSpaceEnvVarRequest{
+				Name:  "API_TOKEN",
+				Value: strings.Repeat("a", api.CodeSpaceEnvVarValueMaxLength+1),
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"value"},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, failures := testcase.req.Validate()
+			require.Equal(t, testcase.wantValid, passed)
+			require.Len(t, failures, len(testcase.wantInvalidFields))
+
+			for _, field := range testcase.wantInvalidFields {
+				fieldFailures, ok := failures[field]
+				require.True(t, ok)
+				require.NotEmpty(t, fieldFailures)
+			}
+		})
+	}
+}
+
+func TestUpdateCodeSpaceEnvVarRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	name := "API_TOKEN"
+	invalidName := "API TOKEN"
+	value := "0xdeadbeef"
+	longValue := strings.Repeat("a", api.CodeSpaceEnvVarValueMaxLength+1)
+	isSecret := true
+
+	testcases := map[string]struct {
+		req               *api.UpdateCodeSpaceEnvVarRequest
+		wantValid         bool
+		wantInvalidFields []string
+	}{
+		"Valid request": {
+			req: &api.UpdateCodeSpaceEnvVarRequest{
+				Name:     &name,
+				Value:    &value,
+				IsSecret: &isSecret,
+			},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Empty request": {
+			req:               &api.UpdateCodeSpaceEnvVarRequest{},
+			wantValid:         true,
+			wantInvalidFields: nil,
+		},
+		"Invalid name": {
+			req: &api.UpdateCodeSpaceEnvVarRequest{
+				Name: &invalidName,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"name"},
+		},
+		"Value too long": {
+			req: &api.UpdateCodeSpaceEnvVarRequest{
+				Value: &longValue,
+			},
+			wantValid:         false,
+			wantInvalidFields: []string{"value"},
+		},
 	}
 
 	for name, testcase := range testcases {
@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // Error codes for API responses.
 const (
 	// ErrCodeInvalidRequest is the error code returned for invalid requests.
@@ -20,9 +22,37 @@ const (
 	// ErrCodeAccessDenied is the error code returned when access is denied.
 	// Used typically with status code 403.
 	ErrCodeAccessDenied = "access_denied"
+	// ErrCodeTooManyRequests is the error code returned when the server is too busy to process the request.
+	// Used typically with status code 429.
+	ErrCodeTooManyRequests = "too_many_requests"
+	// ErrCodeQuotaExceeded is the error code returned when a user or API key has exceeded its quota.
+	// Used typically with status code 429.
+	ErrCodeQuotaExceeded = "quota_exceeded"
+	// ErrCodeUpstreamRateLimited is the error code returned when the code execution service is rate limiting requests.
+	// Used typically with status code 429.
+	ErrCodeUpstreamRateLimited = "upstream_rate_limited"
+	// ErrCodeUpstreamUnavailable is the error code returned when the code execution service is unavailable.
+	// Used typically with status code 503.
+	ErrCodeUpstreamUnavailable = "upstream_unavailable"
+	// ErrCodeUpstreamRejected is the error code returned when the code execution service rejects the request.
+	// Used typically with status code 400.
+	ErrCodeUpstreamRejected = "upstream_rejected"
+	// ErrCodeRuntimeNotFound is the error code returned when the code execution service
+	// does not have the requested runtime installed.
+	// Used typically with status code 400.
+	ErrCodeRuntimeNotFound = "runtime_not_found"
+	// ErrCodeVersionConflict is the error code returned when a resource was modified since the given version.
+	// Used typically with status code 409.
+	ErrCodeVersionConflict = "version_conflict"
+	// ErrCodeRequestCancelled is the error code returned when the request was cancelled before it finished.
+	// Used typically with status code 408.
+	ErrCodeRequestCancelled = "request_cancelled"
 	// ErrCodeInternalServerError is the error code returned when an internal server error occurs.
 	// Used typically with status code 500.
 	ErrCodeInternalServerError = "internal_server_error"
+	// ErrCodeNotImplemented is the error code returned when the server does not support the requested feature.
+	// Used typically with status code 501.
+	ErrCodeNotImplemented = "not_implemented"
 )
 
 // Error details for API responses.
@@ -51,11 +81,91 @@ const (
 	ErrDetailCodeSpaceNotFound = "Code space not found"
 	// ErrDetailCodeSpaceAccessDenied is the error detail returned when access to a code space is denied.
 	ErrDetailCodeSpaceAccessDenied = "Code space access denied"
+	// ErrDetailCodeSpaceRunNotFound is the error detail returned when the code space run is not found.
+	ErrDetailCodeSpaceRunNotFound = "Code space run not found"
+	// ErrDetailCodeSpaceFileExists is the error detail returned when a code space file already exists.
+	ErrDetailCodeSpaceFileExists = "Code space file already exists"
+	// ErrDetailCodeSpaceFileNotFound is the error detail returned when the code space file is not found.
+	ErrDetailCodeSpaceFileNotFound = "Code space file not found"
+	// ErrDetailCodeSpaceFileLimitExceeded is the error detail returned when a code space has too many files.
+	ErrDetailCodeSpaceFileLimitExceeded = "Code space has reached the maximum number of files"
+	// ErrDetailCodeSpaceTestCaseNotFound is the error detail returned when the code space test case is not found.
+	ErrDetailCodeSpaceTestCaseNotFound = "Code space test case not found"
+	// ErrDetailCodeSpaceTestCaseLimitExceeded is the error detail returned when a code space has too many test cases.
+	ErrDetailCodeSpaceTestCaseLimitExceeded = "Code space has reached the maximum number of test cases"
+	// ErrDetailCodeSpaceTestCaseInvalidPattern is the error detail returned
+	// when a test case compared using regular expressions has an invalid pattern.
+	ErrDetailCodeSpaceTestCaseInvalidPattern = "Code space test case pattern is not a valid regular expression"
+	// ErrDetailCodeSpaceRunConfigExists is the error detail returned when a code space run configuration already exists.
+	ErrDetailCodeSpaceRunConfigExists = "Code space run configuration already exists"
+	// ErrDetailCodeSpaceRunConfigNotFound is the error detail returned
+	// when the code space run configuration is not found.
+	ErrDetailCodeSpaceRunConfigNotFound = "Code space run configuration not found"
+	// ErrDetailCodeSpaceRunConfigLimitExceeded is the error detail returned
+	// when a code space has too many run configurations.
+	ErrDetailCodeSpaceRunConfigLimitExceeded = "Code space has reached the maximum number of run configurations"
+	// ErrDetailCodeSpaceEnvVarExists is the error detail returned
+	// when a code space environment variable already exists.
+	ErrDetailCodeSpaceEnvVarExists = "Code space environment variable already exists"
+	// ErrDetailCodeSpaceEnvVarNotFound is the error detail returned
+	// when the code space environment variable is not found.
+	ErrDetailCodeSpaceEnvVarNotFound = "Code space environment variable not found"
+	// ErrDetailCodeSpaceEnvVarLimitExceeded is the error detail returned
+	// when a code space has too many environment variables.
+	ErrDetailCodeSpaceEnvVarLimitExceeded = "Code space has reached the maximum number of environment variables"
+	// ErrDetailCodeSpaceEnvVarsUnsupported is the error detail returned
+	// when a code space with environment variables is run in a language that cannot set them.
+	ErrDetailCodeSpaceEnvVarsUnsupported = "Code space language does not support environment variables"
+	// ErrDetailCodeSpaceLanguageUnsupported is the error detail returned when a code space language is not supported.
+	ErrDetailCodeSpaceLanguageUnsupported = "Code space language is not supported"
+	// ErrDetailCodeSpaceVersionUnsupported is the error detail returned when a language version is not supported.
+	ErrDetailCodeSpaceVersionUnsupported = "Code space language version is not supported"
+	// ErrDetailCodeSpaceRunLimitExceeded is the error detail returned when requested run limits exceed the maximum.
+	ErrDetailCodeSpaceRunLimitExceeded = "Requested run limits exceed the allowed maximum"
+	// ErrDetailCodeExecutionBusy is the error detail returned when code execution is too busy to accept requests.
+	ErrDetailCodeExecutionBusy = "Code execution is busy, please retry later"
+	// ErrDetailCodeExecutionRateLimited is the error detail returned
+	// when the code execution service is rate limiting requests.
+	ErrDetailCodeExecutionRateLimited = "Code execution service is rate limiting requests, please retry later"
+	// ErrDetailCodeExecutionUnavailable is the error detail returned when the code execution service is unavailable.
+	ErrDetailCodeExecutionUnavailable = "Code execution service is unavailable, please retry later"
+	// ErrDetailCodeExecutionRejected is the error detail returned when the code execution service rejects the request.
+	ErrDetailCodeExecutionRejected = "Code execution service rejected the request"
+	// ErrDetailCodeExecutionRuntimeNotFound is the error detail returned
+	// when the code execution service does not have the requested runtime installed.
+	ErrDetailCodeExecutionRuntimeNotFound = "Code execution service does not have the requested language version installed"
+	// ErrDetailCodeExecutionNotInteractive is the error detail returned
+	// when the code execution service does not support interactive runs.
+	ErrDetailCodeExecutionNotInteractive = "Code execution service does not support interactive runs"
+	// ErrDetailWebSocketUpgradeRequired is the error detail returned when a WebSocket endpoint gets a plain request.
+	ErrDetailWebSocketUpgradeRequired = "Request must be a WebSocket upgrade request"
+	// ErrDetailCodeSpaceRunCancelled is the error detail returned when a code space run is cancelled.
+	ErrDetailCodeSpaceRunCancelled = "Code space run was cancelled before it finished"
+	// ErrDetailExecutionQuotaExceeded is the error detail returned when a code execution quota has been exceeded.
+	ErrDetailExecutionQuotaExceeded = "Code execution quota exceeded, please retry after the quota resets"
+	// ErrDetailCodeSpaceRevisionNotFound is the error detail returned when the code space revision is not found.
+	ErrDetailCodeSpaceRevisionNotFound = "Code space revision not found"
+	// ErrDetailCodeSpaceVersionConflict is the error detail returned
+	// when a code space was modified since the given version.
+	ErrDetailCodeSpaceVersionConflict = "Code space was modified since the given version"
+	// ErrDetailCodeSpaceEditInvalid is the error detail returned
+	// when a code space edit does not apply to the given revision.
+	ErrDetailCodeSpaceEditInvalid = "Code space edit does not apply to the given revision"
+	// ErrDetailCodeSpaceEditOutdated is the error detail returned
+	// when a code space edit is based on a revision too old to be merged.
+	ErrDetailCodeSpaceEditOutdated = "Code space edit is based on a revision too old to be merged"
+	// ErrDetailCodeSpaceCursorInvalid is the error detail returned
+	// when a code space cursor lies outside of the code space at the given revision.
+	ErrDetailCodeSpaceCursorInvalid = "Code space cursor lies outside of the code space at the given revision"
 )
 
 // ErrorResponse represents the general error response body.
+// ResetAt is only set for exceeded quotas, and is the time at which the exceeded quota resets.
+// CodeSpace is only set for code space version conflicts, and is the current state of the code space.
 type ErrorResponse struct {
-	Code               string              `json:"code"`
-	Detail             string              `json:"detail"`
-	ValidationFailures map[string][]string `json:"failures,omitempty"`
+	Code               string                `json:"code"`
+	Detail             string                `json:"detail"`
+	ValidationFailures map[string][]string   `json:"failures,omitempty"`
+	ResetAt            *time.Time            `json:"reset_at,omitempty"`
+	CodeSpace          *GetCodeSpaceResponse `json:"code_space,omitempty"`
 }
@@ -3,48 +3,108 @@ package api
 const (
 	// PistonFileEncoding represents the content encoding used for files on Piston requests.
 	PistonFileEncoding = "utf8"
+)
 
+// languages with code templates and file names known ahead of time.
+// The languages supported by the server are discovered from Piston at runtime.
+const (
 	// PistonLanguageC represents the C language runtime in Piston.
 	PistonLanguageC = "c"
-	// PistonVersionC represents the version of C language runtime in Piston.
-	PistonVersionC = "10.2.0"
-
 	// PistonLanguageCPlusPlus represents the C++ language runtime in Piston.
 	PistonLanguageCPlusPlus = "c++"
-	// PistonVersionCPlusPlus represents the version of C++ language runtime in Piston.
-	PistonVersionCPlusPlus = "10.2.0"
-
 	// PistonLanguageGo represents the Go language runtime in Piston.
 	PistonLanguageGo = "go"
-	// PistonVersionGo represents the version of Go language runtime in Piston.
-	PistonVersionGo = "1.16.2"
-
 	// PistonLanguageJava represents the Java language runtime in Piston.
 	PistonLanguageJava = "java"
-	// PistonVersionJava represents the version of Java language runtime in Piston.
-	PistonVersionJava = "15.0.2"
-
 	// PistonLanguageJavaScript represents the JavaScript language runtime in Piston.
 	PistonLanguageJavaScript = "javascript"
-	// PistonVersionJavaScript represents the version of JavaScript language runtime in Piston.
-	PistonVersionJavaScript = "18.15.0"
-
 	// PistonLanguagePython represents the Python language runtime in Piston.
 	PistonLanguagePython = "python"
-	// PistonVersionPython represents the version of Python language runtime in Piston.
-	PistonVersionPython = "3.10.0"
-
 	// PistonLanguageRust represents the Rust language runtime in Piston.
 	PistonLanguageRust = "rust"
-	// PistonVersionRust represents the version of Rust language runtime in Piston.
-	PistonVersionRust = "1.68.2"
-
 	// PistonLanguageTypeScript represents the TypeScript language runtime in Piston.
 	PistonLanguageTypeScript = "typescript"
-	// PistonVersionTypeScript represents the version of TypeScript language runtime in Piston.
-	PistonVersionTypeScript = "1.32.3"
 )
 
+// piston execution event types.
+const (
+	// PistonEventTypeQueued represents events emitted when an execution is waiting for a free worker.
+	PistonEventTypeQueued = "queued"
+	// PistonEventTypeCompiling represents events emitted when compilation starts.
+	PistonEventTypeCompiling = "compiling"
+	// PistonEventTypeCompileFinished represents events emitted when compilation finishes.
+	PistonEventTypeCompileFinished = "compile_finished"
+	// PistonEventTypeRunning represents events emitted when the program starts running.
+	PistonEventTypeRunning = "running"
+	// PistonEventTypeStdout represents events carrying a chunk of standard output.
+	PistonEventTypeStdout = "stdout"
+	// PistonEventTypeStderr represents events carrying a chunk of standard error.
+	PistonEventTypeStderr = "stderr"
+)
+
+// piston interactive session message types.
+const (
+	// PistonMessageTypeInit represents messages that start an interactive session.
+	PistonMessageTypeInit = "init"
+	// PistonMessageTypeRuntime represents messages reporting the runtime chosen for an interactive session.
+	PistonMessageTypeRuntime = "runtime"
+	// PistonMessageTypeStage represents messages reporting that a stage of an interactive session started.
+	PistonMessageTypeStage = "stage"
+	// PistonMessageTypeData represents messages carrying a chunk of standard input, output or error.
+	PistonMessageTypeData = "data"
+	// PistonMessageTypeSignal represents messages that send a signal to the running process.
+	PistonMessageTypeSignal = "signal"
+	// PistonMessageTypeExit represents messages reporting that a stage of an interactive session finished.
+	PistonMessageTypeExit = "exit"
+	// PistonMessageTypeError represents messages reporting that an interactive session failed.
+	PistonMessageTypeError = "error"
+)
+
+// piston interactive session streams.
+const (
+	// PistonStreamStdin represents the standard input of an interactive session.
+	PistonStreamStdin = "stdin"
+	// PistonStreamStdout represents the standard output of an interactive session.
+	PistonStreamStdout = "stdout"
+	// PistonStreamStderr represents the standard error of an interactive session.
+	PistonStreamStderr = "stderr"
+)
+
+// piston execution stages.
+const (
+	// PistonStageCompile represents the compilation stage of an execution.
+	PistonStageCompile = "compile"
+	// PistonStageRun represents the runtime stage of an execution.
+	PistonStageRun = "run"
+)
+
+// piston execution statuses, reported by Piston when a stage did not exit normally.
+const (
+	// PistonStatusRuntimeError represents stages that exited with a non-zero exit code.
+	PistonStatusRuntimeError = "RE"
+	// PistonStatusSignal represents stages that were killed by a signal.
+	PistonStatusSignal = "SG"
+	// PistonStatusTimedOut represents stages that were killed for exceeding their time limit.
+	PistonStatusTimedOut = "TO"
+	// PistonStatusStdoutLimitExceeded represents stages that were killed for writing too much standard output.
+	PistonStatusStdoutLimitExceeded = "OL"
+	// PistonStatusStderrLimitExceeded represents stages that were killed for writing too much standard error.
+	PistonStatusStderrLimitExceeded = "EL"
+	// PistonStatusInternalError represents stages that could not be run because of an error in Piston.
+	PistonStatusInternalError = "XX"
+)
+
+// PistonSignalKill is the signal Piston uses to kill stages that exceed their limits.
+const PistonSignalKill = "SIGKILL"
+
+// PistonRuntime represents a language runtime installed on Piston.
+type PistonRuntime struct {
+	Language string   `json:"language"`
+	Version  string   `json:"version"`
+	Aliases  []string `json:"aliases"`
+	Runtime  *string  `json:"runtime"`
+}
+
 // PistonFile represents a file sent for code execution to Piston.
 type PistonFile struct {
 	Name     *string `json:"name"`
@@ -66,12 +126,35 @@ type PistonExecuteRequest struct {
 }
 
 // PistonResults represents code execution results from Piston.
+// Message, Status, CPUTime, WallTime and Memory are only reported by newer versions of Piston.
+// CPUTime and WallTime are in milliseconds, and Memory is in bytes.
 type PistonResults struct {
-	Stdout string  `json:"stdout"`
-	Stderr string  `json:"stderr"`
-	Output string  `json:"output"`
-	Code   *int    `json:"code"`
-	Signal *string `json:"signal"`
+	Stdout   string  `json:"stdout"`
+	Stderr   string  `json:"stderr"`
+	Output   string  `json:"output"`
+	Code     *int    `json:"code"`
+	Signal   *string `json:"signal"`
+	Message  *string `json:"message"`
+	Status   *string `json:"status"`
+	CPUTime  *int64  `json:"cpu_time"`
+	WallTime *int64  `json:"wall_time"`
+	Memory   *int64  `json:"memory"`
+}
+
+// TimedOut reports whether the stage was killed for exceeding its time limit.
+func (results *PistonResults) TimedOut() bool {
+	return results.Status != nil && *results.Status == PistonStatusTimedOut
+}
+
+// MemoryLimitExceeded reports whether the stage was killed for exceeding its memory limit.
+// Piston has no status for this, but kills such stages with SIGKILL,
+// so stages killed with SIGKILL that did not time out are treated as having run out of memory.
+func (results *PistonResults) MemoryLimitExceeded() bool {
+	if results.Status == nil || *results.Status != PistonStatusSignal {
+		return false
+	}
+
+	return results.Signal != nil && *results.Signal == PistonSignalKill
 }
 
 // PistonExecuteResponse represents the response body for Piston code execution requests.
@@ -81,3 +164,36 @@ type PistonExecuteResponse struct {
 	Compile  *PistonResults `json:"compile"`
 	Run      PistonResults  `json:"run"`
 }
+
+// PistonEvent represents a progress event emitted during code execution.
+type PistonEvent struct {
+	Type   string  `json:"type"`
+	Stage  *string `json:"stage"`
+	Data   *string `json:"data"`
+	Code   *int    `json:"code"`
+	Signal *string `json:"signal"`
+}
+
+// PistonMessage represents a message exchanged with Piston during an interactive session.
+// Only the fields relevant to the message type are set.
+type PistonMessage struct {
+	Type               string       `json:"type"`
+	Language           string       `json:"language,omitempty"`
+	Version            string       `json:"version,omitempty"`
+	Files              []PistonFile `json:"files,omitempty"`
+	Args               []string     `json:"args,omitempty"`
+	CompileTimeout     *int64       `json:"compile_timeout,omitempty"`
+	RunTimeout         *int64       `json:"run_timeout,omitempty"`
+	CompileMemoryLimit *int64       `json:"compile_memory_limit,omitempty"`
+	RunMemoryLimit     *int64       `json:"run_memory_limit,omitempty"`
+	Stage              *string      `json:"stage,omitempty"`
+	Stream             *string      `json:"stream,omitempty"`
+	Data               *string      `json:"data,omitempty"`
+	Code               *int         `json:"code,omitempty"`
+	Signal             *string      `json:"signal,omitempty"`
+	Message            *string      `json:"message,omitempty"`
+	Status             *string      `json:"status,omitempty"`
+	CPUTime            *int64       `json:"cpu_time,omitempty"`
+	WallTime           *int64       `json:"wall_time,omitempty"`
+	Memory             *int64       `json:"memory,omitempty"`
+}
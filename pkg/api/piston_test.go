@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPistonResultsLimits(t *testing.T) {
+	t.Parallel()
+
+	statusTimedOut := api.PistonStatusTimedOut
+	statusSignal := api.PistonStatusSignal
+	statusRuntimeError := api.PistonStatusRuntimeError
+	signalKill := api.PistonSignalKill
+	signalSegfault := "SIGSEGV"
+
+	testcases := map[string]struct {
+		results                 api.PistonResults
+		wantTimedOut            bool
+		wantMemoryLimitExceeded bool
+	}{
+		"No status": {
+			results:                 api.PistonResults{},
+			wantTimedOut:            false,
+			wantMemoryLimitExceeded: false,
+		},
+		"Timed out": {
+			results: api.PistonResults{
+				Status: &statusTimedOut,
+				Signal: &signalKill,
+			},
+			wantTimedOut:            true,
+			wantMemoryLimitExceeded: false,
+		},
+		"Killed by SIGKILL": {
+			results: api.PistonResults{
+				Status: &statusSignal,
+				Signal: &signalKill,
+			},
+			wantTimedOut:            false,
+			wantMemoryLimitExceeded: true,
+		},
+		"Killed by other signal": {
+			results: api.PistonResults{
+				Status: &statusSignal,
+				Signal: &signalSegfault,
+			},
+			wantTimedOut:            false,
+			wantMemoryLimitExceeded: false,
+		},
+		"Runtime error": {
+			results: api.PistonResults{
+				Status: &statusRuntimeError,
+			},
+			wantTimedOut:            false,
+			wantMemoryLimitExceeded: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, testcase.wantTimedOut, testcase.results.TimedOut())
+			require.Equal(t, testcase.wantMemoryLimitExceeded, testcase.results.MemoryLimitExceeded())
+		})
+	}
+}
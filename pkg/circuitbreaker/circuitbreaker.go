@@ -0,0 +1,115 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+)
+
+// circuit breaker states.
+const (
+	// StateClosed represents circuit breakers that let all requests through.
+	StateClosed = "closed"
+	// StateOpen represents circuit breakers that reject all requests until the cooldown passes.
+	StateOpen = "open"
+	// StateHalfOpen represents circuit breakers that let a single trial request through
+	// to decide whether to close or open again.
+	StateHalfOpen = "half_open"
+)
+
+// Breaker is a circuit breaker.
+// It opens after a given number of consecutive failures and rejects requests while open.
+// Once the cooldown passes, a single trial request is let through,
+// which closes the breaker if it succeeds and opens it again if it fails.
+// A trial that reports neither outcome is given up on after another cooldown.
+type Breaker struct {
+	mu               sync.Mutex
+	timeProvider     timekeeper.Provider
+	failureThreshold int
+	cooldown         time.Duration
+	state            string
+	failures         int
+	openedAt         time.Time
+	trialStartedAt   *time.Time
+}
+
+// NewBreaker returns a new Breaker that opens after failureThreshold consecutive failures
+// and stays open for cooldown. The breaker starts closed.
+func NewBreaker(timeProvider timekeeper.Provider, failureThreshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		timeProvider:     timeProvider,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		state:            StateClosed,
+		failures:         0,
+		trialStartedAt:   nil,
+	}
+}
+
+// Allow reports whether a request may proceed.
+// If not, it also returns how long the caller should wait before trying again.
+func (b *Breaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.timeProvider.Now()
+
+	switch b.state {
+	case StateOpen:
+		reopensAt := b.openedAt.Add(b.cooldown)
+		if now.Before(reopensAt) {
+			return false, reopensAt.Sub(now)
+		}
+
+		b.state = StateHalfOpen
+		b.trialStartedAt = &now
+
+		return true, 0
+	case StateHalfOpen:
+		if b.trialStartedAt != nil {
+			trialGivenUpAt := b.trialStartedAt.Add(b.cooldown)
+			if now.Before(trialGivenUpAt) {
+				return false, trialGivenUpAt.Sub(now)
+			}
+		}
+
+		b.trialStartedAt = &now
+
+		return true, 0
+	default:
+		return true, 0
+	}
+}
+
+// RecordSuccess records a successful request, closing the breaker.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.trialStartedAt = nil
+}
+
+// RecordFailure records a failed request,
+// opening the breaker if the failure threshold is reached or if the request was a trial.
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.timeProvider.Now()
+		b.trialStartedAt = nil
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
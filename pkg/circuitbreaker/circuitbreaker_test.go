@@ -0,0 +1,111 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/circuitbreaker"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	breaker := circuitbreaker.NewBreaker(timeProvider, 3, time.Minute)
+
+	breaker.RecordFailure()
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+	breaker.RecordFailure()
+	require.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	ok, _ := breaker.Allow()
+	require.True(t, ok)
+
+	breaker.RecordFailure()
+	require.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	timeProvider.Add(20 * time.Second)
+
+	ok, retryAfter := breaker.Allow()
+	require.False(t, ok)
+	require.Equal(t, 40*time.Second, retryAfter)
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		trialSucceeds bool
+		wantState     string
+	}{
+		"Successful trial closes breaker": {
+			trialSucceeds: true,
+			wantState:     circuitbreaker.StateClosed,
+		},
+		"Failed trial opens breaker": {
+			trialSucceeds: false,
+			wantState:     circuitbreaker.StateOpen,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			timeProvider := timekeeper.NewFrozenProvider()
+			breaker := circuitbreaker.NewBreaker(timeProvider, 1, time.Minute)
+
+			breaker.RecordFailure()
+			require.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+			timeProvider.Add(time.Minute)
+
+			ok, _ := breaker.Allow()
+			require.True(t, ok)
+			require.Equal(t, circuitbreaker.StateHalfOpen, breaker.State())
+
+			// only a single trial is let through at a time
+			ok, _ = breaker.Allow()
+			require.False(t, ok)
+
+			if testcase.trialSucceeds {
+				breaker.RecordSuccess()
+			} else {
+				breaker.RecordFailure()
+			}
+
+			require.Equal(t, testcase.wantState, breaker.State())
+
+			ok, _ = breaker.Allow()
+			require.Equal(t, testcase.trialSucceeds, ok)
+		})
+	}
+}
+
+func TestBreakerHalfOpenAbandonedTrial(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	breaker := circuitbreaker.NewBreaker(timeProvider, 1, time.Minute)
+
+	breaker.RecordFailure()
+	timeProvider.Add(time.Minute)
+
+	ok, _ := breaker.Allow()
+	require.True(t, ok)
+
+	timeProvider.Add(30 * time.Second)
+
+	ok, retryAfter := breaker.Allow()
+	require.False(t, ok)
+	require.Equal(t, 30*time.Second, retryAfter)
+
+	timeProvider.Add(30 * time.Second)
+
+	ok, _ = breaker.Allow()
+	require.True(t, ok)
+}
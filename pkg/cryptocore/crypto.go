@@ -1,7 +1,11 @@
 package cryptocore
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
@@ -44,6 +48,10 @@ const (
 	APIKeyPrefixLength = 8
 	// APIKeySecretNBytes is the number of bytes in API key secrets.
 	APIKeySecretNBytes = 32
+	// SecretEncryptionKeyNBytes is the number of bytes in the key used to encrypt secrets.
+	SecretEncryptionKeyNBytes = 32
+	// secretEncryptionKeyInfo is the context used to derive the key used to encrypt secrets from the secret key.
+	secretEncryptionKeyInfo = "nymphadora secret encryption"
 )
 
 // AuthJWTClaims represents claims in JWTs used for user authentication.
@@ -98,6 +106,8 @@ type Crypto interface {
 		accessLevel int,
 	) (string, error)
 	ValidateCodeSpaceInvitationJWT(token string) (*CodeSpaceInvitationJWTClaims, bool)
+	EncryptSecret(secret string) (string, error)
+	DecryptSecret(encryptedSecret string) (string, error)
 }
 
 // crypto implements Crypto.
@@ -365,3 +375,70 @@ func (c *crypto) ValidateCodeSpaceInvitationJWT(token string) (*CodeSpaceInvitat
 
 	return claims, true
 }
+
+// secretCipher returns the AES-GCM cipher used to encrypt secrets,
+// using a key derived from the secret key.
+func (c *crypto) secretCipher() (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, []byte(c.secretKey), nil, secretEncryptionKeyInfo, SecretEncryptionKeyNBytes)
+	if err != nil {
+		return nil, errutils.FormatError(err, "hkdf.Key failed")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errutils.FormatError(err, "aes.NewCipher failed")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errutils.FormatError(err, "cipher.NewGCM failed")
+	}
+
+	return aead, nil
+}
+
+// EncryptSecret encrypts a given secret so that it can be stored at rest.
+// The encrypted secret is encoded in base64, along with the random nonce used to encrypt it.
+func (c *crypto) EncryptSecret(secret string) (string, error) {
+	aead, err := c.secretCipher()
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	encryptedSecretBytes := aead.Seal(nonce, nonce, []byte(secret), nil)
+	encryptedSecret := base64.StdEncoding.EncodeToString(encryptedSecretBytes)
+
+	return encryptedSecret, nil
+}
+
+// DecryptSecret decrypts a given secret encrypted using EncryptSecret.
+// Returns error when the secret was not encrypted with the same secret key, or was tampered with.
+func (c *crypto) DecryptSecret(encryptedSecret string) (string, error) {
+	aead, err := c.secretCipher()
+	if err != nil {
+		return "", errutils.FormatError(err)
+	}
+
+	encryptedSecretBytes, err := base64.StdEncoding.DecodeString(encryptedSecret)
+	if err != nil {
+		return "", errutils.FormatError(err, "base64.StdEncoding.DecodeString failed")
+	}
+
+	if len(encryptedSecretBytes) < aead.NonceSize() {
+		return "", errutils.FormatErrorf(nil, "encrypted secret of length %d is too short", len(encryptedSecretBytes))
+	}
+
+	nonce, ciphertext := encryptedSecretBytes[:aead.NonceSize()], encryptedSecretBytes[aead.NonceSize():]
+	secretBytes, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errutils.FormatError(err, "aead.Open failed")
+	}
+
+	return string(secretBytes), nil
+}
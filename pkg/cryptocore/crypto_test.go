@@ -1,6 +1,7 @@
 package cryptocore_test
 
 import (
+	"encoding/base64"
 	"regexp"
 	"testing"
 	"time"
@@ -627,3 +628,95 @@ func TestCryptoValidateCodeSpaceInvitationJWT(t *testing.T) {
 		})
 	}
 }
+
+func TestCryptoEncryptSecret(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	c := cryptocore.NewCrypto(timeProvider, "deadbeef")
+
+	secret := "Mischief managed"
+
+	encryptedSecret, err := c.EncryptSecret(secret)
+	require.NoError(t, err)
+	require.NotContains(t, encryptedSecret, secret)
+
+	otherEncryptedSecret, err := c.EncryptSecret(secret)
+	require.NoError(t, err)
+	require.NotEqual(t, encryptedSecret, otherEncryptedSecret)
+
+	decryptedSecret, err := c.DecryptSecret(encryptedSecret)
+	require.NoError(t, err)
+	require.Equal(t, secret, decryptedSecret)
+
+	decryptedSecret, err = c.DecryptSecret(otherEncryptedSecret)
+	require.NoError(t, err)
+	require.Equal(t, secret, decryptedSecret)
+}
+
+func TestCryptoDecryptSecret(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	c := cryptocore.NewCrypto(timeProvider, "deadbeef")
+
+	secret := "Mischief managed"
+	encryptedSecret, err := c.EncryptSecret(secret)
+	require.NoError(t, err)
+
+	encryptedSecretBytes, err := base64.StdEncoding.DecodeString(encryptedSecret)
+	require.NoError(t, err)
+
+	encryptedSecretBytes[len(encryptedSecretBytes)-1] ^= 0xff
+	tamperedSecret := base64.StdEncoding.EncodeToString(encryptedSecretBytes)
+
+	testcases := map[string]struct {
+		secretKey       string
+		encryptedSecret string
+		wantErr         bool
+	}{
+		"Valid encrypted secret": {
+			secretKey:       "deadbeef",
+			encryptedSecret: encryptedSecret,
+			wantErr:         false,
+		},
+		"Different secret key": {
+			secretKey:       "0xdeadbeef",
+			encryptedSecret: encryptedSecret,
+			wantErr:         true,
+		},
+		"Tampered encrypted secret": {
+			secretKey:       "deadbeef",
+			encryptedSecret: tamperedSecret,
+			wantErr:         true,
+		},
+		"Encrypted secret too short": {
+			secretKey:       "deadbeef",
+			encryptedSecret: base64.StdEncoding.EncodeToString([]byte("deadbeef")),
+			wantErr:         true,
+		},
+		"Invalid base64": {
+			secretKey:       "deadbeef",
+			encryptedSecret: "!deadbeef!",
+			wantErr:         true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := cryptocore.NewCrypto(timeProvider, testcase.secretKey)
+
+			decryptedSecret, err := c.DecryptSecret(testcase.encryptedSecret)
+			if testcase.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, secret, decryptedSecret)
+		})
+	}
+}
@@ -116,6 +116,36 @@ func (mr *MockCryptoMockRecorder) CreateCodeSpaceInvitationJWT(userUUID, invitee
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCodeSpaceInvitationJWT", reflect.TypeOf((*MockCrypto)(nil).CreateCodeSpaceInvitationJWT), userUUID, inviteeEmail, codeSpaceID, accessLevel)
 }
 
+// DecryptSecret mocks base method.
+func (m *MockCrypto) DecryptSecret(encryptedSecret string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecryptSecret", encryptedSecret)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecryptSecret indicates an expected call of DecryptSecret.
+func (mr *MockCryptoMockRecorder) DecryptSecret(encryptedSecret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptSecret", reflect.TypeOf((*MockCrypto)(nil).DecryptSecret), encryptedSecret)
+}
+
+// EncryptSecret mocks base method.
+func (m *MockCrypto) EncryptSecret(secret string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncryptSecret", secret)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncryptSecret indicates an expected call of EncryptSecret.
+func (mr *MockCryptoMockRecorder) EncryptSecret(secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptSecret", reflect.TypeOf((*MockCrypto)(nil).EncryptSecret), secret)
+}
+
 // HashPassword mocks base method.
 func (m *MockCrypto) HashPassword(password string) (string, error) {
 	m.ctrl.T.Helper()
@@ -4,15 +4,46 @@ import "errors"
 
 // General shared errors.
 var (
-	ErrInvalidToken                 = errors.New("invalid token")
-	ErrInvalidCredentials           = errors.New("invalid credentials")
-	ErrUserAlreadyExists            = errors.New("user already exists")
-	ErrUserNotFound                 = errors.New("user not found")
-	ErrAPIKeyAlreadyExists          = errors.New("api key already exists")
-	ErrAPIKeyNotFound               = errors.New("api key not found")
-	ErrCodeSpaceAlreadyExists       = errors.New("code space already exists")
-	ErrCodeSpaceNotFound            = errors.New("code space not found")
-	ErrCodeSpaceAccessNotFound      = errors.New("code space access not found")
-	ErrCodeSpaceAccessDenied        = errors.New("code space access denied")
-	ErrCodeSpaceUnsupportedLanguage = errors.New("code space language not supported")
+	ErrInvalidToken                    = errors.New("invalid token")
+	ErrInvalidCredentials              = errors.New("invalid credentials")
+	ErrUserAlreadyExists               = errors.New("user already exists")
+	ErrUserNotFound                    = errors.New("user not found")
+	ErrAPIKeyAlreadyExists             = errors.New("api key already exists")
+	ErrAPIKeyNotFound                  = errors.New("api key not found")
+	ErrCodeSpaceAlreadyExists          = errors.New("code space already exists")
+	ErrCodeSpaceNotFound               = errors.New("code space not found")
+	ErrCodeSpaceAccessNotFound         = errors.New("code space access not found")
+	ErrCodeSpaceAccessDenied           = errors.New("code space access denied")
+	ErrCodeSpaceUnsupportedLanguage    = errors.New("code space language not supported")
+	ErrCodeSpaceUnsupportedVersion     = errors.New("code space language version not supported")
+	ErrCodeSpaceRunLimitExceeded       = errors.New("code space run limit exceeded")
+	ErrCodeExecutionQueueFull          = errors.New("code execution queue full")
+	ErrCodeExecutionRateLimited        = errors.New("code execution rate limited")
+	ErrCodeExecutionUnavailable        = errors.New("code execution unavailable")
+	ErrCodeExecutionNotStarted         = errors.New("code execution not started")
+	ErrCodeExecutionRejected           = errors.New("code execution rejected")
+	ErrCodeExecutionRuntimeNotFound    = errors.New("code execution runtime not found")
+	ErrCodeExecutionNotInteractive     = errors.New("code execution not interactive")
+	ErrExecutionQuotaExceeded          = errors.New("execution quota exceeded")
+	ErrCodeSpaceRunNotFound            = errors.New("code space run not found")
+	ErrCodeSpaceRunCancelled           = errors.New("code space run cancelled")
+	ErrCodeSpaceFileAlreadyExists      = errors.New("code space file already exists")
+	ErrCodeSpaceFileNotFound           = errors.New("code space file not found")
+	ErrCodeSpaceFileLimitExceeded      = errors.New("code space file limit exceeded")
+	ErrCodeSpaceTestCaseNotFound       = errors.New("code space test case not found")
+	ErrCodeSpaceTestCaseLimitExceeded  = errors.New("code space test case limit exceeded")
+	ErrCodeSpaceTestCaseInvalidPattern = errors.New("code space test case pattern invalid")
+	ErrCodeSpaceRunConfigAlreadyExists = errors.New("code space run config already exists")
+	ErrCodeSpaceRunConfigNotFound      = errors.New("code space run config not found")
+	ErrCodeSpaceRunConfigLimitExceeded = errors.New("code space run config limit exceeded")
+	ErrCodeSpaceEnvVarAlreadyExists    = errors.New("code space env var already exists")
+	ErrCodeSpaceEnvVarNotFound         = errors.New("code space env var not found")
+	ErrCodeSpaceEnvVarLimitExceeded    = errors.New("code space env var limit exceeded")
+	ErrCodeSpaceEnvVarsUnsupported     = errors.New("code space env vars not supported")
+	ErrCodeSpaceRevisionNotFound       = errors.New("code space revision not found")
+	ErrCodeSpaceVersionConflict        = errors.New("code space version conflict")
+	ErrCodeSpaceEditInvalid            = errors.New("code space edit invalid")
+	ErrCodeSpaceEditOutdated           = errors.New("code space edit outdated")
+	ErrCodeSpaceCollabClosed           = errors.New("code space collaboration closed")
+	ErrCodeSpaceCursorInvalid          = errors.New("code space cursor invalid")
 )
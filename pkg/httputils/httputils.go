@@ -19,6 +19,16 @@ const (
 	HTTPHeaderContentType = "Content-Type"
 	// HTTPHeaderAuthorization is the header used for authentication/authorization credentials.
 	HTTPHeaderAuthorization = "Authorization"
+	// HTTPHeaderCacheControl is the header that defines caching directives.
+	HTTPHeaderCacheControl = "Cache-Control"
+	// HTTPHeaderConnection is the header that controls whether the connection stays open.
+	HTTPHeaderConnection = "Connection"
+	// HTTPHeaderRetryAfter is the header that indicates how long to wait before making a follow-up request.
+	HTTPHeaderRetryAfter = "Retry-After"
+	// HTTPHeaderETag is the header that identifies the version of a resource.
+	HTTPHeaderETag = "ETag"
+	// HTTPHeaderIfMatch is the header that makes a request conditional on the version of a resource.
+	HTTPHeaderIfMatch = "If-Match"
 )
 
 // HTTPClient represents HTTP clients.
@@ -1,7 +1,10 @@
 package httputils
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/alvii147/nymphadora-api/pkg/errutils"
@@ -57,6 +60,58 @@ func (w *ResponseWriter) WriteJSON(data any, statusCode int) {
 	}
 }
 
+// Flush sends any buffered data in ResponseWriter to the client.
+func (w *ResponseWriter) Flush() error {
+	err := http.NewResponseController(w.ResponseWriter).Flush()
+	if err != nil {
+		return errutils.FormatError(err, "http.ResponseController.Flush failed")
+	}
+
+	return nil
+}
+
+// Hijack lets the caller take over the connection of ResponseWriter, such as to switch to the WebSocket protocol.
+// The status code is recorded as 101 Switching Protocols, since connections are only taken over to switch protocols.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, errutils.FormatError(err, "http.ResponseController.Hijack failed")
+	}
+
+	w.StatusCode = http.StatusSwitchingProtocols
+
+	return conn, rw, nil
+}
+
+// WriteEventHeaders writes the headers and status code for a Server-Sent Events stream to ResponseWriter.
+func (w *ResponseWriter) WriteEventHeaders() {
+	w.Header().Set(HTTPHeaderContentType, "text/event-stream")
+	w.Header().Set(HTTPHeaderCacheControl, "no-cache")
+	w.Header().Set(HTTPHeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+}
+
+// WriteEvent writes a Server-Sent Event with a given event type and JSON data to ResponseWriter,
+// then flushes it to the client.
+func (w *ResponseWriter) WriteEvent(event string, data any) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return errutils.FormatError(err, "json.Marshal failed")
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, dataBytes)
+	if err != nil {
+		return errutils.FormatError(err, "fmt.Fprintf failed")
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
 // ResponseWriterMiddleware converts a HandlerFunc to an http.Handler.
 // This should be the top-level middleware when setting up routes.
 func ResponseWriterMiddleware(next HandlerFunc) http.Handler {
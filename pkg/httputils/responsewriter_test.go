@@ -1,8 +1,10 @@
 package httputils_test
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -162,6 +164,137 @@ func TestResponseWriterWriteJSONError(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, w.StatusCode)
 }
 
+func TestResponseWriterFlushSuccess(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	w := httputils.NewResponseWriter(rec)
+
+	err := w.Flush()
+	require.NoError(t, err)
+	require.True(t, rec.Flushed)
+}
+
+func TestResponseWriterFlushError(t *testing.T) {
+	t.Parallel()
+
+	w := httputils.NewResponseWriter(&mockResponseWriter{
+		headers: map[string][]string{},
+	})
+
+	err := w.Flush()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+}
+
+type hijackableResponseWriter struct {
+	mockResponseWriter
+	conn net.Conn
+}
+
+func (w *hijackableResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestResponseWriterHijackSuccess(t *testing.T) {
+	t.Parallel()
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		serverConn.Close()
+		clientConn.Close()
+	})
+
+	w := httputils.NewResponseWriter(&hijackableResponseWriter{
+		mockResponseWriter: mockResponseWriter{
+			headers: map[string][]string{},
+		},
+		conn: serverConn,
+	})
+
+	conn, rw, err := w.Hijack()
+	require.NoError(t, err)
+	require.Equal(t, serverConn, conn)
+	require.NotNil(t, rw)
+	require.Equal(t, http.StatusSwitchingProtocols, w.StatusCode)
+}
+
+func TestResponseWriterHijackError(t *testing.T) {
+	t.Parallel()
+
+	w := httputils.NewResponseWriter(&mockResponseWriter{
+		headers: map[string][]string{},
+	})
+
+	_, _, err := w.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+	require.Equal(t, http.StatusOK, w.StatusCode)
+}
+
+func TestResponseWriterWriteEventHeaders(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	w := httputils.NewResponseWriter(rec)
+
+	w.WriteEventHeaders()
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	require.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	require.Equal(t, "keep-alive", rec.Header().Get("Connection"))
+}
+
+func TestResponseWriterWriteEventSuccess(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	w := httputils.NewResponseWriter(rec)
+
+	err := w.WriteEvent("stdout", map[string]any{"data": "Hello"})
+	require.NoError(t, err)
+	require.Equal(t, "event: stdout\ndata: {\"data\":\"Hello\"}\n\n", rec.Body.String())
+	require.True(t, rec.Flushed)
+}
+
+func TestResponseWriterWriteEventError(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		data     any
+		writeErr error
+	}{
+		"Marshal fails": {
+			data:     make(chan int),
+			writeErr: nil,
+		},
+		"Write fails": {
+			data:     map[string]any{},
+			writeErr: errors.New("Write failed"),
+		},
+		"Flush fails": {
+			data:     map[string]any{},
+			writeErr: nil,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httputils.NewResponseWriter(&mockResponseWriter{
+				headers:  map[string][]string{},
+				writeErr: testcase.writeErr,
+			})
+
+			err := w.WriteEvent("stdout", testcase.data)
+			require.Error(t, err)
+
+			if testcase.writeErr != nil {
+				require.ErrorIs(t, err, testcase.writeErr)
+			}
+		})
+	}
+}
+
 func TestResponseWriterMiddleware(t *testing.T) {
 	t.Parallel()
 
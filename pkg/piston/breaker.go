@@ -0,0 +1,124 @@
+package piston
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/circuitbreaker"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+// circuitBreakingClient implements a Client that wraps another Client with a circuit breaker.
+// Requests that fail because Piston is unavailable count as failures,
+// and while the breaker is open, requests fail fast with a CircuitOpenError instead of being sent.
+// Requests that are cancelled or rejected by a full queue in front of Piston don't count either way,
+// since they say nothing about Piston's health.
+type circuitBreakingClient struct {
+	client  Client
+	breaker *circuitbreaker.Breaker
+}
+
+// NewCircuitBreakingClient returns a new circuitBreakingClient.
+func NewCircuitBreakingClient(client Client, breaker *circuitbreaker.Breaker) *circuitBreakingClient {
+	return &circuitBreakingClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+// Execute executes the request using the wrapped Client, unless the circuit breaker is open.
+func (c *circuitBreakingClient) Execute(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+) (*api.PistonExecuteResponse, error) {
+	var resp *api.PistonExecuteResponse
+	err := c.do(ctx, func() error {
+		var err error
+		resp, err = c.client.Execute(ctx, data)
+
+		return err
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteStream executes the request using the wrapped Client, reporting progress events to onEvent,
+// unless the circuit breaker is open.
+func (c *circuitBreakingClient) ExecuteStream(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	var resp *api.PistonExecuteResponse
+	err := c.do(ctx, func() error {
+		var err error
+		resp, err = ExecuteStream(ctx, c.client, data, onEvent)
+
+		return err
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// Connect starts an interactive execution using the wrapped Client, unless the circuit breaker is open.
+// Only starting the session counts towards the circuit breaker.
+func (c *circuitBreakingClient) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	var session Session
+	err := c.do(ctx, func() error {
+		var err error
+		session, err = Connect(ctx, c.client, data)
+
+		return err
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return session, nil
+}
+
+// Runtimes lists the runtimes using the wrapped Client, unless the circuit breaker is open.
+func (c *circuitBreakingClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	var runtimes []*api.PistonRuntime
+	err := c.do(ctx, func() error {
+		var err error
+		runtimes, err = c.client.Runtimes(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return runtimes, nil
+}
+
+// do calls fn if the circuit breaker allows it and records its outcome on the circuit breaker.
+func (c *circuitBreakingClient) do(ctx context.Context, fn func() error) error {
+	ok, retryAfter := c.breaker.Allow()
+	if !ok {
+		return &CircuitOpenError{
+			RetryAfter: retryAfter,
+		}
+	}
+
+	err := fn()
+	if err != nil && (ctx.Err() != nil || errors.Is(err, errutils.ErrCodeExecutionQueueFull)) {
+		return err
+	}
+
+	if errors.Is(err, errutils.ErrCodeExecutionUnavailable) {
+		c.breaker.RecordFailure()
+	} else {
+		c.breaker.RecordSuccess()
+	}
+
+	return err
+}
@@ -0,0 +1,164 @@
+package piston_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/circuitbreaker"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCircuitBreakingClientExecuteOpens(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	timeProvider := timekeeper.NewFrozenProvider()
+	breaker := circuitbreaker.NewBreaker(timeProvider, 2, time.Minute)
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(nil, errutils.FormatError(errutils.ErrCodeExecutionUnavailable)).
+		Times(2)
+
+	client := piston.NewCircuitBreakingClient(innerClient, breaker)
+
+	for range 2 {
+		_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+		require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+	}
+
+	require.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	timeProvider.Add(15 * time.Second)
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+
+	var circuitErr *piston.CircuitOpenError
+	require.ErrorAs(t, err, &circuitErr)
+	require.Equal(t, 45*time.Second, circuitErr.RetryAfter)
+}
+
+func TestCircuitBreakingClientExecuteRecordsOutcome(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		err       error
+		cancelled bool
+		wantState string
+	}{
+		"Unavailable is a failure": {
+			err:       errutils.FormatError(errutils.ErrCodeExecutionUnavailable),
+			cancelled: false,
+			wantState: circuitbreaker.StateOpen,
+		},
+		"Rate limited is not a failure": {
+			err:       errutils.FormatError(errutils.ErrCodeExecutionRateLimited),
+			cancelled: false,
+			wantState: circuitbreaker.StateClosed,
+		},
+		"Rejected is not a failure": {
+			err:       errutils.FormatError(errutils.ErrCodeExecutionRejected),
+			cancelled: false,
+			wantState: circuitbreaker.StateClosed,
+		},
+		"Cancelled is not recorded": {
+			err:       errutils.FormatError(errutils.ErrCodeExecutionUnavailable),
+			cancelled: true,
+			wantState: circuitbreaker.StateClosed,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			innerClient := pistonmocks.NewMockClient(ctrl)
+			breaker := circuitbreaker.NewBreaker(timekeeper.NewFrozenProvider(), 1, time.Minute)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			innerClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+					if testcase.cancelled {
+						cancel()
+					}
+
+					return nil, testcase.err
+				}).
+				Times(1)
+
+			client := piston.NewCircuitBreakingClient(innerClient, breaker)
+
+			_, err := client.Execute(ctx, &api.PistonExecuteRequest{})
+			require.ErrorIs(t, err, testcase.err)
+			require.Equal(t, testcase.wantState, breaker.State())
+		})
+	}
+}
+
+func TestCircuitBreakingClientExecuteQueueFullNotRecorded(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	timeProvider := timekeeper.NewFrozenProvider()
+	breaker := circuitbreaker.NewBreaker(timeProvider, 1, time.Minute)
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(nil, errutils.FormatError(errutils.ErrCodeExecutionQueueFull)).
+		Times(1)
+
+	client := piston.NewCircuitBreakingClient(innerClient, breaker)
+
+	breaker.RecordFailure()
+	timeProvider.Add(time.Minute)
+
+	// the trial never reached Piston, so it neither closes nor opens the breaker
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionQueueFull)
+	require.Equal(t, circuitbreaker.StateHalfOpen, breaker.State())
+}
+
+func TestCircuitBreakingClientRuntimes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	breaker := circuitbreaker.NewBreaker(timekeeper.NewFrozenProvider(), 1, time.Minute)
+
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+	}
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(wantRuntimes, nil).
+		Times(1)
+
+	client := piston.NewCircuitBreakingClient(innerClient, breaker)
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRuntimes, runtimes)
+	require.Equal(t, circuitbreaker.StateClosed, breaker.State())
+}
@@ -0,0 +1,131 @@
+package piston
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+)
+
+const (
+	// CacheTypeNone represents disabled caching of execution results.
+	CacheTypeNone = "none"
+	// CacheTypeMemory represents caches that keep execution results in memory.
+	CacheTypeMemory = "memory"
+	// CacheTypeDatabase represents caches that keep execution results in the database.
+	CacheTypeDatabase = "database"
+)
+
+// Cache represents a store of execution results keyed by CacheKey.
+type Cache interface {
+	Get(ctx context.Context, key string) (*api.PistonExecuteResponse, bool, error)
+	Set(ctx context.Context, key string, resp *api.PistonExecuteResponse) error
+}
+
+// CacheKey computes the cache key for a given execution request.
+// The key is a hash of everything that determines the outcome of the execution,
+// that is the language, version, files, standard input, arguments and limits.
+func CacheKey(req *api.PistonExecuteRequest) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", errutils.FormatError(err, "json.Marshal failed")
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// memoryCacheEntry represents a single execution result stored in a memoryCache.
+type memoryCacheEntry struct {
+	key       string
+	resp      *api.PistonExecuteResponse
+	expiresAt time.Time
+}
+
+// memoryCache implements a Cache that keeps execution results in memory.
+// Entries expire after a fixed time to live,
+// and the least recently used entries are evicted once the cache holds the maximum number of entries.
+type memoryCache struct {
+	timeProvider timekeeper.Provider
+	ttl          time.Duration
+	maxEntries   int
+	mu           sync.Mutex
+	entries      map[string]*list.Element
+	order        *list.List
+}
+
+// NewMemoryCache returns a new memoryCache.
+func NewMemoryCache(timeProvider timekeeper.Provider, ttl time.Duration, maxEntries int) *memoryCache {
+	return &memoryCache{
+		timeProvider: timeProvider,
+		ttl:          ttl,
+		maxEntries:   maxEntries,
+		entries:      make(map[string]*list.Element),
+		order:        list.New(),
+	}
+}
+
+// Get gets the execution result for a given key, if it is cached and has not expired.
+func (c *memoryCache) Get(_ context.Context, key string) (*api.PistonExecuteResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry, _ := elem.Value.(*memoryCacheEntry)
+	if !c.timeProvider.Now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.resp, true, nil
+}
+
+// Set caches the execution result for a given key,
+// evicting the least recently used entry if the cache is full.
+func (c *memoryCache) Set(_ context.Context, key string, resp *api.PistonExecuteResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.timeProvider.Now().Add(c.ttl)
+
+	elem, ok := c.entries[key]
+	if ok {
+		entry, _ := elem.Value.(*memoryCacheEntry)
+		entry.resp = resp
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{
+		key:       key,
+		resp:      resp,
+		expiresAt: expiresAt,
+	})
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		entry, _ := oldest.Value.(*memoryCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+	}
+
+	return nil
+}
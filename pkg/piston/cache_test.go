@@ -0,0 +1,157 @@
+package piston_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	fileName := "main.py"
+	stdin := "Harry"
+	otherStdin := "Hermione"
+	runTimeout := int64(3000)
+
+	req := &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+		Files: []api.PistonFile{
+			{
+				Name:    &fileName,
+				Content: "print(input())",
+			},
+		},
+		Stdin: &stdin,
+		Args:  []string{"--verbose"},
+	}
+
+	key, err := piston.CacheKey(req)
+	require.NoError(t, err)
+	require.Len(t, key, 64)
+
+	sameReq := *req
+	sameKey, err := piston.CacheKey(&sameReq)
+	require.NoError(t, err)
+	require.Equal(t, key, sameKey)
+
+	testcases := map[string]func(r *api.PistonExecuteRequest){
+		"Different version": func(r *api.PistonExecuteRequest) {
+			r.Version = "3.9.4"
+		},
+		"Different files": func(r *api.PistonExecuteRequest) {
+			r.Files = []api.PistonFile{
+				{
+					Name:    &fileName,
+					Content: "print('hello')",
+				},
+			}
+		},
+		"Different stdin": func(r *api.PistonExecuteRequest) {
+			r.Stdin = &otherStdin
+		},
+		"Different args": func(r *api.PistonExecuteRequest) {
+			r.Args = []string{"--quiet"}
+		},
+		"Different limits": func(r *api.PistonExecuteRequest) {
+			r.RunTimeout = &runTimeout
+		},
+	}
+
+	for name, modify := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			otherReq := *req
+			modify(&otherReq)
+
+			otherKey, err := piston.CacheKey(&otherReq)
+			require.NoError(t, err)
+			require.NotEqual(t, key, otherKey)
+		})
+	}
+}
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	cache := piston.NewMemoryCache(timeProvider, time.Minute, 8)
+
+	resp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+
+	_, ok, err := cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = cache.Set(context.Background(), "key", resp)
+	require.NoError(t, err)
+
+	cachedResp, ok, err := cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, resp, cachedResp)
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	cache := piston.NewMemoryCache(timeProvider, time.Minute, 8)
+
+	err := cache.Set(context.Background(), "key", &api.PistonExecuteResponse{})
+	require.NoError(t, err)
+
+	timeProvider.Add(59 * time.Second)
+
+	_, ok, err := cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	timeProvider.Add(time.Second)
+
+	_, ok, err = cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	cache := piston.NewMemoryCache(timeProvider, time.Minute, 2)
+
+	for _, key := range []string{"harry", "ron"} {
+		err := cache.Set(context.Background(), key, &api.PistonExecuteResponse{})
+		require.NoError(t, err)
+	}
+
+	// using harry makes ron the least recently used entry
+	_, ok, err := cache.Get(context.Background(), "harry")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	err = cache.Set(context.Background(), "hermione", &api.PistonExecuteResponse{})
+	require.NoError(t, err)
+
+	wantCached := map[string]bool{
+		"harry":    true,
+		"ron":      false,
+		"hermione": true,
+	}
+
+	for key, want := range wantCached {
+		_, ok, err := cache.Get(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, want, ok, key)
+	}
+}
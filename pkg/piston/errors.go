@@ -0,0 +1,98 @@
+package piston
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+const (
+	// RateLimitedRetryAfter is the duration callers are advised to wait before retrying
+	// when Piston is rate limiting requests.
+	RateLimitedRetryAfter = 5 * time.Second
+	// UnavailableRetryAfter is the duration callers are advised to wait before retrying
+	// when Piston is unavailable.
+	UnavailableRetryAfter = 30 * time.Second
+)
+
+// pistonRuntimeUnknownMessage is the part of Piston's error message
+// that indicates the requested runtime is not installed.
+const pistonRuntimeUnknownMessage = "runtime is unknown"
+
+// CircuitOpenError represents a request that was rejected without being sent to Piston
+// because Piston has recently been failing.
+// It wraps errutils.ErrCodeExecutionUnavailable.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+// Error returns the error message of a request rejected by an open circuit breaker.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: circuit breaker open, retry after %s", errutils.ErrCodeExecutionUnavailable, e.RetryAfter)
+}
+
+// Unwrap returns errutils.ErrCodeExecutionUnavailable, so that open circuits can be detected using errors.Is.
+func (e *CircuitOpenError) Unwrap() error {
+	return errutils.ErrCodeExecutionUnavailable
+}
+
+// classifyStatusCode returns the error that describes a non-200 response from Piston.
+// Rate limiting, bad gateway and service unavailable responses mean the code was never executed,
+// other server errors mean Piston is unavailable but may have executed the code,
+// and other client errors are classified as rejections of the request itself.
+func classifyStatusCode(statusCode int, body []byte) error {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return errutils.ErrCodeExecutionRateLimited
+	case statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable:
+		return errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted)
+	case statusCode >= http.StatusInternalServerError:
+		return errutils.ErrCodeExecutionUnavailable
+	case bytes.Contains(body, []byte(pistonRuntimeUnknownMessage)):
+		return errutils.ErrCodeExecutionRuntimeNotFound
+	default:
+		return errutils.ErrCodeExecutionRejected
+	}
+}
+
+// classifyTransportError returns the error that describes a failure to get a response from Piston.
+// Failures caused by the context being done are returned as they are,
+// while other failures mean Piston could not be reached.
+// Failures to connect to Piston also mean the code was never executed,
+// but timeouts and failures after connecting may have cut off a request Piston was already executing.
+func classifyTransportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return errors.Join(errutils.ErrCodeExecutionUnavailable, err)
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted, err)
+	}
+
+	return errors.Join(errutils.ErrCodeExecutionUnavailable, err)
+}
+
+// IsRetryable reports whether a given error from a Client is worth retrying.
+// Only requests that were rate limited or that Piston never started executing are retryable,
+// so that code is never executed more than once per request,
+// and requests rejected by an open circuit breaker are not retried either.
+func IsRetryable(err error) bool {
+	var circuitErr *CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		return false
+	}
+
+	return errors.Is(err, errutils.ErrCodeExecutionRateLimited) || errors.Is(err, errutils.ErrCodeExecutionNotStarted)
+}
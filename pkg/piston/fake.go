@@ -0,0 +1,257 @@
+package piston
+
+import (
+	"context"
+	"io"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+// fakeSessionBufferSize is the number of messages a fakeSession holds before writing to it blocks.
+const fakeSessionBufferSize = 64
+
+// fakeClient implements a Client that returns canned results without executing any code.
+// This should typically be used in local development and integration tests.
+type fakeClient struct {
+	runtimes       []*api.PistonRuntime
+	compileResults *api.PistonResults
+	runResults     api.PistonResults
+}
+
+// WithFakeClientRuntimes can be used with NewFakeClient to set the installed runtimes.
+func WithFakeClientRuntimes(runtimes []*api.PistonRuntime) func(c *fakeClient) {
+	return func(c *fakeClient) {
+		c.runtimes = runtimes
+	}
+}
+
+// WithFakeClientCompileResults can be used with NewFakeClient to set the canned compilation results.
+func WithFakeClientCompileResults(results *api.PistonResults) func(c *fakeClient) {
+	return func(c *fakeClient) {
+		c.compileResults = results
+	}
+}
+
+// WithFakeClientRunResults can be used with NewFakeClient to set the canned runtime results.
+func WithFakeClientRunResults(results api.PistonResults) func(c *fakeClient) {
+	return func(c *fakeClient) {
+		c.runResults = results
+	}
+}
+
+// NewFakeClient returns a new fakeClient.
+// By default, the fakeClient reports a runtime for each language with a code template,
+// and a successful run with no output.
+func NewFakeClient(opts ...func(c *fakeClient)) *fakeClient {
+	exitCode := 0
+	c := &fakeClient{
+		runtimes: []*api.PistonRuntime{
+			{Language: api.PistonLanguageC, Version: "10.2.0", Aliases: []string{"gcc"}},
+			{Language: api.PistonLanguageCPlusPlus, Version: "10.2.0", Aliases: []string{"cpp", "g++"}},
+			{Language: api.PistonLanguageGo, Version: "1.16.2", Aliases: []string{"go", "golang"}},
+			{Language: api.PistonLanguageJava, Version: "15.0.2", Aliases: []string{}},
+			{Language: api.PistonLanguageJavaScript, Version: "18.15.0", Aliases: []string{"node-javascript", "js"}},
+			{Language: api.PistonLanguagePython, Version: "3.10.0", Aliases: []string{"py", "py3", "python3"}},
+			{Language: api.PistonLanguageRust, Version: "1.68.2", Aliases: []string{"rs"}},
+			{Language: api.PistonLanguageTypeScript, Version: "1.32.3", Aliases: []string{"ts", "deno-ts"}},
+		},
+		compileResults: nil,
+		runResults: api.PistonResults{
+			Stdout: "",
+			Stderr: "",
+			Output: "",
+			Code:   &exitCode,
+			Signal: nil,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Execute returns the canned results for the requested language and version.
+// No results are returned if the context is already done.
+func (c *fakeClient) Execute(ctx context.Context, data *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	resp := &api.PistonExecuteResponse{
+		Language: data.Language,
+		Version:  data.Version,
+		Compile:  nil,
+		Run:      c.runResults,
+	}
+
+	if c.compileResults != nil {
+		compileResults := *c.compileResults
+		resp.Compile = &compileResults
+	}
+
+	return resp, nil
+}
+
+// Runtimes returns the canned runtimes.
+func (c *fakeClient) Runtimes(_ context.Context) ([]*api.PistonRuntime, error) {
+	return c.runtimes, nil
+}
+
+// Connect starts a fake interactive session.
+// The session reports the canned compilation results and runtime output,
+// then echoes standard input back as standard output until it is sent a signal,
+// at which point the program exits with that signal.
+// If the canned compilation fails, the session finishes without running the program.
+func (c *fakeClient) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	s := &fakeSession{
+		ctx:      sessionCtx,
+		cancel:   cancel,
+		messages: make(chan *api.PistonMessage, fakeSessionBufferSize),
+		recorder: newSessionRecorder(data.Language, data.Version),
+	}
+
+	if c.compileResults != nil {
+		s.pushStage(api.PistonStageCompile, c.compileResults)
+		_ = s.push(&api.PistonMessage{
+			Type:   api.PistonMessageTypeExit,
+			Code:   c.compileResults.Code,
+			Signal: c.compileResults.Signal,
+		})
+
+		compileFailed := c.compileResults.Signal != nil || (c.compileResults.Code != nil && *c.compileResults.Code != 0)
+		if compileFailed {
+			_ = s.push(nil)
+
+			return s, nil
+		}
+	}
+
+	s.pushStage(api.PistonStageRun, &c.runResults)
+
+	return s, nil
+}
+
+// fakeSession implements a Session that replays messages queued by a fakeClient.
+// A nil message marks the end of the session.
+type fakeSession struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	messages chan *api.PistonMessage
+	recorder *sessionRecorder
+}
+
+// Receive waits for the next queued message and returns the progress event it describes,
+// returning io.EOF once the execution has finished.
+func (s *fakeSession) Receive() (*api.PistonEvent, error) {
+	for !s.recorder.isFinished() {
+		select {
+		case msg := <-s.messages:
+			if msg == nil {
+				s.recorder.finish()
+
+				return nil, io.EOF
+			}
+
+			event, finished := s.recorder.record(msg)
+			if finished {
+				return nil, io.EOF
+			}
+
+			if event != nil {
+				return event, nil
+			}
+		case <-s.ctx.Done():
+			return nil, errutils.FormatError(s.ctx.Err())
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// WriteStdin echoes data back as standard output.
+func (s *fakeSession) WriteStdin(data string) error {
+	stream := api.PistonStreamStdout
+	err := s.push(&api.PistonMessage{
+		Type:   api.PistonMessageTypeData,
+		Stream: &stream,
+		Data:   &data,
+	})
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// Signal makes the program exit with a given signal.
+func (s *fakeSession) Signal(signal string) error {
+	stage := api.PistonStageRun
+	err := s.push(&api.PistonMessage{
+		Type:   api.PistonMessageTypeExit,
+		Stage:  &stage,
+		Signal: &signal,
+	})
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// Results returns the results replayed so far.
+func (s *fakeSession) Results() *api.PistonExecuteResponse {
+	return s.recorder.snapshot()
+}
+
+// Close ends the session.
+func (s *fakeSession) Close() error {
+	s.cancel()
+
+	return nil
+}
+
+// pushStage queues the messages that start a given stage and report its canned output.
+func (s *fakeSession) pushStage(stage string, results *api.PistonResults) {
+	_ = s.push(&api.PistonMessage{
+		Type:  api.PistonMessageTypeStage,
+		Stage: &stage,
+	})
+
+	for _, output := range []struct {
+		stream string
+		data   string
+	}{
+		{stream: api.PistonStreamStdout, data: results.Stdout},
+		{stream: api.PistonStreamStderr, data: results.Stderr},
+	} {
+		if output.data == "" {
+			continue
+		}
+
+		_ = s.push(&api.PistonMessage{
+			Type:   api.PistonMessageTypeData,
+			Stream: &output.stream,
+			Data:   &output.data,
+		})
+	}
+}
+
+// push queues a given message, waiting for space in the queue unless the session is done.
+func (s *fakeSession) push(msg *api.PistonMessage) error {
+	select {
+	case s.messages <- msg:
+		return nil
+	case <-s.ctx.Done():
+		return errutils.FormatError(s.ctx.Err())
+	}
+}
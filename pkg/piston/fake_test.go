@@ -0,0 +1,243 @@
+package piston_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClientExecuteDefault(t *testing.T) {
+	t.Parallel()
+
+	client := piston.NewFakeClient()
+
+	response, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, api.PistonLanguagePython, response.Language)
+	require.Equal(t, "3.10.0", response.Version)
+	require.Nil(t, response.Compile)
+	require.Empty(t, response.Run.Stdout)
+	require.Empty(t, response.Run.Stderr)
+	require.NotNil(t, response.Run.Code)
+	require.Equal(t, 0, *response.Run.Code)
+	require.Nil(t, response.Run.Signal)
+}
+
+func TestFakeClientExecuteCannedResults(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	compileResults := &api.PistonResults{
+		Stdout: "",
+		Stderr: "",
+		Output: "",
+		Code:   &exitCodeZero,
+		Signal: nil,
+	}
+	runResults := api.PistonResults{
+		Stdout: "",
+		Stderr: "Segmentation fault",
+		Output: "Segmentation fault",
+		Code:   &exitCodeOne,
+		Signal: nil,
+	}
+
+	client := piston.NewFakeClient(
+		piston.WithFakeClientCompileResults(compileResults),
+		piston.WithFakeClientRunResults(runResults),
+	)
+
+	response, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguageC,
+		Version:  "10.2.0",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, api.PistonLanguageC, response.Language)
+	require.Equal(t, "10.2.0", response.Version)
+	require.Equal(t, compileResults, response.Compile)
+	require.Equal(t, runResults, response.Run)
+}
+
+func TestFakeClientExecuteContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	client := piston.NewFakeClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.Execute(ctx, &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFakeClientRuntimesDefault(t *testing.T) {
+	t.Parallel()
+
+	client := piston.NewFakeClient()
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+
+	for _, language := range []string{
+		api.PistonLanguageC,
+		api.PistonLanguageCPlusPlus,
+		api.PistonLanguageGo,
+		api.PistonLanguageJava,
+		api.PistonLanguageJavaScript,
+		api.PistonLanguagePython,
+		api.PistonLanguageRust,
+		api.PistonLanguageTypeScript,
+	} {
+		require.NotNil(t, piston.FindRuntime(runtimes, language))
+	}
+}
+
+func TestFakeClientRuntimesCustom(t *testing.T) {
+	t.Parallel()
+
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: "brainfuck",
+			Version:  "2.7.3",
+			Aliases:  []string{"bf"},
+		},
+	}
+	client := piston.NewFakeClient(piston.WithFakeClientRuntimes(wantRuntimes))
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRuntimes, runtimes)
+}
+
+func TestFakeClientConnect(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	compileResults := &api.PistonResults{
+		Stdout: "",
+		Stderr: "",
+		Code:   &exitCodeZero,
+	}
+	runResults := api.PistonResults{
+		Stdout: "What is your name?\n",
+		Stderr: "",
+	}
+
+	client := piston.NewFakeClient(
+		piston.WithFakeClientCompileResults(compileResults),
+		piston.WithFakeClientRunResults(runResults),
+	)
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguageC,
+		Version:  "10.2.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	wantEventTypes := []string{
+		api.PistonEventTypeCompiling,
+		api.PistonEventTypeCompileFinished,
+		api.PistonEventTypeRunning,
+		api.PistonEventTypeStdout,
+	}
+	for _, wantEventType := range wantEventTypes {
+		event, err := session.Receive()
+		require.NoError(t, err)
+		require.Equal(t, wantEventType, event.Type)
+	}
+
+	err = session.WriteStdin("Harry\n")
+	require.NoError(t, err)
+
+	event, err := session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, api.PistonEventTypeStdout, event.Type)
+	require.Equal(t, "Harry\n", *event.Data)
+
+	err = session.Signal("SIGTERM")
+	require.NoError(t, err)
+
+	_, err = session.Receive()
+	require.ErrorIs(t, err, io.EOF)
+
+	results := session.Results()
+	require.Equal(t, api.PistonLanguageC, results.Language)
+	require.NotNil(t, results.Compile)
+	require.Equal(t, &exitCodeZero, results.Compile.Code)
+	require.Equal(t, "What is your name?\nHarry\n", results.Run.Stdout)
+	require.NotNil(t, results.Run.Signal)
+	require.Equal(t, "SIGTERM", *results.Run.Signal)
+}
+
+func TestFakeClientConnectCompileFailed(t *testing.T) {
+	t.Parallel()
+
+	exitCodeOne := 1
+	client := piston.NewFakeClient(piston.WithFakeClientCompileResults(&api.PistonResults{
+		Stdout: "",
+		Stderr: "error: expected ';'",
+		Code:   &exitCodeOne,
+	}))
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguageC,
+		Version:  "10.2.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	wantEventTypes := []string{
+		api.PistonEventTypeCompiling,
+		api.PistonEventTypeStderr,
+		api.PistonEventTypeCompileFinished,
+	}
+	for _, wantEventType := range wantEventTypes {
+		event, err := session.Receive()
+		require.NoError(t, err)
+		require.Equal(t, wantEventType, event.Type)
+	}
+
+	_, err = session.Receive()
+	require.ErrorIs(t, err, io.EOF)
+
+	results := session.Results()
+	require.Equal(t, "error: expected ';'", results.Compile.Stderr)
+	require.Empty(t, results.Run.Stdout)
+}
+
+func TestFakeClientConnectClosed(t *testing.T) {
+	t.Parallel()
+
+	client := piston.NewFakeClient()
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.NoError(t, err)
+
+	event, err := session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, api.PistonEventTypeRunning, event.Type)
+
+	err = session.Close()
+	require.NoError(t, err)
+
+	_, err = session.Receive()
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -10,9 +10,11 @@
 package pistonmocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	api "github.com/alvii147/nymphadora-api/pkg/api"
+	piston "github.com/alvii147/nymphadora-api/pkg/piston"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,16 +43,264 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 }
 
 // Execute mocks base method.
-func (m *MockClient) Execute(request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+func (m *MockClient) Execute(ctx context.Context, request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Execute", request)
+	ret := m.ctrl.Call(m, "Execute", ctx, request)
 	ret0, _ := ret[0].(*api.PistonExecuteResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Execute indicates an expected call of Execute.
-func (mr *MockClientMockRecorder) Execute(request any) *gomock.Call {
+func (mr *MockClientMockRecorder) Execute(ctx, request any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockClient)(nil).Execute), request)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockClient)(nil).Execute), ctx, request)
+}
+
+// Runtimes mocks base method.
+func (m *MockClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Runtimes", ctx)
+	ret0, _ := ret[0].([]*api.PistonRuntime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Runtimes indicates an expected call of Runtimes.
+func (mr *MockClientMockRecorder) Runtimes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Runtimes", reflect.TypeOf((*MockClient)(nil).Runtimes), ctx)
+}
+
+// MockStreamingClient is a mock of StreamingClient interface.
+type MockStreamingClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamingClientMockRecorder
+	isgomock struct{}
+}
+
+// MockStreamingClientMockRecorder is the mock recorder for MockStreamingClient.
+type MockStreamingClientMockRecorder struct {
+	mock *MockStreamingClient
+}
+
+// NewMockStreamingClient creates a new mock instance.
+func NewMockStreamingClient(ctrl *gomock.Controller) *MockStreamingClient {
+	mock := &MockStreamingClient{ctrl: ctrl}
+	mock.recorder = &MockStreamingClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStreamingClient) EXPECT() *MockStreamingClientMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockStreamingClient) Execute(ctx context.Context, request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, request)
+	ret0, _ := ret[0].(*api.PistonExecuteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockStreamingClientMockRecorder) Execute(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockStreamingClient)(nil).Execute), ctx, request)
+}
+
+// ExecuteStream mocks base method.
+func (m *MockStreamingClient) ExecuteStream(ctx context.Context, request *api.PistonExecuteRequest, onEvent func(*api.PistonEvent)) (*api.PistonExecuteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteStream", ctx, request, onEvent)
+	ret0, _ := ret[0].(*api.PistonExecuteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteStream indicates an expected call of ExecuteStream.
+func (mr *MockStreamingClientMockRecorder) ExecuteStream(ctx, request, onEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStream", reflect.TypeOf((*MockStreamingClient)(nil).ExecuteStream), ctx, request, onEvent)
+}
+
+// Runtimes mocks base method.
+func (m *MockStreamingClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Runtimes", ctx)
+	ret0, _ := ret[0].([]*api.PistonRuntime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Runtimes indicates an expected call of Runtimes.
+func (mr *MockStreamingClientMockRecorder) Runtimes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Runtimes", reflect.TypeOf((*MockStreamingClient)(nil).Runtimes), ctx)
+}
+
+// MockSession is a mock of Session interface.
+type MockSession struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionMockRecorder
+	isgomock struct{}
+}
+
+// MockSessionMockRecorder is the mock recorder for MockSession.
+type MockSessionMockRecorder struct {
+	mock *MockSession
+}
+
+// NewMockSession creates a new mock instance.
+func NewMockSession(ctrl *gomock.Controller) *MockSession {
+	mock := &MockSession{ctrl: ctrl}
+	mock.recorder = &MockSessionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSession) EXPECT() *MockSessionMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockSession) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSessionMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSession)(nil).Close))
+}
+
+// Receive mocks base method.
+func (m *MockSession) Receive() (*api.PistonEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Receive")
+	ret0, _ := ret[0].(*api.PistonEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Receive indicates an expected call of Receive.
+func (mr *MockSessionMockRecorder) Receive() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockSession)(nil).Receive))
+}
+
+// Results mocks base method.
+func (m *MockSession) Results() *api.PistonExecuteResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Results")
+	ret0, _ := ret[0].(*api.PistonExecuteResponse)
+	return ret0
+}
+
+// Results indicates an expected call of Results.
+func (mr *MockSessionMockRecorder) Results() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Results", reflect.TypeOf((*MockSession)(nil).Results))
+}
+
+// Signal mocks base method.
+func (m *MockSession) Signal(signal string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Signal", signal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Signal indicates an expected call of Signal.
+func (mr *MockSessionMockRecorder) Signal(signal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signal", reflect.TypeOf((*MockSession)(nil).Signal), signal)
+}
+
+// WriteStdin mocks base method.
+func (m *MockSession) WriteStdin(data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteStdin", data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteStdin indicates an expected call of WriteStdin.
+func (mr *MockSessionMockRecorder) WriteStdin(data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStdin", reflect.TypeOf((*MockSession)(nil).WriteStdin), data)
+}
+
+// MockInteractiveClient is a mock of InteractiveClient interface.
+type MockInteractiveClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveClientMockRecorder
+	isgomock struct{}
+}
+
+// MockInteractiveClientMockRecorder is the mock recorder for MockInteractiveClient.
+type MockInteractiveClientMockRecorder struct {
+	mock *MockInteractiveClient
+}
+
+// NewMockInteractiveClient creates a new mock instance.
+func NewMockInteractiveClient(ctrl *gomock.Controller) *MockInteractiveClient {
+	mock := &MockInteractiveClient{ctrl: ctrl}
+	mock.recorder = &MockInteractiveClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveClient) EXPECT() *MockInteractiveClientMockRecorder {
+	return m.recorder
+}
+
+// Connect mocks base method.
+func (m *MockInteractiveClient) Connect(ctx context.Context, request *api.PistonExecuteRequest) (piston.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connect", ctx, request)
+	ret0, _ := ret[0].(piston.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Connect indicates an expected call of Connect.
+func (mr *MockInteractiveClientMockRecorder) Connect(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockInteractiveClient)(nil).Connect), ctx, request)
+}
+
+// Execute mocks base method.
+func (m *MockInteractiveClient) Execute(ctx context.Context, request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, request)
+	ret0, _ := ret[0].(*api.PistonExecuteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockInteractiveClientMockRecorder) Execute(ctx, request any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockInteractiveClient)(nil).Execute), ctx, request)
+}
+
+// Runtimes mocks base method.
+func (m *MockInteractiveClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Runtimes", ctx)
+	ret0, _ := ret[0].([]*api.PistonRuntime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Runtimes indicates an expected call of Runtimes.
+func (mr *MockInteractiveClientMockRecorder) Runtimes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Runtimes", reflect.TypeOf((*MockInteractiveClient)(nil).Runtimes), ctx)
 }
@@ -2,10 +2,10 @@ package piston
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
-	"sync"
 	"time"
 
 	"github.com/alvii147/nymphadora-api/pkg/api"
@@ -13,72 +13,234 @@ import (
 	"github.com/alvii147/nymphadora-api/pkg/httputils"
 )
 
-// mu is a mutex intended to avoid concurrent requests to Piston.
-// Requests need to be made at least 200 ms apart to avoid hiting the rate limit.
-var mu sync.Mutex
+// piston client types.
+const (
+	// ClientTypePiston represents clients that execute code on a remote Piston instance.
+	ClientTypePiston = "piston"
+	// ClientTypeFake represents clients that return canned results without executing code.
+	ClientTypeFake = "fake"
+)
 
 const (
-	// PistonRateLimit is the rate limit duration for Piston.
-	PistonRateLimit = 200 * time.Millisecond
-	// PistonExecuteURL is the endpoint for code execution on Piston.
-	PistonExecuteURL = "https://emkc.org/api/v2/piston/execute"
+	// PistonDefaultBaseURL is the base URL for the public Piston instance.
+	PistonDefaultBaseURL = "https://emkc.org/api/v2/piston"
+	// PistonExecutePath is the endpoint path for code execution on Piston.
+	PistonExecutePath = "/execute"
+	// PistonRuntimesPath is the endpoint path for listing installed runtimes on Piston.
+	PistonRuntimesPath = "/runtimes"
+	// PistonConnectPath is the endpoint path for interactive sessions on Piston.
+	PistonConnectPath = "/connect"
+	// ExecuteDeadlineGracePeriod is the time allowed for an execution request on top of its compile and run timeouts,
+	// covering network round trips and Piston's own overhead.
+	ExecuteDeadlineGracePeriod = 10 * time.Second
 )
 
-// Client represents a client that sends requests to Piston.
+// Client represents a backend that executes code.
 //
 //go:generate mockgen -package=pistonmocks -source=$GOFILE -destination=./mocks/piston.go
 type Client interface {
-	Execute(request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error)
+	Execute(ctx context.Context, request *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error)
+	Runtimes(ctx context.Context) ([]*api.PistonRuntime, error)
+}
+
+// StreamingClient represents a backend that executes code and reports progress while doing so.
+type StreamingClient interface {
+	Client
+	ExecuteStream(
+		ctx context.Context,
+		request *api.PistonExecuteRequest,
+		onEvent func(event *api.PistonEvent),
+	) (*api.PistonExecuteResponse, error)
+}
+
+// Session represents an interactive execution in progress.
+// Receive must not be called concurrently with itself,
+// but WriteStdin and Signal may be called while another goroutine waits in Receive.
+// Sessions must always be closed, even after Receive returns io.EOF.
+type Session interface {
+	// Receive waits for the next progress event, returning io.EOF once the execution has finished.
+	Receive() (*api.PistonEvent, error)
+	// WriteStdin sends data to the standard input of the running program.
+	WriteStdin(data string) error
+	// Signal sends a signal, such as SIGINT or SIGKILL, to the running program.
+	Signal(signal string) error
+	// Results returns the results reported by the execution so far.
+	Results() *api.PistonExecuteResponse
+	// Close ends the session, killing the program if it is still running.
+	Close() error
+}
+
+// InteractiveClient represents a backend that executes code interactively.
+// The context passed to Connect bounds the whole session, not just the connection.
+type InteractiveClient interface {
+	Client
+	Connect(ctx context.Context, request *api.PistonExecuteRequest) (Session, error)
+}
+
+// Connect starts an interactive execution of a request using a given Client.
+// If the Client is not an InteractiveClient, it fails with errutils.ErrCodeExecutionNotInteractive.
+func Connect(ctx context.Context, c Client, data *api.PistonExecuteRequest) (Session, error) {
+	interactiveClient, ok := c.(InteractiveClient)
+	if !ok {
+		return nil, errutils.FormatError(errutils.ErrCodeExecutionNotInteractive)
+	}
+
+	session, err := interactiveClient.Connect(ctx, data)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return session, nil
+}
+
+// ExecuteStream executes a request using a given Client and reports progress events to onEvent.
+// If the Client is not a StreamingClient, the phase and output events are emitted
+// once the final results are available.
+func ExecuteStream(
+	ctx context.Context,
+	c Client,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	streamingClient, ok := c.(StreamingClient)
+	if ok {
+		resp, err := streamingClient.ExecuteStream(ctx, data, onEvent)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		return resp, nil
+	}
+
+	resp, err := c.Execute(ctx, data)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	EmitResultsEvents(resp, onEvent)
+
+	return resp, nil
 }
 
-// client implements Client.
+// ExecuteDeadline computes how long an execution request may take in total,
+// based on its compile and run timeouts plus ExecuteDeadlineGracePeriod.
+// The returned boolean is false if the request does not set a run timeout,
+// in which case Piston's own defaults apply and no deadline is derived.
+func ExecuteDeadline(data *api.PistonExecuteRequest) (time.Duration, bool) {
+	if data.RunTimeout == nil {
+		return 0, false
+	}
+
+	timeout := time.Duration(*data.RunTimeout) * time.Millisecond
+	if data.CompileTimeout != nil {
+		timeout += time.Duration(*data.CompileTimeout) * time.Millisecond
+	}
+
+	return timeout + ExecuteDeadlineGracePeriod, true
+}
+
+// EmitResultsEvents emits the phase and output events that describe a given set of execution results.
+// Runtime events are not emitted if compilation failed.
+func EmitResultsEvents(resp *api.PistonExecuteResponse, onEvent func(event *api.PistonEvent)) {
+	emitOutputEvents := func(stage string, results *api.PistonResults) {
+		if results.Stdout != "" {
+			onEvent(&api.PistonEvent{
+				Type:  api.PistonEventTypeStdout,
+				Stage: &stage,
+				Data:  &results.Stdout,
+			})
+		}
+
+		if results.Stderr != "" {
+			onEvent(&api.PistonEvent{
+				Type:  api.PistonEventTypeStderr,
+				Stage: &stage,
+				Data:  &results.Stderr,
+			})
+		}
+	}
+
+	if resp.Compile != nil {
+		onEvent(&api.PistonEvent{
+			Type: api.PistonEventTypeCompiling,
+		})
+		emitOutputEvents(api.PistonStageCompile, resp.Compile)
+		onEvent(&api.PistonEvent{
+			Type:   api.PistonEventTypeCompileFinished,
+			Code:   resp.Compile.Code,
+			Signal: resp.Compile.Signal,
+		})
+
+		if resp.Compile.Code == nil || *resp.Compile.Code != 0 {
+			return
+		}
+	}
+
+	onEvent(&api.PistonEvent{
+		Type: api.PistonEventTypeRunning,
+	})
+	emitOutputEvents(api.PistonStageRun, &resp.Run)
+}
+
+// client implements Client and sends requests to Piston.
+// Requests are sent as soon as Execute is called,
+// so client should be wrapped in a queuedClient to avoid hitting Piston's rate limit.
 type client struct {
+	baseURL    string
 	key        *string
 	httpClient httputils.HTTPClient
 }
 
 // NewClient returns a new client.
-func NewClient(key *string, httpClient httputils.HTTPClient) *client {
+func NewClient(baseURL string, key *string, httpClient httputils.HTTPClient) *client {
 	return &client{
+		baseURL:    baseURL,
 		key:        key,
 		httpClient: httpClient,
 	}
 }
 
 // Execute sends a remote code execution request to Piston.
-func (c *client) Execute(data *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
-	mu.Lock()
-	defer mu.Unlock()
+// Failures wrap errutils.ErrCodeExecutionRateLimited, errutils.ErrCodeExecutionUnavailable,
+// errutils.ErrCodeExecutionRejected or errutils.ErrCodeExecutionRuntimeNotFound depending on their cause,
+// and additionally wrap errutils.ErrCodeExecutionNotStarted when Piston never started executing the code.
+// The request is abandoned when the context is done,
+// or when the deadline derived from the request's timeouts passes.
+func (c *client) Execute(ctx context.Context, data *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	timeout, ok := ExecuteDeadline(data)
+	if ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	body, err := json.Marshal(data)
 	if err != nil {
 		return nil, errutils.FormatError(err, "json.Marshal failed")
 	}
 
-	req, err := http.NewRequest(http.MethodPost, PistonExecuteURL, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+PistonExecutePath, bytes.NewReader(body))
 	if err != nil {
-		return nil, errutils.FormatError(err, "http.NewRequest failed")
+		return nil, errutils.FormatError(err, "http.NewRequestWithContext failed")
 	}
 
+	req.Header.Set(httputils.HTTPHeaderContentType, "application/json")
 	if c.key != nil {
-		req.Header.Set("Authorization", *c.key)
+		req.Header.Set(httputils.HTTPHeaderAuthorization, *c.key)
 	}
 
-	// sleep to avoid hitting rate limit
-	time.Sleep(PistonRateLimit)
-
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, errutils.FormatError(err, "c.httpClient.Do failed")
+		return nil, errutils.FormatError(classifyTransportError(ctx, err), "c.httpClient.Do failed")
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(resp.Body)
 
 		return nil, errutils.FormatErrorf(
-			err,
+			classifyStatusCode(resp.StatusCode, bodyBytes),
 			"c.httpClient.Do returned status code %d %v",
 			resp.StatusCode,
 			string(bodyBytes),
@@ -93,3 +255,41 @@ func (c *client) Execute(data *api.PistonExecuteRequest) (*api.PistonExecuteResp
 
 	return results, nil
 }
+
+// Runtimes sends a request to Piston to list its installed runtimes.
+func (c *client) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+PistonRuntimesPath, http.NoBody)
+	if err != nil {
+		return nil, errutils.FormatError(err, "http.NewRequestWithContext failed")
+	}
+
+	if c.key != nil {
+		req.Header.Set(httputils.HTTPHeaderAuthorization, *c.key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errutils.FormatError(classifyTransportError(ctx, err), "c.httpClient.Do failed")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+
+		return nil, errutils.FormatErrorf(
+			classifyStatusCode(resp.StatusCode, bodyBytes),
+			"c.httpClient.Do returned status code %d %v",
+			resp.StatusCode,
+			string(bodyBytes),
+		)
+	}
+
+	runtimes := []*api.PistonRuntime{}
+	err = json.NewDecoder(resp.Body).Decode(&runtimes)
+	if err != nil {
+		return nil, errutils.FormatError(err, "json.Decoder.Decode failed")
+	}
+
+	return runtimes, nil
+}
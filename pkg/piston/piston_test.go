@@ -1,12 +1,22 @@
 package piston_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
 	"github.com/alvii147/nymphadora-api/pkg/httputils"
 	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 const CCode = `
@@ -26,7 +36,7 @@ print('Hello, world!', end='')
 func TestPistonClientExecute(t *testing.T) {
 	t.Parallel()
 
-	client := piston.NewClient(nil, httputils.NewHTTPClient(nil))
+	client := piston.NewClient(piston.PistonDefaultBaseURL, nil, httputils.NewHTTPClient(nil))
 
 	testcases := map[string]struct {
 		fileName           string
@@ -38,14 +48,14 @@ func TestPistonClientExecute(t *testing.T) {
 		"Execute C code, expect compilation results": {
 			fileName:           "main.c",
 			language:           api.PistonLanguageC,
-			version:            api.PistonVersionC,
+			version:            "10.2.0",
 			code:               CCode,
 			wantCompileResults: true,
 		},
 		"Execute Python code, expect only runtime results": {
 			fileName:           "main.py",
 			language:           api.PistonLanguagePython,
-			version:            api.PistonVersionPython,
+			version:            "3.10.0",
 			code:               PythonCode,
 			wantCompileResults: false,
 		},
@@ -68,7 +78,7 @@ func TestPistonClientExecute(t *testing.T) {
 				},
 			}
 
-			response, err := client.Execute(req)
+			response, err := client.Execute(context.Background(), req)
 
 			require.NoError(t, err)
 			require.Equal(t, testcase.language, response.Language)
@@ -97,3 +107,450 @@ func TestPistonClientExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestPistonClientExecuteBaseURLAndKey(t *testing.T) {
+	t.Parallel()
+
+	key := "s3cr3tk3y"
+	exitCodeZero := 0
+	wantResponse := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+		Compile:  nil,
+		Run: api.PistonResults{
+			Stdout: "Hello, world!",
+			Stderr: "",
+			Output: "Hello, world!",
+			Code:   &exitCodeZero,
+			Signal: nil,
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v2"+piston.PistonExecutePath, r.URL.Path)
+		assert.Equal(t, key, r.Header.Get("Authorization"))
+
+		var req api.PistonExecuteRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(t, err)
+		assert.Equal(t, api.PistonLanguagePython, req.Language)
+
+		err = json.NewEncoder(w).Encode(wantResponse)
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := piston.NewClient(srv.URL+"/api/v2", &key, httputils.NewHTTPClient(nil))
+
+	fileName := "main.py"
+	fileEncoding := "utf8"
+	response, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+		Files: []api.PistonFile{
+			{
+				Name:     &fileName,
+				Content:  PythonCode,
+				Encoding: &fileEncoding,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, wantResponse, response)
+}
+
+func TestPistonClientExecuteNonOKStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.Error(t, err)
+}
+
+func TestPistonClientExecuteErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		statusCode    int
+		body          string
+		wantErr       error
+		wantRetryable bool
+	}{
+		"Rate limited": {
+			statusCode:    http.StatusTooManyRequests,
+			body:          `{"message":"Requests limited to 5 per second"}`,
+			wantErr:       errutils.ErrCodeExecutionRateLimited,
+			wantRetryable: true,
+		},
+		"Bad gateway": {
+			statusCode:    http.StatusBadGateway,
+			body:          "",
+			wantErr:       errutils.ErrCodeExecutionUnavailable,
+			wantRetryable: true,
+		},
+		"Service unavailable": {
+			statusCode:    http.StatusServiceUnavailable,
+			body:          "",
+			wantErr:       errutils.ErrCodeExecutionUnavailable,
+			wantRetryable: true,
+		},
+		"Internal server error": {
+			statusCode:    http.StatusInternalServerError,
+			body:          "",
+			wantErr:       errutils.ErrCodeExecutionUnavailable,
+			wantRetryable: false,
+		},
+		"Gateway timeout": {
+			statusCode:    http.StatusGatewayTimeout,
+			body:          "",
+			wantErr:       errutils.ErrCodeExecutionUnavailable,
+			wantRetryable: false,
+		},
+		"Unknown runtime": {
+			statusCode:    http.StatusBadRequest,
+			body:          `{"message":"python-2.7.18 runtime is unknown"}`,
+			wantErr:       errutils.ErrCodeExecutionRuntimeNotFound,
+			wantRetryable: false,
+		},
+		"Bad request": {
+			statusCode:    http.StatusBadRequest,
+			body:          `{"message":"files must be an array"}`,
+			wantErr:       errutils.ErrCodeExecutionRejected,
+			wantRetryable: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(testcase.statusCode)
+				_, err := w.Write([]byte(testcase.body))
+				assert.NoError(t, err)
+			}))
+			t.Cleanup(srv.Close)
+
+			client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+			_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+				Language: api.PistonLanguagePython,
+				Version:  "3.10.0",
+			})
+			require.ErrorIs(t, err, testcase.wantErr)
+			require.Equal(t, testcase.wantRetryable, piston.IsRetryable(err))
+		})
+	}
+}
+
+func TestPistonClientExecuteUnreachable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionNotStarted)
+	require.True(t, piston.IsRetryable(err))
+}
+
+func TestPistonClientExecuteHTTPClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(func(c *http.Client) {
+		c.Timeout = 50 * time.Millisecond
+	}))
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+	require.NotErrorIs(t, err, errutils.ErrCodeExecutionNotStarted)
+	require.False(t, piston.IsRetryable(err))
+}
+
+func TestPistonClientExecuteContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Execute(ctx, &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExecuteDeadline(t *testing.T) {
+	t.Parallel()
+
+	compileTimeout := int64(10000)
+	runTimeout := int64(3000)
+
+	testcases := map[string]struct {
+		compileTimeout *int64
+		runTimeout     *int64
+		wantTimeout    time.Duration
+		wantOK         bool
+	}{
+		"Compile and run timeouts": {
+			compileTimeout: &compileTimeout,
+			runTimeout:     &runTimeout,
+			wantTimeout:    13*time.Second + piston.ExecuteDeadlineGracePeriod,
+			wantOK:         true,
+		},
+		"Run timeout only": {
+			compileTimeout: nil,
+			runTimeout:     &runTimeout,
+			wantTimeout:    3*time.Second + piston.ExecuteDeadlineGracePeriod,
+			wantOK:         true,
+		},
+		"No run timeout": {
+			compileTimeout: &compileTimeout,
+			runTimeout:     nil,
+			wantTimeout:    0,
+			wantOK:         false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			timeout, ok := piston.ExecuteDeadline(&api.PistonExecuteRequest{
+				CompileTimeout: testcase.compileTimeout,
+				RunTimeout:     testcase.runTimeout,
+			})
+			require.Equal(t, testcase.wantOK, ok)
+			require.Equal(t, testcase.wantTimeout, timeout)
+		})
+	}
+}
+
+func TestPistonClientRuntimes(t *testing.T) {
+	t.Parallel()
+
+	key := "s3cr3tk3y"
+	nodeRuntime := "node"
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py", "py3", "python3"},
+			Runtime:  nil,
+		},
+		{
+			Language: api.PistonLanguageJavaScript,
+			Version:  "18.15.0",
+			Aliases:  []string{"node-javascript", "js"},
+			Runtime:  &nodeRuntime,
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v2"+piston.PistonRuntimesPath, r.URL.Path)
+		assert.Equal(t, key, r.Header.Get("Authorization"))
+
+		err := json.NewEncoder(w).Encode(wantRuntimes)
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := piston.NewClient(srv.URL+"/api/v2", &key, httputils.NewHTTPClient(nil))
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRuntimes, runtimes)
+}
+
+func TestPistonClientRuntimesNonOKStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+	_, err := client.Runtimes(context.Background())
+	require.Error(t, err)
+}
+
+func TestExecuteStreamFallback(t *testing.T) {
+	t.Parallel()
+
+	exitCodeZero := 0
+	exitCodeOne := 1
+	compileStage := api.PistonStageCompile
+	runStage := api.PistonStageRun
+	compileError := "error: expected ';' before '}' token"
+	runStdout := "Hello, world!"
+	runStderr := "warning: deprecated"
+
+	testcases := map[string]struct {
+		resp       *api.PistonExecuteResponse
+		wantEvents []*api.PistonEvent
+	}{
+		"No compilation": {
+			resp: &api.PistonExecuteResponse{
+				Compile: nil,
+				Run: api.PistonResults{
+					Stdout: runStdout,
+					Stderr: runStderr,
+					Code:   &exitCodeZero,
+				},
+			},
+			wantEvents: []*api.PistonEvent{
+				{Type: api.PistonEventTypeRunning},
+				{Type: api.PistonEventTypeStdout, Stage: &runStage, Data: &runStdout},
+				{Type: api.PistonEventTypeStderr, Stage: &runStage, Data: &runStderr},
+			},
+		},
+		"Successful compilation": {
+			resp: &api.PistonExecuteResponse{
+				Compile: &api.PistonResults{
+					Code: &exitCodeZero,
+				},
+				Run: api.PistonResults{
+					Stdout: runStdout,
+					Code:   &exitCodeZero,
+				},
+			},
+			wantEvents: []*api.PistonEvent{
+				{Type: api.PistonEventTypeCompiling},
+				{Type: api.PistonEventTypeCompileFinished, Code: &exitCodeZero},
+				{Type: api.PistonEventTypeRunning},
+				{Type: api.PistonEventTypeStdout, Stage: &runStage, Data: &runStdout},
+			},
+		},
+		"Failed compilation": {
+			resp: &api.PistonExecuteResponse{
+				Compile: &api.PistonResults{
+					Stderr: compileError,
+					Code:   &exitCodeOne,
+				},
+			},
+			wantEvents: []*api.PistonEvent{
+				{Type: api.PistonEventTypeCompiling},
+				{Type: api.PistonEventTypeStderr, Stage: &compileStage, Data: &compileError},
+				{Type: api.PistonEventTypeCompileFinished, Code: &exitCodeOne},
+			},
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			client := pistonmocks.NewMockClient(ctrl)
+
+			req := &api.PistonExecuteRequest{}
+			client.
+				EXPECT().
+				Execute(gomock.Any(), req).
+				Return(testcase.resp, nil).
+				Times(1)
+
+			events := make([]*api.PistonEvent, 0)
+			resp, err := piston.ExecuteStream(context.Background(), client, req, func(event *api.PistonEvent) {
+				events = append(events, event)
+			})
+			require.NoError(t, err)
+			require.Equal(t, testcase.resp, resp)
+			require.Equal(t, testcase.wantEvents, events)
+		})
+	}
+}
+
+func TestExecuteStreamStreamingClient(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := pistonmocks.NewMockStreamingClient(ctrl)
+
+	req := &api.PistonExecuteRequest{}
+	wantResp := &api.PistonExecuteResponse{}
+	wantEvent := &api.PistonEvent{
+		Type: api.PistonEventTypeRunning,
+	}
+
+	client.
+		EXPECT().
+		ExecuteStream(gomock.Any(), req, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *api.PistonExecuteRequest,
+			onEvent func(event *api.PistonEvent),
+		) (*api.PistonExecuteResponse, error) {
+			onEvent(wantEvent)
+
+			return wantResp, nil
+		}).
+		Times(1)
+
+	events := make([]*api.PistonEvent, 0)
+	resp, err := piston.ExecuteStream(context.Background(), client, req, func(event *api.PistonEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	require.Equal(t, wantResp, resp)
+	require.Equal(t, []*api.PistonEvent{wantEvent}, events)
+}
+
+func TestExecuteStreamError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := pistonmocks.NewMockClient(ctrl)
+	executeErr := errors.New("Execute failed")
+
+	client.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(nil, executeErr).
+		Times(1)
+
+	eventCount := 0
+	_, err := piston.ExecuteStream(context.Background(), client, &api.PistonExecuteRequest{}, func(event *api.PistonEvent) {
+		eventCount++
+	})
+	require.ErrorIs(t, err, executeErr)
+	require.Equal(t, 0, eventCount)
+}
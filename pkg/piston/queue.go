@@ -0,0 +1,191 @@
+package piston
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/ratelimit"
+)
+
+// QueueFullRetryAfter is the duration callers are advised to wait before retrying when the queue is full.
+const QueueFullRetryAfter = 5 * time.Second
+
+// queuedClient implements a Client that wraps another Client with a rate limiter
+// and a bounded pool of concurrent executions.
+// Requests that arrive when all workers are busy wait in a queue of limited depth,
+// and requests that arrive when the queue is full are rejected immediately.
+type queuedClient struct {
+	client  Client
+	limiter *ratelimit.TokenBucket
+	workers chan struct{}
+	queue   chan struct{}
+}
+
+// NewQueuedClient returns a new queuedClient.
+func NewQueuedClient(
+	client Client,
+	limiter *ratelimit.TokenBucket,
+	maxConcurrency int,
+	maxQueueDepth int,
+) *queuedClient {
+	return &queuedClient{
+		client:  client,
+		limiter: limiter,
+		workers: make(chan struct{}, maxConcurrency),
+		queue:   make(chan struct{}, maxConcurrency+maxQueueDepth),
+	}
+}
+
+// Execute waits for a free worker and a rate limiter token, then executes the request
+// using the wrapped Client.
+func (c *queuedClient) Execute(ctx context.Context, data *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	resp, err := c.execute(ctx, data, nil)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteStream waits for a free worker and a rate limiter token, then executes the request
+// using the wrapped Client, reporting progress events to onEvent.
+// A queued event is emitted as soon as the request is accepted into the queue.
+func (c *queuedClient) ExecuteStream(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	resp, err := c.execute(ctx, data, onEvent)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// Connect waits for a free worker and a rate limiter token, then starts an interactive execution
+// using the wrapped Client.
+// The session occupies its worker and its place in the queue until it is closed.
+func (c *queuedClient) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	select {
+	case c.queue <- struct{}{}:
+	default:
+		return nil, errutils.FormatError(errutils.ErrCodeExecutionQueueFull)
+	}
+
+	select {
+	case c.workers <- struct{}{}:
+	case <-ctx.Done():
+		<-c.queue
+
+		return nil, errutils.FormatError(ctx.Err())
+	}
+
+	release := func() {
+		<-c.workers
+		<-c.queue
+	}
+
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		release()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	session, err := Connect(ctx, c.client, data)
+	if err != nil {
+		release()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	return &queuedSession{
+		Session: session,
+		release: sync.OnceFunc(release),
+	}, nil
+}
+
+// Runtimes waits for a rate limiter token, then lists the runtimes using the wrapped Client.
+// Listing runtimes does not occupy a worker or a place in the queue.
+func (c *queuedClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	runtimes, err := c.client.Runtimes(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return runtimes, nil
+}
+
+// execute queues and executes the request, streaming progress events when onEvent is not nil.
+// The request leaves the queue without being executed if the context is done while it waits.
+func (c *queuedClient) execute(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	select {
+	case c.queue <- struct{}{}:
+	default:
+		return nil, errutils.FormatError(errutils.ErrCodeExecutionQueueFull)
+	}
+	defer func() { <-c.queue }()
+
+	if onEvent != nil {
+		onEvent(&api.PistonEvent{
+			Type: api.PistonEventTypeQueued,
+		})
+	}
+
+	select {
+	case c.workers <- struct{}{}:
+	case <-ctx.Done():
+		return nil, errutils.FormatError(ctx.Err())
+	}
+	defer func() { <-c.workers }()
+
+	err := c.limiter.Wait(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	var resp *api.PistonExecuteResponse
+	if onEvent != nil {
+		resp, err = ExecuteStream(ctx, c.client, data, onEvent)
+	} else {
+		resp, err = c.client.Execute(ctx, data)
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// queuedSession implements a Session that wraps another Session
+// and gives up its worker and its place in the queue once closed.
+type queuedSession struct {
+	Session
+	release func()
+}
+
+// Close closes the wrapped Session, then gives up its worker and its place in the queue.
+func (s *queuedSession) Close() error {
+	defer s.release()
+
+	err := s.Session.Close()
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
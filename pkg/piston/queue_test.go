@@ -0,0 +1,310 @@
+package piston_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/alvii147/nymphadora-api/pkg/ratelimit"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQueuedClientExecute(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	req := &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	wantResp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), req).
+		Return(wantResp, nil).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 1)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	resp, err := client.Execute(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, wantResp, resp)
+}
+
+func TestQueuedClientExecuteError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	innerErr := errors.New("Execute failed")
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		Return(nil, innerErr).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 1)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, innerErr)
+}
+
+func TestQueuedClientExecuteQueueFull(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+			started <- struct{}{}
+			<-release
+
+			return &api.PistonExecuteResponse{}, nil
+		}).
+		Times(2)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 2)
+	client := piston.NewQueuedClient(innerClient, limiter, 2, 0)
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	<-started
+	<-started
+
+	_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionQueueFull)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestQueuedClientExecuteContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+			started <- struct{}{}
+			<-release
+
+			return &api.PistonExecuteResponse{}, nil
+		}).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 2)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+		assert.NoError(t, err)
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan struct{})
+	errs := make(chan error)
+	go func() {
+		_, err := client.ExecuteStream(ctx, &api.PistonExecuteRequest{}, func(event *api.PistonEvent) {
+			close(queued)
+		})
+		errs <- err
+	}()
+
+	<-queued
+	cancel()
+
+	err := <-errs
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	wg.Wait()
+}
+
+func TestQueuedClientRuntimes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+	}
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(wantRuntimes, nil).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 1)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRuntimes, runtimes)
+}
+
+func TestQueuedClientRuntimesError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	innerErr := errors.New("Runtimes failed")
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(nil, innerErr).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 1)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	_, err := client.Runtimes(context.Background())
+	require.ErrorIs(t, err, innerErr)
+}
+
+func TestQueuedClientExecuteStream(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	exitCodeZero := 0
+	req := &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	wantResp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+		Run: api.PistonResults{
+			Code: &exitCodeZero,
+		},
+	}
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), req).
+		Return(wantResp, nil).
+		Times(1)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 1)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	eventTypes := make([]string, 0)
+	resp, err := client.ExecuteStream(context.Background(), req, func(event *api.PistonEvent) {
+		eventTypes = append(eventTypes, event.Type)
+	})
+	require.NoError(t, err)
+	require.Equal(t, wantResp, resp)
+	require.Equal(t, []string{api.PistonEventTypeQueued, api.PistonEventTypeRunning}, eventTypes)
+}
+
+func TestQueuedClientConnect(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockInteractiveClient(ctrl)
+	innerSession := pistonmocks.NewMockSession(ctrl)
+
+	innerClient.
+		EXPECT().
+		Connect(gomock.Any(), gomock.Any()).
+		Return(innerSession, nil).
+		Times(2)
+
+	innerSession.
+		EXPECT().
+		Close().
+		Return(nil).
+		Times(2)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 2)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.NoError(t, err)
+
+	// the open session holds the only worker
+	_, err = client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionQueueFull)
+
+	err = session.Close()
+	require.NoError(t, err)
+
+	session, err = client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.NoError(t, err)
+
+	err = session.Close()
+	require.NoError(t, err)
+}
+
+func TestQueuedClientConnectError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockInteractiveClient(ctrl)
+	innerErr := errors.New("Connect failed")
+
+	innerClient.
+		EXPECT().
+		Connect(gomock.Any(), gomock.Any()).
+		Return(nil, innerErr).
+		Times(2)
+
+	limiter := ratelimit.NewTokenBucket(timekeeper.NewSystemProvider(), 1000, 2)
+	client := piston.NewQueuedClient(innerClient, limiter, 1, 0)
+
+	// failed sessions give up their worker straight away
+	for range 2 {
+		_, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+		require.ErrorIs(t, err, innerErr)
+	}
+}
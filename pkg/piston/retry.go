@@ -0,0 +1,185 @@
+package piston
+
+import (
+	"context"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/random"
+)
+
+// retryingClient implements a Client that wraps another Client
+// and retries requests that fail with retryable errors, as reported by IsRetryable.
+// Retries are delayed using exponential backoff with full jitter,
+// so that many callers retrying at once don't hit Piston at the same time.
+type retryingClient struct {
+	client     Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// NewRetryingClient returns a new retryingClient.
+// Requests are retried at most maxRetries times, and the delay before each retry is picked at random
+// between zero and baseDelay doubled for every earlier retry, capped at maxDelay.
+func NewRetryingClient(client Client, maxRetries int, baseDelay time.Duration, maxDelay time.Duration) *retryingClient {
+	return &retryingClient{
+		client:     client,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+	}
+}
+
+// Execute executes the request using the wrapped Client, retrying it if it fails with a retryable error.
+func (c *retryingClient) Execute(ctx context.Context, data *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+	resp, err := c.execute(ctx, data, nil)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteStream executes the request using the wrapped Client, reporting progress events to onEvent,
+// and retrying it if it fails with a retryable error.
+// Requests are not retried once phase or output events have been reported,
+// since retrying would report them again.
+func (c *retryingClient) ExecuteStream(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	resp, err := c.execute(ctx, data, onEvent)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// Connect starts an interactive execution using the wrapped Client,
+// retrying if starting it fails with a retryable error.
+// Sessions are never retried once started, since the program may already have consumed input.
+func (c *retryingClient) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	for retry := 0; ; retry++ {
+		session, err := Connect(ctx, c.client, data)
+		if err == nil {
+			return session, nil
+		}
+
+		if retry >= c.maxRetries || !IsRetryable(err) {
+			return nil, errutils.FormatError(err)
+		}
+
+		err = c.wait(ctx, retry)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+}
+
+// Runtimes lists the runtimes using the wrapped Client, retrying if it fails with a retryable error.
+func (c *retryingClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	for retry := 0; ; retry++ {
+		runtimes, err := c.client.Runtimes(ctx)
+		if err == nil {
+			return runtimes, nil
+		}
+
+		if retry >= c.maxRetries || !IsRetryable(err) {
+			return nil, errutils.FormatError(err)
+		}
+
+		err = c.wait(ctx, retry)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+}
+
+// execute executes and retries the request, streaming progress events when onEvent is not nil.
+func (c *retryingClient) execute(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	progressed := false
+	var trackedOnEvent func(event *api.PistonEvent)
+	if onEvent != nil {
+		trackedOnEvent = func(event *api.PistonEvent) {
+			// queued events are reported again on every retry, since the request is queued again
+			if event.Type != api.PistonEventTypeQueued {
+				progressed = true
+			}
+
+			onEvent(event)
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		var resp *api.PistonExecuteResponse
+		var err error
+		if onEvent != nil {
+			resp, err = ExecuteStream(ctx, c.client, data, trackedOnEvent)
+		} else {
+			resp, err = c.client.Execute(ctx, data)
+		}
+
+		if err == nil {
+			return resp, nil
+		}
+
+		if retry >= c.maxRetries || progressed || !IsRetryable(err) {
+			return nil, errutils.FormatError(err)
+		}
+
+		err = c.wait(ctx, retry)
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+	}
+}
+
+// wait blocks for the jittered backoff delay before a given retry, or until the context is done.
+func (c *retryingClient) wait(ctx context.Context, retry int) error {
+	delay := c.backoff(retry)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errutils.FormatError(ctx.Err())
+	}
+}
+
+// backoff computes the jittered backoff delay before a given retry.
+func (c *retryingClient) backoff(retry int) time.Duration {
+	delay := c.baseDelay
+	for range retry {
+		if delay >= c.maxDelay {
+			break
+		}
+
+		delay *= 2
+	}
+
+	delay = min(delay, c.maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	jittered, err := random.GenerateInt64(int64(delay) + 1)
+	if err != nil {
+		return delay
+	}
+
+	return time.Duration(jittered)
+}
@@ -0,0 +1,285 @@
+package piston_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRetryingClientExecute(t *testing.T) {
+	t.Parallel()
+
+	rateLimitedErr := errutils.FormatError(errutils.ErrCodeExecutionRateLimited)
+	unavailableErr := errutils.FormatError(
+		errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted),
+	)
+	timedOutErr := errutils.FormatError(errutils.ErrCodeExecutionUnavailable)
+	rejectedErr := errutils.FormatError(errutils.ErrCodeExecutionRejected)
+	circuitErr := &piston.CircuitOpenError{RetryAfter: time.Minute}
+	wantResp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+
+	testcases := map[string]struct {
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		"Succeeds without retries": {
+			errs:      []error{nil},
+			wantCalls: 1,
+			wantErr:   nil,
+		},
+		"Succeeds after retrying rate limited and unavailable": {
+			errs:      []error{rateLimitedErr, unavailableErr, nil},
+			wantCalls: 3,
+			wantErr:   nil,
+		},
+		"Gives up after max retries": {
+			errs:      []error{unavailableErr, unavailableErr, unavailableErr, unavailableErr},
+			wantCalls: 4,
+			wantErr:   errutils.ErrCodeExecutionUnavailable,
+		},
+		"Request that may have been executed is not retried": {
+			errs:      []error{timedOutErr},
+			wantCalls: 1,
+			wantErr:   errutils.ErrCodeExecutionUnavailable,
+		},
+		"Rejected request is not retried": {
+			errs:      []error{rejectedErr},
+			wantCalls: 1,
+			wantErr:   errutils.ErrCodeExecutionRejected,
+		},
+		"Open circuit is not retried": {
+			errs:      []error{circuitErr},
+			wantCalls: 1,
+			wantErr:   errutils.ErrCodeExecutionUnavailable,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			innerClient := pistonmocks.NewMockClient(ctrl)
+
+			calls := 0
+			innerClient.
+				EXPECT().
+				Execute(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+					err := testcase.errs[calls]
+					calls++
+					if err != nil {
+						return nil, err
+					}
+
+					return wantResp, nil
+				}).
+				Times(testcase.wantCalls)
+
+			client := piston.NewRetryingClient(innerClient, 3, time.Millisecond, 4*time.Millisecond)
+
+			resp, err := client.Execute(context.Background(), &api.PistonExecuteRequest{})
+			if testcase.wantErr != nil {
+				require.ErrorIs(t, err, testcase.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, wantResp, resp)
+		})
+	}
+}
+
+func TestRetryingClientExecuteStream(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		events    []*api.PistonEvent
+		wantCalls int
+	}{
+		"Retried after queued event": {
+			events: []*api.PistonEvent{
+				{Type: api.PistonEventTypeQueued},
+			},
+			wantCalls: 2,
+		},
+		"Not retried after running event": {
+			events: []*api.PistonEvent{
+				{Type: api.PistonEventTypeQueued},
+				{Type: api.PistonEventTypeRunning},
+			},
+			wantCalls: 1,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			innerClient := pistonmocks.NewMockStreamingClient(ctrl)
+
+			innerClient.
+				EXPECT().
+				ExecuteStream(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(
+					_ context.Context,
+					_ *api.PistonExecuteRequest,
+					onEvent func(event *api.PistonEvent),
+				) (*api.PistonExecuteResponse, error) {
+					for _, event := range testcase.events {
+						onEvent(event)
+					}
+
+					return nil, errutils.FormatError(
+						errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted),
+					)
+				}).
+				Times(testcase.wantCalls)
+
+			client := piston.NewRetryingClient(innerClient, 1, time.Millisecond, time.Millisecond)
+
+			_, err := client.ExecuteStream(context.Background(), &api.PistonExecuteRequest{}, func(*api.PistonEvent) {})
+			require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+		})
+	}
+}
+
+func TestRetryingClientExecuteContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *api.PistonExecuteRequest) (*api.PistonExecuteResponse, error) {
+			cancel()
+
+			return nil, errutils.FormatError(
+				errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted),
+			)
+		}).
+		Times(1)
+
+	client := piston.NewRetryingClient(innerClient, 3, time.Hour, time.Hour)
+
+	_, err := client.Execute(ctx, &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryingClientRuntimes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+	}
+
+	gomock.InOrder(
+		innerClient.
+			EXPECT().
+			Runtimes(gomock.Any()).
+			Return(nil, errutils.FormatError(errutils.ErrCodeExecutionRateLimited)).
+			Times(1),
+		innerClient.
+			EXPECT().
+			Runtimes(gomock.Any()).
+			Return(wantRuntimes, nil).
+			Times(1),
+	)
+
+	client := piston.NewRetryingClient(innerClient, 3, time.Millisecond, time.Millisecond)
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, wantRuntimes, runtimes)
+}
+
+func TestRetryingClientRuntimesError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	innerErr := errors.New("Runtimes failed")
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(nil, innerErr).
+		Times(1)
+
+	client := piston.NewRetryingClient(innerClient, 3, time.Millisecond, time.Millisecond)
+
+	_, err := client.Runtimes(context.Background())
+	require.ErrorIs(t, err, innerErr)
+}
+
+func TestRetryingClientConnect(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockInteractiveClient(ctrl)
+	innerSession := pistonmocks.NewMockSession(ctrl)
+
+	gomock.InOrder(
+		innerClient.
+			EXPECT().
+			Connect(gomock.Any(), gomock.Any()).
+			Return(nil, errutils.FormatError(
+				errors.Join(errutils.ErrCodeExecutionUnavailable, errutils.ErrCodeExecutionNotStarted),
+			)).
+			Times(1),
+		innerClient.
+			EXPECT().
+			Connect(gomock.Any(), gomock.Any()).
+			Return(innerSession, nil).
+			Times(1),
+	)
+
+	client := piston.NewRetryingClient(innerClient, 3, time.Millisecond, time.Millisecond)
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.NoError(t, err)
+	require.Equal(t, innerSession, session)
+}
+
+func TestRetryingClientConnectNotRetryable(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockInteractiveClient(ctrl)
+
+	innerClient.
+		EXPECT().
+		Connect(gomock.Any(), gomock.Any()).
+		Return(nil, errutils.FormatError(errutils.ErrCodeExecutionRuntimeNotFound)).
+		Times(1)
+
+	client := piston.NewRetryingClient(innerClient, 3, time.Millisecond, time.Millisecond)
+
+	_, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionRuntimeNotFound)
+}
@@ -0,0 +1,210 @@
+package piston
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+// runtimesCachingClient implements a Client that wraps another Client and caches its runtimes.
+// Executions are passed through to the wrapped Client,
+// while runtimes are only fetched from the wrapped Client when Refresh is called
+// or when no runtimes have been cached yet.
+type runtimesCachingClient struct {
+	client   Client
+	mu       sync.RWMutex
+	runtimes []*api.PistonRuntime
+}
+
+// NewRuntimesCachingClient returns a new runtimesCachingClient.
+func NewRuntimesCachingClient(client Client) *runtimesCachingClient {
+	return &runtimesCachingClient{
+		client:   client,
+		runtimes: nil,
+	}
+}
+
+// Execute executes the request using the wrapped Client.
+func (c *runtimesCachingClient) Execute(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+) (*api.PistonExecuteResponse, error) {
+	resp, err := c.client.Execute(ctx, data)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteStream executes the request using the wrapped Client, reporting progress events to onEvent.
+func (c *runtimesCachingClient) ExecuteStream(
+	ctx context.Context,
+	data *api.PistonExecuteRequest,
+	onEvent func(event *api.PistonEvent),
+) (*api.PistonExecuteResponse, error) {
+	resp, err := ExecuteStream(ctx, c.client, data, onEvent)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return resp, nil
+}
+
+// Connect starts an interactive execution using the wrapped Client.
+func (c *runtimesCachingClient) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	session, err := Connect(ctx, c.client, data)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	return session, nil
+}
+
+// Runtimes returns the cached runtimes, fetching them from the wrapped Client if none are cached.
+func (c *runtimesCachingClient) Runtimes(ctx context.Context) ([]*api.PistonRuntime, error) {
+	c.mu.RLock()
+	runtimes := c.runtimes
+	c.mu.RUnlock()
+
+	if runtimes != nil {
+		return runtimes, nil
+	}
+
+	err := c.Refresh(ctx)
+	if err != nil {
+		return nil, errutils.FormatError(err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.runtimes, nil
+}
+
+// Refresh fetches the runtimes from the wrapped Client and replaces the cached runtimes.
+// The cached runtimes are left untouched if fetching fails.
+func (c *runtimesCachingClient) Refresh(ctx context.Context) error {
+	runtimes, err := c.client.Runtimes(ctx)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.runtimes = runtimes
+
+	return nil
+}
+
+// RefreshEvery calls Refresh at a given interval in the background until the returned function is called.
+// Errors from Refresh are reported to onError.
+// The returned function cancels any refresh in progress and waits for it to finish before returning.
+func (c *runtimesCachingClient) RefreshEvery(interval time.Duration, onError func(err error)) func() {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				err := c.Refresh(ctx)
+				if err != nil && ctx.Err() == nil {
+					onError(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			cancel()
+		})
+		<-stopped
+	}
+}
+
+// FindRuntime finds the latest version of the runtime for a given language or alias.
+// It returns nil if no runtime matches.
+func FindRuntime(runtimes []*api.PistonRuntime, language string) *api.PistonRuntime {
+	var found *api.PistonRuntime
+	for _, runtime := range runtimes {
+		if !RuntimeMatches(runtime, language) {
+			continue
+		}
+
+		if found == nil || CompareVersions(runtime.Version, found.Version) > 0 {
+			found = runtime
+		}
+	}
+
+	return found
+}
+
+// FindRuntimeVersion finds the runtime for a given language or alias with a given version.
+// It returns nil if no runtime matches.
+func FindRuntimeVersion(runtimes []*api.PistonRuntime, language string, version string) *api.PistonRuntime {
+	for _, runtime := range runtimes {
+		if RuntimeMatches(runtime, language) && runtime.Version == version {
+			return runtime
+		}
+	}
+
+	return nil
+}
+
+// RuntimeMatches determines whether a given runtime is for a given language or alias.
+func RuntimeMatches(runtime *api.PistonRuntime, language string) bool {
+	if runtime.Language == language {
+		return true
+	}
+
+	for _, alias := range runtime.Aliases {
+		if alias == language {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompareVersions compares two dot-separated versions, such as "3.10.0" and "3.9.4".
+// It returns a negative number if a is older than b, a positive number if a is newer than b, and zero otherwise.
+// Numeric parts are compared numerically and other parts are compared lexicographically.
+func CompareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return aNum - bNum
+			}
+		default:
+			cmp := strings.Compare(aParts[i], bParts[i])
+			if cmp != 0 {
+				return cmp
+			}
+		}
+	}
+
+	return len(aParts) - len(bParts)
+}
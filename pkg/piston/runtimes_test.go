@@ -0,0 +1,440 @@
+package piston_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRuntimesCachingClientRuntimes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	wantRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+	}
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(wantRuntimes, nil).
+		Times(1)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+
+	for range 3 {
+		runtimes, err := client.Runtimes(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, wantRuntimes, runtimes)
+	}
+}
+
+func TestRuntimesCachingClientRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	oldRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+	}
+	newRuntimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		},
+		{
+			Language: api.PistonLanguageRust,
+			Version:  "1.68.2",
+		},
+	}
+	refreshErr := errors.New("Runtimes failed")
+
+	gomock.InOrder(
+		innerClient.
+			EXPECT().
+			Runtimes(gomock.Any()).
+			Return(oldRuntimes, nil).
+			Times(1),
+		innerClient.
+			EXPECT().
+			Runtimes(gomock.Any()).
+			Return(nil, refreshErr).
+			Times(1),
+		innerClient.
+			EXPECT().
+			Runtimes(gomock.Any()).
+			Return(newRuntimes, nil).
+			Times(1),
+	)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+
+	err := client.Refresh(context.Background())
+	require.NoError(t, err)
+
+	runtimes, err := client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, oldRuntimes, runtimes)
+
+	err = client.Refresh(context.Background())
+	require.ErrorIs(t, err, refreshErr)
+
+	runtimes, err = client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, oldRuntimes, runtimes)
+
+	err = client.Refresh(context.Background())
+	require.NoError(t, err)
+
+	runtimes, err = client.Runtimes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, newRuntimes, runtimes)
+}
+
+func TestRuntimesCachingClientRuntimesError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+	innerErr := errors.New("Runtimes failed")
+
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		Return(nil, innerErr).
+		Times(1)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+
+	_, err := client.Runtimes(context.Background())
+	require.ErrorIs(t, err, innerErr)
+}
+
+func TestRuntimesCachingClientRefreshEvery(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	refreshed := make(chan struct{})
+	innerClient.
+		EXPECT().
+		Runtimes(gomock.Any()).
+		DoAndReturn(func(_ context.Context) ([]*api.PistonRuntime, error) {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+
+			return []*api.PistonRuntime{}, nil
+		}).
+		MinTimes(1)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+	stop := client.RefreshEvery(time.Millisecond, func(err error) {
+		assert.NoError(t, err)
+	})
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "runtimes were not refreshed")
+	}
+
+	stop()
+	stop()
+}
+
+func TestRuntimesCachingClientExecute(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockClient(ctrl)
+
+	req := &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	wantResp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+
+	innerClient.
+		EXPECT().
+		Execute(gomock.Any(), req).
+		Return(wantResp, nil).
+		Times(1)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+
+	resp, err := client.Execute(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, wantResp, resp)
+}
+
+func TestRuntimesCachingClientExecuteStream(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	innerClient := pistonmocks.NewMockStreamingClient(ctrl)
+
+	req := &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	wantResp := &api.PistonExecuteResponse{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	}
+	wantEvent := &api.PistonEvent{
+		Type: api.PistonEventTypeQueued,
+	}
+
+	innerClient.
+		EXPECT().
+		ExecuteStream(gomock.Any(), req, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *api.PistonExecuteRequest,
+			onEvent func(event *api.PistonEvent),
+		) (*api.PistonExecuteResponse, error) {
+			onEvent(wantEvent)
+
+			return wantResp, nil
+		}).
+		Times(1)
+
+	client := piston.NewRuntimesCachingClient(innerClient)
+
+	events := []*api.PistonEvent{}
+	resp, err := client.ExecuteStream(context.Background(), req, func(event *api.PistonEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	require.Equal(t, wantResp, resp)
+	require.Equal(t, []*api.PistonEvent{wantEvent}, events)
+}
+
+func TestFindRuntime(t *testing.T) {
+	t.Parallel()
+
+	runtimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
+			Aliases:  []string{"py", "python3"},
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py", "py3", "python3"},
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "2.7.18",
+			Aliases:  []string{"py2", "python2"},
+		},
+		{
+			Language: api.PistonLanguageJavaScript,
+			Version:  "18.15.0",
+			Aliases:  []string{"js"},
+		},
+	}
+
+	testcases := map[string]struct {
+		language     string
+		wantLanguage string
+		wantVersion  string
+		wantFound    bool
+	}{
+		"Language name picks latest version": {
+			language:     api.PistonLanguagePython,
+			wantLanguage: api.PistonLanguagePython,
+			wantVersion:  "3.10.0",
+			wantFound:    true,
+		},
+		"Alias picks latest version with alias": {
+			language:     "python2",
+			wantLanguage: api.PistonLanguagePython,
+			wantVersion:  "2.7.18",
+			wantFound:    true,
+		},
+		"Alias shared by several versions": {
+			language:     "py",
+			wantLanguage: api.PistonLanguagePython,
+			wantVersion:  "3.10.0",
+			wantFound:    true,
+		},
+		"Other language alias": {
+			language:     "js",
+			wantLanguage: api.PistonLanguageJavaScript,
+			wantVersion:  "18.15.0",
+			wantFound:    true,
+		},
+		"Unknown language": {
+			language:     "parseltongue",
+			wantLanguage: "",
+			wantVersion:  "",
+			wantFound:    false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := piston.FindRuntime(runtimes, testcase.language)
+			if !testcase.wantFound {
+				require.Nil(t, runtime)
+
+				return
+			}
+
+			require.NotNil(t, runtime)
+			require.Equal(t, testcase.wantLanguage, runtime.Language)
+			require.Equal(t, testcase.wantVersion, runtime.Version)
+		})
+	}
+}
+
+func TestFindRuntimeVersion(t *testing.T) {
+	t.Parallel()
+
+	runtimes := []*api.PistonRuntime{
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.9.4",
+			Aliases:  []string{"py"},
+		},
+		{
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+			Aliases:  []string{"py"},
+		},
+		{
+			Language: api.PistonLanguageJavaScript,
+			Version:  "18.15.0",
+			Aliases:  []string{"js"},
+		},
+	}
+
+	testcases := map[string]struct {
+		language  string
+		version   string
+		wantFound bool
+	}{
+		"Language name and older version": {
+			language:  api.PistonLanguagePython,
+			version:   "3.9.4",
+			wantFound: true,
+		},
+		"Alias and latest version": {
+			language:  "py",
+			version:   "3.10.0",
+			wantFound: true,
+		},
+		"Version not installed": {
+			language:  api.PistonLanguagePython,
+			version:   "2.7.18",
+			wantFound: false,
+		},
+		"Version of other language": {
+			language:  api.PistonLanguagePython,
+			version:   "18.15.0",
+			wantFound: false,
+		},
+		"Unknown language": {
+			language:  "parseltongue",
+			version:   "3.10.0",
+			wantFound: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := piston.FindRuntimeVersion(runtimes, testcase.language, testcase.version)
+			if !testcase.wantFound {
+				require.Nil(t, runtime)
+
+				return
+			}
+
+			require.NotNil(t, runtime)
+			require.True(t, piston.RuntimeMatches(runtime, testcase.language))
+			require.Equal(t, testcase.version, runtime.Version)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		a        string
+		b        string
+		wantSign int
+	}{
+		"Equal versions": {
+			a:        "3.10.0",
+			b:        "3.10.0",
+			wantSign: 0,
+		},
+		"Numeric parts compared numerically": {
+			a:        "3.10.0",
+			b:        "3.9.4",
+			wantSign: 1,
+		},
+		"Older major version": {
+			a:        "2.7.18",
+			b:        "3.0.0",
+			wantSign: -1,
+		},
+		"More parts is newer": {
+			a:        "1.0.1",
+			b:        "1.0",
+			wantSign: 1,
+		},
+		"Non-numeric parts compared lexicographically": {
+			a:        "1.0.0-alpha",
+			b:        "1.0.0-beta",
+			wantSign: -1,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmp := piston.CompareVersions(testcase.a, testcase.b)
+			switch testcase.wantSign {
+			case 0:
+				require.Zero(t, cmp)
+			case 1:
+				require.Positive(t, cmp)
+			default:
+				require.Negative(t, cmp)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package piston
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/httputils"
+	"github.com/alvii147/nymphadora-api/pkg/websocket"
+)
+
+// SessionOutputMaxLength is the maximum length of standard output and standard error
+// kept in the results of an interactive session for each stage.
+// Output beyond this length is still reported in progress events, but is left out of the results.
+const SessionOutputMaxLength = 64 * 1024
+
+// pistonCloseJobCompleted is the close status code Piston uses once an interactive session has finished.
+const pistonCloseJobCompleted = 4999
+
+// sessionRecorder builds the results of an interactive session from the messages reported by the executor,
+// and translates those messages into progress events.
+type sessionRecorder struct {
+	mu       sync.Mutex
+	stage    string
+	results  *api.PistonExecuteResponse
+	finished bool
+}
+
+// newSessionRecorder returns a new sessionRecorder.
+func newSessionRecorder(language string, version string) *sessionRecorder {
+	return &sessionRecorder{
+		stage: api.PistonStageRun,
+		results: &api.PistonExecuteResponse{
+			Language: language,
+			Version:  version,
+			Compile:  nil,
+			Run:      api.PistonResults{},
+		},
+		finished: false,
+	}
+}
+
+// record updates the results with a given message and returns the progress event it describes,
+// or nil if it describes none.
+// The returned boolean is true once the run stage has exited.
+func (r *sessionRecorder) record(msg *api.PistonMessage) (*api.PistonEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if msg.Stage != nil {
+		r.stage = *msg.Stage
+	}
+
+	switch msg.Type {
+	case api.PistonMessageTypeStage:
+		if r.stage == api.PistonStageCompile {
+			r.results.Compile = &api.PistonResults{}
+
+			return &api.PistonEvent{
+				Type: api.PistonEventTypeCompiling,
+			}, false
+		}
+
+		return &api.PistonEvent{
+			Type: api.PistonEventTypeRunning,
+		}, false
+	case api.PistonMessageTypeData:
+		if msg.Stream == nil || msg.Data == nil {
+			return nil, false
+		}
+
+		results := r.stageResults()
+		var eventType string
+		switch *msg.Stream {
+		case api.PistonStreamStdout:
+			eventType = api.PistonEventTypeStdout
+			appendOutput(&results.Stdout, *msg.Data)
+		case api.PistonStreamStderr:
+			eventType = api.PistonEventTypeStderr
+			appendOutput(&results.Stderr, *msg.Data)
+		default:
+			return nil, false
+		}
+		appendOutput(&results.Output, *msg.Data)
+
+		stage := r.stage
+
+		return &api.PistonEvent{
+			Type:  eventType,
+			Stage: &stage,
+			Data:  msg.Data,
+		}, false
+	case api.PistonMessageTypeExit:
+		results := r.stageResults()
+		results.Code = msg.Code
+		results.Signal = msg.Signal
+		results.Message = msg.Message
+		results.Status = msg.Status
+		results.CPUTime = msg.CPUTime
+		results.WallTime = msg.WallTime
+		results.Memory = msg.Memory
+
+		if r.stage == api.PistonStageCompile {
+			return &api.PistonEvent{
+				Type:   api.PistonEventTypeCompileFinished,
+				Code:   msg.Code,
+				Signal: msg.Signal,
+			}, false
+		}
+
+		r.finished = true
+
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
+// stageResults returns the results of the current stage, creating the compilation results if needed.
+func (r *sessionRecorder) stageResults() *api.PistonResults {
+	if r.stage != api.PistonStageCompile {
+		return &r.results.Run
+	}
+
+	if r.results.Compile == nil {
+		r.results.Compile = &api.PistonResults{}
+	}
+
+	return r.results.Compile
+}
+
+// isFinished reports whether the run stage has exited.
+func (r *sessionRecorder) isFinished() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.finished
+}
+
+// finish marks the execution as finished.
+func (r *sessionRecorder) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finished = true
+}
+
+// snapshot returns a copy of the results recorded so far.
+func (r *sessionRecorder) snapshot() *api.PistonExecuteResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := *r.results
+	if r.results.Compile != nil {
+		compileResults := *r.results.Compile
+		results.Compile = &compileResults
+	}
+
+	return &results
+}
+
+// appendOutput appends data to a given output, up to SessionOutputMaxLength.
+func appendOutput(output *string, data string) {
+	remaining := SessionOutputMaxLength - len(*output)
+	if remaining <= 0 {
+		return
+	}
+
+	*output += data[:min(len(data), remaining)]
+}
+
+// pistonSession implements a Session that proxies an interactive execution on Piston over a WebSocket connection.
+type pistonSession struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stop      func() bool
+	conn      *websocket.Conn
+	recorder  *sessionRecorder
+	closeOnce sync.Once
+}
+
+// Connect starts an interactive execution on Piston.
+// The session is closed once the context is done,
+// or once the request's compile and run timeouts plus ExecuteDeadlineGracePeriod have passed.
+func (c *client) Connect(ctx context.Context, data *api.PistonExecuteRequest) (Session, error) {
+	var sessionCtx context.Context
+	var cancel context.CancelFunc
+	if timeout, ok := ExecuteDeadline(data); ok {
+		sessionCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		sessionCtx, cancel = context.WithCancel(ctx)
+	}
+
+	header := http.Header{}
+	if c.key != nil {
+		header.Set(httputils.HTTPHeaderAuthorization, *c.key)
+	}
+
+	conn, err := websocket.Dial(sessionCtx, c.baseURL+PistonConnectPath, header)
+	if err != nil {
+		cancel()
+
+		var handshakeErr *websocket.HandshakeError
+		if errors.As(err, &handshakeErr) {
+			return nil, errutils.FormatErrorf(
+				classifyStatusCode(handshakeErr.StatusCode, handshakeErr.Body),
+				"websocket.Dial returned status code %d %v",
+				handshakeErr.StatusCode,
+				string(handshakeErr.Body),
+			)
+		}
+
+		return nil, errutils.FormatError(classifyTransportError(sessionCtx, err), "websocket.Dial failed")
+	}
+
+	s := &pistonSession{
+		ctx:    sessionCtx,
+		cancel: cancel,
+		stop: context.AfterFunc(sessionCtx, func() {
+			_ = conn.Close()
+		}),
+		conn:     conn,
+		recorder: newSessionRecorder(data.Language, data.Version),
+	}
+
+	err = conn.WriteJSON(&api.PistonMessage{
+		Type:               api.PistonMessageTypeInit,
+		Language:           data.Language,
+		Version:            data.Version,
+		Files:              data.Files,
+		Args:               data.Args,
+		CompileTimeout:     data.CompileTimeout,
+		RunTimeout:         data.RunTimeout,
+		CompileMemoryLimit: data.CompileMemoryLimit,
+		RunMemoryLimit:     data.RunMemoryLimit,
+	})
+	if err != nil {
+		err = s.classifyError(err)
+		_ = s.Close()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	// Piston confirms the session with the runtime it picked, or reports why it could not start one
+	msg, err := s.receive()
+	if errors.Is(err, io.EOF) {
+		err = errors.Join(errutils.ErrCodeExecutionUnavailable, err)
+	}
+
+	if err != nil {
+		_ = s.Close()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	if msg.Type != api.PistonMessageTypeRuntime {
+		_ = s.Close()
+
+		return nil, errutils.FormatErrorf(
+			errutils.ErrCodeExecutionUnavailable,
+			"expected %s message, got %s",
+			api.PistonMessageTypeRuntime,
+			msg.Type,
+		)
+	}
+
+	return s, nil
+}
+
+// Receive waits for the next progress event from Piston, returning io.EOF once the execution has finished.
+func (s *pistonSession) Receive() (*api.PistonEvent, error) {
+	for !s.recorder.isFinished() {
+		msg, err := s.receive()
+		if errors.Is(err, io.EOF) {
+			// Piston ends sessions without running the program if compilation fails
+			s.recorder.finish()
+
+			break
+		}
+
+		if err != nil {
+			return nil, errutils.FormatError(err)
+		}
+
+		event, finished := s.recorder.record(msg)
+		if finished {
+			break
+		}
+
+		if event != nil {
+			return event, nil
+		}
+	}
+
+	return nil, io.EOF
+}
+
+// WriteStdin sends data to the standard input of the running program.
+func (s *pistonSession) WriteStdin(data string) error {
+	stream := api.PistonStreamStdin
+	err := s.conn.WriteJSON(&api.PistonMessage{
+		Type:   api.PistonMessageTypeData,
+		Stream: &stream,
+		Data:   &data,
+	})
+	if err != nil {
+		return errutils.FormatError(s.classifyError(err))
+	}
+
+	return nil
+}
+
+// Signal sends a signal to the running program.
+func (s *pistonSession) Signal(signal string) error {
+	err := s.conn.WriteJSON(&api.PistonMessage{
+		Type:   api.PistonMessageTypeSignal,
+		Signal: &signal,
+	})
+	if err != nil {
+		return errutils.FormatError(s.classifyError(err))
+	}
+
+	return nil
+}
+
+// Results returns the results reported by Piston so far.
+func (s *pistonSession) Results() *api.PistonExecuteResponse {
+	return s.recorder.snapshot()
+}
+
+// Close closes the connection to Piston, which kills the program if it is still running.
+func (s *pistonSession) Close() error {
+	s.closeOnce.Do(func() {
+		s.stop()
+		s.cancel()
+		_ = s.conn.WriteClose(websocket.CloseNormalClosure, "")
+		_ = s.conn.Close()
+	})
+
+	return nil
+}
+
+// receive reads the next message from Piston, returning io.EOF once Piston reports the session has finished.
+// Error messages from Piston are returned as errors.
+func (s *pistonSession) receive() (*api.PistonMessage, error) {
+	msg := &api.PistonMessage{}
+	err := s.conn.ReadJSON(msg)
+	if websocket.IsCloseError(err, pistonCloseJobCompleted) {
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(s.classifyError(err))
+	}
+
+	if msg.Type == api.PistonMessageTypeError {
+		message := ""
+		if msg.Message != nil {
+			message = *msg.Message
+		}
+
+		return nil, errutils.FormatErrorf(
+			classifyStatusCode(http.StatusBadRequest, []byte(message)),
+			"piston session failed: %s",
+			message,
+		)
+	}
+
+	return msg, nil
+}
+
+// classifyError returns the error that describes a failure to communicate with Piston.
+// Failures caused by the session's context being done are reported as the context's error,
+// and sessions closed by Piston for any other reason are reported as rejected.
+func (s *pistonSession) classifyError(err error) error {
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		return errors.Join(ctxErr, err)
+	}
+
+	if websocket.IsCloseError(err) {
+		return errors.Join(errutils.ErrCodeExecutionRejected, err)
+	}
+
+	return classifyTransportError(s.ctx, err)
+}
@@ -0,0 +1,364 @@
+package piston_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/pkg/api"
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+	"github.com/alvii147/nymphadora-api/pkg/httputils"
+	"github.com/alvii147/nymphadora-api/pkg/piston"
+	pistonmocks "github.com/alvii147/nymphadora-api/pkg/piston/mocks"
+	"github.com/alvii147/nymphadora-api/pkg/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newPistonSessionServer starts a server that acts as Piston's interactive session endpoint,
+// reading the init message and running handle on the connection.
+func newPistonSessionServer(t *testing.T, handle func(conn *websocket.Conn, init *api.PistonMessage)) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, piston.PistonConnectPath, r.URL.Path)
+
+		conn, err := websocket.Upgrade(w, r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		init := &api.PistonMessage{}
+		err = conn.ReadJSON(init)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		handle(conn, init)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func pistonMessage(messageType string, stage string, stream string, data string) *api.PistonMessage {
+	msg := &api.PistonMessage{
+		Type: messageType,
+	}
+
+	if stage != "" {
+		msg.Stage = &stage
+	}
+
+	if stream != "" {
+		msg.Stream = &stream
+	}
+
+	if data != "" {
+		msg.Data = &data
+	}
+
+	return msg
+}
+
+func TestPistonClientConnect(t *testing.T) {
+	t.Parallel()
+
+	key := "0xdeadbeef"
+	exitCodeZero := 0
+	signal := "SIGINT"
+	wallTime := int64(1200)
+
+	baseURL := newPistonSessionServer(t, func(conn *websocket.Conn, init *api.PistonMessage) {
+		assert.Equal(t, api.PistonMessageTypeInit, init.Type)
+		assert.Equal(t, api.PistonLanguageC, init.Language)
+		assert.Equal(t, "10.2.0", init.Version)
+		assert.Equal(t, []string{"--verbose"}, init.Args)
+
+		compileExit := pistonMessage(api.PistonMessageTypeExit, api.PistonStageCompile, "", "")
+		compileExit.Code = &exitCodeZero
+
+		for _, msg := range []*api.PistonMessage{
+			{Type: api.PistonMessageTypeRuntime, Language: api.PistonLanguageC, Version: "10.2.0"},
+			pistonMessage(api.PistonMessageTypeStage, api.PistonStageCompile, "", ""),
+			pistonMessage(api.PistonMessageTypeData, "", api.PistonStreamStderr, "warning: unused variable"),
+			compileExit,
+			pistonMessage(api.PistonMessageTypeStage, api.PistonStageRun, "", ""),
+			pistonMessage(api.PistonMessageTypeData, "", api.PistonStreamStdout, "What is your name?\n"),
+		} {
+			if !assert.NoError(t, conn.WriteJSON(msg)) {
+				return
+			}
+		}
+
+		stdin := &api.PistonMessage{}
+		if !assert.NoError(t, conn.ReadJSON(stdin)) {
+			return
+		}
+		assert.Equal(t, api.PistonMessageTypeData, stdin.Type)
+		assert.Equal(t, api.PistonStreamStdin, *stdin.Stream)
+
+		err := conn.WriteJSON(pistonMessage(api.PistonMessageTypeData, "", api.PistonStreamStdout, "Hello, "+*stdin.Data))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		signalMsg := &api.PistonMessage{}
+		if !assert.NoError(t, conn.ReadJSON(signalMsg)) {
+			return
+		}
+		assert.Equal(t, api.PistonMessageTypeSignal, signalMsg.Type)
+
+		runExit := pistonMessage(api.PistonMessageTypeExit, api.PistonStageRun, "", "")
+		runExit.Signal = signalMsg.Signal
+		runExit.WallTime = &wallTime
+
+		assert.NoError(t, conn.WriteJSON(runExit))
+		assert.NoError(t, conn.WriteClose(4999, "Job Completed"))
+	})
+
+	client := piston.NewClient(baseURL, &key, httputils.NewHTTPClient(nil))
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguageC,
+		Version:  "10.2.0",
+		Args:     []string{"--verbose"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	compileStage := api.PistonStageCompile
+	runStage := api.PistonStageRun
+	compileWarning := "warning: unused variable"
+	prompt := "What is your name?\n"
+	greeting := "Hello, Harry\n"
+
+	wantEvents := []*api.PistonEvent{
+		{Type: api.PistonEventTypeCompiling},
+		{Type: api.PistonEventTypeStderr, Stage: &compileStage, Data: &compileWarning},
+		{Type: api.PistonEventTypeCompileFinished, Code: &exitCodeZero},
+		{Type: api.PistonEventTypeRunning},
+		{Type: api.PistonEventTypeStdout, Stage: &runStage, Data: &prompt},
+	}
+	for _, wantEvent := range wantEvents {
+		event, err := session.Receive()
+		require.NoError(t, err)
+		require.Equal(t, wantEvent, event)
+	}
+
+	err = session.WriteStdin("Harry\n")
+	require.NoError(t, err)
+
+	event, err := session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, &api.PistonEvent{Type: api.PistonEventTypeStdout, Stage: &runStage, Data: &greeting}, event)
+
+	err = session.Signal(signal)
+	require.NoError(t, err)
+
+	_, err = session.Receive()
+	require.ErrorIs(t, err, io.EOF)
+
+	results := session.Results()
+	require.Equal(t, api.PistonLanguageC, results.Language)
+	require.NotNil(t, results.Compile)
+	require.Equal(t, compileWarning, results.Compile.Stderr)
+	require.Equal(t, &exitCodeZero, results.Compile.Code)
+	require.Equal(t, prompt+greeting, results.Run.Stdout)
+	require.Equal(t, prompt+greeting, results.Run.Output)
+	require.Nil(t, results.Run.Code)
+	require.Equal(t, &signal, results.Run.Signal)
+	require.Equal(t, &wallTime, results.Run.WallTime)
+}
+
+func TestPistonClientConnectCompileFailed(t *testing.T) {
+	t.Parallel()
+
+	exitCodeOne := 1
+
+	baseURL := newPistonSessionServer(t, func(conn *websocket.Conn, _ *api.PistonMessage) {
+		compileExit := pistonMessage(api.PistonMessageTypeExit, api.PistonStageCompile, "", "")
+		compileExit.Code = &exitCodeOne
+
+		for _, msg := range []*api.PistonMessage{
+			{Type: api.PistonMessageTypeRuntime, Language: api.PistonLanguageC, Version: "10.2.0"},
+			pistonMessage(api.PistonMessageTypeStage, api.PistonStageCompile, "", ""),
+			compileExit,
+		} {
+			if !assert.NoError(t, conn.WriteJSON(msg)) {
+				return
+			}
+		}
+
+		assert.NoError(t, conn.WriteClose(4999, "Job Completed"))
+	})
+
+	client := piston.NewClient(baseURL, nil, httputils.NewHTTPClient(nil))
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguageC,
+		Version:  "10.2.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	event, err := session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, api.PistonEventTypeCompiling, event.Type)
+
+	event, err = session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, api.PistonEventTypeCompileFinished, event.Type)
+	require.Equal(t, &exitCodeOne, event.Code)
+
+	for range 2 {
+		_, err = session.Receive()
+		require.ErrorIs(t, err, io.EOF)
+	}
+
+	results := session.Results()
+	require.NotNil(t, results.Compile)
+	require.Equal(t, &exitCodeOne, results.Compile.Code)
+}
+
+func TestPistonClientConnectErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		message string
+		wantErr error
+	}{
+		"Unknown runtime": {
+			message: "python-2.0.0 runtime is unknown",
+			wantErr: errutils.ErrCodeExecutionRuntimeNotFound,
+		},
+		"Invalid request": {
+			message: "files is required as an array",
+			wantErr: errutils.ErrCodeExecutionRejected,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			baseURL := newPistonSessionServer(t, func(conn *websocket.Conn, _ *api.PistonMessage) {
+				assert.NoError(t, conn.WriteJSON(&api.PistonMessage{
+					Type:    api.PistonMessageTypeError,
+					Message: &testcase.message,
+				}))
+			})
+
+			client := piston.NewClient(baseURL, nil, httputils.NewHTTPClient(nil))
+
+			_, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+				Language: api.PistonLanguagePython,
+				Version:  "2.0.0",
+			})
+			require.ErrorIs(t, err, testcase.wantErr)
+		})
+	}
+}
+
+func TestPistonClientConnectHandshakeError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := piston.NewClient(srv.URL, nil, httputils.NewHTTPClient(nil))
+
+	_, err := client.Connect(context.Background(), &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionUnavailable)
+	require.True(t, piston.IsRetryable(err))
+}
+
+func TestPistonClientConnectContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan struct{})
+	baseURL := newPistonSessionServer(t, func(conn *websocket.Conn, _ *api.PistonMessage) {
+		assert.NoError(t, conn.WriteJSON(&api.PistonMessage{
+			Type:     api.PistonMessageTypeRuntime,
+			Language: api.PistonLanguagePython,
+			Version:  "3.10.0",
+		}))
+
+		<-done
+	})
+	t.Cleanup(func() {
+		close(done)
+	})
+
+	client := piston.NewClient(baseURL, nil, httputils.NewHTTPClient(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := client.Connect(ctx, &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	cancel()
+
+	_, err = session.Receive()
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestConnectNotInteractive(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := pistonmocks.NewMockClient(ctrl)
+
+	_, err := piston.Connect(context.Background(), client, &api.PistonExecuteRequest{})
+	require.ErrorIs(t, err, errutils.ErrCodeExecutionNotInteractive)
+}
+
+func TestSessionOutputMaxLength(t *testing.T) {
+	t.Parallel()
+
+	client := piston.NewFakeClient()
+
+	session, err := client.Connect(context.Background(), &api.PistonExecuteRequest{
+		Language: api.PistonLanguagePython,
+		Version:  "3.10.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+	})
+
+	event, err := session.Receive()
+	require.NoError(t, err)
+	require.Equal(t, api.PistonEventTypeRunning, event.Type)
+
+	data := strings.Repeat("a", piston.SessionOutputMaxLength-1)
+	for range 2 {
+		err = session.WriteStdin(data)
+		require.NoError(t, err)
+
+		// output beyond the maximum length is still reported in events
+		event, err = session.Receive()
+		require.NoError(t, err)
+		require.Equal(t, data, *event.Data)
+	}
+
+	results := session.Results()
+	require.Len(t, results.Run.Stdout, piston.SessionOutputMaxLength)
+	require.Len(t, results.Run.Output, piston.SessionOutputMaxLength)
+}
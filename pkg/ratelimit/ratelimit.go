@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+)
+
+// TokenBucket is a token bucket rate limiter.
+// Tokens are added to the bucket at a fixed rate, up to a maximum burst size,
+// and each request consumes a single token.
+type TokenBucket struct {
+	mu           sync.Mutex
+	timeProvider timekeeper.Provider
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+}
+
+// NewTokenBucket returns a new TokenBucket that refills at rate tokens per second
+// and holds at most burst tokens. The bucket starts full.
+func NewTokenBucket(timeProvider timekeeper.Provider, rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		timeProvider: timeProvider,
+		rate:         rate,
+		burst:        float64(burst),
+		tokens:       float64(burst),
+		last:         timeProvider.Now(),
+	}
+}
+
+// refill adds tokens accumulated since the last refill.
+// This must be called with the mutex held.
+func (b *TokenBucket) refill() {
+	now := b.timeProvider.Now()
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+	}
+
+	b.last = now
+}
+
+// Allow consumes a token and reports whether one was available.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// Reserve consumes a token and returns how long the caller must wait before the token becomes available.
+// A zero duration means the caller can proceed immediately.
+func (b *TokenBucket) Reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Wait blocks until a token is available and consumes it.
+// If the context is done before the token becomes available,
+// the token is returned to the bucket and the context's error is returned.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	d := b.Reserve()
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
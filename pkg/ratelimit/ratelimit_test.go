@@ -0,0 +1,83 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alvii147/nymphadora-api/pkg/ratelimit"
+	"github.com/alvii147/nymphadora-api/pkg/timekeeper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	bucket := ratelimit.NewTokenBucket(timeProvider, 5, 2)
+
+	require.True(t, bucket.Allow())
+	require.True(t, bucket.Allow())
+	require.False(t, bucket.Allow())
+
+	timeProvider.Add(100 * time.Millisecond)
+	require.False(t, bucket.Allow())
+
+	timeProvider.Add(100 * time.Millisecond)
+	require.True(t, bucket.Allow())
+	require.False(t, bucket.Allow())
+
+	timeProvider.Add(time.Minute)
+	require.True(t, bucket.Allow())
+	require.True(t, bucket.Allow())
+	require.False(t, bucket.Allow())
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	bucket := ratelimit.NewTokenBucket(timeProvider, 5, 1)
+
+	require.Equal(t, time.Duration(0), bucket.Reserve())
+	require.InDelta(t, float64(200*time.Millisecond), float64(bucket.Reserve()), float64(time.Millisecond))
+	require.InDelta(t, float64(400*time.Millisecond), float64(bucket.Reserve()), float64(time.Millisecond))
+
+	timeProvider.Add(time.Second)
+	require.Equal(t, time.Duration(0), bucket.Reserve())
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewSystemProvider()
+	bucket := ratelimit.NewTokenBucket(timeProvider, 20, 1)
+
+	start := time.Now()
+	err := bucket.Wait(context.Background())
+	require.NoError(t, err)
+	err = bucket.Wait(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestTokenBucketWaitContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	timeProvider := timekeeper.NewFrozenProvider()
+	bucket := ratelimit.NewTokenBucket(timeProvider, 1, 1)
+
+	err := bucket.Wait(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = bucket.Wait(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the token reserved by the cancelled wait is returned to the bucket
+	timeProvider.Add(time.Second)
+	require.True(t, bucket.Allow())
+	require.False(t, bucket.Allow())
+}
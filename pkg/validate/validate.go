@@ -11,6 +11,13 @@ import (
 // reSlug is a compiled regular expression for slug string validation.
 var reSlug = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 
+// reFilePath is a compiled regular expression for relative file path validation.
+// Path segments may not begin with a dot, which rules out "." and ".." segments.
+var reFilePath = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(?:/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// reEnvVarName is a compiled regular expression for environment variable name validation.
+var reEnvVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Validator validates given values and accumulates validation errors.
 type Validator struct {
 	failures map[string][]string
@@ -59,6 +66,20 @@ func (v *Validator) ValidateStringMinLength(field string, value string, minLen i
 	}
 }
 
+// ValidateInt64MinValue validates that a given integer is at least a given value.
+func (v *Validator) ValidateInt64MinValue(field string, value int64, minValue int64) {
+	if value < minValue {
+		v.addFailure(field, "\"%s\" must be at least %d", field, minValue)
+	}
+}
+
+// ValidateInt64MaxValue validates that a given integer is at most a given value.
+func (v *Validator) ValidateInt64MaxValue(field string, value int64, maxValue int64) {
+	if value > maxValue {
+		v.addFailure(field, "\"%s\" cannot be more than %d", field, maxValue)
+	}
+}
+
 // ValidateStringEmail validates the format of a given email address.
 func (v *Validator) ValidateStringEmail(field string, email string) {
 	_, err := mail.ParseAddress(email)
@@ -74,6 +95,20 @@ func (v *Validator) ValidateStringSlug(field string, value string) {
 	}
 }
 
+// ValidateStringFilePath validates that a given string is a valid relative file path.
+func (v *Validator) ValidateStringFilePath(field string, value string) {
+	if !reFilePath.MatchString(value) {
+		v.addFailure(field, "\"%s\" must be a valid relative file path", field)
+	}
+}
+
+// ValidateStringEnvVarName validates that a given string is a valid environment variable name.
+func (v *Validator) ValidateStringEnvVarName(field string, value string) {
+	if !reEnvVarName.MatchString(value) {
+		v.addFailure(field, "\"%s\" must be a valid environment variable name", field)
+	}
+}
+
 // ValidateStringOptions validates that a given string belongs to one of the given options.
 func (v *Validator) ValidateStringOptions(field string, value string, options []string, caseSensitive bool) {
 	if !caseSensitive {
@@ -92,3 +127,40 @@ func (v *Validator) ValidateStringOptions(field string, value string, options []
 
 	v.addFailure(field, "\"%s\" must be one of the following options: %v", field, options)
 }
+
+// ValidateStringsUnique validates that given strings contain no duplicates.
+func (v *Validator) ValidateStringsUnique(field string, values []string) {
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			v.addFailure(field, "\"%s\" cannot contain duplicates", field)
+
+			return
+		}
+
+		seen[value] = struct{}{}
+	}
+}
+
+// ValidateStringRegex validates that a given string is a valid regular expression.
+func (v *Validator) ValidateStringRegex(field string, value string) {
+	_, err := regexp.Compile(value)
+	if err != nil {
+		v.addFailure(field, "\"%s\" must be a valid regular expression", field)
+	}
+}
+
+// ValidateExactlyOneSet validates that exactly one of the given options is set.
+// Options are given by name, and whether or not each option is set is given in the same order.
+func (v *Validator) ValidateExactlyOneSet(field string, options []string, set ...bool) {
+	count := 0
+	for _, isSet := range set {
+		if isSet {
+			count++
+		}
+	}
+
+	if count != 1 {
+		v.addFailure(field, "\"%s\" must set exactly one of the following options: %v", field, options)
+	}
+}
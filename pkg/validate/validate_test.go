@@ -134,6 +134,100 @@ func TestValidateStringMinLength(t *testing.T) {
 	}
 }
 
+func TestValidateInt64MinValue(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+
+	testcases := map[string]struct {
+		value      int64
+		minValue   int64
+		wantPassed bool
+	}{
+		"Value above minimum": {
+			value:      42,
+			minValue:   10,
+			wantPassed: true,
+		},
+		"Value equal to minimum": {
+			value:      10,
+			minValue:   10,
+			wantPassed: true,
+		},
+		"Value below minimum": {
+			value:      -1,
+			minValue:   0,
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateInt64MinValue(field, testcase.value, testcase.minValue)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
+func TestValidateInt64MaxValue(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+
+	testcases := map[string]struct {
+		value      int64
+		maxValue   int64
+		wantPassed bool
+	}{
+		"Value below maximum": {
+			value:      7,
+			maxValue:   10,
+			wantPassed: true,
+		},
+		"Value equal to maximum": {
+			value:      10,
+			maxValue:   10,
+			wantPassed: true,
+		},
+		"Value above maximum": {
+			value:      42,
+			maxValue:   10,
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateInt64MaxValue(field, testcase.value, testcase.maxValue)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
 func TestValidateStringEmail(t *testing.T) {
 	t.Parallel()
 
@@ -216,6 +310,120 @@ func TestValidateStringSlug(t *testing.T) {
 	}
 }
 
+func TestValidateStringFilePath(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+
+	testcases := map[string]struct {
+		value      string
+		wantPassed bool
+	}{
+		"Valid file name": {
+			value:      "main.py",
+			wantPassed: true,
+		},
+		"Valid nested file path": {
+			value:      "include/utils_v2.h",
+			wantPassed: true,
+		},
+		"Empty string": {
+			value:      "",
+			wantPassed: false,
+		},
+		"Absolute path": {
+			value:      "/etc/passwd",
+			wantPassed: false,
+		},
+		"Path with parent directory segment": {
+			value:      "../main.py",
+			wantPassed: false,
+		},
+		"Path with trailing slash": {
+			value:      "include/",
+			wantPassed: false,
+		},
+		"String with invalid characters": {
+			value:      "hello w*rld.py",
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateStringFilePath(field, testcase.value)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
+func TestValidateStringEnvVarName(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+
+	testcases := map[string]struct {
+		value      string
+		wantPassed bool
+	}{
+		"Valid name": {
+			value:      "API_TOKEN",
+			wantPassed: true,
+		},
+		"Valid name with leading underscore and digits": {
+			value:      "_token2",
+			wantPassed: true,
+		},
+		"Empty string": {
+			value:      "",
+			wantPassed: false,
+		},
+		"Name with leading digit": {
+			value:      "2FA_SECRET",
+			wantPassed: false,
+		},
+		"Name with equals sign": {
+			value:      "API=TOKEN",
+			wantPassed: false,
+		},
+		"Name with invalid characters": {
+			value:      "API-TOKEN",
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateStringEnvVarName(field, testcase.value)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
 func TestValidateStringOptions(t *testing.T) {
 	t.Parallel()
 
@@ -284,3 +492,141 @@ func TestValidateStringOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStringsUnique(t *testing.T) {
+	t.Parallel()
+
+	field := "values"
+
+	testcases := map[string]struct {
+		values     []string
+		wantPassed bool
+	}{
+		"Unique strings": {
+			values:     []string{"lorem", "ipsum", "deadbeef"},
+			wantPassed: true,
+		},
+		"Empty strings": {
+			values:     []string{},
+			wantPassed: true,
+		},
+		"Duplicate strings": {
+			values:     []string{"lorem", "ipsum", "lorem"},
+			wantPassed: false,
+		},
+		"Strings differing only in case": {
+			values:     []string{"deadbeef", "DeAdBeEf"},
+			wantPassed: true,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateStringsUnique(field, testcase.values)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
+func TestValidateStringRegex(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+
+	testcases := map[string]struct {
+		value      string
+		wantPassed bool
+	}{
+		"Literal string": {
+			value:      "deadbeef",
+			wantPassed: true,
+		},
+		"Valid regular expression": {
+			value:      `^\d+(\.\d+)?$`,
+			wantPassed: true,
+		},
+		"Unclosed group": {
+			value:      "(dead",
+			wantPassed: false,
+		},
+		"Invalid repetition": {
+			value:      "*beef",
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateStringRegex(field, testcase.value)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
+
+func TestValidateExactlyOneSet(t *testing.T) {
+	t.Parallel()
+
+	field := "value"
+	options := []string{"lorem", "ipsum", "dolor"}
+
+	testcases := map[string]struct {
+		set        []bool
+		wantPassed bool
+	}{
+		"One option set": {
+			set:        []bool{false, true, false},
+			wantPassed: true,
+		},
+		"No options set": {
+			set:        []bool{false, false, false},
+			wantPassed: false,
+		},
+		"Several options set": {
+			set:        []bool{true, false, true},
+			wantPassed: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := validate.NewValidator()
+			v.ValidateExactlyOneSet(field, options, testcase.set...)
+			require.Equal(t, testcase.wantPassed, v.Passed())
+
+			failures := v.Failures()
+			if testcase.wantPassed {
+				require.Empty(t, failures)
+
+				return
+			}
+
+			require.NotEmpty(t, failures[field])
+		})
+	}
+}
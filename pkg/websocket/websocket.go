@@ -0,0 +1,653 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alvii147/nymphadora-api/pkg/errutils"
+)
+
+// message opcodes, as defined in RFC 6455.
+const (
+	// OpcodeContinuation represents frames that continue a fragmented message.
+	OpcodeContinuation = 0x0
+	// OpcodeText represents frames that start a UTF-8 text message.
+	OpcodeText = 0x1
+	// OpcodeBinary represents frames that start a binary message.
+	OpcodeBinary = 0x2
+	// OpcodeClose represents frames that close the connection.
+	OpcodeClose = 0x8
+	// OpcodePing represents frames that check whether the peer is still there.
+	OpcodePing = 0x9
+	// OpcodePong represents frames that answer pings.
+	OpcodePong = 0xA
+)
+
+// close status codes, as defined in RFC 6455.
+const (
+	// CloseNormalClosure represents connections closed after they fulfilled their purpose.
+	CloseNormalClosure = 1000
+	// CloseGoingAway represents connections closed because an endpoint is going away.
+	CloseGoingAway = 1001
+	// CloseProtocolError represents connections closed because of a protocol error.
+	CloseProtocolError = 1002
+	// CloseNoStatusReceived represents close frames that did not include a status code.
+	CloseNoStatusReceived = 1005
+	// CloseInvalidFramePayloadData represents connections closed because a message was not consistent with its type.
+	CloseInvalidFramePayloadData = 1007
+	// ClosePolicyViolation represents connections closed because a message violated the endpoint's policy.
+	ClosePolicyViolation = 1008
+	// CloseMessageTooBig represents connections closed because a message was too big to process.
+	CloseMessageTooBig = 1009
+	// CloseInternalServerError represents connections closed because of an unexpected condition.
+	CloseInternalServerError = 1011
+)
+
+const (
+	// DefaultMaxMessageSize is the default maximum size of messages read from a connection, in bytes.
+	DefaultMaxMessageSize = 1 << 20
+	// HTTPHeaderSecWebSocketKey is the header carrying the handshake key of opening handshakes.
+	HTTPHeaderSecWebSocketKey = "Sec-WebSocket-Key"
+	// HTTPHeaderSecWebSocketAccept is the header carrying the accepted handshake key of opening handshakes.
+	HTTPHeaderSecWebSocketAccept = "Sec-WebSocket-Accept"
+	// HTTPHeaderSecWebSocketVersion is the header carrying the protocol version of opening handshakes.
+	HTTPHeaderSecWebSocketVersion = "Sec-WebSocket-Version"
+	// HTTPHeaderUpgrade is the header that names the protocol to switch to.
+	HTTPHeaderUpgrade = "Upgrade"
+	// HTTPHeaderConnection is the header that controls whether the connection stays open.
+	HTTPHeaderConnection = "Connection"
+	// protocolVersion is the only protocol version supported.
+	protocolVersion = "13"
+	// acceptGUID is appended to handshake keys to compute the accepted handshake key.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// handshakeKeyLength is the number of random bytes in handshake keys.
+	handshakeKeyLength = 16
+	// maxControlPayloadLength is the maximum payload length of control frames.
+	maxControlPayloadLength = 125
+	// maxHandshakeErrorBodyLength is the maximum number of bytes of failed handshake responses that are kept.
+	maxHandshakeErrorBodyLength = 4096
+	// closeWriteTimeout is how long writing a close frame may take when the connection is being torn down.
+	closeWriteTimeout = time.Second
+)
+
+// frame header bits and lengths.
+const (
+	finBit          = 0x80
+	rsvBits         = 0x70
+	opcodeBits      = 0x0F
+	maskBit         = 0x80
+	lengthBits      = 0x7F
+	length16        = 126
+	length64        = 127
+	maskKeyLength   = 4
+	maxHeaderLength = 14
+)
+
+// CloseError represents a close frame received from the peer.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error returns the error message of a received close frame.
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Reason)
+}
+
+// HandshakeError represents an opening handshake that the server answered
+// without switching protocols.
+type HandshakeError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error returns the error message of a failed opening handshake.
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Conn is a WebSocket connection.
+// Messages may be written concurrently with reading, but only one goroutine may read at a time.
+type Conn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	isClient       bool
+	maxMessageSize int64
+	writeMu        sync.Mutex
+	closeSent      bool
+}
+
+// newConn returns a new Conn.
+func newConn(conn net.Conn, reader *bufio.Reader, isClient bool) *Conn {
+	return &Conn{
+		conn:           conn,
+		reader:         reader,
+		isClient:       isClient,
+		maxMessageSize: DefaultMaxMessageSize,
+		closeSent:      false,
+	}
+}
+
+// acceptKey computes the accepted handshake key for a given handshake key.
+func acceptKey(key string) string {
+	//nolint:gosec
+	sum := sha1.Sum([]byte(key + acceptGUID))
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContainsToken determines whether a comma-separated header contains a given token, ignoring case.
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsUpgradeRequest determines whether a given request asks to switch to the WebSocket protocol.
+func IsUpgradeRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerContainsToken(r.Header, HTTPHeaderConnection, "upgrade") &&
+		headerContainsToken(r.Header, HTTPHeaderUpgrade, "websocket")
+}
+
+// Upgrade completes the opening handshake of a given request and takes over its connection.
+// The response writer must support hijacking, and must not be written to afterwards.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !IsUpgradeRequest(r) {
+		return nil, errutils.FormatErrorf(nil, "request is not a websocket upgrade request")
+	}
+
+	if r.Header.Get(HTTPHeaderSecWebSocketVersion) != protocolVersion {
+		return nil, errutils.FormatErrorf(nil, "unsupported websocket version %s", r.Header.Get(HTTPHeaderSecWebSocketVersion))
+	}
+
+	key := r.Header.Get(HTTPHeaderSecWebSocketKey)
+	if key == "" {
+		return nil, errutils.FormatErrorf(nil, "missing %s header", HTTPHeaderSecWebSocketKey)
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, errutils.FormatError(err, "http.ResponseController.Hijack failed")
+	}
+
+	// deadlines set by the server for the request don't apply to the connection that takes over
+	err = netConn.SetDeadline(time.Time{})
+	if err != nil {
+		netConn.Close()
+
+		return nil, errutils.FormatError(err, "net.Conn.SetDeadline failed")
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		HTTPHeaderSecWebSocketAccept + ": " + acceptKey(key) + "\r\n\r\n"
+
+	_, err = netConn.Write([]byte(response))
+	if err != nil {
+		netConn.Close()
+
+		return nil, errutils.FormatError(err, "net.Conn.Write failed")
+	}
+
+	return newConn(netConn, rw.Reader, false), nil
+}
+
+// Dial opens a WebSocket connection to a given URL, with a given set of extra request headers.
+// The URL scheme may be ws, wss, http or https.
+// The context only bounds the opening handshake, not the lifetime of the connection.
+func Dial(ctx context.Context, rawURL string, header http.Header) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errutils.FormatError(err, "url.Parse failed")
+	}
+
+	secure := false
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	case "wss", "https":
+		u.Scheme = "https"
+		secure = true
+	default:
+		return nil, errutils.FormatErrorf(nil, "unsupported websocket url scheme %s", u.Scheme)
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if secure {
+			port = "443"
+		}
+
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var netConn net.Conn
+	if secure {
+		dialer := &tls.Dialer{
+			Config: &tls.Config{
+				ServerName: u.Hostname(),
+				MinVersion: tls.VersionTLS12,
+			},
+		}
+		netConn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		dialer := &net.Dialer{}
+		netConn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+
+	if err != nil {
+		return nil, errutils.FormatError(err, "DialContext failed")
+	}
+
+	conn, err := handshake(ctx, netConn, u, header)
+	if err != nil {
+		netConn.Close()
+
+		return nil, errutils.FormatError(err)
+	}
+
+	return conn, nil
+}
+
+// handshake performs the client side of the opening handshake on a given connection.
+func handshake(ctx context.Context, netConn net.Conn, u *url.URL, header http.Header) (*Conn, error) {
+	// the handshake is abandoned by expiring the connection's deadline when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = netConn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	keyBytes := make([]byte, handshakeKeyLength)
+	_, err := rand.Read(keyBytes)
+	if err != nil {
+		return nil, errutils.FormatError(err, "rand.Read failed")
+	}
+
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errutils.FormatError(err, "http.NewRequestWithContext failed")
+	}
+
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	req.Header.Set(HTTPHeaderUpgrade, "websocket")
+	req.Header.Set(HTTPHeaderConnection, "Upgrade")
+	req.Header.Set(HTTPHeaderSecWebSocketKey, key)
+	req.Header.Set(HTTPHeaderSecWebSocketVersion, protocolVersion)
+
+	err = req.Write(netConn)
+	if err != nil {
+		return nil, errutils.FormatError(err, "http.Request.Write failed")
+	}
+
+	reader := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, errutils.FormatError(err, "http.ReadResponse failed")
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHandshakeErrorBodyLength))
+		resp.Body.Close()
+
+		return nil, errutils.FormatError(&HandshakeError{
+			StatusCode: resp.StatusCode,
+			Body:       body,
+		})
+	}
+
+	if resp.Header.Get(HTTPHeaderSecWebSocketAccept) != acceptKey(key) {
+		return nil, errutils.FormatErrorf(nil, "invalid %s header", HTTPHeaderSecWebSocketAccept)
+	}
+
+	err = netConn.SetDeadline(time.Time{})
+	if err != nil {
+		return nil, errutils.FormatError(err, "net.Conn.SetDeadline failed")
+	}
+
+	return newConn(netConn, reader, true), nil
+}
+
+// SetMaxMessageSize sets the maximum size of messages read from the connection, in bytes.
+// Connections are closed with CloseMessageTooBig when a bigger message arrives.
+func (c *Conn) SetMaxMessageSize(size int64) {
+	c.maxMessageSize = size
+}
+
+// readFrame reads a single frame from the connection and unmasks its payload.
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(c.reader, header)
+	if err != nil {
+		return false, 0, nil, errutils.FormatError(err, "io.ReadFull failed")
+	}
+
+	fin := header[0]&finBit != 0
+	opcode := int(header[0] & opcodeBits)
+	masked := header[1]&maskBit != 0
+	length := int64(header[1] & lengthBits)
+
+	if header[0]&rsvBits != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+
+	// clients mask all frames they send, and servers mask none
+	if masked == c.isClient {
+		return false, 0, nil, c.fail(CloseProtocolError, "unexpected frame masking")
+	}
+
+	switch length {
+	case length16:
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(c.reader, extended)
+		length = int64(binary.BigEndian.Uint16(extended))
+	case length64:
+		extended := make([]byte, 8)
+		_, err = io.ReadFull(c.reader, extended)
+		length = int64(binary.BigEndian.Uint64(extended) & (1<<63 - 1))
+	}
+
+	if err != nil {
+		return false, 0, nil, errutils.FormatError(err, "io.ReadFull failed")
+	}
+
+	isControl := opcode >= OpcodeClose
+	if isControl && (!fin || length > maxControlPayloadLength) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+
+	if length > c.maxMessageSize {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var maskKey []byte
+	if masked {
+		maskKey = make([]byte, maskKeyLength)
+		_, err = io.ReadFull(c.reader, maskKey)
+		if err != nil {
+			return false, 0, nil, errutils.FormatError(err, "io.ReadFull failed")
+		}
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	if err != nil {
+		return false, 0, nil, errutils.FormatError(err, "io.ReadFull failed")
+	}
+
+	if masked {
+		maskBytes(maskKey, payload)
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// ReadMessage reads the next text or binary message from the connection, reassembling fragmented messages.
+// Pings are answered and pongs are ignored while waiting.
+// Once the peer closes the connection, the close is acknowledged and a CloseError is returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	messageOpcode := -1
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, errutils.FormatError(err)
+		}
+
+		switch opcode {
+		case OpcodePing:
+			err = c.writeFrame(OpcodePong, payload)
+			if err != nil {
+				return 0, nil, errutils.FormatError(err)
+			}
+
+			continue
+		case OpcodePong:
+			continue
+		case OpcodeClose:
+			closeErr := parseClosePayload(payload)
+			_ = c.WriteClose(closeErr.Code, "")
+
+			return 0, nil, errutils.FormatError(closeErr)
+		case OpcodeContinuation:
+			if messageOpcode < 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		case OpcodeText, OpcodeBinary:
+			if messageOpcode >= 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unfinished fragmented message")
+			}
+
+			messageOpcode = opcode
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message)+len(payload)) > c.maxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+
+		if messageOpcode == OpcodeText && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidFramePayloadData, "invalid utf-8 in text message")
+		}
+
+		return messageOpcode, message, nil
+	}
+}
+
+// ReadJSON reads the next message from the connection and decodes it as JSON into v.
+func (c *Conn) ReadJSON(v any) error {
+	_, message, err := c.ReadMessage()
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	err = json.Unmarshal(message, v)
+	if err != nil {
+		return errutils.FormatError(err, "json.Unmarshal failed")
+	}
+
+	return nil
+}
+
+// WriteMessage writes a single unfragmented message with a given opcode to the connection.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	err := c.writeFrame(opcode, data)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// WriteJSON encodes v as JSON and writes it to the connection as a text message.
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errutils.FormatError(err, "json.Marshal failed")
+	}
+
+	err = c.writeFrame(OpcodeText, data)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// WriteClose writes a close frame with a given status code and reason to the connection.
+// Only the first close frame is written, later calls do nothing.
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code)) //nolint:gosec
+	payload = append(payload, reason...)
+	if len(payload) > maxControlPayloadLength {
+		payload = payload[:maxControlPayloadLength]
+	}
+
+	err := c.writeFrame(OpcodeClose, payload)
+	if err != nil {
+		return errutils.FormatError(err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying network connection without writing a close frame.
+func (c *Conn) Close() error {
+	err := c.conn.Close()
+	if err != nil {
+		return errutils.FormatError(err, "net.Conn.Close failed")
+	}
+
+	return nil
+}
+
+// fail writes a close frame with a given status code and reason, then returns a matching error.
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(closeWriteTimeout))
+	_ = c.WriteClose(code, reason)
+
+	return errutils.FormatErrorf(nil, "websocket protocol error: %s", reason)
+}
+
+// writeFrame writes a single frame with a given opcode and payload to the connection,
+// masking the payload if the connection is a client connection.
+// Nothing is written once a close frame has been written.
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		if opcode == OpcodeClose {
+			return nil
+		}
+
+		return errutils.FormatError(net.ErrClosed, "websocket close already sent")
+	}
+
+	frame := make([]byte, 0, maxHeaderLength+len(payload))
+	frame = append(frame, finBit|byte(opcode))
+
+	var maskFlag byte
+	if c.isClient {
+		maskFlag = maskBit
+	}
+
+	switch {
+	case len(payload) < length16:
+		frame = append(frame, maskFlag|byte(len(payload)))
+	case len(payload) <= 1<<16-1:
+		frame = append(frame, maskFlag|length16)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload))) //nolint:gosec
+	default:
+		frame = append(frame, maskFlag|length64)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	if c.isClient {
+		maskKey := make([]byte, maskKeyLength)
+		_, err := rand.Read(maskKey)
+		if err != nil {
+			return errutils.FormatError(err, "rand.Read failed")
+		}
+
+		frame = append(frame, maskKey...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		maskBytes(maskKey, frame[start:])
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_, err := c.conn.Write(frame)
+	if err != nil {
+		return errutils.FormatError(err, "net.Conn.Write failed")
+	}
+
+	if opcode == OpcodeClose {
+		c.closeSent = true
+	}
+
+	return nil
+}
+
+// maskBytes masks or unmasks a given payload in place with a given masking key.
+func maskBytes(maskKey []byte, payload []byte) {
+	for i := range payload {
+		payload[i] ^= maskKey[i%maskKeyLength]
+	}
+}
+
+// parseClosePayload parses the status code and reason of a close frame payload.
+func parseClosePayload(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{
+			Code:   CloseNoStatusReceived,
+			Reason: "",
+		}
+	}
+
+	return &CloseError{
+		Code:   int(binary.BigEndian.Uint16(payload)),
+		Reason: string(payload[2:]),
+	}
+}
+
+// IsCloseError determines whether a given error is a CloseError with one of the given status codes.
+// If no status codes are given, any CloseError matches.
+func IsCloseError(err error, codes ...int) bool {
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) {
+		return false
+	}
+
+	if len(codes) == 0 {
+		return true
+	}
+
+	for _, code := range codes {
+		if closeErr.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
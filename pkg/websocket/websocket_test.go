@@ -0,0 +1,319 @@
+package websocket_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alvii147/nymphadora-api/pkg/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEchoServer starts a server that upgrades every request and runs handle on the connection.
+func newEchoServer(t *testing.T, handle func(conn *websocket.Conn)) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		handle(conn)
+	}))
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+// echo writes back every message read from a connection until it fails.
+func echo(conn *websocket.Conn) {
+	for {
+		opcode, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		err = conn.WriteMessage(opcode, message)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func TestIsUpgradeRequest(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		method      string
+		header      http.Header
+		wantUpgrade bool
+	}{
+		"Upgrade request": {
+			method: http.MethodGet,
+			header: http.Header{
+				"Connection": {"Upgrade"},
+				"Upgrade":    {"websocket"},
+			},
+			wantUpgrade: true,
+		},
+		"Upgrade request with several connection tokens": {
+			method: http.MethodGet,
+			header: http.Header{
+				"Connection": {"keep-alive, upgrade"},
+				"Upgrade":    {"WebSocket"},
+			},
+			wantUpgrade: true,
+		},
+		"Missing upgrade header": {
+			method: http.MethodGet,
+			header: http.Header{
+				"Connection": {"Upgrade"},
+			},
+			wantUpgrade: false,
+		},
+		"Other protocol": {
+			method: http.MethodGet,
+			header: http.Header{
+				"Connection": {"Upgrade"},
+				"Upgrade":    {"h2c"},
+			},
+			wantUpgrade: false,
+		},
+		"Not a GET request": {
+			method: http.MethodPost,
+			header: http.Header{
+				"Connection": {"Upgrade"},
+				"Upgrade":    {"websocket"},
+			},
+			wantUpgrade: false,
+		},
+	}
+
+	for name, testcase := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(testcase.method, "/", nil)
+			r.Header = testcase.header
+
+			require.Equal(t, testcase.wantUpgrade, websocket.IsUpgradeRequest(r))
+		})
+	}
+}
+
+func TestUpgradeNotUpgradeRequest(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := websocket.Upgrade(rec, r)
+	require.Error(t, err)
+}
+
+func TestConnReadWriteMessages(t *testing.T) {
+	t.Parallel()
+
+	wsURL := newEchoServer(t, echo)
+
+	conn, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	testcases := []struct {
+		opcode  int
+		message []byte
+	}{
+		{
+			opcode:  websocket.OpcodeText,
+			message: []byte("Yello!"),
+		},
+		{
+			opcode:  websocket.OpcodeBinary,
+			message: []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			opcode:  websocket.OpcodeText,
+			message: []byte(strings.Repeat("a", 1000)),
+		},
+		{
+			opcode:  websocket.OpcodeText,
+			message: []byte(strings.Repeat("b", 70000)),
+		},
+	}
+
+	for _, testcase := range testcases {
+		err = conn.WriteMessage(testcase.opcode, testcase.message)
+		require.NoError(t, err)
+
+		opcode, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, testcase.opcode, opcode)
+		require.Equal(t, testcase.message, message)
+	}
+}
+
+func TestConnReadWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	wsURL := newEchoServer(t, echo)
+
+	conn, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	type message struct {
+		Type string `json:"type"`
+		Data string `json:"data"`
+	}
+
+	sent := message{
+		Type: "stdin",
+		Data: "Harry\n",
+	}
+
+	// pings are answered without getting in the way of messages
+	err = conn.WriteMessage(websocket.OpcodePing, []byte("ping"))
+	require.NoError(t, err)
+
+	err = conn.WriteJSON(sent)
+	require.NoError(t, err)
+
+	var received message
+	err = conn.ReadJSON(&received)
+	require.NoError(t, err)
+	require.Equal(t, sent, received)
+}
+
+func TestConnClose(t *testing.T) {
+	t.Parallel()
+
+	serverErr := make(chan error, 1)
+	wsURL := newEchoServer(t, func(conn *websocket.Conn) {
+		_, _, err := conn.ReadMessage()
+		serverErr <- err
+	})
+
+	conn, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	err = conn.WriteClose(websocket.CloseNormalClosure, "Mischief managed")
+	require.NoError(t, err)
+
+	err = <-serverErr
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+	require.Equal(t, "Mischief managed", closeErr.Reason)
+
+	// the server acknowledges the close with the same status code
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+	err = conn.WriteMessage(websocket.OpcodeText, []byte("Yello!"))
+	require.Error(t, err)
+}
+
+func TestConnMaxMessageSize(t *testing.T) {
+	t.Parallel()
+
+	wsURL := newEchoServer(t, func(conn *websocket.Conn) {
+		conn.SetMaxMessageSize(8)
+		echo(conn)
+	})
+
+	conn, err := websocket.Dial(context.Background(), wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	err = conn.WriteMessage(websocket.OpcodeText, []byte("Yello!"))
+	require.NoError(t, err)
+
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, []byte("Yello!"), message)
+
+	err = conn.WriteMessage(websocket.OpcodeText, []byte("Wingardium Leviosa"))
+	require.NoError(t, err)
+
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+}
+
+func TestDialHandshakeError(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("Forbidden"))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := websocket.Dial(context.Background(), srv.URL, nil)
+
+	var handshakeErr *websocket.HandshakeError
+	require.ErrorAs(t, err, &handshakeErr)
+	require.Equal(t, http.StatusForbidden, handshakeErr.StatusCode)
+	require.Equal(t, []byte("Forbidden"), handshakeErr.Body)
+}
+
+func TestDialRequestHeaders(t *testing.T) {
+	t.Parallel()
+
+	gotAuthorization := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization <- r.Header.Get("Authorization")
+
+		conn, err := websocket.Upgrade(w, r)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, err := websocket.Dial(context.Background(), srv.URL, http.Header{
+		"Authorization": {"0xdeadbeef"},
+	})
+	require.NoError(t, err)
+	conn.Close()
+
+	require.Equal(t, "0xdeadbeef", <-gotAuthorization)
+}
+
+func TestDialUnsupportedScheme(t *testing.T) {
+	t.Parallel()
+
+	_, err := websocket.Dial(context.Background(), "ftp://localhost", nil)
+	require.Error(t, err)
+}
+
+func TestIsCloseError(t *testing.T) {
+	t.Parallel()
+
+	closeErr := &websocket.CloseError{
+		Code:   websocket.CloseGoingAway,
+		Reason: "",
+	}
+
+	require.True(t, websocket.IsCloseError(closeErr))
+	require.True(t, websocket.IsCloseError(closeErr, websocket.CloseNormalClosure, websocket.CloseGoingAway))
+	require.False(t, websocket.IsCloseError(closeErr, websocket.CloseNormalClosure))
+	require.False(t, websocket.IsCloseError(errors.New("Write failed")))
+}